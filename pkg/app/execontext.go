@@ -1,13 +1,19 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 
+	"github.com/docker-slim/docker-slim/pkg/command"
 	"github.com/docker-slim/docker-slim/pkg/consts"
+	"github.com/docker-slim/docker-slim/pkg/report"
 	"github.com/docker-slim/docker-slim/pkg/util/errutil"
 )
 
@@ -18,6 +24,7 @@ type ExecutionContext struct {
 
 func (ref *ExecutionContext) Exit(exitCode int) {
 	ref.doCleanup()
+	SaveExitSummary(command.StateExited, exitCode)
 	exit(exitCode)
 }
 
@@ -61,6 +68,10 @@ type Output struct {
 }
 
 func NewOutput(cmdName string) *Output {
+	updateExitSummary(func(info *report.ExitSummary) {
+		info.Command = cmdName
+	})
+
 	ref := &Output{
 		CmdName: cmdName,
 	}
@@ -72,15 +83,149 @@ func NoColor() {
 	color.NoColor = true
 }
 
+// Console output formats
+const (
+	ConsoleOutputText = "text"
+	ConsoleOutputJSON = "json"
+)
+
+var consoleOutput = ConsoleOutputText
+
+// SetConsoleOutput selects the console output format for all commands
+func SetConsoleOutput(format string) error {
+	switch format {
+	case ConsoleOutputText, ConsoleOutputJSON:
+		consoleOutput = format
+		return nil
+	default:
+		return fmt.Errorf("unknown console output format - %q", format)
+	}
+}
+
+// Console output event types
+const (
+	EventState   = "state"
+	EventInfo    = "info"
+	EventError   = "error"
+	EventMessage = "message"
+	EventPrompt  = "prompt"
+	EventLog     = "log"
+)
+
+// ConsoleEvent is the console output event in the 'json' console output mode
+// (each event is printed as one JSON object per line)
+type ConsoleEvent struct {
+	Command   string                 `json:"command"`
+	Event     string                 `json:"event"`
+	Name      string                 `json:"name"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Data      string                 `json:"data,omitempty"`
+	Timestamp string                 `json:"timestamp"`
+}
+
+func printEvent(cmdName, eventType, name string, fields OutVars, data string) {
+	event := ConsoleEvent{
+		Command:   cmdName,
+		Event:     eventType,
+		Name:      name,
+		Data:      data,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+
+	if len(fields) > 0 {
+		event.Fields = map[string]interface{}{}
+		for k, v := range fields {
+			event.Fields[k] = eventFieldValue(v)
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(event); err != nil {
+		//stdout has only the console events, so the error goes to stderr
+		fmt.Fprintf(os.Stderr, "docker-slim: error printing console event (%s/%s) - %v\n", eventType, name, err)
+	}
+}
+
+// eventFieldValue returns the field value that can be encoded as JSON
+func eventFieldValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case nil, string, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return v
+	case error:
+		return tv.Error()
+	case fmt.Stringer:
+		return tv.String()
+	}
+
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return v
+}
+
+// IsJSONConsoleOutput returns true if the console output is in the 'json' format
+func IsJSONConsoleOutput() bool {
+	return consoleOutput == ConsoleOutputJSON
+}
+
+// TextOutput returns the writer for the plain text console output
+// (in the 'json' mode stdout has only the console events, so the text goes to stderr)
+func TextOutput() io.Writer {
+	if IsJSONConsoleOutput() {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+// Printf prints the plain text console output (not the console events)
+func Printf(format string, a ...interface{}) {
+	fmt.Fprintf(TextOutput(), format, a...)
+}
+
+// Println prints the plain text console output (not the console events)
+func Println(a ...interface{}) {
+	fmt.Fprintln(TextOutput(), a...)
+}
+
+// Print prints the plain text console output (not the console events)
+func Print(a ...interface{}) {
+	fmt.Fprint(TextOutput(), a...)
+}
+
 type OutVars map[string]interface{}
 
+// sortedKeys returns the output var names in a stable order
+func sortedKeys(vars OutVars) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
 func (ref *Output) LogDump(logType, data string, params ...OutVars) {
+	if IsJSONConsoleOutput() {
+		var fields OutVars
+		if len(params) > 0 {
+			fields = params[0]
+		}
+
+		printEvent(ref.CmdName, EventLog, logType, fields, data)
+		return
+	}
+
 	var info string
 	if len(params) > 0 {
 		kvSet := params[0]
 		if len(kvSet) > 0 {
 			var builder strings.Builder
-			for k, v := range kvSet {
+			for _, k := range sortedKeys(kvSet) {
+				v := kvSet[k]
 				builder.WriteString(kcolor(k))
 				builder.WriteString("=")
 				builder.WriteString(fmt.Sprintf("'%s'", vcolor("%v", v)))
@@ -97,6 +242,11 @@ func (ref *Output) LogDump(logType, data string, params ...OutVars) {
 }
 
 func (ref *Output) Prompt(data string) {
+	if IsJSONConsoleOutput() {
+		printEvent(ref.CmdName, EventPrompt, "", OutVars{"message": data}, "")
+		return
+	}
+
 	color.Set(color.FgHiRed)
 	defer color.Unset()
 
@@ -104,6 +254,16 @@ func (ref *Output) Prompt(data string) {
 }
 
 func (ref *Output) Error(errType string, data string) {
+	updateExitSummary(func(info *report.ExitSummary) {
+		info.ErrorType = errType
+		info.Error = data
+	})
+
+	if IsJSONConsoleOutput() {
+		printEvent(ref.CmdName, EventError, errType, OutVars{"message": data}, "")
+		return
+	}
+
 	color.Set(color.FgHiRed)
 	defer color.Unset()
 
@@ -111,6 +271,11 @@ func (ref *Output) Error(errType string, data string) {
 }
 
 func (ref *Output) Message(data string) {
+	if IsJSONConsoleOutput() {
+		printEvent(ref.CmdName, EventMessage, "", OutVars{"message": data}, "")
+		return
+	}
+
 	color.Set(color.FgHiMagenta)
 	defer color.Unset()
 
@@ -118,6 +283,16 @@ func (ref *Output) Message(data string) {
 }
 
 func (ref *Output) State(state string, params ...OutVars) {
+	if IsJSONConsoleOutput() {
+		var fields OutVars
+		if len(params) > 0 {
+			fields = params[0]
+		}
+
+		printEvent(ref.CmdName, EventState, state, fields, "")
+		return
+	}
+
 	var exitInfo string
 	var info string
 	var sep string
@@ -134,7 +309,8 @@ func (ref *Output) State(state string, params ...OutVars) {
 			var builder strings.Builder
 			sep = " "
 
-			for k, v := range kvSet {
+			for _, k := range sortedKeys(kvSet) {
+				v := kvSet[k]
 				if k == "exit.code" {
 					continue
 				}
@@ -166,6 +342,24 @@ var (
 )
 
 func (ref *Output) Info(infoType string, params ...OutVars) {
+	if infoType == "report" && len(params) > 0 {
+		if reportFile, ok := params[0]["file"].(string); ok {
+			updateExitSummary(func(info *report.ExitSummary) {
+				info.ReportFile = reportFile
+			})
+		}
+	}
+
+	if IsJSONConsoleOutput() {
+		var fields OutVars
+		if len(params) > 0 {
+			fields = params[0]
+		}
+
+		printEvent(ref.CmdName, EventInfo, infoType, fields, "")
+		return
+	}
+
 	var data string
 	var sep string
 
@@ -175,7 +369,8 @@ func (ref *Output) Info(infoType string, params ...OutVars) {
 			var builder strings.Builder
 			sep = " "
 
-			for k, v := range kvSet {
+			for _, k := range sortedKeys(kvSet) {
+				v := kvSet[k]
 				builder.WriteString(kcolor(k))
 				builder.WriteString("=")
 				builder.WriteString(fmt.Sprintf("'%s'", vcolor("%v", v)))
@@ -190,6 +385,18 @@ func (ref *Output) Info(infoType string, params ...OutVars) {
 }
 
 func ShowCommunityInfo() {
+	if IsJSONConsoleOutput() {
+		for _, info := range []OutVars{
+			{"message": "join the Gitter channel to ask questions or to share your feedback", "info": consts.CommunityGitter},
+			{"message": "join the Discord server to ask questions or to share your feedback", "info": consts.CommunityDiscord},
+			{"message": "Github discussions", "info": consts.CommunityDiscussions},
+		} {
+			printEvent("docker-slim", EventMessage, "community", info, "")
+		}
+
+		return
+	}
+
 	color.Set(color.FgHiMagenta)
 	defer color.Unset()
 	fmt.Printf("docker-slim: message='join the Gitter channel to ask questions or to share your feedback' info='%s'\n", consts.CommunityGitter)
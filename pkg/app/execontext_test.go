@@ -0,0 +1,246 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureOutput returns the stdout and stderr output printed by the function
+func captureOutput(t *testing.T, fn func()) (string, string) {
+	dir, err := ioutil.TempDir("", "ds-output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stdout, err := ioutil.TempFile(dir, "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+
+	stderr, err := ioutil.TempFile(dir, "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+
+	origStdout, origStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	defer func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+	}()
+
+	fn()
+
+	outData, err := ioutil.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errData, err := ioutil.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(outData), string(errData)
+}
+
+func setTestConsoleOutput(t *testing.T, format string) func() {
+	orig := consoleOutput
+	if err := SetConsoleOutput(format); err != nil {
+		t.Fatal(err)
+	}
+
+	return func() {
+		consoleOutput = orig
+	}
+}
+
+func TestSetConsoleOutput(t *testing.T) {
+	defer setTestConsoleOutput(t, ConsoleOutputText)()
+
+	tt := []struct {
+		format string
+		isJSON bool
+		isErr  bool
+	}{
+		{format: ConsoleOutputJSON, isJSON: true},
+		{format: ConsoleOutputText, isJSON: false},
+		{format: "yaml", isJSON: false, isErr: true},
+		{format: "", isJSON: false, isErr: true},
+	}
+
+	for _, test := range tt {
+		err := SetConsoleOutput(test.format)
+		if (err != nil) != test.isErr {
+			t.Errorf("%q: unexpected error result - %v", test.format, err)
+		}
+
+		if IsJSONConsoleOutput() != test.isJSON {
+			t.Errorf("%q: got json=%v expected %v", test.format, IsJSONConsoleOutput(), test.isJSON)
+		}
+
+		expectedOut := os.Stdout
+		if test.isJSON {
+			expectedOut = os.Stderr
+		}
+
+		if TextOutput() != expectedOut {
+			t.Errorf("%q: unexpected text output writer", test.format)
+		}
+	}
+}
+
+func TestJSONConsoleOutput(t *testing.T) {
+	defer setTestConsoleOutput(t, ConsoleOutputJSON)()
+
+	stdout, stderr := captureOutput(t, func() {
+		out := NewOutput("build")
+		out.State("started")
+		out.Info("params", OutVars{
+			"target":  "app",
+			"b.count": 2,
+			"error":   errors.New("not found"),
+			"timeout": 90 * time.Second,
+		})
+		Printf("text %d\n", 1)
+		out.Error("image.inspect", "image not found")
+		out.Message("done")
+		out.Prompt("press enter")
+		Println("more", "text")
+		out.LogDump("container.logs", "line 1\nline 2", OutVars{"id": "c1"})
+		Print("last")
+	})
+
+	if expected := "text 1\nmore text\nlast"; stderr != expected {
+		t.Errorf("stderr: got %q expected %q", stderr, expected)
+	}
+
+	if !strings.HasSuffix(stdout, "\n") {
+		t.Errorf("stdout: the last event is not terminated - %q", stdout)
+	}
+
+	expected := []ConsoleEvent{
+		{Command: "build", Event: EventState, Name: "started"},
+		{
+			Command: "build",
+			Event:   EventInfo,
+			Name:    "params",
+			Fields: map[string]interface{}{
+				"b.count": float64(2),
+				"error":   "not found",
+				"target":  "app",
+				"timeout": "1m30s",
+			},
+		},
+		{Command: "build", Event: EventError, Name: "image.inspect", Fields: map[string]interface{}{"message": "image not found"}},
+		{Command: "build", Event: EventMessage, Fields: map[string]interface{}{"message": "done"}},
+		{Command: "build", Event: EventPrompt, Fields: map[string]interface{}{"message": "press enter"}},
+		{Command: "build", Event: EventLog, Name: "container.logs", Fields: map[string]interface{}{"id": "c1"}, Data: "line 1\nline 2"},
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("stdout: got %d lines expected %d:\n%s", len(lines), len(expected), stdout)
+	}
+
+	//the fixed field set (the optional fields are omitted when empty)
+	requiredKeys := []string{"command", "event", "name", "timestamp"}
+	knownKeys := map[string]struct{}{"fields": {}, "data": {}}
+	for _, key := range requiredKeys {
+		knownKeys[key] = struct{}{}
+	}
+
+	for idx, line := range lines {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			t.Errorf("line %d: not a JSON object - %v (%s)", idx, err, line)
+			continue
+		}
+
+		for _, key := range requiredKeys {
+			if _, found := raw[key]; !found {
+				t.Errorf("line %d: missing '%s' (%s)", idx, key, line)
+			}
+		}
+
+		for key := range raw {
+			if _, found := knownKeys[key]; !found {
+				t.Errorf("line %d: unexpected '%s' (%s)", idx, key, line)
+			}
+		}
+
+		var event ConsoleEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Errorf("line %d: %v", idx, err)
+			continue
+		}
+
+		if _, err := time.Parse(time.RFC3339Nano, event.Timestamp); err != nil {
+			t.Errorf("line %d: bad timestamp - %v", idx, err)
+		}
+
+		event.Timestamp = ""
+		if !reflect.DeepEqual(event, expected[idx]) {
+			t.Errorf("line %d:\ngot      %+v\nexpected %+v", idx, event, expected[idx])
+		}
+	}
+
+	//the fields are sorted by name
+	if expected := `"fields":{"b.count":2,"error":"not found","target":"app","timeout":"1m30s"}`; !strings.Contains(lines[1], expected) {
+		t.Errorf("unsorted fields: %s", lines[1])
+	}
+}
+
+func TestTextConsoleOutput(t *testing.T) {
+	defer setTestConsoleOutput(t, ConsoleOutputText)()
+
+	stdout, stderr := captureOutput(t, func() {
+		Printf("text %d\n", 1)
+		Println("more", "text")
+	})
+
+	if expected := "text 1\nmore text\n"; stdout != expected {
+		t.Errorf("stdout: got %q expected %q", stdout, expected)
+	}
+
+	if stderr != "" {
+		t.Errorf("stderr: unexpected output %q", stderr)
+	}
+}
+
+func TestEventFieldValue(t *testing.T) {
+	type custom struct {
+		Name string `json:"name"`
+	}
+
+	ch := make(chan int)
+	tt := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{value: nil, expected: nil},
+		{value: "value", expected: "value"},
+		{value: 10, expected: 10},
+		{value: true, expected: true},
+		{value: errors.New("failed"), expected: "failed"},
+		{value: 5 * time.Second, expected: "5s"},
+		{value: []string{"a", "b"}, expected: []string{"a", "b"}},
+		{value: custom{Name: "x"}, expected: custom{Name: "x"}},
+		{value: ch, expected: fmt.Sprintf("%v", ch)},
+	}
+
+	for _, test := range tt {
+		if got := eventFieldValue(test.value); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%v: got %#v expected %#v", test.value, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/command"
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/version"
+)
+
+// exitSummary collects the exit summary info from the command console output events
+var exitSummary struct {
+	sync.Mutex
+	location string
+	saved    bool
+	info     report.ExitSummary
+}
+
+// EnableExitSummary saves the machine-readable exit summary to the selected file
+// when the command exits (including the exits on fatal errors)
+func EnableExitSummary(location string) {
+	exitSummary.Lock()
+	defer exitSummary.Unlock()
+
+	exitSummary.location = location
+	exitSummary.saved = false
+	exitSummary.info = report.ExitSummary{
+		Version:    report.OVExitSummary,
+		AppVersion: version.Current(),
+		StartTime:  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	log.RegisterExitHandler(func() {
+		SaveExitSummary(command.StateError, 1)
+	})
+}
+
+// SaveExitSummary saves the exit summary if it's enabled
+// (only the first call saves the summary)
+func SaveExitSummary(state command.State, exitCode int) {
+	exitSummary.Lock()
+	defer exitSummary.Unlock()
+
+	if exitSummary.location == "" || exitSummary.saved {
+		return
+	}
+
+	exitSummary.saved = true
+	exitSummary.info.State = state
+	exitSummary.info.ExitCode = exitCode
+	exitSummary.info.EndTime = time.Now().UTC().Format(time.RFC3339)
+
+	data, err := json.MarshalIndent(&exitSummary.info, "", "  ")
+	if err != nil {
+		log.Errorf("docker-slim: error encoding the exit summary - %v", err)
+		return
+	}
+
+	if err := ioutil.WriteFile(exitSummary.location, append(data, '\n'), 0644); err != nil {
+		log.Errorf("docker-slim: error saving the exit summary (%s) - %v", exitSummary.location, err)
+	}
+}
+
+func updateExitSummary(update func(info *report.ExitSummary)) {
+	exitSummary.Lock()
+	defer exitSummary.Unlock()
+
+	if exitSummary.location != "" && !exitSummary.saved {
+		update(&exitSummary.info)
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/command"
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+func TestExitSummary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ds-exit-summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	location := filepath.Join(dir, "exit.json")
+	EnableExitSummary(location)
+	defer func() {
+		exitSummary.location = ""
+	}()
+
+	out := NewOutput("xray")
+	out.Info("params", OutVars{"target": "app"})
+	out.Error("image.inspect", "image not found")
+	out.Info("report", OutVars{"file": "/tmp/slim.report.json"})
+
+	SaveExitSummary(command.StateExited, -777)
+	//the summary is saved only once (for the first exit)
+	SaveExitSummary(command.StateDone, 0)
+
+	data, err := ioutil.ReadFile(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var summary report.ExitSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.StartTime == "" || summary.EndTime == "" {
+		t.Errorf("missing start/end times: %+v", summary)
+	}
+
+	summary.StartTime, summary.EndTime, summary.AppVersion = "", "", ""
+	expected := report.ExitSummary{
+		Version:    report.OVExitSummary,
+		Command:    "xray",
+		State:      command.StateExited,
+		ExitCode:   -777,
+		ErrorType:  "image.inspect",
+		Error:      "image not found",
+		ReportFile: "/tmp/slim.report.json",
+	}
+
+	if summary != expected {
+		t.Errorf("unexpected exit summary:\ngot      %+v\nexpected %+v", summary, expected)
+	}
+}
@@ -0,0 +1,349 @@
+package builder
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	docker "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/app/master/config"
+	"github.com/docker-slim/docker-slim/pkg/app/master/registryclient"
+	"github.com/docker-slim/docker-slim/pkg/consts"
+	v "github.com/docker-slim/docker-slim/pkg/version"
+)
+
+var (
+	ErrNoImageOutput = errors.New("no image output selected")
+)
+
+const (
+	dataDirName = "files"
+	dataTarName = "files.tar"
+)
+
+// AssembledImage provides the info about the image created without a Docker build
+type AssembledImage struct {
+	Image         gocrv1.Image
+	ID            string
+	Digest        string
+	Size          int64
+	LayerCount    int
+	OCILayoutPath string
+	ArchivePath   string
+	Loaded        bool
+}
+
+// Assemble creates the optimized image directly from the collected artifacts
+// (without a Docker build) and saves it to the selected outputs
+func (b *ImageBuilder) Assemble(opts *config.ImageBuildOptions) (*AssembledImage, error) {
+	if opts == nil ||
+		(opts.OCILayoutPath == "" && opts.ArchivePath == "" && !opts.LoadToDocker) {
+		return nil, ErrNoImageOutput
+	}
+
+	//still generating the Dockerfile (useful as a reference artifact)
+	if err := b.GenerateDockerfile(); err != nil {
+		return nil, err
+	}
+
+	img, err := b.assembleImage()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AssembledImage{
+		Image: img,
+	}
+
+	cn, err := img.ConfigName()
+	if err != nil {
+		return nil, err
+	}
+	result.ID = cn.String()
+
+	d, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+	result.Digest = d.String()
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	result.LayerCount = len(layers)
+
+	for _, layer := range layers {
+		size, err := uncompressedSize(layer)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Size += size
+	}
+
+	refs, err := b.imageRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.OCILayoutPath != "" {
+		if err := registryclient.SaveImageToOCILayout(opts.OCILayoutPath, img, refs[0].Name()); err != nil {
+			return nil, err
+		}
+
+		result.OCILayoutPath = opts.OCILayoutPath
+		fmt.Fprintf(&b.BuildLog, "saved OCI image layout to %s\n", opts.OCILayoutPath)
+	}
+
+	refToImage := map[name.Reference]gocrv1.Image{}
+	for _, ref := range refs {
+		refToImage[ref] = img
+	}
+
+	if opts.ArchivePath != "" {
+		if err := tarball.MultiRefWriteToFile(opts.ArchivePath, refToImage); err != nil {
+			return nil, err
+		}
+
+		result.ArchivePath = opts.ArchivePath
+		fmt.Fprintf(&b.BuildLog, "saved image archive to %s\n", opts.ArchivePath)
+	}
+
+	if opts.LoadToDocker {
+		if err := b.loadImage(refToImage); err != nil {
+			return nil, err
+		}
+
+		result.Loaded = true
+	}
+
+	return result, nil
+}
+
+func (b *ImageBuilder) assembleImage() (gocrv1.Image, error) {
+	labels := map[string]string{}
+	for k, v := range b.Labels {
+		labels[k] = v
+	}
+	labels[consts.ContainerLabelName] = v.Current()
+
+	exposedPorts := map[string]struct{}{}
+	for k, v := range b.ExposedPorts {
+		exposedPorts[string(k)] = v
+	}
+
+	cf := &gocrv1.ConfigFile{
+		Architecture: b.Architecture,
+		OS:           b.OS,
+		//using the source image creation time to keep the output reproducible
+		Created: gocrv1.Time{Time: b.Created.UTC()},
+		Config: gocrv1.Config{
+			Entrypoint:   b.Entrypoint,
+			Cmd:          b.Cmd,
+			WorkingDir:   b.WorkingDir,
+			Env:          b.Env,
+			Labels:       labels,
+			ExposedPorts: exposedPorts,
+			Volumes:      b.Volumes,
+			OnBuild:      b.OnBuild,
+			User:         b.User,
+		},
+		RootFS: gocrv1.RootFS{
+			Type: "layers",
+		},
+	}
+
+	img, err := mutate.ConfigFile(empty.Image, cf)
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.HasData {
+		log.Debug("ImageBuilder.assembleImage: no data artifacts")
+		return img, nil
+	}
+
+	var layer gocrv1.Layer
+	contextDir := b.BuildOptions.ContextDir
+	if b.TarData {
+		layer, err = tarball.LayerFromFile(filepath.Join(contextDir, dataTarName))
+	} else {
+		dataDir := filepath.Join(contextDir, dataDirName)
+		layer, err = tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+			return dirArchiveReader(dataDir), nil
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mutate.Append(img, mutate.Addendum{
+		Layer: layer,
+		History: gocrv1.History{
+			Created:   cf.Created,
+			CreatedBy: fmt.Sprintf("docker-slim %s", v.Current()),
+			Comment:   "optimized image data",
+		},
+	})
+}
+
+func (b *ImageBuilder) imageRefs() ([]name.Reference, error) {
+	var refs []name.Reference
+	ref, err := name.NewTag(b.RepoName)
+	if err != nil {
+		return nil, err
+	}
+
+	refs = append(refs, ref)
+	for _, fullTag := range b.AdditionalTags {
+		fullTag = strings.TrimSpace(fullTag)
+		if len(fullTag) == 0 {
+			continue
+		}
+
+		ref, err := name.NewTag(fullTag)
+		if err != nil {
+			//not failing on tagging errors
+			log.Debugf("ImageBuilder.imageRefs: skipping malformed tag - '%s' (error - %v)", fullTag, err)
+			continue
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+func (b *ImageBuilder) loadImage(refToImage map[name.Reference]gocrv1.Image) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(tarball.MultiRefWrite(refToImage, pw))
+	}()
+
+	err := b.APIClient.LoadImage(docker.LoadImageOptions{
+		InputStream:  pr,
+		OutputStream: &b.BuildLog,
+	})
+
+	pr.CloseWithError(err)
+	return err
+}
+
+func uncompressedSize(layer gocrv1.Layer) (int64, error) {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	return io.Copy(ioutil.Discard, rc)
+}
+
+// dirArchiveReader streams the directory content as a tar archive
+// (the directory itself is the archive root; entries are in lexical order and
+// the file times and the owner names are cleared, so the layer digest depends only on the file data and metadata)
+func dirArchiveReader(dataDir string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := writeDirArchive(tw, dataDir, "")
+		if err == nil {
+			err = tw.Close()
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	return pr
+}
+
+func writeDirArchive(tw *tar.Writer, dataDir, relDir string) error {
+	infos, err := ioutil.ReadDir(filepath.Join(dataDir, relDir))
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+
+	for _, info := range infos {
+		relPath := filepath.Join(relDir, info.Name())
+		if err := writeArchiveEntry(tw, filepath.Join(dataDir, relPath), relPath, info); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if err := writeDirArchive(tw, dataDir, relPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeArchiveEntry(tw *tar.Writer, fullPath, relPath string, info os.FileInfo) error {
+	var linkRef string
+	switch {
+	case info.IsDir(), info.Mode().IsRegular():
+	case info.Mode()&os.ModeSymlink != 0:
+		var err error
+		if linkRef, err = os.Readlink(fullPath); err != nil {
+			return err
+		}
+	default:
+		log.Debugf("dirArchiveReader: ignoring other file object types - %q", fullPath)
+		return nil
+	}
+
+	th, err := tar.FileInfoHeader(info, linkRef)
+	if err != nil {
+		return err
+	}
+
+	th.Name = filepath.ToSlash(relPath)
+	if info.IsDir() {
+		th.Name += "/"
+	}
+
+	th.ModTime = time.Unix(0, 0)
+	th.AccessTime = time.Time{}
+	th.ChangeTime = time.Time{}
+	th.Uname = ""
+	th.Gname = ""
+
+	if err := tw.WriteHeader(th); err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(tw, f)
+	return err
+}
@@ -0,0 +1,162 @@
+package builder
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func newTestDataDir(t *testing.T) string {
+	contextDir, err := ioutil.TempDir("", "ds-assembler-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataDir := filepath.Join(contextDir, dataDirName)
+	files := map[string]string{
+		"etc/hosts":        "127.0.0.1 localhost\n",
+		"app/bin/server":   "server binary",
+		"app/a.conf":       "a",
+		"app/z.conf":       "z",
+		"app/lib/empty.so": "",
+	}
+
+	for name, data := range files {
+		fullPath := filepath.Join(dataDir, name)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(fullPath, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dataDir, "tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink("bin/server", filepath.Join(dataDir, "app/server")); err != nil {
+		t.Fatal(err)
+	}
+
+	return contextDir
+}
+
+func touchAll(t *testing.T, dir string, ts time.Time) {
+	err := filepath.Walk(dir, func(fullPath string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			return err
+		}
+
+		return os.Chtimes(fullPath, ts, ts)
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assembledDigest(t *testing.T, contextDir string) string {
+	b := &ImageBuilder{
+		BasicImageBuilder: BasicImageBuilder{
+			BuildOptions: docker.BuildImageOptions{ContextDir: contextDir},
+		},
+		RepoName:     "app.slim",
+		Entrypoint:   []string{"/app/server"},
+		ExposedPorts: map[docker.Port]struct{}{"8080/tcp": {}},
+		OS:           "linux",
+		Architecture: "amd64",
+		Created:      time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		HasData:      true,
+	}
+
+	img, err := b.assembleImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return digest.String()
+}
+
+func TestAssembleImageReproducible(t *testing.T) {
+	contextDir := newTestDataDir(t)
+	defer os.RemoveAll(contextDir)
+
+	touchAll(t, contextDir, time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC))
+	first := assembledDigest(t, contextDir)
+
+	//a new run collects the same files with different file times
+	touchAll(t, contextDir, time.Now())
+	second := assembledDigest(t, contextDir)
+
+	if first != second {
+		t.Errorf("image digests are different for the same data: %s != %s", first, second)
+	}
+}
+
+func TestDirArchiveReader(t *testing.T) {
+	contextDir := newTestDataDir(t)
+	defer os.RemoveAll(contextDir)
+
+	rc := dirArchiveReader(filepath.Join(contextDir, dataDirName))
+	defer rc.Close()
+
+	var names []string
+	tr := tar.NewReader(rc)
+	for {
+		th, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if th.ModTime.Unix() != 0 || !th.AccessTime.IsZero() || !th.ChangeTime.IsZero() {
+			t.Errorf("%s: file times are not cleared (mtime=%v atime=%v ctime=%v)",
+				th.Name, th.ModTime, th.AccessTime, th.ChangeTime)
+		}
+
+		if th.Uname != "" || th.Gname != "" {
+			t.Errorf("%s: owner names are not cleared (uname=%q gname=%q)", th.Name, th.Uname, th.Gname)
+		}
+
+		if th.Name == "app/server" && (th.Typeflag != tar.TypeSymlink || th.Linkname != "bin/server") {
+			t.Errorf("app/server: expected a symlink to bin/server (type=%v link=%q)", th.Typeflag, th.Linkname)
+		}
+
+		names = append(names, th.Name)
+	}
+
+	expected := []string{
+		"app/",
+		"app/a.conf",
+		"app/bin/",
+		"app/bin/server",
+		"app/lib/",
+		"app/lib/empty.so",
+		"app/server",
+		"app/z.conf",
+		"etc/",
+		"etc/hosts",
+		"tmp/",
+	}
+
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected archive entries:\ngot      %v\nexpected %v", names, expected)
+	}
+}
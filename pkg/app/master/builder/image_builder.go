@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/docker-slim/docker-slim/pkg/app/master/config"
 	"github.com/docker-slim/docker-slim/pkg/docker/dockerfile/reverse"
@@ -42,6 +43,9 @@ type ImageBuilder struct {
 	Volumes        map[string]struct{}
 	OnBuild        []string
 	User           string
+	OS             string
+	Architecture   string
+	Created        time.Time
 	HasData        bool
 	TarData        bool
 }
@@ -202,6 +206,9 @@ func NewImageBuilder(client *docker.Client,
 		Volumes:        imageInfo.Config.Volumes,
 		OnBuild:        imageInfo.Config.OnBuild,
 		User:           imageInfo.Config.User,
+		OS:             imageInfo.OS,
+		Architecture:   imageInfo.Architecture,
+		Created:        imageInfo.Created,
 	}
 
 	if builder.ExposedPorts == nil {
@@ -335,12 +342,12 @@ func NewImageBuilder(client *docker.Client,
 
 	builder.BuildOptions.OutputStream = &builder.BuildLog
 
-	dataTar := filepath.Join(artifactLocation, "files.tar")
+	dataTar := filepath.Join(artifactLocation, dataTarName)
 	builder.TarData = fsutil.IsRegularFile(dataTar)
 	if builder.TarData {
 		builder.HasData = true
 	} else {
-		dataDir := filepath.Join(artifactLocation, "files")
+		dataDir := filepath.Join(artifactLocation, dataDirName)
 		builder.HasData = fsutil.IsDir(dataDir)
 	}
 
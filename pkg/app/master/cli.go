@@ -1,7 +1,6 @@
 package app
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
@@ -19,11 +18,13 @@ import (
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands/probe"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands/profile"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands/registry"
+	"github.com/docker-slim/docker-slim/pkg/app/master/commands/report"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands/run"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands/server"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands/update"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands/version"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands/xray"
+	"github.com/docker-slim/docker-slim/pkg/command"
 	"github.com/docker-slim/docker-slim/pkg/system"
 	v "github.com/docker-slim/docker-slim/pkg/version"
 
@@ -45,6 +46,7 @@ func registerCommands() {
 	lint.RegisterCommand()
 	build.RegisterCommand()
 	registry.RegisterCommand()
+	report.RegisterCommand()
 	profile.RegisterCommand()
 	version.RegisterCommand()
 	help.RegisterCommand()
@@ -68,7 +70,7 @@ func newCLI() *cli.App {
 	cliApp.Name = AppName
 	cliApp.Usage = AppUsage
 	cliApp.CommandNotFound = func(ctx *cli.Context, command string) {
-		fmt.Printf("unknown command - %v \n\n", command)
+		app.Printf("unknown command - %v \n\n", command)
 		cli.ShowAppHelp(ctx)
 	}
 
@@ -79,6 +81,14 @@ func newCLI() *cli.App {
 			app.NoColor()
 		}
 
+		if err := app.SetConsoleOutput(ctx.String(commands.FlagConsoleOutput)); err != nil {
+			log.Fatal(err)
+		}
+
+		if location := ctx.String(commands.FlagExitSummary); location != "" {
+			app.EnableExitSummary(location)
+		}
+
 		if ctx.Bool(commands.FlagDebug) {
 			log.SetLevel(log.DebugLevel)
 		} else {
@@ -138,6 +148,8 @@ func newCLI() *cli.App {
 	}
 
 	cliApp.After = func(ctx *cli.Context) error {
+		app.SaveExitSummary(command.StateDone, 0)
+
 		//tmp hack
 		if !strings.Contains(strings.Join(os.Args, " "), " docker-cli-plugin-metadata") {
 			app.ShowCommunityInfo()
@@ -8,7 +8,9 @@ import (
 	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
 	"github.com/docker-slim/docker-slim/pkg/app/master/config"
+	"github.com/docker-slim/docker-slim/pkg/app/master/security/seccomp"
 	"github.com/docker-slim/docker-slim/pkg/util/errutil"
+	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
 
 	"github.com/urfave/cli/v2"
 )
@@ -71,12 +73,18 @@ var CLI = &cli.Command{
 		commands.Cflag(commands.FlagShowContainerLogs),
 		cflag(FlagShowBuildLogs),
 		commands.Cflag(commands.FlagCopyMetaArtifacts),
+		commands.Cflag(commands.FlagSBOM),
+		commands.Cflag(commands.FlagSeccompArch),
 		commands.Cflag(commands.FlagRemoveFileArtifacts),
 		commands.Cflag(commands.FlagExec),
 		commands.Cflag(commands.FlagExecFile),
 		//
 		cflag(FlagTag),
 		cflag(FlagImageOverrides),
+		cflag(FlagImageBuildEngine),
+		cflag(FlagImageBuildOCILayout),
+		cflag(FlagImageBuildArchive),
+		cflag(FlagImageBuildLoad),
 		//Container Run Options
 		commands.Cflag(commands.FlagCRORuntime),
 		commands.Cflag(commands.FlagCROHostConfigFile),
@@ -130,6 +138,13 @@ var CLI = &cli.Command{
 		cflag(FlagIncludeBinFile),
 		cflag(FlagIncludeExeFile),
 		cflag(FlagIncludeExe),
+		cflag(FlagIncludeStaticDeps),
+		cflag(FlagStaticOnly),
+		cflag(FlagMergeReport),
+		cflag(FlagVariantCmd),
+		cflag(FlagVariantExec),
+		cflag(FlagExplain),
+		cflag(FlagRemovedFilesReport),
 		cflag(FlagIncludeShell),
 		cflag(FlagIncludeCertAll),
 		cflag(FlagIncludeCertBundles),
@@ -161,6 +176,16 @@ var CLI = &cli.Command{
 			xc.Exit(-1)
 		}
 
+		ibOpts, err := GetImageBuildOptions(ctx)
+		if err != nil {
+			xc.Out.Error("param.error.image.build.options", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
 		deleteFatImage := ctx.Bool(commands.FlagDeleteFatImage)
 		if cbOpts.Dockerfile == "" {
 			deleteFatImage = false
@@ -259,6 +284,26 @@ var CLI = &cli.Command{
 		doRmFileArtifacts := ctx.Bool(commands.FlagRemoveFileArtifacts)
 		doCopyMetaArtifacts := ctx.String(commands.FlagCopyMetaArtifacts)
 
+		sbomFormats, err := commands.ParseSBOMFormats(ctx.StringSlice(commands.FlagSBOM))
+		if err != nil {
+			xc.Out.Error("param.error.sbom", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
+		seccompArchs, err := seccomp.ParseArchNames(ctx.StringSlice(commands.FlagSeccompArch))
+		if err != nil {
+			xc.Out.Error("param.error.seccomp.arch", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
 		portBindings, err := commands.ParsePortBindings(ctx.StringSlice(commands.FlagPublishPort))
 		if err != nil {
 			xc.Out.Error("param.publish.port", err.Error())
@@ -493,6 +538,30 @@ var CLI = &cli.Command{
 			}
 		}
 
+		doIncludeStaticDeps := ctx.Bool(FlagIncludeStaticDeps)
+		doStaticOnly := ctx.Bool(FlagStaticOnly)
+
+		mergeReports := ctx.StringSlice(FlagMergeReport)
+		for _, reportPath := range mergeReports {
+			if !fsutil.Exists(reportPath) {
+				xc.Out.Error("param.error.merge.report", fmt.Sprintf("container report not found - %s", reportPath))
+				xc.Out.State("exited",
+					ovars{
+						"exit.code": -1,
+					})
+				xc.Exit(-1)
+			}
+		}
+
+		runVariants, err := parseRunVariants(ctx.StringSlice(FlagVariantCmd), ctx.StringSlice(FlagVariantExec))
+		if err != nil {
+			xc.Out.Error("param.error.variant", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
 		doIncludeShell := ctx.Bool(FlagIncludeShell)
 
 		doIncludeCertAll := ctx.Bool(FlagIncludeCertAll)
@@ -641,6 +710,7 @@ var CLI = &cli.Command{
 			containerProbeComposeSvc,
 			cbOpts,
 			crOpts,
+			ibOpts,
 			outputTags,
 			doHTTPProbe,
 			httpProbeCmds,
@@ -661,6 +731,8 @@ var CLI = &cli.Command{
 			doPublishExposedPorts,
 			doRmFileArtifacts,
 			doCopyMetaArtifacts,
+			sbomFormats,
+			seccompArchs,
 			doRunTargetAsUser,
 			doShowContainerLogs,
 			doShowBuildLogs,
@@ -679,6 +751,12 @@ var CLI = &cli.Command{
 			includePaths,
 			includeBins,
 			includeExes,
+			doIncludeStaticDeps,
+			doStaticOnly,
+			mergeReports,
+			runVariants,
+			ctx.StringSlice(FlagExplain),
+			ctx.Bool(FlagRemovedFilesReport),
 			doIncludeShell,
 			doIncludeCertAll,
 			doIncludeCertBundles,
@@ -0,0 +1,166 @@
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
+	"github.com/docker-slim/docker-slim/pkg/report"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// explainKeptFiles adds the kept file rule stats to the command report
+// and explains why the selected paths were kept in the minified image
+func explainKeptFiles(
+	xc *app.ExecutionContext,
+	logger *log.Entry,
+	artifactLocation string,
+	paths []string,
+	cmdReport *report.BuildCommand) {
+	creport, err := loadContainerReport(filepath.Join(artifactLocation, report.DefaultContainerReportFileName))
+	if err != nil {
+		logger.Debugf("explainKeptFiles: error loading container report - %v", err)
+		return
+	}
+
+	cmdReport.KeptFileRules = keptFileRules(creport)
+
+	for _, name := range paths {
+		info := explainKeptFile(creport, name)
+		cmdReport.Explanations = append(cmdReport.Explanations, info)
+
+		outInfo := ovars{
+			"path": info.Path,
+			"kept": info.Kept,
+		}
+
+		if info.KeptPath != "" && info.KeptPath != info.Path {
+			outInfo["kept.path"] = info.KeptPath
+		}
+
+		if len(info.KeptBy) > 0 {
+			outInfo["kept.by"] = provenanceText(info.KeptBy)
+		}
+
+		xc.Out.Info("explain", outInfo)
+	}
+}
+
+// keptFileRules returns the number of kept files for each provenance rule
+func keptFileRules(creport *report.ContainerReport) map[string]int {
+	counts := map[string]int{}
+	for _, props := range creport.Image.Files {
+		if props == nil {
+			continue
+		}
+
+		rules := map[string]struct{}{}
+		for _, p := range props.KeptBy {
+			rules[p.Rule] = struct{}{}
+		}
+
+		for rule := range rules {
+			counts[rule]++
+		}
+	}
+
+	return counts
+}
+
+// maxExplainLinks is the max number of the kept symlinks followed to explain a path
+const maxExplainLinks = 40
+
+// explainKeptFile looks up the kept artifact for the path
+// (or the kept directory that includes it; the kept symlinks in the path are followed)
+func explainKeptFile(creport *report.ContainerReport, name string) *report.KeptFileInfo {
+	name = filepath.Clean("/" + name)
+	info := &report.KeptFileInfo{Path: name}
+
+	links := map[string]string{}
+	for _, props := range creport.Image.Files {
+		if props != nil && props.LinkRef != "" {
+			links[props.FilePath] = props.LinkRef
+		}
+	}
+
+	lookupPath := name
+	for i := 0; i <= maxExplainLinks; i++ {
+		if props := findKeptArtifact(creport, lookupPath); props != nil {
+			info.Kept = true
+			info.KeptPath = props.FilePath
+			info.KeptBy = props.KeptBy
+			return info
+		}
+
+		resolved := resolveKeptLink(links, lookupPath)
+		if resolved == lookupPath {
+			break
+		}
+
+		lookupPath = resolved
+	}
+
+	return info
+}
+
+// findKeptArtifact returns the kept artifact for the path
+// (or the longest kept directory path that includes it)
+func findKeptArtifact(creport *report.ContainerReport, name string) *report.ArtifactProps {
+	var dirProps *report.ArtifactProps
+	for _, props := range creport.Image.Files {
+		if props == nil {
+			continue
+		}
+
+		if props.FilePath == name {
+			return props
+		}
+
+		//the file mode is not saved in the container report (only its text form)
+		if strings.HasPrefix(props.ModeText, "d") && strings.HasPrefix(name, props.FilePath+"/") {
+			if dirProps == nil || len(props.FilePath) > len(dirProps.FilePath) {
+				dirProps = props
+			}
+		}
+	}
+
+	return dirProps
+}
+
+// resolveKeptLink replaces the first kept symlink in the parent directories of the path with its target
+func resolveKeptLink(links map[string]string, name string) string {
+	parts := strings.Split(strings.TrimPrefix(name, "/"), "/")
+	current := "/"
+	for idx, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		target, found := links[current]
+		if !found {
+			continue
+		}
+
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(current), target)
+		}
+
+		return filepath.Join(append([]string{target}, parts[idx+1:]...)...)
+	}
+
+	return name
+}
+
+func provenanceText(provenance []*report.Provenance) string {
+	var items []string
+	for _, p := range provenance {
+		if p.Source != "" {
+			items = append(items, fmt.Sprintf("%s(%s)", p.Rule, p.Source))
+		} else {
+			items = append(items, p.Rule)
+		}
+	}
+
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
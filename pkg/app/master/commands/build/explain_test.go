@@ -0,0 +1,118 @@
+package build
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+func testKeptArtifact(name string, mode os.FileMode, linkRef string, keptBy ...*report.Provenance) *report.ArtifactProps {
+	return &report.ArtifactProps{
+		FilePath: name,
+		Mode:     mode,
+		ModeText: mode.String(),
+		LinkRef:  linkRef,
+		KeptBy:   keptBy,
+	}
+}
+
+func testExplainReport() *report.ContainerReport {
+	fanotify := &report.Provenance{Rule: report.ProvenanceFanotify, Source: "/app/server"}
+	symlink := &report.Provenance{Rule: report.ProvenanceSymlink, Source: "/app/server"}
+	includePath := &report.Provenance{Rule: report.ProvenanceIncludePath, Source: "/app/static"}
+	includeDir := &report.Provenance{Rule: report.ProvenanceIncludePath, Source: "/app/static/css"}
+
+	creport := &report.ContainerReport{}
+	creport.Image.Files = []*report.ArtifactProps{
+		testKeptArtifact("/app/server", 0755, "", fanotify, &report.Provenance{Rule: report.ProvenancePtrace, Source: "execve"}),
+		testKeptArtifact("/app/static", os.ModeDir|0755, "", includePath),
+		testKeptArtifact("/app/static/css", os.ModeDir|0755, "", includeDir),
+		testKeptArtifact("/lib", os.ModeSymlink|0777, "usr/lib", symlink),
+		testKeptArtifact("/usr/lib/libc.so.6", 0755, "", &report.Provenance{Rule: report.ProvenanceELFDeps, Source: "/app/server"}),
+		testKeptArtifact("/opt/current", os.ModeSymlink|0777, "/opt/v2", symlink),
+		testKeptArtifact("/opt/v2", os.ModeSymlink|0777, "releases/2.0", symlink),
+		testKeptArtifact("/opt/releases/2.0/bin/tool", 0755, "", fanotify),
+		testKeptArtifact("/loop/a", os.ModeSymlink|0777, "b", symlink),
+		testKeptArtifact("/loop/b", os.ModeSymlink|0777, "a", symlink),
+		nil,
+	}
+
+	return creport
+}
+
+func TestExplainKeptFile(t *testing.T) {
+	creport := testExplainReport()
+
+	tt := []struct {
+		path     string
+		kept     bool
+		keptPath string
+		keptBy   []string
+	}{
+		{path: "/app/server", kept: true, keptPath: "/app/server", keptBy: []string{"fanotify:/app/server", "ptrace:execve"}},
+		{path: "app/./server", kept: true, keptPath: "/app/server", keptBy: []string{"fanotify:/app/server", "ptrace:execve"}},
+		{path: "/app/missing", kept: false},
+		{path: "/app/server/extra", kept: false},
+		{path: "/app/static/index.html", kept: true, keptPath: "/app/static", keptBy: []string{"include.path:/app/static"}},
+		{path: "/app/static/css/main.css", kept: true, keptPath: "/app/static/css", keptBy: []string{"include.path:/app/static/css"}},
+		{path: "/app/staticfile", kept: false},
+		{path: "/lib", kept: true, keptPath: "/lib", keptBy: []string{"symlink:/app/server"}},
+		{path: "/lib/libc.so.6", kept: true, keptPath: "/usr/lib/libc.so.6", keptBy: []string{"elf.deps:/app/server"}},
+		{path: "/lib/libssl.so.3", kept: false},
+		{path: "/opt/current/bin/tool", kept: true, keptPath: "/opt/releases/2.0/bin/tool", keptBy: []string{"fanotify:/app/server"}},
+		{path: "/loop/a/file", kept: false},
+	}
+
+	for _, test := range tt {
+		info := explainKeptFile(creport, test.path)
+		if info.Kept != test.kept || info.KeptPath != test.keptPath {
+			t.Errorf("%s: got kept=%v kept.path=%q expected kept=%v kept.path=%q",
+				test.path, info.Kept, info.KeptPath, test.kept, test.keptPath)
+		}
+
+		var keptBy []string
+		for _, p := range info.KeptBy {
+			keptBy = append(keptBy, p.Rule+":"+p.Source)
+		}
+
+		if !reflect.DeepEqual(keptBy, test.keptBy) {
+			t.Errorf("%s: got kept.by=%q expected %q", test.path, keptBy, test.keptBy)
+		}
+	}
+}
+
+func TestKeptFileRules(t *testing.T) {
+	creport := testExplainReport()
+	creport.Image.Files = append(creport.Image.Files,
+		testKeptArtifact("/etc/hosts", 0644, "",
+			&report.Provenance{Rule: report.ProvenancePtrace, Source: "openat"},
+			&report.Provenance{Rule: report.ProvenancePtrace, Source: "stat"}),
+		testKeptArtifact("/etc/resolv.conf", 0644, ""))
+
+	expected := map[string]int{
+		report.ProvenanceFanotify:    2,
+		report.ProvenancePtrace:      2,
+		report.ProvenanceIncludePath: 2,
+		report.ProvenanceSymlink:     5,
+		report.ProvenanceELFDeps:     1,
+	}
+
+	if got := keptFileRules(creport); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v expected %v", got, expected)
+	}
+}
+
+func TestProvenanceText(t *testing.T) {
+	provenance := []*report.Provenance{
+		{Rule: report.ProvenanceSymlink, Source: "/bin/sh"},
+		{Rule: report.ProvenanceHardlink},
+		{Rule: report.ProvenanceFanotify, Source: "/app/server"},
+	}
+
+	expected := "fanotify(/app/server),hardlink,symlink(/bin/sh)"
+	if got := provenanceText(provenance); got != expected {
+		t.Errorf("got %q expected %q", got, expected)
+	}
+}
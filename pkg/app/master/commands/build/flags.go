@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
 	"github.com/docker-slim/docker-slim/pkg/app/master/config"
 
@@ -30,6 +31,16 @@ const (
 	FlagIncludeExeFile   = "include-exe-file"
 	FlagIncludeShell     = "include-shell"
 
+	FlagIncludeStaticDeps = "include-static-deps"
+	FlagStaticOnly        = "static-only"
+
+	FlagMergeReport = "merge-report"
+	FlagVariantCmd  = "variant-cmd"
+	FlagVariantExec = "variant-exec"
+
+	FlagExplain            = "explain"
+	FlagRemovedFilesReport = "removed-files-report"
+
 	FlagIncludeCertAll     = "include-cert-all"
 	FlagIncludeCertBundles = "include-cert-bundles-only"
 	FlagIncludeCertDirs    = "include-cert-dirs"
@@ -61,6 +72,12 @@ const (
 
 	FlagImageOverrides = "image-overrides"
 
+	//Flags to select how the optimized image is built
+	FlagImageBuildEngine    = "image-build-engine"
+	FlagImageBuildOCILayout = "image-build-oci-layout"
+	FlagImageBuildArchive   = "image-build-archive"
+	FlagImageBuildLoad      = "image-build-load"
+
 	//Flags to build fat images from Dockerfile
 	FlagTagFat              = "tag-fat"
 	FlagBuildFromDockerfile = "dockerfile"
@@ -89,6 +106,16 @@ const (
 	FlagIncludeExeUsage       = "Keep executable from original image (by executable name)"
 	FlagIncludeShellUsage     = "Keep basic shell functionality"
 
+	FlagIncludeStaticDepsUsage = "Resolve the include-bin and include-exe dependencies from the image data before running the container"
+	FlagStaticOnlyUsage        = "Minify the image using only the static analysis of the image data (without running the container)"
+
+	FlagMergeReportUsage = "Merge the collected data from a previously saved container report (creport.json) for the same image"
+	FlagVariantCmdUsage  = "Run the target container one more time with this CMD override and merge the collected data"
+	FlagVariantExecUsage = "Run the target container one more time executing this shell command and merge the collected data"
+
+	FlagExplainUsage            = "Explain why the file (or directory) was kept in the minified image"
+	FlagRemovedFilesReportUsage = "Save the report for the files removed from the original image (grouped by directory and OS package)"
+
 	FlagIncludeCertAllUsage     = "Keep all discovered cert files"
 	FlagIncludeCertBundlesUsage = "Keep only cert bundles"
 	FlagIncludeCertDirsUsage    = "Keep known cert directories and all files in them"
@@ -117,6 +144,11 @@ const (
 
 	FlagImageOverridesUsage = "Save runtime overrides in generated image (values is 'all' or a comma delimited list of override types: 'entrypoint', 'cmd', 'workdir', 'env', 'expose', 'volume', 'label')"
 
+	FlagImageBuildEngineUsage    = "Select the image build engine for the optimized image: 'docker' (Docker build) or 'internal' (assembled directly without a Docker build)"
+	FlagImageBuildOCILayoutUsage = "Save the optimized image as an OCI image layout in the selected directory (internal image build engine)"
+	FlagImageBuildArchiveUsage   = "Save the optimized image as a 'docker save' compatible tarball (internal image build engine)"
+	FlagImageBuildLoadUsage      = "Load the optimized image into Docker (internal image build engine)"
+
 	FlagIncludeBinFileUsage = "File with shared binary file names to include from image"
 	FlagIncludeExeFileUsage = "File with executable file names to include from image"
 
@@ -190,6 +222,45 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagIncludeShellUsage,
 		EnvVars: []string{"DSLIM_INCLUDE_SHELL"},
 	},
+	FlagIncludeStaticDeps: &cli.BoolFlag{
+		Name:    FlagIncludeStaticDeps,
+		Usage:   FlagIncludeStaticDepsUsage,
+		EnvVars: []string{"DSLIM_INCLUDE_STATIC_DEPS"},
+	},
+	FlagStaticOnly: &cli.BoolFlag{
+		Name:    FlagStaticOnly,
+		Usage:   FlagStaticOnlyUsage,
+		EnvVars: []string{"DSLIM_STATIC_ONLY"},
+	},
+	FlagMergeReport: &cli.StringSliceFlag{
+		Name:    FlagMergeReport,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagMergeReportUsage,
+		EnvVars: []string{"DSLIM_MERGE_REPORT"},
+	},
+	FlagVariantCmd: &cli.StringSliceFlag{
+		Name:    FlagVariantCmd,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagVariantCmdUsage,
+		EnvVars: []string{"DSLIM_VARIANT_CMD"},
+	},
+	FlagVariantExec: &cli.StringSliceFlag{
+		Name:    FlagVariantExec,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagVariantExecUsage,
+		EnvVars: []string{"DSLIM_VARIANT_EXEC"},
+	},
+	FlagExplain: &cli.StringSliceFlag{
+		Name:    FlagExplain,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagExplainUsage,
+		EnvVars: []string{"DSLIM_EXPLAIN"},
+	},
+	FlagRemovedFilesReport: &cli.BoolFlag{
+		Name:    FlagRemovedFilesReport,
+		Usage:   FlagRemovedFilesReportUsage,
+		EnvVars: []string{"DSLIM_REMOVED_FILES_REPORT"},
+	},
 	////
 	FlagIncludeCertAll: &cli.BoolFlag{
 		Name:    FlagIncludeCertAll,
@@ -288,6 +359,30 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagImageOverridesUsage,
 		EnvVars: []string{"DSLIM_TARGET_OVERRIDES"},
 	},
+	FlagImageBuildEngine: &cli.StringFlag{
+		Name:    FlagImageBuildEngine,
+		Value:   config.IBEDocker,
+		Usage:   FlagImageBuildEngineUsage,
+		EnvVars: []string{"DSLIM_IMAGE_BUILD_ENGINE"},
+	},
+	FlagImageBuildOCILayout: &cli.StringFlag{
+		Name:    FlagImageBuildOCILayout,
+		Value:   "",
+		Usage:   FlagImageBuildOCILayoutUsage,
+		EnvVars: []string{"DSLIM_IMAGE_BUILD_OCI_LAYOUT"},
+	},
+	FlagImageBuildArchive: &cli.StringFlag{
+		Name:    FlagImageBuildArchive,
+		Value:   "",
+		Usage:   FlagImageBuildArchiveUsage,
+		EnvVars: []string{"DSLIM_IMAGE_BUILD_ARCHIVE"},
+	},
+	FlagImageBuildLoad: &cli.BoolFlag{
+		Name:    FlagImageBuildLoad,
+		Value:   true, //enabled by default
+		Usage:   FlagImageBuildLoadUsage,
+		EnvVars: []string{"DSLIM_IMAGE_BUILD_LOAD"},
+	},
 	//Container Build Options
 	FlagBuildFromDockerfile: &cli.StringFlag{
 		Name:    FlagBuildFromDockerfile,
@@ -443,7 +538,7 @@ func GetContainerBuildOptions(ctx *cli.Context) (*config.ContainerBuildOptions,
 				cbo.BuildArgs = append(cbo.BuildArgs, ba)
 			}
 		default:
-			fmt.Printf("GetContainerBuildOptions(): unexpected build arg format - '%v'\n", rba)
+			app.Printf("GetContainerBuildOptions(): unexpected build arg format - '%v'\n", rba)
 		}
 	}
 
@@ -466,13 +561,43 @@ func GetContainerBuildOptions(ctx *cli.Context) (*config.ContainerBuildOptions,
 				cbo.Labels[parts[0]] = envVal
 			}
 		default:
-			fmt.Printf("GetContainerBuildOptions(): unexpected label format - '%v'\n", rlabel)
+			app.Printf("GetContainerBuildOptions(): unexpected label format - '%v'\n", rlabel)
 		}
 	}
 
 	return cbo, nil
 }
 
+func GetImageBuildOptions(ctx *cli.Context) (*config.ImageBuildOptions, error) {
+	ibo := &config.ImageBuildOptions{
+		Engine:        ctx.String(FlagImageBuildEngine),
+		OCILayoutPath: ctx.String(FlagImageBuildOCILayout),
+		ArchivePath:   ctx.String(FlagImageBuildArchive),
+		LoadToDocker:  ctx.Bool(FlagImageBuildLoad),
+	}
+
+	if ibo.Engine == "" {
+		ibo.Engine = config.IBEDocker
+	}
+
+	if !config.IsValidImageBuildEngine(ibo.Engine) {
+		return nil, fmt.Errorf("unknown image build engine - '%s'", ibo.Engine)
+	}
+
+	if ibo.Engine == config.IBEDocker {
+		//the outputs are only used by the internal engine
+		if ibo.OCILayoutPath != "" || ibo.ArchivePath != "" {
+			return nil, fmt.Errorf("image build outputs require the '%s' image build engine", config.IBEInternal)
+		}
+
+		ibo.LoadToDocker = true
+	} else if !ibo.LoadToDocker && ibo.OCILayoutPath == "" && ibo.ArchivePath == "" {
+		return nil, fmt.Errorf("no image build outputs selected")
+	}
+
+	return ibo, nil
+}
+
 //TODO: move/share when the 'edit' command needs these flags too
 
 func GetImageInstructions(ctx *cli.Context) (*config.ImageNewInstructions, error) {
@@ -488,7 +613,7 @@ func GetImageInstructions(ctx *cli.Context) (*config.ImageNewInstructions, error
 
 	volumes, err := commands.ParseTokenSet(ctx.StringSlice(FlagNewVolume))
 	if err != nil {
-		fmt.Printf("getImageInstructions(): invalid new volume options %v\n", err)
+		app.Printf("getImageInstructions(): invalid new volume options %v\n", err)
 		return nil, err
 	}
 
@@ -496,7 +621,7 @@ func GetImageInstructions(ctx *cli.Context) (*config.ImageNewInstructions, error
 
 	labels, err := commands.ParseTokenMap(ctx.StringSlice(FlagNewLabel))
 	if err != nil {
-		fmt.Printf("getImageInstructions(): invalid new label options %v\n", err)
+		app.Printf("getImageInstructions(): invalid new label options %v\n", err)
 		return nil, err
 	}
 
@@ -504,7 +629,7 @@ func GetImageInstructions(ctx *cli.Context) (*config.ImageNewInstructions, error
 
 	removeLabels, err := commands.ParseTokenSet(ctx.StringSlice(FlagRemoveLabel))
 	if err != nil {
-		fmt.Printf("getImageInstructions(): invalid remove label options %v\n", err)
+		app.Printf("getImageInstructions(): invalid remove label options %v\n", err)
 		return nil, err
 	}
 
@@ -512,7 +637,7 @@ func GetImageInstructions(ctx *cli.Context) (*config.ImageNewInstructions, error
 
 	removeEnvs, err := commands.ParseTokenSet(ctx.StringSlice(FlagRemoveEnv))
 	if err != nil {
-		fmt.Printf("getImageInstructions(): invalid remove env options %v\n", err)
+		app.Printf("getImageInstructions(): invalid remove env options %v\n", err)
 		return nil, err
 	}
 
@@ -520,7 +645,7 @@ func GetImageInstructions(ctx *cli.Context) (*config.ImageNewInstructions, error
 
 	removeVolumes, err := commands.ParseTokenSet(ctx.StringSlice(FlagRemoveVolume))
 	if err != nil {
-		fmt.Printf("getImageInstructions(): invalid remove volume options %v\n", err)
+		app.Printf("getImageInstructions(): invalid remove volume options %v\n", err)
 		return nil, err
 	}
 
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -22,11 +23,14 @@ import (
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/container"
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/container/probes/http"
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
+	"github.com/docker-slim/docker-slim/pkg/app/master/security/kubernetes"
 	"github.com/docker-slim/docker-slim/pkg/app/master/version"
 	"github.com/docker-slim/docker-slim/pkg/command"
 	"github.com/docker-slim/docker-slim/pkg/consts"
 	"github.com/docker-slim/docker-slim/pkg/docker/dockerutil"
 	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/sbom"
+	"github.com/docker-slim/docker-slim/pkg/system"
 	"github.com/docker-slim/docker-slim/pkg/util/errutil"
 	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
 	"github.com/docker-slim/docker-slim/pkg/util/printbuffer"
@@ -63,7 +67,7 @@ func NewLogWriter(name string) *chanWriter {
 	go func() {
 		s := bufio.NewScanner(cw.r)
 		for s.Scan() {
-			fmt.Println(name + ": " + string(s.Bytes()))
+			app.Println(name + ": " + string(s.Bytes()))
 		}
 	}()
 	return cw
@@ -106,6 +110,7 @@ func OnCommand(
 	containerProbeComposeSvc string,
 	cbOpts *config.ContainerBuildOptions,
 	crOpts *config.ContainerRunOptions,
+	ibOpts *config.ImageBuildOptions,
 	outputTags []string,
 	doHTTPProbe bool,
 	httpProbeCmds []config.HTTPProbeCmd,
@@ -126,6 +131,8 @@ func OnCommand(
 	doPublishExposedPorts bool,
 	doRmFileArtifacts bool,
 	copyMetaArtifactsLocation string,
+	sbomFormats []string,
+	seccompArchs []system.ArchName,
 	doRunTargetAsUser bool,
 	doShowContainerLogs bool,
 	doShowBuildLogs bool,
@@ -144,6 +151,12 @@ func OnCommand(
 	includePaths map[string]*fsutil.AccessInfo,
 	includeBins map[string]*fsutil.AccessInfo,
 	includeExes map[string]*fsutil.AccessInfo,
+	doIncludeStaticDeps bool,
+	doStaticOnly bool,
+	mergeReports []string,
+	runVariants []*runVariant,
+	explainPaths []string,
+	doRemovedFilesReport bool,
 	doIncludeShell bool,
 	doIncludeCertAll bool,
 	doIncludeCertBundles bool,
@@ -592,7 +605,7 @@ func OnCommand(
 		overrides.Workdir, overrides.Env, overrides.ExposedPorts)
 
 	if gparams.Debug {
-		version.Print(prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		version.Print(xc, prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
 	}
 
 	if overrides.Network == "host" && runtime.GOOS == "darwin" {
@@ -633,6 +646,7 @@ func OnCommand(
 
 	imageInspector, err := image.NewInspector(client, targetRef)
 	xc.FailOn(err)
+	imageInspector.SeccompArchs = seccompArchs
 
 	if imageInspector.NoImage() {
 		if doPull {
@@ -743,540 +757,251 @@ func OnCommand(
 
 	xc.Out.State("image.inspection.done")
 
-	//validate links (check if target container exists, ignore&log if not)
-	svcLinkMap := map[string]struct{}{}
-	for _, linkInfo := range links {
-		svcLinkMap[linkInfo] = struct{}{}
-	}
-
-	selectedNetNames := map[string]compose.NetNameInfo{}
-	if depServicesExe != nil {
-		xc.Out.State("container.dependencies.init.start")
-		err = depServicesExe.Prepare()
-		xc.FailOn(err)
-		err = depServicesExe.Start()
-		if err != nil {
-			depServicesExe.Stop()
-			depServicesExe.Cleanup()
-		}
+	if doIncludeStaticDeps && !doStaticOnly && (len(includeBins) > 0 || len(includeExes) > 0) {
+		xc.Out.State("static.includes.start")
+		_, imageFS, err := saveImageData(xc, logger, client, imageInspector, localVolumePath)
 		xc.FailOn(err)
 
-		exeCleanup := func() {
-			if depServicesExe != nil {
-				xc.Out.State("container.dependencies.shutdown.start")
-				err = depServicesExe.Stop()
-				errutil.WarnOn(err)
-				err = depServicesExe.Cleanup()
-				errutil.WarnOn(err)
-				xc.Out.State("container.dependencies.shutdown.done")
-			}
+		var imageEnv []string
+		if imageInspector.ImageInfo.Config != nil {
+			imageEnv = imageInspector.ImageInfo.Config.Env
 		}
 
-		xc.AddCleanupHandler(exeCleanup)
-
-		//todo:
-		//need a better way to make sure the dependencies are ready
-		//monitor docker events
-		//use basic application level checks (probing)
-		time.Sleep(3 * time.Second)
-		xc.Out.State("container.dependencies.init.done")
-
-		//might need more info (including alias info) when targeting compose services
-		allNetNames := depServicesExe.ActiveNetworkNames()
-
-		if targetComposeSvc != "" {
-			//if we are targetting a compose service and
-			//we have explicitly selected compose networks (composeNets)
-			//we use the selected subset of the configured networks for the target service
-			composeNetsSet := map[string]struct{}{}
-			for _, key := range composeNets {
-				composeNetsSet[key] = struct{}{}
-			}
-
-			svcNets := depServicesExe.ActiveServiceNetworks(targetComposeSvc)
-			for key, netNameInfo := range svcNets {
-				if len(composeNets) > 0 {
-					if _, found := composeNetsSet[key]; !found {
-						continue
-					}
-				}
+		staticIncludes, resolvedBins, resolvedExes := resolveStaticIncludes(logger, imageFS, imageEnv, includeBins, includeExes)
 
-				selectedNetNames[key] = netNameInfo
-			}
-		} else {
-			//we are not targetting a compose service,
-			//but we do want to connect to the networks in compose
-			if len(composeNets) > 0 {
-				for _, key := range composeNets {
-					if net, found := allNetNames[key]; found {
-						selectedNetNames[key] = compose.NetNameInfo{
-							FullName: net,
-							//Aliases: serviceAliases, - we merge serviceAliases later
-						}
-					}
-				}
-			} else {
-				//select/use all networks if specific networks are not selected
-				for key, fullName := range allNetNames {
-					selectedNetNames[key] = compose.NetNameInfo{
-						FullName: fullName,
-						//Aliases: serviceAliases, - we merge serviceAliases later
-					}
-				}
-			}
+		if includePaths == nil {
+			includePaths = map[string]*fsutil.AccessInfo{}
 		}
-	}
-
-	links = []string{} //reset&reuse
-	if targetComposeSvc != "" && depServicesExe != nil {
-		targetSvcInfo := depServicesExe.Service(targetComposeSvc)
-		//convert service links to container links (after deps are started)
-		targetSvcLinkMap := map[string]struct{}{}
-		for _, linkInfo := range targetSvcInfo.Config.Links {
-			var linkTarget string
-			var linkName string
-			parts := strings.Split(linkInfo, ":")
-			switch len(parts) {
-			case 1:
-				linkTarget = parts[0]
-				linkName = parts[0]
-			case 2:
-				linkTarget = parts[0]
-				linkName = parts[1]
-			default:
-				logger.Debugf("targetSvcInfo.Config.Links: malformed link - %s", linkInfo)
-				continue
-			}
-
-			linkSvcInfo := depServicesExe.Service(linkTarget)
-			if linkSvcInfo == nil {
-				logger.Debugf("targetSvcInfo.Config.Links: unknown service in link - %s", linkInfo)
-				continue
-			}
 
-			logger.Debugf("targetSvcInfo.Config.Links: linkInfo=%s linkSvcInfo=%#v", linkInfo, linkSvcInfo)
-			if linkSvcInfo.ContainerName == "" {
-				logger.Debugf("targetSvcInfo.Config.Links: no container name - linkInfo=%s", linkInfo)
-				continue
+		for _, name := range staticIncludes {
+			if _, found := includePaths[name]; !found {
+				includePaths[name] = nil
 			}
-
-			clink := fmt.Sprintf("%s:%s", linkSvcInfo.ContainerName, linkSvcInfo.ContainerName)
-			targetSvcLinkMap[clink] = struct{}{}
-			clink = fmt.Sprintf("%s:%s", linkSvcInfo.ContainerName, linkName)
-			targetSvcLinkMap[clink] = struct{}{}
-		}
-
-		for k := range targetSvcLinkMap {
-			links = append(links, k)
-		}
-	}
-
-	for k := range svcLinkMap {
-		links = append(links, k)
-	}
-
-	selectedNetworks := map[string]container.NetNameInfo{}
-	for key, info := range selectedNetNames {
-		aset := map[string]struct{}{}
-		for _, a := range info.Aliases {
-			aset[a] = struct{}{}
 		}
 
-		//merge serviceAliases with the main set of aliases
-		for _, a := range serviceAliases {
-			aset[a] = struct{}{}
-		}
-		var alist []string
-		for a := range aset {
-			alist = append(alist, a)
+		//the resolved binaries and executables don't need to be resolved again by the sensor
+		for _, name := range resolvedBins {
+			delete(includeBins, name)
 		}
 
-		selectedNetworks[key] = container.NetNameInfo{
-			Name:     key,
-			FullName: info.FullName,
-			Aliases:  alist,
+		for _, name := range resolvedExes {
+			delete(includeExes, name)
 		}
-	}
-
-	xc.Out.State("container.inspection.start")
-
-	hasClassicLinks := true
-	if targetComposeSvc != "" ||
-		len(composeNets) > 0 ||
-		overrides.Network != "" {
-		hasClassicLinks = false
-	}
 
-	containerInspector, err := container.NewInspector(
-		xc,
-		crOpts,
-		logger,
-		client,
-		statePath,
-		imageInspector,
-		localVolumePath,
-		doUseLocalMounts,
-		doUseSensorVolume,
-		doKeepTmpArtifacts,
-		overrides,
-		explicitVolumeMounts,
-		baseMounts,
-		baseVolumesFrom,
-		portBindings,
-		doPublishExposedPorts,
-		hasClassicLinks,
-		links,
-		etcHostsMaps,
-		dnsServers,
-		dnsSearchDomains,
-		doRunTargetAsUser,
-		doShowContainerLogs,
-		doKeepPerms,
-		pathPerms,
-		excludePatterns,
-		preservePaths,
-		includePaths,
-		includeBins,
-		includeExes,
-		doIncludeShell,
-		doIncludeCertAll,
-		doIncludeCertBundles,
-		doIncludeCertDirs,
-		doIncludeCertPKAll,
-		doIncludeCertPKDirs,
-		doIncludeNew,
-		selectedNetworks,
-		gparams.Debug,
-		logLevel,
-		logFormat,
-		gparams.InContainer,
-		sensorIPCEndpoint,
-		sensorIPCMode,
-		true,
-		prefix)
-	xc.FailOn(err)
+		cmdReport.StaticIncludes = staticIncludes
+		staticIncludesPath, err := saveStaticIncludes(imageInspector.ArtifactLocation, staticIncludes)
+		errutil.WarnOn(err)
 
-	if len(containerInspector.FatContainerCmd) == 0 {
-		xc.Out.Info("target.image.error",
+		xc.Out.Info("static.includes",
 			ovars{
-				"status":  "no.entrypoint.cmd",
-				"image":   targetRef,
-				"message": "no ENTRYPOINT/CMD",
+				"bins":  len(resolvedBins),
+				"exes":  len(resolvedExes),
+				"paths": len(staticIncludes),
+				"file":  staticIncludesPath,
 			})
 
-		exitCode := commands.ECTBuild | ecbNoEntrypoint
-		xc.Out.State("exited", ovars{"exit.code": exitCode})
-
-		cmdReport.Error = "no.entrypoint.cmd"
-		xc.Exit(exitCode)
+		xc.Out.State("static.includes.done")
 	}
 
-	logger.Info("starting instrumented 'fat' container...")
-	err = containerInspector.RunContainer()
-	if err != nil && containerInspector.DoShowContainerLogs {
-		containerInspector.ShowContainerLogs()
+	var containerInspector *container.Inspector
+	if !doStaticOnly {
+		var variantReports []string
+		containerInspector, variantReports = runContainerInspection(&containerInspectionParams{
+			xc:                        xc,
+			gparams:                   gparams,
+			logger:                    logger,
+			client:                    client,
+			cmdReport:                 cmdReport,
+			imageInspector:            imageInspector,
+			localVolumePath:           localVolumePath,
+			statePath:                 statePath,
+			artifactLocation:          artifactLocation,
+			targetRef:                 targetRef,
+			depServicesExe:            depServicesExe,
+			serviceAliases:            serviceAliases,
+			baseVolumesFrom:           baseVolumesFrom,
+			baseMounts:                baseMounts,
+			targetComposeSvc:          targetComposeSvc,
+			composeNets:               composeNets,
+			containerProbeComposeSvc:  containerProbeComposeSvc,
+			crOpts:                    crOpts,
+			doHTTPProbe:               doHTTPProbe,
+			httpProbeCmds:             httpProbeCmds,
+			httpProbeStartWait:        httpProbeStartWait,
+			httpProbeRetryCount:       httpProbeRetryCount,
+			httpProbeRetryWait:        httpProbeRetryWait,
+			httpProbePorts:            httpProbePorts,
+			httpCrawlMaxDepth:         httpCrawlMaxDepth,
+			httpCrawlMaxPageCount:     httpCrawlMaxPageCount,
+			httpCrawlConcurrency:      httpCrawlConcurrency,
+			httpMaxConcurrentCrawlers: httpMaxConcurrentCrawlers,
+			doHTTPProbeFull:           doHTTPProbeFull,
+			doHTTPProbeExitOnFailure:  doHTTPProbeExitOnFailure,
+			httpProbeAPISpecs:         httpProbeAPISpecs,
+			httpProbeAPISpecFiles:     httpProbeAPISpecFiles,
+			httpProbeApps:             httpProbeApps,
+			portBindings:              portBindings,
+			doPublishExposedPorts:     doPublishExposedPorts,
+			doRunTargetAsUser:         doRunTargetAsUser,
+			doShowContainerLogs:       doShowContainerLogs,
+			overrides:                 overrides,
+			links:                     links,
+			etcHostsMaps:              etcHostsMaps,
+			dnsServers:                dnsServers,
+			dnsSearchDomains:          dnsSearchDomains,
+			explicitVolumeMounts:      explicitVolumeMounts,
+			doKeepPerms:               doKeepPerms,
+			pathPerms:                 pathPerms,
+			excludePatterns:           excludePatterns,
+			preservePaths:             preservePaths,
+			includePaths:              includePaths,
+			includeBins:               includeBins,
+			includeExes:               includeExes,
+			runVariants:               runVariants,
+			doIncludeShell:            doIncludeShell,
+			doIncludeCertAll:          doIncludeCertAll,
+			doIncludeCertBundles:      doIncludeCertBundles,
+			doIncludeCertDirs:         doIncludeCertDirs,
+			doIncludeCertPKAll:        doIncludeCertPKAll,
+			doIncludeCertPKDirs:       doIncludeCertPKDirs,
+			doIncludeNew:              doIncludeNew,
+			doUseLocalMounts:          doUseLocalMounts,
+			doUseSensorVolume:         doUseSensorVolume,
+			doKeepTmpArtifacts:        doKeepTmpArtifacts,
+			continueAfter:             continueAfter,
+			execCmd:                   execCmd,
+			execFileCmd:               execFileCmd,
+			sensorIPCEndpoint:         sensorIPCEndpoint,
+			sensorIPCMode:             sensorIPCMode,
+			logLevel:                  logLevel,
+			logFormat:                 logFormat,
+			prefix:                    prefix,
+		})
+		mergeReports = append(mergeReports, variantReports...)
+	} else {
+		xc.Out.State("static.analysis.start")
+		err = runStaticAnalysis(
+			xc,
+			logger,
+			client,
+			imageInspector,
+			localVolumePath,
+			artifactLocation,
+			overrides,
+			instructions,
+			doKeepPerms,
+			excludePatterns,
+			includePaths,
+			includeBins,
+			includeExes,
+			doIncludeCertAll,
+			doIncludeCertBundles,
+			doIncludeCertDirs,
+			doIncludeCertPKAll,
+			doIncludeCertPKDirs,
+			cmdReport)
+		xc.FailOn(err)
+		xc.Out.State("static.analysis.done")
 	}
 
-	xc.FailOn(err)
+	if len(mergeReports) > 0 {
+		xc.Out.State("report.merge.start")
+		mergeResult, err := mergeContainerReports(
+			xc,
+			logger,
+			client,
+			imageInspector,
+			localVolumePath,
+			artifactLocation,
+			doKeepPerms,
+			excludePatterns,
+			mergeReports)
+		xc.FailOn(err)
 
-	containerName := containerInspector.ContainerName
-	containerID := containerInspector.ContainerID
-	inspectorCleanup := func() {
-		xc.Out.Info("container.inspector.cleanup",
+		cmdReport.MergedReports = mergeReports
+		xc.Out.Info("report.merge",
 			ovars{
-				"name": containerName,
-				"id":   containerID,
+				"reports":       mergeResult.Reports,
+				"files.added":   mergeResult.Added,
+				"files.missing": mergeResult.Missing,
 			})
 
-		if containerInspector != nil {
-			xc.Out.State("container.target.shutdown.start")
-			containerInspector.FinishMonitoring()
-			_ = containerInspector.ShutdownContainer()
-			xc.Out.State("container.target.shutdown.done")
-		}
+		xc.Out.State("report.merge.done")
 	}
 
-	xc.AddCleanupHandler(inspectorCleanup)
-
-	xc.Out.Info("container",
-		ovars{
-			"name":             containerInspector.ContainerName,
-			"id":               containerInspector.ContainerID,
-			"target.port.list": containerInspector.ContainerPortList,
-			"target.port.info": containerInspector.ContainerPortsInfo,
-			"message":          "YOU CAN USE THESE PORTS TO INTERACT WITH THE CONTAINER",
-		})
-
-	logger.Info("watching container monitor...")
-
-	if hasContinueAfterMode(continueAfter.Mode, config.CAMProbe) {
-		doHTTPProbe = true
-	}
+	explainKeptFiles(xc, logger, artifactLocation, explainPaths, cmdReport)
 
-	var probe *http.CustomProbe
-	if doHTTPProbe {
-		var err error
-		probe, err = http.NewCustomProbe(
+	if doRemovedFilesReport {
+		removedFiles, err := saveRemovedFilesReport(
 			xc,
-			containerInspector,
-			httpProbeCmds,
-			httpProbeStartWait,
-			httpProbeRetryCount,
-			httpProbeRetryWait,
-			httpProbePorts,
-			httpCrawlMaxDepth,
-			httpCrawlMaxPageCount,
-			httpCrawlConcurrency,
-			httpMaxConcurrentCrawlers,
-			doHTTPProbeFull,
-			doHTTPProbeExitOnFailure,
-			httpProbeAPISpecs,
-			httpProbeAPISpecFiles,
-			httpProbeApps,
-			true,
-			prefix)
-		xc.FailOn(err)
-
-		if len(probe.Ports) == 0 {
-			xc.Out.State("http.probe.error",
+			logger,
+			client,
+			imageInspector,
+			localVolumePath,
+			artifactLocation)
+		if err != nil {
+			logger.Errorf("error saving the removed files report - %v", err)
+			xc.Out.Info("removed.files",
 				ovars{
-					"error":   "no exposed ports",
-					"message": "expose your service port with --expose or disable HTTP probing with --http-probe=false if your containerized application doesnt expose any network services",
+					"message": "could not save the removed files report",
 				})
+		} else {
+			cmdReport.RemovedFilesReportName = report.DefaultRemovedFilesReportFileName
 
-			//note: should be handled by inspectorCleanup
-			//logger.Info("shutting down 'fat' container...")
-			//containerInspector.FinishMonitoring()
-			//_ = containerInspector.ShutdownContainer()
+			var packagesRemoved int
+			for _, p := range removedFiles.OSPackages {
+				if p.AllRemoved {
+					packagesRemoved++
+				}
+			}
 
-			exitCode := commands.ECTBuild | ecbImageBuildError
-			xc.Out.State("exited",
+			xc.Out.Info("removed.files",
 				ovars{
-					"exit.code": exitCode,
+					"count":                removedFiles.Count,
+					"size":                 removedFiles.SizeHuman,
+					"executables":          len(removedFiles.Executables),
+					"os.packages.removed":  packagesRemoved,
+					"os.packages.affected": len(removedFiles.OSPackages),
 				})
-
-			cmdReport.Error = "no.exposed.ports"
-			xc.Exit(exitCode)
 		}
-
-		probe.Start()
-		continueAfter.ContinueChan = probe.DoneChan()
-	}
-
-	continueAfterMsg := "provide the expected input to allow the container inspector to continue its execution"
-	if continueAfter.Mode == config.CAMTimeout {
-		continueAfterMsg = "no input required, execution will resume after the timeout"
-	}
-
-	if hasContinueAfterMode(continueAfter.Mode, config.CAMProbe) {
-		continueAfterMsg = "no input required, execution will resume when HTTP probing is completed"
 	}
 
-	xc.Out.Info("continue.after",
-		ovars{
-			"mode":    continueAfter.Mode,
-			"message": continueAfterMsg,
-		})
-
-	execFail := false
-
-	modes := strings.Split(continueAfter.Mode, "&")
-	for _, mode := range modes {
-		//should work for the most parts except
-		//when probe and signal are combined
-		//because both need channels (TODO: fix)
-		switch mode {
-		case config.CAMContainerProbe:
+	var seccompMissingCalls map[string][]string
+	var capabilitiesFileName string
+	var usedCapabilities []string
+	if !doStaticOnly {
+		logger.Info("processing instrumented 'fat' container info...")
+		err = containerInspector.ProcessCollectedData()
+		xc.FailOn(err)
 
-			idsToLog := map[string]string{}
-			idsToLog[targetRef] = containerInspector.ContainerID
-			for name, svc := range depServicesExe.RunningServices {
-				idsToLog[name] = svc.ID
-			}
-			//TODO:
-			//need a flag to control logs for dep services
-			//also good to leverage the logging capabilities in compose (TBD)
-			for name, id := range idsToLog {
-				name := name
-				id := id
-				go func() {
-					err := client.Logs(dockerapi.LogsOptions{
-						Container:    id,
-						OutputStream: NewLogWriter(name + "-stdout"),
-						ErrorStream:  NewLogWriter(name + "-stderr"),
-						Follow:       true,
-						Stdout:       true,
-						Stderr:       true,
-					})
-					xc.FailOn(err)
-				}()
-			}
+		var missingArchs []string
+		for arch := range containerInspector.SeccompMissingCalls {
+			missingArchs = append(missingArchs, arch)
+		}
 
-			svc, ok := depServicesExe.RunningServices[containerProbeComposeSvc]
-			if !ok {
-				xc.Out.State("error", ovars{"message": "container-prove-compose-svc not found in running services"})
-				xc.Exit(1)
-			}
-			for {
-				c, err := client.InspectContainerWithOptions(dockerapi.InspectContainerOptions{
-					ID: svc.ID,
+		sort.Strings(missingArchs)
+		for _, arch := range missingArchs {
+			xc.Out.Info("seccomp.arch.missing.syscalls",
+				ovars{
+					"arch":     arch,
+					"syscalls": strings.Join(containerInspector.SeccompMissingCalls[arch], ","),
 				})
-				xc.FailOn(err)
-				if c.State.Running {
-					xc.Out.Info("wait for container.probe to finish")
-				} else {
-					if c.State.ExitCode != 0 {
-						xc.Out.State("exited", ovars{"container.probe exit.code": c.State.ExitCode})
-						xc.Exit(1)
-					}
-					break
-				}
-				time.Sleep(1 * time.Second)
-			}
-		case config.CAMEnter:
-			xc.Out.Prompt("USER INPUT REQUIRED, PRESS <ENTER> WHEN YOU ARE DONE USING THE CONTAINER")
-			creader := bufio.NewReader(os.Stdin)
-			_, _, _ = creader.ReadLine()
-		case config.CAMExec:
-			var input *bytes.Buffer
-			var cmd []string
-			if len(execFileCmd) != 0 {
-				input = bytes.NewBufferString(execFileCmd)
-				cmd = []string{"sh", "-s"}
-				for _, line := range strings.Split(string(execFileCmd), "\n") {
-					xc.Out.Info("continue.after",
-						ovars{
-							"mode":  config.CAMExec,
-							"shell": line,
-						})
-				}
-			} else {
-				input = bytes.NewBufferString("")
-				cmd = []string{"sh", "-c", execCmd}
-				xc.Out.Info("continue.after",
-					ovars{
-						"mode":  config.CAMExec,
-						"shell": execCmd,
-					})
-			}
-			exec, err := containerInspector.APIClient.CreateExec(dockerapi.CreateExecOptions{
-				Container:    containerInspector.ContainerID,
-				Cmd:          cmd,
-				AttachStdin:  true,
-				AttachStdout: true,
-				AttachStderr: true,
-			})
-			xc.FailOn(err)
-
-			buffer := &printbuffer.PrintBuffer{Prefix: fmt.Sprintf("%s[%s][exec]: output:", appName, cmdName)}
-			xc.FailOn(containerInspector.APIClient.StartExec(exec.ID, dockerapi.StartExecOptions{
-				InputStream:  input,
-				OutputStream: buffer,
-				ErrorStream:  buffer,
-			}))
+		}
 
-			inspect, err := containerInspector.APIClient.InspectExec(exec.ID)
-			xc.FailOn(err)
-			errutil.FailWhen(inspect.Running, "still running")
-			if inspect.ExitCode != 0 {
-				execFail = true
-			}
+		seccompMissingCalls = containerInspector.SeccompMissingCalls
 
-			xc.Out.Info("continue.after",
-				ovars{
-					"mode":     config.CAMExec,
-					"exitcode": inspect.ExitCode,
-				})
-		case config.CAMSignal:
-			xc.Out.Prompt("send SIGUSR1 when you are done using the container")
-			<-continueAfter.ContinueChan
-			xc.Out.Info("event",
-				ovars{
-					"message": "got SIGUSR1",
-				})
-		case config.CAMTimeout:
-			xc.Out.Prompt(fmt.Sprintf("waiting for the target container (%v seconds)", int(continueAfter.Timeout)))
-			<-time.After(time.Second * continueAfter.Timeout)
-			xc.Out.Info("event",
-				ovars{
-					"message": "done waiting for the target container",
-				})
-		case config.CAMProbe:
-			xc.Out.Prompt("waiting for the HTTP probe to finish")
-			<-continueAfter.ContinueChan
-			xc.Out.Info("event",
+		if recs := containerInspector.Capabilities; recs != nil {
+			xc.Out.Info("capabilities",
 				ovars{
-					"message": "HTTP probe is done",
+					"used":             strings.Join(recs.Capabilities, ","),
+					"docker.run.flags": recs.DockerRunFlags,
 				})
 
-			if probe != nil && probe.CallCount > 0 && probe.OkCount == 0 {
-				//make sure we show the container logs because none of the http probe calls were successful
-				containerInspector.DoShowContainerLogs = true
-			}
-		default:
-			errutil.Fail("unknown continue-after mode")
+			capabilitiesFileName = imageInspector.CapabilitiesFileName
+			usedCapabilities = recs.Capabilities
 		}
 	}
 
-	xc.Out.State("container.inspection.finishing")
-
-	containerInspector.FinishMonitoring()
-
-	logger.Info("shutting down 'fat' container...")
-	err = containerInspector.ShutdownContainer()
-	errutil.WarnOn(err)
-
-	if execFail {
-		xc.Out.Info("continue.after",
-			ovars{
-				"mode":    config.CAMExec,
-				"message": "fatal: exec cmd failure",
-			})
-
-		exitCode := 1
-		xc.Out.State("exited",
-			ovars{
-				"exit.code": exitCode,
-			})
-
-		cmdReport.Error = "exec.cmd.failure"
-		xc.Exit(exitCode)
-	}
-
-	if depServicesExe != nil {
-		xc.Out.State("container.dependencies.shutdown.start")
-		err = depServicesExe.Stop()
-		errutil.WarnOn(err)
-		err = depServicesExe.Cleanup()
-		errutil.WarnOn(err)
-		xc.Out.State("container.dependencies.shutdown.done")
-	}
-
-	xc.Out.State("container.inspection.artifact.processing")
-
-	if !containerInspector.HasCollectedData() {
-		imageInspector.ShowFatImageDockerInstructions()
-		xc.Out.Info("results",
-			ovars{
-				"status":   "no data collected (no minified image generated)",
-				"version":  v.Current(),
-				"location": fsutil.ExeDir(),
-			})
-
-		exitCode := commands.ECTBuild | ecbImageBuildError
-		xc.Out.State("exited",
-			ovars{
-				"exit.code": exitCode,
-			})
-
-		cmdReport.Error = "no.data.collected"
-		xc.Exit(exitCode)
-	}
-
-	logger.Info("processing instrumented 'fat' container info...")
-	err = containerInspector.ProcessCollectedData()
-	xc.FailOn(err)
-
 	if customImageTag == "" {
 		customImageTag = imageInspector.SlimImageRepo
 	}
@@ -1287,6 +1012,7 @@ func OnCommand(
 			"message": "building optimized image",
 		})
 
+	var assembledImage *builder.AssembledImage
 	builder, err := builder.NewImageBuilder(client,
 		customImageTag,
 		additionalTags,
@@ -1302,7 +1028,11 @@ func OnCommand(
 		logger.Info("WARNING - no data artifacts")
 	}
 
-	err = builder.Build()
+	if ibOpts.Engine == config.IBEInternal {
+		assembledImage, err = builder.Assemble(ibOpts)
+	} else {
+		err = builder.Build()
+	}
 
 	if doShowBuildLogs || err != nil {
 		xc.Out.LogDump("optimized.image.build", builder.BuildLog.String(),
@@ -1330,6 +1060,24 @@ func OnCommand(
 		xc.Exit(exitCode)
 	}
 
+	cmdReport.ImageBuildEngine = ibOpts.Engine
+	if assembledImage != nil {
+		cmdReport.MinifiedImageID = assembledImage.ID
+		cmdReport.MinifiedImageDigest = assembledImage.Digest
+		cmdReport.MinifiedImageOCILayout = assembledImage.OCILayoutPath
+		cmdReport.MinifiedImageArchive = assembledImage.ArchivePath
+
+		xc.Out.Info("image.build.output",
+			ovars{
+				"engine":     ibOpts.Engine,
+				"id":         assembledImage.ID,
+				"digest":     assembledImage.Digest,
+				"oci.layout": assembledImage.OCILayoutPath,
+				"archive":    assembledImage.ArchivePath,
+				"loaded":     assembledImage.Loaded,
+			})
+	}
+
 	xc.Out.State("completed")
 	cmdReport.State = command.StateCompleted
 
@@ -1350,31 +1098,47 @@ func OnCommand(
 	}
 
 	/////////////////////////////
-	newImageInspector, err := image.NewInspector(client, builder.RepoName)
-	xc.FailOn(err)
+	var minifiedImageSize int64
+	var minifiedImageID string
+	if assembledImage != nil {
+		minifiedImageID = assembledImage.ID
+	}
 
-	if newImageInspector.NoImage() {
-		xc.Out.Info("results",
-			ovars{
-				"message": "minified image not found",
-				"image":   builder.RepoName,
-			})
+	if ibOpts.LoadToDocker {
+		newImageInspector, ierr := image.NewInspector(client, builder.RepoName)
+		xc.FailOn(ierr)
 
-		exitCode := commands.ECTBuild | ecbImageBuildError
-		xc.Out.State("exited",
-			ovars{
-				"exit.code": exitCode,
-			})
+		if newImageInspector.NoImage() {
+			xc.Out.Info("results",
+				ovars{
+					"message": "minified image not found",
+					"image":   builder.RepoName,
+				})
 
-		cmdReport.Error = "minified.image.not.found"
-		xc.Exit(exitCode)
-	}
+			exitCode := commands.ECTBuild | ecbImageBuildError
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+				})
 
-	err = newImageInspector.Inspect()
-	errutil.WarnOn(err)
+			cmdReport.Error = "minified.image.not.found"
+			xc.Exit(exitCode)
+		}
+
+		err = newImageInspector.Inspect()
+		errutil.WarnOn(err)
+
+		if err == nil {
+			minifiedImageSize = newImageInspector.ImageInfo.VirtualSize
+			minifiedImageID = newImageInspector.ImageInfo.ID
+		}
+	} else {
+		//the assembled image is not in Docker
+		minifiedImageSize = assembledImage.Size
+	}
 
 	if err == nil {
-		cmdReport.MinifiedBy = float64(imageInspector.ImageInfo.VirtualSize) / float64(newImageInspector.ImageInfo.VirtualSize)
+		cmdReport.MinifiedBy = float64(imageInspector.ImageInfo.VirtualSize) / float64(minifiedImageSize)
 		imgIdentity := dockerutil.ImageToIdentity(imageInspector.ImageInfo)
 		cmdReport.SourceImage = report.ImageMetadata{
 			Identity: report.ImageIdentity{
@@ -1405,8 +1169,8 @@ func OnCommand(
 		cmdReport.SourceImage.Labels = imageInspector.ImageInfo.Config.Labels
 		cmdReport.SourceImage.EnvVars = imageInspector.ImageInfo.Config.Env
 
-		cmdReport.MinifiedImageSize = newImageInspector.ImageInfo.VirtualSize
-		cmdReport.MinifiedImageSizeHuman = humanize.Bytes(uint64(newImageInspector.ImageInfo.VirtualSize))
+		cmdReport.MinifiedImageSize = minifiedImageSize
+		cmdReport.MinifiedImageSizeHuman = humanize.Bytes(uint64(minifiedImageSize))
 
 		xc.Out.Info("results",
 			ovars{
@@ -1425,7 +1189,10 @@ func OnCommand(
 	cmdReport.ArtifactLocation = imageInspector.ArtifactLocation
 	cmdReport.ContainerReportName = report.DefaultContainerReportFileName
 	cmdReport.SeccompProfileName = imageInspector.SeccompProfileName
+	cmdReport.SeccompMissingSyscalls = seccompMissingCalls
 	cmdReport.AppArmorProfileName = imageInspector.AppArmorProfileName
+	cmdReport.CapabilitiesFileName = capabilitiesFileName
+	cmdReport.Capabilities = usedCapabilities
 
 	xc.Out.Info("results",
 		ovars{
@@ -1464,6 +1231,20 @@ func OnCommand(
 			"artifacts.apparmor": cmdReport.AppArmorProfileName,
 		})
 
+	if cmdReport.CapabilitiesFileName != "" {
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.capabilities": cmdReport.CapabilitiesFileName,
+			})
+	}
+
+	if cmdReport.RemovedFilesReportName != "" {
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.removed.files": cmdReport.RemovedFilesReportName,
+			})
+	}
+
 	if cmdReport.ArtifactLocation != "" {
 		creportPath := filepath.Join(cmdReport.ArtifactLocation, cmdReport.ContainerReportName)
 		if creportData, err := ioutil.ReadFile(creportPath); err == nil {
@@ -1474,6 +1255,26 @@ func OnCommand(
 					Release: creport.System.Release,
 					Distro:  creport.System.Distro,
 				}
+
+				if len(sbomFormats) > 0 {
+					sbomImage := sbom.Image{
+						Name:         cmdReport.MinifiedImage,
+						ID:           minifiedImageID,
+						Digest:       cmdReport.MinifiedImageDigest,
+						OS:           imageInspector.ImageInfo.OS,
+						Architecture: imageInspector.ImageInfo.Architecture,
+						Distro:       creport.System.Distro.Name,
+					}
+
+					doc := newMinifiedImageSBOM(sbomImage, &creport)
+					cmdReport.SBOMFiles = commands.SaveSBOMs(logger, doc, sbomFormats, cmdReport.ArtifactLocation)
+					for _, name := range cmdReport.SBOMFiles {
+						xc.Out.Info("results",
+							ovars{
+								"artifacts.sbom": name,
+							})
+					}
+				}
 			} else {
 				logger.Infof("could not read container report - json parsing error - %v", err)
 			}
@@ -1483,6 +1284,30 @@ func OnCommand(
 
 	}
 
+	if !doStaticOnly && cmdReport.State != command.StateError {
+		k8sArtifacts, err := kubernetes.GenArtifacts(cmdReport.ArtifactLocation,
+			&kubernetes.Options{
+				Name:               imageInspector.KubernetesName,
+				Image:              cmdReport.MinifiedImage,
+				User:               containerInspector.AppUser(),
+				Volumes:            cmdReport.SourceImage.Volumes,
+				ExposedPorts:       cmdReport.SourceImage.ExposedPorts,
+				SeccompProfileName: cmdReport.SeccompProfileName,
+				FilesDirName:       container.FileArtifactsDirName,
+			})
+		if err != nil {
+			logger.Errorf("error generating Kubernetes artifacts - %v", err)
+		}
+
+		cmdReport.KubernetesArtifacts = k8sArtifacts
+		for _, name := range cmdReport.KubernetesArtifacts {
+			xc.Out.Info("results",
+				ovars{
+					"artifacts.kubernetes": name,
+				})
+		}
+	}
+
 	/////////////////////////////
 	if copyMetaArtifactsLocation != "" {
 		toCopy := []string{
@@ -1490,6 +1315,21 @@ func OnCommand(
 			imageInspector.SeccompProfileName,
 			imageInspector.AppArmorProfileName,
 		}
+		toCopy = append(toCopy, cmdReport.SBOMFiles...)
+		if cmdReport.RemovedFilesReportName != "" {
+			toCopy = append(toCopy, cmdReport.RemovedFilesReportName)
+		}
+
+		if len(cmdReport.StaticIncludes) > 0 {
+			toCopy = append(toCopy, staticIncludesFileName)
+		}
+
+		if cmdReport.CapabilitiesFileName != "" {
+			toCopy = append(toCopy, cmdReport.CapabilitiesFileName)
+		}
+
+		toCopy = append(toCopy, cmdReport.KubernetesArtifacts...)
+
 		if !commands.CopyMetaArtifacts(logger,
 			toCopy,
 			artifactLocation, copyMetaArtifactsLocation) {
@@ -1534,6 +1374,671 @@ func OnCommand(
 	}
 }
 
+// containerInspectionParams are the parameters for the container inspection runs
+type containerInspectionParams struct {
+	xc                        *app.ExecutionContext
+	gparams                   *commands.GenericParams
+	logger                    *log.Entry
+	client                    *dockerapi.Client
+	cmdReport                 *report.BuildCommand
+	imageInspector            *image.Inspector
+	localVolumePath           string
+	statePath                 string
+	artifactLocation          string
+	targetRef                 string
+	depServicesExe            *compose.Execution
+	serviceAliases            []string
+	baseVolumesFrom           []string
+	baseMounts                []dockerapi.HostMount
+	targetComposeSvc          string
+	composeNets               []string
+	containerProbeComposeSvc  string
+	crOpts                    *config.ContainerRunOptions
+	doHTTPProbe               bool
+	httpProbeCmds             []config.HTTPProbeCmd
+	httpProbeStartWait        int
+	httpProbeRetryCount       int
+	httpProbeRetryWait        int
+	httpProbePorts            []uint16
+	httpCrawlMaxDepth         int
+	httpCrawlMaxPageCount     int
+	httpCrawlConcurrency      int
+	httpMaxConcurrentCrawlers int
+	doHTTPProbeFull           bool
+	doHTTPProbeExitOnFailure  bool
+	httpProbeAPISpecs         []string
+	httpProbeAPISpecFiles     []string
+	httpProbeApps             []string
+	portBindings              map[dockerapi.Port][]dockerapi.PortBinding
+	doPublishExposedPorts     bool
+	doRunTargetAsUser         bool
+	doShowContainerLogs       bool
+	overrides                 *config.ContainerOverrides
+	links                     []string
+	etcHostsMaps              []string
+	dnsServers                []string
+	dnsSearchDomains          []string
+	explicitVolumeMounts      map[string]config.VolumeMount
+	doKeepPerms               bool
+	pathPerms                 map[string]*fsutil.AccessInfo
+	excludePatterns           map[string]*fsutil.AccessInfo
+	preservePaths             map[string]*fsutil.AccessInfo
+	includePaths              map[string]*fsutil.AccessInfo
+	includeBins               map[string]*fsutil.AccessInfo
+	includeExes               map[string]*fsutil.AccessInfo
+	runVariants               []*runVariant
+	doIncludeShell            bool
+	doIncludeCertAll          bool
+	doIncludeCertBundles      bool
+	doIncludeCertDirs         bool
+	doIncludeCertPKAll        bool
+	doIncludeCertPKDirs       bool
+	doIncludeNew              bool
+	doUseLocalMounts          bool
+	doUseSensorVolume         string
+	doKeepTmpArtifacts        bool
+	continueAfter             *config.ContinueAfter
+	execCmd                   string
+	execFileCmd               string
+	sensorIPCEndpoint         string
+	sensorIPCMode             string
+	logLevel                  string
+	logFormat                 string
+	prefix                    string
+}
+
+// runContainerInspection runs the instrumented target container (once for each run variant and then for the main run)
+// and returns the container inspector for the main run with the saved container reports for the run variants
+func runContainerInspection(p *containerInspectionParams) (*container.Inspector, []string) {
+	const cmdName = Name
+	var err error
+	var containerInspector *container.Inspector
+	var variantReports []string
+
+	//validate links (check if target container exists, ignore&log if not)
+	svcLinkMap := map[string]struct{}{}
+	for _, linkInfo := range p.links {
+		svcLinkMap[linkInfo] = struct{}{}
+	}
+
+	selectedNetNames := map[string]compose.NetNameInfo{}
+	if p.depServicesExe != nil {
+		p.xc.Out.State("container.dependencies.init.start")
+		err = p.depServicesExe.Prepare()
+		p.xc.FailOn(err)
+		err = p.depServicesExe.Start()
+		if err != nil {
+			p.depServicesExe.Stop()
+			p.depServicesExe.Cleanup()
+		}
+		p.xc.FailOn(err)
+
+		exeCleanup := func() {
+			if p.depServicesExe != nil {
+				p.xc.Out.State("container.dependencies.shutdown.start")
+				err = p.depServicesExe.Stop()
+				errutil.WarnOn(err)
+				err = p.depServicesExe.Cleanup()
+				errutil.WarnOn(err)
+				p.xc.Out.State("container.dependencies.shutdown.done")
+			}
+		}
+
+		p.xc.AddCleanupHandler(exeCleanup)
+
+		//todo:
+		//need a better way to make sure the dependencies are ready
+		//monitor docker events
+		//use basic application level checks (probing)
+		time.Sleep(3 * time.Second)
+		p.xc.Out.State("container.dependencies.init.done")
+
+		//might need more info (including alias info) when targeting compose services
+		allNetNames := p.depServicesExe.ActiveNetworkNames()
+
+		if p.targetComposeSvc != "" {
+			//if we are targetting a compose service and
+			//we have explicitly selected compose networks (composeNets)
+			//we use the selected subset of the configured networks for the target service
+			composeNetsSet := map[string]struct{}{}
+			for _, key := range p.composeNets {
+				composeNetsSet[key] = struct{}{}
+			}
+
+			svcNets := p.depServicesExe.ActiveServiceNetworks(p.targetComposeSvc)
+			for key, netNameInfo := range svcNets {
+				if len(p.composeNets) > 0 {
+					if _, found := composeNetsSet[key]; !found {
+						continue
+					}
+				}
+
+				selectedNetNames[key] = netNameInfo
+			}
+		} else {
+			//we are not targetting a compose service,
+			//but we do want to connect to the networks in compose
+			if len(p.composeNets) > 0 {
+				for _, key := range p.composeNets {
+					if net, found := allNetNames[key]; found {
+						selectedNetNames[key] = compose.NetNameInfo{
+							FullName: net,
+							//Aliases: serviceAliases, - we merge serviceAliases later
+						}
+					}
+				}
+			} else {
+				//select/use all networks if specific networks are not selected
+				for key, fullName := range allNetNames {
+					selectedNetNames[key] = compose.NetNameInfo{
+						FullName: fullName,
+						//Aliases: serviceAliases, - we merge serviceAliases later
+					}
+				}
+			}
+		}
+	}
+
+	p.links = []string{} //reset&reuse
+	if p.targetComposeSvc != "" && p.depServicesExe != nil {
+		targetSvcInfo := p.depServicesExe.Service(p.targetComposeSvc)
+		//convert service links to container links (after deps are started)
+		targetSvcLinkMap := map[string]struct{}{}
+		for _, linkInfo := range targetSvcInfo.Config.Links {
+			var linkTarget string
+			var linkName string
+			parts := strings.Split(linkInfo, ":")
+			switch len(parts) {
+			case 1:
+				linkTarget = parts[0]
+				linkName = parts[0]
+			case 2:
+				linkTarget = parts[0]
+				linkName = parts[1]
+			default:
+				p.logger.Debugf("targetSvcInfo.Config.Links: malformed link - %s", linkInfo)
+				continue
+			}
+
+			linkSvcInfo := p.depServicesExe.Service(linkTarget)
+			if linkSvcInfo == nil {
+				p.logger.Debugf("targetSvcInfo.Config.Links: unknown service in link - %s", linkInfo)
+				continue
+			}
+
+			p.logger.Debugf("targetSvcInfo.Config.Links: linkInfo=%s linkSvcInfo=%#v", linkInfo, linkSvcInfo)
+			if linkSvcInfo.ContainerName == "" {
+				p.logger.Debugf("targetSvcInfo.Config.Links: no container name - linkInfo=%s", linkInfo)
+				continue
+			}
+
+			clink := fmt.Sprintf("%s:%s", linkSvcInfo.ContainerName, linkSvcInfo.ContainerName)
+			targetSvcLinkMap[clink] = struct{}{}
+			clink = fmt.Sprintf("%s:%s", linkSvcInfo.ContainerName, linkName)
+			targetSvcLinkMap[clink] = struct{}{}
+		}
+
+		for k := range targetSvcLinkMap {
+			p.links = append(p.links, k)
+		}
+	}
+
+	for k := range svcLinkMap {
+		p.links = append(p.links, k)
+	}
+
+	selectedNetworks := map[string]container.NetNameInfo{}
+	for key, info := range selectedNetNames {
+		aset := map[string]struct{}{}
+		for _, a := range info.Aliases {
+			aset[a] = struct{}{}
+		}
+
+		//merge serviceAliases with the main set of aliases
+		for _, a := range p.serviceAliases {
+			aset[a] = struct{}{}
+		}
+		var alist []string
+		for a := range aset {
+			alist = append(alist, a)
+		}
+
+		selectedNetworks[key] = container.NetNameInfo{
+			Name:     key,
+			FullName: info.FullName,
+			Aliases:  alist,
+		}
+	}
+
+	//the extra run variants go first, so the main run artifacts are the last ones collected
+	for runIdx := 0; runIdx <= len(p.runVariants); runIdx++ {
+		runOverrides := p.overrides
+		runContinueAfter := p.continueAfter
+		runExecCmd := p.execCmd
+		runExecFileCmd := p.execFileCmd
+		runDoHTTPProbe := p.doHTTPProbe
+
+		var variant *runVariant
+		if runIdx < len(p.runVariants) {
+			variant = p.runVariants[runIdx]
+			p.xc.Out.Info("run.variant",
+				ovars{
+					"index":   runIdx + 1,
+					"count":   len(p.runVariants),
+					"variant": variant.Name(),
+				})
+
+			if len(variant.Cmd) > 0 {
+				variantOverrides := *p.overrides
+				variantOverrides.Cmd = variant.Cmd
+				variantOverrides.ClearCmd = false
+				runOverrides = &variantOverrides
+			}
+
+			if variant.ExecCmd != "" {
+				runContinueAfter = &config.ContinueAfter{Mode: config.CAMExec}
+				runExecCmd = variant.ExecCmd
+				runExecFileCmd = ""
+				runDoHTTPProbe = false
+			}
+		}
+
+		p.xc.Out.State("container.inspection.start")
+
+		hasClassicLinks := true
+		if p.targetComposeSvc != "" ||
+			len(p.composeNets) > 0 ||
+			p.overrides.Network != "" {
+			hasClassicLinks = false
+		}
+
+		containerInspector, err = container.NewInspector(
+			p.xc,
+			p.crOpts,
+			p.logger,
+			p.client,
+			p.statePath,
+			p.imageInspector,
+			p.localVolumePath,
+			p.doUseLocalMounts,
+			p.doUseSensorVolume,
+			p.doKeepTmpArtifacts,
+			runOverrides,
+			p.explicitVolumeMounts,
+			p.baseMounts,
+			p.baseVolumesFrom,
+			p.portBindings,
+			p.doPublishExposedPorts,
+			hasClassicLinks,
+			p.links,
+			p.etcHostsMaps,
+			p.dnsServers,
+			p.dnsSearchDomains,
+			p.doRunTargetAsUser,
+			p.doShowContainerLogs,
+			p.doKeepPerms,
+			p.pathPerms,
+			p.excludePatterns,
+			p.preservePaths,
+			p.includePaths,
+			p.includeBins,
+			p.includeExes,
+			p.doIncludeShell,
+			p.doIncludeCertAll,
+			p.doIncludeCertBundles,
+			p.doIncludeCertDirs,
+			p.doIncludeCertPKAll,
+			p.doIncludeCertPKDirs,
+			p.doIncludeNew,
+			selectedNetworks,
+			p.gparams.Debug,
+			p.logLevel,
+			p.logFormat,
+			p.gparams.InContainer,
+			p.sensorIPCEndpoint,
+			p.sensorIPCMode,
+			true,
+			p.prefix)
+		p.xc.FailOn(err)
+
+		if len(containerInspector.FatContainerCmd) == 0 {
+			p.xc.Out.Info("target.image.error",
+				ovars{
+					"status":  "no.entrypoint.cmd",
+					"image":   p.targetRef,
+					"message": "no ENTRYPOINT/CMD",
+				})
+
+			exitCode := commands.ECTBuild | ecbNoEntrypoint
+			p.xc.Out.State("exited", ovars{"exit.code": exitCode})
+
+			p.cmdReport.Error = "no.entrypoint.cmd"
+			p.xc.Exit(exitCode)
+		}
+
+		p.logger.Info("starting instrumented 'fat' container...")
+		err = containerInspector.RunContainer()
+		if err != nil && containerInspector.DoShowContainerLogs {
+			containerInspector.ShowContainerLogs()
+		}
+
+		p.xc.FailOn(err)
+
+		containerName := containerInspector.ContainerName
+		containerID := containerInspector.ContainerID
+		inspectorCleanup := func() {
+			p.xc.Out.Info("container.inspector.cleanup",
+				ovars{
+					"name": containerName,
+					"id":   containerID,
+				})
+
+			if containerInspector != nil {
+				p.xc.Out.State("container.target.shutdown.start")
+				containerInspector.FinishMonitoring()
+				_ = containerInspector.ShutdownContainer()
+				p.xc.Out.State("container.target.shutdown.done")
+			}
+		}
+
+		p.xc.AddCleanupHandler(inspectorCleanup)
+
+		p.xc.Out.Info("container",
+			ovars{
+				"name":             containerInspector.ContainerName,
+				"id":               containerInspector.ContainerID,
+				"target.port.list": containerInspector.ContainerPortList,
+				"target.port.info": containerInspector.ContainerPortsInfo,
+				"message":          "YOU CAN USE THESE PORTS TO INTERACT WITH THE CONTAINER",
+			})
+
+		p.logger.Info("watching container monitor...")
+
+		if hasContinueAfterMode(runContinueAfter.Mode, config.CAMProbe) {
+			runDoHTTPProbe = true
+		}
+
+		var probe *http.CustomProbe
+		if runDoHTTPProbe {
+			var err error
+			probe, err = http.NewCustomProbe(
+				p.xc,
+				containerInspector,
+				p.httpProbeCmds,
+				p.httpProbeStartWait,
+				p.httpProbeRetryCount,
+				p.httpProbeRetryWait,
+				p.httpProbePorts,
+				p.httpCrawlMaxDepth,
+				p.httpCrawlMaxPageCount,
+				p.httpCrawlConcurrency,
+				p.httpMaxConcurrentCrawlers,
+				p.doHTTPProbeFull,
+				p.doHTTPProbeExitOnFailure,
+				p.httpProbeAPISpecs,
+				p.httpProbeAPISpecFiles,
+				p.httpProbeApps,
+				true,
+				p.prefix)
+			p.xc.FailOn(err)
+
+			if len(probe.Ports) == 0 {
+				p.xc.Out.State("http.probe.error",
+					ovars{
+						"error":   "no exposed ports",
+						"message": "expose your service port with --expose or disable HTTP probing with --http-probe=false if your containerized application doesnt expose any network services",
+					})
+
+				//note: should be handled by inspectorCleanup
+				//logger.Info("shutting down 'fat' container...")
+				//containerInspector.FinishMonitoring()
+				//_ = containerInspector.ShutdownContainer()
+
+				exitCode := commands.ECTBuild | ecbImageBuildError
+				p.xc.Out.State("exited",
+					ovars{
+						"exit.code": exitCode,
+					})
+
+				p.cmdReport.Error = "no.exposed.ports"
+				p.xc.Exit(exitCode)
+			}
+
+			probe.Start()
+			runContinueAfter.ContinueChan = probe.DoneChan()
+		}
+
+		continueAfterMsg := "provide the expected input to allow the container inspector to continue its execution"
+		if runContinueAfter.Mode == config.CAMTimeout {
+			continueAfterMsg = "no input required, execution will resume after the timeout"
+		}
+
+		if hasContinueAfterMode(runContinueAfter.Mode, config.CAMProbe) {
+			continueAfterMsg = "no input required, execution will resume when HTTP probing is completed"
+		}
+
+		p.xc.Out.Info("continue.after",
+			ovars{
+				"mode":    runContinueAfter.Mode,
+				"message": continueAfterMsg,
+			})
+
+		execFail := false
+
+		modes := strings.Split(runContinueAfter.Mode, "&")
+		for _, mode := range modes {
+			//should work for the most parts except
+			//when probe and signal are combined
+			//because both need channels (TODO: fix)
+			switch mode {
+			case config.CAMContainerProbe:
+
+				idsToLog := map[string]string{}
+				idsToLog[p.targetRef] = containerInspector.ContainerID
+				for name, svc := range p.depServicesExe.RunningServices {
+					idsToLog[name] = svc.ID
+				}
+				//TODO:
+				//need a flag to control logs for dep services
+				//also good to leverage the logging capabilities in compose (TBD)
+				for name, id := range idsToLog {
+					name := name
+					id := id
+					go func() {
+						err := p.client.Logs(dockerapi.LogsOptions{
+							Container:    id,
+							OutputStream: NewLogWriter(name + "-stdout"),
+							ErrorStream:  NewLogWriter(name + "-stderr"),
+							Follow:       true,
+							Stdout:       true,
+							Stderr:       true,
+						})
+						p.xc.FailOn(err)
+					}()
+				}
+
+				svc, ok := p.depServicesExe.RunningServices[p.containerProbeComposeSvc]
+				if !ok {
+					p.xc.Out.State("error", ovars{"message": "container-prove-compose-svc not found in running services"})
+					p.xc.Exit(1)
+				}
+				for {
+					c, err := p.client.InspectContainerWithOptions(dockerapi.InspectContainerOptions{
+						ID: svc.ID,
+					})
+					p.xc.FailOn(err)
+					if c.State.Running {
+						p.xc.Out.Info("wait for container.probe to finish")
+					} else {
+						if c.State.ExitCode != 0 {
+							p.xc.Out.State("exited", ovars{"container.probe exit.code": c.State.ExitCode})
+							p.xc.Exit(1)
+						}
+						break
+					}
+					time.Sleep(1 * time.Second)
+				}
+			case config.CAMEnter:
+				p.xc.Out.Prompt("USER INPUT REQUIRED, PRESS <ENTER> WHEN YOU ARE DONE USING THE CONTAINER")
+				creader := bufio.NewReader(os.Stdin)
+				_, _, _ = creader.ReadLine()
+			case config.CAMExec:
+				var input *bytes.Buffer
+				var cmd []string
+				if len(runExecFileCmd) != 0 {
+					input = bytes.NewBufferString(runExecFileCmd)
+					cmd = []string{"sh", "-s"}
+					for _, line := range strings.Split(string(runExecFileCmd), "\n") {
+						p.xc.Out.Info("continue.after",
+							ovars{
+								"mode":  config.CAMExec,
+								"shell": line,
+							})
+					}
+				} else {
+					input = bytes.NewBufferString("")
+					cmd = []string{"sh", "-c", runExecCmd}
+					p.xc.Out.Info("continue.after",
+						ovars{
+							"mode":  config.CAMExec,
+							"shell": runExecCmd,
+						})
+				}
+				exec, err := containerInspector.APIClient.CreateExec(dockerapi.CreateExecOptions{
+					Container:    containerInspector.ContainerID,
+					Cmd:          cmd,
+					AttachStdin:  true,
+					AttachStdout: true,
+					AttachStderr: true,
+				})
+				p.xc.FailOn(err)
+
+				buffer := &printbuffer.PrintBuffer{Prefix: fmt.Sprintf("%s[%s][exec]: output:", appName, cmdName)}
+				p.xc.FailOn(containerInspector.APIClient.StartExec(exec.ID, dockerapi.StartExecOptions{
+					InputStream:  input,
+					OutputStream: buffer,
+					ErrorStream:  buffer,
+				}))
+
+				inspect, err := containerInspector.APIClient.InspectExec(exec.ID)
+				p.xc.FailOn(err)
+				errutil.FailWhen(inspect.Running, "still running")
+				if inspect.ExitCode != 0 {
+					execFail = true
+				}
+
+				p.xc.Out.Info("continue.after",
+					ovars{
+						"mode":     config.CAMExec,
+						"exitcode": inspect.ExitCode,
+					})
+			case config.CAMSignal:
+				p.xc.Out.Prompt("send SIGUSR1 when you are done using the container")
+				<-runContinueAfter.ContinueChan
+				p.xc.Out.Info("event",
+					ovars{
+						"message": "got SIGUSR1",
+					})
+			case config.CAMTimeout:
+				p.xc.Out.Prompt(fmt.Sprintf("waiting for the target container (%v seconds)", int(runContinueAfter.Timeout)))
+				<-time.After(time.Second * runContinueAfter.Timeout)
+				p.xc.Out.Info("event",
+					ovars{
+						"message": "done waiting for the target container",
+					})
+			case config.CAMProbe:
+				p.xc.Out.Prompt("waiting for the HTTP probe to finish")
+				<-runContinueAfter.ContinueChan
+				p.xc.Out.Info("event",
+					ovars{
+						"message": "HTTP probe is done",
+					})
+
+				if probe != nil && probe.CallCount > 0 && probe.OkCount == 0 {
+					//make sure we show the container logs because none of the http probe calls were successful
+					containerInspector.DoShowContainerLogs = true
+				}
+			default:
+				errutil.Fail("unknown continue-after mode")
+			}
+		}
+
+		p.xc.Out.State("container.inspection.finishing")
+
+		containerInspector.FinishMonitoring()
+
+		p.logger.Info("shutting down 'fat' container...")
+		err = containerInspector.ShutdownContainer()
+		errutil.WarnOn(err)
+
+		if execFail {
+			p.xc.Out.Info("continue.after",
+				ovars{
+					"mode":    config.CAMExec,
+					"message": "fatal: exec cmd failure",
+				})
+
+			exitCode := 1
+			p.xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+				})
+
+			p.cmdReport.Error = "exec.cmd.failure"
+			p.xc.Exit(exitCode)
+		}
+
+		if variant != nil {
+			if !containerInspector.HasCollectedData() {
+				p.xc.Out.Info("run.variant",
+					ovars{
+						"index":   runIdx + 1,
+						"variant": variant.Name(),
+						"status":  "no data collected",
+					})
+
+				continue
+			}
+
+			variantReportPath, err := saveVariantReport(p.artifactLocation, runIdx+1)
+			p.xc.FailOn(err)
+			variantReports = append(variantReports, variantReportPath)
+		}
+	}
+
+	if p.depServicesExe != nil {
+		p.xc.Out.State("container.dependencies.shutdown.start")
+		err = p.depServicesExe.Stop()
+		errutil.WarnOn(err)
+		err = p.depServicesExe.Cleanup()
+		errutil.WarnOn(err)
+		p.xc.Out.State("container.dependencies.shutdown.done")
+	}
+
+	p.xc.Out.State("container.inspection.artifact.processing")
+
+	if !containerInspector.HasCollectedData() {
+		p.imageInspector.ShowFatImageDockerInstructions()
+		p.xc.Out.Info("results",
+			ovars{
+				"status":   "no data collected (no minified image generated)",
+				"version":  v.Current(),
+				"location": fsutil.ExeDir(),
+			})
+
+		exitCode := commands.ECTBuild | ecbImageBuildError
+		p.xc.Out.State("exited",
+			ovars{
+				"exit.code": exitCode,
+			})
+
+		p.cmdReport.Error = "no.data.collected"
+		p.xc.Exit(exitCode)
+	}
+
+	return containerInspector, variantReports
+}
+
 func hasContinueAfterMode(modeSet, mode string) bool {
 	for _, current := range strings.Split(modeSet, "&") {
 		if current == mode {
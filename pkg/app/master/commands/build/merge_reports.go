@@ -0,0 +1,395 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
+	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
+	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	variantsDirName          = "variants"
+	variantReportFileNamePat = "creport.%d.json"
+	filesArchiveName         = "files.tar"
+)
+
+// runVariant describes an extra target container run
+// (its collected data is merged with the data from the main run)
+type runVariant struct {
+	Cmd     []string
+	ExecCmd string
+}
+
+// Name returns the run variant description
+func (v *runVariant) Name() string {
+	if v.ExecCmd != "" {
+		return fmt.Sprintf("exec: %s", v.ExecCmd)
+	}
+
+	return fmt.Sprintf("cmd: %s", strings.Join(v.Cmd, " "))
+}
+
+func parseRunVariants(cmds []string, execCmds []string) ([]*runVariant, error) {
+	var variants []*runVariant
+	for _, value := range cmds {
+		cmd, err := commands.ParseExec(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cmd) == 0 {
+			return nil, fmt.Errorf("empty variant cmd")
+		}
+
+		variants = append(variants, &runVariant{Cmd: cmd})
+	}
+
+	for _, value := range execCmds {
+		if strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("empty variant exec command")
+		}
+
+		variants = append(variants, &runVariant{ExecCmd: value})
+	}
+
+	return variants, nil
+}
+
+// saveVariantReport moves the container report for the variant run
+// (so it's not replaced by the next run) and returns its new location
+func saveVariantReport(artifactLocation string, idx int) (string, error) {
+	reportPath := filepath.Join(artifactLocation, report.DefaultContainerReportFileName)
+	variantsDir := filepath.Join(artifactLocation, variantsDirName)
+	if err := os.MkdirAll(variantsDir, 0755); err != nil {
+		return "", err
+	}
+
+	variantReportPath := filepath.Join(variantsDir, fmt.Sprintf(variantReportFileNamePat, idx))
+	if err := os.Rename(reportPath, variantReportPath); err != nil {
+		return "", err
+	}
+
+	return variantReportPath, nil
+}
+
+func loadContainerReport(reportPath string) (*report.ContainerReport, error) {
+	data, err := ioutil.ReadFile(reportPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var creport report.ContainerReport
+	if err := json.Unmarshal(data, &creport); err != nil {
+		return nil, err
+	}
+
+	return &creport, nil
+}
+
+// mergeInfo contains the container report merge stats
+type mergeInfo struct {
+	Reports int
+	Added   int
+	Missing int
+}
+
+// mergeContainerReports merges the file artifacts and the monitor data from the other container reports
+// into the container report in the artifact location (the data for the new files comes from the saved image)
+func mergeContainerReports(
+	xc *app.ExecutionContext,
+	logger *log.Entry,
+	client *dockerapi.Client,
+	imageInspector *image.Inspector,
+	localVolumePath string,
+	artifactLocation string,
+	doKeepPerms bool,
+	excludePatterns map[string]*fsutil.AccessInfo,
+	reportPaths []string) (*mergeInfo, error) {
+	info := &mergeInfo{}
+	creport, err := loadContainerReport(filepath.Join(artifactLocation, report.DefaultContainerReportFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var others []*mergeSource
+	for _, reportPath := range reportPaths {
+		other, err := loadContainerReport(reportPath)
+		if err != nil {
+			return nil, err
+		}
+
+		others = append(others, &mergeSource{Path: reportPath, Report: other})
+	}
+
+	newFiles := mergeReportData(creport, others, info)
+	if len(newFiles) > 0 {
+		_, imageFS, err := saveImageData(xc, logger, client, imageInspector, localVolumePath)
+		if err != nil {
+			return nil, err
+		}
+
+		var imageEnv []string
+		if imageInspector.ImageInfo.Config != nil {
+			imageEnv = imageInspector.ImageInfo.Config.Env
+		}
+
+		keepSet := newStaticKeepSet(logger, imageFS, imageEnv, excludePatterns)
+		if err := addMergedFiles(keepSet, artifactLocation, doKeepPerms, creport, newFiles, info); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := saveContainerReport(artifactLocation, creport); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// mergeSource is a container report from another run
+type mergeSource struct {
+	Path   string
+	Report *report.ContainerReport
+}
+
+// mergedFile is a file found only in the other container reports
+type mergedFile struct {
+	Source string //the report the file comes from first
+	Props  *report.ArtifactProps
+}
+
+// mergeReportData merges the monitor data and the artifact flags from the other reports
+// and returns the files that are not in the container report yet
+func mergeReportData(creport *report.ContainerReport, others []*mergeSource, info *mergeInfo) map[string]*mergedFile {
+	known := map[string]*report.ArtifactProps{}
+	for _, props := range creport.Image.Files {
+		known[props.FilePath] = props
+	}
+
+	newFiles := map[string]*mergedFile{}
+	for idx, other := range others {
+		info.Reports++
+		mergeMonitorReports(&creport.Monitors, &other.Report.Monitors, idx+1)
+
+		for _, props := range other.Report.Image.Files {
+			if current, found := known[props.FilePath]; found {
+				mergeArtifactFlags(current, props)
+				continue
+			}
+
+			if current, found := newFiles[props.FilePath]; found {
+				mergeArtifactFlags(current.Props, props)
+				continue
+			}
+
+			newFiles[props.FilePath] = &mergedFile{Source: other.Path, Props: props}
+		}
+	}
+
+	return newFiles
+}
+
+// addMergedFiles saves the new files from the image (with the keep set) and adds them to the container report
+// (the files created at runtime in the other runs are not in the image and they are counted as missing)
+func addMergedFiles(
+	keepSet *staticKeepSet,
+	artifactLocation string,
+	doKeepPerms bool,
+	creport *report.ContainerReport,
+	newFiles map[string]*mergedFile,
+	info *mergeInfo) error {
+	known := map[string]struct{}{}
+	for _, props := range creport.Image.Files {
+		known[props.FilePath] = struct{}{}
+	}
+
+	var names []string
+	for name := range newFiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !keepSet.keep(name, report.ProvenanceMergeReport, newFiles[name].Source, false) {
+			info.Missing++
+		}
+	}
+
+	var saveNames []string
+	for _, name := range keepSet.names() {
+		if _, found := known[name]; !found {
+			saveNames = append(saveNames, name)
+		}
+	}
+
+	var artifacts []*report.ArtifactProps
+	var err error
+	archivePath := filepath.Join(artifactLocation, filesArchiveName)
+	if fsutil.IsRegularFile(archivePath) {
+		artifacts, err = keepSet.saveToArchive(archivePath, saveNames)
+	} else {
+		filesDir := filepath.Join(artifactLocation, staticFilesDirName)
+		if err := os.MkdirAll(filesDir, 0755); err != nil {
+			return err
+		}
+
+		artifacts, err = keepSet.saveToDir(filesDir, saveNames, doKeepPerms)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, props := range artifacts {
+		if other, found := newFiles[props.FilePath]; found {
+			props.Flags = other.Props.Flags
+			props.DataType = other.Props.DataType
+			props.AppType = other.Props.AppType
+		}
+
+		creport.Image.Files = append(creport.Image.Files, props)
+	}
+
+	info.Added = len(artifacts)
+	return nil
+}
+
+func mergeArtifactFlags(dst, src *report.ArtifactProps) {
+	if len(src.Flags) == 0 {
+		return
+	}
+
+	if dst.Flags == nil {
+		dst.Flags = map[string]bool{}
+	}
+
+	for flag, value := range src.Flags {
+		if value {
+			dst.Flags[flag] = true
+		}
+	}
+}
+
+// mergeMonitorReports merges the system call and the file activity data from another run
+// (so the generated security profiles work for all runs)
+func mergeMonitorReports(dst, src *report.MonitorReports, run int) {
+	if src.Pt != nil {
+		if dst.Pt == nil {
+			dst.Pt = src.Pt
+		} else {
+			if dst.Pt.SyscallStats == nil {
+				dst.Pt.SyscallStats = map[string]report.SyscallStatInfo{}
+			}
+
+			for key, stats := range src.Pt.SyscallStats {
+				if current, found := dst.Pt.SyscallStats[key]; found {
+					current.Count += stats.Count
+					dst.Pt.SyscallStats[key] = current
+				} else {
+					dst.Pt.SyscallStats[key] = stats
+				}
+			}
+
+			for _, args := range src.Pt.SyscallArgs {
+				for _, values := range args.Values {
+					dst.Pt.AddSyscallArgs(args.Number, args.Name, args.ArgIndexes, values)
+				}
+			}
+
+			for name, info := range src.Pt.Capabilities {
+				if dst.Pt.Capabilities == nil {
+					dst.Pt.Capabilities = map[string]*report.CapabilityInfo{}
+				}
+
+				current, found := dst.Pt.Capabilities[name]
+				if !found {
+					dst.Pt.Capabilities[name] = info
+					continue
+				}
+
+				count := current.Count + info.Count
+				for _, syscallName := range info.Syscalls {
+					dst.Pt.AddCapabilityUse(name, syscallName)
+				}
+
+				current.Count = count
+			}
+
+			dst.Pt.SyscallCount += src.Pt.SyscallCount
+			dst.Pt.SyscallNum = uint32(len(dst.Pt.SyscallStats))
+
+			if dst.Pt.FSActivity == nil {
+				dst.Pt.FSActivity = map[string]*report.FSActivityInfo{}
+			}
+
+			for name, activity := range src.Pt.FSActivity {
+				if _, found := dst.Pt.FSActivity[name]; !found {
+					dst.Pt.FSActivity[name] = activity
+				}
+			}
+		}
+	}
+
+	if src.Fan != nil {
+		if dst.Fan == nil {
+			dst.Fan = &report.FanMonitorReport{
+				MonitorPid:       src.Fan.MonitorPid,
+				MonitorParentPid: src.Fan.MonitorParentPid,
+				MainProcess:      src.Fan.MainProcess,
+			}
+		}
+
+		//the process data is keyed by PID and the PIDs are reused across the runs,
+		//so the process keys from the other runs include the run number
+		dst.Fan.EventCount += src.Fan.EventCount
+		if len(src.Fan.Processes) > 0 && dst.Fan.Processes == nil {
+			dst.Fan.Processes = map[string]*report.ProcessInfo{}
+		}
+
+		for pid, process := range src.Fan.Processes {
+			dst.Fan.Processes[mergedProcessKey(run, pid)] = process
+		}
+
+		if len(src.Fan.ProcessFiles) > 0 && dst.Fan.ProcessFiles == nil {
+			dst.Fan.ProcessFiles = map[string]map[string]*report.FileInfo{}
+		}
+
+		for pid, files := range src.Fan.ProcessFiles {
+			dst.Fan.ProcessFiles[mergedProcessKey(run, pid)] = files
+		}
+
+		if len(src.Fan.NewFiles) > 0 && dst.Fan.NewFiles == nil {
+			dst.Fan.NewFiles = map[string]*report.FileInfo{}
+		}
+
+		for name, file := range src.Fan.NewFiles {
+			current, found := dst.Fan.NewFiles[name]
+			if !found {
+				dst.Fan.NewFiles[name] = file
+				continue
+			}
+
+			current.EventCount += file.EventCount
+			current.ReadCount += file.ReadCount
+			current.WriteCount += file.WriteCount
+		}
+	}
+}
+
+// mergedProcessKey returns the process key for the process from another run
+func mergedProcessKey(run int, pid string) string {
+	return fmt.Sprintf("%d.%s", run, pid)
+}
@@ -0,0 +1,362 @@
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
+)
+
+func testArtifact(name string, flags ...string) *report.ArtifactProps {
+	props := &report.ArtifactProps{FilePath: name}
+	if len(flags) > 0 {
+		props.Flags = map[string]bool{}
+		for _, flag := range flags {
+			props.Flags[flag] = true
+		}
+	}
+
+	return props
+}
+
+func flagString(props *report.ArtifactProps) string {
+	var flags []string
+	for flag, value := range props.Flags {
+		if value {
+			flags = append(flags, flag)
+		}
+	}
+
+	sort.Strings(flags)
+	var out string
+	for _, flag := range flags {
+		out += flag
+	}
+
+	return out
+}
+
+func TestMergeReportData(t *testing.T) {
+	creport := &report.ContainerReport{}
+	creport.Image.Files = []*report.ArtifactProps{
+		testArtifact("/app/server", "R"),
+		testArtifact("/bin/sh"),
+	}
+
+	others := []*mergeSource{
+		{
+			Path: "creport.1.json",
+			Report: &report.ContainerReport{
+				Image: report.ImageReport{
+					Files: []*report.ArtifactProps{
+						testArtifact("/app/server", "W"),
+						testArtifact("/app/new", "R"),
+						testArtifact("/tmp/runtime.log", "W"),
+					},
+				},
+			},
+		},
+		{
+			Path: "creport.2.json",
+			Report: &report.ContainerReport{
+				Image: report.ImageReport{
+					Files: []*report.ArtifactProps{
+						testArtifact("/app/new", "X"),
+						testArtifact("/bin/sh", "R"),
+						testArtifact("/etc/hosts"),
+					},
+				},
+			},
+		},
+	}
+
+	info := &mergeInfo{}
+	newFiles := mergeReportData(creport, others, info)
+
+	if info.Reports != 2 {
+		t.Errorf("reports: got %d expected 2", info.Reports)
+	}
+
+	expectedNew := map[string]string{
+		"/app/new":         "creport.1.json",
+		"/tmp/runtime.log": "creport.1.json",
+		"/etc/hosts":       "creport.2.json",
+	}
+
+	gotNew := map[string]string{}
+	for name, file := range newFiles {
+		gotNew[name] = file.Source
+	}
+
+	if !reflect.DeepEqual(gotNew, expectedNew) {
+		t.Errorf("new files: got %q expected %q", gotNew, expectedNew)
+	}
+
+	expectedFlags := map[string]string{
+		"/app/server":      "RW",
+		"/bin/sh":          "R",
+		"/app/new":         "RX",
+		"/tmp/runtime.log": "W",
+		"/etc/hosts":       "",
+	}
+
+	gotFlags := map[string]string{}
+	for _, props := range creport.Image.Files {
+		gotFlags[props.FilePath] = flagString(props)
+	}
+
+	for name, file := range newFiles {
+		gotFlags[name] = flagString(file.Props)
+	}
+
+	if !reflect.DeepEqual(gotFlags, expectedFlags) {
+		t.Errorf("flags: got %q expected %q", gotFlags, expectedFlags)
+	}
+
+	if len(creport.Image.Files) != 2 {
+		t.Errorf("the container report files are changed: %d files", len(creport.Image.Files))
+	}
+}
+
+func TestMergeMonitorReportsPt(t *testing.T) {
+	dst := &report.MonitorReports{
+		Pt: &report.PtMonitorReport{
+			SyscallCount: 10,
+			SyscallNum:   2,
+			SyscallStats: map[string]report.SyscallStatInfo{
+				"0": {Number: 0, Name: "read", Count: 7},
+				"1": {Number: 1, Name: "write", Count: 3},
+			},
+			Capabilities: map[string]*report.CapabilityInfo{
+				"CAP_CHOWN": {Name: "CAP_CHOWN", Count: 2, Syscalls: []string{"chown"}},
+			},
+			FSActivity: map[string]*report.FSActivityInfo{
+				"/app/server": {OpsAll: 1},
+			},
+		},
+	}
+
+	dst.Pt.AddSyscallArgs(2, "open", []uint{1}, []uint64{0})
+
+	src := &report.MonitorReports{
+		Pt: &report.PtMonitorReport{
+			SyscallCount: 6,
+			SyscallNum:   2,
+			SyscallStats: map[string]report.SyscallStatInfo{
+				"0":  {Number: 0, Name: "read", Count: 4},
+				"41": {Number: 41, Name: "socket", Count: 2},
+			},
+			Capabilities: map[string]*report.CapabilityInfo{
+				"CAP_CHOWN":            {Name: "CAP_CHOWN", Count: 1, Syscalls: []string{"fchown"}},
+				"CAP_NET_BIND_SERVICE": {Name: "CAP_NET_BIND_SERVICE", Count: 1, Syscalls: []string{"bind"}},
+			},
+			FSActivity: map[string]*report.FSActivityInfo{
+				"/app/server": {OpsAll: 5},
+				"/etc/hosts":  {OpsAll: 1},
+			},
+		},
+	}
+
+	src.Pt.AddSyscallArgs(2, "open", []uint{1}, []uint64{0})
+	src.Pt.AddSyscallArgs(2, "open", []uint{1}, []uint64{64})
+	src.Pt.AddSyscallArgs(3, "close", []uint{0}, []uint64{3})
+
+	mergeMonitorReports(dst, src, 1)
+
+	pt := dst.Pt
+	if pt.SyscallCount != 16 {
+		t.Errorf("syscall count: got %d expected 16", pt.SyscallCount)
+	}
+
+	if pt.SyscallNum != 3 {
+		t.Errorf("syscall num: got %d expected 3", pt.SyscallNum)
+	}
+
+	expectedStats := map[string]uint64{"0": 11, "1": 3, "41": 2}
+	gotStats := map[string]uint64{}
+	for key, stats := range pt.SyscallStats {
+		gotStats[key] = stats.Count
+	}
+
+	if !reflect.DeepEqual(gotStats, expectedStats) {
+		t.Errorf("syscall stats: got %v expected %v", gotStats, expectedStats)
+	}
+
+	expectedArgs := map[string][][]uint64{
+		"2": {{0}, {64}},
+		"3": {{3}},
+	}
+
+	gotArgs := map[string][][]uint64{}
+	for key, args := range pt.SyscallArgs {
+		gotArgs[key] = args.Values
+	}
+
+	if !reflect.DeepEqual(gotArgs, expectedArgs) {
+		t.Errorf("syscall args: got %v expected %v", gotArgs, expectedArgs)
+	}
+
+	expectedCaps := map[string]report.CapabilityInfo{
+		"CAP_CHOWN":            {Name: "CAP_CHOWN", Count: 3, Syscalls: []string{"chown", "fchown"}},
+		"CAP_NET_BIND_SERVICE": {Name: "CAP_NET_BIND_SERVICE", Count: 1, Syscalls: []string{"bind"}},
+	}
+
+	gotCaps := map[string]report.CapabilityInfo{}
+	for name, info := range pt.Capabilities {
+		gotCaps[name] = *info
+	}
+
+	if !reflect.DeepEqual(gotCaps, expectedCaps) {
+		t.Errorf("capabilities:\ngot      %+v\nexpected %+v", gotCaps, expectedCaps)
+	}
+
+	if len(pt.FSActivity) != 2 || pt.FSActivity["/app/server"].OpsAll != 1 {
+		t.Errorf("fs activity: unexpected result %+v", pt.FSActivity)
+	}
+}
+
+func TestMergeMonitorReportsFan(t *testing.T) {
+	newSrc := func(path string, counts uint32) *report.MonitorReports {
+		return &report.MonitorReports{
+			Fan: &report.FanMonitorReport{
+				EventCount: counts,
+				Processes: map[string]*report.ProcessInfo{
+					"10": {Pid: 10, Path: path},
+				},
+				ProcessFiles: map[string]map[string]*report.FileInfo{
+					"10": {path: {EventCount: counts, ExeCount: 1}},
+				},
+				NewFiles: map[string]*report.FileInfo{
+					"/tmp/out": {EventCount: counts, WriteCount: counts},
+				},
+			},
+		}
+	}
+
+	dst := &report.MonitorReports{}
+	mergeMonitorReports(dst, newSrc("/app/server", 2), 1)
+	mergeMonitorReports(dst, newSrc("/app/worker", 3), 2)
+	mergeMonitorReports(dst, &report.MonitorReports{}, 3)
+
+	fan := dst.Fan
+	if fan.EventCount != 5 {
+		t.Errorf("event count: got %d expected 5", fan.EventCount)
+	}
+
+	//the same PID in the different runs is a different process
+	expectedProcesses := map[string]string{
+		"1.10": "/app/server",
+		"2.10": "/app/worker",
+	}
+
+	gotProcesses := map[string]string{}
+	for key, process := range fan.Processes {
+		gotProcesses[key] = process.Path
+	}
+
+	if !reflect.DeepEqual(gotProcesses, expectedProcesses) {
+		t.Errorf("processes: got %q expected %q", gotProcesses, expectedProcesses)
+	}
+
+	expectedFiles := map[string][]string{
+		"1.10": {"/app/server"},
+		"2.10": {"/app/worker"},
+	}
+
+	gotFiles := map[string][]string{}
+	for key, files := range fan.ProcessFiles {
+		for name := range files {
+			gotFiles[key] = append(gotFiles[key], name)
+		}
+	}
+
+	if !reflect.DeepEqual(gotFiles, expectedFiles) {
+		t.Errorf("process files: got %q expected %q", gotFiles, expectedFiles)
+	}
+
+	if file := fan.NewFiles["/tmp/out"]; file == nil || file.EventCount != 5 || file.WriteCount != 5 {
+		t.Errorf("new files: unexpected result %+v", fan.NewFiles)
+	}
+}
+
+func TestAddMergedFiles(t *testing.T) {
+	for _, useArchive := range []bool{false, true} {
+		dir := testDir(t)
+		defer os.RemoveAll(dir)
+
+		artifactLocation := filepath.Join(dir, "artifacts")
+		if err := os.MkdirAll(artifactLocation, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		archivePath := filepath.Join(artifactLocation, filesArchiveName)
+		if useArchive {
+			//an empty tar archive (the end of archive marker only)
+			if err := ioutil.WriteFile(archivePath, make([]byte, 1024), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		keepSet := newTestStaticKeepSet(t, dir)
+
+		creport := &report.ContainerReport{}
+		creport.Image.Files = []*report.ArtifactProps{testArtifact("/bin/busybox", "X")}
+
+		newFiles := map[string]*mergedFile{
+			"/app/config.yaml": {Source: "creport.1.json", Props: testArtifact("/app/config.yaml", "R")},
+			"/bin/sh":          {Source: "creport.1.json", Props: testArtifact("/bin/sh", "X")},
+			"/tmp/runtime.log": {Source: "creport.2.json", Props: testArtifact("/tmp/runtime.log", "W")},
+		}
+
+		info := &mergeInfo{}
+		if err := addMergedFiles(keepSet, artifactLocation, false, creport, newFiles, info); err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Missing != 1 || info.Added != 2 {
+			t.Errorf("archive=%v: got missing=%d added=%d expected missing=1 added=2", useArchive, info.Missing, info.Added)
+		}
+
+		//the symlink target is kept, but it's already in the container report
+		expected := map[string]string{
+			"/bin/busybox":     "X",
+			"/app/config.yaml": "R",
+			"/bin/sh":          "X",
+		}
+
+		got := map[string]string{}
+		for _, props := range creport.Image.Files {
+			if _, found := got[props.FilePath]; found {
+				t.Errorf("archive=%v: duplicate file %s", useArchive, props.FilePath)
+			}
+
+			got[props.FilePath] = flagString(props)
+		}
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("archive=%v: got %q expected %q", useArchive, got, expected)
+		}
+
+		for _, props := range creport.Image.Files[1:] {
+			if len(props.KeptBy) != 1 || props.KeptBy[0].Rule != report.ProvenanceMergeReport {
+				t.Errorf("archive=%v: %s: unexpected provenance %+v", useArchive, props.FilePath, props.KeptBy)
+			}
+		}
+
+		if !useArchive {
+			filesDir := filepath.Join(artifactLocation, staticFilesDirName)
+			if !fsutil.IsRegularFile(filepath.Join(filesDir, "app/config.yaml")) {
+				t.Errorf("app/config.yaml is not saved")
+			}
+
+			if fsutil.Exists(filepath.Join(filesDir, "bin/busybox")) {
+				t.Errorf("bin/busybox is saved again")
+			}
+		}
+	}
+}
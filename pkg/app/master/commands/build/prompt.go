@@ -61,9 +61,15 @@ var CommandFlagSuggestions = &commands.FlagSuggestions{
 		{Text: commands.FullFlagName(FlagKeepPerms), Description: FlagKeepPermsUsage},
 		{Text: commands.FullFlagName(commands.FlagRunTargetAsUser), Description: commands.FlagRunTargetAsUserUsage},
 		{Text: commands.FullFlagName(commands.FlagCopyMetaArtifacts), Description: commands.FlagCopyMetaArtifactsUsage},
+		{Text: commands.FullFlagName(commands.FlagSBOM), Description: commands.FlagSBOMUsage},
+		{Text: commands.FullFlagName(commands.FlagSeccompArch), Description: commands.FlagSeccompArchUsage},
 		{Text: commands.FullFlagName(commands.FlagRemoveFileArtifacts), Description: commands.FlagRemoveFileArtifactsUsage},
 		{Text: commands.FullFlagName(FlagTag), Description: FlagTagUsage},
 		{Text: commands.FullFlagName(FlagImageOverrides), Description: FlagImageOverridesUsage},
+		{Text: commands.FullFlagName(FlagImageBuildEngine), Description: FlagImageBuildEngineUsage},
+		{Text: commands.FullFlagName(FlagImageBuildOCILayout), Description: FlagImageBuildOCILayoutUsage},
+		{Text: commands.FullFlagName(FlagImageBuildArchive), Description: FlagImageBuildArchiveUsage},
+		{Text: commands.FullFlagName(FlagImageBuildLoad), Description: FlagImageBuildLoadUsage},
 		{Text: commands.FullFlagName(commands.FlagUser), Description: commands.FlagUserUsage},
 		{Text: commands.FullFlagName(commands.FlagEntrypoint), Description: commands.FlagEntrypointUsage},
 		{Text: commands.FullFlagName(commands.FlagCmd), Description: commands.FlagCmdUsage},
@@ -101,6 +107,13 @@ var CommandFlagSuggestions = &commands.FlagSuggestions{
 		{Text: commands.FullFlagName(FlagIncludeBinFile), Description: FlagIncludeBinFileUsage},
 		{Text: commands.FullFlagName(FlagIncludeExe), Description: FlagIncludeExeUsage},
 		{Text: commands.FullFlagName(FlagIncludeExeFile), Description: FlagIncludeExeFileUsage},
+		{Text: commands.FullFlagName(FlagIncludeStaticDeps), Description: FlagIncludeStaticDepsUsage},
+		{Text: commands.FullFlagName(FlagStaticOnly), Description: FlagStaticOnlyUsage},
+		{Text: commands.FullFlagName(FlagMergeReport), Description: FlagMergeReportUsage},
+		{Text: commands.FullFlagName(FlagVariantCmd), Description: FlagVariantCmdUsage},
+		{Text: commands.FullFlagName(FlagVariantExec), Description: FlagVariantExecUsage},
+		{Text: commands.FullFlagName(FlagExplain), Description: FlagExplainUsage},
+		{Text: commands.FullFlagName(FlagRemovedFilesReport), Description: FlagRemovedFilesReportUsage},
 		{Text: commands.FullFlagName(FlagIncludeShell), Description: FlagIncludeShellUsage},
 		{Text: commands.FullFlagName(FlagIncludeCertAll), Description: FlagIncludeCertAllUsage},
 		{Text: commands.FullFlagName(FlagIncludeCertBundles), Description: FlagIncludeCertBundlesUsage},
@@ -171,5 +184,8 @@ var CommandFlagSuggestions = &commands.FlagSuggestions{
 		commands.FullFlagName(commands.FlagCROHostConfigFile):              commands.CompleteFile,
 		commands.FullFlagName(FlagDockerfileContext):                       commands.CompleteFile,
 		commands.FullFlagName(commands.FlagSensorIPCMode):                  commands.CompleteIPCMode,
+		commands.FullFlagName(FlagImageBuildOCILayout):                     commands.CompleteFile,
+		commands.FullFlagName(FlagImageBuildArchive):                       commands.CompleteFile,
+		commands.FullFlagName(FlagImageBuildLoad):                          commands.CompleteTBool,
 	},
 }
@@ -0,0 +1,304 @@
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
+	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/system"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+)
+
+// the number of the path elements in the removed file directory groups (e.g., '/usr/share/doc')
+const removedFilesDirDepth = 3
+
+var sharedObjectNamePattern = regexp.MustCompile(`\.so(\.[0-9]+)*$`)
+
+// saveRemovedFilesReport compares the files in the original image with the kept files
+// in the container report and saves the removed files report in the artifact location
+func saveRemovedFilesReport(
+	xc *app.ExecutionContext,
+	logger *log.Entry,
+	client *dockerapi.Client,
+	imageInspector *image.Inspector,
+	localVolumePath string,
+	artifactLocation string) (*report.RemovedFilesReport, error) {
+	creport, err := loadContainerReport(filepath.Join(artifactLocation, report.DefaultContainerReportFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	imagePkg, imageFS, err := saveImageData(xc, logger, client, imageInspector, localVolumePath)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := newRemovedFilesReport(logger, imagePkg, imageFS, creport)
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(removed); err != nil {
+		return nil, err
+	}
+
+	reportPath := filepath.Join(artifactLocation, report.DefaultRemovedFilesReportFileName)
+	if err := ioutil.WriteFile(reportPath, data.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+func newRemovedFilesReport(
+	logger *log.Entry,
+	imagePkg *dockerimage.Package,
+	imageFS *dockerimage.FileSystem,
+	creport *report.ContainerReport) *report.RemovedFilesReport {
+	//the container report has all kept files and directories
+	//(the files in a kept directory are listed too, so the paths are matched exactly)
+	keptFiles := map[string]struct{}{}
+	for _, props := range creport.Image.Files {
+		if props != nil {
+			keptFiles[props.FilePath] = struct{}{}
+		}
+	}
+
+	objects := imagePkg.FinalObjects()
+	fileOwners := osPackageFileOwners(logger, imagePkg, imageFS, objects)
+
+	info := &report.RemovedFilesReport{}
+	dirGroups := map[string]*report.RemovedFilesGroup{}
+	pkgGroups := map[*system.OSPackage]*report.RemovedOSPackageFiles{}
+	unpackaged := &report.RemovedFilesGroup{}
+	for name, object := range objects {
+		if object.Mode.IsDir() {
+			continue
+		}
+
+		owner := fileOwners[name]
+		var pkgInfo *report.RemovedOSPackageFiles
+		if owner != nil {
+			pkgInfo = pkgGroups[owner]
+			if pkgInfo == nil {
+				pkgInfo = &report.RemovedOSPackageFiles{
+					Name:    owner.Name,
+					Version: owner.Version,
+					Arch:    owner.Arch,
+					Manager: owner.Manager,
+				}
+
+				pkgGroups[owner] = pkgInfo
+			}
+		}
+
+		if _, found := keptFiles[name]; found {
+			info.KeptCount++
+			info.KeptSize += object.Size
+			if pkgInfo != nil {
+				pkgInfo.KeptCount++
+			}
+
+			continue
+		}
+
+		info.Count++
+		info.Size += object.Size
+
+		dirName := removedFileGroupDir(name)
+		dirInfo := dirGroups[dirName]
+		if dirInfo == nil {
+			dirInfo = &report.RemovedFilesGroup{Name: dirName}
+			dirGroups[dirName] = dirInfo
+		}
+
+		dirInfo.Count++
+		dirInfo.Size += object.Size
+
+		var pkgName string
+		if pkgInfo != nil {
+			pkgInfo.Count++
+			pkgInfo.Size += object.Size
+			pkgName = pkgInfo.Name
+		} else {
+			unpackaged.Count++
+			unpackaged.Size += object.Size
+		}
+
+		if object.Mode.IsRegular() && isExeOrSharedObject(name, object.Mode) {
+			info.Executables = append(info.Executables,
+				&report.RemovedFileInfo{
+					Path:      name,
+					Size:      object.Size,
+					Mode:      object.Mode.String(),
+					OSPackage: pkgName,
+				})
+		}
+	}
+
+	info.SizeHuman = humanize.Bytes(uint64(info.Size))
+
+	for _, dirInfo := range dirGroups {
+		dirInfo.SizeHuman = humanize.Bytes(uint64(dirInfo.Size))
+		info.Directories = append(info.Directories, dirInfo)
+	}
+
+	sort.Slice(info.Directories, func(i, j int) bool {
+		if info.Directories[i].Size != info.Directories[j].Size {
+			return info.Directories[i].Size > info.Directories[j].Size
+		}
+
+		return info.Directories[i].Name < info.Directories[j].Name
+	})
+
+	for _, pkgInfo := range pkgGroups {
+		if pkgInfo.Count == 0 {
+			continue
+		}
+
+		pkgInfo.SizeHuman = humanize.Bytes(uint64(pkgInfo.Size))
+		pkgInfo.AllRemoved = pkgInfo.KeptCount == 0
+		info.OSPackages = append(info.OSPackages, pkgInfo)
+	}
+
+	sort.Slice(info.OSPackages, func(i, j int) bool {
+		if info.OSPackages[i].Size != info.OSPackages[j].Size {
+			return info.OSPackages[i].Size > info.OSPackages[j].Size
+		}
+
+		return info.OSPackages[i].Name < info.OSPackages[j].Name
+	})
+
+	if unpackaged.Count > 0 && len(fileOwners) > 0 {
+		unpackaged.SizeHuman = humanize.Bytes(uint64(unpackaged.Size))
+		info.Unpackaged = unpackaged
+	}
+
+	sort.Slice(info.Executables, func(i, j int) bool {
+		return info.Executables[i].Path < info.Executables[j].Path
+	})
+
+	return info
+}
+
+// osPackageFileOwners maps the files in the final image filesystem to the OS packages that installed them
+// (the package file lists may use the paths with symlinked directories, e.g., '/lib' in merged-usr distros)
+func osPackageFileOwners(
+	logger *log.Entry,
+	imagePkg *dockerimage.Package,
+	imageFS *dockerimage.FileSystem,
+	objects map[string]*dockerimage.ObjectMetadata) map[string]*system.OSPackage {
+	dbs := map[string][]*system.OSPackage{}
+	for _, layer := range imagePkg.Layers {
+		for dbPath, packages := range layer.OSPackages {
+			dbs[dbPath] = packages
+		}
+	}
+
+	dirCache := map[string]string{}
+	realPath := func(name string) string {
+		dir, base := path.Split(name)
+		resolved, found := dirCache[dir]
+		if !found {
+			var err error
+			if resolved, err = imageFS.RealPath(dir); err != nil {
+				resolved = dir
+			}
+
+			dirCache[dir] = resolved
+		}
+
+		return path.Join(resolved, base)
+	}
+
+	owners := map[string]*system.OSPackage{}
+	for dbPath, packages := range dbs {
+		if _, found := objects[dbPath]; !found {
+			continue
+		}
+
+		for _, pkg := range packages {
+			files := pkg.Files
+			if len(files) == 0 && system.OSPackageManagerForDB(dbPath) == system.OSPackageManagerDpkg {
+				files = imageDpkgPackageFiles(imageFS, dbPath, pkg)
+			}
+
+			for _, name := range files {
+				if _, found := objects[name]; !found {
+					name = realPath(name)
+				}
+
+				if _, found := objects[name]; found {
+					owners[name] = pkg
+				}
+			}
+		}
+	}
+
+	logger.Debugf("osPackageFileOwners: %d package files", len(owners))
+	return owners
+}
+
+func imageDpkgPackageFiles(imageFS *dockerimage.FileSystem, dbPath string, pkg *system.OSPackage) []string {
+	readFile := func(name string) ([]byte, error) {
+		f, err := imageFS.Open(name)
+		if err != nil {
+			return nil, err
+		}
+
+		defer f.Close()
+		return ioutil.ReadAll(f)
+	}
+
+	if dbPath != system.DpkgStatusFile {
+		//the distroless package records have the file lists in the md5sums files
+		raw, err := readFile(dbPath + ".md5sums")
+		if err != nil {
+			return nil
+		}
+
+		return system.ParseDpkgMd5sums(raw)
+	}
+
+	for _, listPath := range system.DpkgFileListPaths(pkg) {
+		if raw, err := readFile(listPath); err == nil {
+			return system.ParseDpkgFileList(raw)
+		}
+	}
+
+	return nil
+}
+
+func removedFileGroupDir(name string) string {
+	dir := path.Dir(name)
+	parts := strings.Split(strings.TrimPrefix(dir, "/"), "/")
+	if len(parts) > removedFilesDirDepth {
+		parts = parts[:removedFilesDirDepth]
+	}
+
+	return fmt.Sprintf("/%s", strings.Join(parts, "/"))
+}
+
+func isExeOrSharedObject(name string, mode os.FileMode) bool {
+	if mode&0111 != 0 {
+		return true
+	}
+
+	return sharedObjectNamePattern.MatchString(path.Base(name))
+}
@@ -0,0 +1,87 @@
+package build
+
+import (
+	"archive/tar"
+	"os"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+func TestNewRemovedFilesReport(t *testing.T) {
+	layer := &dockerimage.Layer{References: map[string]*dockerimage.ObjectMetadata{}}
+	addObject := func(name string, size int64, mode os.FileMode) {
+		typeFlag := byte(tar.TypeReg)
+		if mode.IsDir() {
+			typeFlag = tar.TypeDir
+		}
+
+		object := &dockerimage.ObjectMetadata{
+			Name:     name,
+			Size:     size,
+			Mode:     mode,
+			TypeFlag: typeFlag,
+			Change:   dockerimage.ChangeAdd,
+		}
+
+		layer.Objects = append(layer.Objects, object)
+		layer.References[name] = object
+	}
+
+	addObject("/app", 0, os.ModeDir|0755)
+	addObject("/app/server", 100, 0755)
+	addObject("/app/docs/README", 10, 0644)
+	addObject("/tmp", 0, os.ModeDir|os.ModeSticky|0777)
+	addObject("/tmp/cache/data.bin", 1000, 0644)
+	addObject("/usr/lib/libssl.so.1.1", 500, 0644)
+
+	creport := &report.ContainerReport{}
+	for _, name := range []string{"/app", "/app/server", "/tmp"} {
+		mode := os.FileMode(0755)
+		if name != "/app/server" {
+			mode |= os.ModeDir
+		}
+
+		creport.Image.Files = append(creport.Image.Files, &report.ArtifactProps{
+			FilePath: name,
+			Mode:     mode,
+			ModeText: mode.String(),
+		})
+	}
+
+	imagePkg := &dockerimage.Package{Layers: []*dockerimage.Layer{layer}}
+	removed := newRemovedFilesReport(log.NewEntry(log.StandardLogger()), imagePkg, nil, creport)
+
+	//the files in the kept directories are removed if they are not kept too
+	//('/tmp' is kept as an empty directory)
+	if removed.KeptCount != 1 || removed.KeptSize != 100 {
+		t.Errorf("kept files: got %d (%d bytes) expected 1 (100 bytes)", removed.KeptCount, removed.KeptSize)
+	}
+
+	if removed.Count != 3 || removed.Size != 1510 {
+		t.Errorf("removed files: got %d (%d bytes) expected 3 (1510 bytes)", removed.Count, removed.Size)
+	}
+
+	expectedDirs := map[string]int64{
+		"/app/docs":  10,
+		"/tmp/cache": 1000,
+		"/usr/lib":   500,
+	}
+
+	if len(removed.Directories) != len(expectedDirs) {
+		t.Fatalf("unexpected directory groups: %+v", removed.Directories)
+	}
+
+	for _, dir := range removed.Directories {
+		if size, found := expectedDirs[dir.Name]; !found || size != dir.Size {
+			t.Errorf("unexpected directory group: %+v", dir)
+		}
+	}
+
+	if len(removed.Executables) != 1 || removed.Executables[0].Path != "/usr/lib/libssl.so.1.1" {
+		t.Errorf("unexpected removed executables: %+v", removed.Executables)
+	}
+}
@@ -0,0 +1,47 @@
+package build
+
+import (
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/sbom"
+	v "github.com/docker-slim/docker-slim/pkg/version"
+)
+
+// newMinifiedImageSBOM creates the SBOM document for the minified image
+// using the artifacts and the package info in the container report
+func newMinifiedImageSBOM(image sbom.Image, creport *report.ContainerReport) *sbom.Document {
+	doc := sbom.New(image, v.Tag())
+	for _, props := range creport.Image.Files {
+		if props == nil || props.FileType != report.FileArtifactType {
+			continue
+		}
+
+		doc.Files = append(doc.Files, &sbom.File{
+			Path:     props.FilePath,
+			Size:     props.FileSize,
+			SHA1:     props.Sha1Hash,
+			DataType: props.DataType,
+			AppType:  props.AppType,
+		})
+	}
+
+	//the sensor reports only the packages with files in the minified image
+	for _, p := range creport.Image.OSPackages {
+		doc.Packages = append(doc.Packages, &sbom.Package{
+			Name:          p.Name,
+			Version:       p.Version,
+			Arch:          p.Arch,
+			Manager:       p.Manager,
+			InstalledSize: p.InstalledSize,
+		})
+	}
+
+	for _, s := range creport.Image.AppStacks {
+		doc.AppStacks = append(doc.AppStacks, &sbom.AppStack{
+			Language:    s.Language,
+			CodeFiles:   s.CodeFiles,
+			PackageDirs: s.PackageDirs,
+		})
+	}
+
+	return doc
+}
@@ -0,0 +1,927 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha1"
+	"debug/elf"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
+	"github.com/docker-slim/docker-slim/pkg/app/master/config"
+	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
+	"github.com/docker-slim/docker-slim/pkg/app/sensor/inspectors/sodeps"
+	"github.com/docker-slim/docker-slim/pkg/certdiscover"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
+
+	"github.com/bmatcuk/doublestar/v3"
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	staticFilesDirName = "files"
+	scriptHeaderSize   = 256
+)
+
+var shellExeNames = map[string]struct{}{
+	"sh":   {},
+	"bash": {},
+	"ash":  {},
+	"dash": {},
+	"zsh":  {},
+}
+
+// runStaticAnalysis selects the files to keep using only the image data (without running the target container)
+// and saves them as the container artifacts, so the minified image is built the same way
+func runStaticAnalysis(
+	xc *app.ExecutionContext,
+	logger *log.Entry,
+	client *dockerapi.Client,
+	imageInspector *image.Inspector,
+	localVolumePath string,
+	artifactLocation string,
+	overrides *config.ContainerOverrides,
+	instructions *config.ImageNewInstructions,
+	doKeepPerms bool,
+	excludePatterns map[string]*fsutil.AccessInfo,
+	includePaths map[string]*fsutil.AccessInfo,
+	includeBins map[string]*fsutil.AccessInfo,
+	includeExes map[string]*fsutil.AccessInfo,
+	doIncludeCertAll bool,
+	doIncludeCertBundles bool,
+	doIncludeCertDirs bool,
+	doIncludeCertPKAll bool,
+	doIncludeCertPKDirs bool,
+	cmdReport *report.BuildCommand) error {
+	imagePkg, imageFS, err := saveImageData(xc, logger, client, imageInspector, localVolumePath)
+	if err != nil {
+		return err
+	}
+
+	imageConfig := imageInspector.ImageInfo.Config
+	if imageConfig == nil {
+		imageConfig = &dockerapi.Config{}
+	}
+
+	workDir := imageConfig.WorkingDir
+	user := imageConfig.User
+	if overrides != nil {
+		if overrides.Workdir != "" {
+			workDir = overrides.Workdir
+		}
+
+		if overrides.User != "" {
+			user = overrides.User
+		}
+	}
+
+	if instructions != nil && instructions.Workdir != "" {
+		workDir = instructions.Workdir
+	}
+
+	if workDir == "" {
+		workDir = "/"
+	}
+
+	keepSet := newStaticKeepSet(logger, imageFS, imageConfig.Env, excludePatterns)
+
+	keepSet.keepEntrypoint(staticContainerCmd(imageConfig, overrides), workDir)
+	if instructions != nil {
+		keepSet.keepEntrypoint(append(append([]string{}, instructions.Entrypoint...), instructions.Cmd...), workDir)
+	}
+
+	//the same system files the sensor keeps
+	if user != "" {
+		keepSet.keep("/etc/passwd", report.ProvenanceStaticSystem, "user", false)
+		keepSet.keep("/etc/group", report.ProvenanceStaticSystem, "user", false)
+	}
+
+	for _, dir := range []string{"/tmp", "/run", workDir} {
+		keepSet.keep(dir, report.ProvenanceStaticSystem, dir, false)
+	}
+
+	for name := range includePaths {
+		info, err := imageFS.Stat(name)
+		if err != nil {
+			logger.Debugf("runStaticAnalysis: include path not found '%s' - %v", name, err)
+			continue
+		}
+
+		if info.IsDir() {
+			keepSet.keepDir(name, report.ProvenanceIncludePath, name)
+		} else {
+			keepSet.keep(name, report.ProvenanceIncludePath, name, false)
+		}
+	}
+
+	for name := range includeBins {
+		keepSet.keep(name, report.ProvenanceIncludeBin, name, true)
+	}
+
+	for name := range includeExes {
+		keepSet.keepExe(name, report.ProvenanceIncludeExe, name, workDir)
+	}
+
+	keepSet.keepCerts(imagePkg,
+		doIncludeCertAll,
+		doIncludeCertBundles,
+		doIncludeCertDirs,
+		doIncludeCertPKAll,
+		doIncludeCertPKDirs)
+
+	keepSet.analyze()
+
+	artifacts, err := keepSet.save(artifactLocation, doKeepPerms)
+	if err != nil {
+		return err
+	}
+
+	if err := saveStaticContainerReport(artifactLocation, imagePkg, artifacts); err != nil {
+		return err
+	}
+
+	cmdReport.StaticOnly = true
+
+	ruleInfo := ovars{"files": len(artifacts)}
+	for rule, count := range keepSet.ruleCounts() {
+		ruleInfo[rule] = count
+	}
+
+	xc.Out.Info("static.analysis", ruleInfo)
+	return nil
+}
+
+// staticContainerCmd returns the command the target container would run
+// (the same way the container inspector selects it)
+func staticContainerCmd(imageConfig *dockerapi.Config, overrides *config.ContainerOverrides) []string {
+	var cmd []string
+	switch {
+	case overrides == nil:
+		cmd = append(cmd, imageConfig.Entrypoint...)
+		cmd = append(cmd, imageConfig.Cmd...)
+	case len(overrides.Entrypoint) > 0 || overrides.ClearEntrypoint:
+		cmd = append(cmd, overrides.Entrypoint...)
+		if len(overrides.Cmd) > 0 || overrides.ClearCmd {
+			cmd = append(cmd, overrides.Cmd...)
+		}
+	default:
+		cmd = append(cmd, imageConfig.Entrypoint...)
+		if len(overrides.Cmd) > 0 || overrides.ClearCmd {
+			cmd = append(cmd, overrides.Cmd...)
+		} else {
+			cmd = append(cmd, imageConfig.Cmd...)
+		}
+	}
+
+	for len(cmd) > 0 && strings.TrimSpace(cmd[0]) == "" {
+		cmd = cmd[1:]
+	}
+
+	return cmd
+}
+
+// staticKeepSet collects the files to keep in the minified image
+// using the static analysis of the image filesystem (without running the target container)
+type staticKeepSet struct {
+	logger          *log.Entry
+	fs              *dockerimage.FileSystem
+	resolver        *sodeps.Resolver
+	searchPath      []string
+	excludePatterns []string
+	files           map[string][]*report.Provenance
+	pending         []string
+	analyzed        map[string]struct{}
+}
+
+func newStaticKeepSet(
+	logger *log.Entry,
+	imageFS *dockerimage.FileSystem,
+	imageEnv []string,
+	excludePatterns map[string]*fsutil.AccessInfo) *staticKeepSet {
+	s := &staticKeepSet{
+		logger:     logger,
+		fs:         imageFS,
+		resolver:   sodeps.NewResolver(imageFileSystem{imageFS}, sodeps.LibraryPath(imageEnv)),
+		searchPath: imageSearchPath(imageEnv),
+		files:      map[string][]*report.Provenance{},
+		analyzed:   map[string]struct{}{},
+	}
+
+	for pattern := range excludePatterns {
+		s.excludePatterns = append(s.excludePatterns, pattern)
+	}
+
+	return s
+}
+
+// keep adds the file to the keep set with the rule that selected it
+// (the symlinks in the file path and the symlink targets are also kept;
+// the executables and scripts are analyzed when analyze is true)
+func (s *staticKeepSet) keep(name, rule, source string, analyze bool) bool {
+	if !path.IsAbs(name) {
+		return false
+	}
+
+	name = s.canonicalPath(path.Clean(name))
+	if name == "/" || s.isExcluded(name) {
+		return false
+	}
+
+	info, err := s.fs.Lstat(name)
+	if err != nil {
+		s.logger.Debugf("staticKeepSet.keep: skipping '%s' (rule=%s source=%s) - %v", name, rule, source, err)
+		return false
+	}
+
+	if !s.addProvenance(name, rule, source) {
+		return true
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := s.fs.Readlink(name)
+		if err != nil {
+			return true
+		}
+
+		if !path.IsAbs(target) {
+			target = path.Join(path.Dir(name), target)
+		}
+
+		s.keep(target, report.ProvenanceStaticSymlink, name, analyze)
+	case info.Mode().IsRegular() && analyze:
+		if _, found := s.analyzed[name]; !found {
+			s.analyzed[name] = struct{}{}
+			s.pending = append(s.pending, name)
+		}
+	}
+
+	return true
+}
+
+// keepDir adds the directory and all its files to the keep set
+func (s *staticKeepSet) keepDir(name, rule, source string) bool {
+	if !s.keep(name, rule, source, false) {
+		return false
+	}
+
+	dirPath, err := s.fs.RealPath(name)
+	if err != nil {
+		return false
+	}
+
+	entries, err := s.fs.ReadDir(dirPath)
+	if err != nil {
+		return true
+	}
+
+	for _, entry := range entries {
+		entryPath := path.Join(dirPath, entry.Name())
+		if entry.IsDir() {
+			s.keepDir(entryPath, rule, source)
+			continue
+		}
+
+		s.keep(entryPath, rule, source, false)
+	}
+
+	return true
+}
+
+// keepExe adds the executable to the keep set (looking it up in the image PATH if needed)
+func (s *staticKeepSet) keepExe(name, rule, source, workDir string) bool {
+	exePath := name
+	switch {
+	case !strings.Contains(name, "/"):
+		var err error
+		exePath, err = s.resolver.LookPath(name, s.searchPath)
+		if err != nil {
+			s.logger.Debugf("staticKeepSet.keepExe: executable not found '%s' (rule=%s source=%s) - %v", name, rule, source, err)
+			return false
+		}
+	case !path.IsAbs(name):
+		exePath = path.Join(workDir, name)
+	}
+
+	return s.keep(exePath, rule, source, true)
+}
+
+// keepEntrypoint adds the entrypoint/cmd executable, the shell command executable
+// and the existing files passed as the command arguments
+func (s *staticKeepSet) keepEntrypoint(cmd []string, workDir string) {
+	if len(cmd) == 0 {
+		return
+	}
+
+	source := strings.Join(cmd, " ")
+	s.keepExe(cmd[0], report.ProvenanceStaticEntrypoint, source, workDir)
+
+	args := cmd[1:]
+	if _, isShell := shellExeNames[path.Base(cmd[0])]; isShell {
+		for idx, arg := range args {
+			if arg == "-c" && idx+1 < len(args) {
+				s.keepShellCommand(args[idx+1], source, workDir)
+				args = args[idx+2:]
+				break
+			}
+		}
+	}
+
+	for _, arg := range args {
+		s.keepArgFile(arg, source, workDir)
+	}
+}
+
+func (s *staticKeepSet) keepShellCommand(cmd, source, workDir string) {
+	fields := strings.Fields(cmd)
+	for len(fields) > 0 && (fields[0] == "exec" || strings.Contains(fields[0], "=")) {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return
+	}
+
+	s.keepExe(fields[0], report.ProvenanceStaticEntrypoint, source, workDir)
+	for _, arg := range fields[1:] {
+		s.keepArgFile(arg, source, workDir)
+	}
+}
+
+// keepArgFile adds the command argument if it's an existing file (e.g., the script for an interpreter)
+func (s *staticKeepSet) keepArgFile(arg, source, workDir string) {
+	if arg == "" || strings.HasPrefix(arg, "-") {
+		return
+	}
+
+	filePath := arg
+	if !path.IsAbs(filePath) {
+		filePath = path.Join(workDir, filePath)
+	}
+
+	info, err := s.fs.Stat(filePath)
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+
+	s.keep(filePath, report.ProvenanceStaticEntrypoint, source, true)
+}
+
+// analyze processes the pending executables and scripts
+// (keeping the ELF shared library dependencies and the script interpreters)
+func (s *staticKeepSet) analyze() {
+	for len(s.pending) > 0 {
+		name := s.pending[0]
+		s.pending = s.pending[1:]
+
+		f, err := s.fs.Open(name)
+		if err != nil {
+			s.logger.Debugf("staticKeepSet.analyze: error opening '%s' - %v", name, err)
+			continue
+		}
+
+		header := make([]byte, scriptHeaderSize)
+		n, _ := io.ReadFull(f, header)
+		f.Close()
+		header = header[:n]
+
+		switch {
+		case bytes.HasPrefix(header, []byte(elf.ELFMAG)):
+			deps, err := s.resolver.Dependencies(name)
+			if err != nil {
+				s.logger.Debugf("staticKeepSet.analyze: error resolving dependencies for '%s' - %v", name, err)
+				continue
+			}
+
+			for _, dep := range deps {
+				s.keep(dep, report.ProvenanceStaticELFDeps, name, false)
+			}
+		case bytes.HasPrefix(header, []byte("#!")):
+			line := header[2:]
+			if idx := bytes.IndexByte(line, '\n'); idx > -1 {
+				line = line[:idx]
+			}
+
+			fields := strings.Fields(string(line))
+			if len(fields) == 0 {
+				continue
+			}
+
+			s.keepExe(fields[0], report.ProvenanceStaticShebang, name, "/")
+			if path.Base(fields[0]) == "env" {
+				//'#!/usr/bin/env [-S] interp'
+				for _, field := range fields[1:] {
+					if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+						s.keepExe(field, report.ProvenanceStaticShebang, name, "/")
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// keepCerts adds the certificate files and directories (using the same selection as the sensor)
+func (s *staticKeepSet) keepCerts(
+	imagePkg *dockerimage.Package,
+	doIncludeCertAll bool,
+	doIncludeCertBundles bool,
+	doIncludeCertDirs bool,
+	doIncludeCertPKAll bool,
+	doIncludeCertPKDirs bool) {
+	keepFiles := func(list []string) {
+		for _, name := range list {
+			s.keep(name, report.ProvenanceCerts, name, false)
+		}
+	}
+
+	keepDirs := func(list []string) {
+		for _, name := range list {
+			s.keepDir(name, report.ProvenanceCerts, name)
+		}
+	}
+
+	keepSet := func(set map[string]struct{}) {
+		for name := range set {
+			s.keep(name, report.ProvenanceCerts, name, false)
+		}
+	}
+
+	if doIncludeCertAll || doIncludeCertBundles {
+		keepFiles(certdiscover.CertFileList())
+		keepFiles(certdiscover.CACertFileList())
+		keepSet(imagePkg.Certs.Bundles)
+		keepSet(imagePkg.CACerts.Bundles)
+	}
+
+	if doIncludeCertAll || doIncludeCertDirs {
+		keepDirs(certdiscover.CertDirList())
+		keepDirs(certdiscover.CACertDirList())
+		keepDirs(certdiscover.CertExtraDirList())
+	}
+
+	if doIncludeCertPKAll {
+		keepFiles(certdiscover.CACertPKFileList())
+	}
+
+	if doIncludeCertPKAll || doIncludeCertPKDirs {
+		keepDirs(certdiscover.CertPKDirList())
+		keepDirs(certdiscover.CACertPKDirList())
+	}
+}
+
+// canonicalPath resolves the symlinks in the parent directories
+// (keeping the symlinks, so the original paths still work in the minified image)
+func (s *staticKeepSet) canonicalPath(name string) string {
+	current := "/"
+	parts := strings.Split(strings.TrimPrefix(name, "/"), "/")
+	for idx, part := range parts {
+		next := path.Join(current, part)
+		if idx == len(parts)-1 {
+			return next
+		}
+
+		info, err := s.fs.Lstat(next)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		if _, found := s.files[next]; !found {
+			s.keep(next, report.ProvenanceStaticSymlink, name, false)
+		}
+
+		if resolved, err := s.fs.RealPath(next); err == nil {
+			current = resolved
+		} else {
+			current = next
+		}
+	}
+
+	return current
+}
+
+func (s *staticKeepSet) addProvenance(name, rule, source string) bool {
+	for _, p := range s.files[name] {
+		if p.Rule == rule && p.Source == source {
+			return false
+		}
+	}
+
+	s.files[name] = append(s.files[name], &report.Provenance{
+		Rule:   rule,
+		Source: source,
+	})
+
+	return true
+}
+
+func (s *staticKeepSet) isExcluded(name string) bool {
+	for _, pattern := range s.excludePatterns {
+		if found, err := doublestar.Match(pattern, name); err == nil && found {
+			s.logger.Debugf("staticKeepSet.isExcluded: '%s' (pattern=%s)", name, pattern)
+			return true
+		}
+	}
+
+	return false
+}
+
+// ruleCounts returns the number of kept files for each rule
+func (s *staticKeepSet) ruleCounts() map[string]int {
+	counts := map[string]int{}
+	for _, provenance := range s.files {
+		rules := map[string]struct{}{}
+		for _, p := range provenance {
+			rules[p.Rule] = struct{}{}
+		}
+
+		for rule := range rules {
+			counts[rule]++
+		}
+	}
+
+	return counts
+}
+
+// save copies the kept files from the image data to the artifact files directory
+// and returns the artifact properties for the container report
+func (s *staticKeepSet) save(artifactLocation string, doKeepPerms bool) ([]*report.ArtifactProps, error) {
+	filesDir := filepath.Join(artifactLocation, staticFilesDirName)
+	if err := os.RemoveAll(filesDir); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filesDir, 0755); err != nil {
+		return nil, err
+	}
+
+	return s.saveToDir(filesDir, s.names(), doKeepPerms)
+}
+
+// names returns the sorted kept file names
+// (the parent directories are listed before their files)
+func (s *staticKeepSet) names() []string {
+	var names []string
+	for name := range s.files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// saveToDir copies the selected kept files to the files directory
+func (s *staticKeepSet) saveToDir(filesDir string, names []string, doKeepPerms bool) ([]*report.ArtifactProps, error) {
+	var artifacts []*report.ArtifactProps
+	for _, name := range names {
+		info, err := s.fs.Lstat(name)
+		if err != nil {
+			continue
+		}
+
+		dstPath := filepath.Join(filesDir, name)
+		if err := s.makeParentDirs(filesDir, name, doKeepPerms); err != nil {
+			return nil, err
+		}
+
+		props := s.artifactProps(name, info)
+		switch props.FileType {
+		case report.DirArtifactType:
+			if err := os.MkdirAll(dstPath, 0755); err != nil {
+				return nil, err
+			}
+
+			if err := os.Chmod(dstPath, info.Mode()); err != nil {
+				s.logger.Debugf("staticKeepSet.saveToDir: error setting permissions for '%s' - %v", name, err)
+			}
+		case report.SymlinkArtifactType:
+			if err := os.Symlink(props.LinkRef, dstPath); err != nil && !os.IsExist(err) {
+				return nil, err
+			}
+		case report.FileArtifactType:
+			hash, err := s.saveFile(name, dstPath, info.Mode())
+			if err != nil {
+				return nil, err
+			}
+
+			props.Sha1Hash = hash
+		default:
+			s.logger.Debugf("staticKeepSet.saveToDir: skipping special file '%s'", name)
+			continue
+		}
+
+		if doKeepPerms {
+			setOwner(dstPath, info)
+		}
+
+		artifacts = append(artifacts, props)
+	}
+
+	return artifacts, nil
+}
+
+// saveToArchive adds the selected kept files to the files archive
+// (the files already in the archive are not replaced)
+func (s *staticKeepSet) saveToArchive(archivePath string, names []string) ([]*report.ArtifactProps, error) {
+	inFile, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer inFile.Close()
+
+	tmpPath := fmt.Sprintf("%s.tmp", archivePath)
+	outFile, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer outFile.Close()
+
+	archived := map[string]struct{}{}
+	tw := tar.NewWriter(outFile)
+	tr := tar.NewReader(inFile)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		archived[path.Join("/", hdr.Name)] = struct{}{}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+
+		if _, err := io.Copy(tw, tr); err != nil {
+			return nil, err
+		}
+	}
+
+	var artifacts []*report.ArtifactProps
+	for _, name := range names {
+		info, err := s.fs.Lstat(name)
+		if err != nil {
+			continue
+		}
+
+		props := s.artifactProps(name, info)
+		if props.FileType == report.UnknownArtifactType {
+			s.logger.Debugf("staticKeepSet.saveToArchive: skipping special file '%s'", name)
+			continue
+		}
+
+		if _, found := archived[name]; !found {
+			for _, dir := range parentDirs(name) {
+				if _, found := archived[dir]; found {
+					continue
+				}
+
+				dirInfo, err := s.fs.Lstat(dir)
+				if err != nil {
+					return nil, err
+				}
+
+				if err := s.writeArchiveObject(tw, dir, dirInfo, nil); err != nil {
+					return nil, err
+				}
+
+				archived[dir] = struct{}{}
+			}
+
+			if err := s.writeArchiveObject(tw, name, info, props); err != nil {
+				return nil, err
+			}
+
+			archived[name] = struct{}{}
+		}
+
+		artifacts = append(artifacts, props)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := outFile.Close(); err != nil {
+		return nil, err
+	}
+
+	return artifacts, os.Rename(tmpPath, archivePath)
+}
+
+func (s *staticKeepSet) writeArchiveObject(tw *tar.Writer, name string, info os.FileInfo, props *report.ArtifactProps) error {
+	var linkTarget string
+	if info.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if linkTarget, err = s.fs.Readlink(name); err != nil {
+			return err
+		}
+	}
+
+	hdr, err := tar.FileInfoHeader(info, linkTarget)
+	if err != nil {
+		return err
+	}
+
+	hdr.Name = strings.TrimPrefix(name, "/")
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+
+	if object, ok := info.Sys().(*dockerimage.ObjectMetadata); ok && object != nil {
+		hdr.Uid = object.UID
+		hdr.Gid = object.GID
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	src, err := s.fs.Open(name)
+	if err != nil {
+		return err
+	}
+
+	defer src.Close()
+
+	hasher := sha1.New()
+	if _, err := io.Copy(io.MultiWriter(tw, hasher), src); err != nil {
+		return err
+	}
+
+	if props != nil {
+		props.Sha1Hash = hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	return nil
+}
+
+// artifactProps returns the container report artifact properties for the kept file
+func (s *staticKeepSet) artifactProps(name string, info os.FileInfo) *report.ArtifactProps {
+	props := &report.ArtifactProps{
+		FilePath: name,
+		Mode:     info.Mode(),
+		ModeText: info.Mode().String(),
+		FileSize: info.Size(),
+		KeptBy:   s.files[name],
+	}
+
+	switch {
+	case info.IsDir():
+		props.FileType = report.DirArtifactType
+	case info.Mode()&os.ModeSymlink != 0:
+		props.FileType = report.SymlinkArtifactType
+		props.LinkRef, _ = s.fs.Readlink(name)
+	case info.Mode().IsRegular():
+		props.FileType = report.FileArtifactType
+	default:
+		props.FileType = report.UnknownArtifactType
+	}
+
+	return props
+}
+
+func (s *staticKeepSet) saveFile(name, dstPath string, mode os.FileMode) (string, error) {
+	src, err := s.fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	defer dst.Close()
+
+	hasher := sha1.New()
+	if _, err := io.Copy(io.MultiWriter(dst, hasher), src); err != nil {
+		return "", err
+	}
+
+	//setting the mode explicitly (so the umask doesn't change it and the special bits are preserved)
+	if err := os.Chmod(dstPath, mode); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// makeParentDirs creates the parent directories using the directory permissions from the image
+func (s *staticKeepSet) makeParentDirs(filesDir, name string, doKeepPerms bool) error {
+	dir := path.Dir(name)
+	if dir == "/" {
+		return nil
+	}
+
+	dstDir := filepath.Join(filesDir, dir)
+	if fsutil.DirExists(dstDir) {
+		return nil
+	}
+
+	if err := s.makeParentDirs(filesDir, dir, doKeepPerms); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0755)
+	info, err := s.fs.Lstat(dir)
+	if err == nil && info.IsDir() {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.Mkdir(dstDir, 0755); err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	if err := os.Chmod(dstDir, mode|0700); err != nil {
+		return err
+	}
+
+	if doKeepPerms && info != nil {
+		setOwner(dstDir, info)
+	}
+
+	return nil
+}
+
+func setOwner(filePath string, info os.FileInfo) {
+	object, ok := info.Sys().(*dockerimage.ObjectMetadata)
+	if !ok || object == nil {
+		return
+	}
+
+	if err := os.Lchown(filePath, object.UID, object.GID); err != nil {
+		log.Debugf("setOwner(%s): error - %v", filePath, err)
+	}
+}
+
+// saveStaticContainerReport saves the container report for the static analysis results
+// (so the report based processing works the same way as with the sensor results)
+func saveStaticContainerReport(
+	artifactLocation string,
+	imagePkg *dockerimage.Package,
+	artifacts []*report.ArtifactProps) error {
+	creport := &report.ContainerReport{
+		System: report.SystemReport{
+			Type: "Linux",
+		},
+	}
+
+	for _, layer := range imagePkg.Layers {
+		if layer.Distro != nil {
+			creport.System.Distro = report.DistroInfo{
+				Name:        layer.Distro.Name,
+				Version:     layer.Distro.Version,
+				DisplayName: layer.Distro.DisplayName,
+			}
+		}
+	}
+
+	creport.Image.Files = artifacts
+	return saveContainerReport(artifactLocation, creport)
+}
+
+func saveContainerReport(artifactLocation string, creport *report.ContainerReport) error {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(creport); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(artifactLocation, report.DefaultContainerReportFileName), out.Bytes(), 0644)
+}
+
+// parentDirs returns the parent directories for the path (starting from the top level directory)
+func parentDirs(name string) []string {
+	var dirs []string
+	for dir := path.Dir(name); dir != "/" && dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+
+	return dirs
+}
@@ -0,0 +1,477 @@
+package build
+
+import (
+	"archive/tar"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/app/master/config"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
+)
+
+// testStaticImageFiles is the test image for the static keep set tests
+var testStaticImageFiles = map[string]testImageFile{
+	"/app/":                              {},
+	"/app/start.sh":                      {data: "#!/bin/sh\nexec /usr/bin/server\n"},
+	"/app/main.py":                       {data: "#!/usr/bin/env -S PYTHONUNBUFFERED=1 python3 -u\nprint('ok')\n", mode: 0644},
+	"/app/config.yaml":                   {data: "port: 80\n", mode: 0644},
+	"/app/docs/":                         {},
+	"/app/docs/README":                   {data: "docs\n", mode: 0644},
+	"/app/docs/debug.log":                {data: "log\n", mode: 0644},
+	"/bin/":                              {},
+	"/bin/busybox":                       {data: testELF(testGlibcLoader, "libc.so.6")},
+	"/bin/sh":                            {link: "busybox"},
+	"/etc/":                              {},
+	"/etc/ssl/":                          {},
+	"/etc/ssl/certs/":                    {},
+	"/etc/ssl/certs/ca-certificates.crt": {data: "CERTS\n", mode: 0644},
+	"/etc/ssl/certs/local.pem":           {data: "CERT\n", mode: 0644},
+	"/lib64/":                            {},
+	testGlibcLoader:                      {data: testELF("")},
+	"/lib64/libc.so.6":                   {data: testELF("")},
+	"/opt/":                              {},
+	"/opt/current":                       {link: "/opt/v1"},
+	"/opt/v1/":                           {},
+	"/opt/v1/bin/":                       {},
+	"/opt/v1/bin/tool":                   {data: testELF(testGlibcLoader, "libc.so.6")},
+	"/usr/":                              {},
+	"/usr/bin/":                          {},
+	"/usr/bin/env":                       {link: "/bin/busybox", hardlink: true},
+	"/usr/bin/python3":                   {link: "python3.11"},
+	"/usr/bin/python3.11":                {data: testELF(testGlibcLoader, "libc.so.6")},
+	"/usr/bin/server":                    {data: testELF(testGlibcLoader, "libssl.so.3", "libc.so.6")},
+	"/usr/lib/":                          {},
+	"/usr/lib/libssl.so.3":               {data: testELF("", "libc.so.6")},
+}
+
+func newTestStaticKeepSet(t *testing.T, dir string, excludes ...string) *staticKeepSet {
+	excludePatterns := map[string]*fsutil.AccessInfo{}
+	for _, pattern := range excludes {
+		excludePatterns[pattern] = nil
+	}
+
+	imageFS := testImageFS(t, dir, testStaticImageFiles)
+	imageEnv := []string{"PATH=/usr/bin:/bin", "LD_LIBRARY_PATH=/usr/lib"}
+	return newStaticKeepSet(log.NewEntry(log.StandardLogger()), imageFS, imageEnv, excludePatterns)
+}
+
+// keptRules returns the kept files with their provenance rules and sources ("rule:source")
+func keptRules(s *staticKeepSet) map[string][]string {
+	rules := map[string][]string{}
+	for name, provenance := range s.files {
+		for _, p := range provenance {
+			rules[name] = append(rules[name], p.Rule+":"+p.Source)
+		}
+
+		sort.Strings(rules[name])
+	}
+
+	return rules
+}
+
+func TestStaticKeepSetEntrypoint(t *testing.T) {
+	const (
+		ruleEntrypoint = report.ProvenanceStaticEntrypoint
+		ruleDeps       = report.ProvenanceStaticELFDeps
+		ruleShebang    = report.ProvenanceStaticShebang
+		ruleSymlink    = report.ProvenanceStaticSymlink
+	)
+
+	tt := []struct {
+		cmd      []string
+		workDir  string
+		excludes []string
+		expected map[string][]string
+	}{
+		{
+			cmd:      nil,
+			workDir:  "/",
+			expected: map[string][]string{},
+		},
+		{
+			cmd:      []string{"missing", "arg"},
+			workDir:  "/",
+			expected: map[string][]string{},
+		},
+		{
+			cmd:     []string{"server"},
+			workDir: "/",
+			expected: map[string][]string{
+				"/usr/bin/server":      {ruleEntrypoint + ":server"},
+				"/usr/lib/libssl.so.3": {ruleDeps + ":/usr/bin/server"},
+				"/lib64/libc.so.6":     {ruleDeps + ":/usr/bin/server"},
+				testGlibcLoader:        {ruleDeps + ":/usr/bin/server"},
+			},
+		},
+		{
+			cmd:     []string{"/app/start.sh"},
+			workDir: "/",
+			expected: map[string][]string{
+				"/app/start.sh":    {ruleEntrypoint + ":/app/start.sh"},
+				"/bin/sh":          {ruleShebang + ":/app/start.sh"},
+				"/bin/busybox":     {ruleSymlink + ":/bin/sh"},
+				"/lib64/libc.so.6": {ruleDeps + ":/bin/busybox"},
+				testGlibcLoader:    {ruleDeps + ":/bin/busybox"},
+			},
+		},
+		{
+			cmd:     []string{"sh", "-c", "exec DEBUG=1 server config.yaml --verbose missing.txt"},
+			workDir: "/app",
+			expected: map[string][]string{
+				"/bin/sh": {
+					ruleEntrypoint + ":sh -c exec DEBUG=1 server config.yaml --verbose missing.txt",
+				},
+				"/bin/busybox":     {ruleSymlink + ":/bin/sh"},
+				"/usr/bin/server":  {ruleEntrypoint + ":sh -c exec DEBUG=1 server config.yaml --verbose missing.txt"},
+				"/app/config.yaml": {ruleEntrypoint + ":sh -c exec DEBUG=1 server config.yaml --verbose missing.txt"},
+				"/usr/lib/libssl.so.3": {
+					ruleDeps + ":/usr/bin/server",
+				},
+				"/lib64/libc.so.6": {ruleDeps + ":/bin/busybox", ruleDeps + ":/usr/bin/server"},
+				testGlibcLoader:    {ruleDeps + ":/bin/busybox", ruleDeps + ":/usr/bin/server"},
+			},
+		},
+		{
+			cmd:     []string{"python3", "main.py"},
+			workDir: "/app",
+			expected: map[string][]string{
+				"/usr/bin/python3": {ruleEntrypoint + ":python3 main.py", ruleShebang + ":/app/main.py"},
+				"/usr/bin/python3.11": {
+					ruleSymlink + ":/usr/bin/python3",
+				},
+				"/app/main.py":     {ruleEntrypoint + ":python3 main.py"},
+				"/usr/bin/env":     {ruleShebang + ":/app/main.py"},
+				"/lib64/libc.so.6": {ruleDeps + ":/usr/bin/env", ruleDeps + ":/usr/bin/python3.11"},
+				testGlibcLoader:    {ruleDeps + ":/usr/bin/env", ruleDeps + ":/usr/bin/python3.11"},
+			},
+		},
+		{
+			cmd:     []string{"./tool"},
+			workDir: "/opt/current/bin",
+			expected: map[string][]string{
+				"/opt/current":     {ruleSymlink + ":/opt/current/bin/tool"},
+				"/opt/v1":          {ruleSymlink + ":/opt/current"},
+				"/opt/v1/bin/tool": {ruleEntrypoint + ":./tool"},
+				"/lib64/libc.so.6": {ruleDeps + ":/opt/v1/bin/tool"},
+				testGlibcLoader:    {ruleDeps + ":/opt/v1/bin/tool"},
+			},
+		},
+		{
+			cmd:      []string{"sh", "-c", "server"},
+			workDir:  "/",
+			excludes: []string{"/bin/*", "/usr/lib/**"},
+			expected: map[string][]string{
+				"/usr/bin/server":  {ruleEntrypoint + ":sh -c server"},
+				"/lib64/libc.so.6": {ruleDeps + ":/usr/bin/server"},
+				testGlibcLoader:    {ruleDeps + ":/usr/bin/server"},
+			},
+		},
+	}
+
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	for _, test := range tt {
+		s := newTestStaticKeepSet(t, dir, test.excludes...)
+		s.keepEntrypoint(test.cmd, test.workDir)
+		s.analyze()
+
+		if got := keptRules(s); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q (workdir=%s):\ngot      %q\nexpected %q", test.cmd, test.workDir, got, test.expected)
+		}
+	}
+}
+
+func TestStaticKeepSetKeep(t *testing.T) {
+	tt := []struct {
+		name     string
+		dir      bool
+		excludes []string
+		kept     bool
+		expected []string
+	}{
+		{name: "app/config.yaml", kept: false},
+		{name: "/", kept: false},
+		{name: "/app/missing", kept: false},
+		{name: "/app/config.yaml", excludes: []string{"/app/*.yaml"}, kept: false},
+		{name: "/app/../app/config.yaml", kept: true, expected: []string{"/app/config.yaml"}},
+		{name: "/opt/current/bin/tool", kept: true, expected: []string{"/opt/current", "/opt/v1", "/opt/v1/bin/tool"}},
+		{name: "/bin/sh", kept: true, expected: []string{"/bin/busybox", "/bin/sh"}},
+		{name: "/bin/sh", excludes: []string{"/bin/busybox"}, kept: true, expected: []string{"/bin/sh"}},
+		{
+			name:     "/app/docs",
+			dir:      true,
+			kept:     true,
+			expected: []string{"/app/docs", "/app/docs/README", "/app/docs/debug.log"},
+		},
+		{
+			name:     "/app/docs",
+			dir:      true,
+			excludes: []string{"/app/docs/*.log"},
+			kept:     true,
+			expected: []string{"/app/docs", "/app/docs/README"},
+		},
+		{
+			name:     "/opt/current",
+			dir:      true,
+			kept:     true,
+			expected: []string{"/opt/current", "/opt/v1", "/opt/v1/bin", "/opt/v1/bin/tool"},
+		},
+	}
+
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	for _, test := range tt {
+		s := newTestStaticKeepSet(t, dir, test.excludes...)
+
+		var kept bool
+		if test.dir {
+			kept = s.keepDir(test.name, report.ProvenanceIncludePath, test.name)
+		} else {
+			kept = s.keep(test.name, report.ProvenanceIncludePath, test.name, false)
+		}
+
+		if kept != test.kept {
+			t.Errorf("%s (excludes=%q): got kept=%v expected %v", test.name, test.excludes, kept, test.kept)
+		}
+
+		if got := s.names(); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s (excludes=%q):\ngot      %q\nexpected %q", test.name, test.excludes, got, test.expected)
+		}
+
+		if len(s.pending) != 0 {
+			t.Errorf("%s: unexpected pending files %q", test.name, s.pending)
+		}
+	}
+}
+
+func TestStaticKeepSetCerts(t *testing.T) {
+	tt := []struct {
+		bundles  bool
+		dirs     bool
+		expected []string
+	}{
+		{expected: nil},
+		{
+			bundles:  true,
+			expected: []string{"/etc/ssl/certs/ca-certificates.crt", "/etc/ssl/certs/local.pem"},
+		},
+		{
+			dirs: true,
+			expected: []string{
+				"/etc/ssl/certs",
+				"/etc/ssl/certs/ca-certificates.crt",
+				"/etc/ssl/certs/local.pem",
+			},
+		},
+	}
+
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	imagePkg := &dockerimage.Package{}
+	imagePkg.Certs.Bundles = map[string]struct{}{"/etc/ssl/certs/local.pem": {}}
+
+	for _, test := range tt {
+		s := newTestStaticKeepSet(t, dir)
+		s.keepCerts(imagePkg, false, test.bundles, test.dirs, false, false)
+
+		if got := s.names(); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("bundles=%v dirs=%v:\ngot      %q\nexpected %q", test.bundles, test.dirs, got, test.expected)
+		}
+
+		for name, provenance := range s.files {
+			if len(provenance) != 1 || provenance[0].Rule != report.ProvenanceCerts {
+				t.Errorf("%s: unexpected provenance %+v", name, provenance)
+			}
+		}
+	}
+}
+
+func TestStaticKeepSetSaveToArchive(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	s := newTestStaticKeepSet(t, dir)
+	for _, name := range []string{"/app/config.yaml", "/app/docs/README", "/bin/sh", "/usr/bin/env"} {
+		s.keep(name, report.ProvenanceIncludePath, name, false)
+	}
+
+	archivePath := filepath.Join(dir, "files.tar")
+	afile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tw := tar.NewWriter(afile)
+	for _, hdr := range []*tar.Header{
+		{Name: "app/", Typeflag: tar.TypeDir, Mode: 0700},
+		{Name: "app/config.yaml", Typeflag: tar.TypeReg, Mode: 0600, Size: 4},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+
+		if hdr.Size > 0 {
+			tw.Write([]byte("old\n"))
+		}
+	}
+
+	tw.Close()
+	afile.Close()
+
+	artifacts, err := s.saveToArchive(archivePath, s.names())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		typeflag byte
+		link     string
+		data     string
+	}
+
+	entries := map[string]entry{}
+	var order []string
+	afile, err = os.Open(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer afile.Close()
+
+	tr := tar.NewReader(afile)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := ioutil.ReadAll(tr)
+		if _, found := entries[hdr.Name]; found {
+			t.Errorf("duplicate archive entry: %s", hdr.Name)
+		}
+
+		entries[hdr.Name] = entry{typeflag: hdr.Typeflag, link: hdr.Linkname, data: string(data)}
+		order = append(order, hdr.Name)
+	}
+
+	expectedOrder := []string{
+		"app/",
+		"app/config.yaml",
+		"app/docs/",
+		"app/docs/README",
+		"bin/",
+		"bin/busybox",
+		"bin/sh",
+		"usr/",
+		"usr/bin/",
+		"usr/bin/env",
+	}
+
+	if !reflect.DeepEqual(order, expectedOrder) {
+		t.Errorf("archive entries:\ngot      %q\nexpected %q", order, expectedOrder)
+	}
+
+	if e := entries["app/config.yaml"]; e.data != "old\n" {
+		t.Errorf("app/config.yaml: the existing entry was replaced (data=%q)", e.data)
+	}
+
+	if e := entries["bin/sh"]; e.typeflag != tar.TypeSymlink || e.link != "busybox" {
+		t.Errorf("bin/sh: got type=%c link=%q expected a symlink to 'busybox'", e.typeflag, e.link)
+	}
+
+	if e := entries["usr/bin/env"]; e.data != testStaticImageFiles["/bin/busybox"].data {
+		t.Errorf("usr/bin/env: the hardlink data is not saved")
+	}
+
+	hashes := map[string]string{}
+	for _, props := range artifacts {
+		hashes[props.FilePath] = props.Sha1Hash
+		if len(props.KeptBy) == 0 {
+			t.Errorf("%s: no provenance", props.FilePath)
+		}
+	}
+
+	sha1Hex := func(data string) string {
+		sum := sha1.Sum([]byte(data))
+		return hex.EncodeToString(sum[:])
+	}
+
+	expectedHashes := map[string]string{
+		"/app/config.yaml": "",
+		"/app/docs/README": sha1Hex("docs\n"),
+		"/bin/busybox":     sha1Hex(testStaticImageFiles["/bin/busybox"].data),
+		"/bin/sh":          "",
+		"/usr/bin/env":     sha1Hex(testStaticImageFiles["/bin/busybox"].data),
+	}
+
+	if !reflect.DeepEqual(hashes, expectedHashes) {
+		t.Errorf("artifact hashes:\ngot      %q\nexpected %q", hashes, expectedHashes)
+	}
+}
+
+func TestStaticContainerCmd(t *testing.T) {
+	imageConfig := &dockerapi.Config{
+		Entrypoint: []string{"/entrypoint.sh"},
+		Cmd:        []string{"server", "--port", "80"},
+	}
+
+	tt := []struct {
+		overrides *config.ContainerOverrides
+		expected  []string
+	}{
+		{expected: []string{"/entrypoint.sh", "server", "--port", "80"}},
+		{
+			overrides: &config.ContainerOverrides{},
+			expected:  []string{"/entrypoint.sh", "server", "--port", "80"},
+		},
+		{
+			overrides: &config.ContainerOverrides{Cmd: []string{"worker"}},
+			expected:  []string{"/entrypoint.sh", "worker"},
+		},
+		{
+			overrides: &config.ContainerOverrides{ClearCmd: true},
+			expected:  []string{"/entrypoint.sh"},
+		},
+		{
+			overrides: &config.ContainerOverrides{Entrypoint: []string{"/bin/sh", "-c"}},
+			expected:  []string{"/bin/sh", "-c"},
+		},
+		{
+			overrides: &config.ContainerOverrides{Entrypoint: []string{"/bin/sh", "-c"}, Cmd: []string{"server"}},
+			expected:  []string{"/bin/sh", "-c", "server"},
+		},
+		{
+			overrides: &config.ContainerOverrides{ClearEntrypoint: true},
+			expected:  nil,
+		},
+		{
+			overrides: &config.ContainerOverrides{Entrypoint: []string{""}, Cmd: []string{"server"}},
+			expected:  []string{"server"},
+		},
+	}
+
+	for _, test := range tt {
+		if got := staticContainerCmd(imageConfig, test.overrides); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%+v: got %q expected %q", test.overrides, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package build
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
+	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
+	"github.com/docker-slim/docker-slim/pkg/app/sensor/inspectors/sodeps"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerutil"
+	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	staticIncludesFileName = "static.includes"
+	defaultImagePathEnv    = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+)
+
+// imageFileSystem adapts the image filesystem for the dependency resolver
+type imageFileSystem struct {
+	*dockerimage.FileSystem
+}
+
+func (fs imageFileSystem) Open(name string) (sodeps.File, error) {
+	f, err := fs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// saveImageData saves the target image (reusing the previously saved image data)
+// and loads the image filesystem from the saved image
+func saveImageData(
+	xc *app.ExecutionContext,
+	logger *log.Entry,
+	client *dockerapi.Client,
+	imageInspector *image.Inspector,
+	localVolumePath string) (*dockerimage.Package, *dockerimage.FileSystem, error) {
+	imageID := dockerutil.CleanImageID(imageInspector.ImageInfo.ID)
+	iaPath := filepath.Join(localVolumePath, "image", fmt.Sprintf("%s.tar", imageID))
+	iaPathReady := fmt.Sprintf("%s.ready", iaPath)
+
+	if fsutil.IsRegularFile(iaPath) && fsutil.Exists(iaPathReady) {
+		logger.Debugf("saveImageData: exported image already exists - %s", iaPath)
+	} else {
+		if fsutil.Exists(iaPathReady) {
+			fsutil.Remove(iaPathReady)
+		}
+
+		xc.Out.Info("image.data.save.start")
+		if err := dockerutil.SaveImage(client, imageID, iaPath, false, false); err != nil {
+			return nil, nil, err
+		}
+
+		if err := fsutil.Touch(iaPathReady); err != nil {
+			logger.Debugf("saveImageData: error creating the ready marker - %v", err)
+		}
+
+		xc.Out.Info("image.data.save.end")
+	}
+
+	imagePkg, err := dockerimage.LoadPackage(
+		iaPath,
+		imageID,
+		false,
+		0,
+		false,
+		false,
+		nil,
+		nil,
+		nil,
+		nil,
+		false,
+		false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	imageFS, err := dockerimage.NewFileSystem(iaPath, imagePkg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return imagePkg, imageFS, nil
+}
+
+// resolveStaticIncludes resolves the shared library and interpreter dependencies
+// for the included binaries and executables using the image filesystem
+// (returns the sorted paths to include and the binaries and executables that were resolved)
+func resolveStaticIncludes(
+	logger *log.Entry,
+	imageFS *dockerimage.FileSystem,
+	imageEnv []string,
+	includeBins map[string]*fsutil.AccessInfo,
+	includeExes map[string]*fsutil.AccessInfo) (paths []string, resolvedBins []string, resolvedExes []string) {
+	resolver := sodeps.NewResolver(imageFileSystem{imageFS}, sodeps.LibraryPath(imageEnv))
+	allPaths := map[string]struct{}{}
+
+	addDeps := func(name string, deps []string) {
+		logger.Debugf("resolveStaticIncludes: %s - artifacts (%d):\n%v",
+			name, len(deps), strings.Join(deps, "\n"))
+		for _, dep := range deps {
+			allPaths[dep] = struct{}{}
+		}
+	}
+
+	for binPath := range includeBins {
+		deps, err := resolver.AllDependencies(binPath)
+		if err != nil {
+			logger.Warnf("resolveStaticIncludes: %v - error getting bin artifacts => %v", binPath, err)
+			continue
+		}
+
+		addDeps(binPath, deps)
+		resolvedBins = append(resolvedBins, binPath)
+	}
+
+	searchPath := imageSearchPath(imageEnv)
+	for exeName := range includeExes {
+		exePath, err := resolver.LookPath(exeName, searchPath)
+		if err != nil {
+			logger.Warnf("resolveStaticIncludes: %v - executable not found => %v", exeName, err)
+			continue
+		}
+
+		deps, err := resolver.AllDependencies(exePath)
+		if err != nil {
+			logger.Warnf("resolveStaticIncludes: %v - error getting exe artifacts => %v", exeName, err)
+			continue
+		}
+
+		addDeps(exePath, deps)
+		resolvedExes = append(resolvedExes, exeName)
+	}
+
+	for name := range allPaths {
+		paths = append(paths, name)
+	}
+
+	sort.Strings(paths)
+	sort.Strings(resolvedBins)
+	sort.Strings(resolvedExes)
+	return paths, resolvedBins, resolvedExes
+}
+
+// imageSearchPath returns the executable search path from the image environment variables
+func imageSearchPath(env []string) []string {
+	pathEnv := defaultImagePathEnv
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "PATH=") {
+			pathEnv = strings.TrimPrefix(kv, "PATH=")
+		}
+	}
+
+	return strings.Split(pathEnv, ":")
+}
+
+// saveStaticIncludes saves the resolved include paths (using the 'include-path-file' format)
+func saveStaticIncludes(artifactLocation string, paths []string) (string, error) {
+	filePath := filepath.Join(artifactLocation, staticIncludesFileName)
+	data := strings.Join(paths, "\n")
+	if len(paths) > 0 {
+		data += "\n"
+	}
+
+	if err := ioutil.WriteFile(filePath, []byte(data), 0644); err != nil {
+		return "", err
+	}
+
+	return filePath, nil
+}
@@ -0,0 +1,317 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
+)
+
+const (
+	testImageID     = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testGlibcLoader = "/lib64/ld-linux-x86-64.so.2"
+)
+
+// testImageFile is a file in the test image
+// (the directories end with '/', the symlinks and hardlinks have a link target)
+type testImageFile struct {
+	data     string
+	link     string
+	hardlink bool
+	mode     int64
+}
+
+// testELF creates a minimal 64-bit ELF file with the program interpreter and the needed libraries
+func testELF(interp string, needed ...string) string {
+	const (
+		ehdrSize = 64
+		phdrSize = 56
+		shdrSize = 64
+		dynSize  = 16
+	)
+
+	dynstr := []byte{0}
+	var dyns []elf.Dyn64
+	for _, name := range needed {
+		dyns = append(dyns, elf.Dyn64{Tag: int64(elf.DT_NEEDED), Val: uint64(len(dynstr))})
+		dynstr = append(append(dynstr, name...), 0)
+	}
+
+	dyns = append(dyns, elf.Dyn64{Tag: int64(elf.DT_NULL)})
+	shstrtab := []byte("\x00.dynstr\x00.dynamic\x00.shstrtab\x00")
+
+	phnum := 1
+	if interp != "" {
+		phnum++
+	}
+
+	offset := uint64(ehdrSize + phnum*phdrSize)
+	interpOffset := offset
+	if interp != "" {
+		offset += uint64(len(interp) + 1)
+	}
+
+	dynstrOffset := offset
+	dynOffset := (offset + uint64(len(dynstr)) + 7) &^ 7
+	shstrtabOffset := dynOffset + uint64(len(dyns)*dynSize)
+	shOffset := (shstrtabOffset + uint64(len(shstrtab)) + 7) &^ 7
+
+	var progs []elf.Prog64
+	if interp != "" {
+		progs = append(progs, elf.Prog64{
+			Type:   uint32(elf.PT_INTERP),
+			Off:    interpOffset,
+			Vaddr:  interpOffset,
+			Filesz: uint64(len(interp) + 1),
+			Memsz:  uint64(len(interp) + 1),
+		})
+	}
+
+	progs = append(progs, elf.Prog64{
+		Type:   uint32(elf.PT_DYNAMIC),
+		Off:    dynOffset,
+		Vaddr:  dynOffset,
+		Filesz: uint64(len(dyns) * dynSize),
+		Memsz:  uint64(len(dyns) * dynSize),
+	})
+
+	sections := []elf.Section64{
+		{},
+		{Name: 1, Type: uint32(elf.SHT_STRTAB), Flags: uint64(elf.SHF_ALLOC), Addr: dynstrOffset, Off: dynstrOffset, Size: uint64(len(dynstr))},
+		{Name: 9, Type: uint32(elf.SHT_DYNAMIC), Flags: uint64(elf.SHF_ALLOC | elf.SHF_WRITE), Addr: dynOffset, Off: dynOffset, Size: uint64(len(dyns) * dynSize), Link: 1, Entsize: dynSize},
+		{Name: 18, Type: uint32(elf.SHT_STRTAB), Off: shstrtabOffset, Size: uint64(len(shstrtab))},
+	}
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_DYN),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     ehdrSize,
+		Shoff:     shOffset,
+		Ehsize:    ehdrSize,
+		Phentsize: phdrSize,
+		Phnum:     uint16(len(progs)),
+		Shentsize: shdrSize,
+		Shnum:     uint16(len(sections)),
+		Shstrndx:  3,
+	}
+
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var buf bytes.Buffer
+	write := func(data interface{}) {
+		_ = binary.Write(&buf, binary.LittleEndian, data)
+	}
+
+	pad := func(offset uint64) {
+		for uint64(buf.Len()) < offset {
+			buf.WriteByte(0)
+		}
+	}
+
+	write(&header)
+	write(progs)
+	if interp != "" {
+		buf.WriteString(interp)
+		buf.WriteByte(0)
+	}
+
+	buf.Write(dynstr)
+	pad(dynOffset)
+	write(dyns)
+	buf.Write(shstrtab)
+	pad(shOffset)
+	write(sections)
+	return buf.String()
+}
+
+// testImageFS creates a saved single layer image archive with the files
+// and loads the image filesystem from it
+func testImageFS(t *testing.T, dir string, files map[string]testImageFile) *dockerimage.FileSystem {
+	var layer bytes.Buffer
+	ltw := tar.NewWriter(&layer)
+	for name, file := range files {
+		hdr := &tar.Header{Name: strings.TrimPrefix(name, "/"), Mode: file.mode}
+		switch {
+		case strings.HasSuffix(name, "/"):
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+		case file.hardlink:
+			hdr.Typeflag = tar.TypeLink
+			hdr.Linkname = strings.TrimPrefix(file.link, "/")
+		case file.link != "":
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = file.link
+			hdr.Mode = 0777
+		default:
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(file.data))
+			if hdr.Mode == 0 {
+				hdr.Mode = 0755
+			}
+		}
+
+		if err := ltw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ltw.Write([]byte(file.data)); err != nil && hdr.Typeflag == tar.TypeReg {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ltw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	config, _ := json.Marshal(&dockerimage.ConfigObject{})
+	manifest, _ := json.Marshal([]dockerimage.ManifestObject{
+		{Config: testImageID + ".json", Layers: []string{"layer/layer.tar"}},
+	})
+
+	archivePath := filepath.Join(dir, "image.tar")
+	afile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer afile.Close()
+
+	tw := tar.NewWriter(afile)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{name: "layer/layer.tar", data: layer.Bytes()},
+		{name: testImageID + ".json", data: config},
+		{name: "manifest.json", data: manifest},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: entry.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(entry.data))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write(entry.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := dockerimage.LoadPackage(archivePath, testImageID, false, -1, false, false, nil, nil, nil, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imageFS, err := dockerimage.NewFileSystem(archivePath, pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return imageFS
+}
+
+func testDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "build")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestResolveStaticIncludes(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	imageFS := testImageFS(t, dir, map[string]testImageFile{
+		testGlibcLoader:                            {data: testELF("")},
+		"/lib64/libc.so.6":                         {data: testELF("")},
+		"/etc/ld.so.conf":                          {data: "/usr/lib/x86_64-linux-gnu\n", mode: 0644},
+		"/usr/lib/x86_64-linux-gnu/libz.so.1":      {link: "libz.so.1.2.11"},
+		"/usr/lib/x86_64-linux-gnu/libz.so.1.2.11": {data: testELF("", "libc.so.6")},
+		"/opt/app/lib/libapp.so":                   {data: testELF("", "libc.so.6")},
+		"/usr/bin/app":                             {data: testELF(testGlibcLoader, "libz.so.1", "libapp.so", "libc.so.6")},
+		"/usr/local/bin/tool":                      {data: testELF(testGlibcLoader, "libc.so.6")},
+		"/bin/tool":                                {data: testELF(testGlibcLoader, "libmissing.so")},
+		"/bin/script":                              {data: "#!/bin/sh\n"},
+		"/usr/local/bin/not-executable":            {data: testELF(""), mode: 0644},
+		"/bin/not-executable":                      {data: testELF("")},
+	})
+
+	includeBins := map[string]*fsutil.AccessInfo{
+		"/usr/bin/app":    nil,
+		"/bin/script":     nil,
+		"/missing/binary": nil,
+	}
+
+	includeExes := map[string]*fsutil.AccessInfo{
+		"tool":           nil,
+		"not-executable": nil,
+		"missing":        nil,
+	}
+
+	imageEnv := []string{
+		"PATH=/usr/local/bin:/bin",
+		"LD_LIBRARY_PATH=/opt/app/lib",
+	}
+
+	paths, resolvedBins, resolvedExes := resolveStaticIncludes(log.NewEntry(log.StandardLogger()), imageFS, imageEnv, includeBins, includeExes)
+
+	expectedPaths := []string{
+		"/bin/not-executable",
+		testGlibcLoader,
+		"/lib64/libc.so.6",
+		"/opt/app/lib/libapp.so",
+		"/usr/bin/app",
+		"/usr/lib/x86_64-linux-gnu/libz.so.1",
+		"/usr/lib/x86_64-linux-gnu/libz.so.1.2.11",
+		"/usr/local/bin/tool",
+	}
+
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("paths:\ngot      %q\nexpected %q", paths, expectedPaths)
+	}
+
+	if expected := []string{"/usr/bin/app"}; !reflect.DeepEqual(resolvedBins, expected) {
+		t.Errorf("resolved bins: got %q expected %q", resolvedBins, expected)
+	}
+
+	if expected := []string{"not-executable", "tool"}; !reflect.DeepEqual(resolvedExes, expected) {
+		t.Errorf("resolved exes: got %q expected %q", resolvedExes, expected)
+	}
+}
+
+func TestImageSearchPath(t *testing.T) {
+	tt := []struct {
+		env      []string
+		expected []string
+	}{
+		{expected: []string{"/usr/local/sbin", "/usr/local/bin", "/usr/sbin", "/usr/bin", "/sbin", "/bin"}},
+		{env: []string{"HOME=/root", "PATH=/app/bin:/bin"}, expected: []string{"/app/bin", "/bin"}},
+		{env: []string{"PATH=/old", "PATH=/new"}, expected: []string{"/new"}},
+		{env: []string{"MYPATH=/app/bin"}, expected: []string{"/usr/local/sbin", "/usr/local/bin", "/usr/sbin", "/usr/bin", "/sbin", "/bin"}},
+	}
+
+	for _, test := range tt {
+		if got := imageSearchPath(test.env); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: got %q expected %q", test.env, got, test.expected)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package commands
 import (
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
 )
 
 /////////////////////////////////////////////////////////
@@ -26,6 +28,8 @@ const (
 	FlagInContainer   = "in-container"
 	FlagArchiveState  = "archive-state"
 	FlagNoColor       = "no-color"
+	FlagConsoleOutput = "console-output"
+	FlagExitSummary   = "exit-summary"
 )
 
 // Global flag usage info
@@ -45,6 +49,8 @@ const (
 	FlagInContainerUsage   = "DockerSlim is running in a container"
 	FlagArchiveStateUsage  = "archive DockerSlim state to the selected Docker volume (default volume - docker-slim-state). By default, enabled when DockerSlim is running in a container (disabled otherwise). Set it to \"off\" to disable explicitly."
 	FlagNoColorUsage       = "disable color output"
+	FlagConsoleOutputUsage = "set the console output format ('text' (default), or 'json' - one JSON object per line for each state, info, error and log event)"
+	FlagExitSummaryUsage   = "save the machine-readable exit summary (command, state, exit code, error and command report location) to the selected file when the command exits"
 )
 
 // Shared command flag names
@@ -77,6 +83,8 @@ const (
 
 	FlagRemoveFileArtifacts = "remove-file-artifacts"
 	FlagCopyMetaArtifacts   = "copy-meta-artifacts"
+	FlagSBOM                = "sbom"
+	FlagSeccompArch         = "seccomp-arch"
 
 	FlagHTTPProbe                 = "http-probe"
 	FlagHTTPProbeOff              = "http-probe-off" //alternative way to disable http probing
@@ -172,6 +180,8 @@ const (
 
 	FlagRemoveFileArtifactsUsage = "remove file artifacts when command is done"
 	FlagCopyMetaArtifactsUsage   = "copy metadata artifacts to the selected location when command is done"
+	FlagSBOMUsage                = "Generate SBOM documents in the selected formats (spdx-json, cyclonedx-json)"
+	FlagSeccompArchUsage         = "Generate the Seccomp profile for the selected extra architectures (amd64, arm64, 386, arm or all)"
 
 	FlagHTTPProbeUsage                 = "Enable or disable HTTP probing"
 	FlagHTTPProbeOffUsage              = "Alternative way to disable HTTP probing"
@@ -310,6 +320,18 @@ func GlobalFlags() []cli.Flag {
 			Name:  FlagNoColor,
 			Usage: FlagNoColorUsage,
 		},
+		&cli.StringFlag{
+			Name:    FlagConsoleOutput,
+			Value:   app.ConsoleOutputText,
+			Usage:   FlagConsoleOutputUsage,
+			EnvVars: []string{"DSLIM_CONSOLE_OUTPUT"},
+		},
+		&cli.StringFlag{
+			Name:    FlagExitSummary,
+			Value:   "",
+			Usage:   FlagExitSummaryUsage,
+			EnvVars: []string{"DSLIM_EXIT_SUMMARY"},
+		},
 	}
 }
 
@@ -452,6 +474,18 @@ var CommonFlags = map[string]cli.Flag{
 		Usage:   FlagCopyMetaArtifactsUsage,
 		EnvVars: []string{"DSLIM_CP_META_ARTIFACTS"},
 	},
+	FlagSBOM: &cli.StringSliceFlag{
+		Name:    FlagSBOM,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagSBOMUsage,
+		EnvVars: []string{"DSLIM_SBOM"},
+	},
+	FlagSeccompArch: &cli.StringSliceFlag{
+		Name:    FlagSeccompArch,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagSeccompArchUsage,
+		EnvVars: []string{"DSLIM_SECCOMP_ARCH"},
+	},
 	//
 	FlagHTTPProbe: &cli.BoolFlag{ //true by default
 		Name:    FlagHTTPProbe,
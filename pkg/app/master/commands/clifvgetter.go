@@ -4,13 +4,13 @@ package commands
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/config"
 	"github.com/docker-slim/docker-slim/pkg/app/master/docker/dockerclient"
 	"github.com/docker-slim/docker-slim/pkg/app/master/signals"
@@ -25,7 +25,7 @@ func GetContainerRunOptions(ctx *cli.Context) (*config.ContainerRunOptions, erro
 	if len(sysctlList) > 0 {
 		params, err := ParseTokenMap(sysctlList)
 		if err != nil {
-			fmt.Printf("invalid sysctl options %v\n", err)
+			app.Printf("invalid sysctl options %v\n", err)
 			return nil, err
 		}
 
@@ -35,7 +35,7 @@ func GetContainerRunOptions(ctx *cli.Context) (*config.ContainerRunOptions, erro
 	if len(hostConfigFileName) > 0 {
 		hostConfigBytes, err := ioutil.ReadFile(hostConfigFileName)
 		if err != nil {
-			fmt.Printf("could not read host config file %v: %v\n", hostConfigFileName, err)
+			app.Printf("could not read host config file %v: %v\n", hostConfigFileName, err)
 		}
 		json.Unmarshal(hostConfigBytes, &cro.HostConfig)
 	}
@@ -119,7 +119,7 @@ func GetContainerOverrides(ctx *cli.Context) (*config.ContainerOverrides, error)
 	if len(exposePortList) > 0 {
 		overrides.ExposedPorts, err = ParseDockerExposeOpt(exposePortList)
 		if err != nil {
-			fmt.Printf("invalid expose options..\n\n")
+			app.Printf("invalid expose options..\n\n")
 			return nil, err
 		}
 	}
@@ -127,7 +127,7 @@ func GetContainerOverrides(ctx *cli.Context) (*config.ContainerOverrides, error)
 	if len(volumesList) > 0 {
 		volumes, err := ParseTokenSet(volumesList)
 		if err != nil {
-			fmt.Printf("invalid volume options %v\n", err)
+			app.Printf("invalid volume options %v\n", err)
 			return nil, err
 		}
 
@@ -137,7 +137,7 @@ func GetContainerOverrides(ctx *cli.Context) (*config.ContainerOverrides, error)
 	if len(labelsList) > 0 {
 		labels, err := ParseTokenMap(labelsList)
 		if err != nil {
-			fmt.Printf("invalid label options %v\n", err)
+			app.Printf("invalid label options %v\n", err)
 			return nil, err
 		}
 
@@ -146,7 +146,7 @@ func GetContainerOverrides(ctx *cli.Context) (*config.ContainerOverrides, error)
 
 	overrides.Entrypoint, err = ParseExec(doUseEntrypoint)
 	if err != nil {
-		fmt.Printf("invalid entrypoint option..\n\n")
+		app.Printf("invalid entrypoint option..\n\n")
 		return nil, err
 	}
 
@@ -155,7 +155,7 @@ func GetContainerOverrides(ctx *cli.Context) (*config.ContainerOverrides, error)
 
 	overrides.Cmd, err = ParseExec(doUseCmd)
 	if err != nil {
-		fmt.Printf("invalid cmd option..\n\n")
+		app.Printf("invalid cmd option..\n\n")
 		return nil, err
 	}
 
@@ -17,7 +17,9 @@ import (
 	"github.com/fsouza/go-dockerclient"
 	"github.com/google/shlex"
 
+	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/config"
+	"github.com/docker-slim/docker-slim/pkg/sbom"
 	"github.com/docker-slim/docker-slim/pkg/sysenv"
 	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
 )
@@ -243,6 +245,33 @@ func ParseCheckTags(values []string) (map[string]string, error) {
 	return tags, nil
 }
 
+func ParseSBOMFormats(values []string) ([]string, error) {
+	var formats []string
+	seen := map[string]struct{}{}
+	for _, raw := range values {
+		for _, format := range strings.Split(raw, ",") {
+			format = strings.ToLower(strings.TrimSpace(format))
+			if format == "" {
+				continue
+			}
+
+			if !sbom.IsFormat(format) {
+				return nil, fmt.Errorf("unknown SBOM format: %s (supported formats: %s)",
+					format, strings.Join(sbom.Formats(), ", "))
+			}
+
+			if _, found := seen[format]; found {
+				continue
+			}
+
+			seen[format] = struct{}{}
+			formats = append(formats, format)
+		}
+	}
+
+	return formats, nil
+}
+
 func ParseTokenSetFile(filePath string) (map[string]struct{}, error) {
 	tokens := map[string]struct{}{}
 
@@ -429,7 +458,7 @@ func ParsePaths(values []string) map[string]*fsutil.AccessInfo {
 	for _, raw := range values {
 		pathStr, access, err := ParsePathPerms(raw)
 		if err != nil {
-			fmt.Printf("parsePaths() - skipping %v (error=%v)\n", raw, err)
+			app.Printf("parsePaths() - skipping %v (error=%v)\n", raw, err)
 			continue
 		}
 
@@ -499,7 +528,7 @@ func ParsePathsFile(filePath string) (map[string]*fsutil.AccessInfo, error) {
 		if len(line) != 0 {
 			pathStr, access, err := ParsePathPerms(line)
 			if err != nil {
-				fmt.Printf("parsePathsFile() - skipping %v (error=%v)\n", line, err)
+				app.Printf("parsePathsFile() - skipping %v (error=%v)\n", line, err)
 				continue
 			}
 
@@ -33,9 +33,9 @@ type InteractiveApp struct {
 	dclient     *dockerapi.Client
 }
 
-func NewInteractiveApp(app *cli.App, gparams *GenericParams) *InteractiveApp {
+func NewInteractiveApp(cliApp *cli.App, gparams *GenericParams) *InteractiveApp {
 	ia := InteractiveApp{
-		app: app,
+		app: cliApp,
 		fpCompleter: completer.FilePathCompleter{
 			IgnoreCase: true,
 		},
@@ -47,8 +47,8 @@ func NewInteractiveApp(app *cli.App, gparams *GenericParams) *InteractiveApp {
 		if gparams.InContainer && gparams.IsDSImage {
 			exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
 		}
-		fmt.Printf("docker-slim: info=docker.connect.error message='%s'\n", exitMsg)
-		fmt.Printf("docker-slim: state=exited version=%s location='%s'\n", version.Current(), fsutil.ExeDir())
+		app.Printf("docker-slim: info=docker.connect.error message='%s'\n", exitMsg)
+		app.Printf("docker-slim: state=exited version=%s location='%s'\n", version.Current(), fsutil.ExeDir())
 		os.Exit(-777)
 	}
 	errutil.FailOn(err)
@@ -218,6 +218,8 @@ var GlobalFlagSuggestions = []prompt.Suggest{
 	{Text: FullFlagName(FlagInContainer), Description: FlagInContainerUsage},
 	{Text: FullFlagName(FlagCheckVersion), Description: FlagCheckVersionUsage},
 	{Text: FullFlagName(FlagNoColor), Description: FlagNoColorUsage},
+	{Text: FullFlagName(FlagConsoleOutput), Description: FlagConsoleOutputUsage},
+	{Text: FullFlagName(FlagExitSummary), Description: FlagExitSummaryUsage},
 }
 
 func FullFlagName(name string) string {
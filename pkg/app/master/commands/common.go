@@ -9,6 +9,7 @@ import (
 
 	"github.com/docker-slim/docker-slim/pkg/app/master/config"
 	"github.com/docker-slim/docker-slim/pkg/docker/dockerutil"
+	"github.com/docker-slim/docker-slim/pkg/sbom"
 	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
 )
 
@@ -137,3 +138,20 @@ func ConfirmNetwork(logger *log.Entry, client *docker.Client, network string) bo
 }
 
 var CLI []*cli.Command
+
+// SaveSBOMs saves the SBOM document in the selected formats to the artifact location
+// (returns the SBOM file names)
+func SaveSBOMs(logger *log.Entry, doc *sbom.Document, formats []string, artifactLocation string) []string {
+	var names []string
+	for _, format := range formats {
+		name := sbom.FileName(format)
+		if err := doc.Save(format, filepath.Join(artifactLocation, name)); err != nil {
+			logger.Errorf("error saving SBOM (%s) - %v", format, err)
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
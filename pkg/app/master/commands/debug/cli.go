@@ -1,8 +1,6 @@
 package debug
 
 import (
-	"fmt"
-
 	"github.com/urfave/cli/v2"
 
 	"github.com/docker-slim/docker-slim/pkg/app"
@@ -23,7 +21,7 @@ var CLI = &cli.Command{
 	Usage:   Usage,
 	Action: func(ctx *cli.Context) error {
 		if ctx.Args().Len() < 1 {
-			fmt.Printf("docker-slim[%s]: missing target info...\n\n", Name)
+			app.Printf("docker-slim[%s]: missing target info...\n\n", Name)
 			cli.ShowCommandHelp(ctx, Name)
 			return nil
 		}
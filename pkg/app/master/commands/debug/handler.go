@@ -63,7 +63,7 @@ func OnCommand(
 	errutil.FailOn(err)
 
 	if gparams.Debug {
-		version.Print(prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		version.Print(xc, prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
 	}
 
 	xc.Out.State("completed")
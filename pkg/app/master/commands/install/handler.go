@@ -1,13 +1,13 @@
 package install
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/docker-slim/go-update"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/util/errutil"
 	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
 	vinfo "github.com/docker-slim/docker-slim/pkg/version"
@@ -30,12 +30,12 @@ func OnCommand(doDebug bool, statePath, archiveState string, inContainer, isDSIm
 	if dockerCLIPlugin {
 		err := installDockerCLIPlugin(logger, statePath, inContainer, isDSImage, appDirPath)
 		if err != nil {
-			fmt.Printf("docker-slim[install]: info=status message='error installing as Docker CLI plugin'\n")
-			fmt.Printf("docker-slim[install]: state=exited version=%s\n", vinfo.Current())
+			app.Printf("docker-slim[install]: info=status message='error installing as Docker CLI plugin'\n")
+			app.Printf("docker-slim[install]: state=exited version=%s\n", vinfo.Current())
 			return
 		}
 
-		fmt.Printf("docker-slim[install]: state=docker.cli.plugin.installed\n")
+		app.Printf("docker-slim[install]: state=docker.cli.plugin.installed\n")
 	}
 }
 
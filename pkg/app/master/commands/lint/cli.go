@@ -5,6 +5,8 @@ import (
 
 	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
+	"github.com/docker-slim/docker-slim/pkg/docker/linter"
+	"github.com/docker-slim/docker-slim/pkg/docker/linter/check"
 )
 
 const (
@@ -32,6 +34,8 @@ var CLI = &cli.Command{
 		cflag(FlagShowNoHits),
 		cflag(FlagShowSnippet),
 		cflag(FlagListChecks),
+		cflag(FlagSARIFReport),
+		cflag(FlagConfigFile),
 	},
 	Action: func(ctx *cli.Context) error {
 		xc := app.NewExecutionContext(Name)
@@ -138,6 +142,20 @@ var CLI = &cli.Command{
 
 		doShowNoHits := ctx.Bool(FlagShowNoHits)
 		doShowSnippet := ctx.Bool(FlagShowSnippet)
+		sarifReportPath := ctx.String(FlagSARIFReport)
+
+		var checkConfig map[string]*check.Options
+		if configFile := ctx.String(FlagConfigFile); configFile != "" {
+			checkConfig, err = linter.LoadConfig(configFile)
+			if err != nil {
+				xc.Out.Error("param.error.invalid.config.file", err.Error())
+				xc.Out.State("exited",
+					ovars{
+						"exit.code": -1,
+					})
+				xc.Exit(-1)
+			}
+		}
 
 		OnCommand(
 			xc,
@@ -153,7 +171,9 @@ var CLI = &cli.Command{
 			excludeCheckIDs,
 			doShowNoHits,
 			doShowSnippet,
-			doListChecks)
+			doListChecks,
+			sarifReportPath,
+			checkConfig)
 
 		return nil
 	},
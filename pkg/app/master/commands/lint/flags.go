@@ -22,6 +22,8 @@ const (
 	FlagShowNoHits         = "show-nohits"
 	FlagShowSnippet        = "show-snippet"
 	FlagListChecks         = "list-checks"
+	FlagSARIFReport        = "sarif-report"
+	FlagConfigFile         = "config-file"
 )
 
 // Lint command flag usage info
@@ -40,6 +42,8 @@ const (
 	FlagShowNoHitsUsage         = "Show checks with no matches"
 	FlagShowSnippetUsage        = "Show check match snippet"
 	FlagListChecksUsage         = "List available checks"
+	FlagSARIFReportUsage        = "Save the lint results in the SARIF format to the selected file"
+	FlagConfigFileUsage         = "Lint config file (YAML or JSON) with the check options and level overrides"
 )
 
 var Flags = map[string]cli.Flag{
@@ -123,6 +127,18 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagListChecksUsage,
 		EnvVars: []string{"DSLIM_LINT_LIST_CHECKS"},
 	},
+	FlagSARIFReport: &cli.StringFlag{
+		Name:    FlagSARIFReport,
+		Value:   "",
+		Usage:   FlagSARIFReportUsage,
+		EnvVars: []string{"DSLIM_LINT_SARIF_REPORT"},
+	},
+	FlagConfigFile: &cli.StringFlag{
+		Name:    FlagConfigFile,
+		Value:   "",
+		Usage:   FlagConfigFileUsage,
+		EnvVars: []string{"DSLIM_LINT_CONFIG_FILE"},
+	},
 }
 
 func cflag(name string) cli.Flag {
@@ -1,7 +1,10 @@
 package lint
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/docker-slim/docker-slim/pkg/app"
@@ -12,6 +15,7 @@ import (
 	"github.com/docker-slim/docker-slim/pkg/docker/linter/check"
 	"github.com/docker-slim/docker-slim/pkg/report"
 	"github.com/docker-slim/docker-slim/pkg/util/errutil"
+	v "github.com/docker-slim/docker-slim/pkg/version"
 
 	dockerapi "github.com/fsouza/go-dockerclient"
 	log "github.com/sirupsen/logrus"
@@ -21,6 +25,8 @@ const appName = commands.AppName
 
 type ovars = app.OutVars
 
+const sarifToolInfoURI = "https://github.com/docker-slim/docker-slim"
+
 // OnCommand implements the 'lint' docker-slim command
 func OnCommand(
 	xc *app.ExecutionContext,
@@ -36,7 +42,9 @@ func OnCommand(
 	excludeCheckIDs map[string]struct{},
 	doShowNoHits bool,
 	doShowSnippet bool,
-	doListChecks bool) {
+	doListChecks bool,
+	sarifReportPath string,
+	checkConfig map[string]*check.Options) {
 	const cmdName = Name
 	logger := log.WithFields(log.Fields{"app": appName, "command": cmdName})
 	prefix := fmt.Sprintf("cmd=%s", cmdName)
@@ -61,8 +69,8 @@ func OnCommand(
 			if gparams.InContainer && gparams.IsDSImage {
 				exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
 			}
-			fmt.Printf("cmd=%s info=docker.connect.error message='%s'\n", cmdName, exitMsg)
-			fmt.Printf("cmd=%s state=exited version=%s location='%s'\n", cmdName, v.Current(), fsutil.ExeDir())
+			app.Printf("cmd=%s info=docker.connect.error message='%s'\n", cmdName, exitMsg)
+			app.Printf("cmd=%s state=exited version=%s location='%s'\n", cmdName, v.Current(), fsutil.ExeDir())
 			os.Exit(ectCommon | ecNoDockerConnectInfo)
 		}
 		errutil.FailOn(err)
@@ -70,7 +78,7 @@ func OnCommand(
 	var client *dockerapi.Client
 
 	if gparams.Debug {
-		version.Print(prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		version.Print(xc, prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
 	}
 
 	if doListChecks {
@@ -91,6 +99,7 @@ func OnCommand(
 				ExcludeCheckLabels: excludeCheckLabels,
 				ExcludeCheckIDs:    excludeCheckIDs,
 			},
+			Config: checkConfig,
 		}
 
 		lintResults, err := linter.Execute(options)
@@ -101,6 +110,10 @@ func OnCommand(
 		cmdReport.Errors = lintResults.Errors
 
 		printLintResults(xc, lintResults, appName, cmdName, cmdReport, doShowNoHits, doShowSnippet)
+
+		if sarifReportPath != "" {
+			saveSARIFReport(xc, lintResults, targetRef, sarifReportPath)
+		}
 	}
 
 	xc.Out.State("completed")
@@ -256,3 +269,32 @@ func printLintResults(
 		}
 	}
 }
+
+func saveSARIFReport(
+	xc *app.ExecutionContext,
+	lintResults *linter.Report,
+	dockerfilePath string,
+	reportPath string) {
+	sarifLog := lintResults.SARIF(dockerfilePath,
+		linter.SARIFToolInfo{
+			Name:           appName,
+			Version:        v.Current(),
+			InformationURI: sarifToolInfoURI,
+		})
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(sarifLog)
+	xc.FailOn(err)
+
+	err = ioutil.WriteFile(reportPath, out.Bytes(), 0644)
+	xc.FailOn(err)
+
+	xc.Out.Info("report.sarif",
+		ovars{
+			"file":    reportPath,
+			"results": len(sarifLog.Runs[0].Results),
+		})
+}
@@ -28,6 +28,8 @@ var CommandFlagSuggestions = &commands.FlagSuggestions{
 		{Text: commands.FullFlagName(FlagShowNoHits), Description: FlagShowNoHitsUsage},
 		{Text: commands.FullFlagName(FlagShowSnippet), Description: FlagShowSnippetUsage},
 		{Text: commands.FullFlagName(FlagListChecks), Description: FlagListChecksUsage},
+		{Text: commands.FullFlagName(FlagSARIFReport), Description: FlagSARIFReportUsage},
+		{Text: commands.FullFlagName(FlagConfigFile), Description: FlagConfigFileUsage},
 	},
 	Values: map[string]commands.CompleteValue{
 		commands.FullFlagName(commands.FlagTarget):    completeLintTarget,
@@ -42,6 +44,8 @@ var CommandFlagSuggestions = &commands.FlagSuggestions{
 		commands.FullFlagName(FlagShowNoHits):         commands.CompleteBool,
 		commands.FullFlagName(FlagShowSnippet):        commands.CompleteTBool,
 		commands.FullFlagName(FlagListChecks):         commands.CompleteBool,
+		commands.FullFlagName(FlagSARIFReport):        commands.CompleteFile,
+		commands.FullFlagName(FlagConfigFile):         commands.CompleteFile,
 	},
 }
 
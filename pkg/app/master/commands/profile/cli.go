@@ -6,6 +6,7 @@ import (
 	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
 	"github.com/docker-slim/docker-slim/pkg/app/master/config"
+	"github.com/docker-slim/docker-slim/pkg/app/master/security/seccomp"
 
 	"github.com/urfave/cli/v2"
 )
@@ -67,6 +68,7 @@ var CLI = &cli.Command{
 		commands.Cflag(commands.FlagRunTargetAsUser),
 		commands.Cflag(commands.FlagShowContainerLogs),
 		commands.Cflag(commands.FlagCopyMetaArtifacts),
+		commands.Cflag(commands.FlagSeccompArch),
 		commands.Cflag(commands.FlagRemoveFileArtifacts),
 		commands.Cflag(commands.FlagExec),
 		commands.Cflag(commands.FlagExecFile),
@@ -259,6 +261,16 @@ var CLI = &cli.Command{
 
 		doRunTargetAsUser := ctx.Bool(commands.FlagRunTargetAsUser)
 
+		seccompArchs, err := seccomp.ParseArchNames(ctx.StringSlice(commands.FlagSeccompArch))
+		if err != nil {
+			xc.Out.Error("param.error.seccomp.arch", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
 		doShowContainerLogs := ctx.Bool(commands.FlagShowContainerLogs)
 		overrides, err := commands.GetContainerOverrides(ctx)
 		if err != nil {
@@ -382,6 +394,7 @@ var CLI = &cli.Command{
 			doPublishExposedPorts,
 			doRmFileArtifacts,
 			doCopyMetaArtifacts,
+			seccompArchs,
 			doRunTargetAsUser,
 			doShowContainerLogs,
 			overrides,
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/docker-slim/docker-slim/pkg/app"
@@ -14,9 +16,11 @@ import (
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/container"
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/container/probes/http"
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
+	"github.com/docker-slim/docker-slim/pkg/app/master/security/kubernetes"
 	"github.com/docker-slim/docker-slim/pkg/app/master/version"
 	"github.com/docker-slim/docker-slim/pkg/command"
 	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/system"
 	"github.com/docker-slim/docker-slim/pkg/util/errutil"
 	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
 	v "github.com/docker-slim/docker-slim/pkg/version"
@@ -67,6 +71,7 @@ func OnCommand(
 	doPublishExposedPorts bool,
 	doRmFileArtifacts bool,
 	copyMetaArtifactsLocation string,
+	seccompArchs []system.ArchName,
 	doRunTargetAsUser bool,
 	doShowContainerLogs bool,
 	overrides *config.ContainerOverrides,
@@ -130,7 +135,7 @@ func OnCommand(
 	errutil.FailOn(err)
 
 	if gparams.Debug {
-		version.Print(prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		version.Print(xc, prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
 	}
 
 	if overrides.Network == "host" && runtime.GOOS == "darwin" {
@@ -169,6 +174,7 @@ func OnCommand(
 
 	imageInspector, err := image.NewInspector(client, targetRef)
 	errutil.FailOn(err)
+	imageInspector.SeccompArchs = seccompArchs
 
 	if imageInspector.NoImage() {
 		if doPull {
@@ -420,6 +426,61 @@ func OnCommand(
 	err = containerInspector.ProcessCollectedData()
 	errutil.FailOn(err)
 
+	var missingArchs []string
+	for arch := range containerInspector.SeccompMissingCalls {
+		missingArchs = append(missingArchs, arch)
+	}
+
+	sort.Strings(missingArchs)
+	for _, arch := range missingArchs {
+		xc.Out.Info("seccomp.arch.missing.syscalls",
+			ovars{
+				"arch":     arch,
+				"syscalls": strings.Join(containerInspector.SeccompMissingCalls[arch], ","),
+			})
+	}
+
+	cmdReport.SeccompMissingSyscalls = containerInspector.SeccompMissingCalls
+
+	if recs := containerInspector.Capabilities; recs != nil {
+		xc.Out.Info("capabilities",
+			ovars{
+				"used":             strings.Join(recs.Capabilities, ","),
+				"docker.run.flags": recs.DockerRunFlags,
+			})
+
+		cmdReport.CapabilitiesFileName = imageInspector.CapabilitiesFileName
+		cmdReport.Capabilities = recs.Capabilities
+	}
+
+	k8sOptions := &kubernetes.Options{
+		Name:               imageInspector.KubernetesName,
+		Image:              targetRef,
+		User:               containerInspector.AppUser(),
+		SeccompProfileName: imageInspector.SeccompProfileName,
+		FilesDirName:       container.FileArtifactsDirName,
+	}
+
+	for k := range imageInspector.ImageInfo.Config.ExposedPorts {
+		k8sOptions.ExposedPorts = append(k8sOptions.ExposedPorts, string(k))
+	}
+
+	for k := range imageInspector.ImageInfo.Config.Volumes {
+		k8sOptions.Volumes = append(k8sOptions.Volumes, k)
+	}
+
+	cmdReport.KubernetesArtifacts, err = kubernetes.GenArtifacts(artifactLocation, k8sOptions)
+	if err != nil {
+		logger.Errorf("error generating Kubernetes artifacts - %v", err)
+	}
+
+	for _, name := range cmdReport.KubernetesArtifacts {
+		xc.Out.Info("artifacts",
+			ovars{
+				"kubernetes": name,
+			})
+	}
+
 	xc.Out.State("container.inspection.done")
 	xc.Out.State("completed")
 
@@ -431,6 +492,13 @@ func OnCommand(
 			imageInspector.SeccompProfileName,
 			imageInspector.AppArmorProfileName,
 		}
+
+		if cmdReport.CapabilitiesFileName != "" {
+			toCopy = append(toCopy, cmdReport.CapabilitiesFileName)
+		}
+
+		toCopy = append(toCopy, cmdReport.KubernetesArtifacts...)
+
 		if !commands.CopyMetaArtifacts(logger,
 			toCopy,
 			artifactLocation, copyMetaArtifactsLocation) {
@@ -2,9 +2,13 @@ package registry
 
 import (
 	"fmt"
+	"strings"
+
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
 
 	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
+	"github.com/docker-slim/docker-slim/pkg/app/master/registryclient"
 
 	"github.com/urfave/cli/v2"
 )
@@ -27,14 +31,120 @@ func fullCmdName(subCmdName string) string {
 }
 
 type PullCommandParams struct {
-	TargetRef    string
-	SaveToDocker bool
+	TargetRef        string
+	SaveToDocker     bool
+	SaveToOCILayout  string
+	SaveToArchive    string
+	ListManifests    bool
+	Platforms        []gocrv1.Platform
+	Insecure         bool
+	DockerConfigPath string
+	RegistryAccount  string
+	RegistrySecret   string
+}
+
+type PushCommandParams struct {
+	TargetRef        string
+	Source           string
+	SourcePath       string
+	As               string
+	Insecure         bool
+	DockerConfigPath string
+	RegistryAccount  string
+	RegistrySecret   string
+}
+
+func PushCommandFlagValues(ctx *cli.Context) (*PushCommandParams, error) {
+	values := &PushCommandParams{
+		TargetRef:        ctx.String(commands.FlagTarget),
+		Source:           ctx.String(FlagSource),
+		SourcePath:       ctx.String(FlagSourcePath),
+		As:               ctx.String(FlagAs),
+		Insecure:         ctx.Bool(FlagInsecure),
+		DockerConfigPath: ctx.String(commands.FlagDockerConfigPath),
+		RegistryAccount:  ctx.String(commands.FlagRegistryAccount),
+		RegistrySecret:   ctx.String(commands.FlagRegistrySecret),
+	}
+
+	if !registryclient.IsValidSource(values.Source) ||
+		values.Source == registryclient.SourceRegistry {
+		return nil, fmt.Errorf("unsupported image source - '%s'", values.Source)
+	}
+
+	if values.Source != registryclient.SourceDocker && values.SourcePath == "" {
+		return nil, fmt.Errorf("missing image source path for '%s'", values.Source)
+	}
+
+	return values, nil
+}
+
+type CopyCommandParams struct {
+	TargetRef        string
+	Destinations     []string
+	Platforms        []gocrv1.Platform
+	Insecure         bool
+	DockerConfigPath string
+	RegistryAccount  string
+	RegistrySecret   string
+}
+
+func CopyCommandFlagValues(ctx *cli.Context) (*CopyCommandParams, error) {
+	values := &CopyCommandParams{
+		TargetRef:        ctx.String(commands.FlagTarget),
+		Insecure:         ctx.Bool(FlagInsecure),
+		DockerConfigPath: ctx.String(commands.FlagDockerConfigPath),
+		RegistryAccount:  ctx.String(commands.FlagRegistryAccount),
+		RegistrySecret:   ctx.String(commands.FlagRegistrySecret),
+	}
+
+	for _, dest := range ctx.StringSlice(FlagTo) {
+		if dest = strings.TrimSpace(dest); dest != "" {
+			values.Destinations = append(values.Destinations, dest)
+		}
+	}
+
+	//positional args: [source] destination...
+	args := ctx.Args().Slice()
+	if values.TargetRef == "" && len(args) > 0 {
+		values.TargetRef = args[0]
+		args = args[1:]
+	}
+
+	values.Destinations = append(values.Destinations, args...)
+
+	var err error
+	values.Platforms, err = registryclient.ParsePlatforms(ctx.StringSlice(FlagPlatform))
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
 }
 
 func PullCommandFlagValues(ctx *cli.Context) (*PullCommandParams, error) {
 	values := &PullCommandParams{
-		TargetRef:    ctx.String(commands.FlagTarget),
-		SaveToDocker: ctx.Bool(FlagSaveToDocker),
+		TargetRef:        ctx.String(commands.FlagTarget),
+		SaveToDocker:     ctx.Bool(FlagSaveToDocker),
+		SaveToOCILayout:  ctx.String(FlagSaveToOCILayout),
+		SaveToArchive:    ctx.String(FlagSaveToArchive),
+		ListManifests:    ctx.Bool(FlagListManifests),
+		Insecure:         ctx.Bool(FlagInsecure),
+		DockerConfigPath: ctx.String(commands.FlagDockerConfigPath),
+		RegistryAccount:  ctx.String(commands.FlagRegistryAccount),
+		RegistrySecret:   ctx.String(commands.FlagRegistrySecret),
+	}
+
+	//saving to docker only if it's explicitly selected when there are other outputs
+	//(or when the manifests are only listed)
+	if (values.SaveToOCILayout != "" || values.SaveToArchive != "" || values.ListManifests) &&
+		!ctx.IsSet(FlagSaveToDocker) {
+		values.SaveToDocker = false
+	}
+
+	var err error
+	values.Platforms, err = registryclient.ParsePlatforms(ctx.StringSlice(FlagPlatform))
+	if err != nil {
+		return nil, err
 	}
 
 	return values, nil
@@ -50,7 +160,15 @@ var CLI = &cli.Command{
 			Usage: PullCmdNameUsage,
 			Flags: []cli.Flag{
 				commands.Cflag(commands.FlagTarget),
+				commands.Cflag(commands.FlagDockerConfigPath),
+				commands.Cflag(commands.FlagRegistryAccount),
+				commands.Cflag(commands.FlagRegistrySecret),
 				cflag(FlagSaveToDocker),
+				cflag(FlagSaveToOCILayout),
+				cflag(FlagSaveToArchive),
+				cflag(FlagListManifests),
+				cflag(FlagPlatform),
+				cflag(FlagInsecure),
 			},
 			Action: func(ctx *cli.Context) error {
 				xc := app.NewExecutionContext(fullCmdName(PullCmdName))
@@ -62,7 +180,12 @@ var CLI = &cli.Command{
 
 				cparams, err := PullCommandFlagValues(ctx)
 				if err != nil {
-					return err
+					xc.Out.Error("param.error", err.Error())
+					xc.Out.State("exited",
+						ovars{
+							"exit.code": -1,
+						})
+					xc.Exit(-1)
 				}
 
 				if cparams.TargetRef == "" {
@@ -82,28 +205,92 @@ var CLI = &cli.Command{
 		{
 			Name:  PushCmdName,
 			Usage: PushCmdNameUsage,
+			Flags: []cli.Flag{
+				commands.Cflag(commands.FlagTarget),
+				commands.Cflag(commands.FlagDockerConfigPath),
+				commands.Cflag(commands.FlagRegistryAccount),
+				commands.Cflag(commands.FlagRegistrySecret),
+				cflag(FlagSource),
+				cflag(FlagSourcePath),
+				cflag(FlagAs),
+				cflag(FlagInsecure),
+			},
 			Action: func(ctx *cli.Context) error {
+				xc := app.NewExecutionContext(fullCmdName(PushCmdName))
+
 				gcvalues, err := commands.GlobalFlagValues(ctx)
 				if err != nil {
 					return err
 				}
 
-				xc := app.NewExecutionContext(fullCmdName(PushCmdName))
-				OnPushCommand(xc, gcvalues)
+				cparams, err := PushCommandFlagValues(ctx)
+				if err != nil {
+					xc.Out.Error("param.error", err.Error())
+					xc.Out.State("exited",
+						ovars{
+							"exit.code": -1,
+						})
+					xc.Exit(-1)
+				}
+
+				if cparams.TargetRef == "" {
+					if ctx.Args().Len() < 1 {
+						xc.Out.Error("param.target", "missing target")
+						cli.ShowCommandHelp(ctx, Name)
+						return nil
+					} else {
+						cparams.TargetRef = ctx.Args().First()
+					}
+				}
+
+				OnPushCommand(xc, gcvalues, cparams)
 				return nil
 			},
 		},
 		{
-			Name:  CopyCmdName,
-			Usage: CopyCmdNameUsage,
+			Name:      CopyCmdName,
+			Usage:     CopyCmdNameUsage,
+			ArgsUsage: "[SOURCE_IMAGE] DESTINATION_IMAGE...",
+			Flags: []cli.Flag{
+				commands.Cflag(commands.FlagTarget),
+				commands.Cflag(commands.FlagDockerConfigPath),
+				commands.Cflag(commands.FlagRegistryAccount),
+				commands.Cflag(commands.FlagRegistrySecret),
+				cflag(FlagTo),
+				cflag(FlagPlatform),
+				cflag(FlagInsecure),
+			},
 			Action: func(ctx *cli.Context) error {
+				xc := app.NewExecutionContext(fullCmdName(CopyCmdName))
+
 				gcvalues, err := commands.GlobalFlagValues(ctx)
 				if err != nil {
 					return err
 				}
 
-				xc := app.NewExecutionContext(fullCmdName(CopyCmdName))
-				OnCopyCommand(xc, gcvalues)
+				cparams, err := CopyCommandFlagValues(ctx)
+				if err != nil {
+					xc.Out.Error("param.error", err.Error())
+					xc.Out.State("exited",
+						ovars{
+							"exit.code": -1,
+						})
+					xc.Exit(-1)
+				}
+
+				if cparams.TargetRef == "" {
+					xc.Out.Error("param.target", "missing target")
+					cli.ShowCommandHelp(ctx, CopyCmdName)
+					return nil
+				}
+
+				if len(cparams.Destinations) == 0 {
+					xc.Out.Error("param.to", "missing destination")
+					cli.ShowCommandHelp(ctx, CopyCmdName)
+					return nil
+				}
+
+				OnCopyCommand(xc, gcvalues, cparams)
 				return nil
 			},
 		},
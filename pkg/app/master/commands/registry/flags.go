@@ -3,16 +3,38 @@ package registry
 import (
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
+
+	"github.com/docker-slim/docker-slim/pkg/app/master/registryclient"
 )
 
 // Registry command flag names
 const (
 	FlagSaveToDocker = "save-to-docker"
+	FlagSource       = "source"
+	FlagSourcePath   = "source-path"
+	FlagAs           = "as"
+	FlagTo           = "to"
+	FlagPlatform     = "platform"
+	FlagInsecure     = "insecure-registry"
+
+	FlagListManifests   = "list-manifests"
+	FlagSaveToOCILayout = "save-to-oci-layout"
+	FlagSaveToArchive   = "save-to-archive"
 )
 
 // Registry command flag usage info
 const (
-	FlagSaveToDockerUsage = "Save pulled image to docker"
+	FlagSaveToDockerUsage = "Save pulled image to docker (disabled by default if other pull outputs are selected)"
+	FlagSourceUsage       = "Image source type: 'docker' (local Docker image), 'oci-layout' (OCI image layout directory) or 'docker-archive' ('docker save' tarball)"
+	FlagSourcePathUsage   = "Image source location (OCI image layout directory or 'docker save' tarball)"
+	FlagAsUsage           = "Destination image reference (defaults to the target reference)"
+	FlagToUsage           = "Destination image reference (can be used multiple times to copy to multiple registries)"
+	FlagPlatformUsage     = "Platform to select from multi-platform images ('os/arch[/variant]', can be used multiple times)"
+	FlagInsecureUsage     = "Use plain HTTP to connect to the registries"
+
+	FlagListManifestsUsage   = "List the manifests in the image index (without pulling the images)"
+	FlagSaveToOCILayoutUsage = "Save pulled image to an OCI image layout directory"
+	FlagSaveToArchiveUsage   = "Save pulled image to a 'docker save' compatible tarball"
 )
 
 var Flags = map[string]cli.Flag{
@@ -22,6 +44,60 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagSaveToDockerUsage,
 		EnvVars: []string{"DSLIM_REG_PULL_SAVE_TO_DOCKER"},
 	},
+	FlagSource: &cli.StringFlag{
+		Name:    FlagSource,
+		Value:   registryclient.SourceDocker,
+		Usage:   FlagSourceUsage,
+		EnvVars: []string{"DSLIM_REG_PUSH_SOURCE"},
+	},
+	FlagSourcePath: &cli.StringFlag{
+		Name:    FlagSourcePath,
+		Value:   "",
+		Usage:   FlagSourcePathUsage,
+		EnvVars: []string{"DSLIM_REG_PUSH_SOURCE_PATH"},
+	},
+	FlagAs: &cli.StringFlag{
+		Name:    FlagAs,
+		Value:   "",
+		Usage:   FlagAsUsage,
+		EnvVars: []string{"DSLIM_REG_PUSH_AS"},
+	},
+	FlagTo: &cli.StringSliceFlag{
+		Name:    FlagTo,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagToUsage,
+		EnvVars: []string{"DSLIM_REG_COPY_TO"},
+	},
+	FlagPlatform: &cli.StringSliceFlag{
+		Name:    FlagPlatform,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagPlatformUsage,
+		EnvVars: []string{"DSLIM_REG_PLATFORM"},
+	},
+	FlagInsecure: &cli.BoolFlag{
+		Name:    FlagInsecure,
+		Value:   false,
+		Usage:   FlagInsecureUsage,
+		EnvVars: []string{"DSLIM_REG_INSECURE"},
+	},
+	FlagListManifests: &cli.BoolFlag{
+		Name:    FlagListManifests,
+		Value:   false,
+		Usage:   FlagListManifestsUsage,
+		EnvVars: []string{"DSLIM_REG_PULL_LIST_MANIFESTS"},
+	},
+	FlagSaveToOCILayout: &cli.StringFlag{
+		Name:    FlagSaveToOCILayout,
+		Value:   "",
+		Usage:   FlagSaveToOCILayoutUsage,
+		EnvVars: []string{"DSLIM_REG_PULL_SAVE_TO_OCI_LAYOUT"},
+	},
+	FlagSaveToArchive: &cli.StringFlag{
+		Name:    FlagSaveToArchive,
+		Value:   "",
+		Usage:   FlagSaveToArchiveUsage,
+		EnvVars: []string{"DSLIM_REG_PULL_SAVE_TO_ARCHIVE"},
+	},
 }
 
 func cflag(name string) cli.Flag {
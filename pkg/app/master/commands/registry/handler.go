@@ -2,16 +2,20 @@ package registry
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/google/go-containerregistry/pkg/crane"
+	dockerapi "github.com/fsouza/go-dockerclient"
 	"github.com/google/go-containerregistry/pkg/name"
 	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
 	"github.com/docker-slim/docker-slim/pkg/app/master/docker/dockerclient"
+	"github.com/docker-slim/docker-slim/pkg/app/master/registryclient"
 	"github.com/docker-slim/docker-slim/pkg/app/master/version"
 	"github.com/docker-slim/docker-slim/pkg/command"
 	"github.com/docker-slim/docker-slim/pkg/report"
@@ -39,55 +43,135 @@ func OnPullCommand(
 	cmdReport.State = command.StateStarted
 	cmdReport.TargetReference = cparams.TargetRef
 
+	var platforms []string
+	for _, p := range cparams.Platforms {
+		platforms = append(platforms, registryclient.PlatformString(&p))
+	}
+
 	xc.Out.State("started")
 	xc.Out.Info("params",
 		ovars{
-			"cmd.params": fmt.Sprintf("%+v", cparams),
+			"target":             cparams.TargetRef,
+			"platforms":          strings.Join(platforms, ","),
+			"list.manifests":     cparams.ListManifests,
+			"save.to.docker":     cparams.SaveToDocker,
+			"save.to.oci_layout": cparams.SaveToOCILayout,
+			"save.to.archive":    cparams.SaveToArchive,
 		})
 
-	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
-		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
-			exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
+	if cparams.SaveToDocker {
+		client, err := dockerclient.New(gparams.ClientConfig)
+		if err == dockerclient.ErrNoDockerInfo {
+			exitMsg := "missing Docker connection info"
+			if gparams.InContainer && gparams.IsDSImage {
+				exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
+			}
+
+			xc.Out.Info("docker.connect.error",
+				ovars{
+					"message": exitMsg,
+				})
+
+			exitCode := commands.ECTCommon | commands.ECNoDockerConnectInfo
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+					"version":   v.Current(),
+					"location":  fsutil.ExeDir(),
+				})
+			xc.Exit(exitCode)
 		}
+		errutil.FailOn(err)
 
-		xc.Out.Info("docker.connect.error",
-			ovars{
-				"message": exitMsg,
-			})
+		if gparams.Debug {
+			version.Print(xc, prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		}
+	}
 
-		exitCode := commands.ECTCommon | commands.ECNoDockerConnectInfo
-		xc.Out.State("exited",
-			ovars{
-				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
-			})
-		xc.Exit(exitCode)
+	ref, err := name.ParseReference(cparams.TargetRef, nameOptions(cparams.Insecure)...)
+	xc.FailOn(err)
+
+	remoteOpts, err := registryclient.RemoteOptions(ref.Context(), &registryclient.AuthParams{
+		DockerConfigPath: cparams.DockerConfigPath,
+		Account:          cparams.RegistryAccount,
+		Secret:           cparams.RegistrySecret,
+	})
+	xc.FailOn(err)
+
+	target, err := resolvePullTarget(ref, cparams.Platforms, cparams.ListManifests, remoteOpts...)
+	xc.FailOn(err)
+
+	pullInfo := &report.RegistryPullInfo{
+		Reference: ref.Name(),
+		Digest:    target.digest.String(),
+		MediaType: string(target.mediaType),
+		Manifests: target.manifests,
 	}
-	errutil.FailOn(err)
+	cmdReport.Pull = pullInfo
 
-	if gparams.Debug {
-		version.Print(prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+	for _, info := range target.manifests {
+		outManifestInfo(xc, info)
 	}
 
-	//todo: pass a custom client to Pull (based on `client` above)
-	targetImage, err := crane.Pull(cparams.TargetRef)
-	errutil.FailOn(err)
-	outImageInfo(xc, targetImage)
+	targetIndex, targetImages := target.index, target.images
+	if len(targetImages) > 1 &&
+		(cparams.SaveToArchive != "" || cparams.SaveToDocker) {
+		//the OCI layout is the only output that can keep multiple platform images
+		xc.FailOn(registryclient.ErrMultiplePlatformImages)
+	}
 
-	if cparams.SaveToDocker {
-		xc.Out.State("save.docker.start")
+	for _, img := range targetImages {
+		info, err := imageManifestInfo(img)
+		xc.FailOn(err)
 
-		tag, err := name.NewTag(cparams.TargetRef)
-		errutil.FailOn(err)
+		pullInfo.Images = append(pullInfo.Images, info)
+		outImageInfo(xc, img)
+	}
 
-		rawResponse, err := daemon.Write(tag, targetImage)
-		errutil.FailOn(err)
-		logger.Tracef("Image save to Docker response: %v", rawResponse)
+	if len(targetImages) > 0 {
+		if cparams.SaveToOCILayout != "" {
+			xc.Out.State("save.oci_layout.start")
+
+			if targetIndex != nil {
+				err = registryclient.SaveIndexToOCILayout(cparams.SaveToOCILayout, targetIndex, ref.Name())
+			} else {
+				err = registryclient.SaveImageToOCILayout(cparams.SaveToOCILayout, targetImages[0], ref.Name())
+			}
+			xc.FailOn(err)
+
+			pullInfo.OCILayoutPath = cparams.SaveToOCILayout
+			xc.Out.State("save.oci_layout.done",
+				ovars{
+					"location": cparams.SaveToOCILayout,
+				})
+		}
+
+		if cparams.SaveToArchive != "" {
+			xc.Out.State("save.archive.start")
+
+			err = registryclient.SaveImageToArchive(cparams.SaveToArchive, targetImages[0], ref)
+			xc.FailOn(err)
 
-		xc.Out.State("save.docker.done")
+			pullInfo.ArchivePath = cparams.SaveToArchive
+			xc.Out.State("save.archive.done",
+				ovars{
+					"location": cparams.SaveToArchive,
+				})
+		}
+
+		if cparams.SaveToDocker {
+			xc.Out.State("save.docker.start")
+
+			tag, err := name.NewTag(cparams.TargetRef)
+			xc.FailOn(err)
+
+			rawResponse, err := daemon.Write(tag, targetImages[0])
+			xc.FailOn(err)
+			logger.Tracef("Image save to Docker response: %v", rawResponse)
+
+			pullInfo.SavedToDocker = true
+			xc.Out.State("save.docker.done")
+		}
 	}
 
 	xc.Out.State("completed")
@@ -109,7 +193,8 @@ func OnPullCommand(
 // OnPushCommand implements the 'registry push' docker-slim command
 func OnPushCommand(
 	xc *app.ExecutionContext,
-	gparams *commands.GenericParams) {
+	gparams *commands.GenericParams,
+	cparams *PushCommandParams) {
 	cmdName := fullCmdName(PushCmdName)
 	logger := log.WithFields(log.Fields{"app": appName, "command": cmdName})
 	prefix := fmt.Sprintf("cmd=%s", cmdName)
@@ -118,36 +203,119 @@ func OnPushCommand(
 
 	cmdReport := report.NewRegistryCommand(gparams.ReportLocation, gparams.InContainer)
 	cmdReport.State = command.StateStarted
+	cmdReport.TargetReference = cparams.TargetRef
 
 	xc.Out.State("started")
+	xc.Out.Info("params",
+		ovars{
+			"target":      cparams.TargetRef,
+			"source":      cparams.Source,
+			"source.path": cparams.SourcePath,
+			"as":          cparams.As,
+			"insecure":    cparams.Insecure,
+		})
 
-	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
-		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
-			exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
+	var client *dockerapi.Client
+	if cparams.Source == registryclient.SourceDocker {
+		var err error
+		client, err = dockerclient.New(gparams.ClientConfig)
+		if err == dockerclient.ErrNoDockerInfo {
+			exitMsg := "missing Docker connection info"
+			if gparams.InContainer && gparams.IsDSImage {
+				exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
+			}
+
+			xc.Out.Info("docker.connect.error",
+				ovars{
+					"message": exitMsg,
+				})
+
+			exitCode := commands.ECTCommon | commands.ECNoDockerConnectInfo
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+					"version":   v.Current(),
+					"location":  fsutil.ExeDir(),
+				})
+			xc.Exit(exitCode)
 		}
+		errutil.FailOn(err)
 
-		xc.Out.Info("docker.connect.error",
-			ovars{
-				"message": exitMsg,
-			})
+		if gparams.Debug {
+			version.Print(xc, prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		}
+	}
 
-		exitCode := commands.ECTCommon | commands.ECNoDockerConnectInfo
-		xc.Out.State("exited",
-			ovars{
-				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
-			})
-		xc.Exit(exitCode)
+	destRef := cparams.As
+	if destRef == "" {
+		destRef = cparams.TargetRef
 	}
-	errutil.FailOn(err)
 
-	if gparams.Debug {
-		version.Print(prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+	ref, err := name.ParseReference(destRef, nameOptions(cparams.Insecure)...)
+	xc.FailOn(err)
+
+	xc.Out.State("image.load.start")
+
+	var targetImage gocrv1.Image
+	switch cparams.Source {
+	case registryclient.SourceDocker:
+		targetImage, err = registryclient.ImageFromDocker(client, cparams.TargetRef)
+		xc.FailOn(err)
+	case registryclient.SourceOCILayout:
+		targetImage, err = registryclient.ImageFromOCILayout(cparams.SourcePath, cparams.TargetRef, nil)
+		xc.FailOn(err)
+	case registryclient.SourceArchive:
+		targetImage, err = registryclient.ImageFromArchive(cparams.SourcePath, cparams.TargetRef)
+		xc.FailOn(err)
 	}
 
+	xc.Out.State("image.load.done")
+	outImageInfo(xc, targetImage)
+
+	xc.Out.State("push.start",
+		ovars{
+			"destination": ref.Name(),
+		})
+
+	authParams := &registryclient.AuthParams{
+		DockerConfigPath: cparams.DockerConfigPath,
+		Account:          cparams.RegistryAccount,
+		Secret:           cparams.RegistrySecret,
+	}
+
+	uploadCounter := registryclient.NewUploadCounter()
+	remoteOpts, err := registryclient.RemoteOptions(ref.Context(),
+		authParams,
+		remote.WithTransport(uploadCounter))
+	xc.FailOn(err)
+
+	err = remote.Write(ref, targetImage, remoteOpts...)
+	xc.FailOn(err)
+
+	d, err := targetImage.Digest()
+	xc.FailOn(err)
+
+	layers, err := targetImage.Layers()
+	xc.FailOn(err)
+
+	cmdReport.Push = &report.RegistryPushInfo{
+		Source:        cparams.Source,
+		SourcePath:    cparams.SourcePath,
+		Destination:   ref.Name(),
+		Digest:        d.String(),
+		LayerCount:    len(layers),
+		BytesUploaded: uploadCounter.Bytes(),
+	}
+
+	xc.Out.State("push.done")
+	xc.Out.Info("push.results",
+		ovars{
+			"destination":    cmdReport.Push.Destination,
+			"digest":         cmdReport.Push.Digest,
+			"layers.count":   cmdReport.Push.LayerCount,
+			"bytes.uploaded": cmdReport.Push.BytesUploaded,
+		})
+
 	xc.Out.State("completed")
 	cmdReport.State = command.StateCompleted
 	xc.Out.State("done")
@@ -167,43 +335,113 @@ func OnPushCommand(
 // OnCopyCommand implements the 'registry copy' docker-slim command
 func OnCopyCommand(
 	xc *app.ExecutionContext,
-	gparams *commands.GenericParams) {
-	cmdName := fullCmdName(CopyCmdName)
-	logger := log.WithFields(log.Fields{"app": appName, "command": cmdName})
-	prefix := fmt.Sprintf("cmd=%s", cmdName)
-
+	gparams *commands.GenericParams,
+	cparams *CopyCommandParams) {
 	viChan := version.CheckAsync(gparams.CheckVersion, gparams.InContainer, gparams.IsDSImage)
 
 	cmdReport := report.NewRegistryCommand(gparams.ReportLocation, gparams.InContainer)
 	cmdReport.State = command.StateStarted
+	cmdReport.TargetReference = cparams.TargetRef
+
+	var platforms []string
+	for _, p := range cparams.Platforms {
+		platforms = append(platforms, registryclient.PlatformString(&p))
+	}
 
 	xc.Out.State("started")
+	xc.Out.Info("params",
+		ovars{
+			"target":       cparams.TargetRef,
+			"destinations": strings.Join(cparams.Destinations, ","),
+			"platforms":    strings.Join(platforms, ","),
+			"insecure":     cparams.Insecure,
+		})
 
-	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
-		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
-			exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
-		}
+	nameOpts := nameOptions(cparams.Insecure)
+
+	authParams := &registryclient.AuthParams{
+		DockerConfigPath: cparams.DockerConfigPath,
+		Account:          cparams.RegistryAccount,
+		Secret:           cparams.RegistrySecret,
+	}
+
+	srcRef, err := name.ParseReference(cparams.TargetRef, nameOpts...)
+	xc.FailOn(err)
 
-		xc.Out.Info("docker.connect.error",
+	var destRefs []name.Reference
+	for _, dest := range cparams.Destinations {
+		ref, err := name.ParseReference(dest, nameOpts...)
+		xc.FailOn(err)
+		destRefs = append(destRefs, ref)
+	}
+
+	srcOpts, err := registryclient.RemoteOptions(srcRef.Context(), authParams)
+	xc.FailOn(err)
+
+	xc.Out.State("source.resolve.start")
+
+	//resolving the source once, so all destinations get the same (digest pinned) content
+	src, err := resolveCopySource(srcRef, cparams.Platforms, srcOpts...)
+	xc.FailOn(err)
+
+	cmdReport.Copy = &report.RegistryCopyInfo{
+		Source:       src.pinnedRef.Name(),
+		SourceDigest: src.pinnedRef.DigestStr(),
+		MediaType:    string(src.mediaType),
+		Platforms:    src.platforms,
+	}
+
+	if src.index != nil {
+		xc.Out.Info("image.index.info",
 			ovars{
-				"message": exitMsg,
+				"digest":          src.pinnedRef.DigestStr(),
+				"media_type":      src.mediaType,
+				"manifests.count": len(src.platforms),
+				"platforms":       strings.Join(src.platforms, ","),
 			})
+	} else {
+		outImageInfo(xc, src.image)
+	}
+
+	xc.Out.State("source.resolve.done",
+		ovars{
+			"source": cmdReport.Copy.Source,
+		})
+
+	for _, ref := range destRefs {
+		xc.FailOn(checkDestinationDigest(ref, src.digest))
 
-		exitCode := commands.ECTCommon | commands.ECNoDockerConnectInfo
-		xc.Out.State("exited",
+		xc.Out.State("copy.start",
 			ovars{
-				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
+				"destination": ref.Name(),
 			})
-		xc.Exit(exitCode)
-	}
-	errutil.FailOn(err)
 
-	if gparams.Debug {
-		version.Print(prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		uploadCounter := registryclient.NewUploadCounter()
+		remoteOpts, err := registryclient.RemoteOptions(ref.Context(),
+			authParams,
+			remote.WithTransport(uploadCounter))
+		xc.FailOn(err)
+
+		err = src.write(ref, remoteOpts...)
+		xc.FailOn(err)
+
+		destInfo := &report.RegistryCopyDestination{
+			Reference:     ref.Name(),
+			Digest:        src.digest.String(),
+			PinnedRef:     ref.Context().Digest(src.digest.String()).Name(),
+			BytesUploaded: uploadCounter.Bytes(),
+		}
+
+		cmdReport.Copy.Destinations = append(cmdReport.Copy.Destinations, destInfo)
+
+		xc.Out.State("copy.done")
+		xc.Out.Info("copy.results",
+			ovars{
+				"destination":    destInfo.Reference,
+				"digest":         destInfo.Digest,
+				"pinned":         destInfo.PinnedRef,
+				"bytes.uploaded": destInfo.BytesUploaded,
+			})
 	}
 
 	xc.Out.State("completed")
@@ -222,6 +460,269 @@ func OnCopyCommand(
 	}
 }
 
+// pullTarget is the resolved 'registry pull' target
+// (the index is set only if the pulled index is filtered by the selected platforms)
+type pullTarget struct {
+	digest    gocrv1.Hash
+	mediaType types.MediaType
+	manifests []*report.RegistryManifestInfo
+	index     gocrv1.ImageIndex
+	images    []gocrv1.Image
+}
+
+// resolvePullTarget resolves the pulled reference and selects the platform images
+// (the manifests are listed for the image indexes and for the images if listManifests is true;
+// no images are selected if listManifests is true)
+func resolvePullTarget(
+	ref name.Reference,
+	platforms []gocrv1.Platform,
+	listManifests bool,
+	opts ...remote.Option) (*pullTarget, error) {
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &pullTarget{
+		digest:    desc.Digest,
+		mediaType: desc.MediaType,
+	}
+
+	if !desc.MediaType.IsIndex() {
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := imageManifestInfo(img)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(platforms) > 0 {
+			platform, err := registryclient.ParsePlatform(info.Platform)
+			if err != nil {
+				return nil, err
+			}
+
+			if !registryclient.PlatformMatcher(platforms)(gocrv1.Descriptor{Platform: platform}) {
+				return nil, registryclient.ErrNoMatchingPlatforms
+			}
+		}
+
+		if listManifests {
+			target.manifests = append(target.manifests, info)
+		} else {
+			target.images = append(target.images, img)
+		}
+
+		return target, nil
+	}
+
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range im.Manifests {
+		target.manifests = append(target.manifests, &report.RegistryManifestInfo{
+			Platform:  registryclient.PlatformString(m.Platform),
+			Digest:    m.Digest.String(),
+			MediaType: string(m.MediaType),
+			Size:      m.Size,
+		})
+	}
+
+	if listManifests {
+		return target, nil
+	}
+
+	if len(platforms) == 0 {
+		//using the default platform image
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+
+		target.images = append(target.images, img)
+		return target, nil
+	}
+
+	target.index, _, err = registryclient.FilterIndex(idx, platforms)
+	if err != nil {
+		return nil, err
+	}
+
+	fim, err := target.index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range fim.Manifests {
+		if !m.MediaType.IsImage() {
+			log.Debugf("resolvePullTarget: skipping non-image index manifest - %s (%s)", m.Digest, m.MediaType)
+			continue
+		}
+
+		img, err := target.index.Image(m.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		target.images = append(target.images, img)
+	}
+
+	return target, nil
+}
+
+// copySource is the resolved 'registry copy' source
+// (the index is filtered by the selected platforms, so its digest can be different from the pinned source digest)
+type copySource struct {
+	pinnedRef name.Digest
+	mediaType types.MediaType
+	index     gocrv1.ImageIndex
+	image     gocrv1.Image
+	digest    gocrv1.Hash
+	platforms []string
+}
+
+// resolveCopySource resolves the source reference to its digest and selects the platforms to copy
+func resolveCopySource(
+	srcRef name.Reference,
+	platforms []gocrv1.Platform,
+	opts ...remote.Option) (*copySource, error) {
+	desc, err := remote.Get(srcRef, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &copySource{
+		pinnedRef: srcRef.Context().Digest(desc.Digest.String()),
+		mediaType: desc.MediaType,
+	}
+
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return nil, err
+		}
+
+		src.index, src.platforms, err = registryclient.FilterIndex(idx, platforms)
+		if err != nil {
+			return nil, err
+		}
+
+		src.digest, err = src.index.Digest()
+		if err != nil {
+			return nil, err
+		}
+
+		return src, nil
+	}
+
+	src.image, err = desc.Image()
+	if err != nil {
+		return nil, err
+	}
+
+	cf, err := src.image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	imagePlatform := &gocrv1.Platform{
+		OS:           cf.OS,
+		Architecture: cf.Architecture,
+	}
+
+	if len(platforms) > 0 &&
+		!registryclient.PlatformMatcher(platforms)(gocrv1.Descriptor{Platform: imagePlatform}) {
+		return nil, registryclient.ErrNoMatchingPlatforms
+	}
+
+	src.platforms = []string{registryclient.PlatformString(imagePlatform)}
+	src.digest = desc.Digest
+	return src, nil
+}
+
+// write copies the source to the destination
+func (src *copySource) write(ref name.Reference, opts ...remote.Option) error {
+	if src.index != nil {
+		return remote.WriteIndex(ref, src.index, opts...)
+	}
+
+	return remote.Write(ref, src.image, opts...)
+}
+
+// checkDestinationDigest checks that the digest in the destination reference matches the copied content
+func checkDestinationDigest(ref name.Reference, digest gocrv1.Hash) error {
+	if d, ok := ref.(name.Digest); ok && d.DigestStr() != digest.String() {
+		return fmt.Errorf("destination digest mismatch - %s (expected %s)", ref.Name(), digest.String())
+	}
+
+	return nil
+}
+
+func nameOptions(insecure bool) []name.Option {
+	var opts []name.Option
+	if insecure {
+		opts = append(opts, name.Insecure)
+	}
+
+	return opts
+}
+
+func imageManifestInfo(targetImage gocrv1.Image) (*report.RegistryManifestInfo, error) {
+	cf, err := targetImage.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := targetImage.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	mt, err := targetImage.MediaType()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := targetImage.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &report.RegistryManifestInfo{
+		Platform: registryclient.PlatformString(&gocrv1.Platform{
+			OS:           cf.OS,
+			Architecture: cf.Architecture,
+		}),
+		Digest:    d.String(),
+		MediaType: string(mt),
+		Size:      size,
+	}
+
+	return info, nil
+}
+
+func outManifestInfo(
+	xc *app.ExecutionContext,
+	info *report.RegistryManifestInfo) {
+	xc.Out.Info("image.manifest",
+		ovars{
+			"platform":   info.Platform,
+			"digest":     info.Digest,
+			"media_type": info.MediaType,
+			"size":       info.Size,
+		})
+}
+
 func outImageInfo(
 	xc *app.ExecutionContext,
 	targetImage gocrv1.Image) {
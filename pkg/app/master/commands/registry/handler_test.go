@@ -0,0 +1,404 @@
+package registry
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/docker-slim/docker-slim/pkg/app/master/registryclient"
+)
+
+// testRegistry is an in-process registry
+type testRegistry struct {
+	server *httptest.Server
+	host   string
+}
+
+func newTestRegistry() *testRegistry {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+	return &testRegistry{
+		server: server,
+		host:   strings.TrimPrefix(server.URL, "http://"),
+	}
+}
+
+func (r *testRegistry) ref(t *testing.T, repo string) name.Reference {
+	ref, err := name.ParseReference(r.host+"/"+repo, nameOptions(true)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ref
+}
+
+func (r *testRegistry) close() {
+	r.server.Close()
+}
+
+func testImage(t *testing.T, platform string) gocrv1.Image {
+	img, err := random.Image(512, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := registryclient.ParsePlatform(platform)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf = cf.DeepCopy()
+	cf.OS = p.OS
+	cf.Architecture = p.Architecture
+	img, err = mutate.ConfigFile(img, cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return img
+}
+
+func testIndex(t *testing.T, platforms ...string) gocrv1.ImageIndex {
+	var adds []mutate.IndexAddendum
+	for _, platform := range platforms {
+		p, err := registryclient.ParsePlatform(platform)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		adds = append(adds, mutate.IndexAddendum{
+			Add: testImage(t, platform),
+			Descriptor: gocrv1.Descriptor{
+				MediaType: types.OCIManifestSchema1,
+				Platform:  p,
+			},
+		})
+	}
+
+	idx := mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	return mutate.AppendManifests(idx, adds...)
+}
+
+func digestOf(t *testing.T, ref name.Reference) gocrv1.Hash {
+	desc, err := remote.Get(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return desc.Digest
+}
+
+func TestResolveCopySourceIndex(t *testing.T) {
+	reg := newTestRegistry()
+	defer reg.close()
+
+	srcRef := reg.ref(t, "app:multi")
+	idx := testIndex(t, "linux/amd64", "linux/arm64/v8", "linux/arm/v7", "windows/amd64")
+	if err := remote.WriteIndex(srcRef, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	srcDigest := digestOf(t, srcRef)
+
+	tt := []struct {
+		desc      string
+		platforms []string
+		expected  []string
+		err       error
+	}{
+		{desc: "all", expected: []string{"linux/amd64", "linux/arm64/v8", "linux/arm/v7", "windows/amd64"}},
+		{desc: "one", platforms: []string{"linux/amd64"}, expected: []string{"linux/amd64"}},
+		//the variant is ignored if it's not in the selector
+		{desc: "no variant", platforms: []string{"linux/arm64", "linux/arm/v7"}, expected: []string{"linux/arm64/v8", "linux/arm/v7"}},
+		{desc: "other variant", platforms: []string{"linux/arm/v6"}, err: registryclient.ErrNoMatchingPlatforms},
+		{desc: "no match", platforms: []string{"linux/s390x"}, err: registryclient.ErrNoMatchingPlatforms},
+	}
+
+	for _, test := range tt {
+		platforms, err := registryclient.ParsePlatforms(test.platforms)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		src, err := resolveCopySource(srcRef, platforms)
+		if err != test.err {
+			t.Errorf("%s: unexpected error - %v", test.desc, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(src.platforms, test.expected) {
+			t.Errorf("%s: platforms: got %v expected %v", test.desc, src.platforms, test.expected)
+		}
+
+		if src.index == nil || src.mediaType != types.OCIImageIndex {
+			t.Errorf("%s: expected an index source (%v)", test.desc, src.mediaType)
+		}
+
+		//the source is pinned to the original index digest
+		if src.pinnedRef.DigestStr() != srcDigest.String() {
+			t.Errorf("%s: pinned ref: got %s expected %s", test.desc, src.pinnedRef, srcDigest)
+		}
+
+		//the filtered index has a different digest
+		if filtered := len(test.platforms) > 0; filtered == (src.digest == srcDigest) {
+			t.Errorf("%s: unexpected digest - %s (source %s)", test.desc, src.digest, srcDigest)
+		}
+
+		destRef := reg.ref(t, "copy/"+strings.Replace(test.desc, " ", "-", -1)+":latest")
+		if err := src.write(destRef); err != nil {
+			t.Fatalf("%s: write: %v", test.desc, err)
+		}
+
+		if got := digestOf(t, destRef); got != src.digest {
+			t.Errorf("%s: destination digest: got %s expected %s", test.desc, got, src.digest)
+		}
+
+		copied, err := remote.Index(destRef)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		im, err := copied.IndexManifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(im.Manifests) != len(test.expected) {
+			t.Errorf("%s: expected %d manifests (got %d)", test.desc, len(test.expected), len(im.Manifests))
+		}
+	}
+}
+
+func TestResolveCopySourceImage(t *testing.T) {
+	reg := newTestRegistry()
+	defer reg.close()
+
+	srcRef := reg.ref(t, "app:single")
+	if err := remote.Write(srcRef, testImage(t, "linux/arm64")); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := resolveCopySource(srcRef, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if src.image == nil || src.index != nil || !reflect.DeepEqual(src.platforms, []string{"linux/arm64"}) {
+		t.Errorf("unexpected image source: %+v", src)
+	}
+
+	if src.digest != digestOf(t, srcRef) {
+		t.Errorf("unexpected source digest - %s", src.digest)
+	}
+
+	platforms, _ := registryclient.ParsePlatforms([]string{"linux/amd64"})
+	if _, err := resolveCopySource(srcRef, platforms); err != registryclient.ErrNoMatchingPlatforms {
+		t.Errorf("expected the platform mismatch error (got %v)", err)
+	}
+}
+
+func TestCopyDigestPinning(t *testing.T) {
+	reg := newTestRegistry()
+	defer reg.close()
+
+	srcRef := reg.ref(t, "app:latest")
+	if err := remote.WriteIndex(srcRef, testIndex(t, "linux/amd64", "linux/arm64")); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := resolveCopySource(srcRef, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the tag is moved after the source is resolved
+	if err := remote.WriteIndex(srcRef, testIndex(t, "linux/amd64")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dest := range []string{"mirror/one:latest", "mirror/two:latest"} {
+		destRef := reg.ref(t, dest)
+		if err := src.write(destRef); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := digestOf(t, destRef); got != src.digest {
+			t.Errorf("%s: got %s expected the pinned digest %s", dest, got, src.digest)
+		}
+	}
+
+	if got := digestOf(t, srcRef); got == src.digest {
+		t.Errorf("expected the source tag to point to the new index")
+	}
+}
+
+func TestCheckDestinationDigest(t *testing.T) {
+	digest, err := gocrv1.NewHash("sha256:" + strings.Repeat("a", 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		ref      string
+		mismatch bool
+	}{
+		{ref: "registry.example.com/app:latest"},
+		{ref: "registry.example.com/app@sha256:" + strings.Repeat("a", 64)},
+		{ref: "registry.example.com/app@sha256:" + strings.Repeat("b", 64), mismatch: true},
+	}
+
+	for _, test := range tt {
+		ref, err := name.ParseReference(test.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = checkDestinationDigest(ref, digest)
+		if (err != nil) != test.mismatch {
+			t.Errorf("%s: unexpected result - %v", test.ref, err)
+		}
+
+		if err != nil && !strings.Contains(err.Error(), "destination digest mismatch") {
+			t.Errorf("%s: unexpected error - %v", test.ref, err)
+		}
+	}
+}
+
+func TestResolvePullTarget(t *testing.T) {
+	reg := newTestRegistry()
+	defer reg.close()
+
+	indexRef := reg.ref(t, "app:multi")
+	if err := remote.WriteIndex(indexRef, testIndex(t, "linux/amd64", "linux/arm64", "linux/arm/v7")); err != nil {
+		t.Fatal(err)
+	}
+
+	imageRef := reg.ref(t, "app:single")
+	if err := remote.Write(imageRef, testImage(t, "linux/arm64")); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		desc          string
+		ref           name.Reference
+		platforms     []string
+		listManifests bool
+		manifests     []string
+		images        []string
+		index         bool
+		err           error
+	}{
+		{
+			desc:      "index default platform",
+			ref:       indexRef,
+			manifests: []string{"linux/amd64", "linux/arm64", "linux/arm/v7"},
+			images:    []string{"linux/amd64"},
+		},
+		{
+			desc:      "index platforms",
+			ref:       indexRef,
+			platforms: []string{"linux/arm64", "linux/arm"},
+			manifests: []string{"linux/amd64", "linux/arm64", "linux/arm/v7"},
+			images:    []string{"linux/arm64", "linux/arm"},
+			index:     true,
+		},
+		{
+			desc:          "index list",
+			ref:           indexRef,
+			platforms:     []string{"linux/arm64"},
+			listManifests: true,
+			manifests:     []string{"linux/amd64", "linux/arm64", "linux/arm/v7"},
+		},
+		{
+			desc:      "index no match",
+			ref:       indexRef,
+			platforms: []string{"windows/amd64"},
+			err:       registryclient.ErrNoMatchingPlatforms,
+		},
+		{
+			desc:   "image",
+			ref:    imageRef,
+			images: []string{"linux/arm64"},
+		},
+		{
+			desc:          "image list",
+			ref:           imageRef,
+			listManifests: true,
+			manifests:     []string{"linux/arm64"},
+		},
+		{
+			desc:      "image no match",
+			ref:       imageRef,
+			platforms: []string{"linux/amd64"},
+			err:       registryclient.ErrNoMatchingPlatforms,
+		},
+	}
+
+	for _, test := range tt {
+		platforms, err := registryclient.ParsePlatforms(test.platforms)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		target, err := resolvePullTarget(test.ref, platforms, test.listManifests)
+		if err != test.err {
+			t.Errorf("%s: unexpected error - %v", test.desc, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if target.digest != digestOf(t, test.ref) {
+			t.Errorf("%s: unexpected digest - %s", test.desc, target.digest)
+		}
+
+		var manifests []string
+		for _, info := range target.manifests {
+			manifests = append(manifests, info.Platform)
+		}
+
+		if !reflect.DeepEqual(manifests, test.manifests) {
+			t.Errorf("%s: manifests: got %v expected %v", test.desc, manifests, test.manifests)
+		}
+
+		var images []string
+		for _, img := range target.images {
+			info, err := imageManifestInfo(img)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			images = append(images, info.Platform)
+		}
+
+		if !reflect.DeepEqual(images, test.images) {
+			t.Errorf("%s: images: got %v expected %v", test.desc, images, test.images)
+		}
+
+		if (target.index != nil) != test.index {
+			t.Errorf("%s: unexpected index - %v", test.desc, target.index)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	Name  = "report"
+	Usage = "Show the command report info"
+	Alias = "rp"
+
+	SchemaCmdName      = "schema"
+	SchemaCmdNameUsage = "Show the JSON schema for the command report (or the container report)"
+)
+
+func fullCmdName(subCmdName string) string {
+	return fmt.Sprintf("%s.%s", Name, subCmdName)
+}
+
+var CLI = &cli.Command{
+	Name:    Name,
+	Aliases: []string{Alias},
+	Usage:   Usage,
+	Subcommands: []*cli.Command{
+		{
+			Name:      SchemaCmdName,
+			Usage:     SchemaCmdNameUsage,
+			ArgsUsage: "[REPORT_NAME]",
+			Flags: []cli.Flag{
+				cflag(FlagOutput),
+			},
+			Action: func(ctx *cli.Context) error {
+				xc := app.NewExecutionContext(fullCmdName(SchemaCmdName))
+				OnSchemaCommand(xc, ctx.Args().First(), ctx.String(FlagOutput))
+				return nil
+			},
+		},
+	},
+}
@@ -0,0 +1,34 @@
+package report
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// Report command flag names
+const (
+	FlagOutput = "output"
+)
+
+// Report command flag usage info
+const (
+	FlagOutputUsage = "Save the report schema to a file (instead of printing it)"
+)
+
+var Flags = map[string]cli.Flag{
+	FlagOutput: &cli.StringFlag{
+		Name:    FlagOutput,
+		Value:   "",
+		Usage:   FlagOutputUsage,
+		EnvVars: []string{"DSLIM_REPORT_SCHEMA_OUTPUT"},
+	},
+}
+
+func cflag(name string) cli.Flag {
+	cf, ok := Flags[name]
+	if !ok {
+		log.Fatalf("unknown flag='%s'", name)
+	}
+
+	return cf
+}
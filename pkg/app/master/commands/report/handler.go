@@ -0,0 +1,63 @@
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+type ovars = app.OutVars
+
+// OnSchemaCommand implements the 'report schema' docker-slim command
+// (lists the available report schemas if the report name is not selected)
+func OnSchemaCommand(
+	xc *app.ExecutionContext,
+	reportName string,
+	outputPath string) {
+	if reportName == "" {
+		xc.Out.Info("report.schemas",
+			ovars{
+				"names": strings.Join(report.SchemaNames(), ","),
+			})
+		return
+	}
+
+	data, err := report.SchemaData(reportName)
+	if err != nil {
+		xc.Out.Error("param.error.report.name", err.Error())
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": -1,
+				"names":     strings.Join(report.SchemaNames(), ","),
+			})
+		xc.Exit(-1)
+	}
+
+	if outputPath == "" {
+		if app.IsJSONConsoleOutput() {
+			xc.Out.LogDump("report.schema", string(data), ovars{"name": reportName})
+			return
+		}
+
+		fmt.Print(string(data))
+		return
+	}
+
+	if err := ioutil.WriteFile(outputPath, data, 0644); err != nil {
+		xc.Out.Error("report.schema.save", err.Error())
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": -1,
+			})
+		xc.Exit(-1)
+	}
+
+	xc.Out.Info("report.schema",
+		ovars{
+			"name":   reportName,
+			"output": outputPath,
+		})
+}
@@ -0,0 +1,9 @@
+package init
+
+import (
+	"github.com/docker-slim/docker-slim/pkg/app/master/commands/report"
+)
+
+func init() {
+	report.RegisterCommand()
+}
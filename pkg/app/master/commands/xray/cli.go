@@ -7,8 +7,10 @@ import (
 
 	"github.com/docker-slim/docker-slim/pkg/app"
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
+	"github.com/docker-slim/docker-slim/pkg/app/master/registryclient"
 	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
 
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/urfave/cli/v2"
 )
 
@@ -54,6 +56,9 @@ var CLI = &cli.Command{
 		cflag(FlagChangeDataHash),
 		cflag(FlagExportAllDataArtifacts),
 		commands.Cflag(commands.FlagRemoveFileArtifacts),
+		cflag(FlagPlatform),
+		cflag(FlagDiffWith),
+		commands.Cflag(commands.FlagSBOM),
 	},
 	Action: func(ctx *cli.Context) error {
 		xc := app.NewExecutionContext(Name)
@@ -187,6 +192,27 @@ var CLI = &cli.Command{
 			doHashData = true
 		}
 
+		diffWithRef := ctx.String(FlagDiffWith)
+		if diffWithRef != "" {
+			//need the data hashes to detect the modified files
+			doHashData = true
+		}
+
+		sbomFormats, err := commands.ParseSBOMFormats(ctx.StringSlice(commands.FlagSBOM))
+		if err != nil {
+			xc.Out.Error("param.error.sbom", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
+		if len(sbomFormats) > 0 {
+			//need the data hashes for the file checksums
+			doHashData = true
+		}
+
 		rawDetectUTF8 := ctx.String(FlagDetectUTF8)
 		if xdArtifactsPath != "" && rawDetectUTF8 == "" {
 			rawDetectUTF8 = "dump:utf8.tgz::10000000"
@@ -226,6 +252,19 @@ var CLI = &cli.Command{
 
 		changeMatchLayersOnly := ctx.Bool(FlagChangeMatchLayersOnly)
 
+		var targetPlatform *gocrv1.Platform
+		if platform := ctx.String(FlagPlatform); platform != "" {
+			targetPlatform, err = registryclient.ParsePlatform(platform)
+			if err != nil {
+				xc.Out.Error("param.error.platform", err.Error())
+				xc.Out.State("exited",
+					ovars{
+						"exit.code": -1,
+					})
+				xc.Exit(-1)
+			}
+		}
+
 		OnCommand(
 			xc,
 			gcvalues,
@@ -234,6 +273,9 @@ var CLI = &cli.Command{
 			dockerConfigPath,
 			registryAccount,
 			registrySecret,
+			targetPlatform,
+			diffWithRef,
+			sbomFormats,
 			doShowPullLogs,
 			changes,
 			changesOutputs,
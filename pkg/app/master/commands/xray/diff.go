@@ -0,0 +1,218 @@
+package xray
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/app"
+	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
+	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
+	"github.com/docker-slim/docker-slim/pkg/app/master/registryclient"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/util/errutil"
+	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
+
+	"github.com/dustin/go-humanize"
+	dockerapi "github.com/fsouza/go-dockerclient"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+const diffDirsMax = 10
+
+// loadDiffImage loads the package data for the image to compare with the target image
+// (using the same image sources supported for the target image)
+func loadDiffImage(
+	xc *app.ExecutionContext,
+	gparams *commands.GenericParams,
+	logger *log.Entry,
+	prefix string,
+	client *dockerapi.Client,
+	diffWithRef string,
+	doPull bool,
+	dockerConfigPath string,
+	registryAccount string,
+	registrySecret string,
+	targetPlatform *gocrv1.Platform,
+	doShowPullLogs bool,
+	topChangesMax int,
+	doReuseSavedImage bool) (*dockerimage.Package, string, string) {
+	diffTarget, err := registryclient.ParseImageTarget(diffWithRef)
+	errutil.FailOn(err)
+
+	if diffTarget != nil {
+		diffImage, err := registryclient.ImageFromTarget(diffTarget,
+			targetPlatform,
+			&registryclient.AuthParams{
+				DockerConfigPath: dockerConfigPath,
+				Account:          registryAccount,
+				Secret:           registrySecret,
+			})
+		errutil.FailOn(err)
+
+		diffPkg, err := dockerimage.LoadPackageFromImage(
+			diffImage,
+			diffTarget.String(),
+			topChangesMax,
+			true,
+			false,
+			nil,
+			nil,
+			nil,
+			nil,
+			false,
+			false)
+		errutil.FailOn(err)
+
+		return diffPkg, diffWithRef, ""
+	}
+
+	if client == nil {
+		client = connectDocker(xc, gparams, logger, prefix)
+	}
+
+	diffInspector, err := image.NewInspector(client, diffWithRef)
+	errutil.FailOn(err)
+
+	if diffInspector.NoImage() {
+		if doPull {
+			xc.Out.Info("diff.image",
+				ovars{
+					"status":  "not.found",
+					"image":   diffWithRef,
+					"message": "trying to pull diff image",
+				})
+
+			err := diffInspector.Pull(doShowPullLogs, dockerConfigPath, registryAccount, registrySecret)
+			errutil.FailOn(err)
+		} else {
+			xc.Out.Error("diff.image.not.found", "make sure the diff image already exists locally (use --pull flag to auto-download it from registry)")
+
+			exitCode := commands.ECTBuild | ecxImageNotFound
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+				})
+			xc.Exit(exitCode)
+		}
+	}
+
+	err = diffInspector.Inspect()
+	errutil.FailOn(err)
+
+	localVolumePath, _, _, _ := fsutil.PrepareImageStateDirs(gparams.StatePath, diffInspector.ImageInfo.ID)
+	diffPkg, iaPath := inspectImageData(
+		xc,
+		logger,
+		client,
+		diffInspector,
+		localVolumePath,
+		topChangesMax,
+		true,
+		false,
+		nil,
+		nil,
+		nil,
+		nil,
+		false,
+		false,
+		doReuseSavedImage)
+
+	return diffPkg, diffInspector.ImageRef, iaPath
+}
+
+func printImageDiff(xc *app.ExecutionContext, diff *dockerimage.ImageDiffReport) {
+	xc.Out.Info("image.diff",
+		ovars{
+			"from":                 diff.From,
+			"to":                   diff.To,
+			"size.delta":           diff.Stats.SizeDelta,
+			"size.delta.human":     signedBytes(diff.Stats.SizeDelta),
+			"added.count":          diff.Stats.AddedCount,
+			"added.size.human":     humanize.Bytes(diff.Stats.AddedSize),
+			"removed.count":        diff.Stats.RemovedCount,
+			"removed.size.human":   humanize.Bytes(diff.Stats.RemovedSize),
+			"modified.count":       diff.Stats.ModifiedCount,
+			"modified.delta.human": signedBytes(diff.Stats.ModifiedSizeDelta),
+		})
+
+	for _, field := range diff.Config {
+		params := ovars{"field": field.Field}
+		if len(field.Added) > 0 || len(field.Removed) > 0 {
+			params["added"] = strings.Join(field.Added, ",")
+			params["removed"] = strings.Join(field.Removed, ",")
+		} else {
+			params["from"] = fmt.Sprintf("%v", field.From)
+			params["to"] = fmt.Sprintf("%v", field.To)
+		}
+
+		xc.Out.Info("image.diff.config", params)
+	}
+
+	if diff.Packages != nil {
+		xc.Out.Info("image.diff.packages",
+			ovars{
+				"from.count":    diff.Packages.FromCount,
+				"to.count":      diff.Packages.ToCount,
+				"added.count":   len(diff.Packages.Added),
+				"removed.count": len(diff.Packages.Removed),
+				"changed.count": len(diff.Packages.Changed),
+			})
+
+		for _, p := range diff.Packages.Added {
+			xc.Out.Info("image.diff.package",
+				ovars{
+					"change":  "added",
+					"name":    p.Name,
+					"version": p.Version,
+				})
+		}
+
+		for _, p := range diff.Packages.Removed {
+			xc.Out.Info("image.diff.package",
+				ovars{
+					"change":  "removed",
+					"name":    p.Name,
+					"version": p.Version,
+				})
+		}
+
+		for _, p := range diff.Packages.Changed {
+			xc.Out.Info("image.diff.package",
+				ovars{
+					"change":       "changed",
+					"name":         p.Name,
+					"from.version": p.FromVersion,
+					"to.version":   p.ToVersion,
+				})
+		}
+	}
+
+	for idx, dir := range diff.Directories {
+		if idx == diffDirsMax {
+			xc.Out.Info("image.diff.dirs",
+				ovars{
+					"count":   len(diff.Directories),
+					"message": "see report file for details",
+				})
+			break
+		}
+
+		xc.Out.Info("image.diff.dir",
+			ovars{
+				"path":             dir.Path,
+				"size.delta":       dir.SizeDelta,
+				"size.delta.human": signedBytes(dir.SizeDelta),
+				"from.count":       dir.FromCount,
+				"to.count":         dir.ToCount,
+			})
+	}
+}
+
+func signedBytes(val int64) string {
+	if val < 0 {
+		return "-" + humanize.Bytes(uint64(-val))
+	}
+
+	return "+" + humanize.Bytes(uint64(val))
+}
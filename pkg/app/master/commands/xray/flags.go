@@ -31,6 +31,8 @@ const (
 	FlagExportAllDataArtifacts = "export-all-data-artifacts"
 	FlagDetectAllCertFiles     = "detect-all-certs"
 	FlagDetectAllCertPKFiles   = "detect-all-cert-pks"
+	FlagPlatform               = "platform"
+	FlagDiffWith               = "diff-with"
 )
 
 // Xray command flag usage info
@@ -59,9 +61,23 @@ const (
 	FlagExportAllDataArtifactsUsage = "Archive path to export file of all data artifacts, enabling the related flags if not set (if set to `.` then path defaults to `./data-artifacts.tar`, otherwise path must include file name)"
 	FlagDetectAllCertFilesUsage     = "Detect all certifcate files"
 	FlagDetectAllCertPKFilesUsage   = "Detect all certifcate private key files"
+	FlagPlatformUsage               = "Platform to select from multi-platform images ('os/arch[/variant]', used with the 'registry://' and 'oci-layout://' targets)"
+	FlagDiffWithUsage               = "Compare the target image with another image (showing the added, removed and modified files, the directory size deltas and the config and OS package changes), enables --hash-data"
 )
 
 var Flags = map[string]cli.Flag{
+	FlagPlatform: &cli.StringFlag{
+		Name:    FlagPlatform,
+		Value:   "",
+		Usage:   FlagPlatformUsage,
+		EnvVars: []string{"DSLIM_XRAY_PLATFORM"},
+	},
+	FlagDiffWith: &cli.StringFlag{
+		Name:    FlagDiffWith,
+		Value:   "",
+		Usage:   FlagDiffWithUsage,
+		EnvVars: []string{"DSLIM_XRAY_DIFF_WITH"},
+	},
 	FlagChanges: &cli.StringSliceFlag{
 		Name:    FlagChanges,
 		Value:   cli.NewStringSlice(""),
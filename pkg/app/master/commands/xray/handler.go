@@ -11,6 +11,7 @@ import (
 	"github.com/docker-slim/docker-slim/pkg/app/master/commands"
 	"github.com/docker-slim/docker-slim/pkg/app/master/docker/dockerclient"
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
+	"github.com/docker-slim/docker-slim/pkg/app/master/registryclient"
 	"github.com/docker-slim/docker-slim/pkg/app/master/version"
 	"github.com/docker-slim/docker-slim/pkg/command"
 	"github.com/docker-slim/docker-slim/pkg/docker/buildpackinfo"
@@ -23,6 +24,8 @@ import (
 
 	//"github.com/bmatcuk/doublestar/v3"
 	"github.com/dustin/go-humanize"
+	dockerapi "github.com/fsouza/go-dockerclient"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -49,6 +52,9 @@ func OnCommand(
 	dockerConfigPath string,
 	registryAccount string,
 	registrySecret string,
+	targetPlatform *gocrv1.Platform,
+	diffWithRef string,
+	sbomFormats []string,
 	doShowPullLogs bool,
 	changes map[string]struct{},
 	changesOutputs map[string]struct{},
@@ -104,70 +110,103 @@ func OnCommand(
 	xc.Out.Info("params",
 		ovars{
 			"target":             targetRef,
+			"diff-with":          diffWithRef,
 			"add-image-manifest": doAddImageManifest,
 			"add-image-config":   doAddImageConfig,
 			"rm-file-artifacts":  doRmFileArtifacts,
 		})
 
-	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
-		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
-			exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
-		}
+	var (
+		imageInspector *image.Inspector
+		imagePkg       *dockerimage.Package
+		client         *dockerapi.Client
+	)
 
-		xc.Out.Error("docker.connect.error", exitMsg)
+	imageTarget, err := registryclient.ParseImageTarget(targetRef)
+	errutil.FailOn(err)
 
-		exitCode := commands.ECTCommon | commands.ECNoDockerConnectInfo
-		xc.Out.State("exited",
+	if imageTarget != nil {
+		//inspecting the image without Docker (streaming the image layers from the target source)
+		xc.Out.State("image.source.inspection.start",
 			ovars{
-				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
+				"source": imageTarget.Source,
+				"path":   imageTarget.Path,
+				"ref":    imageTarget.Ref,
 			})
-		xc.Exit(exitCode)
-	}
-	errutil.FailOn(err)
 
-	if gparams.Debug {
-		version.Print(prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
-	}
+		targetImage, err := registryclient.ImageFromTarget(imageTarget,
+			targetPlatform,
+			&registryclient.AuthParams{
+				DockerConfigPath: dockerConfigPath,
+				Account:          registryAccount,
+				Secret:           registrySecret,
+			})
+		errutil.FailOn(err)
 
-	imageInspector, err := image.NewInspector(client, targetRef)
-	errutil.FailOn(err)
+		xc.Out.Info("image.data.inspection.process.image.start")
+		imagePkg, err = dockerimage.LoadPackageFromImage(
+			targetImage,
+			imageTarget.String(),
+			topChangesMax,
+			doHashData,
+			doDetectDuplicates,
+			changeDataHashMatchers,
+			changePathMatchers,
+			changeDataMatchers,
+			utf8Detector,
+			doDetectAllCertFiles,
+			doDetectAllCertPKFiles)
+		errutil.FailOn(err)
+		xc.Out.Info("image.data.inspection.process.image.end")
 
-	if imageInspector.NoImage() {
-		if doPull {
-			xc.Out.Info("target.image",
-				ovars{
-					"status":  "not.found",
-					"image":   targetRef,
-					"message": "trying to pull target image",
-				})
+		if utf8Detector != nil {
+			errutil.FailOn(utf8Detector.Close())
+		}
 
-			err := imageInspector.Pull(doShowPullLogs, dockerConfigPath, registryAccount, registrySecret)
-			errutil.FailOn(err)
-		} else {
-			xc.Out.Error("image.not.found", "make sure the target image already exists locally (use --pull flag to auto-download it from registry)")
+		repoTags, repoDigests := imageTarget.Names(targetImage)
+		imageInspector, err = image.NewInspectorFromPackage(targetRef, repoTags, repoDigests, imagePkg)
+		errutil.FailOn(err)
 
-			exitCode := commands.ECTBuild | ecxImageNotFound
-			xc.Out.State("exited",
-				ovars{
-					"exit.code": exitCode,
-				})
-			xc.Exit(exitCode)
+		xc.Out.State("image.source.inspection.done")
+	} else {
+		client = connectDocker(xc, gparams, logger, prefix)
+
+		imageInspector, err = image.NewInspector(client, targetRef)
+		errutil.FailOn(err)
+
+		if imageInspector.NoImage() {
+			if doPull {
+				xc.Out.Info("target.image",
+					ovars{
+						"status":  "not.found",
+						"image":   targetRef,
+						"message": "trying to pull target image",
+					})
+
+				err := imageInspector.Pull(doShowPullLogs, dockerConfigPath, registryAccount, registrySecret)
+				errutil.FailOn(err)
+			} else {
+				xc.Out.Error("image.not.found", "make sure the target image already exists locally (use --pull flag to auto-download it from registry)")
+
+				exitCode := commands.ECTBuild | ecxImageNotFound
+				xc.Out.State("exited",
+					ovars{
+						"exit.code": exitCode,
+					})
+				xc.Exit(exitCode)
+			}
 		}
-	}
 
-	//refresh the target refs
-	targetRef = imageInspector.ImageRef
-	cmdReport.TargetReference = imageInspector.ImageRef
+		//refresh the target refs
+		targetRef = imageInspector.ImageRef
+		cmdReport.TargetReference = imageInspector.ImageRef
 
-	xc.Out.State("image.api.inspection.start")
+		xc.Out.State("image.api.inspection.start")
 
-	logger.Info("inspecting 'fat' image metadata...")
-	err = imageInspector.Inspect()
-	errutil.FailOn(err)
+		logger.Info("inspecting 'fat' image metadata...")
+		err = imageInspector.Inspect()
+		errutil.FailOn(err)
+	}
 
 	localVolumePath, artifactLocation, statePath, stateKey := fsutil.PrepareImageStateDirs(gparams.StatePath, imageInspector.ImageInfo.ID)
 	imageInspector.ArtifactLocation = artifactLocation
@@ -285,68 +324,27 @@ func OnCommand(
 
 	cmdReport.ArtifactLocation = imageInspector.ArtifactLocation
 
-	xc.Out.State("image.api.inspection.done")
-	xc.Out.State("image.data.inspection.start")
-
-	imageID := dockerutil.CleanImageID(imageInspector.ImageInfo.ID)
-	iaName := fmt.Sprintf("%s.tar", imageID)
-	iaPath := filepath.Join(localVolumePath, "image", iaName)
-	iaPathReady := fmt.Sprintf("%s.ready", iaPath)
-
-	var doSave bool
-	if fsutil.IsRegularFile(iaPath) {
-		if !doReuseSavedImage {
-			doSave = true
-		}
-
-		if !fsutil.Exists(iaPathReady) {
-			doSave = true
-		}
-	} else {
-		doSave = true
-	}
-
-	if doSave {
-		if fsutil.Exists(iaPathReady) {
-			fsutil.Remove(iaPathReady)
-		}
-
-		xc.Out.Info("image.data.inspection.save.image.start")
-		err = dockerutil.SaveImage(client, imageID, iaPath, false, false)
-		errutil.FailOn(err)
-
-		err = fsutil.Touch(iaPathReady)
-		errutil.WarnOn(err)
-
-		xc.Out.Info("image.data.inspection.save.image.end")
-	} else {
-		logger.Debugf("exported image already exists - %s", iaPath)
-	}
-
-	xc.Out.Info("image.data.inspection.process.image.start")
-	imagePkg, err := dockerimage.LoadPackage(
-		iaPath,
-		imageID,
-		false,
-		topChangesMax,
-		doHashData,
-		doDetectDuplicates,
-		changeDataHashMatchers,
-		changePathMatchers,
-		changeDataMatchers,
-		utf8Detector,
-		doDetectAllCertFiles,
-		doDetectAllCertPKFiles)
-
-	errutil.FailOn(err)
-	xc.Out.Info("image.data.inspection.process.image.end")
-
-	if utf8Detector != nil {
-		errutil.FailOn(utf8Detector.Close())
+	var iaPath string
+	if imagePkg == nil {
+		xc.Out.State("image.api.inspection.done")
+		imagePkg, iaPath = inspectImageData(
+			xc,
+			logger,
+			client,
+			imageInspector,
+			localVolumePath,
+			topChangesMax,
+			doHashData,
+			doDetectDuplicates,
+			changeDataHashMatchers,
+			changePathMatchers,
+			changeDataMatchers,
+			utf8Detector,
+			doDetectAllCertFiles,
+			doDetectAllCertPKFiles,
+			doReuseSavedImage)
 	}
 
-	xc.Out.State("image.data.inspection.done")
-
 	if len(imageInspector.DockerfileInfo.AllInstructions) == len(imagePkg.Config.History) {
 		for instIdx, instInfo := range imageInspector.DockerfileInfo.AllInstructions {
 			instInfo.Author = imagePkg.Config.History[instIdx].Author
@@ -463,15 +461,60 @@ func OnCommand(
 		cmdReport.RawImageConfig = imagePkg.Config
 	}
 
+	var diffIAPath string
+	if diffWithRef != "" {
+		xc.Out.State("image.diff.start",
+			ovars{
+				"diff.with": diffWithRef,
+			})
+
+		var diffPkg *dockerimage.Package
+		diffPkg, diffWithRef, diffIAPath = loadDiffImage(
+			xc,
+			gparams,
+			logger,
+			prefix,
+			client,
+			diffWithRef,
+			doPull,
+			dockerConfigPath,
+			registryAccount,
+			registrySecret,
+			targetPlatform,
+			doShowPullLogs,
+			topChangesMax,
+			doReuseSavedImage)
+
+		imageDiff := dockerimage.DiffPackages(imagePkg, diffPkg, dockerimage.DefaultDiffDirDepth)
+		imageDiff.From = targetRef
+		imageDiff.To = diffWithRef
+		cmdReport.ImageDiff = imageDiff
+
+		printImageDiff(xc, imageDiff)
+		xc.Out.State("image.diff.done")
+	}
+
+	if len(sbomFormats) > 0 {
+		doc := newImageSBOM(targetRef, imagePkg, cmdReport)
+		cmdReport.SBOMFiles = commands.SaveSBOMs(logger, doc, sbomFormats, cmdReport.ArtifactLocation)
+	}
+
 	xc.Out.State("completed")
 	cmdReport.State = command.StateCompleted
 
-	if doRmFileArtifacts {
-		logger.Info("removing temporary artifacts...")
-		err = fsutil.Remove(iaPath)
+	if iaPath != "" {
+		if doRmFileArtifacts {
+			logger.Info("removing temporary artifacts...")
+			err = fsutil.Remove(iaPath)
+			errutil.WarnOn(err)
+		} else {
+			cmdReport.ImageArchiveLocation = iaPath
+		}
+	}
+
+	if diffIAPath != "" && doRmFileArtifacts {
+		err = fsutil.Remove(diffIAPath)
 		errutil.WarnOn(err)
-	} else {
-		cmdReport.ImageArchiveLocation = iaPath
 	}
 
 	xc.Out.State("done")
@@ -486,6 +529,13 @@ func OnCommand(
 			"artifacts.dockerfile.original": "Dockerfile.fat",
 		})
 
+	for _, name := range cmdReport.SBOMFiles {
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.sbom": name,
+			})
+	}
+
 	vinfo := <-viChan
 	version.PrintCheckVersion(xc, "", vinfo)
 
@@ -501,6 +551,10 @@ func OnCommand(
 		var filesToExport []string
 		filesToExport = append(filesToExport, cmdReport.ReportLocation())
 		filesToExport = append(filesToExport, filepath.Join(cmdReport.ArtifactLocation, fatDockerfileName))
+		for _, name := range cmdReport.SBOMFiles {
+			filesToExport = append(filesToExport, filepath.Join(cmdReport.ArtifactLocation, name))
+		}
+
 		if utf8Detector.DumpArchive != "" {
 			filesToExport = append(filesToExport, utf8Detector.DumpArchive)
 		}
@@ -520,6 +574,38 @@ func OnCommand(
 	}
 }
 
+func connectDocker(
+	xc *app.ExecutionContext,
+	gparams *commands.GenericParams,
+	logger *log.Entry,
+	prefix string) *dockerapi.Client {
+	client, err := dockerclient.New(gparams.ClientConfig)
+	if err == dockerclient.ErrNoDockerInfo {
+		exitMsg := "missing Docker connection info"
+		if gparams.InContainer && gparams.IsDSImage {
+			exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
+		}
+
+		xc.Out.Error("docker.connect.error", exitMsg)
+
+		exitCode := commands.ECTCommon | commands.ECNoDockerConnectInfo
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": exitCode,
+				"version":   v.Current(),
+				"location":  fsutil.ExeDir(),
+			})
+		xc.Exit(exitCode)
+	}
+	errutil.FailOn(err)
+
+	if gparams.Debug {
+		version.Print(xc, prefix, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+	}
+
+	return client
+}
+
 func findChange(pkg *dockerimage.Package, filepath string) *dockerimage.ObjectMetadata {
 	for _, layer := range pkg.Layers {
 		if object, found := layer.References[filepath]; found {
@@ -1101,6 +1187,19 @@ func printImagePackage(
 		cmdReport.ImageReport.OSShells = append(cmdReport.ImageReport.OSShells, info)
 	}
 
+	if osPackages := pkg.OSPackageInventory(); osPackages != nil {
+		xc.Out.Info("image.os_packages",
+			ovars{
+				"managers":             strings.Join(osPackages.Managers, ","),
+				"count":                osPackages.Count,
+				"installed_size.bytes": osPackages.InstalledSize,
+				"installed_size.human": humanize.Bytes(osPackages.InstalledSize),
+				"message":              "see report file for details",
+			})
+
+		cmdReport.ImageReport.OSPackages = osPackages
+	}
+
 	xc.Out.Info("image.entry",
 		ovars{
 			"exe_path": cmdReport.SourceImage.ContainerEntry.ExePath,
@@ -1247,6 +1346,30 @@ func objectHistoryString(history *dockerimage.ObjectHistory) string {
 }
 
 func printObject(xc *app.ExecutionContext, object *dockerimage.ObjectMetadata) {
+	if app.IsJSONConsoleOutput() {
+		fields := ovars{
+			"change":  object.Change,
+			"mode":    object.Mode,
+			"size":    object.Size,
+			"uid":     object.UID,
+			"gid":     object.GID,
+			"mtime":   object.ModTime.UTC().Format(time.RFC3339),
+			"history": objectHistoryString(object.History),
+			"name":    object.Name,
+		}
+
+		if object.Hash != "" {
+			fields["hash"] = object.Hash
+		}
+
+		if object.LinkTarget != "" {
+			fields["link_target"] = object.LinkTarget
+		}
+
+		xc.Out.Info("object", fields)
+		return
+	}
+
 	var hashInfo string
 	if object.Hash != "" {
 		hashInfo = fmt.Sprintf(" hash=%s", object.Hash)
@@ -1270,3 +1393,83 @@ func printObject(xc *app.ExecutionContext, object *dockerimage.ObjectMetadata) {
 		fmt.Printf("\n")
 	}
 }
+
+// inspectImageData saves the target image with Docker and loads the saved image data
+func inspectImageData(
+	xc *app.ExecutionContext,
+	logger *log.Entry,
+	client *dockerapi.Client,
+	imageInspector *image.Inspector,
+	localVolumePath string,
+	topChangesMax int,
+	doHashData bool,
+	doDetectDuplicates bool,
+	changeDataHashMatchers map[string]*dockerimage.ChangeDataHashMatcher,
+	changePathMatchers []*dockerimage.ChangePathMatcher,
+	changeDataMatchers map[string]*dockerimage.ChangeDataMatcher,
+	utf8Detector *dockerimage.UTF8Detector,
+	doDetectAllCertFiles bool,
+	doDetectAllCertPKFiles bool,
+	doReuseSavedImage bool) (*dockerimage.Package, string) {
+	xc.Out.State("image.data.inspection.start")
+
+	imageID := dockerutil.CleanImageID(imageInspector.ImageInfo.ID)
+	iaName := fmt.Sprintf("%s.tar", imageID)
+	iaPath := filepath.Join(localVolumePath, "image", iaName)
+	iaPathReady := fmt.Sprintf("%s.ready", iaPath)
+
+	var doSave bool
+	if fsutil.IsRegularFile(iaPath) {
+		if !doReuseSavedImage {
+			doSave = true
+		}
+
+		if !fsutil.Exists(iaPathReady) {
+			doSave = true
+		}
+	} else {
+		doSave = true
+	}
+
+	if doSave {
+		if fsutil.Exists(iaPathReady) {
+			fsutil.Remove(iaPathReady)
+		}
+
+		xc.Out.Info("image.data.inspection.save.image.start")
+		err := dockerutil.SaveImage(client, imageID, iaPath, false, false)
+		errutil.FailOn(err)
+
+		err = fsutil.Touch(iaPathReady)
+		errutil.WarnOn(err)
+
+		xc.Out.Info("image.data.inspection.save.image.end")
+	} else {
+		logger.Debugf("exported image already exists - %s", iaPath)
+	}
+
+	xc.Out.Info("image.data.inspection.process.image.start")
+	imagePkg, err := dockerimage.LoadPackage(
+		iaPath,
+		imageID,
+		false,
+		topChangesMax,
+		doHashData,
+		doDetectDuplicates,
+		changeDataHashMatchers,
+		changePathMatchers,
+		changeDataMatchers,
+		utf8Detector,
+		doDetectAllCertFiles,
+		doDetectAllCertPKFiles)
+
+	errutil.FailOn(err)
+	xc.Out.Info("image.data.inspection.process.image.end")
+
+	if utf8Detector != nil {
+		errutil.FailOn(utf8Detector.Close())
+	}
+
+	xc.Out.State("image.data.inspection.done")
+	return imagePkg, iaPath
+}
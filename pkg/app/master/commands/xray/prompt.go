@@ -42,7 +42,10 @@ var CommandFlagSuggestions = &commands.FlagSuggestions{
 		{Text: commands.FullFlagName(FlagDetectAllCertFiles), Description: FlagDetectAllCertFilesUsage},
 		{Text: commands.FullFlagName(FlagDetectAllCertPKFiles), Description: FlagDetectAllCertPKFilesUsage},
 		{Text: commands.FullFlagName(FlagExportAllDataArtifacts), Description: FlagExportAllDataArtifactsUsage},
+		{Text: commands.FullFlagName(FlagPlatform), Description: FlagPlatformUsage},
+		{Text: commands.FullFlagName(FlagDiffWith), Description: FlagDiffWithUsage},
 		{Text: commands.FullFlagName(commands.FlagRemoveFileArtifacts), Description: commands.FlagRemoveFileArtifactsUsage},
+		{Text: commands.FullFlagName(commands.FlagSBOM), Description: commands.FlagSBOMUsage},
 	},
 	Values: map[string]commands.CompleteValue{
 		commands.FullFlagName(commands.FlagPull):                commands.CompleteBool,
@@ -0,0 +1,134 @@
+package xray
+
+import (
+	"archive/tar"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/certdiscover"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/sbom"
+	v "github.com/docker-slim/docker-slim/pkg/version"
+)
+
+// newImageSBOM creates the SBOM document for the final image filesystem
+func newImageSBOM(targetRef string, pkg *dockerimage.Package, cmdReport *report.XrayCommand) *sbom.Document {
+	image := sbom.Image{
+		Name:         targetRef,
+		ID:           cmdReport.SourceImage.Identity.ID,
+		OS:           cmdReport.SourceImage.OS,
+		Architecture: cmdReport.SourceImage.Architecture,
+	}
+
+	if len(cmdReport.SourceImage.Identity.FullDigests) > 0 {
+		//using the digest part of the first 'repo@digest' reference
+		digest := cmdReport.SourceImage.Identity.FullDigests[0]
+		if idx := strings.LastIndex(digest, "@"); idx > -1 {
+			digest = digest[idx+1:]
+		}
+
+		image.Digest = digest
+	}
+
+	if cmdReport.SourceImage.Distro != nil {
+		image.Distro = cmdReport.SourceImage.Distro.Name
+	}
+
+	doc := sbom.New(image, v.Tag())
+	appStacks := map[string]*sbom.AppStack{}
+	appStackDirs := map[string]map[string]struct{}{}
+	for name, object := range pkg.FinalObjects() {
+		if object.TypeFlag != tar.TypeReg && object.TypeFlag != tar.TypeRegA {
+			continue
+		}
+
+		if language, isCode, pkgDir := detectAppStackFile(name); language != "" {
+			appStack, found := appStacks[language]
+			if !found {
+				appStack = &sbom.AppStack{Language: language}
+				appStacks[language] = appStack
+				appStackDirs[language] = map[string]struct{}{}
+			}
+
+			if isCode {
+				appStack.CodeFiles++
+			}
+
+			if pkgDir != "" {
+				appStackDirs[language][pkgDir] = struct{}{}
+			}
+		}
+
+		doc.Files = append(doc.Files, &sbom.File{
+			Path:     name,
+			Size:     object.Size,
+			SHA1:     object.Hash,
+			DataType: object.ContentType,
+		})
+	}
+
+	if cmdReport.ImageReport != nil && cmdReport.ImageReport.OSPackages != nil {
+		for _, p := range cmdReport.ImageReport.OSPackages.Packages {
+			doc.Packages = append(doc.Packages, &sbom.Package{
+				Name:          p.Name,
+				Version:       p.Version,
+				Arch:          p.Arch,
+				Manager:       p.Manager,
+				InstalledSize: p.InstalledSize,
+			})
+		}
+	}
+
+	for language, appStack := range appStacks {
+		for dir := range appStackDirs[language] {
+			appStack.PackageDirs = append(appStack.PackageDirs, dir)
+		}
+
+		sort.Strings(appStack.PackageDirs)
+		doc.AppStacks = append(doc.AppStacks, appStack)
+	}
+
+	return doc
+}
+
+type appStackMatcher struct {
+	language   string
+	codeExt    string
+	pkgDirs    []string
+	lastPkgDir bool
+}
+
+// same file path based app stack detection as the sensor does for the minified images
+var appStackMatchers = []appStackMatcher{
+	{language: certdiscover.LanguagePython, codeExt: ".py", pkgDirs: []string{"/dist-packages/", "/site-packages/"}},
+	{language: certdiscover.LanguageRuby, codeExt: ".rb", pkgDirs: []string{"/gems/"}, lastPkgDir: true},
+	{language: certdiscover.LanguageNode, codeExt: ".js", pkgDirs: []string{"/node_modules/"}},
+}
+
+// detectAppStackFile returns the app stack language for the file,
+// if the file is a code file and its package directory (if it's in one)
+func detectAppStackFile(name string) (string, bool, string) {
+	for _, m := range appStackMatchers {
+		isCode := filepath.Ext(name) == m.codeExt
+		var pkgDir string
+		for _, dir := range m.pkgDirs {
+			idx := strings.Index(name, dir)
+			if m.lastPkgDir {
+				idx = strings.LastIndex(name, dir)
+			}
+
+			if idx > 0 {
+				pkgDir = name[:idx+len(dir)]
+				break
+			}
+		}
+
+		if isCode || pkgDir != "" {
+			return m.language, isCode, pkgDir
+		}
+	}
+
+	return "", false, ""
+}
@@ -952,7 +952,7 @@ func EnvVarsFromService(varMap types.MappingWithEquals, varFiles types.StringLis
 	for _, file := range varFiles {
 		data, err := ioutil.ReadFile(file)
 		if err != nil {
-			fmt.Printf("EnvVarsFromService: error reading '%s' - %v\n", err)
+			app.Printf("EnvVarsFromService: error reading '%s' - %v\n", file, err)
 			continue
 		}
 
@@ -1094,9 +1094,9 @@ func pullImage(ctx context.Context, apiClient *dockerapi.Client, imageRef string
 		return err
 	}
 
-	fmt.Println("pull output:")
-	fmt.Println(output.String())
-	fmt.Println("pull output [DONE]")
+	app.Println("pull output:")
+	app.Println(output.String())
+	app.Println("pull output [DONE]")
 
 	return nil
 }
@@ -1172,9 +1172,9 @@ func buildImage(ctx context.Context, apiClient *dockerapi.Client, basePath, imag
 		return err
 	}
 
-	fmt.Println("build output:")
-	fmt.Println(output.String())
-	fmt.Println("build output [DONE]")
+	app.Println("build output:")
+	app.Println(output.String())
+	app.Println("build output [DONE]")
 
 	return nil
 }
@@ -1732,19 +1732,19 @@ func deleteNetwork(apiClient *dockerapi.Client, id string) error {
 
 func dumpComposeJSON(data *types.Project) {
 	if pretty, err := json.MarshalIndent(data, "", "  "); err == nil {
-		fmt.Printf("%s\n", string(pretty))
+		app.Printf("%s\n", string(pretty))
 	}
 }
 
 func dumpRawJSON(data map[string]interface{}) {
 	if pretty, err := json.MarshalIndent(data, "", "  "); err == nil {
-		fmt.Printf("%s\n", string(pretty))
+		app.Printf("%s\n", string(pretty))
 	}
 }
 
 func dumpConfig(config *types.Config) {
-	fmt.Printf("\n\n")
-	fmt.Printf("types.Config:\n%#v\n", config)
+	app.Printf("\n\n")
+	app.Printf("types.Config:\n%#v\n", config)
 }
 
 func (ref *Execution) Stop() error {
@@ -145,3 +145,31 @@ type ContinueAfter struct {
 	Timeout      time.Duration
 	ContinueChan <-chan struct{}
 }
+
+const (
+	IBEDocker   = "docker"
+	IBEInternal = "internal"
+)
+
+// ImageBuildOptions provides the options to use when
+// building the optimized container images
+type ImageBuildOptions struct {
+	//Engine is the image build engine ("docker" or "internal")
+	Engine string
+	//OCILayoutPath is the directory where the image is saved as an OCI image layout
+	OCILayoutPath string
+	//ArchivePath is the file where the image is saved as a 'docker save' tarball
+	ArchivePath string
+	//LoadToDocker loads the assembled image into the Docker daemon
+	LoadToDocker bool
+}
+
+// IsValidImageBuildEngine returns true if the engine name is supported
+func IsValidImageBuildEngine(name string) bool {
+	switch name {
+	case IBEDocker, IBEInternal:
+		return true
+	default:
+		return false
+	}
+}
@@ -217,7 +217,7 @@ func (ref *Execution) Start() error {
 	}
 
 	if ref.options != nil && ref.options.Terminal {
-		fmt.Println("adding more container params for Terminal")
+		app.Println("adding more container params for Terminal")
 		containerOptions.Config.OpenStdin = true
 		//containerOptions.Config.StdinOnce = true
 		containerOptions.Config.AttachStdin = true
@@ -536,7 +536,7 @@ func (ref *Execution) startTerminal() {
 func (ref *Execution) startLiveLogs() {
 	options := dockerapi.AttachToContainerOptions{
 		Container:    ref.ContainerID,
-		OutputStream: os.Stdout,
+		OutputStream: app.TextOutput(),
 		ErrorStream:  os.Stderr,
 		Stdin:        false,
 		Stdout:       true,
@@ -588,11 +588,17 @@ func (ref *Execution) ShowContainerLogs() {
 	} else {
 		outw.Flush()
 		errw.Flush()
-		fmt.Printf("[%s] CONTAINER STDOUT:\n", ref.ContainerID)
-		outData.WriteTo(os.Stdout)
-		fmt.Printf("[%s] CONTAINER STDERR:\n", ref.ContainerID)
-		errData.WriteTo(os.Stdout)
-		fmt.Printf("[%s] END OF CONTAINER LOGS =============\n", ref.ContainerID)
+		if app.IsJSONConsoleOutput() && ref.xc != nil {
+			ref.xc.Out.LogDump("container.stdout", outData.String(), app.OutVars{"id": ref.ContainerID})
+			ref.xc.Out.LogDump("container.stderr", errData.String(), app.OutVars{"id": ref.ContainerID})
+			return
+		}
+
+		app.Printf("[%s] CONTAINER STDOUT:\n", ref.ContainerID)
+		outData.WriteTo(app.TextOutput())
+		app.Printf("[%s] CONTAINER STDERR:\n", ref.ContainerID)
+		errData.WriteTo(app.TextOutput())
+		app.Printf("[%s] END OF CONTAINER LOGS =============\n", ref.ContainerID)
 	}
 }
 
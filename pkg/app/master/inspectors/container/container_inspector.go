@@ -20,6 +20,7 @@ import (
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/container/ipc"
 	"github.com/docker-slim/docker-slim/pkg/app/master/inspectors/image"
 	"github.com/docker-slim/docker-slim/pkg/app/master/security/apparmor"
+	"github.com/docker-slim/docker-slim/pkg/app/master/security/capabilities"
 	"github.com/docker-slim/docker-slim/pkg/app/master/security/seccomp"
 	"github.com/docker-slim/docker-slim/pkg/docker/dockerutil"
 	"github.com/docker-slim/docker-slim/pkg/ipc/channel"
@@ -133,6 +134,8 @@ type Inspector struct {
 	SensorIPCEndpoint     string
 	SensorIPCMode         string
 	TargetHost            string
+	SeccompMissingCalls   map[string][]string //observed syscalls missing on the seccomp target architectures
+	Capabilities          *capabilities.Recommendations
 	dockerEventCh         chan *dockerapi.APIEvents
 	dockerEventStopCh     chan struct{}
 	isDone                aflag.Type
@@ -1042,6 +1045,12 @@ func (i *Inspector) ShowContainerLogs() {
 	} else {
 		outw.Flush()
 		errw.Flush()
+		if app.IsJSONConsoleOutput() {
+			i.xc.Out.LogDump("container.stdout", outData.String())
+			i.xc.Out.LogDump("container.stderr", errData.String())
+			return
+		}
+
 		fmt.Println("docker-slim: container stdout:")
 		outData.WriteTo(os.Stdout)
 		fmt.Println("docker-slim: container stderr:")
@@ -1235,6 +1244,21 @@ func (i *Inspector) shutdownContainerChannels() {
 	}
 }
 
+// AppUser returns the user the target application runs as
+// (the sensor runs the application as 'root' if the user is not set or if it can't run it as the user)
+func (i *Inspector) AppUser() string {
+	user := i.ImageInspector.ImageInfo.Config.User
+	if i.Overrides != nil && i.Overrides.User != "" {
+		user = i.Overrides.User
+	}
+
+	if !i.RunTargetAsUser {
+		return ""
+	}
+
+	return user
+}
+
 // HasCollectedData returns true if any data was produced monitoring the target container
 func (i *Inspector) HasCollectedData() bool {
 	return fsutil.Exists(filepath.Join(i.ImageInspector.ArtifactLocation, report.DefaultContainerReportFileName))
@@ -1248,7 +1272,18 @@ func (i *Inspector) ProcessCollectedData() error {
 		return err
 	}
 
-	return seccomp.GenProfile(i.ImageInspector.ArtifactLocation, i.ImageInspector.SeccompProfileName)
+	i.logger.Info("generating Seccomp profile...")
+	i.SeccompMissingCalls, err = seccomp.GenProfile(i.ImageInspector.ArtifactLocation,
+		i.ImageInspector.SeccompProfileName,
+		i.ImageInspector.SeccompArchs)
+	if err != nil {
+		return err
+	}
+
+	i.logger.Info("generating capability recommendations...")
+	i.Capabilities, err = capabilities.GenRecommendations(i.ImageInspector.ArtifactLocation,
+		i.ImageInspector.CapabilitiesFileName)
+	return err
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -641,7 +641,7 @@ func exeAppCall(appCall string) error {
 	//var outBuf, errBuf bytes.Buffer
 	//cmd.Stdout = io.MultiWriter(os.Stdout, &outBuf)
 	//cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = app.TextOutput()
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Start(); err != nil {
@@ -650,7 +650,7 @@ func exeAppCall(appCall string) error {
 	}
 
 	err = cmd.Wait()
-	fmt.Printf("\n")
+	app.Printf("\n")
 	if err != nil {
 		log.Fatalf("exeAppCall(%s): command exited with error: %v", appCall, err)
 		return err
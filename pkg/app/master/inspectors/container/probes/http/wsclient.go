@@ -8,6 +8,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/docker-slim/docker-slim/pkg/acounter"
+	"github.com/docker-slim/docker-slim/pkg/app"
 )
 
 const (
@@ -184,7 +185,7 @@ func (wc *WebsocketClient) Disconnect() error {
 		if err != nil {
 			log.Debugf("WebsocketClient.Disconnect: conn.WriteMessage(websocket.CloseMessage) error=%v", err)
 			return err
-			fmt.Print("write close err: %v\n", err)
+			app.Printf("write close err: %v\n", err)
 		}
 
 		//TODO: should wait for the 'websocket: close 1000 (normal)' read error
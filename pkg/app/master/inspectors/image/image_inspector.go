@@ -8,8 +8,12 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/docker-slim/docker-slim/pkg/app"
+	"github.com/docker-slim/docker-slim/pkg/app/master/security/kubernetes"
 	"github.com/docker-slim/docker-slim/pkg/docker/dockerfile/reverse"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
 	"github.com/docker-slim/docker-slim/pkg/docker/dockerutil"
+	"github.com/docker-slim/docker-slim/pkg/system"
 	"github.com/docker-slim/docker-slim/pkg/util/errutil"
 
 	docker "github.com/fsouza/go-dockerclient"
@@ -17,37 +21,44 @@ import (
 )
 
 const (
-	slimImageRepo          = "slim"
-	appArmorProfileName    = "apparmor-profile"
-	seccompProfileName     = "seccomp-profile"
-	fatDockerfileName      = "Dockerfile.fat"
-	appArmorProfileNamePat = "%s-apparmor-profile"
-	seccompProfileNamePat  = "%s-seccomp.json"
-	https                  = "https://"
-	http                   = "http://"
+	slimImageRepo           = "slim"
+	appArmorProfileName     = "apparmor-profile"
+	seccompProfileName      = "seccomp-profile"
+	capabilitiesFileName    = "capabilities.yaml"
+	fatDockerfileName       = "Dockerfile.fat"
+	appArmorProfileNamePat  = "%s-apparmor-profile"
+	seccompProfileNamePat   = "%s-seccomp.json"
+	capabilitiesFileNamePat = "%s-capabilities.yaml"
+	https                   = "https://"
+	http                    = "http://"
 )
 
 // Inspector is a container image inspector
 type Inspector struct {
-	ImageRef            string
-	ArtifactLocation    string
-	SlimImageRepo       string
-	AppArmorProfileName string
-	SeccompProfileName  string
-	ImageInfo           *docker.Image
-	ImageRecordInfo     docker.APIImages
-	APIClient           *docker.Client
+	ImageRef             string
+	ArtifactLocation     string
+	SlimImageRepo        string
+	AppArmorProfileName  string
+	SeccompProfileName   string
+	SeccompArchs         []system.ArchName //extra target architectures for the Seccomp profile
+	CapabilitiesFileName string
+	KubernetesName       string //the Kubernetes resource name for the generated resources
+	ImageInfo            *docker.Image
+	ImageRecordInfo      docker.APIImages
+	APIClient            *docker.Client
 	//fatImageDockerInstructions []string
 	DockerfileInfo *reverse.Dockerfile
+	imagePkg       *dockerimage.Package
 }
 
 // NewInspector creates a new container image inspector
 func NewInspector(client *docker.Client, imageRef string /*, artifactLocation string*/) (*Inspector, error) {
 	inspector := &Inspector{
-		ImageRef:            imageRef,
-		SlimImageRepo:       slimImageRepo,
-		AppArmorProfileName: appArmorProfileName,
-		SeccompProfileName:  seccompProfileName,
+		ImageRef:             imageRef,
+		SlimImageRepo:        slimImageRepo,
+		AppArmorProfileName:  appArmorProfileName,
+		SeccompProfileName:   seccompProfileName,
+		CapabilitiesFileName: capabilitiesFileName,
 		//ArtifactLocation:    artifactLocation,
 		APIClient: client,
 	}
@@ -122,9 +133,9 @@ func (i *Inspector) Pull(showPullLog bool, dockerConfigPath, registryAccount, re
 	}
 
 	if showPullLog {
-		fmt.Printf("pull logs ====================\n")
-		fmt.Println(pullLog.String())
-		fmt.Printf("end of pull logs =============\n")
+		app.Printf("pull logs ====================\n")
+		app.Println(pullLog.String())
+		app.Printf("end of pull logs =============\n")
 	}
 
 	return nil
@@ -271,12 +282,17 @@ func (i *Inspector) processImageName() {
 			if nameParts := strings.Split(rtInfo[0], "/"); len(nameParts) > 1 {
 				i.AppArmorProfileName = strings.Join(nameParts, "-")
 				i.SeccompProfileName = strings.Join(nameParts, "-")
+				i.CapabilitiesFileName = strings.Join(nameParts, "-")
+				i.KubernetesName = kubernetes.ResourceName(strings.Join(nameParts, "-"))
 			} else {
 				i.AppArmorProfileName = rtInfo[0]
 				i.SeccompProfileName = rtInfo[0]
+				i.CapabilitiesFileName = rtInfo[0]
+				i.KubernetesName = kubernetes.ResourceName(rtInfo[0])
 			}
 			i.AppArmorProfileName = fmt.Sprintf(appArmorProfileNamePat, i.AppArmorProfileName)
 			i.SeccompProfileName = fmt.Sprintf(seccompProfileNamePat, i.SeccompProfileName)
+			i.CapabilitiesFileName = fmt.Sprintf(capabilitiesFileNamePat, i.CapabilitiesFileName)
 		}
 	}
 }
@@ -285,6 +301,10 @@ func (i *Inspector) processImageName() {
 func (i *Inspector) ProcessCollectedData() error {
 	i.processImageName()
 
+	if i.imagePkg != nil {
+		return i.processPackageData()
+	}
+
 	var err error
 	i.DockerfileInfo, err = reverse.DockerfileFromHistory(i.APIClient, i.ImageRef)
 	if err != nil {
@@ -300,8 +320,8 @@ func (i *Inspector) ProcessCollectedData() error {
 // ShowFatImageDockerInstructions prints the original target image Dockerfile instructions
 func (i *Inspector) ShowFatImageDockerInstructions() {
 	if i.DockerfileInfo != nil && i.DockerfileInfo.Lines != nil {
-		fmt.Println("docker-slim: Fat image - Dockerfile instructures: start ====")
-		fmt.Println(strings.Join(i.DockerfileInfo.Lines, "\n"))
-		fmt.Println("docker-slim: Fat image - Dockerfile instructures: end ======")
+		app.Println("docker-slim: Fat image - Dockerfile instructures: start ====")
+		app.Println(strings.Join(i.DockerfileInfo.Lines, "\n"))
+		app.Println("docker-slim: Fat image - Dockerfile instructures: end ======")
 	}
 }
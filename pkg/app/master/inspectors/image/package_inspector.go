@@ -0,0 +1,128 @@
+package image
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerfile/reverse"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/util/errutil"
+)
+
+const missingHistoryID = "<missing>"
+
+// NewInspectorFromPackage creates a new container image inspector for the images
+// loaded without Docker (using the image package data instead of the Docker API)
+func NewInspectorFromPackage(imageRef string, repoTags, repoDigests []string, imagePkg *dockerimage.Package) (*Inspector, error) {
+	if imagePkg == nil || imagePkg.Config == nil || imagePkg.Manifest == nil {
+		return nil, fmt.Errorf("missing image package data - %s", imageRef)
+	}
+
+	imageID := fmt.Sprintf("sha256:%s", strings.TrimSuffix(imagePkg.Manifest.Config, ".json"))
+
+	var size int64
+	for _, layer := range imagePkg.Layers {
+		size += int64(layer.Stats.AllSize)
+	}
+
+	cfg := imagePkg.Config
+	imageInfo := &docker.Image{
+		ID:            imageID,
+		Created:       cfg.Created,
+		Author:        cfg.Author,
+		DockerVersion: cfg.DockerVersion,
+		Architecture:  cfg.Architecture,
+		OS:            cfg.OS,
+		Size:          size,
+		VirtualSize:   size,
+		Config:        &docker.Config{},
+		RepoTags:      repoTags,
+		RepoDigests:   repoDigests,
+	}
+
+	if cc := cfg.Config; cc != nil {
+		imageInfo.Config = &docker.Config{
+			User:         cc.User,
+			Env:          cc.Env,
+			Cmd:          cc.Cmd,
+			Entrypoint:   cc.Entrypoint,
+			WorkingDir:   cc.WorkingDir,
+			Volumes:      cc.Volumes,
+			OnBuild:      cc.OnBuild,
+			Labels:       cc.Labels,
+			StopSignal:   cc.StopSignal,
+			ExposedPorts: map[docker.Port]struct{}{},
+		}
+
+		for k, v := range cc.ExposedPorts {
+			imageInfo.Config.ExposedPorts[docker.Port(k)] = v
+		}
+	}
+
+	inspector := &Inspector{
+		ImageRef:             imageRef,
+		SlimImageRepo:        slimImageRepo,
+		AppArmorProfileName:  appArmorProfileName,
+		SeccompProfileName:   seccompProfileName,
+		CapabilitiesFileName: capabilitiesFileName,
+		ImageInfo:            imageInfo,
+		ImageRecordInfo: docker.APIImages{
+			ID:          imageID,
+			RepoTags:    repoTags,
+			RepoDigests: repoDigests,
+			Created:     cfg.Created.Unix(),
+			Size:        size,
+			VirtualSize: size,
+			Labels:      imageInfo.Config.Labels,
+		},
+		imagePkg: imagePkg,
+	}
+
+	return inspector, nil
+}
+
+// imageHistory creates the Docker 'history' API records from the image config history
+func (i *Inspector) imageHistory() []docker.ImageHistory {
+	var records []docker.ImageHistory
+	history := i.imagePkg.Config.History
+	for idx := len(history) - 1; idx >= 0; idx-- {
+		record := docker.ImageHistory{
+			ID:        missingHistoryID,
+			Created:   history[idx].Created.Unix(),
+			CreatedBy: history[idx].CreatedBy,
+			Comment:   history[idx].Comment,
+		}
+
+		if !history[idx].EmptyLayer &&
+			history[idx].LayerIndex > -1 &&
+			history[idx].LayerIndex < len(i.imagePkg.Layers) {
+			record.Size = int64(i.imagePkg.Layers[history[idx].LayerIndex].Stats.AllSize)
+		}
+
+		if idx == len(history)-1 {
+			record.ID = i.ImageInfo.ID
+			record.Tags = i.ImageRecordInfo.RepoTags
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func (i *Inspector) processPackageData() error {
+	var err error
+	i.DockerfileInfo, err = reverse.DockerfileFromHistoryData(i.imageHistory())
+	if err != nil {
+		return err
+	}
+
+	fatImageDockerfileLocation := filepath.Join(i.ArtifactLocation, fatDockerfileName)
+	err = reverse.SaveDockerfileData(fatImageDockerfileLocation, i.DockerfileInfo.Lines)
+	errutil.FailOn(err)
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package registryclient
+
+import (
+	"github.com/google/go-containerregistry/pkg/name"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// SaveImageToOCILayout adds the image to an OCI image layout directory
+// (the layout is created if it doesn't exist)
+func SaveImageToOCILayout(layoutPath string, img gocrv1.Image, refName string) error {
+	lp, err := openOCILayout(layoutPath)
+	if err != nil {
+		return err
+	}
+
+	return lp.AppendImage(img, refNameOption(refName)...)
+}
+
+// SaveIndexToOCILayout adds the image index to an OCI image layout directory
+// (the layout is created if it doesn't exist)
+func SaveIndexToOCILayout(layoutPath string, idx gocrv1.ImageIndex, refName string) error {
+	lp, err := openOCILayout(layoutPath)
+	if err != nil {
+		return err
+	}
+
+	return lp.AppendIndex(idx, refNameOption(refName)...)
+}
+
+// SaveImageToArchive saves the image to a 'docker load' compatible tarball
+// (the reference is used to tag the image in the tarball)
+func SaveImageToArchive(archivePath string, img gocrv1.Image, ref name.Reference) error {
+	return tarball.WriteToFile(archivePath, ref, img)
+}
+
+func openOCILayout(layoutPath string) (layout.Path, error) {
+	lp, err := layout.FromPath(layoutPath)
+	if err != nil {
+		return layout.Write(layoutPath, empty.Index)
+	}
+
+	return lp, nil
+}
+
+func refNameOption(refName string) []layout.Option {
+	if refName == "" {
+		return nil
+	}
+
+	return []layout.Option{
+		layout.WithAnnotations(map[string]string{
+			ociRefNameAnnotation: refName,
+		}),
+	}
+}
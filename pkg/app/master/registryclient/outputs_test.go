@@ -0,0 +1,162 @@
+package registryclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func platformImage(t *testing.T, os, arch string) gocrv1.Image {
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf = cf.DeepCopy()
+	cf.OS = os
+	cf.Architecture = arch
+	img, err = mutate.ConfigFile(img, cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return img
+}
+
+func sameImage(t *testing.T, got, expected gocrv1.Image) bool {
+	gotName, err := got.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedName, err := expected.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return gotName == expectedName
+}
+
+func TestSaveImageToOCILayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci-layout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	//the layout directory is created by the first save
+	layoutPath := filepath.Join(dir, "layout")
+	app := platformImage(t, "linux", "amd64")
+	if err := SaveImageToOCILayout(layoutPath, app, "registry.example.com/app:1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := ImageFromOCILayout(layoutPath, "", nil)
+	if err != nil || !sameImage(t, img, app) {
+		t.Fatalf("unexpected layout image (%v)", err)
+	}
+
+	//the next images are added to the same layout
+	db := platformImage(t, "linux", "arm64")
+	if err := SaveImageToOCILayout(layoutPath, db, "registry.example.com/db:1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ImageFromOCILayout(layoutPath, "", nil); err != ErrMultipleImagesInLayout {
+		t.Errorf("expected the multiple images error (got %v)", err)
+	}
+
+	for ref, expected := range map[string]gocrv1.Image{
+		"registry.example.com/app:1.0": app,
+		"registry.example.com/db:1.0":  db,
+	} {
+		img, err := ImageFromOCILayout(layoutPath, ref, nil)
+		if err != nil || !sameImage(t, img, expected) {
+			t.Errorf("%s: unexpected layout image (%v)", ref, err)
+		}
+	}
+
+	if _, err := ImageFromOCILayout(layoutPath, "registry.example.com/other:1.0", nil); err == nil {
+		t.Errorf("expected an error for the missing image")
+	}
+}
+
+func TestSaveIndexToOCILayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci-layout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	amd64 := platformImage(t, "linux", "amd64")
+	arm64 := platformImage(t, "linux", "arm64")
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+		mutate.IndexAddendum{
+			Add:        amd64,
+			Descriptor: gocrv1.Descriptor{Platform: &gocrv1.Platform{OS: "linux", Architecture: "amd64"}},
+		},
+		mutate.IndexAddendum{
+			Add:        arm64,
+			Descriptor: gocrv1.Descriptor{Platform: &gocrv1.Platform{OS: "linux", Architecture: "arm64"}},
+		})
+
+	ref := "registry.example.com/app:multi"
+	if err := SaveIndexToOCILayout(dir, idx, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := ImageFromOCILayout(dir, ref, &gocrv1.Platform{OS: "linux", Architecture: "arm64"})
+	if err != nil || !sameImage(t, img, arm64) {
+		t.Errorf("unexpected platform image (%v)", err)
+	}
+
+	if _, err := ImageFromOCILayout(dir, ref, nil); err != ErrMultiplePlatformImages {
+		t.Errorf("expected the multiple platform images error (got %v)", err)
+	}
+
+	if _, err := ImageFromOCILayout(dir, ref, &gocrv1.Platform{OS: "linux", Architecture: "s390x"}); err != ErrNoMatchingPlatforms {
+		t.Errorf("expected the no matching platforms error (got %v)", err)
+	}
+}
+
+func TestSaveImageToArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ref, err := name.ParseReference("registry.example.com/app:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := platformImage(t, "linux", "amd64")
+	archivePath := filepath.Join(dir, "app.tar")
+	if err := SaveImageToArchive(archivePath, app, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := ImageFromArchive(archivePath, "")
+	if err != nil || !sameImage(t, img, app) {
+		t.Fatalf("unexpected archive image (%v)", err)
+	}
+
+	cf, err := img.ConfigFile()
+	if err != nil || cf.OS != "linux" || cf.Architecture != "amd64" {
+		t.Errorf("unexpected archive image config (%v)", err)
+	}
+}
@@ -0,0 +1,111 @@
+package registryclient
+
+import (
+	"fmt"
+	"strings"
+
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// ParsePlatform parses a platform selector ('os/arch[/variant]')
+func ParsePlatform(spec string) (*gocrv1.Platform, error) {
+	parts := strings.Split(strings.TrimSpace(spec), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("malformed platform - '%s' (expected 'os/arch[/variant]')", spec)
+	}
+
+	platform := &gocrv1.Platform{
+		OS:           parts[0],
+		Architecture: parts[1],
+	}
+
+	if len(parts) == 3 {
+		platform.Variant = parts[2]
+	}
+
+	return platform, nil
+}
+
+// ParsePlatforms parses a list of platform selectors
+func ParsePlatforms(specs []string) ([]gocrv1.Platform, error) {
+	var platforms []gocrv1.Platform
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+
+		platform, err := ParsePlatform(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		platforms = append(platforms, *platform)
+	}
+
+	return platforms, nil
+}
+
+// PlatformString returns the 'os/arch[/variant]' platform name
+func PlatformString(platform *gocrv1.Platform) string {
+	if platform == nil {
+		return ""
+	}
+
+	name := fmt.Sprintf("%s/%s", platform.OS, platform.Architecture)
+	if platform.Variant != "" {
+		name = fmt.Sprintf("%s/%s", name, platform.Variant)
+	}
+
+	return name
+}
+
+// PlatformMatcher matches the index manifests for any of the selected platforms
+// (the variant is ignored if it's not in the selector)
+func PlatformMatcher(platforms []gocrv1.Platform) match.Matcher {
+	return func(desc gocrv1.Descriptor) bool {
+		if desc.Platform == nil {
+			return false
+		}
+
+		for _, p := range platforms {
+			if desc.Platform.OS == p.OS &&
+				desc.Platform.Architecture == p.Architecture &&
+				(p.Variant == "" || desc.Platform.Variant == p.Variant) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// FilterIndex removes the index manifests that don't match the selected platforms
+// (it returns the platforms for the manifests that remain in the index)
+func FilterIndex(idx gocrv1.ImageIndex, platforms []gocrv1.Platform) (gocrv1.ImageIndex, []string, error) {
+	if len(platforms) > 0 {
+		matcher := PlatformMatcher(platforms)
+		idx = mutate.RemoveManifests(idx, func(desc gocrv1.Descriptor) bool {
+			return !matcher(desc)
+		})
+	}
+
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(im.Manifests) == 0 {
+		return nil, nil, ErrNoMatchingPlatforms
+	}
+
+	var names []string
+	for _, desc := range im.Manifests {
+		if name := PlatformString(desc.Platform); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return idx, names, nil
+}
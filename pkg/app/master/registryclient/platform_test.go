@@ -0,0 +1,73 @@
+package registryclient
+
+import (
+	"reflect"
+	"testing"
+
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestParsePlatform(t *testing.T) {
+	tt := []struct {
+		spec     string
+		expected *gocrv1.Platform
+		name     string
+	}{
+		{spec: "linux/amd64", expected: &gocrv1.Platform{OS: "linux", Architecture: "amd64"}, name: "linux/amd64"},
+		{spec: " linux/arm/v7 ", expected: &gocrv1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, name: "linux/arm/v7"},
+		{spec: "linux"},
+		{spec: "linux/"},
+		{spec: "/amd64"},
+		{spec: "linux/arm/v7/extra"},
+	}
+
+	for _, test := range tt {
+		platform, err := ParsePlatform(test.spec)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.spec)
+			}
+
+			continue
+		}
+
+		if err != nil || !reflect.DeepEqual(platform, test.expected) {
+			t.Errorf("%q: got %+v (%v) expected %+v", test.spec, platform, err, test.expected)
+		}
+
+		if got := PlatformString(platform); got != test.name {
+			t.Errorf("%q: platform string: got %s expected %s", test.spec, got, test.name)
+		}
+	}
+}
+
+func TestPlatformMatcher(t *testing.T) {
+	platforms, err := ParsePlatforms([]string{"linux/amd64", "", "linux/arm/v7", "linux/arm64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matcher := PlatformMatcher(platforms)
+	tt := []struct {
+		platform *gocrv1.Platform
+		expected bool
+	}{
+		{platform: &gocrv1.Platform{OS: "linux", Architecture: "amd64"}, expected: true},
+		{platform: &gocrv1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, expected: true},
+		{platform: &gocrv1.Platform{OS: "linux", Architecture: "arm", Variant: "v6"}, expected: false},
+		{platform: &gocrv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}, expected: true},
+		{platform: &gocrv1.Platform{OS: "windows", Architecture: "amd64"}, expected: false},
+		//the attestation manifests and other descriptors without a platform
+		{platform: nil, expected: false},
+	}
+
+	for idx, test := range tt {
+		if got := matcher(gocrv1.Descriptor{Platform: test.platform}); got != test.expected {
+			t.Errorf("%d/%s: got %v expected %v", idx, PlatformString(test.platform), got, test.expected)
+		}
+	}
+
+	if _, err := ParsePlatforms([]string{"linux/amd64", "arm64"}); err == nil {
+		t.Errorf("expected an error for the malformed platform")
+	}
+}
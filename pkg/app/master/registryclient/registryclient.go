@@ -0,0 +1,146 @@
+package registryclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	log "github.com/sirupsen/logrus"
+)
+
+const dockerHubConfigKey = "https://index.docker.io/v1/"
+
+// AuthParams provides the registry credential selectors
+type AuthParams struct {
+	DockerConfigPath string
+	Account          string
+	Secret           string
+}
+
+// Authenticator returns the registry authenticator to use with the target repository
+// (explicit account/secret, then the selected Docker config file, then the default keychain)
+func Authenticator(repo name.Repository, params *AuthParams) (authn.Authenticator, error) {
+	if params != nil {
+		if params.Account != "" && params.Secret != "" {
+			return authn.FromConfig(authn.AuthConfig{
+				Username: params.Account,
+				Password: params.Secret,
+			}), nil
+		}
+
+		if params.DockerConfigPath != "" {
+			configs, err := dockerapi.NewAuthConfigurationsFromFile(params.DockerConfigPath)
+			if err != nil {
+				log.Debugf("registryclient.Authenticator: error loading docker config (%s) - %v", params.DockerConfigPath, err)
+				return nil, err
+			}
+
+			registry := repo.RegistryStr()
+			keys := []string{registry}
+			if registry == name.DefaultRegistry {
+				keys = append(keys, dockerHubConfigKey)
+			}
+
+			for _, key := range keys {
+				if cfg, found := configs.Configs[key]; found {
+					return authn.FromConfig(authn.AuthConfig{
+						Username:      cfg.Username,
+						Password:      cfg.Password,
+						IdentityToken: cfg.IdentityToken,
+						RegistryToken: cfg.RegistryToken,
+					}), nil
+				}
+			}
+
+			//the selected config might not have credentials for all registries used by the command
+			log.Debugf("registryclient.Authenticator: no auth config for registry (%s) - using anonymous access", registry)
+			return authn.Anonymous, nil
+		}
+	}
+
+	return authn.DefaultKeychain.Resolve(repo)
+}
+
+// RemoteOptions returns the common options for the remote registry operations
+func RemoteOptions(repo name.Repository, params *AuthParams, extra ...remote.Option) ([]remote.Option, error) {
+	auth, err := Authenticator(repo, params)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []remote.Option{remote.WithAuth(auth)}
+	return append(opts, extra...), nil
+}
+
+// UploadCounter is an HTTP transport that counts the uploaded blob bytes
+// (only the blob upload requests accepted by the registry are counted,
+// so the failed attempts that are retried and the manifest uploads are not included)
+type UploadCounter struct {
+	Transport http.RoundTripper
+	count     int64
+}
+
+// NewUploadCounter creates a new UploadCounter wrapping the default remote transport
+func NewUploadCounter() *UploadCounter {
+	return &UploadCounter{
+		Transport: remote.DefaultTransport,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *UploadCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || !isBlobUpload(req) {
+		return t.Transport.RoundTrip(req)
+	}
+
+	body := &countingReader{rc: req.Body}
+	req.Body = body
+
+	resp, err := t.Transport.RoundTrip(req)
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		atomic.AddInt64(&t.count, body.Count())
+	}
+
+	return resp, err
+}
+
+// Bytes returns the number of uploaded bytes
+func (t *UploadCounter) Bytes() int64 {
+	return atomic.LoadInt64(&t.count)
+}
+
+// isBlobUpload returns true for the blob upload session requests with the blob data
+// ('/v2/<name>/blobs/uploads/...')
+func isBlobUpload(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPut, http.MethodPatch, http.MethodPost:
+		return strings.Contains(req.URL.Path, "/blobs/uploads/")
+	default:
+		return false
+	}
+}
+
+type countingReader struct {
+	rc    io.ReadCloser
+	count int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	atomic.AddInt64(&r.count, int64(n))
+	return n, err
+}
+
+func (r *countingReader) Close() error {
+	return r.rc.Close()
+}
+
+// Count returns the number of bytes read from the request body
+func (r *countingReader) Count() int64 {
+	return atomic.LoadInt64(&r.count)
+}
@@ -0,0 +1,71 @@
+package registryclient
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUploadCounter(t *testing.T) {
+	failedOnce := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/blobs/uploads/retry"):
+			if !failedOnce {
+				failedOnce = true
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	counter := &UploadCounter{Transport: http.DefaultTransport}
+	client := &http.Client{Transport: counter}
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodPatch, path: "/v2/app/blobs/uploads/1", body: "0123456789"},
+		{method: http.MethodPut, path: "/v2/app/blobs/uploads/1", body: "01234"},
+		//the failed attempt is retried (only the accepted upload is counted)
+		{method: http.MethodPatch, path: "/v2/app/blobs/uploads/retry", body: "abc"},
+		{method: http.MethodPatch, path: "/v2/app/blobs/uploads/retry", body: "abc"},
+		//the manifest upload is not a blob upload
+		{method: http.MethodPut, path: "/v2/app/manifests/latest", body: "{\"schemaVersion\":2}"},
+		{method: http.MethodGet, path: "/v2/app/blobs/sha256:abc", body: ""},
+	}
+
+	for _, r := range requests {
+		var body io.Reader
+		if r.body != "" {
+			body = strings.NewReader(r.body)
+		}
+
+		req, err := http.NewRequest(r.method, server.URL+r.path, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+	}
+
+	if got, expected := counter.Bytes(), int64(10+5+3); got != expected {
+		t.Errorf("uploaded bytes: got %d expected %d", got, expected)
+	}
+}
@@ -0,0 +1,260 @@
+package registryclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docker/docker/api/types"
+	dockerapi "github.com/fsouza/go-dockerclient"
+	"github.com/google/go-containerregistry/pkg/name"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// Image source types
+const (
+	SourceDocker    = "docker"
+	SourceOCILayout = "oci-layout"
+	SourceArchive   = "docker-archive"
+	SourceRegistry  = "registry"
+)
+
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+var (
+	ErrNoImageInLayout         = errors.New("no matching image in OCI layout")
+	ErrMultipleImagesInLayout  = errors.New("multiple images in OCI layout (select one by reference)")
+	ErrMultipleImagesInArchive = errors.New("multiple images in image archive (select one by reference)")
+	ErrNoMatchingPlatforms     = errors.New("no image manifests for the selected platforms")
+	ErrMultiplePlatformImages  = errors.New("multiple platform images selected (select one platform)")
+	ErrUnsupportedDaemonOp     = errors.New("unsupported Docker daemon operation")
+)
+
+// IsValidSource returns true if the image source type is supported
+func IsValidSource(source string) bool {
+	switch source {
+	case SourceDocker, SourceOCILayout, SourceArchive, SourceRegistry:
+		return true
+	default:
+		return false
+	}
+}
+
+// ImageFromDocker loads an image from the Docker daemon (using the configured Docker client)
+func ImageFromDocker(client *dockerapi.Client, imageRef string) (gocrv1.Image, error) {
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return daemon.Image(ref, daemon.WithClient(&daemonClient{client: client, imageRef: imageRef}))
+}
+
+// daemonClient adapts the configured Docker client to the client interface used by the daemon images
+// (the image is saved using the original image reference, so the image IDs work too)
+type daemonClient struct {
+	client   *dockerapi.Client
+	imageRef string
+}
+
+func (c *daemonClient) NegotiateAPIVersion(ctx context.Context) {}
+
+func (c *daemonClient) ImageSave(ctx context.Context, names []string) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.client.ExportImage(dockerapi.ExportImageOptions{
+			Name:         c.imageRef,
+			OutputStream: pw,
+			Context:      ctx,
+		}))
+	}()
+
+	return pr, nil
+}
+
+func (c *daemonClient) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	return types.ImageLoadResponse{}, ErrUnsupportedDaemonOp
+}
+
+func (c *daemonClient) ImageTag(ctx context.Context, source, target string) error {
+	return ErrUnsupportedDaemonOp
+}
+
+// ImageInspectWithRaw inspects the image (the daemon images use the image ID as the config name)
+func (c *daemonClient) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	image, err := c.client.InspectImage(c.imageRef)
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+
+	raw, err := json.Marshal(image)
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+
+	info := types.ImageInspect{
+		ID:           image.ID,
+		RepoTags:     image.RepoTags,
+		RepoDigests:  image.RepoDigests,
+		Architecture: image.Architecture,
+		Os:           image.OS,
+		Size:         image.Size,
+	}
+
+	return info, raw, nil
+}
+
+// ImageFromArchive loads an image from a 'docker save' tarball
+// (the tag is used only if the tarball has more than one image)
+func ImageFromArchive(archivePath string, tagRef string) (gocrv1.Image, error) {
+	manifest, err := tarball.LoadManifest(func() (io.ReadCloser, error) {
+		return os.Open(archivePath)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var tag *name.Tag
+	if len(manifest) > 1 {
+		if tagRef == "" {
+			return nil, ErrMultipleImagesInArchive
+		}
+
+		t, err := name.NewTag(tagRef)
+		if err != nil {
+			return nil, err
+		}
+
+		tag = &t
+	}
+
+	return tarball.ImageFromPath(archivePath, tag)
+}
+
+// ImageFromOCILayout loads an image from an OCI image layout directory
+// (the reference is matched against the image ref name annotations and
+// it's optional if the layout has only one image; the platform is used
+// to select the image if the reference points to an image index)
+func ImageFromOCILayout(layoutPath string, ref string, platform *gocrv1.Platform) (gocrv1.Image, error) {
+	desc, err := layoutImageDescriptor(layoutPath, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	lp, err := layout.FromPath(layoutPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !desc.MediaType.IsIndex() {
+		return lp.Image(desc.Digest)
+	}
+
+	rootIdx, err := lp.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := rootIdx.ImageIndex(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return imageFromIndex(idx, platform)
+}
+
+// ImageFromRegistry loads an image from a registry
+// (the platform is used to select the image if the reference points to an image index)
+func ImageFromRegistry(ref name.Reference, platform *gocrv1.Platform, opts ...remote.Option) (gocrv1.Image, error) {
+	if platform != nil {
+		opts = append(opts, remote.WithPlatform(*platform))
+	}
+
+	return remote.Image(ref, opts...)
+}
+
+func imageFromIndex(idx gocrv1.ImageIndex, platform *gocrv1.Platform) (gocrv1.Image, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []gocrv1.Descriptor
+	for _, desc := range im.Manifests {
+		if !desc.MediaType.IsImage() {
+			continue
+		}
+
+		if platform != nil &&
+			!PlatformMatcher([]gocrv1.Platform{*platform})(desc) {
+			continue
+		}
+
+		candidates = append(candidates, desc)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, ErrNoMatchingPlatforms
+	case 1:
+		return idx.Image(candidates[0].Digest)
+	default:
+		return nil, ErrMultiplePlatformImages
+	}
+}
+
+func layoutImageDescriptor(layoutPath string, ref string) (*gocrv1.Descriptor, error) {
+	idx, err := layout.ImageIndexFromPath(layoutPath)
+	if err != nil {
+		return nil, err
+	}
+
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var fullRef string
+	if ref != "" {
+		if r, err := name.ParseReference(ref); err == nil {
+			fullRef = r.Name()
+		}
+	}
+
+	var candidates []gocrv1.Descriptor
+	for _, desc := range im.Manifests {
+		if !desc.MediaType.IsImage() && !desc.MediaType.IsIndex() {
+			continue
+		}
+
+		if ref != "" {
+			refName := desc.Annotations[ociRefNameAnnotation]
+			if refName == ref || (fullRef != "" && refName == fullRef) {
+				return &desc, nil
+			}
+		}
+
+		candidates = append(candidates, desc)
+	}
+
+	//the only image in the layout is used even if its ref name doesn't match
+	switch len(candidates) {
+	case 0:
+		return nil, ErrNoImageInLayout
+	case 1:
+		return &candidates[0], nil
+	default:
+		if ref != "" {
+			return nil, fmt.Errorf("%s - %s", ErrNoImageInLayout.Error(), ref)
+		}
+
+		return nil, ErrMultipleImagesInLayout
+	}
+}
@@ -0,0 +1,118 @@
+package registryclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// newTestDaemon starts a Docker API server that exports and inspects one image
+func newTestDaemon(t *testing.T, imageRef string) (*dockerapi.Client, func()) {
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configName, err := img.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := name.NewTag(imageRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/images/" + imageRef + "/get":
+			if err := tarball.Write(tag, img, w); err != nil {
+				t.Errorf("tarball.Write: %v", err)
+			}
+		case "/images/" + imageRef + "/json":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"Id":       configName.String(),
+				"RepoTags": []string{imageRef},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	client, err := dockerapi.NewClient(server.URL)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	return client, server.Close
+}
+
+func TestPushDockerImage(t *testing.T) {
+	client, closeDaemon := newTestDaemon(t, "app:latest")
+	defer closeDaemon()
+
+	reg := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+	defer reg.Close()
+
+	img, err := ImageFromDocker(client, "app:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the image info uses the config name from the Docker daemon
+	configName, err := img.ConfigName()
+	if err != nil {
+		t.Fatalf("ConfigName: %v", err)
+	}
+
+	cf, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, _, err := gocrv1.SHA256(bytes.NewReader(cf)); err != nil || configName != expected {
+		t.Errorf("config name: got %v expected %v", configName, expected)
+	}
+
+	dest, err := name.ParseReference(strings.TrimPrefix(reg.URL, "http://")+"/app:pushed", name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &UploadCounter{Transport: http.DefaultTransport}
+	if err := remote.Write(dest, img, remote.WithTransport(counter)); err != nil {
+		t.Fatalf("remote.Write: %v", err)
+	}
+
+	pushed, err := remote.Image(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDigest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := pushed.Digest(); err != nil || got != expectedDigest {
+		t.Errorf("pushed digest: got %v expected %v", got, expectedDigest)
+	}
+
+	if counter.Bytes() == 0 {
+		t.Errorf("expected the uploaded bytes to be counted")
+	}
+}
@@ -0,0 +1,149 @@
+package registryclient
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Image target prefixes (for the commands that can work without Docker)
+const (
+	TargetPrefixRegistry  = "registry://"
+	TargetPrefixOCILayout = "oci-layout://"
+	TargetPrefixArchive   = "docker-archive://"
+)
+
+// ImageTarget is a parsed image target reference
+type ImageTarget struct {
+	Source string
+	Path   string
+	Ref    string
+}
+
+// String returns the image target reference
+func (t *ImageTarget) String() string {
+	switch t.Source {
+	case SourceRegistry:
+		return TargetPrefixRegistry + t.Ref
+	case SourceOCILayout, SourceArchive:
+		prefix := TargetPrefixOCILayout
+		if t.Source == SourceArchive {
+			prefix = TargetPrefixArchive
+		}
+
+		if t.Ref == "" {
+			return prefix + t.Path
+		}
+
+		return fmt.Sprintf("%s%s:%s", prefix, t.Path, t.Ref)
+	default:
+		return t.Ref
+	}
+}
+
+// ParseImageTarget parses the prefixed image target references:
+// 'registry://REF', 'oci-layout://PATH[:REF]' and 'docker-archive://PATH[:REF]'
+// (it returns nil for the plain Docker image references)
+func ParseImageTarget(target string) (*ImageTarget, error) {
+	switch {
+	case strings.HasPrefix(target, TargetPrefixRegistry):
+		ref := strings.TrimPrefix(target, TargetPrefixRegistry)
+		if ref == "" {
+			return nil, fmt.Errorf("missing image reference - '%s'", target)
+		}
+
+		return &ImageTarget{
+			Source: SourceRegistry,
+			Ref:    ref,
+		}, nil
+	case strings.HasPrefix(target, TargetPrefixOCILayout):
+		path, ref := splitTargetPath(strings.TrimPrefix(target, TargetPrefixOCILayout))
+		if path == "" {
+			return nil, fmt.Errorf("missing OCI image layout path - '%s'", target)
+		}
+
+		return &ImageTarget{
+			Source: SourceOCILayout,
+			Path:   path,
+			Ref:    ref,
+		}, nil
+	case strings.HasPrefix(target, TargetPrefixArchive):
+		path, ref := splitTargetPath(strings.TrimPrefix(target, TargetPrefixArchive))
+		if path == "" {
+			return nil, fmt.Errorf("missing image archive path - '%s'", target)
+		}
+
+		return &ImageTarget{
+			Source: SourceArchive,
+			Path:   path,
+			Ref:    ref,
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// splitTargetPath splits 'PATH[:REF]'
+// (the full value is used as the path if it exists)
+func splitTargetPath(value string) (string, string) {
+	if _, err := os.Stat(value); err == nil {
+		return value, ""
+	}
+
+	if idx := strings.Index(value, ":"); idx > -1 {
+		return value[:idx], value[idx+1:]
+	}
+
+	return value, ""
+}
+
+// ImageFromTarget loads the image for the parsed image target
+// (the platform is used to select the image from multi-platform images)
+func ImageFromTarget(target *ImageTarget, platform *gocrv1.Platform, authParams *AuthParams) (gocrv1.Image, error) {
+	switch target.Source {
+	case SourceRegistry:
+		ref, err := name.ParseReference(target.Ref)
+		if err != nil {
+			return nil, err
+		}
+
+		opts, err := RemoteOptions(ref.Context(), authParams)
+		if err != nil {
+			return nil, err
+		}
+
+		return ImageFromRegistry(ref, platform, opts...)
+	case SourceOCILayout:
+		return ImageFromOCILayout(target.Path, target.Ref, platform)
+	case SourceArchive:
+		return ImageFromArchive(target.Path, target.Ref)
+	default:
+		return nil, fmt.Errorf("unsupported image target source - '%s'", target.Source)
+	}
+}
+
+// Names returns the image repo tags and digests for the image target
+func (t *ImageTarget) Names(img gocrv1.Image) ([]string, []string) {
+	if t.Ref == "" {
+		return nil, nil
+	}
+
+	ref, err := name.ParseReference(t.Ref)
+	if err != nil {
+		return []string{t.Ref}, nil
+	}
+
+	var tags, digests []string
+	if tag, ok := ref.(name.Tag); ok {
+		tags = append(tags, tag.String())
+	}
+
+	if d, err := img.Digest(); err == nil && t.Source == SourceRegistry {
+		digests = append(digests, ref.Context().Digest(d.String()).String())
+	}
+
+	return tags, digests
+}
@@ -0,0 +1,110 @@
+package registryclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseImageTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-target")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	//the existing paths with ':' are not split
+	colonPath := filepath.Join(dir, "app:1.0.tar")
+	if err := ioutil.WriteFile(colonPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		target   string
+		expected *ImageTarget
+		err      bool
+	}{
+		{target: "app:latest"},
+		{target: "registry.example.com:5000/app:latest"},
+		{
+			target:   "registry://registry.example.com:5000/app:1.0",
+			expected: &ImageTarget{Source: SourceRegistry, Ref: "registry.example.com:5000/app:1.0"},
+		},
+		{target: "registry://", err: true},
+		{
+			target:   "oci-layout:///images/layout",
+			expected: &ImageTarget{Source: SourceOCILayout, Path: "/images/layout"},
+		},
+		{
+			target:   "oci-layout:///images/layout:app:1.0",
+			expected: &ImageTarget{Source: SourceOCILayout, Path: "/images/layout", Ref: "app:1.0"},
+		},
+		{target: "oci-layout://", err: true},
+		{target: "oci-layout://:app", err: true},
+		{
+			target:   "docker-archive://images/app.tar",
+			expected: &ImageTarget{Source: SourceArchive, Path: "images/app.tar"},
+		},
+		{
+			target:   "docker-archive://images/app.tar:registry.example.com/app:1.0",
+			expected: &ImageTarget{Source: SourceArchive, Path: "images/app.tar", Ref: "registry.example.com/app:1.0"},
+		},
+		{
+			target:   "docker-archive://" + colonPath,
+			expected: &ImageTarget{Source: SourceArchive, Path: colonPath},
+		},
+		{target: "docker-archive://", err: true},
+	}
+
+	for _, test := range tt {
+		target, err := ParseImageTarget(test.target)
+		if (err != nil) != test.err {
+			t.Errorf("%q: unexpected error - %v", test.target, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(target, test.expected) {
+			t.Errorf("%q: got %+v expected %+v", test.target, target, test.expected)
+			continue
+		}
+
+		//the parsed targets are formatted back to the same reference
+		if target != nil && target.String() != test.target {
+			t.Errorf("%q: unexpected target string - %q", test.target, target.String())
+		}
+	}
+}
+
+func TestImageFromTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-target")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := platformImage(t, "linux", "amd64")
+	if err := SaveImageToOCILayout(filepath.Join(dir, "layout"), app, "app:1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := ParseImageTarget("oci-layout://" + filepath.Join(dir, "layout") + ":app:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := ImageFromTarget(target, nil, &AuthParams{})
+	if err != nil || !sameImage(t, img, app) {
+		t.Fatalf("unexpected target image (%v)", err)
+	}
+
+	tags, digests := target.Names(img)
+	if !reflect.DeepEqual(tags, []string{"app:1.0"}) || digests != nil {
+		t.Errorf("unexpected target names - %v / %v", tags, digests)
+	}
+
+	if _, err := ImageFromTarget(&ImageTarget{Source: SourceDocker, Ref: "app:1.0"}, nil, &AuthParams{}); err == nil {
+		t.Errorf("expected an error for the Docker image target")
+	}
+}
@@ -1,9 +1,13 @@
 package apparmor
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/docker-slim/docker-slim/pkg/report"
@@ -11,35 +15,39 @@ import (
 
 const appArmorTemplate = `
 profile {{.ProfileName}} flags=(attach_disconnected,mediate_deleted) {
-
-  network,
-
-{{range $value := .ExeFileRules}}  {{$value.FilePath}} {{$value.PermSet}},
+{{range $value := .CapabilityRules}}  {{$value}},
+{{end}}
+{{range $value := .NetworkRules}}  {{$value}},
+{{end}}
+{{range $value := .SignalRules}}  {{$value}},
+{{end}}{{range $value := .PtraceRules}}  {{$value}},
+{{end}}
+{{range $value := .RuntimeFileRules}}  {{$value}},
+{{end}}
+{{range $value := .ExeFileRules}}  {{$value}},
 {{end}}
-{{range $value := .WriteFileRules}}  {{$value.FilePath}} {{$value.PermSet}},
+{{range $value := .WriteFileRules}}  {{$value}},
 {{end}}
-{{range $value := .ReadFileRules}}  {{$value.FilePath}} {{$value.PermSet}},
+{{range $value := .OwnerFileRules}}  {{$value}},
+{{end}}
+{{range $value := .ReadFileRules}}  {{$value}},
 {{end}}
 }
 `
 
-type appArmorFileRule struct {
-	FilePath string
-	PermSet  string
-}
-
 type appArmorProfileData struct {
-	ProfileName    string
-	ExeFileRules   []appArmorFileRule
-	WriteFileRules []appArmorFileRule
-	ReadFileRules  []appArmorFileRule
+	ProfileName      string
+	CapabilityRules  []string
+	NetworkRules     []string
+	SignalRules      []string
+	PtraceRules      []string
+	RuntimeFileRules []appArmorFileRule
+	ExeFileRules     []appArmorFileRule
+	WriteFileRules   []appArmorFileRule
+	OwnerFileRules   []appArmorFileRule //files created by the application
+	ReadFileRules    []appArmorFileRule
 }
 
-//TODO:
-//need to safe more metadata about the artifacts in the monitor data
-//1. exe bit
-//2. w/r operation info (so we can add useful write rules)
-
 // GenProfile creates an AppArmor profile
 func GenProfile(artifactLocation string, profileName string) error {
 	containerReportFilePath := filepath.Join(artifactLocation, report.DefaultContainerReportFileName)
@@ -60,61 +68,97 @@ func GenProfile(artifactLocation string, profileName string) error {
 
 	profilePath := filepath.Join(artifactLocation, profileName)
 
-	profileFile, err := os.OpenFile(profilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0644)
+	profile, err := renderProfile(genProfileData(&creport, profileName))
 	if err != nil {
 		return err
 	}
 
-	defer profileFile.Close()
+	return ioutil.WriteFile(profilePath, profile, 0644)
+}
 
-	profileData := appArmorProfileData{ProfileName: profileName}
+// renderProfile creates the validated AppArmor profile from the profile data
+func renderProfile(profileData *appArmorProfileData) ([]byte, error) {
+	t, err := template.New("profile").Parse(appArmorTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, profileData); err != nil {
+		return nil, err
+	}
 
+	//make sure the profile can be loaded (apparmor_parser might not be available on the build host)
+	if _, err := Parse(out.Bytes()); err != nil {
+		return nil, fmt.Errorf("generated invalid AppArmor profile - %v", err)
+	}
+
+	return out.Bytes(), nil
+}
+
+func genProfileData(creport *report.ContainerReport, profileName string) *appArmorProfileData {
+	calls := observedCalls(creport)
+	socketArgs := socketArgValues(creport)
+
+	profileData := &appArmorProfileData{
+		ProfileName:      profileName,
+		CapabilityRules:  capabilityRules(creport),
+		NetworkRules:     networkRules(calls, socketArgs),
+		SignalRules:      signalRules(profileName, calls),
+		PtraceRules:      ptraceRules(profileName, calls),
+		RuntimeFileRules: runtimeFileRules,
+	}
+
+	if creport.Monitors.Pt == nil {
+		//no syscall data (allow all networking like before)
+		profileData.NetworkRules = []string{"network"}
+	}
+
+	var newFiles map[string]*report.FileInfo
+	if creport.Monitors.Fan != nil {
+		newFiles = creport.Monitors.Fan.NewFiles
+	}
+
+	var exeRules, writeRules, readRules []appArmorFileRule
 	for _, aprops := range creport.Image.Files {
 		if aprops == nil {
 			continue
 		}
-		if aprops.Flags == nil {
-			//default to "R" (todo: double check flag creation...)
-			profileData.ReadFileRules = append(profileData.ReadFileRules,
-				appArmorFileRule{
-					FilePath: aprops.FilePath,
-					PermSet:  "r",
-				})
-		} else {
-			switch {
-			case aprops.Flags["X"]:
-				profileData.ExeFileRules = append(profileData.ExeFileRules,
-					appArmorFileRule{
-						FilePath: aprops.FilePath,
-						PermSet:  report.PermSetFromFlags(aprops.Flags),
-					})
-			case aprops.Flags["W"]:
-				profileData.WriteFileRules = append(profileData.WriteFileRules,
-					appArmorFileRule{
-						FilePath: aprops.FilePath,
-						PermSet:  report.PermSetFromFlags(aprops.Flags),
-					})
-			case aprops.Flags["R"]:
-				profileData.ReadFileRules = append(profileData.ReadFileRules,
-					appArmorFileRule{
-						FilePath: aprops.FilePath,
-						PermSet:  report.PermSetFromFlags(aprops.Flags),
-					})
-			default:
-				//logrus.Printf("docker-slim: genAppArmorProfile - other artifact => %v\n", aprops)
-				//note: most are Symlinks
-			}
+
+		if strings.HasPrefix(aprops.ModeText, "L") {
+			//AppArmor mediates the symlink targets (and they are in the artifact list too)
+			continue
 		}
-	}
 
-	t, err := template.New("profile").Parse(appArmorTemplate)
-	if err != nil {
-		return err
-	}
+		if _, found := newFiles[aprops.FilePath]; found {
+			//the new files get the owner rules
+			continue
+		}
 
-	if err := t.Execute(profileFile, profileData); err != nil {
-		return err
+		rule := appArmorFileRule{
+			FilePath: escapePath(aprops.FilePath),
+			PermSet:  filePermSet(aprops),
+		}
+
+		if strings.HasPrefix(aprops.ModeText, "d") {
+			//directory listing
+			rule.FilePath += "/"
+			rule.PermSet = "r"
+		}
+
+		switch {
+		case aprops.Flags["X"]:
+			exeRules = append(exeRules, rule)
+		case aprops.Flags["W"]:
+			writeRules = append(writeRules, rule)
+		default:
+			readRules = append(readRules, rule)
+		}
 	}
 
-	return nil
+	profileData.ExeFileRules = collapseFileRules(exeRules)
+	profileData.WriteFileRules = collapseFileRules(writeRules)
+	profileData.OwnerFileRules = collapseFileRules(newFileRules(newFiles))
+	profileData.ReadFileRules = collapseFileRules(readRules)
+	return profileData
 }
@@ -0,0 +1,249 @@
+package apparmor
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	//number of files with the same permissions in a directory to use a 'dir/*' rule
+	globMinFiles = 4
+	//number of globbed directories with the same permissions to use a 'dir/**' rule
+	globMinDirs = 3
+	//minimum directory depth for the 'dir/**' rules (no '/usr/**')
+	globMinTreeDepth = 2
+)
+
+// noGlobDirs are the directories where the file rules are not collapsed
+var noGlobDirs = map[string]struct{}{
+	"/":    {},
+	"/etc": {},
+}
+
+// noGlobTrees are the directory trees where the file rules are not collapsed
+var noGlobTrees = []string{"/root", "/proc", "/sys", "/dev"}
+
+type appArmorFileRule struct {
+	FilePath string //AppArmor path expression (the literal path chars are escaped)
+	PermSet  string
+	Owner    bool
+}
+
+func (r appArmorFileRule) String() string {
+	var b strings.Builder
+	if r.Owner {
+		b.WriteString("owner ")
+	}
+
+	b.WriteString(quotePath(r.FilePath))
+	b.WriteString(" ")
+	b.WriteString(r.PermSet)
+	return b.String()
+}
+
+// escapePath escapes the AppArmor glob and alternation characters in the literal path
+func escapePath(filePath string) string {
+	var b strings.Builder
+	for _, c := range filePath {
+		switch c {
+		case '*', '?', '[', ']', '{', '}', '^', '\\', '"':
+			b.WriteRune('\\')
+		}
+
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// quotePath quotes the paths with the characters that can't be in the unquoted paths
+func quotePath(filePath string) string {
+	if strings.ContainsAny(filePath, " \t,#=()") {
+		return `"` + filePath + `"`
+	}
+
+	return filePath
+}
+
+func pathDepth(dir string) int {
+	if dir == "/" {
+		return 0
+	}
+
+	return strings.Count(dir, "/")
+}
+
+func canGlob(dir string, recursive bool) bool {
+	if _, found := noGlobDirs[dir]; found {
+		return false
+	}
+
+	for _, tree := range noGlobTrees {
+		if dir == tree || strings.HasPrefix(dir, tree+"/") {
+			return false
+		}
+	}
+
+	if strings.Contains(dir, `\`) {
+		//no globs for the directories with special chars
+		return false
+	}
+
+	if recursive {
+		return pathDepth(dir) >= globMinTreeDepth
+	}
+
+	return pathDepth(dir) >= 1
+}
+
+type ruleKey struct {
+	permSet string
+	owner   bool
+}
+
+type dirGlob struct {
+	dir       string
+	recursive bool
+}
+
+func (g dirGlob) covers(filePath string) bool {
+	if g.recursive {
+		return strings.HasPrefix(filePath, g.dir+"/")
+	}
+
+	return path.Dir(filePath) == g.dir
+}
+
+// collapseFileRules replaces the file rules with the same permissions
+// with the directory ('dir/*') and directory tree ('dir/**') rules
+func collapseFileRules(rules []appArmorFileRule) []appArmorFileRule {
+	groups := map[ruleKey][]string{}
+	var keys []ruleKey
+	for _, rule := range rules {
+		key := ruleKey{permSet: rule.PermSet, owner: rule.Owner}
+		if _, found := groups[key]; !found {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], rule.FilePath)
+	}
+
+	var result []appArmorFileRule
+	for _, key := range keys {
+		for _, filePath := range collapsePaths(groups[key]) {
+			result = append(result, appArmorFileRule{
+				FilePath: filePath,
+				PermSet:  key.permSet,
+				Owner:    key.owner,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].FilePath < result[j].FilePath
+	})
+
+	return result
+}
+
+// isFilePath returns false for the glob and directory rules
+func isFilePath(filePath string) bool {
+	return !strings.HasSuffix(filePath, "*") && !strings.HasSuffix(filePath, "/")
+}
+
+func collapsePaths(paths []string) []string {
+	dirFiles := map[string][]string{}
+	for _, filePath := range paths {
+		if !isFilePath(filePath) {
+			continue
+		}
+
+		dir := path.Dir(filePath)
+		dirFiles[dir] = append(dirFiles[dir], filePath)
+	}
+
+	globs := map[dirGlob]struct{}{}
+	for dir, files := range dirFiles {
+		if len(files) >= globMinFiles && canGlob(dir, false) {
+			globs[dirGlob{dir: dir}] = struct{}{}
+		}
+	}
+
+	//merge the directory globs into the directory tree globs (the deepest trees first)
+	for {
+		counts := map[string]int{}
+		for g := range globs {
+			for dir := path.Dir(g.dir); pathDepth(dir) >= globMinTreeDepth; dir = path.Dir(dir) {
+				counts[dir]++
+			}
+
+			if g.recursive {
+				continue
+			}
+
+			if pathDepth(g.dir) >= globMinTreeDepth {
+				//the directory tree glob for the directory itself covers its subdirectories
+				counts[g.dir]++
+			}
+		}
+
+		var candidates []string
+		for dir, count := range counts {
+			if count >= globMinDirs && canGlob(dir, true) {
+				candidates = append(candidates, dir)
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			if pathDepth(candidates[i]) != pathDepth(candidates[j]) {
+				return pathDepth(candidates[i]) > pathDepth(candidates[j])
+			}
+
+			return candidates[i] < candidates[j]
+		})
+
+		tree := dirGlob{dir: candidates[0], recursive: true}
+		for g := range globs {
+			if g.dir == tree.dir || tree.covers(g.dir) {
+				delete(globs, g)
+			}
+		}
+
+		globs[tree] = struct{}{}
+	}
+
+	var result []string
+	for g := range globs {
+		if g.recursive {
+			result = append(result, g.dir+"/**")
+		} else {
+			result = append(result, g.dir+"/*")
+		}
+	}
+
+	for _, filePath := range paths {
+		covered := false
+		for g := range globs {
+			if !isFilePath(filePath) {
+				break
+			}
+
+			if g.covers(filePath) {
+				covered = true
+				break
+			}
+		}
+
+		if !covered {
+			result = append(result, filePath)
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,113 @@
+package apparmor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollapsePaths(t *testing.T) {
+	tt := []struct {
+		desc     string
+		paths    []string
+		expected []string
+	}{
+		{
+			desc:     "few files",
+			paths:    []string{"/app/a.conf", "/app/b.conf", "/app/c.conf"},
+			expected: []string{"/app/a.conf", "/app/b.conf", "/app/c.conf"},
+		},
+		{
+			desc:     "directory glob",
+			paths:    []string{"/app/conf/a", "/app/conf/b", "/app/conf/c", "/app/conf/d", "/app/main"},
+			expected: []string{"/app/conf/*", "/app/main"},
+		},
+		{
+			desc: "directory tree glob",
+			paths: []string{
+				"/usr/lib/python3/a/1.py", "/usr/lib/python3/a/2.py", "/usr/lib/python3/a/3.py", "/usr/lib/python3/a/4.py",
+				"/usr/lib/python3/b/1.py", "/usr/lib/python3/b/2.py", "/usr/lib/python3/b/3.py", "/usr/lib/python3/b/4.py",
+				"/usr/lib/python3/c/1.py", "/usr/lib/python3/c/2.py", "/usr/lib/python3/c/3.py", "/usr/lib/python3/c/4.py",
+				"/usr/lib/python3/os.py",
+			},
+			expected: []string{"/usr/lib/python3/**"},
+		},
+		{
+			desc: "no top level tree glob",
+			paths: []string{
+				"/srv/a/1", "/srv/a/2", "/srv/a/3", "/srv/a/4",
+				"/srv/b/1", "/srv/b/2", "/srv/b/3", "/srv/b/4",
+				"/srv/c/1", "/srv/c/2", "/srv/c/3", "/srv/c/4",
+			},
+			expected: []string{"/srv/a/*", "/srv/b/*", "/srv/c/*"},
+		},
+		{
+			desc:     "no globs in /etc",
+			paths:    []string{"/etc/group", "/etc/hosts", "/etc/passwd", "/etc/resolv.conf"},
+			expected: []string{"/etc/group", "/etc/hosts", "/etc/passwd", "/etc/resolv.conf"},
+		},
+		{
+			desc:     "no globs in /proc",
+			paths:    []string{"/proc/self/a", "/proc/self/b", "/proc/self/c", "/proc/self/d"},
+			expected: []string{"/proc/self/a", "/proc/self/b", "/proc/self/c", "/proc/self/d"},
+		},
+		{
+			desc:     "no globs for the escaped directories",
+			paths:    []string{`/app/\[x\]/a`, `/app/\[x\]/b`, `/app/\[x\]/c`, `/app/\[x\]/d`},
+			expected: []string{`/app/\[x\]/a`, `/app/\[x\]/b`, `/app/\[x\]/c`, `/app/\[x\]/d`},
+		},
+		{
+			desc:     "directory rules are kept",
+			paths:    []string{"/app/conf/", "/app/conf/a", "/app/conf/b", "/app/conf/c", "/app/conf/d"},
+			expected: []string{"/app/conf/", "/app/conf/*"},
+		},
+	}
+
+	for _, test := range tt {
+		if got := collapsePaths(test.paths); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s:\ngot      %v\nexpected %v", test.desc, got, test.expected)
+		}
+	}
+}
+
+func TestCollapseFileRules(t *testing.T) {
+	rules := []appArmorFileRule{
+		{FilePath: "/app/conf/a", PermSet: "r"},
+		{FilePath: "/app/conf/b", PermSet: "r"},
+		{FilePath: "/app/conf/c", PermSet: "r"},
+		{FilePath: "/app/conf/d", PermSet: "rw"},
+		{FilePath: "/app/conf/e", PermSet: "r", Owner: true},
+		{FilePath: "/app/conf/f", PermSet: "r"},
+	}
+
+	//the rules with different permissions are not collapsed together
+	expected := []appArmorFileRule{
+		{FilePath: "/app/conf/*", PermSet: "r"},
+		{FilePath: "/app/conf/d", PermSet: "rw"},
+		{FilePath: "/app/conf/e", PermSet: "r", Owner: true},
+	}
+
+	if got := collapseFileRules(rules); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected rules:\ngot      %+v\nexpected %+v", got, expected)
+	}
+}
+
+func TestFileRuleString(t *testing.T) {
+	tt := []struct {
+		rule     appArmorFileRule
+		expected string
+	}{
+		{rule: appArmorFileRule{FilePath: escapePath("/app/main"), PermSet: "mrix"}, expected: "/app/main mrix"},
+		{rule: appArmorFileRule{FilePath: escapePath("/app/data[1]{x}*?"), PermSet: "r"}, expected: `/app/data\[1\]\{x\}\*\? r`},
+		{rule: appArmorFileRule{FilePath: escapePath("/app/my files,#1"), PermSet: "rw", Owner: true}, expected: `owner "/app/my files,#1" rw`},
+	}
+
+	for _, test := range tt {
+		if got := test.rule.String(); got != test.expected {
+			t.Errorf("got %q expected %q", got, test.expected)
+		}
+
+		if _, err := Parse([]byte("profile app {\n  " + test.rule.String() + ",\n}\n")); err != nil {
+			t.Errorf("%s: %v", test.expected, err)
+		}
+	}
+}
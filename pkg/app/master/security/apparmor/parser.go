@@ -0,0 +1,954 @@
+package apparmor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Profile is a parsed AppArmor profile
+type Profile struct {
+	Name       string
+	Attachment string
+	Flags      []string
+	Rules      []*Rule
+	Children   []*Profile //child profiles and hats
+	Line       int
+}
+
+// Rule is a parsed AppArmor profile rule
+type Rule struct {
+	Kind       string   //rule type ('file' for the file rules)
+	Qualifiers []string //audit, deny, allow, owner, ...
+	Args       []string //rule arguments (without the rule type)
+	Line       int
+}
+
+// ParseError is an AppArmor profile syntax error
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("apparmor profile syntax error (line %d) - %s", e.Line, e.Message)
+}
+
+type tokenType int
+
+const (
+	tokWord tokenType = iota
+	tokString
+	tokComma
+	tokOpenParen
+	tokCloseParen
+	tokOpenBrace
+	tokCloseBrace
+	tokInclude
+)
+
+type token struct {
+	typ   tokenType
+	value string
+	line  int
+}
+
+func (t token) String() string {
+	switch t.typ {
+	case tokComma:
+		return "','"
+	case tokOpenParen:
+		return "'('"
+	case tokCloseParen:
+		return "')'"
+	case tokOpenBrace:
+		return "'{'"
+	case tokCloseBrace:
+		return "'}'"
+	case tokString:
+		return fmt.Sprintf("\"%s\"", t.value)
+	default:
+		return fmt.Sprintf("'%s'", t.value)
+	}
+}
+
+// Parse parses and validates the AppArmor profiles
+// (the policy language parts used in the container profiles are validated,
+// the other rule types are only checked for their structure)
+func Parse(data []byte) ([]*Profile, error) {
+	tokens, err := tokenize(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	return p.parseFile()
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	line := 1
+	src := []rune(input)
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			end := i
+			for end < len(src) && src[end] != '\n' {
+				end++
+			}
+
+			text := string(src[i:end])
+			if strings.HasPrefix(text, "#include") {
+				tokens = append(tokens, token{
+					typ:   tokInclude,
+					value: strings.TrimSpace(strings.TrimPrefix(text, "#include")),
+					line:  line,
+				})
+			}
+
+			i = end
+		case c == ',':
+			tokens = append(tokens, token{typ: tokComma, value: ",", line: line})
+			i++
+		case c == '(':
+			tokens = append(tokens, token{typ: tokOpenParen, value: "(", line: line})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{typ: tokCloseParen, value: ")", line: line})
+			i++
+		case c == '{':
+			tokens = append(tokens, token{typ: tokOpenBrace, value: "{", line: line})
+			i++
+		case c == '}':
+			tokens = append(tokens, token{typ: tokCloseBrace, value: "}", line: line})
+			i++
+		case c == '"':
+			start := line
+			var b strings.Builder
+			i++
+			closed := false
+			for i < len(src) {
+				if src[i] == '\\' && i+1 < len(src) {
+					b.WriteRune(src[i])
+					b.WriteRune(src[i+1])
+					i += 2
+					continue
+				}
+
+				if src[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+
+				if src[i] == '\n' {
+					line++
+				}
+
+				b.WriteRune(src[i])
+				i++
+			}
+
+			if !closed {
+				return nil, &ParseError{Line: start, Message: "unterminated quoted string"}
+			}
+
+			tokens = append(tokens, token{typ: tokString, value: b.String(), line: start})
+		default:
+			var b strings.Builder
+			depth := 0
+		word:
+			for i < len(src) {
+				c := src[i]
+				switch {
+				case c == '\\' && i+1 < len(src):
+					b.WriteRune(c)
+					b.WriteRune(src[i+1])
+					i += 2
+					continue
+				case c == '{':
+					//alternations and variables ('/usr/{lib,lib64}/', '@{HOME}')
+					depth++
+				case c == '}':
+					if depth == 0 {
+						break word
+					}
+
+					depth--
+				case depth > 0:
+					if c == '\n' {
+						return nil, &ParseError{Line: line, Message: "unterminated '{' in " + b.String()}
+					}
+				case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ',' || c == '(' || c == ')':
+					break word
+				}
+
+				b.WriteRune(c)
+				i++
+			}
+
+			if depth > 0 {
+				return nil, &ParseError{Line: line, Message: "unterminated '{' in " + b.String()}
+			}
+
+			tokens = append(tokens, token{typ: tokWord, value: b.String(), line: line})
+		}
+	}
+
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *parser) lastLine() int {
+	if len(p.tokens) == 0 {
+		return 1
+	}
+
+	return p.tokens[len(p.tokens)-1].line
+}
+
+var variableAssignment = regexp.MustCompile(`^@\{[A-Za-z0-9_]+\}\s*\+?=`)
+
+func (p *parser) parseFile() ([]*Profile, error) {
+	var profiles []*Profile
+	for !p.eof() {
+		t := p.peek()
+		switch {
+		case t.typ == tokInclude:
+			p.next()
+			if err := validateInclude(t.value, t.line); err != nil {
+				return nil, err
+			}
+		case t.typ == tokWord && t.value == "include":
+			if err := p.parseInclude(); err != nil {
+				return nil, err
+			}
+		case t.typ == tokWord && strings.HasPrefix(t.value, "@{"):
+			if err := p.parseVariable(); err != nil {
+				return nil, err
+			}
+		case t.typ == tokWord && t.value == "abi":
+			if _, err := p.ruleTokens(); err != nil {
+				return nil, err
+			}
+		case t.typ == tokWord && (t.value == "profile" || strings.HasPrefix(t.value, "/")),
+			t.typ == tokString:
+			profile, err := p.parseProfile()
+			if err != nil {
+				return nil, err
+			}
+
+			profiles = append(profiles, profile)
+		default:
+			return nil, &ParseError{Line: t.line, Message: fmt.Sprintf("unexpected %s", t)}
+		}
+	}
+
+	if len(profiles) == 0 {
+		return nil, &ParseError{Line: p.lastLine(), Message: "no profiles"}
+	}
+
+	return profiles, nil
+}
+
+func validateInclude(target string, line int) error {
+	target = strings.TrimSpace(strings.TrimPrefix(target, "if exists"))
+	if (strings.HasPrefix(target, "<") && strings.HasSuffix(target, ">") && len(target) > 2) ||
+		(strings.HasPrefix(target, `"`) && strings.HasSuffix(target, `"`) && len(target) > 2) {
+		return nil
+	}
+
+	return &ParseError{Line: line, Message: fmt.Sprintf("invalid include - %s", target)}
+}
+
+func (p *parser) parseInclude() error {
+	first := p.next()
+	var parts []string
+	for !p.eof() && p.peek().line == first.line {
+		t := p.next()
+		if t.typ == tokString {
+			parts = append(parts, `"`+t.value+`"`)
+		} else {
+			parts = append(parts, t.value)
+		}
+	}
+
+	return validateInclude(strings.Join(parts, " "), first.line)
+}
+
+// parseVariable parses the variable assignments (they end at the end of the line)
+func (p *parser) parseVariable() error {
+	first := p.next()
+	text := first.value
+	for !p.eof() && p.peek().line == first.line {
+		text += " " + p.next().value
+	}
+
+	if !variableAssignment.MatchString(text) {
+		return &ParseError{Line: first.line, Message: fmt.Sprintf("invalid variable assignment - %s", text)}
+	}
+
+	return nil
+}
+
+var profileNamePattern = regexp.MustCompile(`^[^\s"]+$`)
+
+func (p *parser) parseProfile() (*Profile, error) {
+	first := p.next()
+	profile := &Profile{Line: first.line}
+	isHat := false
+	switch {
+	case first.typ == tokWord && first.value == "profile":
+		if p.eof() || (p.peek().typ != tokWord && p.peek().typ != tokString) {
+			return nil, &ParseError{Line: first.line, Message: "missing profile name"}
+		}
+
+		profile.Name = p.next().value
+		if !p.eof() && (p.peek().typ == tokString ||
+			(p.peek().typ == tokWord && strings.HasPrefix(p.peek().value, "/"))) {
+			profile.Attachment = p.next().value
+		}
+	case first.typ == tokWord && first.value == "hat":
+		isHat = true
+		if p.eof() || p.peek().typ != tokWord {
+			return nil, &ParseError{Line: first.line, Message: "missing hat name"}
+		}
+
+		profile.Name = p.next().value
+	case first.typ == tokWord && strings.HasPrefix(first.value, "^"):
+		isHat = true
+		profile.Name = strings.TrimPrefix(first.value, "^")
+	default:
+		profile.Name = first.value
+		profile.Attachment = first.value
+	}
+
+	if profile.Name == "" || !profileNamePattern.MatchString(profile.Name) {
+		return nil, &ParseError{Line: first.line, Message: fmt.Sprintf("invalid profile name - '%s'", profile.Name)}
+	}
+
+	for {
+		if p.eof() {
+			return nil, &ParseError{Line: p.lastLine(), Message: fmt.Sprintf("missing '{' for profile %s", profile.Name)}
+		}
+
+		t := p.next()
+		if t.typ == tokOpenBrace {
+			break
+		}
+
+		if isHat || t.typ != tokWord || (t.value != "flags=" && t.value != "flags") {
+			return nil, &ParseError{Line: t.line, Message: fmt.Sprintf("unexpected %s in profile %s header", t, profile.Name)}
+		}
+
+		if t.value == "flags" {
+			if p.eof() || p.peek().value != "=" {
+				return nil, &ParseError{Line: t.line, Message: "missing '=' after 'flags'"}
+			}
+
+			p.next()
+		}
+
+		flags, err := p.parseList(t.line)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, flag := range flags {
+			if _, found := profileFlags[flag]; !found {
+				return nil, &ParseError{Line: t.line, Message: fmt.Sprintf("unknown profile flag - %s", flag)}
+			}
+		}
+
+		profile.Flags = append(profile.Flags, flags...)
+	}
+
+	if err := p.parseBody(profile); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// parseList parses the '(value1,value2 value3)' lists
+func (p *parser) parseList(line int) ([]string, error) {
+	if p.eof() || p.peek().typ != tokOpenParen {
+		return nil, &ParseError{Line: line, Message: "missing '('"}
+	}
+
+	p.next()
+	var values []string
+	for {
+		if p.eof() {
+			return nil, &ParseError{Line: line, Message: "missing ')'"}
+		}
+
+		t := p.next()
+		switch t.typ {
+		case tokCloseParen:
+			return values, nil
+		case tokComma:
+		case tokWord:
+			values = append(values, t.value)
+		default:
+			return nil, &ParseError{Line: t.line, Message: fmt.Sprintf("unexpected %s in list", t)}
+		}
+	}
+}
+
+func (p *parser) parseBody(profile *Profile) error {
+	for {
+		if p.eof() {
+			return &ParseError{Line: p.lastLine(), Message: fmt.Sprintf("missing '}' for profile %s", profile.Name)}
+		}
+
+		t := p.peek()
+		switch {
+		case t.typ == tokCloseBrace:
+			p.next()
+			return nil
+		case t.typ == tokInclude:
+			p.next()
+			if err := validateInclude(t.value, t.line); err != nil {
+				return err
+			}
+
+			continue
+		case t.typ == tokWord && t.value == "include":
+			if err := p.parseInclude(); err != nil {
+				return err
+			}
+
+			continue
+		case t.typ == tokWord && (t.value == "profile" || t.value == "hat" || strings.HasPrefix(t.value, "^")):
+			if p.isChildProfile() {
+				child, err := p.parseProfile()
+				if err != nil {
+					return err
+				}
+
+				profile.Children = append(profile.Children, child)
+				continue
+			}
+		}
+
+		tokens, err := p.ruleTokens()
+		if err != nil {
+			return err
+		}
+
+		rule, err := parseRule(tokens)
+		if err != nil {
+			return err
+		}
+
+		profile.Rules = append(profile.Rules, rule)
+	}
+}
+
+// isChildProfile checks if the next tokens start a child profile (and not a 'profile' rule)
+func (p *parser) isChildProfile() bool {
+	for i := p.pos; i < len(p.tokens); i++ {
+		switch p.tokens[i].typ {
+		case tokOpenBrace:
+			return true
+		case tokComma, tokCloseBrace:
+			return false
+		}
+	}
+
+	return false
+}
+
+// ruleTokens returns the rule tokens (up to the ',' rule terminator)
+func (p *parser) ruleTokens() ([]token, error) {
+	var tokens []token
+	depth := 0
+	first := p.peek()
+	for {
+		if p.eof() {
+			return nil, &ParseError{Line: first.line, Message: "rule is not terminated by ','"}
+		}
+
+		t := p.next()
+		switch t.typ {
+		case tokOpenParen:
+			depth++
+		case tokCloseParen:
+			depth--
+			if depth < 0 {
+				return nil, &ParseError{Line: t.line, Message: "unexpected ')'"}
+			}
+		case tokComma:
+			if depth == 0 {
+				if len(tokens) == 0 {
+					return nil, &ParseError{Line: t.line, Message: "empty rule"}
+				}
+
+				return tokens, nil
+			}
+		case tokOpenBrace, tokCloseBrace, tokInclude:
+			//the error is reported for the rule line (the next line is usually the unexpected token)
+			return nil, &ParseError{Line: first.line, Message: fmt.Sprintf("rule is not terminated by ',' (unexpected %s)", t)}
+		}
+
+		tokens = append(tokens, t)
+	}
+}
+
+var ruleQualifiers = map[string]struct{}{
+	"audit": {},
+	"deny":  {},
+	"allow": {},
+	"owner": {},
+	"other": {},
+	"quiet": {},
+}
+
+// rule types that are only checked for their structure
+var otherRuleTypes = map[string]struct{}{
+	"unix":           {},
+	"mount":          {},
+	"remount":        {},
+	"umount":         {},
+	"pivot_root":     {},
+	"change_profile": {},
+	"change_hat":     {},
+	"dbus":           {},
+	"rlimit":         {},
+	"set":            {},
+	"link":           {},
+	"userns":         {},
+	"io_uring":       {},
+	"mqueue":         {},
+	"all":            {},
+}
+
+func parseRule(tokens []token) (*Rule, error) {
+	rule := &Rule{Line: tokens[0].line}
+	for len(tokens) > 0 && tokens[0].typ == tokWord {
+		if _, found := ruleQualifiers[tokens[0].value]; !found {
+			break
+		}
+
+		rule.Qualifiers = append(rule.Qualifiers, tokens[0].value)
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) == 0 {
+		return nil, &ParseError{Line: rule.Line, Message: "missing rule after the qualifiers"}
+	}
+
+	isDeny := false
+	for _, q := range rule.Qualifiers {
+		if q == "deny" {
+			isDeny = true
+		}
+	}
+
+	for _, t := range tokens[1:] {
+		rule.Args = append(rule.Args, t.value)
+	}
+
+	first := tokens[0]
+	if first.typ == tokWord {
+		switch first.value {
+		case "capability":
+			rule.Kind = first.value
+			return rule, validateNames(tokens[1:], capabilityNames, "capability")
+		case "network":
+			rule.Kind = first.value
+			return rule, validateNetworkRule(tokens[1:])
+		case "signal":
+			rule.Kind = first.value
+			return rule, validateAccessRule(tokens[1:], signalPerms, true)
+		case "ptrace":
+			rule.Kind = first.value
+			return rule, validateAccessRule(tokens[1:], ptracePerms, false)
+		case "file":
+			rule.Kind = first.value
+			if len(tokens) == 1 {
+				return rule, nil
+			}
+
+			return rule, validateFileRule(tokens[1:], isDeny)
+		}
+
+		if _, found := otherRuleTypes[first.value]; found {
+			rule.Kind = first.value
+			return rule, nil
+		}
+	}
+
+	rule.Kind = "file"
+	rule.Args = nil
+	for _, t := range tokens {
+		rule.Args = append(rule.Args, t.value)
+	}
+
+	if first.typ == tokString || isPathExpr(first.value) || (len(tokens) > 1 && isPathToken(tokens[1])) {
+		return rule, validateFileRule(tokens, isDeny)
+	}
+
+	return nil, &ParseError{Line: first.line, Message: fmt.Sprintf("unknown rule - %s", first.value)}
+}
+
+func validateNames(tokens []token, known map[string]struct{}, nameType string) error {
+	for _, t := range tokens {
+		if t.typ != tokWord {
+			return &ParseError{Line: t.line, Message: fmt.Sprintf("unexpected %s in %s rule", t, nameType)}
+		}
+
+		if _, found := known[t.value]; !found {
+			return &ParseError{Line: t.line, Message: fmt.Sprintf("unknown %s - %s", nameType, t.value)}
+		}
+	}
+
+	return nil
+}
+
+func validateNetworkRule(tokens []token) error {
+	if len(tokens) > 3 {
+		return &ParseError{Line: tokens[0].line, Message: "too many network rule arguments"}
+	}
+
+	for _, t := range tokens {
+		if t.typ != tokWord {
+			return &ParseError{Line: t.line, Message: fmt.Sprintf("unexpected %s in network rule", t)}
+		}
+
+		_, isDomain := networkDomains[t.value]
+		_, isType := networkTypes[t.value]
+		_, isProtocol := networkProtocols[t.value]
+		if !isDomain && !isType && !isProtocol {
+			return &ParseError{Line: t.line, Message: fmt.Sprintf("unknown network domain or type - %s", t.value)}
+		}
+	}
+
+	return nil
+}
+
+// validateAccessRule validates the 'signal' and 'ptrace' rules
+// ('[access] [set=(signals)] [peer=label]')
+func validateAccessRule(tokens []token, perms map[string]struct{}, hasSet bool) error {
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.typ == tokOpenParen:
+			end := i + 1
+			for ; end < len(tokens) && tokens[end].typ != tokCloseParen; end++ {
+				if tokens[end].typ == tokComma {
+					continue
+				}
+
+				if _, found := perms[tokens[end].value]; !found {
+					return &ParseError{Line: t.line, Message: fmt.Sprintf("unknown access - %s", tokens[end].value)}
+				}
+			}
+
+			i = end
+		case t.typ == tokWord && strings.HasPrefix(t.value, "peer="):
+			if t.value == "peer=" {
+				return &ParseError{Line: t.line, Message: "missing peer label"}
+			}
+		case t.typ == tokWord && hasSet && strings.HasPrefix(t.value, "set="):
+			var names []token
+			if t.value == "set=" {
+				end := i + 1
+				if end >= len(tokens) || tokens[end].typ != tokOpenParen {
+					return &ParseError{Line: t.line, Message: "missing signal set"}
+				}
+
+				for end++; end < len(tokens) && tokens[end].typ != tokCloseParen; end++ {
+					if tokens[end].typ != tokComma {
+						names = append(names, tokens[end])
+					}
+				}
+
+				i = end
+			} else {
+				names = append(names, token{typ: tokWord, value: strings.TrimPrefix(t.value, "set="), line: t.line})
+			}
+
+			for _, name := range names {
+				if _, found := signalNames[name.value]; !found && !rtSignalPattern.MatchString(name.value) {
+					return &ParseError{Line: name.line, Message: fmt.Sprintf("unknown signal - %s", name.value)}
+				}
+			}
+		case t.typ == tokWord:
+			if _, found := perms[t.value]; !found {
+				return &ParseError{Line: t.line, Message: fmt.Sprintf("unknown access - %s", t.value)}
+			}
+		default:
+			return &ParseError{Line: t.line, Message: fmt.Sprintf("unexpected %s", t)}
+		}
+	}
+
+	return nil
+}
+
+func isPathExpr(value string) bool {
+	return strings.HasPrefix(value, "/") || strings.HasPrefix(value, "@{")
+}
+
+func isPathToken(t token) bool {
+	return t.typ == tokString || isPathExpr(t.value)
+}
+
+// validateFileRule validates the '[path] [perms] [-> target]' and '[perms] [path] [-> target]' rules
+func validateFileRule(tokens []token, isDeny bool) error {
+	line := tokens[0].line
+	if len(tokens) > 2 && tokens[len(tokens)-2].value == "->" {
+		target := tokens[len(tokens)-1]
+		if target.value == "" {
+			return &ParseError{Line: line, Message: "missing exec target"}
+		}
+
+		tokens = tokens[:len(tokens)-2]
+	}
+
+	if len(tokens) != 2 {
+		return &ParseError{Line: line, Message: "file rules need a path and permissions"}
+	}
+
+	pathToken, permToken := tokens[0], tokens[1]
+	if !isPathToken(pathToken) {
+		pathToken, permToken = tokens[1], tokens[0]
+	}
+
+	if !isPathToken(pathToken) {
+		return &ParseError{Line: line, Message: fmt.Sprintf("invalid file rule path - %s", pathToken.value)}
+	}
+
+	if pathToken.typ == tokString && !isPathExpr(pathToken.value) {
+		return &ParseError{Line: line, Message: fmt.Sprintf("file rule path must be absolute - %s", pathToken.value)}
+	}
+
+	if err := validatePathExpr(pathToken.value); err != nil {
+		return &ParseError{Line: line, Message: err.Error()}
+	}
+
+	if permToken.typ != tokWord {
+		return &ParseError{Line: line, Message: fmt.Sprintf("invalid file permissions - %s", permToken.value)}
+	}
+
+	if err := validateFilePerms(permToken.value, isDeny); err != nil {
+		return &ParseError{Line: line, Message: err.Error()}
+	}
+
+	return nil
+}
+
+// validatePathExpr checks the glob brackets in the path expressions
+func validatePathExpr(value string) error {
+	var stack []rune
+	for i := 0; i < len(value); i++ {
+		c := rune(value[i])
+		switch c {
+		case '\\':
+			i++
+		case '{', '[':
+			stack = append(stack, c)
+		case '}', ']':
+			open := '{'
+			if c == ']' {
+				open = '['
+			}
+
+			if len(stack) == 0 || stack[len(stack)-1] != open {
+				return fmt.Errorf("unbalanced '%c' in path - %s", c, value)
+			}
+
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if len(stack) > 0 {
+		return fmt.Errorf("unbalanced '%c' in path - %s", stack[len(stack)-1], value)
+	}
+
+	return nil
+}
+
+// exec permission modes (the longest modes first)
+var execModes = []string{
+	"pix", "Pix", "cix", "Cix", "pux", "PUx", "cux", "CUx",
+	"ix", "px", "Px", "ux", "Ux", "cx", "Cx",
+}
+
+// validateFilePerms validates the file permissions ('rwalkm' and one exec mode)
+func validateFilePerms(perms string, isDeny bool) error {
+	if perms == "" {
+		return fmt.Errorf("missing file permissions")
+	}
+
+	seen := map[byte]struct{}{}
+	execMode := ""
+	for i := 0; i < len(perms); {
+		c := perms[i]
+		if strings.IndexByte("rwalkm", c) >= 0 {
+			seen[c] = struct{}{}
+			i++
+			continue
+		}
+
+		mode := ""
+		for _, m := range execModes {
+			if strings.HasPrefix(perms[i:], m) {
+				mode = m
+				break
+			}
+		}
+
+		if mode == "" && c == 'x' && isDeny {
+			mode = "x"
+		}
+
+		if mode == "" {
+			if c == 'x' {
+				return fmt.Errorf("'x' needs an exec mode (ix, px, ux, cx) - %s", perms)
+			}
+
+			return fmt.Errorf("invalid file permissions - %s", perms)
+		}
+
+		if execMode != "" && execMode != mode {
+			return fmt.Errorf("conflicting exec modes (%s and %s) - %s", execMode, mode, perms)
+		}
+
+		execMode = mode
+		i += len(mode)
+	}
+
+	_, hasWrite := seen['w']
+	_, hasAppend := seen['a']
+	if hasWrite && hasAppend {
+		return fmt.Errorf("conflicting 'w' and 'a' permissions - %s", perms)
+	}
+
+	return nil
+}
+
+var profileFlags = map[string]struct{}{
+	"complain":               {},
+	"enforce":                {},
+	"kill":                   {},
+	"unconfined":             {},
+	"prompt":                 {},
+	"audit":                  {},
+	"mediate_deleted":        {},
+	"delegate_deleted":       {},
+	"attach_disconnected":    {},
+	"no_attach_disconnected": {},
+	"chroot_relative":        {},
+	"namespace_relative":     {},
+	"chroot_attach":          {},
+	"chroot_no_attach":       {},
+	"debug":                  {},
+	"interruptible":          {},
+}
+
+var capabilityNames = map[string]struct{}{
+	"chown":              {},
+	"dac_override":       {},
+	"dac_read_search":    {},
+	"fowner":             {},
+	"fsetid":             {},
+	"kill":               {},
+	"setgid":             {},
+	"setuid":             {},
+	"setpcap":            {},
+	"linux_immutable":    {},
+	"net_bind_service":   {},
+	"net_broadcast":      {},
+	"net_admin":          {},
+	"net_raw":            {},
+	"ipc_lock":           {},
+	"ipc_owner":          {},
+	"sys_module":         {},
+	"sys_rawio":          {},
+	"sys_chroot":         {},
+	"sys_ptrace":         {},
+	"sys_pacct":          {},
+	"sys_admin":          {},
+	"sys_boot":           {},
+	"sys_nice":           {},
+	"sys_resource":       {},
+	"sys_time":           {},
+	"sys_tty_config":     {},
+	"mknod":              {},
+	"lease":              {},
+	"audit_write":        {},
+	"audit_control":      {},
+	"setfcap":            {},
+	"mac_override":       {},
+	"mac_admin":          {},
+	"syslog":             {},
+	"wake_alarm":         {},
+	"block_suspend":      {},
+	"audit_read":         {},
+	"perfmon":            {},
+	"bpf":                {},
+	"checkpoint_restore": {},
+}
+
+var networkDomains = map[string]struct{}{
+	"unix": {}, "inet": {}, "ax25": {}, "ipx": {}, "appletalk": {}, "netrom": {},
+	"bridge": {}, "atmpvc": {}, "x25": {}, "inet6": {}, "rose": {}, "netbeui": {},
+	"security": {}, "key": {}, "netlink": {}, "packet": {}, "ash": {}, "econet": {},
+	"atmsvc": {}, "rds": {}, "sna": {}, "irda": {}, "pppox": {}, "wanpipe": {},
+	"llc": {}, "ib": {}, "mpls": {}, "can": {}, "tipc": {}, "bluetooth": {},
+	"iucv": {}, "rxrpc": {}, "isdn": {}, "phonet": {}, "ieee802154": {}, "caif": {},
+	"alg": {}, "nfc": {}, "vsock": {}, "kcm": {}, "qipcrtr": {}, "smc": {},
+	"xdp": {}, "mctp": {},
+}
+
+var networkTypes = map[string]struct{}{
+	"stream": {}, "dgram": {}, "seqpacket": {}, "rdm": {}, "raw": {}, "packet": {},
+}
+
+var networkProtocols = map[string]struct{}{
+	"tcp": {}, "udp": {}, "icmp": {},
+}
+
+var signalPerms = map[string]struct{}{
+	"send": {}, "receive": {}, "r": {}, "w": {}, "rw": {}, "read": {}, "write": {},
+}
+
+var ptracePerms = map[string]struct{}{
+	"trace": {}, "read": {}, "tracedby": {}, "readby": {}, "r": {}, "w": {}, "rw": {},
+}
+
+var signalNames = map[string]struct{}{
+	"hup": {}, "int": {}, "quit": {}, "ill": {}, "trap": {}, "abrt": {}, "bus": {},
+	"fpe": {}, "kill": {}, "usr1": {}, "segv": {}, "usr2": {}, "pipe": {}, "alrm": {},
+	"term": {}, "stkflt": {}, "chld": {}, "cont": {}, "stop": {}, "stp": {}, "ttin": {},
+	"ttou": {}, "urg": {}, "xcpu": {}, "xfsz": {}, "vtalrm": {}, "prof": {}, "winch": {},
+	"io": {}, "pwr": {}, "sys": {}, "emt": {}, "exists": {},
+}
+
+var rtSignalPattern = regexp.MustCompile(`^rtmin\+[0-9]+$`)
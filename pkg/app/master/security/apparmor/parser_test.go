@@ -0,0 +1,152 @@
+package apparmor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const knownGoodProfile = `
+#include <tunables/global>
+abi <abi/3.0>,
+@{APP_DIR}=/opt/app
+@{APP_DIR}+=/srv/app
+
+profile app-profile /usr/bin/app flags=(attach_disconnected,mediate_deleted) {
+  #include <abstractions/base>
+  include if exists <local/app>
+
+  capability net_bind_service,
+  capability setuid setgid,
+  network inet stream,
+  network unix,
+  network,
+  signal (receive) peer=unconfined,
+  signal (send,receive) set=(term, kill, rtmin+5) peer=app-profile,
+  ptrace (read,readby) peer=app-profile,
+  unix (send, receive) type=stream,
+  mount fstype=tmpfs -> /tmp/,
+
+  /etc/hosts r,
+  /usr/{lib,lib64}/** mr,
+  @{APP_DIR}/bin/app mrix,
+  owner /tmp/** rwk,
+  "/app/data files/*" rw,
+  r /var/log/app.log,
+  deny /etc/shadow rwx,
+  audit /usr/bin/helper Px -> helper,
+  file,
+  /app/escaped\[1\] r,
+
+  ^hat-name {
+    /etc/hat.conf r,
+  }
+
+  profile child /usr/bin/child {
+    /usr/bin/child mr,
+  }
+}
+
+/usr/sbin/daemon {
+  /var/run/daemon.pid rwk,
+}
+`
+
+func TestParse(t *testing.T) {
+	profiles, err := Parse([]byte(knownGoodProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(profiles) != 2 {
+		t.Fatalf("expected 2 profiles (got %d)", len(profiles))
+	}
+
+	profile := profiles[0]
+	if profile.Name != "app-profile" || profile.Attachment != "/usr/bin/app" || profile.Line != 7 {
+		t.Errorf("unexpected profile header: name=%q attachment=%q line=%d", profile.Name, profile.Attachment, profile.Line)
+	}
+
+	if expected := []string{"attach_disconnected", "mediate_deleted"}; !reflect.DeepEqual(profile.Flags, expected) {
+		t.Errorf("unexpected flags: %v", profile.Flags)
+	}
+
+	var kinds []string
+	for _, rule := range profile.Rules {
+		kinds = append(kinds, rule.Kind)
+	}
+
+	expectedKinds := []string{
+		"capability", "capability", "network", "network", "network",
+		"signal", "signal", "ptrace", "unix", "mount",
+		"file", "file", "file", "file", "file", "file", "file", "file", "file", "file",
+	}
+
+	if !reflect.DeepEqual(kinds, expectedKinds) {
+		t.Errorf("unexpected rule kinds:\ngot      %v\nexpected %v", kinds, expectedKinds)
+	}
+
+	owner := profile.Rules[13]
+	if !reflect.DeepEqual(owner.Qualifiers, []string{"owner"}) || !reflect.DeepEqual(owner.Args, []string{"/tmp/**", "rwk"}) {
+		t.Errorf("unexpected owner rule: %+v", owner)
+	}
+
+	if len(profile.Children) != 2 || profile.Children[0].Name != "hat-name" || profile.Children[1].Name != "child" {
+		t.Errorf("unexpected child profiles: %+v", profile.Children)
+	}
+
+	if daemon := profiles[1]; daemon.Name != "/usr/sbin/daemon" || daemon.Attachment != "/usr/sbin/daemon" || len(daemon.Rules) != 1 {
+		t.Errorf("unexpected profile: %+v", daemon)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tt := []struct {
+		desc    string
+		profile string
+		line    int
+		message string
+	}{
+		{desc: "empty", profile: "# no profiles\n", line: 1, message: "no profiles"},
+		{desc: "missing brace", profile: "profile app {\n  /etc/hosts r,\n", line: 2, message: "missing '}'"},
+		{desc: "missing comma", profile: "profile app {\n  /etc/hosts r\n}\n", line: 2, message: "not terminated by ','"},
+		{desc: "unknown capability", profile: "profile app {\n  capability net_bind,\n}\n", line: 2, message: "unknown capability - net_bind"},
+		{desc: "uppercase capability", profile: "profile app {\n  capability NET_RAW,\n}\n", line: 2, message: "unknown capability"},
+		{desc: "unknown network", profile: "profile app {\n  network inet streams,\n}\n", line: 2, message: "unknown network domain or type - streams"},
+		{desc: "unknown signal", profile: "profile app {\n  signal send set=(term,bogus),\n}\n", line: 2, message: "unknown signal - bogus"},
+		{desc: "unknown signal access", profile: "profile app {\n  signal (send,trace),\n}\n", line: 2, message: "unknown access - trace"},
+		{desc: "missing peer", profile: "profile app {\n  ptrace read peer=,\n}\n", line: 2, message: "missing peer label"},
+		{desc: "exec mode", profile: "profile app {\n  /usr/bin/app rx,\n}\n", line: 2, message: "needs an exec mode"},
+		{desc: "exec modes", profile: "profile app {\n  /usr/bin/app ixpx,\n}\n", line: 2, message: "conflicting exec modes"},
+		{desc: "write append", profile: "profile app {\n  /var/log/app.log wa,\n}\n", line: 2, message: "conflicting 'w' and 'a'"},
+		{desc: "bad perms", profile: "profile app {\n  /etc/hosts rz,\n}\n", line: 2, message: "invalid file permissions"},
+		{desc: "relative path", profile: "profile app {\n  \"etc/hosts\" r,\n}\n", line: 2, message: "must be absolute"},
+		{desc: "unbalanced glob", profile: "profile app {\n  /etc/[ab r,\n}\n", line: 2, message: "unbalanced '['"},
+		{desc: "unterminated alternation", profile: "profile app {\n  /usr/{lib,lib64/** r,\n}\n", line: 2, message: "unterminated '{'"},
+		{desc: "unterminated string", profile: "profile app {\n  \"/etc/hosts r,\n}\n", line: 2, message: "unterminated quoted string"},
+		{desc: "unknown rule", profile: "profile app {\n  bogus rule,\n}\n", line: 2, message: "unknown rule - bogus"},
+		{desc: "unknown flag", profile: "profile app flags=(attach_disconected) {\n}\n", line: 1, message: "unknown profile flag"},
+		{desc: "bad include", profile: "#include tunables/global\nprofile app {\n}\n", line: 1, message: "invalid include"},
+		{desc: "bad variable", profile: "@{APP_DIR} /opt/app\nprofile app {\n}\n", line: 1, message: "invalid variable assignment"},
+		{desc: "missing name", profile: "profile {\n}\n", line: 1, message: "missing profile name"},
+		{desc: "qualifiers only", profile: "profile app {\n  audit deny,\n}\n", line: 2, message: "missing rule after the qualifiers"},
+	}
+
+	for _, test := range tt {
+		_, err := Parse([]byte(test.profile))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.desc)
+			continue
+		}
+
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("%s: unexpected error type - %v", test.desc, err)
+			continue
+		}
+
+		if perr.Line != test.line || !strings.Contains(perr.Message, test.message) {
+			t.Errorf("%s: unexpected error (line %d): %v", test.desc, perr.Line, err)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package apparmor
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+// Socket address families and types (from the recorded 'socket' arguments)
+var socketDomains = map[uint64]string{
+	1:  "unix",
+	2:  "inet",
+	10: "inet6",
+	16: "netlink",
+	17: "packet",
+}
+
+var socketTypes = map[uint64]string{
+	1: "stream",
+	2: "dgram",
+	3: "raw",
+	5: "seqpacket",
+}
+
+const socketTypeMask = 0xf //SOCK_NONBLOCK and SOCK_CLOEXEC are in the higher bits
+
+// signalCalls are the system calls used to send signals to other processes
+var signalCalls = []string{"kill", "tkill", "tgkill", "rt_sigqueueinfo", "rt_tgsigqueueinfo", "pidfd_send_signal"}
+
+// ptraceCalls are the system calls used to trace other processes
+var ptraceCalls = []string{"ptrace", "process_vm_readv", "process_vm_writev"}
+
+// tempDirs are the directories with the random (temporary) file names
+// (the new files in these directories are covered by one owner rule)
+var tempDirs = []string{"/tmp", "/var/tmp", "/dev/shm"}
+
+// runtimeFileRules are the rules for the runtime files not visible to the file monitor
+var runtimeFileRules = []appArmorFileRule{
+	{FilePath: "/dev/null", PermSet: "rw"},
+	{FilePath: "/dev/zero", PermSet: "rw"},
+	{FilePath: "/dev/full", PermSet: "rw"},
+	{FilePath: "/dev/random", PermSet: "r"},
+	{FilePath: "/dev/urandom", PermSet: "r"},
+	{FilePath: "/dev/tty", PermSet: "rw"},
+	{FilePath: "/dev/pts/*", PermSet: "rw"},
+	{FilePath: "/proc/*/**", PermSet: "r", Owner: true},
+	{FilePath: "/proc/sys/**", PermSet: "r"},
+	{FilePath: "/proc/meminfo", PermSet: "r"},
+	{FilePath: "/proc/cpuinfo", PermSet: "r"},
+	{FilePath: "/proc/stat", PermSet: "r"},
+	{FilePath: "/proc/loadavg", PermSet: "r"},
+	{FilePath: "/sys/fs/cgroup/**", PermSet: "r"},
+	{FilePath: "/sys/devices/system/cpu/**", PermSet: "r"},
+}
+
+func observedCalls(creport *report.ContainerReport) map[string]struct{} {
+	calls := map[string]struct{}{}
+	if creport.Monitors.Pt == nil {
+		return calls
+	}
+
+	for _, info := range creport.Monitors.Pt.SyscallStats {
+		calls[info.Name] = struct{}{}
+	}
+
+	return calls
+}
+
+func hasAnyCall(calls map[string]struct{}, names []string) bool {
+	for _, name := range names {
+		if _, found := calls[name]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+func socketArgValues(creport *report.ContainerReport) [][]uint64 {
+	if creport.Monitors.Pt == nil {
+		return nil
+	}
+
+	//the syscall args are keyed by the syscall number
+	var values [][]uint64
+	for _, info := range creport.Monitors.Pt.SyscallArgs {
+		if info != nil && info.Name == "socket" {
+			values = append(values, info.Values...)
+		}
+	}
+
+	return values
+}
+
+// capabilityRules creates the capability rules for the capabilities used by the application
+func capabilityRules(creport *report.ContainerReport) []string {
+	if creport.Monitors.Pt == nil {
+		return nil
+	}
+
+	var rules []string
+	for _, name := range creport.Monitors.Pt.CapabilitySet() {
+		rules = append(rules, fmt.Sprintf("capability %s", strings.ToLower(name)))
+	}
+
+	return rules
+}
+
+// networkRules creates the network rules for the observed socket families and types
+// (all networking is allowed if the 'socket' arguments were not recorded)
+func networkRules(calls map[string]struct{}, socketArgs [][]uint64) []string {
+	rules := map[string]struct{}{}
+	if _, found := calls["socketpair"]; found {
+		rules["network unix"] = struct{}{}
+	}
+
+	if _, found := calls["socket"]; found {
+		if len(socketArgs) == 0 {
+			return []string{"network"}
+		}
+
+		for _, values := range socketArgs {
+			if len(values) < 2 {
+				return []string{"network"}
+			}
+
+			domain, found := socketDomains[values[0]]
+			if !found {
+				//the profile can't be more specific than the address family
+				return []string{"network"}
+			}
+
+			if stype, found := socketTypes[values[1]&socketTypeMask]; found {
+				rules[fmt.Sprintf("network %s %s", domain, stype)] = struct{}{}
+			} else {
+				rules[fmt.Sprintf("network %s", domain)] = struct{}{}
+			}
+		}
+	}
+
+	var result []string
+	for rule := range rules {
+		result = append(result, rule)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// signalRules allows the container processes to receive signals from the container runtime
+// and to signal each other
+func signalRules(profileName string, calls map[string]struct{}) []string {
+	rules := []string{"signal (receive) peer=unconfined"}
+	if hasAnyCall(calls, signalCalls) {
+		rules = append(rules, fmt.Sprintf("signal (send,receive) peer=%s", profileName))
+	} else {
+		rules = append(rules, fmt.Sprintf("signal (receive) peer=%s", profileName))
+	}
+
+	return rules
+}
+
+// ptraceRules allows the container processes to read each other's process info
+// (tracing is allowed only if the application used it)
+func ptraceRules(profileName string, calls map[string]struct{}) []string {
+	if hasAnyCall(calls, ptraceCalls) {
+		return []string{fmt.Sprintf("ptrace (trace,read,tracedby,readby) peer=%s", profileName)}
+	}
+
+	return []string{fmt.Sprintf("ptrace (read,readby) peer=%s", profileName)}
+}
+
+// filePermSet maps the observed file activity to the AppArmor file permissions
+// ('m' is needed to map the executables and the shared libraries)
+func filePermSet(aprops *report.ArtifactProps) string {
+	flags := aprops.Flags
+	if flags == nil {
+		flags = map[string]bool{"R": true}
+	}
+
+	base := path.Base(aprops.FilePath)
+	isLib := strings.HasSuffix(base, ".so") || strings.Contains(base, ".so.")
+	isExe := strings.Contains(aprops.ModeText, "x")
+
+	var b strings.Builder
+	if flags["X"] || (flags["R"] && (isLib || isExe)) {
+		b.WriteString("m")
+	}
+
+	if flags["R"] || flags["W"] || flags["X"] {
+		b.WriteString("r")
+	}
+
+	if flags["W"] {
+		b.WriteString("w")
+		if isLockFile(aprops.FilePath) {
+			b.WriteString("k")
+		}
+	}
+
+	if flags["X"] {
+		b.WriteString("ix")
+	}
+
+	if b.Len() == 0 {
+		return "r"
+	}
+
+	return b.String()
+}
+
+func isLockFile(filePath string) bool {
+	return strings.HasSuffix(filePath, ".lock") || isPidFile(filePath)
+}
+
+func isPidFile(filePath string) bool {
+	return strings.HasSuffix(filePath, ".pid")
+}
+
+func tempDir(filePath string) string {
+	for _, dir := range tempDirs {
+		if strings.HasPrefix(filePath, dir+"/") {
+			return dir
+		}
+	}
+
+	return ""
+}
+
+// newFileRules creates the owner rules for the files created by the application
+func newFileRules(newFiles map[string]*report.FileInfo) []appArmorFileRule {
+	var rules []appArmorFileRule
+	temp := map[string]struct{}{}
+	for name, info := range newFiles {
+		if info == nil {
+			continue
+		}
+
+		switch {
+		case isPidFile(name):
+			rules = append(rules, appArmorFileRule{FilePath: escapePath(name), PermSet: "rwk", Owner: true})
+		case tempDir(name) != "":
+			temp[tempDir(name)] = struct{}{}
+		default:
+			rules = append(rules, appArmorFileRule{FilePath: escapePath(name), PermSet: "rw", Owner: true})
+		}
+	}
+
+	for dir := range temp {
+		rules = append(rules, appArmorFileRule{FilePath: dir + "/**", PermSet: "rwk", Owner: true})
+	}
+
+	return rules
+}
@@ -0,0 +1,204 @@
+package apparmor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+func TestNetworkRules(t *testing.T) {
+	socket := map[string]struct{}{"socket": {}}
+	tt := []struct {
+		desc       string
+		calls      map[string]struct{}
+		socketArgs [][]uint64
+		expected   []string
+	}{
+		{desc: "no sockets", calls: map[string]struct{}{"read": {}}},
+		{desc: "socketpair", calls: map[string]struct{}{"socketpair": {}}, expected: []string{"network unix"}},
+		{desc: "no socket args", calls: socket, expected: []string{"network"}},
+		{
+			desc:       "socket args",
+			calls:      socket,
+			socketArgs: [][]uint64{{2, 1}, {2, 1 | 0x80000}, {10, 2}, {1, 5}, {16, 4}},
+			expected:   []string{"network inet stream", "network inet6 dgram", "network netlink", "network unix seqpacket"},
+		},
+		{desc: "unknown family", calls: socket, socketArgs: [][]uint64{{2, 1}, {38, 5}}, expected: []string{"network"}},
+	}
+
+	for _, test := range tt {
+		if got := networkRules(test.calls, test.socketArgs); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: got %v expected %v", test.desc, got, test.expected)
+		}
+	}
+}
+
+func TestSignalAndPtraceRules(t *testing.T) {
+	calls := map[string]struct{}{"kill": {}}
+	expected := []string{"signal (receive) peer=unconfined", "signal (send,receive) peer=app"}
+	if got := signalRules("app", calls); !reflect.DeepEqual(got, expected) {
+		t.Errorf("signal rules: got %v expected %v", got, expected)
+	}
+
+	expected = []string{"signal (receive) peer=unconfined", "signal (receive) peer=app"}
+	if got := signalRules("app", nil); !reflect.DeepEqual(got, expected) {
+		t.Errorf("signal rules: got %v expected %v", got, expected)
+	}
+
+	expected = []string{"ptrace (read,readby) peer=app"}
+	if got := ptraceRules("app", calls); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ptrace rules: got %v expected %v", got, expected)
+	}
+
+	expected = []string{"ptrace (trace,read,tracedby,readby) peer=app"}
+	if got := ptraceRules("app", map[string]struct{}{"ptrace": {}}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ptrace rules: got %v expected %v", got, expected)
+	}
+}
+
+func TestFilePermSet(t *testing.T) {
+	tt := []struct {
+		props    report.ArtifactProps
+		expected string
+	}{
+		{props: report.ArtifactProps{FilePath: "/etc/hosts", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}}, expected: "r"},
+		{props: report.ArtifactProps{FilePath: "/etc/hosts", ModeText: "-rw-r--r--"}, expected: "r"},
+		{props: report.ArtifactProps{FilePath: "/lib/libc.so.6", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}}, expected: "mr"},
+		{props: report.ArtifactProps{FilePath: "/app/main", ModeText: "-rwxr-xr-x", Flags: map[string]bool{"R": true, "X": true}}, expected: "mrix"},
+		{props: report.ArtifactProps{FilePath: "/var/log/app.log", ModeText: "-rw-r--r--", Flags: map[string]bool{"W": true}}, expected: "rw"},
+		{props: report.ArtifactProps{FilePath: "/var/run/app.pid", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true, "W": true}}, expected: "rwk"},
+		{props: report.ArtifactProps{FilePath: "/etc/hosts", ModeText: "-rw-r--r--", Flags: map[string]bool{}}, expected: "r"},
+	}
+
+	for _, test := range tt {
+		props := test.props
+		if got := filePermSet(&props); got != test.expected {
+			t.Errorf("%s %v: got %q expected %q", props.FilePath, props.Flags, got, test.expected)
+		}
+	}
+}
+
+func TestNewFileRules(t *testing.T) {
+	newFiles := map[string]*report.FileInfo{
+		"/tmp/tmp.x1y2z3":  {WriteCount: 1},
+		"/tmp/cache/a.bin": {WriteCount: 1},
+		"/run/app.pid":     {WriteCount: 1},
+		"/app/data/[1].db": {WriteCount: 1},
+		"/app/ignored":     nil,
+	}
+
+	expected := []appArmorFileRule{
+		{FilePath: `/app/data/\[1\].db`, PermSet: "rw", Owner: true},
+		{FilePath: "/run/app.pid", PermSet: "rwk", Owner: true},
+		{FilePath: "/tmp/**", PermSet: "rwk", Owner: true},
+	}
+
+	if got := collapseFileRules(newFileRules(newFiles)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected rules:\ngot      %+v\nexpected %+v", got, expected)
+	}
+}
+
+func testContainerReport() *report.ContainerReport {
+	creport := &report.ContainerReport{}
+	creport.Image.Files = []*report.ArtifactProps{
+		{FilePath: "/app", ModeText: "drwxr-xr-x", Flags: map[string]bool{"R": true}},
+		{FilePath: "/app/main", ModeText: "-rwxr-xr-x", Flags: map[string]bool{"R": true, "X": true}},
+		{FilePath: "/app/conf/a.yaml", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}},
+		{FilePath: "/app/conf/b.yaml", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}},
+		{FilePath: "/app/conf/c.yaml", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}},
+		{FilePath: "/app/conf/d.yaml", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}},
+		{FilePath: "/app/static/my file (1),#.txt", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}},
+		{FilePath: "/app/static/{x}[y]*.css", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}},
+		{FilePath: "/lib/x86_64-linux-gnu/libc.so.6", ModeText: "-rw-r--r--", Flags: map[string]bool{"R": true}},
+		{FilePath: "/lib64/ld-linux-x86-64.so.2", ModeText: "Lrwxrwxrwx", Flags: map[string]bool{"R": true}},
+		{FilePath: "/var/log/app.log", ModeText: "-rw-r--r--", Flags: map[string]bool{"W": true}},
+		{FilePath: "/var/run/app.pid", ModeText: "-rw-r--r--", Flags: map[string]bool{"W": true}},
+		{FilePath: "/tmp/tmp.abc", ModeText: "-rw-------", Flags: map[string]bool{"W": true}},
+	}
+
+	creport.Monitors.Fan = &report.FanMonitorReport{
+		NewFiles: map[string]*report.FileInfo{"/tmp/tmp.abc": {WriteCount: 1}},
+	}
+
+	creport.Monitors.Pt = &report.PtMonitorReport{
+		SyscallStats: map[string]report.SyscallStatInfo{
+			"41":  {Name: "socket"},
+			"49":  {Name: "bind"},
+			"62":  {Name: "kill"},
+			"105": {Name: "setuid"},
+		},
+	}
+
+	creport.Monitors.Pt.AddSyscallArgs(41, "socket", []uint{0, 1}, []uint64{2, 1 | 0x80000})
+	creport.Monitors.Pt.AddCapabilityUse("NET_BIND_SERVICE", "bind")
+	creport.Monitors.Pt.AddCapabilityUse("SETUID", "setuid")
+	return creport
+}
+
+func TestGenProfileData(t *testing.T) {
+	data := genProfileData(testContainerReport(), "app-apparmor-profile")
+
+	if expected := []string{"capability net_bind_service", "capability setuid"}; !reflect.DeepEqual(data.CapabilityRules, expected) {
+		t.Errorf("capability rules: got %v expected %v", data.CapabilityRules, expected)
+	}
+
+	if expected := []string{"network inet stream"}; !reflect.DeepEqual(data.NetworkRules, expected) {
+		t.Errorf("network rules: got %v expected %v", data.NetworkRules, expected)
+	}
+
+	var fileRules []string
+	for _, rules := range [][]appArmorFileRule{data.ExeFileRules, data.WriteFileRules, data.OwnerFileRules, data.ReadFileRules} {
+		for _, rule := range rules {
+			fileRules = append(fileRules, rule.String())
+		}
+	}
+
+	expected := []string{
+		"/app/main mrix",
+		"/var/log/app.log rw",
+		"/var/run/app.pid rwk",
+		"owner /tmp/** rwk",
+		"/app/ r",
+		"/app/conf/* r",
+		`/app/static/\{x\}\[y\]\*.css r`,
+		`"/app/static/my file (1),#.txt" r`,
+		"/lib/x86_64-linux-gnu/libc.so.6 mr",
+	}
+
+	if !reflect.DeepEqual(fileRules, expected) {
+		t.Errorf("unexpected file rules:\ngot      %q\nexpected %q", fileRules, expected)
+	}
+}
+
+func TestRenderProfile(t *testing.T) {
+	reports := map[string]*report.ContainerReport{
+		"full":   testContainerReport(),
+		"no pt":  {Image: testContainerReport().Image},
+		"no fan": {Image: testContainerReport().Image, Monitors: report.MonitorReports{Pt: testContainerReport().Monitors.Pt}},
+		"empty":  {},
+	}
+
+	for desc, creport := range reports {
+		profile, err := renderProfile(genProfileData(creport, "app-apparmor-profile"))
+		if err != nil {
+			t.Errorf("%s: %v", desc, err)
+			continue
+		}
+
+		profiles, err := Parse(profile)
+		if err != nil {
+			t.Errorf("%s: %v", desc, err)
+			continue
+		}
+
+		if len(profiles) != 1 || profiles[0].Name != "app-apparmor-profile" {
+			t.Errorf("%s: unexpected profiles: %+v", desc, profiles)
+		}
+
+		if creport.Monitors.Pt == nil && !strings.Contains(string(profile), "\n  network,\n") {
+			t.Errorf("%s: expected the allow-all network rule:\n%s", desc, profile)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package capabilities
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+// CapAll is the capability name for all capabilities
+const CapAll = "ALL"
+
+// Recommendations are the container capability settings for the capabilities used by the application
+type Recommendations struct {
+	//Capabilities is the minimal capability set
+	Capabilities []string
+	//DockerRunFlags are the 'docker run' capability flags
+	DockerRunFlags string
+	//SecurityContext is the Kubernetes container 'securityContext' snippet (YAML)
+	SecurityContext string
+}
+
+// SecurityContext is the capability part of the Kubernetes container 'securityContext'
+type SecurityContext struct {
+	Capabilities *CapabilitySettings `json:"capabilities"`
+}
+
+// CapabilitySettings are the Kubernetes capabilities to add and to drop
+type CapabilitySettings struct {
+	Add  []string `json:"add,omitempty"`
+	Drop []string `json:"drop"`
+}
+
+type securityContextSnippet struct {
+	SecurityContext *SecurityContext `json:"securityContext"`
+}
+
+// NewSecurityContext creates the Kubernetes 'securityContext' capability settings
+// (all capabilities are dropped and only the used capabilities are added back)
+func NewSecurityContext(caps []string) *SecurityContext {
+	return &SecurityContext{
+		Capabilities: &CapabilitySettings{
+			Add:  caps,
+			Drop: []string{CapAll},
+		},
+	}
+}
+
+// DockerRunFlags creates the 'docker run' flags to drop all capabilities except the used capabilities
+func DockerRunFlags(caps []string) string {
+	flags := []string{"--cap-drop", CapAll}
+	for _, name := range caps {
+		flags = append(flags, "--cap-add", name)
+	}
+
+	return strings.Join(flags, " ")
+}
+
+// GenRecommendations creates the capability settings for the capabilities
+// used by the application and saves them in the recommendations file
+func GenRecommendations(artifactLocation string, fileName string) (*Recommendations, error) {
+	containerReportFilePath := filepath.Join(artifactLocation, report.DefaultContainerReportFileName)
+
+	if _, err := os.Stat(containerReportFilePath); err != nil {
+		return nil, err
+	}
+	reportFile, err := os.Open(containerReportFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reportFile.Close()
+
+	var creport report.ContainerReport
+	if err = json.NewDecoder(reportFile).Decode(&creport); err != nil {
+		return nil, err
+	}
+
+	if creport.Monitors.Pt == nil {
+		log.Debug("docker-slim: capabilities - no syscall monitor data")
+		return nil, nil
+	}
+
+	caps := creport.Monitors.Pt.CapabilitySet()
+	snippet, err := yaml.Marshal(&securityContextSnippet{SecurityContext: NewSecurityContext(caps)})
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := &Recommendations{
+		Capabilities:    caps,
+		DockerRunFlags:  DockerRunFlags(caps),
+		SecurityContext: string(snippet),
+	}
+
+	var out bytes.Buffer
+	if len(caps) > 0 {
+		out.WriteString(fmt.Sprintf("# capabilities used by the application: %s\n", strings.Join(caps, ", ")))
+		for _, name := range caps {
+			syscalls := append([]string{}, creport.Monitors.Pt.Capabilities[name].Syscalls...)
+			sort.Strings(syscalls)
+			out.WriteString(fmt.Sprintf("#   %s - %s\n", name, strings.Join(syscalls, ", ")))
+		}
+	} else {
+		out.WriteString("# no capabilities used by the application\n")
+	}
+
+	out.WriteString(fmt.Sprintf("# docker run %s ...\n", recommendations.DockerRunFlags))
+	out.WriteString(recommendations.SecurityContext)
+
+	filePath := filepath.Join(artifactLocation, fileName)
+	log.Debug("docker-slim: saving capability recommendations to ", filePath)
+	if err := ioutil.WriteFile(filePath, out.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
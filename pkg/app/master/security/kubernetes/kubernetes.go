@@ -0,0 +1,326 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/app/master/security/capabilities"
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+// Namespace is the namespace for the generated resources
+// (the operator seccomp profiles are installed in the 'operator/<namespace>/' directory)
+const Namespace = "default"
+
+// Artifact file name patterns (the resource name is the prefix)
+const (
+	seccompProfileFileNamePat  = "%s-k8s-seccomp-profile.yaml"
+	securityContextFileNamePat = "%s-k8s-security-context.yaml"
+	podFileNamePat             = "%s-k8s-pod.yaml"
+	defaultResourceName        = "app"
+	maxResourceNameLen         = 63
+)
+
+// runtimeDirs are the container runtime file systems (not on the root file system)
+var runtimeDirs = []string{"/dev", "/proc", "/sys"}
+
+// Options are the build/profile results used to create the Kubernetes artifacts
+type Options struct {
+	//Name is the resource name base (usually the target image name)
+	Name string
+	//Image is the image for the sample Pod (the minified image if it's available)
+	Image string
+	//User is the user the target application runs as
+	User string
+	//Volumes are the volumes declared in the target image
+	Volumes []string
+	//ExposedPorts are the ports exposed in the target image ('port/protocol')
+	ExposedPorts []string
+	//SeccompProfileName is the generated Docker seccomp profile file name
+	SeccompProfileName string
+	//FilesDirName is the artifacts directory with the image files (to resolve the user name)
+	FilesDirName string
+}
+
+// ObjectMeta is the Kubernetes resource metadata
+type ObjectMeta struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// SecurityContext is the Kubernetes container 'securityContext'
+type SecurityContext struct {
+	RunAsUser              *int64                           `json:"runAsUser,omitempty"`
+	RunAsGroup             *int64                           `json:"runAsGroup,omitempty"`
+	RunAsNonRoot           *bool                            `json:"runAsNonRoot,omitempty"`
+	ReadOnlyRootFilesystem *bool                            `json:"readOnlyRootFilesystem"`
+	Capabilities           *capabilities.CapabilitySettings `json:"capabilities,omitempty"`
+	SeccompProfile         *SeccompProfileRef               `json:"seccompProfile,omitempty"`
+}
+
+// SeccompProfileRef references the seccomp profile installed on the node
+type SeccompProfileRef struct {
+	Type             string `json:"type"`
+	LocalhostProfile string `json:"localhostProfile,omitempty"`
+}
+
+// Pod is the sample Kubernetes Pod
+type Pod struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       PodSpec    `json:"spec"`
+}
+
+// PodSpec is the sample Pod spec
+type PodSpec struct {
+	Containers []*Container `json:"containers"`
+	Volumes    []*Volume    `json:"volumes,omitempty"`
+}
+
+// Container is the sample Pod container
+type Container struct {
+	Name            string           `json:"name"`
+	Image           string           `json:"image"`
+	Ports           []*ContainerPort `json:"ports,omitempty"`
+	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
+	VolumeMounts    []*VolumeMount   `json:"volumeMounts,omitempty"`
+}
+
+// ContainerPort is the sample Pod container port
+type ContainerPort struct {
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+// Volume is the sample Pod volume (for the volumes declared in the image)
+type Volume struct {
+	Name     string    `json:"name"`
+	EmptyDir *struct{} `json:"emptyDir"`
+}
+
+// VolumeMount is the sample Pod volume mount
+type VolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+}
+
+type securityContextSnippet struct {
+	SecurityContext *SecurityContext `json:"securityContext"`
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ResourceName creates a valid Kubernetes resource name (DNS label) from the image name
+func ResourceName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > maxResourceNameLen {
+		name = name[:maxResourceNameLen]
+	}
+
+	name = strings.Trim(name, "-")
+	if name == "" {
+		return defaultResourceName
+	}
+
+	return name
+}
+
+// GenArtifacts creates the 'SeccompProfile' resource, the container 'securityContext' snippet
+// and the sample Pod manifest (returns the artifact file names)
+func GenArtifacts(artifactLocation string, options *Options) ([]string, error) {
+	containerReportFilePath := filepath.Join(artifactLocation, report.DefaultContainerReportFileName)
+
+	if _, err := os.Stat(containerReportFilePath); err != nil {
+		return nil, err
+	}
+	reportFile, err := os.Open(containerReportFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reportFile.Close()
+
+	var creport report.ContainerReport
+	if err = json.NewDecoder(reportFile).Decode(&creport); err != nil {
+		return nil, err
+	}
+
+	name := ResourceName(options.Name)
+	var artifacts []string
+
+	securityContext := newSecurityContext(artifactLocation, &creport, options)
+	if options.SeccompProfileName != "" {
+		seccompFileName := fmt.Sprintf(seccompProfileFileNamePat, name)
+		if err := genSeccompProfile(artifactLocation, options.SeccompProfileName, name, seccompFileName); err != nil {
+			return nil, err
+		}
+
+		artifacts = append(artifacts, seccompFileName)
+		securityContext.SeccompProfile = &SeccompProfileRef{
+			Type:             "Localhost",
+			LocalhostProfile: fmt.Sprintf("operator/%s/%s.json", Namespace, name),
+		}
+	}
+
+	snippet, err := yaml.Marshal(&securityContextSnippet{SecurityContext: securityContext})
+	if err != nil {
+		return nil, err
+	}
+
+	securityContextFileName := fmt.Sprintf(securityContextFileNamePat, name)
+	if err := ioutil.WriteFile(filepath.Join(artifactLocation, securityContextFileName), snippet, 0644); err != nil {
+		return nil, err
+	}
+
+	artifacts = append(artifacts, securityContextFileName)
+
+	pod, err := yaml.Marshal(newPod(name, options, securityContext))
+	if err != nil {
+		return nil, err
+	}
+
+	podFileName := fmt.Sprintf(podFileNamePat, name)
+	if err := ioutil.WriteFile(filepath.Join(artifactLocation, podFileName), pod, 0644); err != nil {
+		return nil, err
+	}
+
+	artifacts = append(artifacts, podFileName)
+	return artifacts, nil
+}
+
+func newSecurityContext(artifactLocation string, creport *report.ContainerReport, options *Options) *SecurityContext {
+	securityContext := &SecurityContext{}
+
+	filesLocation := filepath.Join(artifactLocation, options.FilesDirName)
+	securityContext.RunAsUser, securityContext.RunAsGroup = resolveIdentity(options.User,
+		filepath.Join(filesLocation, "etc/passwd"),
+		filepath.Join(filesLocation, "etc/group"))
+	if securityContext.RunAsUser == nil {
+		log.Debugf("docker-slim: kubernetes - could not resolve the user (%s)", options.User)
+	} else if *securityContext.RunAsUser != 0 {
+		nonRoot := true
+		securityContext.RunAsNonRoot = &nonRoot
+	}
+
+	writes := rootFSWrites(creport, options.Volumes)
+	readOnly := len(writes) == 0
+	if !readOnly {
+		log.Debugf("docker-slim: kubernetes - root file system writes: %s", strings.Join(writes, ","))
+	}
+
+	securityContext.ReadOnlyRootFilesystem = &readOnly
+
+	if creport.Monitors.Pt != nil {
+		securityContext.Capabilities = capabilities.NewSecurityContext(creport.Monitors.Pt.CapabilitySet()).Capabilities
+	}
+
+	return securityContext
+}
+
+func underDir(filePath string, dirs []string) bool {
+	for _, dir := range dirs {
+		dir = strings.TrimSuffix(dir, "/")
+		if filePath == dir || strings.HasPrefix(filePath, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rootFSWrites returns the files written outside of the declared volumes
+func rootFSWrites(creport *report.ContainerReport, volumes []string) []string {
+	written := map[string]struct{}{}
+	if creport.Monitors.Fan != nil {
+		for _, files := range creport.Monitors.Fan.ProcessFiles {
+			for filePath, info := range files {
+				if info != nil && info.WriteCount > 0 {
+					written[filePath] = struct{}{}
+				}
+			}
+		}
+
+		for filePath := range creport.Monitors.Fan.NewFiles {
+			written[filePath] = struct{}{}
+		}
+	}
+
+	for _, info := range creport.Image.Files {
+		if info != nil && info.Flags["W"] {
+			written[info.FilePath] = struct{}{}
+		}
+	}
+
+	var writes []string
+	for filePath := range written {
+		if underDir(filePath, runtimeDirs) || underDir(filePath, volumes) {
+			continue
+		}
+
+		writes = append(writes, filePath)
+	}
+
+	sort.Strings(writes)
+	return writes
+}
+
+func newPod(name string, options *Options, securityContext *SecurityContext) *Pod {
+	container := &Container{
+		Name:            name,
+		Image:           options.Image,
+		SecurityContext: securityContext,
+	}
+
+	for _, port := range options.ExposedPorts {
+		parts := strings.SplitN(port, "/", 2)
+		num, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+
+		protocol := "TCP"
+		if len(parts) > 1 {
+			protocol = strings.ToUpper(parts[1])
+		}
+
+		container.Ports = append(container.Ports, &ContainerPort{ContainerPort: num, Protocol: protocol})
+	}
+
+	sort.Slice(container.Ports, func(i, j int) bool {
+		return container.Ports[i].ContainerPort < container.Ports[j].ContainerPort
+	})
+
+	pod := &Pod{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Metadata: ObjectMeta{
+			Name:      name,
+			Namespace: Namespace,
+			Labels:    map[string]string{"app": name},
+		},
+		Spec: PodSpec{
+			Containers: []*Container{container},
+		},
+	}
+
+	volumes := append([]string{}, options.Volumes...)
+	sort.Strings(volumes)
+	for idx, mountPath := range volumes {
+		volumeName := fmt.Sprintf("volume-%d", idx)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, &Volume{Name: volumeName, EmptyDir: &struct{}{}})
+		container.VolumeMounts = append(container.VolumeMounts, &VolumeMount{Name: volumeName, MountPath: mountPath})
+	}
+
+	return pod
+}
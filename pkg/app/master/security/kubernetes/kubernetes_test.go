@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+func TestRootFSWrites(t *testing.T) {
+	creport := &report.ContainerReport{
+		Monitors: report.MonitorReports{
+			Fan: &report.FanMonitorReport{
+				ProcessFiles: map[string]map[string]*report.FileInfo{
+					"1": {
+						"/etc/hosts":        {ReadCount: 1},
+						"/var/log/app.log":  {WriteCount: 2},
+						"/data/db/file":     {WriteCount: 1},
+						"/dev/null":         {WriteCount: 3},
+						"/proc/self/oom":    {WriteCount: 1},
+						"/sys/fs/cgroup/io": {WriteCount: 1},
+						"/devices/file":     {WriteCount: 1},
+						"/tmp/nil":          nil,
+					},
+				},
+				NewFiles: map[string]*report.FileInfo{
+					"/tmp/app.sock": {},
+					"/data":         {},
+				},
+			},
+		},
+		Image: report.ImageReport{
+			Files: []*report.ArtifactProps{
+				{FilePath: "/app/cache/index", Flags: map[string]bool{"R": true, "W": true}},
+				{FilePath: "/app/bin/app", Flags: map[string]bool{"R": true, "X": true}},
+				nil,
+			},
+		},
+	}
+
+	tt := []struct {
+		desc     string
+		volumes  []string
+		expected []string
+	}{
+		{
+			desc:     "no volumes",
+			expected: []string{"/app/cache/index", "/data", "/data/db/file", "/devices/file", "/tmp/app.sock", "/var/log/app.log"},
+		},
+		{
+			desc:     "volumes",
+			volumes:  []string{"/data/", "/tmp", "/var/log/app"},
+			expected: []string{"/app/cache/index", "/devices/file", "/var/log/app.log"},
+		},
+	}
+
+	for _, test := range tt {
+		if got := rootFSWrites(creport, test.volumes); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s:\ngot      %q\nexpected %q", test.desc, got, test.expected)
+		}
+	}
+
+	if got := rootFSWrites(&report.ContainerReport{}, nil); got != nil {
+		t.Errorf("expected no writes without the monitor reports (got %q)", got)
+	}
+}
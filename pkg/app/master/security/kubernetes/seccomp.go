@@ -0,0 +1,123 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/docker-slim/docker-slim/pkg/third_party/opencontainers/specs"
+)
+
+// Security Profiles Operator resource info
+const (
+	SeccompProfileAPIVersion = "security-profiles-operator.x-k8s.io/v1beta1"
+	SeccompProfileKind       = "SeccompProfile"
+)
+
+// SeccompProfile is the Security Profiles Operator 'SeccompProfile' resource
+type SeccompProfile struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   ObjectMeta         `json:"metadata"`
+	Spec       SeccompProfileSpec `json:"spec"`
+}
+
+// SeccompProfileSpec is the 'SeccompProfile' spec
+// (the Docker seccomp profile without the Docker specific fields)
+type SeccompProfileSpec struct {
+	DefaultAction specs.Action `json:"defaultAction"`
+	Architectures []specs.Arch `json:"architectures,omitempty"`
+	Syscalls      []*Syscall   `json:"syscalls,omitempty"`
+}
+
+// Syscall is the 'SeccompProfile' syscall rule
+type Syscall struct {
+	Names    []string     `json:"names"`
+	Action   specs.Action `json:"action"`
+	ErrnoRet uint         `json:"errnoRet,omitempty"`
+	Args     []*specs.Arg `json:"args,omitempty"`
+}
+
+// newSeccompProfileSpec converts the Docker seccomp profile
+// (the operator profiles have no 'archMap', so all architectures are in the 'architectures' list)
+func newSeccompProfileSpec(profile *specs.Seccomp) SeccompProfileSpec {
+	spec := SeccompProfileSpec{
+		DefaultAction: profile.DefaultAction,
+	}
+
+	known := map[specs.Arch]struct{}{}
+	addArch := func(arch specs.Arch) {
+		if _, found := known[arch]; !found {
+			known[arch] = struct{}{}
+			spec.Architectures = append(spec.Architectures, arch)
+		}
+	}
+
+	for _, arch := range profile.Architectures {
+		addArch(arch)
+	}
+
+	for _, entry := range profile.ArchMap {
+		addArch(entry.Arch)
+		for _, arch := range entry.SubArches {
+			addArch(arch)
+		}
+	}
+
+	for _, rule := range profile.Syscalls {
+		if rule == nil {
+			continue
+		}
+
+		names := rule.Names
+		if len(names) == 0 && rule.Name != "" {
+			names = []string{rule.Name}
+		}
+
+		syscall := &Syscall{
+			Names:  names,
+			Action: rule.Action,
+			Args:   rule.Args,
+		}
+
+		if rule.ErrnoRet != nil {
+			syscall.ErrnoRet = *rule.ErrnoRet
+		}
+
+		spec.Syscalls = append(spec.Syscalls, syscall)
+	}
+
+	return spec
+}
+
+// genSeccompProfile creates the 'SeccompProfile' resource from the generated Docker seccomp profile
+func genSeccompProfile(artifactLocation, seccompProfileName, name, fileName string) error {
+	data, err := ioutil.ReadFile(filepath.Join(artifactLocation, seccompProfileName))
+	if err != nil {
+		return err
+	}
+
+	var profile specs.Seccomp
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return err
+	}
+
+	resource := &SeccompProfile{
+		APIVersion: SeccompProfileAPIVersion,
+		Kind:       SeccompProfileKind,
+		Metadata: ObjectMeta{
+			Name:      name,
+			Namespace: Namespace,
+		},
+		Spec: newSeccompProfileSpec(&profile),
+	}
+
+	out, err := yaml.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(artifactLocation, fileName), out, 0644)
+}
@@ -0,0 +1,55 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/third_party/opencontainers/specs"
+)
+
+func TestNewSeccompProfileSpec(t *testing.T) {
+	errno := uint(38)
+	profile := &specs.Seccomp{
+		DefaultAction: specs.ActErrno,
+		Architectures: []specs.Arch{specs.ArchX86_64},
+		ArchMap: []specs.Architecture{
+			{Arch: specs.ArchX86_64, SubArches: []specs.Arch{specs.ArchX86, specs.ArchX32}},
+			{Arch: specs.ArchAARCH64, SubArches: []specs.Arch{specs.ArchARM}},
+		},
+		Syscalls: []*specs.Syscall{
+			{Names: []string{"read", "write"}, Action: specs.ActAllow},
+			//the old profiles with one syscall name
+			{Name: "exit", Action: specs.ActAllow},
+			{Name: "ignored", Names: []string{"clone"}, Action: specs.ActAllow,
+				Args: []*specs.Arg{{Index: 0, Value: 0x10000000, ValueTwo: 0, Op: specs.OpMaskedEqual}}},
+			nil,
+			{Names: []string{"clone3"}, Action: specs.ActErrno, ErrnoRet: &errno},
+		},
+	}
+
+	spec := newSeccompProfileSpec(profile)
+
+	if spec.DefaultAction != specs.ActErrno {
+		t.Errorf("unexpected default action: %v", spec.DefaultAction)
+	}
+
+	expectedArchs := []specs.Arch{specs.ArchX86_64, specs.ArchX86, specs.ArchX32, specs.ArchAARCH64, specs.ArchARM}
+	if !reflect.DeepEqual(spec.Architectures, expectedArchs) {
+		t.Errorf("unexpected architectures:\ngot      %v\nexpected %v", spec.Architectures, expectedArchs)
+	}
+
+	expectedSyscalls := []*Syscall{
+		{Names: []string{"read", "write"}, Action: specs.ActAllow},
+		{Names: []string{"exit"}, Action: specs.ActAllow},
+		{Names: []string{"clone"}, Action: specs.ActAllow, Args: profile.Syscalls[2].Args},
+		{Names: []string{"clone3"}, Action: specs.ActErrno, ErrnoRet: 38},
+	}
+
+	if !reflect.DeepEqual(spec.Syscalls, expectedSyscalls) {
+		for idx, rule := range spec.Syscalls {
+			t.Logf("%d: %+v", idx, rule)
+		}
+
+		t.Errorf("unexpected syscall rules")
+	}
+}
@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// lookupID finds the numeric ID for the name in a passwd or group file
+// (the file ID is in the third field in both files)
+func lookupID(filePath, name string) (int64, bool) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 || fields[0] != name {
+			continue
+		}
+
+		id, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		return id, true
+	}
+
+	return 0, false
+}
+
+// primaryGroupID finds the primary group ID for the user ID in a passwd file
+func primaryGroupID(passwdPath string, uid int64) (int64, bool) {
+	file, err := os.Open(passwdPath)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ":")
+		if len(fields) < 4 || fields[2] != strconv.FormatInt(uid, 10) {
+			continue
+		}
+
+		gid, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		return gid, true
+	}
+
+	return 0, false
+}
+
+// resolveIdentity resolves the container user ('user', 'uid', 'user:group' or 'uid:gid')
+// to the numeric IDs using the passwd and group files from the image
+// (the IDs are nil if they can't be resolved)
+func resolveIdentity(user, passwdPath, groupPath string) (*int64, *int64) {
+	userName := user
+	groupName := ""
+	if parts := strings.SplitN(user, ":", 2); len(parts) == 2 {
+		userName, groupName = parts[0], parts[1]
+	}
+
+	if userName == "" {
+		userName = "root"
+	}
+
+	var uid, gid *int64
+	if id, err := strconv.ParseInt(userName, 10, 64); err == nil {
+		uid = &id
+	} else if id, found := lookupID(passwdPath, userName); found {
+		uid = &id
+	} else if userName == "root" {
+		id := int64(0)
+		uid = &id
+	}
+
+	switch {
+	case groupName != "":
+		if id, err := strconv.ParseInt(groupName, 10, 64); err == nil {
+			gid = &id
+		} else if id, found := lookupID(groupPath, groupName); found {
+			gid = &id
+		}
+	case uid != nil:
+		if id, found := primaryGroupID(passwdPath, *uid); found {
+			gid = &id
+		}
+	}
+
+	return uid, gid
+}
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+const testPasswd = `root:x:0:0:root:/root:/bin/sh
+# app user
+app:x:1000:1001:app:/home/app:/bin/sh
+nobody:x:65534:65534:nobody:/nonexistent:/usr/sbin/nologin
+`
+
+const testGroup = `root:x:0:
+app:x:1001:
+staff:x:50:app
+`
+
+func TestResolveIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "k8s-users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	passwdPath := filepath.Join(dir, "passwd")
+	groupPath := filepath.Join(dir, "group")
+	if err := ioutil.WriteFile(passwdPath, []byte(testPasswd), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(groupPath, []byte(testGroup), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	id := func(value int64) *int64 {
+		return &value
+	}
+
+	tt := []struct {
+		user       string
+		passwdPath string
+		uid        *int64
+		gid        *int64
+	}{
+		{user: "", passwdPath: passwdPath, uid: id(0), gid: id(0)},
+		{user: "app", passwdPath: passwdPath, uid: id(1000), gid: id(1001)},
+		{user: "1000", passwdPath: passwdPath, uid: id(1000), gid: id(1001)},
+		{user: "app:staff", passwdPath: passwdPath, uid: id(1000), gid: id(50)},
+		{user: "app:2000", passwdPath: passwdPath, uid: id(1000), gid: id(2000)},
+		{user: ":staff", passwdPath: passwdPath, uid: id(0), gid: id(50)},
+		//the users and groups not in the image files
+		{user: "2000", passwdPath: passwdPath, uid: id(2000)},
+		{user: "missing", passwdPath: passwdPath},
+		{user: "app:missing", passwdPath: passwdPath, uid: id(1000)},
+		//the images without the passwd file
+		{user: "", passwdPath: filepath.Join(dir, "none"), uid: id(0)},
+		{user: "app", passwdPath: filepath.Join(dir, "none")},
+	}
+
+	for _, test := range tt {
+		uid, gid := resolveIdentity(test.user, test.passwdPath, groupPath)
+		if !equalID(uid, test.uid) || !equalID(gid, test.gid) {
+			t.Errorf("%q: got %s:%s expected %s:%s", test.user, idText(uid), idText(gid), idText(test.uid), idText(test.gid))
+		}
+	}
+}
+
+func equalID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func idText(id *int64) string {
+	if id == nil {
+		return "nil"
+	}
+
+	return strconv.FormatInt(*id, 10)
+}
@@ -0,0 +1,278 @@
+package seccomp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/system"
+	"github.com/docker-slim/docker-slim/pkg/third_party/opencontainers/specs"
+)
+
+// ArchAll selects all supported architectures
+const ArchAll = "all"
+
+// archNameAliases maps the platform architecture names to the system arch names
+var archNameAliases = map[string]system.ArchName{
+	"amd64":   system.ArchNameAmd64,
+	"x86_64":  system.ArchNameAmd64,
+	"arm64":   system.ArchNameArm64,
+	"aarch64": system.ArchNameArm64,
+	"386":     system.ArchName386,
+	"i386":    system.ArchName386,
+	"arm":     system.ArchNameArm32,
+	"armhf":   system.ArchNameArm32,
+}
+
+// primaryArchs are the architectures with their own archMap entries
+var primaryArchs = []system.ArchName{
+	system.ArchNameAmd64,
+	system.ArchNameArm64,
+}
+
+// subArchs are the sub-architectures for the primary architectures
+// (the 32 bit applications running on the 64 bit kernels)
+var subArchs = map[system.ArchName][]system.ArchName{
+	system.ArchNameAmd64: {system.ArchName386},
+	system.ArchNameArm64: {system.ArchNameArm32},
+}
+
+// x32 uses the x86_64 syscall names (there's no separate syscall table)
+var extraSubArchs = map[system.ArchName][]specs.Arch{
+	system.ArchNameAmd64: {specs.ArchX32},
+}
+
+// ParseArchNames parses the target architecture names for the seccomp profiles
+// (the 'amd64', 'arm64', '386' and 'arm' platform names or 'all')
+func ParseArchNames(names []string) ([]system.ArchName, error) {
+	var archNames []system.ArchName
+	known := map[system.ArchName]struct{}{}
+	add := func(name system.ArchName) {
+		if _, found := known[name]; !found {
+			known[name] = struct{}{}
+			archNames = append(archNames, name)
+		}
+	}
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		if name == ArchAll {
+			for _, archName := range primaryArchs {
+				add(archName)
+			}
+
+			continue
+		}
+
+		archName, found := archNameAliases[name]
+		if !found {
+			return nil, fmt.Errorf("unsupported seccomp architecture - %s", name)
+		}
+
+		add(archName)
+	}
+
+	return archNames, nil
+}
+
+// equivalentCalls are the syscalls used instead of the syscalls
+// that don't exist on some architectures
+// (e.g., 'arm64' has no 'open' and the 32 bit architectures use the '*64' syscalls)
+var equivalentCalls = map[string][]string{
+	"open":            {"openat"},
+	"creat":           {"openat"},
+	"stat":            {"newfstatat", "fstatat64", "stat64", "statx"},
+	"lstat":           {"newfstatat", "fstatat64", "lstat64", "statx"},
+	"fstat":           {"fstat64"},
+	"newfstatat":      {"fstatat64", "statx"},
+	"access":          {"faccessat"},
+	"readlink":        {"readlinkat"},
+	"mkdir":           {"mkdirat"},
+	"mknod":           {"mknodat"},
+	"rmdir":           {"unlinkat"},
+	"unlink":          {"unlinkat"},
+	"rename":          {"renameat", "renameat2"},
+	"link":            {"linkat"},
+	"symlink":         {"symlinkat"},
+	"chmod":           {"fchmodat"},
+	"chown":           {"fchownat", "chown32"},
+	"lchown":          {"fchownat", "lchown32"},
+	"fchown":          {"fchown32"},
+	"utime":           {"utimensat"},
+	"utimes":          {"utimensat"},
+	"futimesat":       {"utimensat"},
+	"getdents":        {"getdents64"},
+	"dup2":            {"dup3"},
+	"pipe":            {"pipe2"},
+	"poll":            {"ppoll"},
+	"select":          {"pselect6", "_newselect"},
+	"epoll_wait":      {"epoll_pwait"},
+	"epoll_create":    {"epoll_create1"},
+	"eventfd":         {"eventfd2"},
+	"signalfd":        {"signalfd4"},
+	"inotify_init":    {"inotify_init1"},
+	"fork":            {"clone"},
+	"vfork":           {"clone"},
+	"pause":           {"rt_sigsuspend"},
+	"alarm":           {"setitimer"},
+	"getpgrp":         {"getpgid"},
+	"time":            {"clock_gettime"},
+	"mmap":            {"mmap2"},
+	"lseek":           {"_llseek"},
+	"fcntl":           {"fcntl64"},
+	"sendfile":        {"sendfile64"},
+	"truncate":        {"truncate64"},
+	"ftruncate":       {"ftruncate64"},
+	"statfs":          {"statfs64"},
+	"fstatfs":         {"fstatfs64"},
+	"getuid":          {"getuid32"},
+	"geteuid":         {"geteuid32"},
+	"getgid":          {"getgid32"},
+	"getegid":         {"getegid32"},
+	"setuid":          {"setuid32"},
+	"setgid":          {"setgid32"},
+	"setreuid":        {"setreuid32"},
+	"setregid":        {"setregid32"},
+	"setresuid":       {"setresuid32"},
+	"setresgid":       {"setresgid32"},
+	"getresuid":       {"getresuid32"},
+	"getresgid":       {"getresgid32"},
+	"getgroups":       {"getgroups32"},
+	"setgroups":       {"setgroups32"},
+	"setfsuid":        {"setfsuid32"},
+	"setfsgid":        {"setfsgid32"},
+	"clock_gettime":   {"clock_gettime64"},
+	"clock_nanosleep": {"clock_nanosleep_time64"},
+	"futex":           {"futex_time64"},
+	"ppoll":           {"ppoll_time64"},
+	"pselect6":        {"pselect6_time64"},
+	"recvmmsg":        {"recvmmsg_time64"},
+	"utimensat":       {"utimensat_time64"},
+	"timerfd_settime": {"timerfd_settime64"},
+	"timerfd_gettime": {"timerfd_gettime64"},
+	"rt_sigtimedwait": {"rt_sigtimedwait_time64"},
+}
+
+// socketCalls are the socket syscalls multiplexed by 'socketcall' on the 32 bit x86 architecture
+// (the 'socketcall' args are in memory, so they can't be filtered)
+var socketCalls = map[string]struct{}{
+	"socket":      {},
+	"socketpair":  {},
+	"bind":        {},
+	"connect":     {},
+	"listen":      {},
+	"accept":      {},
+	"accept4":     {},
+	"getsockname": {},
+	"getpeername": {},
+	"send":        {},
+	"sendto":      {},
+	"recv":        {},
+	"recvfrom":    {},
+	"shutdown":    {},
+	"setsockopt":  {},
+	"getsockopt":  {},
+	"sendmsg":     {},
+	"recvmsg":     {},
+	"sendmmsg":    {},
+	"recvmmsg":    {},
+}
+
+// archSyscalls maps the observed syscall names to the syscall names for the architecture
+// (returns the observed syscalls that don't exist on the architecture and have no equivalent syscalls)
+func archSyscalls(sourceArch, arch system.ArchName, names []string) ([]string, []string) {
+	if arch == sourceArch {
+		return names, nil
+	}
+
+	resolver := system.CallNameResolver(arch)
+	if resolver == nil {
+		return nil, names
+	}
+
+	//the 32 bit libc versions use the '*64' and '*32' syscalls even if the original syscalls exist
+	is32Bit := arch == system.ArchName386 || arch == system.ArchNameArm32
+
+	var archNames []string
+	var missing []string
+	var hasSocketCall bool
+	for _, name := range names {
+		_, found := resolver(name)
+		if found {
+			archNames = append(archNames, name)
+		}
+
+		if !found || is32Bit {
+			for _, equivalent := range equivalentCalls[name] {
+				if _, ok := resolver(equivalent); ok {
+					archNames = append(archNames, equivalent)
+					found = true
+				}
+			}
+		}
+
+		//the 32 bit x86 libc versions use 'socketcall' instead of the socket syscalls
+		if _, ok := socketCalls[name]; ok && arch == system.ArchName386 {
+			if !hasSocketCall {
+				hasSocketCall = true
+				archNames = append(archNames, "socketcall")
+			}
+
+			found = true
+		}
+
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+
+	return archNames, missing
+}
+
+// genArchMap creates the archMap entries for the target architectures
+// (and the syscall names for all architectures and the missing syscalls by architecture)
+func genArchMap(sourceArch system.ArchName, targetArchs []system.ArchName, names []string) ([]specs.Architecture, []string, map[string][]string) {
+	var archMap []specs.Architecture
+	nameSet := map[string]struct{}{}
+	missingByArch := map[string][]string{}
+
+	addArchSyscalls := func(arch system.ArchName) {
+		archNames, missing := archSyscalls(sourceArch, arch, names)
+		for _, name := range archNames {
+			nameSet[name] = struct{}{}
+		}
+
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			missingByArch[string(arch)] = missing
+		}
+	}
+
+	for _, arch := range targetArchs {
+		entry := specs.Architecture{
+			Arch:      archNameToSeccompArch(string(arch)),
+			SubArches: []specs.Arch{},
+		}
+
+		addArchSyscalls(arch)
+		for _, subArch := range subArchs[arch] {
+			entry.SubArches = append(entry.SubArches, archNameToSeccompArch(string(subArch)))
+			addArchSyscalls(subArch)
+		}
+
+		entry.SubArches = append(entry.SubArches, extraSubArchs[arch]...)
+		archMap = append(archMap, entry)
+	}
+
+	var archNames []string
+	for name := range nameSet {
+		archNames = append(archNames, name)
+	}
+
+	sort.Strings(archNames)
+	return archMap, archNames, missingByArch
+}
@@ -0,0 +1,89 @@
+package seccomp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/system"
+	"github.com/docker-slim/docker-slim/pkg/third_party/opencontainers/specs"
+)
+
+func TestArchSyscalls(t *testing.T) {
+	names := []string{"open", "stat", "socket", "connect", "getuid", "epoll_wait", "fork", "arch_prctl", "iopl"}
+
+	tt := []struct {
+		arch     system.ArchName
+		expected []string
+		missing  []string
+	}{
+		{
+			arch:     system.ArchNameAmd64,
+			expected: names,
+		},
+		{
+			arch:     system.ArchNameArm64,
+			expected: []string{"openat", "newfstatat", "statx", "socket", "connect", "getuid", "epoll_pwait", "clone"},
+			missing:  []string{"arch_prctl", "iopl"},
+		},
+		{
+			//the 32 bit x86 libc versions use 'socketcall' and the '*64' and '*32' syscalls
+			arch: system.ArchName386,
+			expected: []string{
+				"open", "openat",
+				"stat", "fstatat64", "stat64", "statx",
+				"socket", "socketcall",
+				"connect",
+				"getuid", "getuid32",
+				"epoll_wait", "epoll_pwait",
+				"fork", "clone",
+				"arch_prctl",
+				"iopl",
+			},
+		},
+		{
+			arch:    "s390x",
+			missing: names,
+		},
+	}
+
+	for _, test := range tt {
+		archNames, missing := archSyscalls(system.ArchNameAmd64, test.arch, names)
+		if !reflect.DeepEqual(archNames, test.expected) {
+			t.Errorf("%s: unexpected syscalls:\ngot      %v\nexpected %v", test.arch, archNames, test.expected)
+		}
+
+		if !reflect.DeepEqual(missing, test.missing) {
+			t.Errorf("%s: unexpected missing syscalls: got %v expected %v", test.arch, missing, test.missing)
+		}
+	}
+}
+
+func TestGenArchMap(t *testing.T) {
+	names := []string{"open", "socket", "arch_prctl"}
+	archMap, archNames, missing := genArchMap(system.ArchNameAmd64,
+		[]system.ArchName{system.ArchNameAmd64, system.ArchNameArm64},
+		names)
+
+	expectedArchMap := []specs.Architecture{
+		{Arch: specs.ArchX86_64, SubArches: []specs.Arch{specs.ArchX86, specs.ArchX32}},
+		{Arch: specs.ArchAARCH64, SubArches: []specs.Arch{specs.ArchARM}},
+	}
+
+	if !reflect.DeepEqual(archMap, expectedArchMap) {
+		t.Errorf("unexpected archMap:\ngot      %+v\nexpected %+v", archMap, expectedArchMap)
+	}
+
+	expectedNames := []string{"arch_prctl", "open", "openat", "socket", "socketcall"}
+	if !reflect.DeepEqual(archNames, expectedNames) {
+		t.Errorf("unexpected syscalls:\ngot      %v\nexpected %v", archNames, expectedNames)
+	}
+
+	expectedMissing := map[string][]string{
+		string(system.ArchNameArm64): {"arch_prctl"},
+		string(system.ArchNameArm32): {"arch_prctl"},
+	}
+
+	if !reflect.DeepEqual(missing, expectedMissing) {
+		t.Errorf("unexpected missing syscalls:\ngot      %v\nexpected %v", missing, expectedMissing)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/docker-slim/docker-slim/pkg/report"
 	"github.com/docker-slim/docker-slim/pkg/system"
@@ -63,32 +65,237 @@ var extraCalls = []string{
 	"getcwd", //safe to add
 }
 
+// cloneNamespaceFlags are the clone flags for the new namespaces
+// (CLONE_NEWNS|CLONE_NEWCGROUP|CLONE_NEWUTS|CLONE_NEWIPC|CLONE_NEWUSER|CLONE_NEWPID|CLONE_NEWNET)
+const cloneNamespaceFlags = 0x7E020000
+
+const (
+	intArgMask        = 0xffffffff //int and unsigned int args (the upper bits may be sign extended)
+	socketTypeArgMask = 0xf        //SOCK_TYPE_MASK (without SOCK_NONBLOCK and SOCK_CLOEXEC)
+)
+
+// enosysErrno is the Linux ENOSYS error number
+const enosysErrno = 38
+
+type argFilter struct {
+	name string //arg name (for the rule comments)
+	mask uint64 //arg value mask
+}
+
+// argFilters are the argument filters for the syscalls with the recorded argument values
+// (keyed by the syscall name and the argument index; each distinct masked value set gets its own allow rule)
+var argFilters = map[string]map[uint]argFilter{
+	"clone":       {0: {name: "flags", mask: cloneNamespaceFlags}},
+	"socket":      {0: {name: "domain", mask: intArgMask}, 1: {name: "type", mask: socketTypeArgMask}},
+	"ioctl":       {1: {name: "request", mask: intArgMask}},
+	"personality": {0: {name: "persona", mask: intArgMask}},
+	"prctl":       {0: {name: "option", mask: intArgMask}},
+}
+
+// argFilterArchs are the architectures with the same argument layout for the filtered syscall args
+// (the argument indexes recorded on one architecture are valid on the other architectures)
+var argFilterArchs = map[system.ArchName]struct{}{
+	system.ArchNameAmd64: {},
+	system.ArchName386:   {},
+	system.ArchNameArm64: {},
+	system.ArchNameArm32: {},
+}
+
+// runtimeArgValues are the argument values used by the container runtime
+// after the seccomp profile is applied (for the syscalls in extraCalls)
+var runtimeArgValues = map[string]*report.SyscallArgsInfo{
+	"prctl": {
+		Name:       "prctl",
+		ArgIndexes: []uint{0},
+		Values: [][]uint64{
+			{1},  //PR_SET_PDEATHSIG
+			{4},  //PR_SET_DUMPABLE
+			{8},  //PR_SET_KEEPCAPS
+			{23}, //PR_CAPBSET_READ
+			{24}, //PR_CAPBSET_DROP
+			{38}, //PR_SET_NO_NEW_PRIVS
+			{47}, //PR_CAP_AMBIENT
+		},
+	},
+}
+
+// genSyscallRules creates the syscall allow rules for the profile architectures
+// (the syscalls with the recorded argument values get the argument filters)
+func genSyscallRules(names []string, syscallArgs map[string]*report.SyscallArgsInfo, archs []system.ArchName) []*specs.Syscall {
+	for _, arch := range archs {
+		if _, found := argFilterArchs[arch]; !found {
+			//the recorded argument indexes may not match the argument layout
+			syscallArgs = nil
+			break
+		}
+	}
+
+	argSets := map[string][]*report.SyscallArgsInfo{}
+	for _, info := range syscallArgs {
+		argSets[info.Name] = append(argSets[info.Name], info)
+	}
+
+	nameSet := map[string]struct{}{}
+	for _, name := range names {
+		nameSet[name] = struct{}{}
+	}
+
+	//'clone3' args can't be filtered (the flags are in a struct),
+	//so 'clone3' fails with ENOSYS and libc falls back to 'clone'
+	//(the 'clone' filter includes the 'clone3' flags)
+	var blockClone3 bool
+	if _, found := nameSet["clone3"]; found && syscallArgs != nil {
+		blockClone3 = true
+		delete(nameSet, "clone3")
+		nameSet["clone"] = struct{}{}
+		for _, info := range argSets["clone3"] {
+			//the recorded 'clone3' value is 'clone_args.flags' (the 'clone' flags arg)
+			argSets["clone"] = append(argSets["clone"], &report.SyscallArgsInfo{
+				Name:       "clone",
+				ArgIndexes: []uint{0},
+				Values:     info.Values,
+			})
+		}
+	}
+
+	allowAll := &specs.Syscall{
+		Action: specs.ActAllow,
+	}
+
+	var argRules []*specs.Syscall
+	var sortedNames []string
+	for name := range nameSet {
+		sortedNames = append(sortedNames, name)
+	}
+
+	sort.Strings(sortedNames)
+	for _, name := range sortedNames {
+		sets := argSets[name]
+		if info, found := runtimeArgValues[name]; found {
+			sets = append(sets, info)
+		}
+
+		filters, found := argFilters[name]
+		//no argument filters if the sensor didn't record the argument values
+		//(or if the recorded arguments don't have filters)
+		if !found || syscallArgs == nil || !hasArgFilters(filters, sets) {
+			allowAll.Names = append(allowAll.Names, name)
+			continue
+		}
+
+		argRules = append(argRules, genArgRules(name, filters, sets)...)
+	}
+
+	var rules []*specs.Syscall
+	if len(allowAll.Names) > 0 {
+		rules = append(rules, allowAll)
+	}
+
+	rules = append(rules, argRules...)
+
+	if blockClone3 {
+		errnoRet := uint(enosysErrno)
+		rules = append(rules, &specs.Syscall{
+			Names:    []string{"clone3"},
+			Action:   specs.ActErrno,
+			ErrnoRet: &errnoRet,
+			Comment:  "clone3 args can't be filtered (libc falls back to clone)",
+		})
+	}
+
+	return rules
+}
+
+// hasArgFilters checks if all recorded arguments have filters
+func hasArgFilters(filters map[uint]argFilter, sets []*report.SyscallArgsInfo) bool {
+	if len(sets) == 0 {
+		return false
+	}
+
+	for _, info := range sets {
+		for _, idx := range info.ArgIndexes {
+			if _, found := filters[idx]; !found {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func genArgRules(name string, filters map[uint]argFilter, sets []*report.SyscallArgsInfo) []*specs.Syscall {
+	var rules []*specs.Syscall
+	ruleKeys := map[string]struct{}{}
+	for _, info := range sets {
+		for _, argValues := range info.Values {
+			if len(argValues) != len(info.ArgIndexes) {
+				continue
+			}
+
+			var args []*specs.Arg
+			var comments []string
+			for idx, value := range argValues {
+				argIndex := info.ArgIndexes[idx]
+				filter := filters[argIndex]
+				masked := value & filter.mask
+				args = append(args, &specs.Arg{
+					Index:    argIndex,
+					Value:    filter.mask,
+					ValueTwo: masked,
+					Op:       specs.OpMaskedEqual,
+				})
+
+				comments = append(comments, fmt.Sprintf("%s=0x%x", filter.name, masked))
+			}
+
+			key := strings.Join(comments, ",")
+			if _, found := ruleKeys[key]; found {
+				continue
+			}
+
+			ruleKeys[key] = struct{}{}
+			rules = append(rules, &specs.Syscall{
+				Names:   []string{name},
+				Action:  specs.ActAllow,
+				Args:    args,
+				Comment: fmt.Sprintf("%s(%s)", name, key),
+			})
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Comment < rules[j].Comment
+	})
+
+	return rules
+}
+
 // GenProfile creates a SecComp profile
-func GenProfile(artifactLocation string, profileName string) error {
+// (the profile is generated for the monitored architecture and the selected target architectures;
+// it returns the observed syscalls that don't exist on the target architectures)
+func GenProfile(artifactLocation string, profileName string, targetArchs []system.ArchName) (map[string][]string, error) {
 	containerReportFilePath := filepath.Join(artifactLocation, report.DefaultContainerReportFileName)
 
 	if _, err := os.Stat(containerReportFilePath); err != nil {
-		return err
+		return nil, err
 	}
 	reportFile, err := os.Open(containerReportFilePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer reportFile.Close()
 
 	var creport report.ContainerReport
 	if err = json.NewDecoder(reportFile).Decode(&creport); err != nil {
-		return err
+		return nil, err
 	}
 
 	profilePath := filepath.Join(artifactLocation, profileName)
 	log.Debug("docker-slim: saving seccomp profile to ", profilePath)
 
+	sourceArch := system.ArchName(creport.Monitors.Pt.ArchName)
 	profile := &specs.Seccomp{
 		DefaultAction: specs.ActErrno,
-		Architectures: []specs.Arch{
-			archNameToSeccompArch(creport.Monitors.Pt.ArchName),
-		},
 	}
 
 	nameResolver := system.CallNameResolver(system.ArchName(creport.Monitors.Pt.ArchName))
@@ -103,25 +310,48 @@ func GenProfile(artifactLocation string, profileName string) error {
 		}
 	}
 
-	scSpec := specs.Syscall{
-		Action: specs.ActAllow,
+	var names []string
+	for _, scInfo := range creport.Monitors.Pt.SyscallStats {
+		names = append(names, scInfo.Name)
 	}
 
-	for _, scInfo := range creport.Monitors.Pt.SyscallStats {
-		scSpec.Names = append(scSpec.Names, scInfo.Name)
+	var missing map[string][]string
+	//the architectures for the syscall rules (including the sub-architectures)
+	ruleArchs := []system.ArchName{sourceArch}
+	if len(targetArchs) == 0 {
+		profile.Architectures = []specs.Arch{archNameToSeccompArch(string(sourceArch))}
+	} else {
+		//Docker and containerd select the archMap entry for the host architecture
+		//(the profile can't have both 'architectures' and 'archMap')
+		archs := []system.ArchName{sourceArch}
+		for _, arch := range targetArchs {
+			if arch != sourceArch {
+				archs = append(archs, arch)
+			}
+		}
+
+		profile.ArchMap, names, missing = genArchMap(sourceArch, archs, names)
+		ruleArchs = nil
+		for _, arch := range archs {
+			ruleArchs = append(ruleArchs, arch)
+			ruleArchs = append(ruleArchs, subArchs[arch]...)
+		}
+		for arch, archMissing := range missing {
+			log.Debugf("docker-slim: seccomp - syscalls missing on %s: %s", arch, strings.Join(archMissing, ","))
+		}
 	}
 
-	profile.Syscalls = append(profile.Syscalls, &scSpec)
+	profile.Syscalls = genSyscallRules(names, creport.Monitors.Pt.SyscallArgs, ruleArchs)
 
 	profileData, err := json.MarshalIndent(profile, "", "  ")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = ioutil.WriteFile(profilePath, profileData, 0644)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return missing, nil
 }
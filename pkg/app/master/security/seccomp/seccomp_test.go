@@ -0,0 +1,202 @@
+package seccomp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/system"
+	"github.com/docker-slim/docker-slim/pkg/third_party/opencontainers/specs"
+)
+
+func ruleComments(rules []*specs.Syscall) []string {
+	var comments []string
+	for _, rule := range rules {
+		if rule.Comment != "" {
+			comments = append(comments, rule.Comment)
+		}
+	}
+
+	return comments
+}
+
+func findRule(rules []*specs.Syscall, comment string) *specs.Syscall {
+	for _, rule := range rules {
+		if rule.Comment == comment {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+func TestGenSyscallRules(t *testing.T) {
+	syscallArgs := map[string]*report.SyscallArgsInfo{
+		"56": {
+			Number:     56,
+			Name:       "clone",
+			ArgIndexes: []uint{0},
+			Values: [][]uint64{
+				{0x3d0f00}, //thread flags (masked to 0)
+				{0x1200011},
+				{0x10000000 | 0x11}, //CLONE_NEWUSER|SIGCHLD
+			},
+		},
+		"435": {
+			Number:     435,
+			Name:       "clone3",
+			ArgIndexes: []uint{0},
+			Values: [][]uint64{
+				{0x20000000 | 0x3d0f00}, //CLONE_NEWPID + thread flags
+			},
+		},
+		"41": {
+			Number:     41,
+			Name:       "socket",
+			ArgIndexes: []uint{0, 1},
+			Values: [][]uint64{
+				{2, 1},
+				{2, 1 | 0x80000}, //SOCK_STREAM|SOCK_CLOEXEC (same rule)
+				{0xffffffff0000000a, 2},
+			},
+		},
+	}
+
+	rules := genSyscallRules([]string{"read", "clone", "clone3", "socket", "prctl"}, syscallArgs,
+		[]system.ArchName{system.ArchNameAmd64, system.ArchName386})
+
+	if len(rules) == 0 || rules[0].Comment != "" || !reflect.DeepEqual(rules[0].Names, []string{"read"}) {
+		t.Fatalf("unexpected allow-all rule: %+v", rules[0])
+	}
+
+	expected := []string{
+		"clone(flags=0x0)",
+		"clone(flags=0x10000000)",
+		"clone(flags=0x20000000)",
+		"prctl(option=0x1)",
+		"prctl(option=0x17)",
+		"prctl(option=0x18)",
+		"prctl(option=0x26)",
+		"prctl(option=0x2f)",
+		"prctl(option=0x4)",
+		"prctl(option=0x8)",
+		"socket(domain=0x2,type=0x1)",
+		"socket(domain=0xa,type=0x2)",
+		"clone3 args can't be filtered (libc falls back to clone)",
+	}
+
+	if got := ruleComments(rules); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected rules:\ngot      %q\nexpected %q", got, expected)
+	}
+
+	clone3 := findRule(rules, "clone3 args can't be filtered (libc falls back to clone)")
+	if clone3 == nil || clone3.Action != specs.ActErrno || clone3.ErrnoRet == nil || *clone3.ErrnoRet != enosysErrno {
+		t.Errorf("unexpected clone3 rule: %+v", clone3)
+	}
+
+	socket := findRule(rules, "socket(domain=0xa,type=0x2)")
+	expectedArgs := []*specs.Arg{
+		{Index: 0, Value: intArgMask, ValueTwo: 0xa, Op: specs.OpMaskedEqual},
+		{Index: 1, Value: socketTypeArgMask, ValueTwo: 0x2, Op: specs.OpMaskedEqual},
+	}
+
+	if socket == nil || !reflect.DeepEqual(socket.Args, expectedArgs) {
+		t.Errorf("unexpected socket rule: %+v", socket)
+	}
+}
+
+func TestGenSyscallRulesNoArgs(t *testing.T) {
+	syscallArgs := map[string]*report.SyscallArgsInfo{
+		"56": {Number: 56, Name: "clone", ArgIndexes: []uint{0}, Values: [][]uint64{{0x11}}},
+	}
+
+	tt := []struct {
+		desc        string
+		syscallArgs map[string]*report.SyscallArgsInfo
+		archs       []system.ArchName
+	}{
+		//the reports without the recorded argument values get the allow-all rule
+		{desc: "no args", archs: []system.ArchName{system.ArchNameAmd64}},
+		//the recorded argument indexes may not be valid for the other architectures
+		{desc: "unknown arch", syscallArgs: syscallArgs, archs: []system.ArchName{system.ArchNameAmd64, "s390x"}},
+	}
+
+	for _, test := range tt {
+		rules := genSyscallRules([]string{"clone3", "clone", "prctl", "read"}, test.syscallArgs, test.archs)
+
+		if len(rules) != 1 {
+			t.Errorf("%s: expected one rule (got %d): %q", test.desc, len(rules), ruleComments(rules))
+			continue
+		}
+
+		expected := []string{"clone", "clone3", "prctl", "read"}
+		if rules[0].Action != specs.ActAllow || len(rules[0].Args) != 0 || !reflect.DeepEqual(rules[0].Names, expected) {
+			t.Errorf("%s: unexpected rule: %+v", test.desc, rules[0])
+		}
+	}
+}
+
+func TestGenArgRules(t *testing.T) {
+	tt := []struct {
+		name     string
+		sets     []*report.SyscallArgsInfo
+		expected []string
+	}{
+		{
+			name: "ioctl",
+			sets: []*report.SyscallArgsInfo{
+				{ArgIndexes: []uint{1}, Values: [][]uint64{{0x5413}, {0x5401}}},
+				{ArgIndexes: []uint{1}, Values: [][]uint64{{0xffffffff00005413}}},
+			},
+			expected: []string{"ioctl(request=0x5401)", "ioctl(request=0x5413)"},
+		},
+		{
+			name: "clone",
+			sets: []*report.SyscallArgsInfo{
+				{ArgIndexes: []uint{0}, Values: [][]uint64{{0x11}, {0x3d0f00}, {0x40000000 | 0x11}}},
+			},
+			expected: []string{"clone(flags=0x0)", "clone(flags=0x40000000)"},
+		},
+		{
+			name: "socket",
+			sets: []*report.SyscallArgsInfo{
+				//the value sets that don't match the indexes are ignored
+				{ArgIndexes: []uint{0, 1}, Values: [][]uint64{{1}, {1, 5}}},
+			},
+			expected: []string{"socket(domain=0x1,type=0x5)"},
+		},
+	}
+
+	for _, test := range tt {
+		rules := genArgRules(test.name, argFilters[test.name], test.sets)
+		if got := ruleComments(rules); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: unexpected rules:\ngot      %q\nexpected %q", test.name, got, test.expected)
+		}
+
+		for _, rule := range rules {
+			if !reflect.DeepEqual(rule.Names, []string{test.name}) || rule.Action != specs.ActAllow {
+				t.Errorf("%s: unexpected rule: %+v", test.name, rule)
+			}
+		}
+	}
+}
+
+func TestHasArgFilters(t *testing.T) {
+	tt := []struct {
+		name     string
+		sets     []*report.SyscallArgsInfo
+		expected bool
+	}{
+		{name: "ioctl", sets: []*report.SyscallArgsInfo{{ArgIndexes: []uint{1}}}, expected: true},
+		//the recorded index doesn't have a filter
+		{name: "ioctl", sets: []*report.SyscallArgsInfo{{ArgIndexes: []uint{0}}}, expected: false},
+		{name: "socket", sets: []*report.SyscallArgsInfo{{ArgIndexes: []uint{0, 1}}, {ArgIndexes: []uint{0, 2}}}, expected: false},
+		{name: "socket", expected: false},
+	}
+
+	for idx, test := range tt {
+		if got := hasArgFilters(argFilters[test.name], test.sets); got != test.expected {
+			t.Errorf("%d/%s: got %v expected %v", idx, test.name, got, test.expected)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/docker-slim/docker-slim/pkg/app"
 	vchecker "github.com/docker-slim/docker-slim/pkg/app/master/version"
 	"github.com/docker-slim/docker-slim/pkg/util/errutil"
 	"github.com/docker-slim/docker-slim/pkg/util/fsutil"
@@ -49,18 +50,18 @@ func Run(doDebug bool, statePath string, inContainer, isDSImage, doShowProgress
 	logger.Debugf("Version Status => %+v", vstatus)
 
 	if vstatus == nil || vstatus.Status != "success" {
-		fmt.Printf("docker-slim[update]: info=status message='version check was not successful'\n")
-		fmt.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
+		app.Printf("docker-slim[update]: info=status message='version check was not successful'\n")
+		app.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
 		return
 	}
 
 	if !vstatus.Outdated {
-		fmt.Printf("docker-slim[update]: info=status message='already using the current version'\n")
-		fmt.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
+		app.Printf("docker-slim[update]: info=status message='already using the current version'\n")
+		app.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
 		return
 	}
 
-	fmt.Printf("docker-slim[update]: info=version local=%s current=%s\n", vinfo.Tag(), vstatus.Current)
+	app.Printf("docker-slim[update]: info=version local=%s current=%s\n", vinfo.Tag(), vstatus.Current)
 
 	blobNameBase, blobNameExt := getReleaseBlobInfo()
 	errutil.FailWhen(blobNameBase == "", "could not discover platform-specific release package name")
@@ -74,19 +75,19 @@ func Run(doDebug bool, statePath string, inContainer, isDSImage, doShowProgress
 
 	if fsutil.Exists(blobPath) {
 		//feature: not removing/replacing the existing release package blob if it's already there
-		fmt.Printf("docker-slim[update]: info=status message='release package already downloaded'\n")
-		fmt.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
+		app.Printf("docker-slim[update]: info=status message='release package already downloaded'\n")
+		app.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
 		return
 	}
 
-	fmt.Println("docker-slim[update]: state=update.download.started")
+	app.Println("docker-slim[update]: state=update.download.started")
 
 	releaseDownloadPath := fmt.Sprintf("%s/%s/%s", downloadEndpoint, vstatus.Current, blobName)
 	logger.Debugf("release download path: %v", releaseDownloadPath)
 
 	if !isGoodDownloadSource(logger, releaseDownloadPath) {
-		fmt.Printf("docker-slim[update]: info=status message='release package download location is not accessible'\n")
-		fmt.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
+		app.Printf("docker-slim[update]: info=status message='release package download location is not accessible'\n")
+		app.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
 		return
 	}
 
@@ -99,31 +100,31 @@ func Run(doDebug bool, statePath string, inContainer, isDSImage, doShowProgress
 	err = downloadRelease(logger, blobPath, releaseDownloadPath, brConstructor)
 	if err != nil {
 		logger.Debugf("error downloading release: %v", err)
-		fmt.Printf("docker-slim[update]: info=status message='error downloading release package'\n")
-		fmt.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
+		app.Printf("docker-slim[update]: info=status message='error downloading release package'\n")
+		app.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
 		return
 	}
 
-	fmt.Println("docker-slim[update]: state=update.download.completed")
+	app.Println("docker-slim[update]: state=update.download.completed")
 
 	if err := unpackRelease(logger, blobPath, releaseDirPath, blobNameBase); err != nil {
 		logger.Debugf("error unpacking release package: %v", err)
-		fmt.Printf("docker-slim[update]: info=status message='error unpacking release package'\n")
-		fmt.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
+		app.Printf("docker-slim[update]: info=status message='error unpacking release package'\n")
+		app.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
 		return
 	}
 
-	fmt.Println("docker-slim[update]: state=update.unpacked")
+	app.Println("docker-slim[update]: state=update.unpacked")
 
 	if err := installRelease(logger, appDirPath, statePath, releaseDirPath); err != nil {
 		logger.Debugf("error installing release: %v", err)
-		fmt.Printf("docker-slim[update]: info=status message='error installing release'\n")
-		fmt.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
+		app.Printf("docker-slim[update]: info=status message='error installing release'\n")
+		app.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
 		return
 	}
 
-	fmt.Println("docker-slim[update]: state=update.installed")
-	fmt.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
+	app.Println("docker-slim[update]: state=update.installed")
+	app.Printf("docker-slim[update]: state=exited version=%s\n", vinfo.Current())
 }
 
 func getReleaseBlobInfo() (base string, ext string) {
@@ -43,8 +43,8 @@ func PrintCheckVersion(
 	if info != nil && info.Status == "success" && info.Outdated {
 		msg := "Your version of DockerSlim is out of date! Use the \"update\" command or download the new version from https://dockersl.im/downloads.html"
 		if xc == nil {
-			fmt.Printf("%s info=version status=OUTDATED local=%s current=%s\n", printPrefix, v.Tag(), info.Current)
-			fmt.Printf("%s info=message message='%s'\n", printPrefix, msg)
+			app.Printf("%s info=version status=OUTDATED local=%s current=%s\n", printPrefix, v.Tag(), info.Current)
+			app.Printf("%s info=message message='%s'\n", printPrefix, msg)
 		} else {
 			xc.Out.Info("version",
 				app.OutVars{
@@ -71,8 +71,30 @@ func GetCheckVersionVerdict(info *CheckVersionInfo) string {
 }
 
 // Print shows the master app version information
-func Print(printPrefix string, logger *log.Entry, client *docker.Client, checkVersion, inContainer, isDSImage bool) {
-	fmt.Printf("%s info=app version='%s' container=%v dsimage=%v\n", printPrefix, v.Current(), inContainer, isDSImage)
+// (in the 'json' console output mode the information is printed as the console events)
+func Print(xc *app.ExecutionContext, printPrefix string, logger *log.Entry, client *docker.Client, checkVersion, inContainer, isDSImage bool) {
+	jsonOutput := xc != nil && app.IsJSONConsoleOutput()
+	printInfo := func(infoType string, fields app.OutVars, text string) {
+		if jsonOutput {
+			xc.Out.Info(infoType, fields)
+			return
+		}
+
+		fmt.Printf("%s info=%s %s\n", printPrefix, infoType, text)
+	}
+
+	printError := func(errType, msg string) {
+		if jsonOutput {
+			xc.Out.Error(errType, msg)
+			return
+		}
+
+		fmt.Printf("%s error='%s'\n", printPrefix, msg)
+	}
+
+	printInfo("app",
+		app.OutVars{"version": v.Current(), "container": inContainer, "dsimage": isDSImage},
+		fmt.Sprintf("version='%s' container=%v dsimage=%v", v.Current(), inContainer, isDSImage))
 	if checkVersion {
 		vinfo := Check(inContainer, isDSImage)
 		outdated := "unknown"
@@ -81,47 +103,91 @@ func Print(printPrefix string, logger *log.Entry, client *docker.Client, checkVe
 			outdated = fmt.Sprintf("%v", vinfo.Outdated)
 			current = vinfo.Current
 		}
-		fmt.Printf("%s info=app outdated=%v current=%v verdict='%v'\n",
-			printPrefix, outdated, current, GetCheckVersionVerdict(vinfo))
+
+		verdict := GetCheckVersionVerdict(vinfo)
+		printInfo("app",
+			app.OutVars{"outdated": outdated, "current": current, "verdict": verdict},
+			fmt.Sprintf("outdated=%v current=%v verdict='%v'", outdated, current, verdict))
 	}
 
-	fmt.Printf("%s info=app location='%v'\n", printPrefix, fsutil.ExeDir())
+	printInfo("app",
+		app.OutVars{"location": fsutil.ExeDir()},
+		fmt.Sprintf("location='%v'", fsutil.ExeDir()))
 
 	hostInfo := system.GetSystemInfo()
-	fmt.Printf("%s info=host osname='%v'\n", printPrefix, hostInfo.Distro.DisplayName)
-	fmt.Printf("%s info=host osbuild=%v\n", printPrefix, hostInfo.OsBuild)
-	fmt.Printf("%s info=host version='%v'\n", printPrefix, hostInfo.Version)
-	fmt.Printf("%s info=host release=%v\n", printPrefix, hostInfo.Release)
-	fmt.Printf("%s info=host sysname=%v\n", printPrefix, hostInfo.Sysname)
-
-	if client != nil {
-		info, err := client.Info()
-		if err != nil {
-			fmt.Printf("%s error='error getting docker info'\n", printPrefix)
-			logger.Debugf("Error getting docker info => %v", err)
-			return
+	if jsonOutput {
+		xc.Out.Info("host",
+			app.OutVars{
+				"osname":  hostInfo.Distro.DisplayName,
+				"osbuild": hostInfo.OsBuild,
+				"version": hostInfo.Version,
+				"release": hostInfo.Release,
+				"sysname": hostInfo.Sysname,
+			})
+	} else {
+		fmt.Printf("%s info=host osname='%v'\n", printPrefix, hostInfo.Distro.DisplayName)
+		fmt.Printf("%s info=host osbuild=%v\n", printPrefix, hostInfo.OsBuild)
+		fmt.Printf("%s info=host version='%v'\n", printPrefix, hostInfo.Version)
+		fmt.Printf("%s info=host release=%v\n", printPrefix, hostInfo.Release)
+		fmt.Printf("%s info=host sysname=%v\n", printPrefix, hostInfo.Sysname)
+	}
+
+	if client == nil {
+		if jsonOutput {
+			xc.Out.Info("no.docker.client")
+		} else {
+			fmt.Printf("%s info=no.docker.client\n", printPrefix)
 		}
 
+		return
+	}
+
+	info, err := client.Info()
+	if err != nil {
+		printError("docker.info", "error getting docker info")
+		logger.Debugf("Error getting docker info => %v", err)
+		return
+	}
+
+	if jsonOutput {
+		xc.Out.Info("docker",
+			app.OutVars{
+				"name":             info.Name,
+				"kernel_version":   info.KernelVersion,
+				"operating_system": info.OperatingSystem,
+				"ostype":           info.OSType,
+				"server_version":   info.ServerVersion,
+				"architecture":     info.Architecture,
+			})
+	} else {
 		fmt.Printf("%s info=docker name=%v\n", printPrefix, info.Name)
 		fmt.Printf("%s info=docker kernel_version=%v\n", printPrefix, info.KernelVersion)
 		fmt.Printf("%s info=docker operating_system=%v\n", printPrefix, info.OperatingSystem)
 		fmt.Printf("%s info=docker ostype=%v\n", printPrefix, info.OSType)
 		fmt.Printf("%s info=docker server_version=%v\n", printPrefix, info.ServerVersion)
 		fmt.Printf("%s info=docker architecture=%v\n", printPrefix, info.Architecture)
+	}
 
-		ver, err := client.Version()
-		if err != nil {
-			fmt.Printf("%s error='error getting docker client version'\n", printPrefix)
-			logger.Debugf("Error getting docker client version => %v", err)
-			return
-		}
+	ver, err := client.Version()
+	if err != nil {
+		printError("docker.client.version", "error getting docker client version")
+		logger.Debugf("Error getting docker client version => %v", err)
+		return
+	}
 
+	if jsonOutput {
+		xc.Out.Info("dclient",
+			app.OutVars{
+				"api_version":     ver.Get("ApiVersion"),
+				"min_api_version": ver.Get("MinAPIVersion"),
+				"build_time":      ver.Get("BuildTime"),
+				"git_commit":      ver.Get("GitCommit"),
+			})
+	} else {
 		fmt.Printf("%s info=dclient api_version=%v\n", printPrefix, ver.Get("ApiVersion"))
 		fmt.Printf("%s info=dclient min_api_version=%v\n", printPrefix, ver.Get("MinAPIVersion"))
 		fmt.Printf("%s info=dclient build_time=%v\n", printPrefix, ver.Get("BuildTime"))
 		fmt.Printf("%s info=dclient git_commit=%v\n", printPrefix, ver.Get("GitCommit"))
-	} else {
-		fmt.Printf("%s info=no.docker.client\n", printPrefix)
 	}
 }
 
@@ -0,0 +1,141 @@
+// +build linux
+
+package app
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/app/sensor/inspectors/appdeps"
+	"github.com/docker-slim/docker-slim/pkg/certdiscover"
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+// appDependencies returns the language level dependencies installed in the detected app stack package directories,
+// the dependencies compiled into the executed Go binaries and the Java libraries in the accessed archive directories
+func (p *artifactStore) appDependencies() []*appdeps.Dependency {
+	var deps []*appdeps.Dependency
+	for _, appStack := range p.appStacks {
+		var pkgDirs []string
+		for dir := range appStack.packageDirs {
+			pkgDirs = append(pkgDirs, dir)
+		}
+
+		sort.Strings(pkgDirs)
+		for _, dir := range pkgDirs {
+			switch appStack.language {
+			case certdiscover.LanguagePython:
+				deps = append(deps, appdeps.PythonPackages(dir)...)
+			case certdiscover.LanguageRuby:
+				deps = append(deps, appdeps.RubyGems(dir)...)
+			case certdiscover.LanguageNode:
+				deps = append(deps, appdeps.NodeModules(dir)...)
+				for _, lockPath := range appdeps.NodePackageLockPaths(dir) {
+					lockDeps, err := appdeps.NodePackageLock(lockPath)
+					if err != nil {
+						continue
+					}
+
+					//the lock file packages that are not installed are also included
+					deps = append(deps, lockDeps...)
+					break
+				}
+			}
+		}
+	}
+
+	archiveDirs := map[string]struct{}{}
+	for _, name := range p.nameList {
+		props := p.rawNames[name]
+		if props == nil || props.FileType != report.FileArtifactType {
+			continue
+		}
+
+		if appdeps.IsJavaArchive(name) {
+			archiveDirs[filepath.Dir(name)] = struct{}{}
+			continue
+		}
+
+		if !props.Flags["X"] {
+			continue
+		}
+
+		goDeps, err := appdeps.GoModules(name)
+		if err != nil {
+			log.Debugf("sensor: appDependencies - no Go build info in '%s' => %v", name, err)
+			continue
+		}
+
+		deps = append(deps, goDeps...)
+	}
+
+	var archiveDirList []string
+	for dir := range archiveDirs {
+		archiveDirList = append(archiveDirList, dir)
+	}
+
+	sort.Strings(archiveDirList)
+	for _, dir := range archiveDirList {
+		//including the other archives in the same directories (e.g., the unused libraries in 'lib')
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.Mode().IsRegular() || !appdeps.IsJavaArchive(entry.Name()) {
+				continue
+			}
+
+			archiveDeps, err := appdeps.JavaArchive(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				log.Debugf("sensor: appDependencies - error reading Java archive '%s' => %v", entry.Name(), err)
+				continue
+			}
+
+			deps = append(deps, archiveDeps...)
+		}
+	}
+
+	return appdeps.Dedupe(deps)
+}
+
+func (p *artifactStore) appDependenciesInfo() []*report.AppDependencyInfo {
+	deps := p.appDependencies()
+	if len(deps) == 0 {
+		return nil
+	}
+
+	accessed := map[string]struct{}{}
+	for name := range p.rawNames {
+		accessed[name] = struct{}{}
+	}
+
+	accessedSorted := make([]string, len(p.nameList))
+	copy(accessedSorted, p.nameList)
+	sort.Strings(accessedSorted)
+
+	var result []*report.AppDependencyInfo
+	for _, dep := range deps {
+		result = append(result, &report.AppDependencyInfo{
+			Language: dep.Language,
+			Name:     dep.Name,
+			Version:  dep.Version,
+			Path:     dep.Path,
+			Runtime:  dep.Touched(accessed, accessedSorted),
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Language != result[j].Language {
+			return result[i].Language < result[j].Language
+		}
+
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -22,6 +22,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/docker-slim/docker-slim/pkg/app/sensor/detectors/binfile"
+	"github.com/docker-slim/docker-slim/pkg/app/sensor/detectors/filetype"
 	"github.com/docker-slim/docker-slim/pkg/app/sensor/inspectors/sodeps"
 	"github.com/docker-slim/docker-slim/pkg/certdiscover"
 	"github.com/docker-slim/docker-slim/pkg/ipc/command"
@@ -34,7 +35,6 @@ import (
 const (
 	pidFileSuffix          = ".pid"
 	varRunDir              = "/var/run/"
-	fileTypeCmdName        = "file"
 	defaultReportName      = "creport.json"
 	defaultArtifactDirName = "/opt/dockerslim/artifacts"
 	filesDirName           = "files"
@@ -122,22 +122,6 @@ type appStackInfo struct {
 	packageDirs map[string]struct{}
 }
 
-var fileTypeCmd string
-
-func init() {
-	findFileTypeCmd()
-}
-
-func findFileTypeCmd() {
-	fileTypeCmd, err := exec.LookPath(fileTypeCmdName)
-	if err != nil {
-		log.Debugf("findFileTypeCmd - cmd not found: %v", err)
-		return
-	}
-
-	log.Debugf("findFileTypeCmd - cmd found: %v", fileTypeCmd)
-}
-
 func prepareEnv(storeLocation string, cmd *command.StartMonitor) {
 	log.Debug("sensor.app.prepareEnv()")
 
@@ -146,6 +130,13 @@ func prepareEnv(storeLocation string, cmd *command.StartMonitor) {
 	err := os.MkdirAll(dstRootPath, 0777)
 	errutil.FailOn(err)
 
+	//the target app inherits the sensor environment
+	//(its library search path is used to find the shared library dependencies)
+	if libraryPath := os.Getenv(sodeps.EnvLibraryPath); libraryPath != "" {
+		log.Debugf("sensor.app.prepareEnv - library path - '%s'", libraryPath)
+		sodeps.SetLibraryPath(libraryPath)
+	}
+
 	if cmd != nil && len(cmd.Preserves) > 0 {
 		log.Debugf("sensor.app.prepareEnv(): preserving paths - %d", len(cmd.Preserves))
 
@@ -222,6 +213,8 @@ type artifactStore struct {
 	cmd           *command.StartMonitor
 	appStacks     map[string]*appStackInfo
 	origPaths     map[string]interface{}
+	provenance    map[string][]*report.Provenance
+	extraNames    map[string]struct{}
 }
 
 func newArtifactStore(
@@ -246,11 +239,95 @@ func newArtifactStore(
 		cmd:           cmd,
 		appStacks:     map[string]*appStackInfo{},
 		origPaths:     origPaths,
+		provenance:    map[string][]*report.Provenance{},
+		extraNames:    map[string]struct{}{},
 	}
 
 	return store
 }
 
+// keptBy records the reason the artifact is kept in the minified image
+func (p *artifactStore) keptBy(name, rule, source string) {
+	for _, info := range p.provenance[name] {
+		if info.Rule == rule && info.Source == source {
+			return
+		}
+	}
+
+	p.provenance[name] = append(p.provenance[name], &report.Provenance{
+		Rule:   rule,
+		Source: source,
+	})
+}
+
+// keepExtra records the artifact saved outside of the monitored artifact set
+// (so it's included in the container report with the reason it was kept)
+func (p *artifactStore) keepExtra(name, rule, source string) {
+	p.keptBy(name, rule, source)
+	p.extraNames[name] = struct{}{}
+}
+
+// keepExtraDir records the directory and the artifacts in its copy
+// (the copy has only the artifacts left after applying the exclude patterns)
+func (p *artifactStore) keepExtraDir(dirPath, copyPath, rule, source string) {
+	p.keepExtra(dirPath, rule, source)
+	err := filepath.Walk(copyPath, func(fullPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(copyPath, fullPath)
+		if err != nil || relPath == "." {
+			return nil
+		}
+
+		p.keepExtra(filepath.Join(dirPath, relPath), rule, source)
+		return nil
+	})
+
+	if err != nil {
+		log.Debugf("keepExtraDir(%v) - error walking %v => %v", dirPath, copyPath, err)
+	}
+}
+
+// fanotifyProcesses returns the paths of the processes that accessed each file
+func (p *artifactStore) fanotifyProcesses() map[string][]string {
+	fileProcesses := map[string][]string{}
+	for pid, processFileMap := range p.fanMonReport.ProcessFiles {
+		processPath := pid
+		if process, found := p.fanMonReport.Processes[pid]; found && process != nil && process.Path != "" {
+			processPath = process.Path
+		}
+
+		for fileName := range processFileMap {
+			fileProcesses[fileName] = append(fileProcesses[fileName], processPath)
+		}
+	}
+
+	return fileProcesses
+}
+
+// ptraceSyscalls returns the names of the system calls that checked the file
+func (p *artifactStore) ptraceSyscalls(fsaInfo *report.FSActivityInfo) string {
+	if fsaInfo == nil {
+		return ""
+	}
+
+	nameResolver := system.CallNumberResolver(system.ArchName(p.ptMonReport.ArchName))
+	var names []string
+	for num := range fsaInfo.Syscalls {
+		name := fmt.Sprintf("%d", num)
+		if nameResolver != nil {
+			name = nameResolver(uint32(num))
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
 func (p *artifactStore) getArtifactFlags(artifactFileName string) map[string]bool {
 	flags := map[string]bool{}
 	for _, processFileMap := range p.fanMonReport.ProcessFiles {
@@ -300,10 +377,13 @@ func (p *artifactStore) prepareArtifact(artifactFileName string) {
 		props.FileType = report.FileArtifactType
 		props.Sha1Hash, _ = getFileHash(artifactFileName)
 
-		if fileTypeCmd != "" {
-			props.DataType, _ = getDataType(artifactFileName)
+		dataType, err := filetype.Detect(artifactFileName)
+		if err != nil {
+			log.Debugf("prepareArtifact - error detecting data type for %s: %v", artifactFileName, err)
 		}
 
+		props.DataType = dataType
+
 		p.fileMap[artifactFileName] = props
 		p.rawNames[artifactFileName] = props
 	case (srcLinkFileInfo.Mode() & os.ModeSymlink) != 0:
@@ -345,12 +425,14 @@ func (p *artifactStore) prepareArtifact(artifactFileName string) {
 				if evalLinkRef != absLinkRef {
 					if _, ok := p.rawNames[evalLinkRef]; !ok {
 						p.resolve[evalLinkRef] = struct{}{}
+						p.keptBy(evalLinkRef, report.ProvenanceSymlink, artifactFileName)
 					}
 				}
 			}
 
 			if _, ok := p.rawNames[absLinkRef]; !ok {
 				p.resolve[absLinkRef] = struct{}{}
+				p.keptBy(absLinkRef, report.ProvenanceSymlink, artifactFileName)
 			}
 		}
 
@@ -368,12 +450,35 @@ func (p *artifactStore) prepareArtifact(artifactFileName string) {
 func (p *artifactStore) prepareArtifacts() {
 	log.Debugf("p.prepareArtifacts() p.rawNames=%v", len(p.rawNames))
 
+	fileProcesses := p.fanotifyProcesses()
 	for artifactFileName := range p.rawNames {
 		log.Debugf("prepareArtifacts - artifact => %v", artifactFileName)
 		p.prepareArtifact(artifactFileName)
+
+		if processes, found := fileProcesses[artifactFileName]; found {
+			for _, processPath := range processes {
+				p.keptBy(artifactFileName, report.ProvenanceFanotify, processPath)
+			}
+
+			continue
+		}
+
+		//the other files are the links to the files accessed at runtime
+		//(or the symlinks for the directories in their paths)
+		target, err := filepath.EvalSymlinks(artifactFileName)
+		if err != nil || target == artifactFileName {
+			target = ""
+		}
+
+		if fsutil.IsSymlink(artifactFileName) || target != "" {
+			p.keptBy(artifactFileName, report.ProvenanceSymlink, target)
+		} else {
+			p.keptBy(artifactFileName, report.ProvenanceHardlink, "")
+		}
 	}
 
 	for artifactFileName, fsaInfo := range p.ptMonReport.FSActivity {
+		p.keptBy(artifactFileName, report.ProvenancePtrace, p.ptraceSyscalls(fsaInfo))
 		artifactInfo, found := p.rawNames[artifactFileName]
 		if found {
 			artifactInfo.FSActivity = fsaInfo
@@ -401,11 +506,7 @@ func (p *artifactStore) prepareArtifacts() {
 
 		binArtifacts, err := sodeps.AllDependencies(artifactFileName)
 		if err != nil {
-			if err == sodeps.ErrDepResolverNotFound {
-				log.Debug("prepareArtifacts.binArtifacts[bsa] - no static bin dep resolver")
-			} else {
-				log.Warnf("prepareArtifacts.binArtifacts[bsa] - %v - error getting bin artifacts => %v\n", artifactFileName, err)
-			}
+			log.Warnf("prepareArtifacts.binArtifacts[bsa] - %v - error getting bin artifacts => %v\n", artifactFileName, err)
 			continue
 		}
 
@@ -434,6 +535,7 @@ func (p *artifactStore) prepareArtifacts() {
 			}
 
 			bprops.Flags = p.getArtifactFlags(bpath)
+			p.keptBy(bpath, report.ProvenanceELFDeps, artifactFileName)
 
 			fsType := "unknown"
 			switch {
@@ -484,7 +586,7 @@ func (p *artifactStore) resolveLinks() {
 
 		fileInfo, err := os.Lstat(fpath)
 		if err != nil {
-			log.Debugf("resolveLinks.files - os.Lstat(%s) error: ", fpath, err)
+			log.Debugf("resolveLinks.files - os.Lstat(%s) error: %v", fpath, err)
 			continue
 		}
 
@@ -495,7 +597,7 @@ func (p *artifactStore) resolveLinks() {
 
 		linkRef, err := os.Readlink(fpath)
 		if err != nil {
-			log.Debugf("resolveLinks.files - os.Readlink(%s) error: ", fpath, err)
+			log.Debugf("resolveLinks.files - os.Readlink(%s) error: %v", fpath, err)
 			continue
 		}
 
@@ -536,6 +638,7 @@ func (p *artifactStore) resolveLinks() {
 				} else {
 					p.rawNames[fpath] = nil
 					log.Debugf("resolveLinks.files - added path symlink to p.rawNames (0) -> %v", fpath)
+					p.keptBy(fpath, report.ProvenanceSymlink, rawName)
 					p.prepareArtifact(fpath)
 				}
 				break
@@ -547,6 +650,7 @@ func (p *artifactStore) resolveLinks() {
 				} else {
 					p.rawNames[fpath] = nil
 					log.Debugf("resolveLinks.files - added path symlink to p.rawNames (1) -> %v", fpath)
+					p.keptBy(fpath, report.ProvenanceSymlink, rawName)
 					p.prepareArtifact(fpath)
 				}
 				break
@@ -560,6 +664,7 @@ func (p *artifactStore) resolveLinks() {
 				} else {
 					p.rawNames[fpath] = nil
 					log.Debugf("resolveLinks.files - added path symlink to p.rawNames (2) -> %v", fpath)
+					p.keptBy(fpath, report.ProvenanceSymlink, rawName)
 					p.prepareArtifact(fpath)
 				}
 				break
@@ -644,6 +749,8 @@ func (p *artifactStore) saveCertsData() {
 				dstPath := fmt.Sprintf("%s/files%s", p.storeLocation, fname)
 				if err := fsutil.CopyFile(p.cmd.KeepPerms, fname, dstPath, true); err != nil {
 					log.Warnf("sensor.artifactStore.saveCertsData.copyCertFiles: fsutil.CopyFile(%v,%v) error - %v", fname, dstPath, err)
+				} else {
+					p.keepExtra(fname, report.ProvenanceCerts, "")
 				}
 			}
 		}
@@ -659,7 +766,11 @@ func (p *artifactStore) saveCertsData() {
 					err, errs := fsutil.CopyDir(p.cmd.KeepPerms, fname, dstPath, true, true, nil, nil, nil)
 					if err != nil {
 						log.Warnf("sensor.artifactStore.saveCertsData.copyDirs: fsutil.CopyDir(%v,%v) error: %v", fname, dstPath, err)
-					} else if copyLinkTargets {
+					} else {
+						p.keepExtra(fname, report.ProvenanceCerts, "")
+					}
+
+					if err == nil && copyLinkTargets {
 						foList, err := ioutil.ReadDir(fname)
 						if err == nil {
 							log.Debugf("sensor.artifactStore.saveCertsData.copyDirs(): dir=%v fcount=%v", fname, len(foList))
@@ -683,6 +794,8 @@ func (p *artifactStore) saveCertsData() {
 											dstPath := fmt.Sprintf("%s/files%s", p.storeLocation, targetFilePath)
 											if err := fsutil.CopyFile(p.cmd.KeepPerms, targetFilePath, dstPath, true); err != nil {
 												log.Warnf("sensor.artifactStore.saveCertsData.copyDirs: fsutil.CopyFile(%v,%v) error - %v", targetFilePath, dstPath, err)
+											} else {
+												p.keepExtra(targetFilePath, report.ProvenanceCerts, fullPath)
 											}
 										} else {
 											log.Warnf("sensor.artifactStore.saveCertsData.copyDirs: targetFilePath does not exist - %v", targetFilePath)
@@ -701,6 +814,8 @@ func (p *artifactStore) saveCertsData() {
 				} else if fsutil.IsSymlink(fname) {
 					if err := fsutil.CopySymlinkFile(p.cmd.KeepPerms, fname, dstPath, true); err != nil {
 						log.Warnf("sensor.artifactStore.saveCertsData.copyDirs: fsutil.CopySymlinkFile(%v,%v) error - %v", fname, dstPath, err)
+					} else {
+						p.keepExtra(fname, report.ProvenanceCerts, "")
 					}
 				} else {
 					log.Warnf("artifactStore.saveCertsData.copyDir: unexpected obect type - %s", fname)
@@ -734,6 +849,8 @@ func (p *artifactStore) saveCertsData() {
 				dstPath := fmt.Sprintf("%s/files%s", p.storeLocation, srcFilePath)
 				if err := fsutil.CopyFile(p.cmd.KeepPerms, srcFilePath, dstPath, true); err != nil {
 					log.Warnf("sensor.artifactStore.saveCertsData.copyAppCertFiles: fsutil.CopyFile(%v,%v) error - %v", srcFilePath, dstPath, err)
+				} else {
+					p.keepExtra(srcFilePath, report.ProvenanceCerts, dirName)
 				}
 			}
 		}
@@ -1001,24 +1118,37 @@ copyFiles:
 
 		if isRbGemSpecFile(fileName) {
 			log.Debug("saveArtifacts - processing ruby gem spec ==>", fileName)
-			err := rbEnsureGemFiles(fileName, p.storeLocation, "/files")
+			ensured, err := rbEnsureGemFiles(fileName, p.storeLocation, "/files")
 			if err != nil {
 				log.Warn("saveArtifacts - error ensuring ruby gem files => ", err)
 			}
+
+			for _, name := range ensured {
+				p.keepExtra(name, report.ProvenanceFixupRuby, fileName)
+			}
 		} else if isNodePackageFile(fileName) {
 			log.Debug("saveArtifacts - processing node package file ==>", fileName)
-			err := nodeEnsurePackageFiles(p.cmd.KeepPerms, fileName, p.storeLocation, "/files")
+			ensured, err := nodeEnsurePackageFiles(p.cmd.KeepPerms, fileName, p.storeLocation, "/files")
 			if err != nil {
 				log.Warn("saveArtifacts - error ensuring node package files => ", err)
 			}
+
+			for _, name := range ensured {
+				p.keepExtra(name, report.ProvenanceFixupNode, fileName)
+			}
 		} else if isNgxArtifact(fileName) && !ngxEnsured {
 			log.Debug("saveArtifacts - ensuring ngx artifacts....")
-			ngxEnsure(p.storeLocation)
+			for _, name := range ngxEnsure(p.storeLocation) {
+				p.keepExtra(name, report.ProvenanceFixupNginx, fileName)
+			}
+
 			ngxEnsured = true
 		} else {
 			err := fixPy3CacheFile(fileName, filePath)
 			if err != nil {
 				log.Warn("saveArtifacts - error fixing py3 cache file => ", err)
+			} else if srcPyFilePath := py3FileNameFromCache(fileName); srcPyFilePath != "" && fsutil.Exists(srcPyFilePath) {
+				p.keepExtra(srcPyFilePath, report.ProvenanceFixupPython, fileName)
 			}
 		}
 	}
@@ -1064,6 +1194,8 @@ copyBsaFiles:
 			//if err := cpFile(passwdFilePath, passwdFileTargetPath); err != nil {
 			if err := fsutil.CopyRegularFile(p.cmd.KeepPerms, passwdFilePath, passwdFileTargetPath, true); err != nil {
 				log.Warn("sensor: monitor - error copying user info file =>", err)
+			} else {
+				p.keepExtra(passwdFilePath, report.ProvenanceSystem, "user")
 			}
 		} else {
 			if os.IsNotExist(err) {
@@ -1081,6 +1213,8 @@ copyIncludes:
 			err, errs := fsutil.CopyDir(p.cmd.KeepPerms, inPath, dstPath, true, true, excludePatterns, nil, nil)
 			if err != nil {
 				log.Warnf("CopyDir(%v,%v) error: %v", inPath, dstPath, err)
+			} else {
+				p.keepExtraDir(inPath, dstPath, report.ProvenanceIncludePath, inPath)
 			}
 
 			if len(errs) > 0 {
@@ -1102,6 +1236,8 @@ copyIncludes:
 
 			if err := fsutil.CopyFile(p.cmd.KeepPerms, inPath, dstPath, true); err != nil {
 				log.Warnf("CopyFile(%v,%v) error: %v", inPath, dstPath, err)
+			} else {
+				p.keepExtra(inPath, report.ProvenanceIncludePath, inPath)
 			}
 		}
 	}
@@ -1120,6 +1256,8 @@ copyIncludes:
 			dstPath := fmt.Sprintf("%s/files%s", p.storeLocation, apath)
 			if err := fsutil.CopyFile(p.cmd.KeepPerms, apath, dstPath, true); err != nil {
 				log.Warnf("CopyFile(%v,%v) error: %v", apath, dstPath, err)
+			} else {
+				p.keepExtra(apath, report.ProvenanceIncludeExe, exePath)
 			}
 		}
 	}
@@ -1138,6 +1276,8 @@ copyIncludes:
 			dstPath := fmt.Sprintf("%s/files%s", p.storeLocation, bpath)
 			if err := fsutil.CopyFile(p.cmd.KeepPerms, bpath, dstPath, true); err != nil {
 				log.Warnf("CopyFile(%v,%v) error: %v", bpath, dstPath, err)
+			} else {
+				p.keepExtra(bpath, report.ProvenanceIncludeBin, binPath)
 			}
 		}
 	}
@@ -1152,6 +1292,8 @@ copyIncludes:
 				dstPath := fmt.Sprintf("%s/files%s", p.storeLocation, spath)
 				if err := fsutil.CopyFile(p.cmd.KeepPerms, spath, dstPath, true); err != nil {
 					log.Warnf("CopyFile(%v,%v) error: %v", spath, dstPath, err)
+				} else {
+					p.keepExtra(spath, report.ProvenanceIncludeShell, "shell")
 				}
 			}
 		} else {
@@ -1163,6 +1305,7 @@ copyIncludes:
 	p.saveCertsData()
 
 	if fsutil.DirExists("/tmp") {
+		p.keepExtra("/tmp", report.ProvenanceSystem, "")
 		tdTargetPath := fmt.Sprintf("%s/files/tmp", p.storeLocation)
 		if !fsutil.DirExists(tdTargetPath) {
 			if err := os.MkdirAll(tdTargetPath, os.ModeSticky|os.ModeDir|0777); err != nil {
@@ -1176,6 +1319,7 @@ copyIncludes:
 	}
 
 	if fsutil.DirExists("/run") {
+		p.keepExtra("/run", report.ProvenanceSystem, "")
 		tdTargetPath := fmt.Sprintf("%s/files/run", p.storeLocation)
 		if !fsutil.DirExists(tdTargetPath) {
 			if err := os.MkdirAll(tdTargetPath, 0755); err != nil {
@@ -1189,6 +1333,8 @@ copyIncludes:
 		if fsutil.DirExists(extraDir) && !fsutil.DirExists(tdTargetPath) {
 			if err := fsutil.CopyDirOnly(p.cmd.KeepPerms, extraDir, tdTargetPath); err != nil {
 				log.Warnf("CopyDirOnly(%v,%v) error: %v", extraDir, tdTargetPath, err)
+			} else {
+				p.keepExtra(extraDir, report.ProvenanceSystem, "pid.file")
 			}
 		}
 	}
@@ -1203,7 +1349,7 @@ copyIncludes:
 	}
 
 	if len(p.cmd.Preserves) > 0 {
-		log.Debug("saveArtifacts: restoring preserved paths - %d", len(p.cmd.Preserves))
+		log.Debugf("saveArtifacts: restoring preserved paths - %d", len(p.cmd.Preserves))
 
 		preservedDirPath := filepath.Join(p.storeLocation, preservedDirName)
 		filesDirPath := filepath.Join(p.storeLocation, filesDirName)
@@ -1217,6 +1363,8 @@ copyIncludes:
 					err, errs := fsutil.CopyDir(p.cmd.KeepPerms, srcPath, dstPath, true, true, nil, nil, nil)
 					if err != nil {
 						log.Warnf("saveArtifacts.CopyDir(%v,%v) error: %v", srcPath, dstPath, err)
+					} else {
+						p.keepExtraDir(inPath, srcPath, report.ProvenancePreservePath, inPath)
 					}
 
 					if len(errs) > 0 {
@@ -1225,6 +1373,8 @@ copyIncludes:
 				} else {
 					if err := fsutil.CopyFile(p.cmd.KeepPerms, srcPath, dstPath, true); err != nil {
 						log.Warnf("saveArtifacts.CopyFile(%v,%v) error: %v", srcPath, dstPath, err)
+					} else {
+						p.keepExtra(inPath, report.ProvenancePreservePath, inPath)
 					}
 				}
 			}
@@ -1419,8 +1569,6 @@ func (p *artifactStore) archiveArtifacts() {
 }
 
 func (p *artifactStore) saveReport() {
-	sort.Strings(p.nameList)
-
 	creport := report.ContainerReport{
 		Monitors: report.MonitorReports{
 			Pt:  p.ptMonReport,
@@ -1439,10 +1587,34 @@ func (p *artifactStore) saveReport() {
 		},
 	}
 
+	//the files copied outside of the monitored file set
+	for name := range p.extraNames {
+		if _, found := p.rawNames[name]; found {
+			continue
+		}
+
+		if props := p.extraArtifactProps(name); props != nil {
+			p.nameList = append(p.nameList, name)
+			p.rawNames[name] = props
+		}
+	}
+
+	sort.Strings(p.nameList)
+
 	for _, fname := range p.nameList {
-		creport.Image.Files = append(creport.Image.Files, p.rawNames[fname])
+		props := p.rawNames[fname]
+		if props == nil {
+			continue
+		}
+
+		props.KeptBy = p.provenance[fname]
+		creport.Image.Files = append(creport.Image.Files, props)
 	}
 
+	creport.Image.AppStacks = p.appStacksInfo()
+	creport.Image.AppDependencies = p.appDependenciesInfo()
+	creport.Image.OSPackages = p.osPackagesInfo()
+
 	reportName := defaultReportName
 
 	_, err := os.Stat(p.storeLocation)
@@ -1466,39 +1638,45 @@ func (p *artifactStore) saveReport() {
 	errutil.FailOn(err)
 }
 
-func getFileHash(artifactFileName string) (string, error) {
-	fileData, err := ioutil.ReadFile(artifactFileName)
+func (p *artifactStore) extraArtifactProps(name string) *report.ArtifactProps {
+	info, err := os.Lstat(name)
 	if err != nil {
-		return "", err
+		log.Debugf("extraArtifactProps - error checking %v => %v", name, err)
+		return nil
 	}
 
-	hash := sha1.Sum(fileData)
-	return hex.EncodeToString(hash[:]), nil
-}
-
-func getDataType(artifactFileName string) (string, error) {
-	//TODO: use libmagic (pure impl)
-	var cerr bytes.Buffer
-	var cout bytes.Buffer
-
-	cmd := exec.Command(fileTypeCmd, artifactFileName)
-	cmd.Stderr = &cerr
-	cmd.Stdout = &cout
-
-	if err := cmd.Start(); err != nil {
-		return "", err
+	props := &report.ArtifactProps{
+		FilePath: name,
+		Mode:     info.Mode(),
+		ModeText: info.Mode().String(),
+		FileSize: info.Size(),
+		Flags:    p.getArtifactFlags(name),
 	}
 
-	if err := cmd.Wait(); err != nil {
-		err = fmt.Errorf("getDataType - error getting data type: %s / stderr: %s", err, cerr.String())
-		return "", err
+	switch {
+	case info.Mode().IsRegular():
+		props.FileType = report.FileArtifactType
+		props.Sha1Hash, _ = getFileHash(name)
+	case (info.Mode() & os.ModeSymlink) != 0:
+		props.FileType = report.SymlinkArtifactType
+		props.LinkRef, _ = os.Readlink(name)
+	case info.IsDir():
+		props.FileType = report.DirArtifactType
+	default:
+		props.FileType = report.UnknownArtifactType
 	}
 
-	if typeInfo := strings.Split(strings.TrimSpace(cout.String()), ":"); len(typeInfo) > 1 {
-		return strings.TrimSpace(typeInfo[1]), nil
+	return props
+}
+
+func getFileHash(artifactFileName string) (string, error) {
+	fileData, err := ioutil.ReadFile(artifactFileName)
+	if err != nil {
+		return "", err
 	}
 
-	return "unknown", nil
+	hash := sha1.Sum(fileData)
+	return hex.EncodeToString(hash[:]), nil
 }
 
 /*
@@ -1616,9 +1794,9 @@ func fixPy3CacheFile(src, dst string) error {
 	return nil
 }
 
-func rbEnsureGemFiles(src, storeLocation, prefix string) error {
+func rbEnsureGemFiles(src, storeLocation, prefix string) ([]string, error) {
 	if strings.Contains(src, rbDefaultSpecSubDir) {
-		return nil
+		return nil, nil
 	}
 
 	dir, file := path.Split(src)
@@ -1628,9 +1806,11 @@ func rbEnsureGemFiles(src, storeLocation, prefix string) error {
 	extBasePath := filepath.Join(base, rgExtSibDir)
 	foList, err := ioutil.ReadDir(extBasePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var ensured []string
+
 	for _, fo := range foList {
 		if fo.IsDir() {
 			platform := fo.Name()
@@ -1638,7 +1818,7 @@ func rbEnsureGemFiles(src, storeLocation, prefix string) error {
 			extPlatformPath := filepath.Join(extBasePath, platform)
 			foVerList, err := ioutil.ReadDir(extPlatformPath)
 			if err != nil {
-				return err
+				return ensured, err
 			}
 
 			for _, foVer := range foVerList {
@@ -1658,15 +1838,17 @@ func rbEnsureGemFiles(src, storeLocation, prefix string) error {
 						//if err := cpFile(extBuildFlagFilePath, extBuildFlagFilePathDst); err != nil {
 						if err := fsutil.CopyRegularFile(true, extBuildFlagFilePath, extBuildFlagFilePathDst, true); err != nil {
 							log.Warnln("sensor: monitor - rbEnsureGemFiles - error copying file =>", extBuildFlagFilePathDst)
-							return err
+							return ensured, err
 						}
+
+						ensured = append(ensured, extBuildFlagFilePath)
 					}
 				}
 			}
 		}
 	}
 
-	return nil
+	return ensured, nil
 }
 
 func isRbGemSpecFile(filePath string) bool {
@@ -1690,7 +1872,8 @@ func isNodePackageFile(filePath string) bool {
 	return false
 }
 
-func nodeEnsurePackageFiles(keepPerms bool, src, storeLocation, prefix string) error {
+func nodeEnsurePackageFiles(keepPerms bool, src, storeLocation, prefix string) ([]string, error) {
+	var ensured []string
 	if strings.HasSuffix(src, nodeNPMNodeGypPackage) {
 		//for now only ensure that we have node-gyp for npm
 		//npm requires it to be there even though it won't use it
@@ -1700,12 +1883,14 @@ func nodeEnsurePackageFiles(keepPerms bool, src, storeLocation, prefix string) e
 			nodeGypFilePathDst := fmt.Sprintf("%s%s%s", storeLocation, prefix, nodeGypFilePath)
 			if err := fsutil.CopyRegularFile(keepPerms, nodeGypFilePath, nodeGypFilePathDst, true); err != nil {
 				log.Warnf("sensor: nodeEnsurePackageFiles - error copying %s => %v", nodeGypFilePath, err)
+			} else {
+				ensured = append(ensured, nodeGypFilePath)
 			}
 		}
 	}
 
 	//NOTE: can also read the dependencies and confirm/ensure that we copied everything we need
-	return nil
+	return ensured, nil
 }
 
 var pidFilePathSuffixes = []string{
@@ -1741,10 +1926,12 @@ func isNgxArtifact(filePath string) bool {
 	return false
 }
 
-func ngxEnsure(prefix string) {
+func ngxEnsure(prefix string) []string {
+	var ensured []string
 	//ensure common temp paths (note: full implementation needs mkdir syscall info)
 	if info, err := os.Stat(ngxCommonTemp); err == nil {
 		if info.IsDir() {
+			ensured = append(ensured, ngxCommonTemp)
 			dstPath := fmt.Sprintf("%s/files%s", prefix, ngxCommonTemp)
 			if !fsutil.DirExists(dstPath) {
 				err := os.MkdirAll(dstPath, 0777)
@@ -1767,6 +1954,7 @@ func ngxEnsure(prefix string) {
 
 	if info, err := os.Stat(ngxLogTemp); err == nil {
 		if info.IsDir() {
+			ensured = append(ensured, ngxLogTemp)
 			dstPath := fmt.Sprintf("%s/files%s", prefix, ngxLogTemp)
 			if !fsutil.DirExists(dstPath) {
 				err := os.MkdirAll(dstPath, 0777)
@@ -1785,6 +1973,7 @@ func ngxEnsure(prefix string) {
 
 	if info, err := os.Stat(ngxCacheTemp); err == nil {
 		if info.IsDir() {
+			ensured = append(ensured, ngxCacheTemp)
 			dstPath := fmt.Sprintf("%s/files%s", prefix, ngxCacheTemp)
 			if !fsutil.DirExists(dstPath) {
 				err := os.MkdirAll(dstPath, 0777)
@@ -1800,6 +1989,8 @@ func ngxEnsure(prefix string) {
 			log.Debugf("ngxEnsure - error checking %v => %v", ngxCacheTemp, err)
 		}
 	}
+
+	return ensured
 }
 
 var shellNames = []string{
@@ -0,0 +1,181 @@
+// +build linux
+
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+)
+
+// testArtifactsDir creates the test files
+// (the names ending with '/' are directories and the '->' values are symlinks)
+func testArtifactsDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "sensor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the monitored paths have no symlinks in the parent directories
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range files {
+		fullPath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case name[len(name)-1] == '/':
+			err = os.MkdirAll(fullPath, 0755)
+		case len(data) > 2 && data[:2] == "->":
+			err = os.Symlink(data[2:], fullPath)
+		default:
+			err = ioutil.WriteFile(fullPath, []byte(data), 0644)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// provenanceStrings returns the provenance for each artifact ("rule:source", sorted)
+// with the source paths relative to the test directory
+func provenanceStrings(dir string, provenance map[string][]*report.Provenance) map[string][]string {
+	rel := func(name string) string {
+		if relPath, err := filepath.Rel(dir, name); err == nil && filepath.IsAbs(name) {
+			return relPath
+		}
+
+		return name
+	}
+
+	result := map[string][]string{}
+	for name, items := range provenance {
+		for _, p := range items {
+			result[rel(name)] = append(result[rel(name)], p.Rule+":"+rel(p.Source))
+		}
+
+		sort.Strings(result[rel(name)])
+	}
+
+	return result
+}
+
+func TestArtifactStoreProvenance(t *testing.T) {
+	dir := testArtifactsDir(t, map[string]string{
+		"app/server":       "server",
+		"app/config.yaml":  "port: 80",
+		"app/config.local": "port: 8080",
+		"app/link":         "->server",
+		"app/cfg":          "->config.local",
+		"data/ptrace.txt":  "data",
+		"extra/a":          "a",
+		"extra/sub/b":      "b",
+		"copy/a":           "a",
+		"copy/sub/":        "",
+	})
+
+	defer os.RemoveAll(dir)
+
+	if err := os.Link(filepath.Join(dir, "app/server"), filepath.Join(dir, "app/hard")); err != nil {
+		t.Fatal(err)
+	}
+
+	abs := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	rawNames := map[string]*report.ArtifactProps{}
+	for _, name := range []string{"app/server", "app/config.yaml", "app/link", "app/hard", "app/cfg"} {
+		rawNames[abs(name)] = nil
+	}
+
+	fanReport := &report.FanMonitorReport{
+		Processes: map[string]*report.ProcessInfo{
+			"10": {Pid: 10, Path: abs("app/server")},
+		},
+		ProcessFiles: map[string]map[string]*report.FileInfo{
+			"10": {
+				abs("app/server"):      {ExeCount: 1},
+				abs("app/config.yaml"): {ReadCount: 1},
+			},
+			"11": {
+				abs("app/config.yaml"): {ReadCount: 1},
+			},
+		},
+	}
+
+	ptReport := &report.PtMonitorReport{
+		ArchName: "amd64",
+		FSActivity: map[string]*report.FSActivityInfo{
+			abs("data/ptrace.txt"): {Syscalls: map[int]struct{}{0: {}, 4: {}}},
+		},
+	}
+
+	storeLocation := abs("artifacts")
+	store := newArtifactStore(storeLocation, nil, rawNames, fanReport, ptReport, nil, nil)
+	store.prepareArtifacts()
+
+	//the copy of the extra directory doesn't have the excluded files ('extra/sub/b')
+	store.keepExtraDir(abs("extra"), abs("copy"), report.ProvenanceIncludePath, abs("extra"))
+	store.keepExtra(abs("app/config.yaml"), report.ProvenanceIncludePath, abs("app/config.yaml"))
+
+	expected := map[string][]string{
+		"app/server": {"fanotify:app/server"},
+		"app/config.yaml": {
+			"fanotify:11",
+			"fanotify:app/server",
+			"include.path:app/config.yaml",
+		},
+		"app/link":         {"symlink:app/server"},
+		"app/hard":         {"hardlink:"},
+		"app/cfg":          {"symlink:app/config.local"},
+		"app/config.local": {"symlink:app/cfg"},
+		"data/ptrace.txt":  {"ptrace:read,stat"},
+		"extra":            {"include.path:extra"},
+		"extra/a":          {"include.path:extra"},
+		"extra/sub":        {"include.path:extra"},
+	}
+
+	if got := provenanceStrings(dir, store.provenance); !reflect.DeepEqual(got, expected) {
+		t.Errorf("provenance:\ngot      %q\nexpected %q", got, expected)
+	}
+
+	store.saveReport()
+
+	data, err := ioutil.ReadFile(filepath.Join(storeLocation, defaultReportName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var creport report.ContainerReport
+	if err := json.Unmarshal(data, &creport); err != nil {
+		t.Fatal(err)
+	}
+
+	//all artifacts in the report have their provenance
+	reported := map[string][]*report.Provenance{}
+	for _, props := range creport.Image.Files {
+		if _, found := reported[props.FilePath]; found {
+			t.Errorf("duplicate report artifact: %s", props.FilePath)
+		}
+
+		reported[props.FilePath] = props.KeptBy
+	}
+
+	if got := provenanceStrings(dir, reported); !reflect.DeepEqual(got, expected) {
+		t.Errorf("report provenance:\ngot      %q\nexpected %q", got, expected)
+	}
+}
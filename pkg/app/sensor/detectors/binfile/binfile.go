@@ -27,3 +27,29 @@ func Detected(filePath string) (bool, error) {
 
 	return false, err
 }
+
+// maxInterpreterPathSize is the PATH_MAX limit for the program interpreter path
+const maxInterpreterPathSize = 4096
+
+// Interpreter returns the program interpreter (the PT_INTERP program header value)
+// or an empty string if the binary has no interpreter or the interpreter path is not valid
+func Interpreter(ef *elf.File) string {
+	for _, prog := range ef.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+
+		if prog.Filesz == 0 || prog.Filesz > maxInterpreterPathSize {
+			return ""
+		}
+
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			return ""
+		}
+
+		return strings.TrimRight(string(data), "\x00")
+	}
+
+	return ""
+}
@@ -0,0 +1,183 @@
+package filetype
+
+import (
+	"debug/elf"
+	"fmt"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/app/sensor/detectors/binfile"
+)
+
+const (
+	elfTagFlags1 = 0x6ffffffb //DT_FLAGS_1
+	elfFlag1PIE  = 0x08000000 //DF_1_PIE
+)
+
+var elfMachineNames = map[elf.Machine]string{
+	elf.EM_386:     "Intel 80386",
+	elf.EM_X86_64:  "x86-64",
+	elf.EM_ARM:     "ARM",
+	elf.EM_AARCH64: "ARM aarch64",
+	elf.EM_PPC:     "PowerPC or cisco 4500",
+	elf.EM_PPC64:   "64-bit PowerPC or cisco 7500",
+	elf.EM_S390:    "IBM S/390",
+	elf.EM_MIPS:    "MIPS",
+	elf.EM_RISCV:   "UCB RISC-V",
+	elf.EM_SPARCV9: "SPARC V9",
+}
+
+var elfOSABINames = map[elf.OSABI]string{
+	elf.ELFOSABI_NONE:    "SYSV",
+	elf.ELFOSABI_LINUX:   "GNU/Linux",
+	elf.ELFOSABI_FREEBSD: "FreeBSD",
+}
+
+// describeELF describes the ELF file (class, byte order, object type, arch, linkage, interpreter and symbols)
+// e.g., 'ELF 64-bit LSB pie executable, x86-64, version 1 (SYSV), dynamically linked, interpreter /lib64/ld-linux-x86-64.so.2, stripped'
+func describeELF(ef *elf.File) string {
+	var parts []string
+
+	class := "32-bit"
+	if ef.Class == elf.ELFCLASS64 {
+		class = "64-bit"
+	}
+
+	order := "LSB"
+	if ef.Data == elf.ELFDATA2MSB {
+		order = "MSB"
+	}
+
+	interp := binfile.Interpreter(ef)
+	isDynamic := ef.Section(".dynamic") != nil || elfHasProg(ef, elf.PT_DYNAMIC)
+
+	var objType string
+	switch ef.Type {
+	case elf.ET_EXEC:
+		objType = "executable"
+	case elf.ET_DYN:
+		//PIE executables are shared objects with the PIE flag
+		//(the flag is set by the newer linkers)
+		if elfHasPIEFlag(ef) {
+			objType = "pie executable"
+		} else {
+			objType = "shared object"
+		}
+	case elf.ET_REL:
+		objType = "relocatable"
+	case elf.ET_CORE:
+		objType = "core file"
+	default:
+		objType = fmt.Sprintf("unknown type 0x%x", uint16(ef.Type))
+	}
+
+	parts = append(parts, fmt.Sprintf("ELF %s %s %s", class, order, objType))
+
+	machine, found := elfMachineNames[ef.Machine]
+	if !found {
+		machine = strings.TrimPrefix(ef.Machine.String(), "EM_")
+	}
+
+	parts = append(parts, machine)
+
+	osabi, found := elfOSABINames[ef.OSABI]
+	if !found {
+		osabi = strings.TrimPrefix(ef.OSABI.String(), "ELFOSABI_")
+	}
+
+	parts = append(parts, fmt.Sprintf("version %d (%s)", ef.Version, osabi))
+
+	if ef.Type == elf.ET_EXEC || ef.Type == elf.ET_DYN {
+		switch {
+		case isDynamic && ef.Type == elf.ET_DYN && interp == "" && elfIsStaticPIE(ef):
+			parts = append(parts, "static-pie linked")
+		case isDynamic:
+			parts = append(parts, "dynamically linked")
+		default:
+			parts = append(parts, "statically linked")
+		}
+	}
+
+	if interp != "" {
+		parts = append(parts, fmt.Sprintf("interpreter %s", interp))
+	}
+
+	if ef.Type != elf.ET_CORE {
+		if ef.Section(".symtab") != nil {
+			parts = append(parts, "not stripped")
+		} else {
+			parts = append(parts, "stripped")
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func elfHasProg(ef *elf.File, progType elf.ProgType) bool {
+	for _, prog := range ef.Progs {
+		if prog.Type == progType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// elfIsStaticPIE checks if the shared object is a static PIE executable
+// (has an entry point and no needed shared libraries)
+func elfIsStaticPIE(ef *elf.File) bool {
+	if ef.Entry == 0 {
+		return false
+	}
+
+	libs, err := ef.ImportedLibraries()
+	if err != nil || len(libs) > 0 {
+		return false
+	}
+
+	//the static PIE executables don't export dynamic symbols (the shared libraries do)
+	symbols, err := ef.DynamicSymbols()
+	if err != nil {
+		return true
+	}
+
+	for _, sym := range symbols {
+		if elf.ST_BIND(sym.Info) == elf.STB_GLOBAL && sym.Section != elf.SHN_UNDEF {
+			return false
+		}
+	}
+
+	return true
+}
+
+// elfHasPIEFlag checks if the DF_1_PIE flag is set in the dynamic section
+func elfHasPIEFlag(ef *elf.File) bool {
+	section := ef.Section(".dynamic")
+	if section == nil {
+		return false
+	}
+
+	data, err := section.Data()
+	if err != nil {
+		return false
+	}
+
+	entrySize := 8
+	readVal := func(b []byte) uint64 { return uint64(ef.ByteOrder.Uint32(b)) }
+	if ef.Class == elf.ELFCLASS64 {
+		entrySize = 16
+		readVal = ef.ByteOrder.Uint64
+	}
+
+	for offset := 0; offset+entrySize <= len(data); offset += entrySize {
+		tag := readVal(data[offset:])
+		if tag == uint64(elf.DT_NULL) {
+			break
+		}
+
+		if tag == elfTagFlags1 {
+			return readVal(data[offset+entrySize/2:])&elfFlag1PIE != 0
+		}
+	}
+
+	return false
+}
@@ -2,58 +2,87 @@ package filetype
 
 import (
 	"bytes"
-	"fmt"
-	"os/exec"
+	"debug/elf"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
+)
 
-	log "github.com/sirupsen/logrus"
+// Data type descriptions (using the same descriptions as the 'file' command where possible)
+const (
+	TypeEmpty   = "empty"
+	TypeData    = "data"
+	TypeUnknown = "unknown"
 )
 
 const (
-	fileTypeCmdName = "file"
+	headerSize = 64 * 1024 //enough data for the magic and text encoding checks
 )
 
-var fileTypeCmd string
+// Detect returns the data type description for the file
+// (a pure Go replacement for the 'file' command output covering the common file formats)
+func Detect(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
 
-func init() {
-	findFileTypeCmd()
-}
+	defer f.Close()
 
-func findFileTypeCmd() {
-	fileTypeCmd, err := exec.LookPath(fileTypeCmdName)
+	info, err := f.Stat()
 	if err != nil {
-		log.Debugf("findFileTypeCmd - cmd not found: %v", err)
-		return
+		return "", err
 	}
 
-	log.Debugf("findFileTypeCmd - cmd found: %v", fileTypeCmd)
-}
+	if info.Size() == 0 {
+		return TypeEmpty, nil
+	}
 
-func Detect(filePath string) (string, error) {
-	//TODO: use libmagic (pure impl)
-	var cerr bytes.Buffer
-	var cout bytes.Buffer
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	header = header[:n]
+	if bytes.HasPrefix(header, []byte(elf.ELFMAG)) {
+		if ef, err := elf.NewFile(f); err == nil {
+			defer ef.Close()
+			return describeELF(ef), nil
+		}
 
-	if fileTypeCmd != "" {
-		return "", nil
+		return "ELF (malformed)", nil
 	}
 
-	cmd := exec.Command(fileTypeCmd, filePath)
-	cmd.Stderr = &cerr
-	cmd.Stdout = &cout
+	return DetectData(filePath, header, int(info.Size()) == n), nil
+}
 
-	if err := cmd.Start(); err != nil {
-		return "", err
+// DetectData returns the data type description for the file data header
+// (complete is true if the header is the complete file data)
+func DetectData(filePath string, header []byte, complete bool) string {
+	if len(header) == 0 {
+		return TypeEmpty
 	}
 
-	if err := cmd.Wait(); err != nil {
-		err = fmt.Errorf("Detect - error getting data type: %s / stderr: %s", err, cerr.String())
-		return "", err
+	if dataType := detectMagic(filePath, header); dataType != "" {
+		return dataType
+	}
+
+	if dataType := detectText(filePath, header, complete); dataType != "" {
+		return dataType
 	}
 
-	if typeInfo := strings.Split(strings.TrimSpace(cout.String()), ":"); len(typeInfo) > 1 {
-		return strings.TrimSpace(typeInfo[1]), nil
+	return TypeData
+}
+
+func hasExt(filePath string, exts ...string) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
 	}
 
-	return "unknown", nil
+	return false
 }
@@ -0,0 +1,304 @@
+package filetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//NOTE:
+//The ELF files in testdata are built from a minimal C program with gcc
+//('-nostdlib -static', '-nostdlib -static-pie', '-pie', '-no-pie' and '-shared')
+//and linked with '-z noseparate-code -z max-page-size=0x10' to keep them small.
+
+func TestDetectELF(t *testing.T) {
+	tt := []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "static",
+			expected: "ELF 64-bit LSB executable, x86-64, version 1 (SYSV), statically linked, stripped",
+		},
+		{
+			name:     "static-pie",
+			expected: "ELF 64-bit LSB pie executable, x86-64, version 1 (SYSV), static-pie linked, stripped",
+		},
+		{
+			name:     "dynamic",
+			expected: "ELF 64-bit LSB executable, x86-64, version 1 (SYSV), dynamically linked, interpreter /lib64/ld-linux-x86-64.so.2, not stripped",
+		},
+		{
+			name:     "dynamic-pie",
+			expected: "ELF 64-bit LSB pie executable, x86-64, version 1 (SYSV), dynamically linked, interpreter /lib64/ld-linux-x86-64.so.2, stripped",
+		},
+		{
+			name:     "shared",
+			expected: "ELF 64-bit LSB shared object, x86-64, version 1 (SYSV), dynamically linked, stripped",
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			dataType, err := Detect(filepath.Join("testdata", test.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if dataType != test.expected {
+				t.Errorf("got %q expected %q", dataType, test.expected)
+			}
+		})
+	}
+}
+
+func TestDetectFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ds-filetype-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]struct {
+		data     []byte
+		expected string
+	}{
+		"empty":     {data: nil, expected: TypeEmpty},
+		"truncated": {data: []byte("\x7fELF\x02\x01\x01"), expected: "ELF (malformed)"},
+		//the multi-byte sequence at the header end is not an encoding error if the file is bigger
+		//(only the header data is checked)
+		"big.txt": {
+			data:     append(bytes.Repeat([]byte("a"), headerSize-1), []byte("é\n")...),
+			expected: encodingUTF8 + ", with very long lines (65536), with no line terminators",
+		},
+	}
+
+	for name, file := range files {
+		filePath := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filePath, file.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		dataType, err := Detect(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if dataType != file.expected {
+			t.Errorf("%s: got %q expected %q", name, dataType, file.expected)
+		}
+	}
+}
+
+func TestDetectData(t *testing.T) {
+	tarHeader := make([]byte, 512)
+	copy(tarHeader[257:], "ustar\x0000")
+
+	tt := []struct {
+		name     string
+		filePath string
+		data     []byte
+		expected string
+	}{
+		//scripts
+		{
+			name:     "shell script",
+			filePath: "/docker-entrypoint.sh",
+			data:     []byte("#!/bin/sh\nexec \"$@\"\n"),
+			expected: "POSIX shell script, ASCII text executable",
+		},
+		{
+			name:     "bash script",
+			filePath: "/usr/local/bin/run",
+			data:     []byte("#!/bin/bash -e\r\necho ok\r\n"),
+			expected: "Bourne-Again shell script, ASCII text executable, with CRLF line terminators",
+		},
+		{
+			name:     "env python script",
+			filePath: "/app/main",
+			data:     []byte("#!/usr/bin/env python3\nprint('ok')\n"),
+			expected: "Python script, ASCII text executable",
+		},
+		{
+			name:     "versioned interpreter",
+			filePath: "/app/main",
+			data:     []byte("#!/usr/bin/python3.9\nprint('ok')\n"),
+			expected: "Python script, ASCII text executable",
+		},
+		{
+			name:     "env -S node script",
+			filePath: "/app/server",
+			data:     []byte("#!/usr/bin/env -S node --harmony\n"),
+			expected: "Node.js script, ASCII text executable",
+		},
+		{
+			name:     "unknown interpreter",
+			filePath: "/app/main",
+			data:     []byte("#!/opt/app/bin/runner\n"),
+			expected: "a /opt/app/bin/runner script, ASCII text executable",
+		},
+		{
+			name:     "unknown env interpreter",
+			filePath: "/app/main",
+			data:     []byte("#!/usr/bin/env runner\n"),
+			expected: "a /usr/bin/env runner script, ASCII text executable",
+		},
+		//archives and compressed data
+		{
+			name:     "gzip",
+			filePath: "/app/data.gz",
+			data:     []byte{0x1f, 0x8b, 0x08, 0x00},
+			expected: "gzip compressed data",
+		},
+		{
+			name:     "xz",
+			filePath: "/app/data.xz",
+			data:     []byte{0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00},
+			expected: "XZ compressed data",
+		},
+		{
+			name:     "tar",
+			filePath: "/app/data.tar",
+			data:     tarHeader,
+			expected: "POSIX tar archive",
+		},
+		{
+			name:     "debian package",
+			filePath: "/tmp/app.deb",
+			data:     []byte("!<arch>\ndebian-binary   "),
+			expected: "Debian binary package",
+		},
+		{
+			name:     "ar archive",
+			filePath: "/usr/lib/libc.a",
+			data:     []byte("!<arch>\n/               "),
+			expected: "current ar archive",
+		},
+		{
+			name:     "jar with manifest",
+			filePath: "/app/app.zip",
+			data:     append([]byte("PK\x03\x04\x14\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x09\x00\x00\x00"), "META-INF/"...),
+			expected: "Java archive data (JAR)",
+		},
+		{
+			name:     "jar by extension",
+			filePath: "/app/app.jar",
+			data:     []byte("PK\x03\x04"),
+			expected: "Java archive data (JAR)",
+		},
+		{
+			name:     "python wheel",
+			filePath: "/app/app-1.0-py3-none-any.whl",
+			data:     []byte("PK\x03\x04"),
+			expected: "Zip archive data (Python package)",
+		},
+		{
+			name:     "zip",
+			filePath: "/app/data.zip",
+			data:     []byte("PK\x03\x04"),
+			expected: "Zip archive data",
+		},
+		//other binary data
+		{
+			name:     "java class",
+			filePath: "/app/Main.class",
+			data:     []byte{0xca, 0xfe, 0xba, 0xbe, 0x00, 0x00, 0x00, 0x37},
+			expected: "compiled Java class data, version 55.0",
+		},
+		{
+			name:     "python bytecode",
+			filePath: "/app/__pycache__/main.cpython-39.pyc",
+			data:     []byte{0x61, 0x0d, '\r', '\n'},
+			expected: "python 3.9 byte-compiled",
+		},
+		{
+			name:     "binary data",
+			filePath: "/app/data.bin",
+			data:     []byte{0x00, 0x01, 0x02, 0x03, 0xfe},
+			expected: TypeData,
+		},
+		//text
+		{
+			name:     "ascii text",
+			filePath: "/etc/hostname",
+			data:     []byte("localhost\n"),
+			expected: "ASCII text",
+		},
+		{
+			name:     "no line terminators",
+			filePath: "/etc/timezone",
+			data:     []byte("UTC"),
+			expected: "ASCII text, with no line terminators",
+		},
+		{
+			name:     "utf-8 text",
+			filePath: "/app/README",
+			data:     []byte("café\n"),
+			expected: encodingUTF8,
+		},
+		{
+			name:     "utf-8 text with BOM",
+			filePath: "/app/README",
+			data:     []byte("\xef\xbb\xbfcafé\n"),
+			expected: encodingUTF8BOM,
+		},
+		{
+			name:     "utf-16 text",
+			filePath: "/app/README",
+			data:     []byte{0xff, 0xfe, 'a', 0x00},
+			expected: encodingUTF16LE + ", with no line terminators",
+		},
+		{
+			name:     "iso-8859 text",
+			filePath: "/app/README",
+			data:     []byte("caf\xe9\n"),
+			expected: encodingISO8859,
+		},
+		{
+			name:     "pem certificate",
+			filePath: "/etc/ssl/certs/ca.pem",
+			data:     []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"),
+			expected: "PEM certificate",
+		},
+		{
+			name:     "xml",
+			filePath: "/app/config.xml",
+			data:     []byte("<?xml version=\"1.0\"?>\n<config/>\n"),
+			expected: "XML 1.0 document, ASCII text",
+		},
+		{
+			name:     "svg",
+			filePath: "/app/logo.svg",
+			data:     []byte("<?xml version=\"1.0\"?>\n<svg/>\n"),
+			expected: "SVG Scalable Vector Graphics image",
+		},
+		{
+			name:     "html",
+			filePath: "/app/index.html",
+			data:     []byte("<!DOCTYPE html>\n<html></html>\n"),
+			expected: "HTML document, ASCII text",
+		},
+		{
+			name:     "json",
+			filePath: "/app/package.json",
+			data:     []byte("{\"name\": \"app\"}\n"),
+			expected: "JSON data, ASCII text",
+		},
+		{
+			name:     "source by extension",
+			filePath: "/app/main.go",
+			data:     []byte("package main\n"),
+			expected: "Go source, ASCII text",
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			if dataType := DetectData(test.filePath, test.data, true); dataType != test.expected {
+				t.Errorf("got %q expected %q", dataType, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package filetype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+type magicMatcher struct {
+	offset   int
+	magic    []byte
+	describe func(filePath string, header []byte) string
+}
+
+func fixed(description string) func(string, []byte) string {
+	return func(string, []byte) string {
+		return description
+	}
+}
+
+var magicMatchers = []*magicMatcher{
+	//archives and compressed data
+	{0, []byte{0x1f, 0x8b}, fixed("gzip compressed data")},
+	{0, []byte("BZh"), fixed("bzip2 compressed data")},
+	{0, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, fixed("XZ compressed data")},
+	{0, []byte{0x28, 0xb5, 0x2f, 0xfd}, fixed("Zstandard compressed data")},
+	{0, []byte{0x04, 0x22, 0x4d, 0x18}, fixed("LZ4 compressed data")},
+	{0, []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}, fixed("7-zip archive data")},
+	{0, []byte("Rar!\x1a\x07"), fixed("RAR archive data")},
+	{0, []byte("!<arch>\ndebian-binary"), fixed("Debian binary package")},
+	{0, []byte("!<arch>\n"), fixed("current ar archive")},
+	{0, []byte{0xed, 0xab, 0xee, 0xdb}, fixed("RPM")},
+	{0, []byte("PK\x03\x04"), describeZip},
+	{0, []byte("PK\x05\x06"), fixed("Zip archive data (empty)")},
+	{257, []byte("ustar\x0000"), fixed("POSIX tar archive")},
+	{257, []byte("ustar  \x00"), fixed("POSIX tar archive (GNU)")},
+	//images
+	{0, []byte("\x89PNG\r\n\x1a\n"), describePNG},
+	{0, []byte("GIF87a"), describeGIF},
+	{0, []byte("GIF89a"), describeGIF},
+	{0, []byte{0xff, 0xd8, 0xff}, fixed("JPEG image data")},
+	{0, []byte{0x00, 0x00, 0x01, 0x00}, fixed("MS Windows icon resource")},
+	{0, []byte("BM"), describeBMP},
+	//certificates and keys
+	{0, []byte{0xfe, 0xed, 0xfe, 0xed}, fixed("Java KeyStore")},
+	{0, []byte{0xce, 0xce, 0xce, 0xce}, fixed("Java JCE KeyStore")},
+	//other binary formats
+	{0, []byte{0xca, 0xfe, 0xba, 0xbe}, describeJavaClass},
+	{0, []byte("SQLite format 3\x00"), fixed("SQLite 3.x database")},
+	{0, []byte("\x00asm"), describeWasm},
+	{0, []byte("\x7fELF"), fixed("ELF (malformed)")},
+	{0, []byte("MZ"), describePE},
+	{0, []byte{0xcf, 0xfa, 0xed, 0xfe}, fixed("Mach-O 64-bit executable")},
+	{0, []byte("%PDF-"), fixed("PDF document")},
+	{0, []byte("wOFF"), fixed("Web Open Font Format")},
+	{0, []byte("wOF2"), fixed("Web Open Font Format (Version 2)")},
+	{0, []byte{0x00, 0x01, 0x00, 0x00, 0x00}, fixed("TrueType Font data")},
+	{0, []byte("OTTO"), fixed("OpenType font data")},
+}
+
+func detectMagic(filePath string, header []byte) string {
+	if bytes.HasPrefix(header, []byte("RIFF")) && len(header) >= 12 {
+		switch string(header[8:12]) {
+		case "WEBP":
+			return "RIFF (little-endian) data, Web/P image"
+		case "WAVE":
+			return "RIFF (little-endian) data, WAVE audio"
+		default:
+			return "RIFF (little-endian) data"
+		}
+	}
+
+	for _, matcher := range magicMatchers {
+		end := matcher.offset + len(matcher.magic)
+		if len(header) >= end && bytes.Equal(header[matcher.offset:end], matcher.magic) {
+			if dataType := matcher.describe(filePath, header); dataType != "" {
+				return dataType
+			}
+		}
+	}
+
+	if dataType := detectPythonBytecode(header); dataType != "" {
+		return dataType
+	}
+
+	return ""
+}
+
+func describeZip(filePath string, header []byte) string {
+	//the first entry name in the local file header
+	if len(header) >= 30 {
+		nameLen := int(binary.LittleEndian.Uint16(header[26:28]))
+		if 30+nameLen <= len(header) {
+			name := string(header[30 : 30+nameLen])
+			switch {
+			case name == "mimetype":
+				return "Zip data (MIME type)"
+			case name == "META-INF/" || name == "META-INF/MANIFEST.MF":
+				return "Java archive data (JAR)"
+			}
+		}
+	}
+
+	switch {
+	case hasExt(filePath, ".jar", ".war", ".ear"):
+		return "Java archive data (JAR)"
+	case hasExt(filePath, ".whl", ".egg"):
+		return "Zip archive data (Python package)"
+	}
+
+	return "Zip archive data"
+}
+
+func describePNG(filePath string, header []byte) string {
+	//the IHDR chunk is the first chunk
+	if len(header) < 29 || string(header[12:16]) != "IHDR" {
+		return "PNG image data"
+	}
+
+	width := binary.BigEndian.Uint32(header[16:20])
+	height := binary.BigEndian.Uint32(header[20:24])
+	bitDepth := header[24]
+
+	var color string
+	switch header[25] {
+	case 0:
+		color = "grayscale"
+	case 2:
+		color = "RGB"
+	case 3:
+		color = "colormap"
+	case 4:
+		color = "gray+alpha"
+	case 6:
+		color = "RGBA"
+	default:
+		color = "unknown color type"
+	}
+
+	interlace := "non-interlaced"
+	if header[28] == 1 {
+		interlace = "interlaced"
+	}
+
+	return fmt.Sprintf("PNG image data, %d x %d, %d-bit/color %s, %s", width, height, bitDepth, color, interlace)
+}
+
+func describeGIF(filePath string, header []byte) string {
+	if len(header) < 10 {
+		return "GIF image data"
+	}
+
+	width := binary.LittleEndian.Uint16(header[6:8])
+	height := binary.LittleEndian.Uint16(header[8:10])
+	return fmt.Sprintf("GIF image data, version %s, %d x %d", header[3:6], width, height)
+}
+
+func describeBMP(filePath string, header []byte) string {
+	if len(header) < 26 {
+		return ""
+	}
+
+	//the DIB header size (the BITMAPINFOHEADER and newer)
+	dibSize := binary.LittleEndian.Uint32(header[14:18])
+	if dibSize != 12 && dibSize != 40 && dibSize != 108 && dibSize != 124 {
+		return ""
+	}
+
+	if dibSize == 12 {
+		return "PC bitmap, OS/2 1.x format"
+	}
+
+	width := int32(binary.LittleEndian.Uint32(header[18:22]))
+	height := int32(binary.LittleEndian.Uint32(header[22:26]))
+	if height < 0 {
+		height = -height
+	}
+
+	return fmt.Sprintf("PC bitmap, Windows 3.x format, %d x %d", width, height)
+}
+
+func describeJavaClass(filePath string, header []byte) string {
+	if len(header) < 8 {
+		return TypeData
+	}
+
+	minor := binary.BigEndian.Uint16(header[4:6])
+	major := binary.BigEndian.Uint16(header[6:8])
+	if major < 45 {
+		//the same magic is used by the Mach-O universal binaries (with a small architecture count)
+		return "Mach-O universal binary"
+	}
+
+	return fmt.Sprintf("compiled Java class data, version %d.%d", major, minor)
+}
+
+func describePE(filePath string, header []byte) string {
+	//the PE header offset is at 0x3c in the DOS header
+	if len(header) < 0x40 {
+		return ""
+	}
+
+	offset := int(binary.LittleEndian.Uint32(header[0x3c:0x40]))
+	if offset+4 > len(header) || string(header[offset:offset+4]) != "PE\x00\x00" {
+		return ""
+	}
+
+	return "PE32 executable (MS Windows)"
+}
+
+func describeWasm(filePath string, header []byte) string {
+	if len(header) < 8 {
+		return "WebAssembly (wasm) binary module"
+	}
+
+	return fmt.Sprintf("WebAssembly (wasm) binary module version 0x%x", binary.LittleEndian.Uint32(header[4:8]))
+}
+
+// Python bytecode magic numbers (the first two bytes, followed by '\r\n')
+var pythonBytecodeVersions = []struct {
+	first, last uint16
+	version     string
+}{
+	{62211, 62211, "2.7"},
+	{3000, 3131, "3.0"},
+	{3140, 3151, "3.1"},
+	{3160, 3180, "3.2"},
+	{3190, 3230, "3.3"},
+	{3250, 3310, "3.4"},
+	{3320, 3351, "3.5"},
+	{3360, 3379, "3.6"},
+	{3390, 3394, "3.7"},
+	{3400, 3413, "3.8"},
+	{3420, 3425, "3.9"},
+	{3430, 3439, "3.10"},
+	{3450, 3495, "3.11"},
+	{3500, 3531, "3.12"},
+	{3550, 3599, "3.13"},
+}
+
+func detectPythonBytecode(header []byte) string {
+	if len(header) < 4 || header[2] != '\r' || header[3] != '\n' {
+		return ""
+	}
+
+	magic := binary.LittleEndian.Uint16(header[0:2])
+	for _, v := range pythonBytecodeVersions {
+		if magic >= v.first && magic <= v.last {
+			return fmt.Sprintf("python %s byte-compiled", v.version)
+		}
+	}
+
+	return ""
+}
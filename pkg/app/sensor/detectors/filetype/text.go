@@ -0,0 +1,312 @@
+package filetype
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Text encoding descriptions
+const (
+	encodingASCII   = "ASCII text"
+	encodingUTF8    = "Unicode text, UTF-8 text"
+	encodingUTF8BOM = "Unicode text, UTF-8 (with BOM) text"
+	encodingUTF16LE = "Unicode text, UTF-16, little-endian text"
+	encodingUTF16BE = "Unicode text, UTF-16, big-endian text"
+	encodingISO8859 = "ISO-8859 text"
+)
+
+const longLineSize = 300
+
+var pemTypes = []struct {
+	label       string
+	description string
+}{
+	{"CERTIFICATE REQUEST", "PEM certificate request"},
+	{"NEW CERTIFICATE REQUEST", "PEM certificate request"},
+	{"TRUSTED CERTIFICATE", "PEM certificate"},
+	{"X509 CRL", "PEM certificate revocation list"},
+	{"CERTIFICATE", "PEM certificate"},
+	{"RSA PRIVATE KEY", "PEM RSA private key"},
+	{"EC PRIVATE KEY", "PEM EC private key"},
+	{"DSA PRIVATE KEY", "PEM DSA private key"},
+	{"OPENSSH PRIVATE KEY", "OpenSSH private key"},
+	{"ENCRYPTED PRIVATE KEY", "PEM encrypted private key"},
+	{"PRIVATE KEY", "PEM private key"},
+	{"PUBLIC KEY", "PEM public key"},
+	{"PGP PUBLIC KEY BLOCK", "PGP public key block"},
+	{"PGP SIGNATURE", "PGP signature"},
+}
+
+var interpreterScriptTypes = map[string]string{
+	"sh":      "POSIX shell script",
+	"dash":    "POSIX shell script",
+	"ash":     "POSIX shell script",
+	"bash":    "Bourne-Again shell script",
+	"zsh":     "Paul Falstad's zsh script",
+	"ksh":     "Korn shell script",
+	"csh":     "C shell script",
+	"tcsh":    "Tenex C shell script",
+	"python":  "Python script",
+	"perl":    "Perl script",
+	"ruby":    "Ruby script",
+	"node":    "Node.js script",
+	"nodejs":  "Node.js script",
+	"php":     "PHP script",
+	"lua":     "Lua script",
+	"awk":     "awk script",
+	"gawk":    "GNU awk script",
+	"make":    "makefile script",
+	"tclsh":   "Tcl script",
+	"wish":    "Tcl/Tk script",
+	"Rscript": "R script",
+}
+
+// detectText describes the text data (scripts, certificates, markup and plain text with its encoding)
+func detectText(filePath string, header []byte, complete bool) string {
+	encoding, body := textEncoding(header, complete)
+	if encoding == "" {
+		return ""
+	}
+
+	var kind string
+	if bytes.HasPrefix(body, []byte("#!")) {
+		kind = scriptType(body)
+		if kind != "" {
+			return fmt.Sprintf("%s, %s executable%s", kind, encoding, lineInfo(body))
+		}
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN ")):
+		if kind = pemType(trimmed); kind != "" {
+			return kind
+		}
+	case bytes.HasPrefix(trimmed, []byte("<?xml")):
+		if bytes.Contains(trimmed, []byte("<svg")) {
+			return "SVG Scalable Vector Graphics image"
+		}
+
+		kind = "XML 1.0 document"
+	case bytes.HasPrefix(trimmed, []byte("<svg")):
+		return "SVG Scalable Vector Graphics image"
+	case hasPrefixFold(trimmed, "<!doctype html") || hasPrefixFold(trimmed, "<html"):
+		kind = "HTML document"
+	case (bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("["))) && hasExt(filePath, ".json"):
+		kind = "JSON data"
+	}
+
+	if kind == "" {
+		kind = sourceType(filePath)
+	}
+
+	if kind != "" {
+		return fmt.Sprintf("%s, %s%s", kind, encoding, lineInfo(body))
+	}
+
+	return fmt.Sprintf("%s%s", encoding, lineInfo(body))
+}
+
+// textEncoding returns the text encoding description and the text data (without the BOM)
+// or an empty string if the data is not text
+func textEncoding(data []byte, complete bool) (string, []byte) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		if isText(data[3:], complete) {
+			return encodingUTF8BOM, data[3:]
+		}
+
+		return "", nil
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		return encodingUTF16LE, nil
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return encodingUTF16BE, nil
+	}
+
+	if !isText(data, complete) {
+		if isISO8859(data) {
+			return encodingISO8859, data
+		}
+
+		return "", nil
+	}
+
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return encodingUTF8, data
+		}
+	}
+
+	return encodingASCII, data
+}
+
+// isText checks if the data is valid UTF-8 text without the control characters (except the common whitespace ones)
+func isText(data []byte, complete bool) bool {
+	if !complete {
+		//the header can end in the middle of a multi-byte sequence
+		for i := 0; i < utf8.UTFMax && len(data) > 0; i++ {
+			if r, _ := utf8.DecodeLastRune(data); r != utf8.RuneError {
+				break
+			}
+
+			data = data[:len(data)-1]
+		}
+	}
+
+	if !utf8.Valid(data) {
+		return false
+	}
+
+	for _, b := range data {
+		if isControlChar(b) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isISO8859(data []byte) bool {
+	for _, b := range data {
+		if isControlChar(b) || (b >= 0x80 && b < 0xa0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isControlChar(b byte) bool {
+	if b >= 0x20 && b != 0x7f {
+		return false
+	}
+
+	switch b {
+	case '\t', '\n', '\r', '\f', '\v', 0x1b:
+		return false
+	}
+
+	return true
+}
+
+func lineInfo(data []byte) string {
+	var info []string
+	maxLine := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > maxLine {
+			maxLine = len(line)
+		}
+	}
+
+	if maxLine > longLineSize {
+		info = append(info, fmt.Sprintf("with very long lines (%d)", maxLine))
+	}
+
+	switch {
+	case bytes.Contains(data, []byte("\r\n")):
+		info = append(info, "with CRLF line terminators")
+	case !bytes.Contains(data, []byte("\n")):
+		info = append(info, "with no line terminators")
+	}
+
+	if len(info) == 0 {
+		return ""
+	}
+
+	return ", " + strings.Join(info, ", ")
+}
+
+// scriptType returns the script type based on the shebang interpreter
+func scriptType(data []byte) string {
+	line := data[2:]
+	if idx := bytes.IndexByte(line, '\n'); idx > -1 {
+		line = line[:idx]
+	}
+
+	fields := strings.Fields(strings.TrimSpace(string(line)))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interp := filepath.Base(fields[0])
+	if interp == "env" {
+		//'#!/usr/bin/env [-S] interp'
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interp = field
+				break
+			}
+		}
+
+		if interp == "env" {
+			return ""
+		}
+
+		if kind := interpreterType(interp); kind != "" {
+			return kind
+		}
+
+		return fmt.Sprintf("a /usr/bin/env %s script", interp)
+	}
+
+	if kind := interpreterType(interp); kind != "" {
+		return kind
+	}
+
+	return fmt.Sprintf("a %s script", fields[0])
+}
+
+func interpreterType(interp string) string {
+	if kind, found := interpreterScriptTypes[interp]; found {
+		return kind
+	}
+
+	//versioned interpreters (e.g., 'python3.9', 'perl5.30', 'ruby2.7')
+	name := strings.TrimRight(interp, "0123456789.")
+	return interpreterScriptTypes[name]
+}
+
+func pemType(data []byte) string {
+	line := data[len("-----BEGIN "):]
+	if idx := bytes.Index(line, []byte("-----")); idx > -1 {
+		label := string(line[:idx])
+		for _, pt := range pemTypes {
+			if label == pt.label {
+				return pt.description
+			}
+		}
+	}
+
+	return ""
+}
+
+var sourceExtTypes = map[string]string{
+	".py":   "Python script",
+	".rb":   "Ruby script",
+	".js":   "JavaScript source",
+	".mjs":  "JavaScript source",
+	".cjs":  "JavaScript source",
+	".ts":   "TypeScript source",
+	".java": "Java source",
+	".go":   "Go source",
+	".c":    "C source",
+	".h":    "C header",
+	".cc":   "C++ source",
+	".cpp":  "C++ source",
+	".php":  "PHP script",
+	".pl":   "Perl script",
+	".pm":   "Perl5 module source",
+	".sh":   "POSIX shell script",
+	".css":  "CSS source",
+}
+
+func sourceType(filePath string) string {
+	return sourceExtTypes[strings.ToLower(filepath.Ext(filePath))]
+}
+
+func hasPrefixFold(data []byte, prefix string) bool {
+	return len(data) >= len(prefix) && strings.EqualFold(string(data[:len(prefix)]), prefix)
+}
@@ -0,0 +1,84 @@
+package appdeps
+
+import (
+	"sort"
+	"strings"
+)
+
+// Application language names (same values as the certdiscover language names)
+const (
+	LanguagePython = "python"
+	LanguageNode   = "node.js"
+	LanguageRuby   = "ruby"
+	LanguageJava   = "java"
+	LanguageGo     = "go"
+)
+
+// NestedPathSep separates the archive path and the nested archive path in the dependency paths
+const NestedPathSep = "!/"
+
+// Dependency is a language level application dependency
+type Dependency struct {
+	Language string
+	Name     string
+	Version  string
+	//Path is the dependency location (package directory, archive or binary)
+	Path string
+	//Files are the known dependency files (if the file list is available)
+	Files []string
+}
+
+// Key returns the unique dependency key
+func (ref *Dependency) Key() string {
+	return strings.Join([]string{ref.Language, ref.Name, ref.Version, ref.Path}, "|")
+}
+
+// Touched checks if any of the dependency files is in the accessed file set
+// (a dependency directory is touched if any file in it is accessed,
+// accessedSorted is the sorted list of the accessed file paths)
+func (ref *Dependency) Touched(accessed map[string]struct{}, accessedSorted []string) bool {
+	if len(ref.Files) == 0 {
+		return isTouched(ref.Path, accessed, accessedSorted)
+	}
+
+	for _, name := range ref.Files {
+		if isTouched(name, accessed, accessedSorted) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTouched(name string, accessed map[string]struct{}, accessedSorted []string) bool {
+	if idx := strings.Index(name, NestedPathSep); idx > -1 {
+		//nested archive dependencies are touched when the outer archive is touched
+		name = name[:idx]
+	}
+
+	if _, found := accessed[name]; found {
+		return true
+	}
+
+	dirPrefix := strings.TrimSuffix(name, "/") + "/"
+	idx := sort.SearchStrings(accessedSorted, dirPrefix)
+	return idx < len(accessedSorted) && strings.HasPrefix(accessedSorted[idx], dirPrefix)
+}
+
+// Dedupe removes the duplicate dependencies
+// (keeping the first instance and preserving the order)
+func Dedupe(deps []*Dependency) []*Dependency {
+	seen := map[string]struct{}{}
+	var result []*Dependency
+	for _, dep := range deps {
+		key := dep.Key()
+		if _, found := seen[key]; found {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		result = append(result, dep)
+	}
+
+	return result
+}
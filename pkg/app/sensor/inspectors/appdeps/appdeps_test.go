@@ -0,0 +1,116 @@
+package appdeps
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeTestFiles creates the test files (and their parent directories) in the directory
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		fullPath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(fullPath, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "appdeps")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+// depStrings returns the sorted 'name@version path' dependency strings
+// (with the paths relative to the base directory)
+func depStrings(t *testing.T, base string, deps []*Dependency) []string {
+	var result []string
+	for _, dep := range deps {
+		depPath := dep.Path
+		if base != "" {
+			rel, err := filepath.Rel(base, dep.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			depPath = rel
+		}
+
+		result = append(result, dep.Language+":"+dep.Name+"@"+dep.Version+" "+depPath)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+func TestDependencyTouched(t *testing.T) {
+	accessed := map[string]struct{}{
+		"/app/node_modules/express/index.js":    {},
+		"/app/lib/app.jar":                      {},
+		"/usr/lib/python3/site-packages/six.py": {},
+	}
+
+	var accessedSorted []string
+	for name := range accessed {
+		accessedSorted = append(accessedSorted, name)
+	}
+
+	sort.Strings(accessedSorted)
+
+	tt := []struct {
+		dep      Dependency
+		expected bool
+	}{
+		{dep: Dependency{Path: "/app/node_modules/express"}, expected: true},
+		{dep: Dependency{Path: "/app/node_modules/express/"}, expected: true},
+		//not a directory prefix
+		{dep: Dependency{Path: "/app/node_modules/expr"}},
+		{dep: Dependency{Path: "/app/lib/app.jar!/BOOT-INF/lib/dep-1.0.jar"}, expected: true},
+		{dep: Dependency{Path: "/app/lib/other.jar"}},
+		{
+			dep: Dependency{
+				Path:  "/usr/lib/python3/site-packages/six-1.16.0.dist-info",
+				Files: []string{"/usr/lib/python3/site-packages/six.py"},
+			},
+			expected: true,
+		},
+		{
+			//the package files are used instead of the package path
+			dep: Dependency{
+				Path:  "/usr/lib/python3/site-packages",
+				Files: []string{"/usr/lib/python3/site-packages/idna/__init__.py"},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		if got := test.dep.Touched(accessed, accessedSorted); got != test.expected {
+			t.Errorf("%s: got %v expected %v", test.dep.Path, got, test.expected)
+		}
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	deps := []*Dependency{
+		{Language: LanguageNode, Name: "a", Version: "1.0.0", Path: "/app/node_modules/a"},
+		{Language: LanguageNode, Name: "b", Version: "1.0.0", Path: "/app/node_modules/b"},
+		{Language: LanguageNode, Name: "a", Version: "1.0.0", Path: "/app/node_modules/a", Files: []string{"x"}},
+		{Language: LanguageNode, Name: "a", Version: "2.0.0", Path: "/app/node_modules/a"},
+	}
+
+	expected := []*Dependency{deps[0], deps[1], deps[3]}
+	if got := Dedupe(deps); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v expected %v", got, expected)
+	}
+}
@@ -0,0 +1,207 @@
+package appdeps
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Go build info errors
+var (
+	ErrGoBuildInfoNotFound  = errors.New("go build info not found")
+	ErrGoBuildInfoMalformed = errors.New("malformed go build info")
+)
+
+const (
+	goBuildInfoSection = ".go.buildinfo"
+	goBuildInfoAlign   = 16
+	goBuildInfoHdrSize = 32
+	goModInfoDep       = "dep"
+	goModInfoReplace   = "=>"
+)
+
+var goBuildInfoMagic = []byte("\xff Go buildinf:")
+
+// GoBuildInfo is the build info embedded in the Go binaries
+type GoBuildInfo struct {
+	GoVersion string
+	Path      string
+	Deps      []*Dependency
+}
+
+// GoModules returns the Go modules compiled into the Go binary (from the embedded build info)
+func GoModules(binPath string) ([]*Dependency, error) {
+	info, err := ReadGoBuildInfo(binPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Deps, nil
+}
+
+// ReadGoBuildInfo reads the build info from the Go ELF binary
+// (without using 'debug/buildinfo' to support the older Go toolchains)
+func ReadGoBuildInfo(binPath string) (*GoBuildInfo, error) {
+	ef, err := elf.Open(binPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer ef.Close()
+
+	data, err := goBuildInfoData(ef)
+	if err != nil {
+		return nil, err
+	}
+
+	ptrSize := int(data[len(goBuildInfoMagic)])
+	flags := data[len(goBuildInfoMagic)+1]
+
+	var byteOrder binary.ByteOrder = binary.LittleEndian
+	if flags&0x1 != 0 {
+		byteOrder = binary.BigEndian
+	}
+
+	var version, modinfo string
+	if flags&0x2 != 0 {
+		//Go 1.18+ (inline varint length prefixed strings)
+		rest := data[goBuildInfoHdrSize:]
+		version, rest = readVarintString(rest)
+		modinfo, _ = readVarintString(rest)
+	} else {
+		//older Go versions (pointers to the string headers)
+		if ptrSize != 4 && ptrSize != 8 || len(data) < 16+2*ptrSize {
+			return nil, ErrGoBuildInfoMalformed
+		}
+
+		readPtr := func(b []byte) uint64 {
+			if ptrSize == 4 {
+				return uint64(byteOrder.Uint32(b))
+			}
+
+			return byteOrder.Uint64(b)
+		}
+
+		readString := func(addr uint64) string {
+			hdr := readELFAddr(ef, addr, uint64(2*ptrSize))
+			if len(hdr) < 2*ptrSize {
+				return ""
+			}
+
+			strData := readELFAddr(ef, readPtr(hdr), readPtr(hdr[ptrSize:]))
+			return string(strData)
+		}
+
+		version = readString(readPtr(data[16:]))
+		modinfo = readString(readPtr(data[16+ptrSize:]))
+	}
+
+	if version == "" {
+		return nil, ErrGoBuildInfoMalformed
+	}
+
+	info := &GoBuildInfo{GoVersion: version}
+	info.Path, info.Deps = parseGoModInfo(binPath, modinfo)
+	return info, nil
+}
+
+func goBuildInfoData(ef *elf.File) ([]byte, error) {
+	if section := ef.Section(goBuildInfoSection); section != nil {
+		data, err := section.Data()
+		if err == nil && bytes.HasPrefix(data, goBuildInfoMagic) && len(data) >= goBuildInfoHdrSize {
+			return data, nil
+		}
+	}
+
+	//the build info section may not exist (e.g., in stripped or packed binaries),
+	//so looking for the build info in the writable data sections
+	for _, section := range ef.Sections {
+		if section.Type != elf.SHT_PROGBITS || section.Flags&elf.SHF_WRITE == 0 {
+			continue
+		}
+
+		data, err := section.Data()
+		if err != nil {
+			continue
+		}
+
+		for offset := 0; offset+goBuildInfoHdrSize <= len(data); offset += goBuildInfoAlign {
+			idx := bytes.Index(data[offset:], goBuildInfoMagic)
+			if idx < 0 {
+				break
+			}
+
+			offset += idx
+			if offset%goBuildInfoAlign == 0 && offset+goBuildInfoHdrSize <= len(data) {
+				return data[offset:], nil
+			}
+		}
+	}
+
+	return nil, ErrGoBuildInfoNotFound
+}
+
+func readELFAddr(ef *elf.File, addr, size uint64) []byte {
+	for _, prog := range ef.Progs {
+		//(checking the size first, so the malformed sizes don't overflow the end address)
+		if prog.Type != elf.PT_LOAD || addr < prog.Vaddr || size > prog.Filesz || addr-prog.Vaddr > prog.Filesz-size {
+			continue
+		}
+
+		data := make([]byte, size)
+		if _, err := prog.ReadAt(data, int64(addr-prog.Vaddr)); err != nil && err != io.EOF {
+			return nil
+		}
+
+		return data
+	}
+
+	return nil
+}
+
+func readVarintString(data []byte) (string, []byte) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return "", nil
+	}
+
+	return string(data[n : n+int(size)]), data[n+int(size):]
+}
+
+// parseGoModInfo parses the module info (the main package path and the module dependencies)
+func parseGoModInfo(binPath, modinfo string) (string, []*Dependency) {
+	//the module info is wrapped with 16 byte sentinel values
+	if len(modinfo) >= 33 && modinfo[len(modinfo)-17] == '\n' {
+		modinfo = modinfo[16 : len(modinfo)-16]
+	}
+
+	var (
+		mainPath string
+		deps     []*Dependency
+	)
+
+	for _, line := range strings.Split(modinfo, "\n") {
+		fields := strings.Split(line, "\t")
+		switch {
+		case len(fields) >= 2 && fields[0] == "path":
+			mainPath = fields[1]
+		case len(fields) >= 3 && fields[0] == goModInfoDep:
+			deps = append(deps, &Dependency{
+				Language: LanguageGo,
+				Name:     fields[1],
+				Version:  fields[2],
+				Path:     binPath,
+			})
+		case len(fields) >= 3 && fields[0] == goModInfoReplace && len(deps) > 0:
+			//the replacement applies to the previous dependency
+			last := deps[len(deps)-1]
+			last.Name = fields[1]
+			last.Version = fields[2]
+		}
+	}
+
+	return mainPath, deps
+}
@@ -0,0 +1,287 @@
+package appdeps
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+const testGoDataAddr = 0x1000
+
+// testGoELF creates a minimal 64-bit ELF file with a writable data section
+// (loaded at testGoDataAddr, so the old build info pointers can reference the section data)
+func testGoELF(sectionName string, data []byte) []byte {
+	const (
+		ehdrSize = 64
+		phdrSize = 56
+		shdrSize = 64
+	)
+
+	shstrtab := []byte("\x00" + sectionName + "\x00.shstrtab\x00")
+	dataOffset := uint64(testGoDataAddr)
+	shstrtabOffset := dataOffset + uint64(len(data))
+	shOffset := (shstrtabOffset + uint64(len(shstrtab)) + 7) &^ 7
+
+	progs := []elf.Prog64{
+		{
+			Type:   uint32(elf.PT_LOAD),
+			Flags:  uint32(elf.PF_R | elf.PF_W),
+			Off:    dataOffset,
+			Vaddr:  testGoDataAddr,
+			Filesz: uint64(len(data)),
+			Memsz:  uint64(len(data)),
+			Align:  0x1000,
+		},
+	}
+
+	sections := []elf.Section64{
+		{},
+		{
+			Name:      1,
+			Type:      uint32(elf.SHT_PROGBITS),
+			Flags:     uint64(elf.SHF_ALLOC | elf.SHF_WRITE),
+			Addr:      testGoDataAddr,
+			Off:       dataOffset,
+			Size:      uint64(len(data)),
+			Addralign: 16,
+		},
+		{
+			Name:      uint32(len(sectionName) + 2),
+			Type:      uint32(elf.SHT_STRTAB),
+			Off:       shstrtabOffset,
+			Size:      uint64(len(shstrtab)),
+			Addralign: 1,
+		},
+	}
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     ehdrSize,
+		Shoff:     shOffset,
+		Ehsize:    ehdrSize,
+		Phentsize: phdrSize,
+		Phnum:     uint16(len(progs)),
+		Shentsize: shdrSize,
+		Shnum:     uint16(len(sections)),
+		Shstrndx:  2,
+	}
+
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var buf bytes.Buffer
+	write := func(data interface{}) {
+		_ = binary.Write(&buf, binary.LittleEndian, data)
+	}
+
+	pad := func(offset uint64) {
+		for uint64(buf.Len()) < offset {
+			buf.WriteByte(0)
+		}
+	}
+
+	write(&header)
+	write(progs)
+	pad(dataOffset)
+	buf.Write(data)
+	buf.Write(shstrtab)
+	pad(shOffset)
+	write(sections)
+	return buf.Bytes()
+}
+
+// goBuildInfoHeader creates the build info header (magic, pointer size and flags)
+func goBuildInfoHeader(ptrSize, flags byte) []byte {
+	hdr := make([]byte, goBuildInfoHdrSize)
+	copy(hdr, goBuildInfoMagic)
+	hdr[len(goBuildInfoMagic)] = ptrSize
+	hdr[len(goBuildInfoMagic)+1] = flags
+	return hdr
+}
+
+// goBuildInfoInline creates the Go 1.18+ build info (with the inline strings)
+func goBuildInfoInline(version, modinfo string) []byte {
+	data := goBuildInfoHeader(8, 0x2)
+	for _, s := range []string{version, modinfo} {
+		data = append(data, make([]byte, binary.MaxVarintLen64)...)
+		n := binary.PutUvarint(data[len(data)-binary.MaxVarintLen64:], uint64(len(s)))
+		data = append(data[:len(data)-binary.MaxVarintLen64+n], s...)
+	}
+
+	return data
+}
+
+// goBuildInfoPointers creates the pre Go 1.18 build info
+// (with the pointers to the string headers, the string lengths can be overridden)
+func goBuildInfoPointers(version, modinfo string, lengths ...uint64) []byte {
+	const (
+		versionHdr = goBuildInfoHdrSize
+		modinfoHdr = versionHdr + 16
+		strData    = modinfoHdr + 16
+	)
+
+	if len(lengths) == 0 {
+		lengths = []uint64{uint64(len(version)), uint64(len(modinfo))}
+	}
+
+	data := goBuildInfoHeader(8, 0)
+	data = append(data, make([]byte, strData-goBuildInfoHdrSize)...)
+	binary.LittleEndian.PutUint64(data[16:], testGoDataAddr+versionHdr)
+	binary.LittleEndian.PutUint64(data[24:], testGoDataAddr+modinfoHdr)
+	binary.LittleEndian.PutUint64(data[versionHdr:], testGoDataAddr+strData)
+	binary.LittleEndian.PutUint64(data[versionHdr+8:], lengths[0])
+	binary.LittleEndian.PutUint64(data[modinfoHdr:], testGoDataAddr+strData+uint64(len(version)))
+	binary.LittleEndian.PutUint64(data[modinfoHdr+8:], lengths[1])
+	return append(data, version+modinfo...)
+}
+
+// testModInfo wraps the module info with the sentinel values
+func testModInfo(lines string) string {
+	sentinel := "0123456789abcdef"
+	return sentinel + lines + sentinel
+}
+
+func TestReadGoBuildInfo(t *testing.T) {
+	modinfo := testModInfo("path\texample.com/app\n" +
+		"mod\texample.com/app\t(devel)\t\n" +
+		"dep\tgithub.com/pkg/errors\tv0.9.1\th1:abc=\n" +
+		"dep\tgolang.org/x/sys\tv0.1.0\th1:def=\n" +
+		"=>\tgithub.com/fork/sys\tv0.1.1\th1:ghi=\n" +
+		"build\tCGO_ENABLED=0\n")
+
+	expectedDeps := []string{
+		"go:github.com/fork/sys@v0.1.1 bin",
+		"go:github.com/pkg/errors@v0.9.1 bin",
+	}
+
+	//the build info is aligned in the data sections without the build info section
+	//(the unaligned magic values are skipped)
+	scanned := append(make([]byte, 48), goBuildInfoInline("go1.19.3", modinfo)...)
+	copy(scanned[8:], goBuildInfoMagic)
+
+	tt := []struct {
+		desc      string
+		data      []byte
+		section   string
+		version   string
+		path      string
+		deps      []string
+		err       error
+		checkErrs bool
+	}{
+		{desc: "inline strings", section: goBuildInfoSection, data: goBuildInfoInline("go1.19.3", modinfo), version: "go1.19.3", path: "example.com/app", deps: expectedDeps},
+		{desc: "string pointers", section: goBuildInfoSection, data: goBuildInfoPointers("go1.16.15", modinfo), version: "go1.16.15", path: "example.com/app", deps: expectedDeps},
+		{desc: "data section", section: ".data", data: scanned, version: "go1.19.3", path: "example.com/app", deps: expectedDeps},
+		{desc: "no module info", section: goBuildInfoSection, data: goBuildInfoInline("go1.19.3", ""), version: "go1.19.3"},
+		{desc: "no build info", section: ".data", data: make([]byte, 64), err: ErrGoBuildInfoNotFound},
+		{desc: "truncated header", section: goBuildInfoSection, data: goBuildInfoHeader(8, 0x2)[:20], err: ErrGoBuildInfoNotFound},
+		{desc: "truncated inline version", section: goBuildInfoSection, data: append(goBuildInfoHeader(8, 0x2), 0x7f, 'g', 'o'), err: ErrGoBuildInfoMalformed},
+		{desc: "malformed varint", section: goBuildInfoSection, data: append(goBuildInfoHeader(8, 0x2), bytes.Repeat([]byte{0xff}, 12)...), err: ErrGoBuildInfoMalformed},
+		{desc: "bad pointer size", section: goBuildInfoSection, data: goBuildInfoHeader(3, 0), err: ErrGoBuildInfoMalformed},
+		{desc: "string out of range", section: goBuildInfoSection, data: goBuildInfoPointers("go1.16.15", modinfo, 1024, 0), err: ErrGoBuildInfoMalformed},
+		{desc: "overflowing string length", section: goBuildInfoSection, data: goBuildInfoPointers("go1.16.15", modinfo, ^uint64(0), 1), err: ErrGoBuildInfoMalformed},
+	}
+
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	binPath := filepath.Join(dir, "bin")
+	for _, test := range tt {
+		if err := ioutil.WriteFile(binPath, testGoELF(test.section, test.data), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := ReadGoBuildInfo(binPath)
+		if err != test.err {
+			t.Errorf("%s: got error %v expected %v", test.desc, err, test.err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if info.GoVersion != test.version || info.Path != test.path {
+			t.Errorf("%s: got %q/%q expected %q/%q", test.desc, info.GoVersion, info.Path, test.version, test.path)
+		}
+
+		if got := depStrings(t, dir, info.Deps); !reflect.DeepEqual(got, test.deps) {
+			t.Errorf("%s:\ngot      %q\nexpected %q", test.desc, got, test.deps)
+		}
+	}
+
+	notELF := filepath.Join(dir, "script.sh")
+	if err := ioutil.WriteFile(notELF, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GoModules(notELF); err == nil {
+		t.Errorf("expected an error for a non-ELF file")
+	}
+}
+
+func TestReadGoBuildInfoTestBinary(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the test binary is not an ELF binary")
+	}
+
+	binPath, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ReadGoBuildInfo(binPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("got Go version %q expected %q", info.GoVersion, runtime.Version())
+	}
+}
+
+func TestParseGoModInfo(t *testing.T) {
+	tt := []struct {
+		desc    string
+		modinfo string
+		path    string
+		deps    []string
+	}{
+		{
+			desc:    "no sentinels",
+			modinfo: "path\tcmd/app\ndep\tgithub.com/a/b\tv1.0.0\n",
+			path:    "cmd/app",
+			deps:    []string{"go:github.com/a/b@v1.0.0 /bin/app"},
+		},
+		{
+			desc:    "replacement without a dependency",
+			modinfo: "=>\t../local\t(devel)\ndep\tgithub.com/a/b\tv1.0.0\t\n=>\t../b\t\t\n",
+			deps:    []string{"go:../b@ /bin/app"},
+		},
+		{
+			desc:    "malformed lines",
+			modinfo: "path\ndep\tonly-name\n\t\t\n\x00\xff",
+		},
+	}
+
+	for _, test := range tt {
+		path, deps := parseGoModInfo("/bin/app", test.modinfo)
+		if path != test.path {
+			t.Errorf("%s: got path %q expected %q", test.desc, path, test.path)
+		}
+
+		if got := depStrings(t, "", deps); !reflect.DeepEqual(got, test.deps) {
+			t.Errorf("%s:\ngot      %q\nexpected %q", test.desc, got, test.deps)
+		}
+	}
+}
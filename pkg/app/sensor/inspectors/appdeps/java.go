@@ -0,0 +1,212 @@
+package appdeps
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	javaManifestFile     = "META-INF/MANIFEST.MF"
+	javaMavenDir         = "META-INF/maven/"
+	javaPomPropsFile     = "pom.properties"
+	javaJarExt           = ".jar"
+	javaWarExt           = ".war"
+	javaEarExt           = ".ear"
+	javaNestedLibDirBoot = "BOOT-INF/lib/"
+	javaNestedLibDirWeb  = "WEB-INF/lib/"
+)
+
+// IsJavaArchive checks if the file is a Java archive (jar, war or ear)
+func IsJavaArchive(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case javaJarExt, javaWarExt, javaEarExt:
+		return true
+	default:
+		return false
+	}
+}
+
+// JavaArchive returns the Java libraries in the archive
+// (the archive itself, the embedded Maven artifacts and the nested library archives)
+func JavaArchive(archivePath string) ([]*Dependency, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	return javaArchiveDeps(archivePath, &reader.Reader, true), nil
+}
+
+func javaArchiveDeps(archivePath string, reader *zip.Reader, nested bool) []*Dependency {
+	var (
+		deps       []*Dependency
+		nestedDeps []*Dependency
+		manifest   map[string]string
+	)
+
+	for _, file := range reader.File {
+		switch {
+		case file.Name == javaManifestFile:
+			if raw, err := readZipFile(file); err == nil {
+				manifest = ParseJavaManifest(raw)
+			}
+		case strings.HasPrefix(file.Name, javaMavenDir) && path.Base(file.Name) == javaPomPropsFile:
+			raw, err := readZipFile(file)
+			if err != nil {
+				continue
+			}
+
+			props := parseJavaProperties(raw)
+			if props["artifactId"] == "" {
+				continue
+			}
+
+			name := props["artifactId"]
+			if props["groupId"] != "" {
+				name = props["groupId"] + ":" + name
+			}
+
+			deps = append(deps, &Dependency{
+				Language: LanguageJava,
+				Name:     name,
+				Version:  props["version"],
+				Path:     archivePath,
+			})
+		case nested && IsJavaArchive(file.Name) &&
+			(strings.HasPrefix(file.Name, javaNestedLibDirBoot) || strings.HasPrefix(file.Name, javaNestedLibDirWeb)):
+			//nested library archives (Spring Boot and web applications)
+			nestedPath := archivePath + NestedPathSep + file.Name
+			var libDeps []*Dependency
+			if raw, err := readZipFile(file); err == nil {
+				if nestedReader, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw))); err == nil {
+					libDeps = javaArchiveDeps(nestedPath, nestedReader, false)
+				}
+			}
+
+			if len(libDeps) == 0 {
+				name, version := splitJavaArchiveName(path.Base(file.Name))
+				libDeps = append(libDeps, &Dependency{
+					Language: LanguageJava,
+					Name:     name,
+					Version:  version,
+					Path:     nestedPath,
+				})
+			}
+
+			nestedDeps = append(nestedDeps, libDeps...)
+		}
+	}
+
+	if len(deps) == 0 {
+		//using the manifest info if the Maven artifact info is not available
+		name, version := javaManifestNameVersion(manifest)
+		if name == "" {
+			name, version = splitJavaArchiveName(path.Base(archivePath))
+		}
+
+		deps = append(deps, &Dependency{
+			Language: LanguageJava,
+			Name:     name,
+			Version:  version,
+			Path:     archivePath,
+		})
+	}
+
+	return append(deps, nestedDeps...)
+}
+
+func javaManifestNameVersion(manifest map[string]string) (string, string) {
+	for _, keys := range [][2]string{
+		{"Bundle-SymbolicName", "Bundle-Version"},
+		{"Implementation-Title", "Implementation-Version"},
+		{"Specification-Title", "Specification-Version"},
+		{"Automatic-Module-Name", "Implementation-Version"},
+	} {
+		if name := manifest[keys[0]]; name != "" {
+			//the OSGi bundle names can have directives ('name;singleton:=true')
+			if idx := strings.Index(name, ";"); idx > -1 {
+				name = name[:idx]
+			}
+
+			return strings.TrimSpace(name), manifest[keys[1]]
+		}
+	}
+
+	return "", ""
+}
+
+// ParseJavaManifest parses the main section of the JAR manifest
+func ParseJavaManifest(raw []byte) map[string]string {
+	attrs := map[string]string{}
+	var lastKey string
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			//the main section ends with an empty line
+			break
+		}
+
+		if strings.HasPrefix(line, " ") {
+			//continuation line (the manifest lines are limited to 72 bytes)
+			if lastKey != "" {
+				attrs[lastKey] += line[1:]
+			}
+
+			continue
+		}
+
+		if idx := strings.Index(line, ":"); idx > 0 {
+			lastKey = line[:idx]
+			attrs[lastKey] = strings.TrimSpace(line[idx+1:])
+		}
+	}
+
+	return attrs
+}
+
+func parseJavaProperties(raw []byte) map[string]string {
+	props := map[string]string{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		if idx := strings.IndexAny(line, "=:"); idx > 0 {
+			props[strings.TrimSpace(line[:idx])] = strings.TrimSpace(line[idx+1:])
+		}
+	}
+
+	return props
+}
+
+// splitJavaArchiveName splits the archive file name ('name-version.jar')
+func splitJavaArchiveName(fileName string) (string, string) {
+	base := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	parts := strings.Split(base, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" && parts[i][0] >= '0' && parts[i][0] <= '9' {
+			return strings.Join(parts[:i], "-"), strings.Join(parts[i:], "-")
+		}
+	}
+
+	return base, ""
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
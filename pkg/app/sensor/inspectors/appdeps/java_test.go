@@ -0,0 +1,224 @@
+package appdeps
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testZip creates a zip archive with the files
+func testZip(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, data := range files {
+		fw, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := fw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestParseJavaManifest(t *testing.T) {
+	tt := []struct {
+		desc     string
+		raw      string
+		expected map[string]string
+	}{
+		{
+			desc: "main section",
+			raw: "Manifest-Version: 1.0\r\n" +
+				"Bundle-SymbolicName: com.fasterxml.jackson.core.jackson-databind;sin\r\n" +
+				" gleton:=true\r\n" +
+				"Bundle-Version: 2.13.4\r\n" +
+				"\r\n" +
+				"Name: com/example/\r\n" +
+				"Implementation-Title: section\r\n",
+			expected: map[string]string{
+				"Manifest-Version":    "1.0",
+				"Bundle-SymbolicName": "com.fasterxml.jackson.core.jackson-databind;singleton:=true",
+				"Bundle-Version":      "2.13.4",
+			},
+		},
+		{
+			desc: "malformed",
+			raw:  " orphan continuation\n:no key\nno separator\nKey:value\n",
+			expected: map[string]string{
+				"Key": "value",
+			},
+		},
+		{desc: "empty", expected: map[string]string{}},
+	}
+
+	for _, test := range tt {
+		if got := ParseJavaManifest([]byte(test.raw)); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: got %q expected %q", test.desc, got, test.expected)
+		}
+	}
+}
+
+func TestJavaManifestNameVersion(t *testing.T) {
+	tt := []struct {
+		manifest map[string]string
+		name     string
+		version  string
+	}{
+		{
+			manifest: map[string]string{"Bundle-SymbolicName": "org.slf4j.api; singleton:=true", "Bundle-Version": "1.7.36", "Implementation-Title": "slf4j"},
+			name:     "org.slf4j.api",
+			version:  "1.7.36",
+		},
+		{
+			manifest: map[string]string{"Implementation-Title": "guava", "Implementation-Version": "31.1-jre"},
+			name:     "guava",
+			version:  "31.1-jre",
+		},
+		{
+			manifest: map[string]string{"Automatic-Module-Name": "org.example.lib"},
+			name:     "org.example.lib",
+		},
+		{manifest: map[string]string{"Main-Class": "org.example.Main"}},
+		{},
+	}
+
+	for _, test := range tt {
+		name, version := javaManifestNameVersion(test.manifest)
+		if name != test.name || version != test.version {
+			t.Errorf("%v: got %q/%q expected %q/%q", test.manifest, name, version, test.name, test.version)
+		}
+	}
+}
+
+func TestSplitJavaArchiveName(t *testing.T) {
+	tt := []struct {
+		fileName string
+		name     string
+		version  string
+	}{
+		{fileName: "commons-lang3-3.12.0.jar", name: "commons-lang3", version: "3.12.0"},
+		{fileName: "guava-31.1-jre.jar", name: "guava", version: "31.1-jre"},
+		{fileName: "app.war", name: "app"},
+		{fileName: "1.0.jar", name: "1.0"},
+	}
+
+	for _, test := range tt {
+		name, version := splitJavaArchiveName(test.fileName)
+		if name != test.name || version != test.version {
+			t.Errorf("%s: got %q/%q expected %q/%q", test.fileName, name, version, test.name, test.version)
+		}
+	}
+}
+
+func TestJavaArchive(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	pomLib := testZip(t, map[string][]byte{
+		"META-INF/maven/org.slf4j/slf4j-api/pom.properties": []byte("#Generated by Maven\ngroupId=org.slf4j\nartifactId=slf4j-api\nversion=1.7.36\n"),
+	})
+
+	manifestLib := testZip(t, map[string][]byte{
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\nImplementation-Title: jackson-core\nImplementation-Version: 2.13.4\n"),
+	})
+
+	archives := map[string][]byte{
+		"app.jar": testZip(t, map[string][]byte{
+			"META-INF/MANIFEST.MF":                                  []byte("Manifest-Version: 1.0\nMain-Class: org.example.Main\n"),
+			"META-INF/maven/org.example/app/pom.properties":         []byte("groupId=org.example\nartifactId=app\nversion=1.0.0\n"),
+			"META-INF/maven/org.example/no-artifact/pom.properties": []byte("groupId=org.example\n"),
+			"BOOT-INF/lib/slf4j-api-1.7.36.jar":                     pomLib,
+			"BOOT-INF/lib/jackson-core-2.13.4.jar":                  manifestLib,
+			"BOOT-INF/lib/broken-lib-0.1.jar":                       []byte("not a zip"),
+			"lib/ignored-1.0.jar":                                   pomLib,
+		}),
+		"web.war": testZip(t, map[string][]byte{
+			"META-INF/MANIFEST.MF":                   []byte("Bundle-SymbolicName: org.example.web;singleton:=true\nBundle-Version: 2.0\n"),
+			"WEB-INF/lib/app.jar":                    manifestLib,
+			"WEB-INF/classes/org/example/Main.class": nil,
+		}),
+		"commons-io-2.11.0.jar": testZip(t, map[string][]byte{
+			"org/apache/commons/io/IOUtils.class": nil,
+		}),
+	}
+
+	tt := []struct {
+		archive  string
+		expected []string
+	}{
+		{
+			archive: "app.jar",
+			expected: []string{
+				"java:broken-lib@0.1 app.jar!/BOOT-INF/lib/broken-lib-0.1.jar",
+				"java:jackson-core@2.13.4 app.jar!/BOOT-INF/lib/jackson-core-2.13.4.jar",
+				"java:org.example:app@1.0.0 app.jar",
+				"java:org.slf4j:slf4j-api@1.7.36 app.jar!/BOOT-INF/lib/slf4j-api-1.7.36.jar",
+			},
+		},
+		{
+			archive: "web.war",
+			expected: []string{
+				"java:jackson-core@2.13.4 web.war!/WEB-INF/lib/app.jar",
+				"java:org.example.web@2.0 web.war",
+			},
+		},
+		{
+			archive:  "commons-io-2.11.0.jar",
+			expected: []string{"java:commons-io@2.11.0 commons-io-2.11.0.jar"},
+		},
+	}
+
+	for _, test := range tt {
+		archivePath := filepath.Join(dir, test.archive)
+		if err := ioutil.WriteFile(archivePath, archives[test.archive], 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		deps, err := JavaArchive(archivePath)
+		if err != nil {
+			t.Errorf("%s: unexpected error - %v", test.archive, err)
+			continue
+		}
+
+		if got := depStrings(t, dir, deps); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s:\ngot      %q\nexpected %q", test.archive, got, test.expected)
+		}
+	}
+
+	brokenPath := filepath.Join(dir, "broken.jar")
+	if err := ioutil.WriteFile(brokenPath, []byte("PK\x03\x04broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := JavaArchive(brokenPath); err == nil {
+		t.Errorf("expected an error for a malformed archive")
+	}
+}
+
+func TestIsJavaArchive(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"app.jar":  true,
+		"APP.JAR":  true,
+		"web.war":  true,
+		"app.ear":  true,
+		"app.zip":  false,
+		"jar":      false,
+		"app.jar/": false,
+	} {
+		if got := IsJavaArchive(name); got != expected {
+			t.Errorf("%s: got %v expected %v", name, got, expected)
+		}
+	}
+}
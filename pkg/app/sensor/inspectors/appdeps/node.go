@@ -0,0 +1,167 @@
+package appdeps
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	nodePackageFile     = "package.json"
+	nodePackageLockFile = "package-lock.json"
+	nodeShrinkwrapFile  = "npm-shrinkwrap.json"
+	nodeModulesDir      = "node_modules"
+)
+
+type nodePackageManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type nodePackageLockEntry struct {
+	Version      string                           `json:"version"`
+	Dependencies map[string]*nodePackageLockEntry `json:"dependencies"`
+}
+
+type nodePackageLock struct {
+	LockfileVersion int                              `json:"lockfileVersion"`
+	Packages        map[string]*nodePackageLockEntry `json:"packages"`
+	Dependencies    map[string]*nodePackageLockEntry `json:"dependencies"`
+}
+
+// NodeModules returns the packages installed in the 'node_modules' directory
+// (including the scoped and the nested packages)
+func NodeModules(modulesDir string) []*Dependency {
+	var deps []*Dependency
+	entries, err := ioutil.ReadDir(modulesDir)
+	if err != nil {
+		log.Debugf("appdeps.NodeModules(%s) - error reading dir: %v", modulesDir, err)
+		return nil
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		pkgDir := filepath.Join(modulesDir, entry.Name())
+		if strings.HasPrefix(entry.Name(), "@") {
+			//scoped packages (@scope/name)
+			scoped, err := ioutil.ReadDir(pkgDir)
+			if err != nil {
+				continue
+			}
+
+			for _, sentry := range scoped {
+				if sentry.IsDir() {
+					deps = append(deps, nodePackage(filepath.Join(pkgDir, sentry.Name()))...)
+				}
+			}
+
+			continue
+		}
+
+		deps = append(deps, nodePackage(pkgDir)...)
+	}
+
+	return deps
+}
+
+func nodePackage(pkgDir string) []*Dependency {
+	var deps []*Dependency
+	raw, err := ioutil.ReadFile(filepath.Join(pkgDir, nodePackageFile))
+	if err == nil {
+		var manifest nodePackageManifest
+		if err := json.Unmarshal(raw, &manifest); err == nil && manifest.Name != "" {
+			deps = append(deps, &Dependency{
+				Language: LanguageNode,
+				Name:     manifest.Name,
+				Version:  manifest.Version,
+				Path:     pkgDir,
+			})
+		}
+	}
+
+	nestedDir := filepath.Join(pkgDir, nodeModulesDir)
+	if info, err := os.Stat(nestedDir); err == nil && info.IsDir() {
+		deps = append(deps, NodeModules(nestedDir)...)
+	}
+
+	return deps
+}
+
+// NodePackageLock returns the packages in the 'package-lock.json' (or 'npm-shrinkwrap.json') file
+// (the package paths are based on the lock file location)
+func NodePackageLock(lockPath string) ([]*Dependency, error) {
+	raw, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock nodePackageLock
+	if err := json.Unmarshal(raw, &lock); err != nil {
+		return nil, err
+	}
+
+	appDir := filepath.Dir(lockPath)
+	var deps []*Dependency
+	if len(lock.Packages) > 0 {
+		//lockfile v2 and v3 ("node_modules/name[/node_modules/name]" keys)
+		for key, entry := range lock.Packages {
+			if key == "" || entry == nil {
+				continue
+			}
+
+			idx := strings.LastIndex(key, nodeModulesDir+"/")
+			if idx < 0 {
+				//workspace packages
+				continue
+			}
+
+			deps = append(deps, &Dependency{
+				Language: LanguageNode,
+				Name:     key[idx+len(nodeModulesDir)+1:],
+				Version:  entry.Version,
+				Path:     filepath.Join(appDir, key),
+			})
+		}
+
+		return deps, nil
+	}
+
+	//lockfile v1 (nested "dependencies")
+	var walk func(parentDir string, entries map[string]*nodePackageLockEntry)
+	walk = func(parentDir string, entries map[string]*nodePackageLockEntry) {
+		for name, entry := range entries {
+			if entry == nil {
+				continue
+			}
+
+			pkgDir := filepath.Join(parentDir, nodeModulesDir, name)
+			deps = append(deps, &Dependency{
+				Language: LanguageNode,
+				Name:     name,
+				Version:  entry.Version,
+				Path:     pkgDir,
+			})
+
+			walk(pkgDir, entry.Dependencies)
+		}
+	}
+
+	walk(appDir, lock.Dependencies)
+	return deps, nil
+}
+
+// NodePackageLockPaths returns the possible package lock file paths for the 'node_modules' directory
+func NodePackageLockPaths(modulesDir string) []string {
+	appDir := filepath.Dir(strings.TrimSuffix(modulesDir, "/"))
+	return []string{
+		filepath.Join(appDir, nodePackageLockFile),
+		filepath.Join(appDir, nodeShrinkwrapFile),
+	}
+}
@@ -0,0 +1,134 @@
+package appdeps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNodeModules(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"node_modules/express/package.json":                         `{"name": "express", "version": "4.18.2"}`,
+		"node_modules/express/node_modules/debug/package.json":      `{"name": "debug", "version": "2.6.9"}`,
+		"node_modules/@babel/core/package.json":                     `{"name": "@babel/core", "version": "7.20.0"}`,
+		"node_modules/@babel/parser/package.json":                   `{"name": "@babel/parser", "version": "7.20.1"}`,
+		"node_modules/no-version/package.json":                      `{"name": "no-version"}`,
+		"node_modules/no-name/package.json":                         `{"version": "1.0.0"}`,
+		"node_modules/malformed/package.json":                       `{"name": "malformed", "version": `,
+		"node_modules/wrong-types/package.json":                     `{"name": ["x"], "version": 1}`,
+		"node_modules/no-manifest/index.js":                         "",
+		"node_modules/no-manifest/node_modules/nested/package.json": `{"name": "nested", "version": "0.1.0"}`,
+		"node_modules/.bin/express":                                 "",
+		"node_modules/.package-lock.json":                           "{}",
+	})
+
+	modulesDir := filepath.Join(dir, "node_modules")
+	expected := []string{
+		"node.js:@babel/core@7.20.0 @babel/core",
+		"node.js:@babel/parser@7.20.1 @babel/parser",
+		"node.js:debug@2.6.9 express/node_modules/debug",
+		"node.js:express@4.18.2 express",
+		"node.js:nested@0.1.0 no-manifest/node_modules/nested",
+		"node.js:no-version@ no-version",
+	}
+
+	if got := depStrings(t, modulesDir, NodeModules(modulesDir)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("\ngot      %q\nexpected %q", got, expected)
+	}
+
+	if deps := NodeModules(filepath.Join(dir, "missing")); deps != nil {
+		t.Errorf("unexpected dependencies for a missing directory: %v", deps)
+	}
+}
+
+func TestNodePackageLock(t *testing.T) {
+	tt := []struct {
+		desc     string
+		raw      string
+		expected []string
+		err      bool
+	}{
+		{
+			desc: "lockfile v1",
+			raw: `{
+  "name": "app",
+  "lockfileVersion": 1,
+  "dependencies": {
+    "express": {
+      "version": "4.18.2",
+      "dependencies": {
+        "debug": {"version": "2.6.9"}
+      }
+    },
+    "ms": {"version": "2.1.3"},
+    "null": null
+  }
+}`,
+			expected: []string{
+				"node.js:debug@2.6.9 node_modules/express/node_modules/debug",
+				"node.js:express@4.18.2 node_modules/express",
+				"node.js:ms@2.1.3 node_modules/ms",
+			},
+		},
+		{
+			desc: "lockfile v2",
+			raw: `{
+  "name": "app",
+  "lockfileVersion": 2,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0"},
+    "node_modules/@babel/core": {"version": "7.20.0"},
+    "node_modules/express": {"version": "4.18.2"},
+    "node_modules/express/node_modules/debug": {"version": "2.6.9"},
+    "packages/workspace-a": {"version": "0.0.1"},
+    "node_modules/null": null
+  },
+  "dependencies": {
+    "ignored": {"version": "0.0.0"}
+  }
+}`,
+			expected: []string{
+				"node.js:@babel/core@7.20.0 node_modules/@babel/core",
+				"node.js:debug@2.6.9 node_modules/express/node_modules/debug",
+				"node.js:express@4.18.2 node_modules/express",
+			},
+		},
+		{desc: "empty", raw: `{}`},
+		{desc: "malformed", raw: `{"lockfileVersion": 2, "packages": {`, err: true},
+		{desc: "wrong types", raw: `{"packages": []}`, err: true},
+	}
+
+	for _, test := range tt {
+		dir := testDir(t)
+		lockPath := filepath.Join(dir, nodePackageLockFile)
+		writeTestFiles(t, dir, map[string]string{nodePackageLockFile: test.raw})
+
+		deps, err := NodePackageLock(lockPath)
+		os.RemoveAll(dir)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error - %v", test.desc, err)
+			continue
+		}
+
+		if got := depStrings(t, dir, deps); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s:\ngot      %q\nexpected %q", test.desc, got, test.expected)
+		}
+	}
+
+	if _, err := NodePackageLock("/missing/package-lock.json"); err == nil {
+		t.Errorf("expected an error for a missing lock file")
+	}
+}
+
+func TestNodePackageLockPaths(t *testing.T) {
+	expected := []string{"/app/package-lock.json", "/app/npm-shrinkwrap.json"}
+	for _, modulesDir := range []string{"/app/node_modules", "/app/node_modules/"} {
+		if got := NodePackageLockPaths(modulesDir); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: got %q expected %q", modulesDir, got, expected)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package appdeps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	pyDistInfoExt    = ".dist-info"
+	pyEggInfoExt     = ".egg-info"
+	pyMetadataFile   = "METADATA"
+	pyPkgInfoFile    = "PKG-INFO"
+	pyRecordFile     = "RECORD"
+	pyTopLevelFile   = "top_level.txt"
+	pyInstalledFiles = "installed-files.txt"
+)
+
+// PythonPackages returns the packages installed in the 'site-packages' (or 'dist-packages') directory
+// (using the package metadata in the '*.dist-info' and '*.egg-info' directories)
+func PythonPackages(pkgDir string) []*Dependency {
+	entries, err := ioutil.ReadDir(pkgDir)
+	if err != nil {
+		log.Debugf("appdeps.PythonPackages(%s) - error reading dir: %v", pkgDir, err)
+		return nil
+	}
+
+	var deps []*Dependency
+	for _, entry := range entries {
+		name := entry.Name()
+		var metaFile string
+		switch {
+		case strings.HasSuffix(name, pyDistInfoExt) && entry.IsDir():
+			metaFile = pyMetadataFile
+		case strings.HasSuffix(name, pyEggInfoExt) && entry.IsDir():
+			metaFile = pyPkgInfoFile
+		case strings.HasSuffix(name, pyEggInfoExt):
+			//the egg metadata can also be a file
+			metaFile = ""
+		default:
+			continue
+		}
+
+		infoPath := filepath.Join(pkgDir, name)
+		metaPath := infoPath
+		if metaFile != "" {
+			metaPath = filepath.Join(infoPath, metaFile)
+		}
+
+		raw, err := ioutil.ReadFile(metaPath)
+		if err != nil {
+			continue
+		}
+
+		pkgName, pkgVersion := ParsePythonMetadata(raw)
+		if pkgName == "" {
+			continue
+		}
+
+		dep := &Dependency{
+			Language: LanguagePython,
+			Name:     pkgName,
+			Version:  pkgVersion,
+			Path:     infoPath,
+		}
+
+		if metaFile != "" {
+			dep.Files = pythonPackageFiles(pkgDir, infoPath)
+		}
+
+		deps = append(deps, dep)
+	}
+
+	return deps
+}
+
+// ParsePythonMetadata returns the package name and version from the package metadata
+// (the 'METADATA' and 'PKG-INFO' files use the same email header style format)
+func ParsePythonMetadata(raw []byte) (string, string) {
+	var name, version string
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			//the package description starts after the headers
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(line, "Name:"):
+			name = strings.TrimSpace(line[len("Name:"):])
+		case strings.HasPrefix(line, "Version:"):
+			version = strings.TrimSpace(line[len("Version:"):])
+		}
+
+		if name != "" && version != "" {
+			break
+		}
+	}
+
+	return name, version
+}
+
+// pythonPackageFiles returns the package files (from the 'RECORD' or 'installed-files.txt' file)
+// or the top level package directories if the file list is not available
+func pythonPackageFiles(pkgDir, infoPath string) []string {
+	var files []string
+	if raw, err := ioutil.ReadFile(filepath.Join(infoPath, pyRecordFile)); err == nil {
+		records, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+		if err == nil {
+			for _, record := range records {
+				if len(record) == 0 || record[0] == "" {
+					continue
+				}
+
+				if strings.Contains(record[0], pyDistInfoExt+"/") {
+					//the package metadata files are read when the installed packages are enumerated
+					continue
+				}
+
+				files = append(files, filepath.Join(pkgDir, record[0]))
+			}
+
+			return files
+		}
+	}
+
+	if raw, err := ioutil.ReadFile(filepath.Join(infoPath, pyInstalledFiles)); err == nil {
+		//the egg file list is relative to the egg-info directory
+		for _, line := range strings.Split(string(raw), "\n") {
+			if line = strings.TrimSpace(line); line != "" && strings.HasPrefix(line, "..") {
+				files = append(files, filepath.Join(infoPath, line))
+			}
+		}
+
+		return files
+	}
+
+	if raw, err := ioutil.ReadFile(filepath.Join(infoPath, pyTopLevelFile)); err == nil {
+		for _, line := range strings.Split(string(raw), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				files = append(files,
+					filepath.Join(pkgDir, line),
+					filepath.Join(pkgDir, line+".py"))
+			}
+		}
+	}
+
+	return files
+}
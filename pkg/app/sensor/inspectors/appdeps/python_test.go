@@ -0,0 +1,126 @@
+package appdeps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParsePythonMetadata(t *testing.T) {
+	tt := []struct {
+		desc    string
+		raw     string
+		name    string
+		version string
+	}{
+		{
+			desc: "dist-info",
+			raw: `Metadata-Version: 2.1
+Name: requests
+Version: 2.28.1
+Summary: Python HTTP for Humans.
+Requires-Dist: idna (<4,>=2.5)
+
+Name: not-a-header
+`,
+			name:    "requests",
+			version: "2.28.1",
+		},
+		{
+			desc:    "description only",
+			raw:     "\nName: description\nVersion: 1.0\n",
+			name:    "",
+			version: "",
+		},
+		{
+			desc: "no version",
+			raw:  "Metadata-Version: 1.0\nName:   six  \n",
+			name: "six",
+		},
+		{desc: "binary", raw: "\x00\x01\x02Name\xff"},
+		{desc: "empty"},
+	}
+
+	for _, test := range tt {
+		name, version := ParsePythonMetadata([]byte(test.raw))
+		if name != test.name || version != test.version {
+			t.Errorf("%s: got %q/%q expected %q/%q", test.desc, name, version, test.name, test.version)
+		}
+	}
+}
+
+func TestPythonPackages(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"requests-2.28.1.dist-info/METADATA": "Name: requests\nVersion: 2.28.1\n",
+		"requests-2.28.1.dist-info/RECORD": `requests/__init__.py,sha256=abc,4924
+requests/api.py,sha256=def,6377
+requests-2.28.1.dist-info/METADATA,,
+,,
+`,
+		"six-1.16.0.dist-info/METADATA":      "Name: six\nVersion: 1.16.0\n",
+		"six-1.16.0.dist-info/top_level.txt": "six\n",
+		//malformed RECORD (uses the top level names)
+		"idna-3.4.dist-info/METADATA":      "Name: idna\nVersion: 3.4\n",
+		"idna-3.4.dist-info/RECORD":        "idna/core.py,\"sha256\n",
+		"idna-3.4.dist-info/top_level.txt": "idna\n",
+		"chardet-5.0.0.egg-info/PKG-INFO":  "Name: chardet\nVersion: 5.0.0\n",
+		"chardet-5.0.0.egg-info/installed-files.txt": `../chardet/__init__.py
+../chardet/big5freq.py
+PKG-INFO
+`,
+		"pyyaml-6.0.egg-info":              "Metadata-Version: 1.1\nName: PyYAML\nVersion: 6.0\n",
+		"no-metadata-1.0.dist-info/RECORD": "x.py,,\n",
+		"no-name-1.0.dist-info/METADATA":   "Version: 1.0\n",
+		"other/__init__.py":                "",
+	})
+
+	deps := PythonPackages(dir)
+	expected := []string{
+		"python:PyYAML@6.0 pyyaml-6.0.egg-info",
+		"python:chardet@5.0.0 chardet-5.0.0.egg-info",
+		"python:idna@3.4 idna-3.4.dist-info",
+		"python:requests@2.28.1 requests-2.28.1.dist-info",
+		"python:six@1.16.0 six-1.16.0.dist-info",
+	}
+
+	if got := depStrings(t, dir, deps); !reflect.DeepEqual(got, expected) {
+		t.Errorf("\ngot      %q\nexpected %q", got, expected)
+	}
+
+	expectedFiles := map[string][]string{
+		"PyYAML":   nil,
+		"chardet":  {"chardet/__init__.py", "chardet/big5freq.py"},
+		"idna":     {"idna", "idna.py"},
+		"requests": {"requests/__init__.py", "requests/api.py"},
+		"six":      {"six", "six.py"},
+	}
+
+	files := map[string][]string{}
+	for _, dep := range deps {
+		var names []string
+		for _, name := range dep.Files {
+			rel, err := filepath.Rel(dir, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names = append(names, rel)
+		}
+
+		sort.Strings(names)
+		files[dep.Name] = names
+	}
+
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Errorf("package files:\ngot      %q\nexpected %q", files, expectedFiles)
+	}
+
+	if deps := PythonPackages(filepath.Join(dir, "missing")); deps != nil {
+		t.Errorf("unexpected dependencies for a missing directory: %v", deps)
+	}
+}
@@ -0,0 +1,95 @@
+package appdeps
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	rbGemSpecExt   = ".gemspec"
+	rbSpecsSubDir  = "specifications"
+	rbDefaultSpecs = "default"
+	rbGemsSubDir   = "gems"
+)
+
+var (
+	rbSpecNameMatcher    = regexp.MustCompile(`\.name\s*=\s*["']([^"']+)["']`)
+	rbSpecVersionMatcher = regexp.MustCompile(`\.version\s*=\s*["']([^"']+)["']`)
+)
+
+// RubyGems returns the gems installed in the gem directory (the directory with the 'gems' subdirectory)
+// using the gem specs in the 'specifications' subdirectory
+func RubyGems(gemsDir string) []*Dependency {
+	gemHome := filepath.Dir(strings.TrimSuffix(gemsDir, "/"))
+	var deps []*Dependency
+	for _, specsDir := range []string{
+		filepath.Join(gemHome, rbSpecsSubDir),
+		filepath.Join(gemHome, rbSpecsSubDir, rbDefaultSpecs),
+	} {
+		entries, err := ioutil.ReadDir(specsDir)
+		if err != nil {
+			log.Debugf("appdeps.RubyGems(%s) - error reading dir: %v", specsDir, err)
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), rbGemSpecExt) {
+				continue
+			}
+
+			raw, err := ioutil.ReadFile(filepath.Join(specsDir, entry.Name()))
+			if err != nil {
+				continue
+			}
+
+			fullName := strings.TrimSuffix(entry.Name(), rbGemSpecExt)
+			name, version := ParseRubyGemSpec(raw)
+			if name == "" || version == "" {
+				name, version = splitGemFullName(fullName)
+			}
+
+			if name == "" {
+				continue
+			}
+
+			deps = append(deps, &Dependency{
+				Language: LanguageRuby,
+				Name:     name,
+				Version:  version,
+				Path:     filepath.Join(gemHome, rbGemsSubDir, fullName),
+			})
+		}
+	}
+
+	return deps
+}
+
+// ParseRubyGemSpec returns the gem name and version from the gem spec
+func ParseRubyGemSpec(raw []byte) (string, string) {
+	var name, version string
+	if match := rbSpecNameMatcher.FindSubmatch(raw); match != nil {
+		name = string(match[1])
+	}
+
+	if match := rbSpecVersionMatcher.FindSubmatch(raw); match != nil {
+		version = string(match[1])
+	}
+
+	return name, version
+}
+
+// splitGemFullName splits the gem full name ('name-version[-platform]')
+func splitGemFullName(fullName string) (string, string) {
+	parts := strings.Split(fullName, "-")
+	for i := len(parts) - 1; i > 0; i-- {
+		if parts[i] != "" && parts[i][0] >= '0' && parts[i][0] <= '9' {
+			return strings.Join(parts[:i], "-"), parts[i]
+		}
+	}
+
+	return fullName, ""
+}
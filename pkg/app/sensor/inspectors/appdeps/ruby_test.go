@@ -0,0 +1,100 @@
+package appdeps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRubyGemSpec(t *testing.T) {
+	tt := []struct {
+		desc    string
+		raw     string
+		name    string
+		version string
+	}{
+		{
+			desc: "gemspec",
+			raw: `# -*- encoding: utf-8 -*-
+# stub: rack 2.2.4 ruby lib
+
+Gem::Specification.new do |s|
+  s.name = "rack".freeze
+  s.version = "2.2.4"
+  s.required_rubygems_version = Gem::Requirement.new(">= 0".freeze) if s.respond_to? :required_rubygems_version=
+end
+`,
+			name:    "rack",
+			version: "2.2.4",
+		},
+		{
+			desc:    "single quotes",
+			raw:     "Gem::Specification.new do |spec|\n  spec.name    = 'json'\n  spec.version = '2.6.1'\nend\n",
+			name:    "json",
+			version: "2.6.1",
+		},
+		{
+			desc: "computed version",
+			raw:  "s.name = \"puma\"\ns.version = Puma::VERSION\n",
+			name: "puma",
+		},
+		{desc: "malformed", raw: "s.name = \"unterminated\ns.version ="},
+	}
+
+	for _, test := range tt {
+		name, version := ParseRubyGemSpec([]byte(test.raw))
+		if name != test.name || version != test.version {
+			t.Errorf("%s: got %q/%q expected %q/%q", test.desc, name, version, test.name, test.version)
+		}
+	}
+}
+
+func TestSplitGemFullName(t *testing.T) {
+	tt := []struct {
+		fullName string
+		name     string
+		version  string
+	}{
+		{fullName: "rack-2.2.4", name: "rack", version: "2.2.4"},
+		{fullName: "net-http-persistent-4.0.1", name: "net-http-persistent", version: "4.0.1"},
+		{fullName: "nokogiri-1.13.8-x86_64-linux", name: "nokogiri", version: "1.13.8"},
+		{fullName: "noversion", name: "noversion"},
+		{fullName: "trailing-", name: "trailing-"},
+		{fullName: "", name: ""},
+	}
+
+	for _, test := range tt {
+		name, version := splitGemFullName(test.fullName)
+		if name != test.name || version != test.version {
+			t.Errorf("%s: got %q/%q expected %q/%q", test.fullName, name, version, test.name, test.version)
+		}
+	}
+}
+
+func TestRubyGems(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"specifications/rack-2.2.4.gemspec":         "s.name = \"rack\"\ns.version = \"2.2.4\"\n",
+		"specifications/puma-5.6.5.gemspec":         "s.name = \"puma\"\ns.version = Puma::VERSION\n",
+		"specifications/broken.gemspec":             "\x00\xff",
+		"specifications/README":                     "",
+		"specifications/default/json-2.6.1.gemspec": "s.name = 'json'\ns.version = '2.6.1'\n",
+		"gems/rack-2.2.4/lib/rack.rb":               "",
+	})
+
+	expected := []string{
+		"ruby:broken@ gems/broken",
+		"ruby:json@2.6.1 gems/json-2.6.1",
+		"ruby:puma@5.6.5 gems/puma-5.6.5",
+		"ruby:rack@2.2.4 gems/rack-2.2.4",
+	}
+
+	for _, gemsDir := range []string{filepath.Join(dir, "gems"), filepath.Join(dir, "gems") + "/"} {
+		if got := depStrings(t, dir, RubyGems(gemsDir)); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s:\ngot      %q\nexpected %q", gemsDir, got, expected)
+		}
+	}
+}
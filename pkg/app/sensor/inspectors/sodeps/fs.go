@@ -0,0 +1,52 @@
+package sodeps
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// FileSystem provides the file access for the dependency resolver
+// (the local file system or the file system of an image)
+type FileSystem interface {
+	// Lstat returns the file info without following the symlink in the last path element
+	Lstat(name string) (os.FileInfo, error)
+	Readlink(name string) (string, error)
+	// ReadDir returns the directory entries sorted by name
+	ReadDir(name string) ([]os.FileInfo, error)
+	// Open opens the file (following the symlinks)
+	Open(name string) (File, error)
+}
+
+// File is a file opened with the resolver file system
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Closer
+}
+
+type localFileSystem struct{}
+
+// LocalFileSystem is the file system where the resolver is running
+var LocalFileSystem FileSystem = localFileSystem{}
+
+func (localFileSystem) Lstat(name string) (os.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (localFileSystem) Readlink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+func (localFileSystem) ReadDir(name string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(name)
+}
+
+func (localFileSystem) Open(name string) (File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
@@ -0,0 +1,156 @@
+package sodeps
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ldConfigFilePath     = "/etc/ld.so.conf"
+	ldConfigMaxDepth     = 8
+	muslPathFilePrefix   = "/etc/ld-musl-"
+	muslPathFileSuffix   = ".path"
+	muslLoaderNamePrefix = "ld-musl-"
+	muslLoaderNameSuffix = ".so.1"
+	searchPathSeparators = ":\n"
+	ldConfigSeparators   = " \t:,"
+)
+
+// the trusted directories used by the glibc loader after the ld.so.conf directories
+var (
+	glibcDefaultPaths32 = []string{"/lib", "/usr/lib"}
+	glibcDefaultPaths64 = []string{"/lib64", "/usr/lib64", "/lib", "/usr/lib"}
+)
+
+// the musl loader search path when there's no '/etc/ld-musl-ARCH.path' file
+var muslDefaultPaths = []string{"/lib", "/usr/local/lib", "/usr/lib"}
+
+// isMuslLoader checks if the program interpreter is the musl dynamic loader (e.g., '/lib/ld-musl-x86_64.so.1')
+func isMuslLoader(interp string) bool {
+	return strings.HasPrefix(path.Base(interp), muslLoaderNamePrefix)
+}
+
+// muslSearchPaths returns the library search path for the musl loader
+// (from '/etc/ld-musl-ARCH.path' where ARCH is taken from the loader name)
+func (r *Resolver) muslSearchPaths(interp string) []string {
+	arch := strings.TrimPrefix(path.Base(interp), muslLoaderNamePrefix)
+	arch = strings.TrimSuffix(arch, muslLoaderNameSuffix)
+
+	data, err := r.readFile(muslPathFilePrefix + arch + muslPathFileSuffix)
+	if err != nil {
+		return muslDefaultPaths
+	}
+
+	var paths []string
+	for _, dir := range strings.FieldsFunc(string(data), func(c rune) bool {
+		return strings.ContainsRune(searchPathSeparators, c)
+	}) {
+		dir = strings.TrimSpace(dir)
+		if path.IsAbs(dir) {
+			paths = append(paths, path.Clean(dir))
+		}
+	}
+
+	return paths
+}
+
+// glibcSearchPaths returns the library search path for the glibc loader
+// (the ld.so.conf directories instead of the ld.so.cache entries and the default trusted directories)
+func (r *Resolver) glibcSearchPaths(is64bit bool) []string {
+	paths := r.ldConfigPaths(ldConfigFilePath, 0)
+	if is64bit {
+		return append(paths, glibcDefaultPaths64...)
+	}
+
+	return append(paths, glibcDefaultPaths32...)
+}
+
+func (r *Resolver) ldConfigPaths(filePath string, depth int) []string {
+	if depth > ldConfigMaxDepth {
+		log.Debugf("sodeps.ldConfigPaths(%v): include too deep - skipping", filePath)
+		return nil
+	}
+
+	data, err := r.readFile(filePath)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.IndexByte(line, '#'); idx > -1 {
+			line = line[:idx]
+		}
+
+		fields := strings.FieldsFunc(line, func(c rune) bool {
+			return strings.ContainsRune(ldConfigSeparators, c)
+		})
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "include":
+			for _, pattern := range fields[1:] {
+				if !path.IsAbs(pattern) {
+					pattern = path.Join(path.Dir(filePath), pattern)
+				}
+
+				for _, name := range r.glob(pattern) {
+					paths = append(paths, r.ldConfigPaths(name, depth+1)...)
+				}
+			}
+		case "hwcap":
+			continue
+		default:
+			for _, dir := range fields {
+				//old style 'dir=type' entries
+				if idx := strings.IndexByte(dir, '='); idx > -1 {
+					dir = dir[:idx]
+				}
+
+				if path.IsAbs(dir) {
+					paths = append(paths, path.Clean(dir))
+				}
+			}
+		}
+	}
+
+	return paths
+}
+
+// glob matches the file names in the pattern directory (the pattern directory can't have wildcards)
+func (r *Resolver) glob(pattern string) []string {
+	dir, filePattern := path.Split(pattern)
+	entries, err := r.fs.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if matched, _ := path.Match(filePattern, entry.Name()); matched {
+			names = append(names, path.Join(dir, entry.Name()))
+		}
+	}
+
+	return names
+}
+
+func (r *Resolver) readFile(name string) ([]byte, error) {
+	f, err := r.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
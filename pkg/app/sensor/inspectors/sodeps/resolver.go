@@ -0,0 +1,403 @@
+package sodeps
+
+import (
+	"debug/elf"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/app/sensor/detectors/binfile"
+)
+
+const (
+	maxSymlinks = 40
+)
+
+// EnvLibraryPath is the environment variable with the extra library search path
+const EnvLibraryPath = "LD_LIBRARY_PATH"
+
+// Resolver resolves the shared library dependencies for the ELF binaries
+// using the same rules as the dynamic loader (without running 'ldd' or the loader)
+type Resolver struct {
+	fs          FileSystem
+	libraryPath []string
+	mu          sync.Mutex
+	searchPaths map[string][]string
+}
+
+// NewResolver creates a new dependency resolver for the file system
+// (libraryPath is the LD_LIBRARY_PATH value in the target environment)
+func NewResolver(fs FileSystem, libraryPath string) *Resolver {
+	return &Resolver{
+		fs:          fs,
+		libraryPath: splitSearchPath([]string{libraryPath}),
+		searchPaths: map[string][]string{},
+	}
+}
+
+// LibraryPath returns the LD_LIBRARY_PATH value in the environment variable list ('NAME=VALUE')
+func LibraryPath(env []string) string {
+	var value string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, EnvLibraryPath+"=") {
+			value = strings.TrimPrefix(kv, EnvLibraryPath+"=")
+		}
+	}
+
+	return value
+}
+
+type binInfo struct {
+	filePath string
+	class    elf.Class
+	machine  elf.Machine
+	interp   string
+	needed   []string
+	rpath    []string
+	runpath  []string
+}
+
+// Dependencies returns the program interpreter and the shared libraries needed by the binary
+// (the library paths are the paths where the libraries are found, which can be symlinks)
+func (r *Resolver) Dependencies(binFilePath string) ([]string, error) {
+	if !path.IsAbs(binFilePath) {
+		return nil, ErrFilePathNotAbs
+	}
+
+	exe, err := r.binInfo(binFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if exe.interp == "" && len(exe.needed) == 0 {
+		log.Debugf("sodeps.Resolver.Dependencies(%v): statically linked binary file", binFilePath)
+		return nil, nil
+	}
+
+	var deps []string
+	if exe.interp != "" {
+		deps = append(deps, exe.interp)
+	}
+
+	isMusl := isMuslLoader(exe.interp)
+	sysPaths := r.systemSearchPaths(exe, isMusl)
+
+	type neededLib struct {
+		name   string
+		loader *binInfo
+	}
+
+	var queue []neededLib
+	for _, name := range exe.needed {
+		queue = append(queue, neededLib{name: name, loader: exe})
+	}
+
+	found := map[string]struct{}{}
+	known := map[string]struct{}{}
+	for _, dep := range deps {
+		known[dep] = struct{}{}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		//the libraries are loaded only once (by their names)
+		if _, ok := found[current.name]; ok {
+			continue
+		}
+
+		found[current.name] = struct{}{}
+
+		lib := r.findLib(current.name, current.loader, exe, sysPaths, isMusl)
+		if lib == nil {
+			log.Debugf("sodeps.Resolver.Dependencies(%v): library not found - '%v' (needed by %v)",
+				binFilePath, current.name, current.loader.filePath)
+			continue
+		}
+
+		if _, ok := known[lib.filePath]; ok {
+			continue
+		}
+
+		known[lib.filePath] = struct{}{}
+		deps = append(deps, lib.filePath)
+
+		for _, name := range lib.needed {
+			queue = append(queue, neededLib{name: name, loader: lib})
+		}
+	}
+
+	return deps, nil
+}
+
+// LookPath searches for the executable in the search path directories (like 'exec.LookPath')
+func (r *Resolver) LookPath(name string, searchPath []string) (string, error) {
+	if strings.Contains(name, "/") {
+		if !path.IsAbs(name) {
+			return "", ErrFilePathNotAbs
+		}
+
+		if r.isExecutable(name) {
+			return name, nil
+		}
+
+		return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+
+	for _, dir := range searchPath {
+		if !path.IsAbs(dir) {
+			continue
+		}
+
+		filePath := path.Join(dir, name)
+		if r.isExecutable(filePath) {
+			return filePath, nil
+		}
+	}
+
+	return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+}
+
+func (r *Resolver) isExecutable(filePath string) bool {
+	info, err := r.fs.Lstat(r.realPath(filePath))
+	if err != nil {
+		return false
+	}
+
+	return info.Mode().IsRegular() && info.Mode()&0111 != 0
+}
+
+func (r *Resolver) binInfo(filePath string) (*binInfo, error) {
+	f, err := r.fs.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	ef, err := elf.NewFile(f)
+	if err != nil {
+		if elfErr, ok := err.(*elf.FormatError); ok && strings.Contains(elfErr.Error(), "bad magic number") {
+			return nil, ErrFileNotBin
+		}
+
+		return nil, err
+	}
+
+	info := &binInfo{
+		filePath: filePath,
+		class:    ef.Class,
+		machine:  ef.Machine,
+		interp:   binfile.Interpreter(ef),
+	}
+
+	if info.needed, err = ef.DynString(elf.DT_NEEDED); err != nil {
+		log.Debugf("sodeps.Resolver.binInfo(%v): error reading needed libraries - %v", filePath, err)
+	}
+
+	rpath, _ := ef.DynString(elf.DT_RPATH)
+	info.rpath = splitSearchPath(rpath)
+	runpath, _ := ef.DynString(elf.DT_RUNPATH)
+	info.runpath = splitSearchPath(runpath)
+
+	return info, nil
+}
+
+// findLib searches for the library using the loader search order:
+// DT_RPATH (of the loading object and the executable, if there's no DT_RUNPATH),
+// LD_LIBRARY_PATH, DT_RUNPATH (of the loading object) and the system search path
+// (the musl loader searches LD_LIBRARY_PATH first and uses DT_RPATH and DT_RUNPATH the same way)
+func (r *Resolver) findLib(name string, loader, exe *binInfo, sysPaths []string, isMusl bool) *binInfo {
+	if strings.Contains(name, "/") {
+		if !path.IsAbs(name) {
+			return nil
+		}
+
+		return r.checkLib(name, exe)
+	}
+
+	//the LD_LIBRARY_PATH tokens are expanded for the executable
+	libraryPath := r.expandSearchPath(r.libraryPath, exe, isMusl)
+
+	var dirs []string
+	switch {
+	case isMusl:
+		dirs = append(dirs, libraryPath...)
+		dirs = append(dirs, r.expandSearchPath(loader.rpath, loader, isMusl)...)
+		dirs = append(dirs, r.expandSearchPath(loader.runpath, loader, isMusl)...)
+		if loader != exe {
+			dirs = append(dirs, r.expandSearchPath(exe.rpath, exe, isMusl)...)
+			dirs = append(dirs, r.expandSearchPath(exe.runpath, exe, isMusl)...)
+		}
+	case len(loader.runpath) == 0:
+		dirs = append(dirs, r.expandSearchPath(loader.rpath, loader, isMusl)...)
+		if loader != exe && len(exe.runpath) == 0 {
+			dirs = append(dirs, r.expandSearchPath(exe.rpath, exe, isMusl)...)
+		}
+
+		dirs = append(dirs, libraryPath...)
+	default:
+		dirs = append(dirs, libraryPath...)
+		dirs = append(dirs, r.expandSearchPath(loader.runpath, loader, isMusl)...)
+	}
+
+	dirs = append(dirs, sysPaths...)
+
+	checked := map[string]struct{}{}
+	for _, dir := range dirs {
+		if _, ok := checked[dir]; ok {
+			continue
+		}
+
+		checked[dir] = struct{}{}
+		if lib := r.checkLib(path.Join(dir, name), exe); lib != nil {
+			return lib
+		}
+	}
+
+	return nil
+}
+
+// checkLib returns the library info if the library exists and it's compatible with the executable
+// (the loader skips the libraries for other architectures in the search path)
+func (r *Resolver) checkLib(filePath string, exe *binInfo) *binInfo {
+	if _, err := r.fs.Lstat(filePath); err != nil {
+		return nil
+	}
+
+	lib, err := r.binInfo(filePath)
+	if err != nil {
+		log.Debugf("sodeps.Resolver.checkLib(%v): not a library - %v", filePath, err)
+		return nil
+	}
+
+	if lib.class != exe.class || lib.machine != exe.machine {
+		log.Debugf("sodeps.Resolver.checkLib(%v): incompatible library - %v/%v", filePath, lib.class, lib.machine)
+		return nil
+	}
+
+	return lib
+}
+
+// expandSearchPath expands the dynamic string tokens in the DT_RPATH/DT_RUNPATH directories
+// ($ORIGIN and $LIB; the directories with unsupported tokens or relative paths are skipped)
+func (r *Resolver) expandSearchPath(dirs []string, obj *binInfo, isMusl bool) []string {
+	var expanded []string
+	for _, dir := range dirs {
+		if strings.Contains(dir, "$") {
+			libDir := "lib"
+			if obj.class == elf.ELFCLASS64 && !isMusl {
+				libDir = "lib64"
+			}
+
+			dir = strings.NewReplacer(
+				"${ORIGIN}", "$ORIGIN",
+				"${LIB}", libDir,
+				"$LIB", libDir).Replace(dir)
+
+			if strings.Contains(dir, "$ORIGIN") {
+				origin := path.Dir(r.realPath(obj.filePath))
+				dir = strings.Replace(dir, "$ORIGIN", origin, -1)
+			}
+
+			if strings.Contains(dir, "$") {
+				log.Debugf("sodeps.Resolver.expandSearchPath(%v): unsupported search path - '%v'", obj.filePath, dir)
+				continue
+			}
+		}
+
+		if !path.IsAbs(dir) {
+			continue
+		}
+
+		expanded = append(expanded, path.Clean(dir))
+	}
+
+	return expanded
+}
+
+func (r *Resolver) systemSearchPaths(exe *binInfo, isMusl bool) []string {
+	key := exe.interp
+	if !isMusl {
+		key = exe.class.String()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if paths, ok := r.searchPaths[key]; ok {
+		return paths
+	}
+
+	var paths []string
+	if isMusl {
+		paths = r.muslSearchPaths(exe.interp)
+	} else {
+		paths = r.glibcSearchPaths(exe.class == elf.ELFCLASS64)
+	}
+
+	r.searchPaths[key] = paths
+	return paths
+}
+
+// realPath resolves all symlinks in the file path
+func (r *Resolver) realPath(filePath string) string {
+	resolved := "/"
+	parts := strings.Split(strings.TrimPrefix(path.Clean(filePath), "/"), "/")
+	for links := 0; len(parts) > 0; {
+		part := parts[0]
+		parts = parts[1:]
+
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+
+		next := path.Join(resolved, part)
+		info, err := r.fs.Lstat(next)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return path.Clean(filePath)
+		}
+
+		target, err := r.fs.Readlink(next)
+		if err != nil {
+			resolved = next
+			continue
+		}
+
+		if path.IsAbs(target) {
+			resolved = "/"
+		}
+
+		parts = append(strings.Split(target, "/"), parts...)
+	}
+
+	return resolved
+}
+
+func splitSearchPath(values []string) []string {
+	var dirs []string
+	for _, value := range values {
+		for _, dir := range strings.Split(value, ":") {
+			if dir != "" {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	return dirs
+}
@@ -0,0 +1,523 @@
+package sodeps
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testELF struct {
+	machine elf.Machine
+	interp  string
+	needed  []string
+	rpath   string
+	runpath string
+}
+
+// build creates a minimal 64-bit ELF file with the program interpreter and the dynamic section
+func (e testELF) build() []byte {
+	const (
+		ehdrSize = 64
+		phdrSize = 56
+		shdrSize = 64
+		dynSize  = 16
+	)
+
+	machine := e.machine
+	if machine == elf.EM_NONE {
+		machine = elf.EM_X86_64
+	}
+
+	dynstr := []byte{0}
+	addString := func(s string) uint64 {
+		offset := len(dynstr)
+		dynstr = append(dynstr, s...)
+		dynstr = append(dynstr, 0)
+		return uint64(offset)
+	}
+
+	var dyns []elf.Dyn64
+	for _, name := range e.needed {
+		dyns = append(dyns, elf.Dyn64{Tag: int64(elf.DT_NEEDED), Val: addString(name)})
+	}
+
+	if e.rpath != "" {
+		dyns = append(dyns, elf.Dyn64{Tag: int64(elf.DT_RPATH), Val: addString(e.rpath)})
+	}
+
+	if e.runpath != "" {
+		dyns = append(dyns, elf.Dyn64{Tag: int64(elf.DT_RUNPATH), Val: addString(e.runpath)})
+	}
+
+	dyns = append(dyns, elf.Dyn64{Tag: int64(elf.DT_NULL)})
+
+	shstrtab := []byte("\x00.dynstr\x00.dynamic\x00.shstrtab\x00")
+
+	var progs []elf.Prog64
+	phnum := 1
+	if e.interp != "" {
+		phnum++
+	}
+
+	offset := uint64(ehdrSize + phnum*phdrSize)
+	interpOffset := offset
+	if e.interp != "" {
+		offset += uint64(len(e.interp) + 1)
+	}
+
+	dynstrOffset := offset
+	offset += uint64(len(dynstr))
+	offset = (offset + 7) &^ 7
+	dynOffset := offset
+	offset += uint64(len(dyns) * dynSize)
+	shstrtabOffset := offset
+	offset += uint64(len(shstrtab))
+	offset = (offset + 7) &^ 7
+	shOffset := offset
+
+	if e.interp != "" {
+		progs = append(progs, elf.Prog64{
+			Type:   uint32(elf.PT_INTERP),
+			Flags:  uint32(elf.PF_R),
+			Off:    interpOffset,
+			Vaddr:  interpOffset,
+			Filesz: uint64(len(e.interp) + 1),
+			Memsz:  uint64(len(e.interp) + 1),
+			Align:  1,
+		})
+	}
+
+	progs = append(progs, elf.Prog64{
+		Type:   uint32(elf.PT_DYNAMIC),
+		Flags:  uint32(elf.PF_R | elf.PF_W),
+		Off:    dynOffset,
+		Vaddr:  dynOffset,
+		Filesz: uint64(len(dyns) * dynSize),
+		Memsz:  uint64(len(dyns) * dynSize),
+		Align:  8,
+	})
+
+	sections := []elf.Section64{
+		{},
+		{
+			Name:      1,
+			Type:      uint32(elf.SHT_STRTAB),
+			Flags:     uint64(elf.SHF_ALLOC),
+			Addr:      dynstrOffset,
+			Off:       dynstrOffset,
+			Size:      uint64(len(dynstr)),
+			Addralign: 1,
+		},
+		{
+			Name:      9,
+			Type:      uint32(elf.SHT_DYNAMIC),
+			Flags:     uint64(elf.SHF_ALLOC | elf.SHF_WRITE),
+			Addr:      dynOffset,
+			Off:       dynOffset,
+			Size:      uint64(len(dyns) * dynSize),
+			Link:      1,
+			Addralign: 8,
+			Entsize:   dynSize,
+		},
+		{
+			Name:      18,
+			Type:      uint32(elf.SHT_STRTAB),
+			Off:       shstrtabOffset,
+			Size:      uint64(len(shstrtab)),
+			Addralign: 1,
+		},
+	}
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_DYN),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     ehdrSize,
+		Shoff:     shOffset,
+		Ehsize:    ehdrSize,
+		Phentsize: phdrSize,
+		Phnum:     uint16(len(progs)),
+		Shentsize: shdrSize,
+		Shnum:     uint16(len(sections)),
+		Shstrndx:  3,
+	}
+
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var buf bytes.Buffer
+	write := func(data interface{}) {
+		_ = binary.Write(&buf, binary.LittleEndian, data)
+	}
+
+	pad := func(offset uint64) {
+		for uint64(buf.Len()) < offset {
+			buf.WriteByte(0)
+		}
+	}
+
+	write(&header)
+	write(progs)
+	if e.interp != "" {
+		buf.WriteString(e.interp)
+		buf.WriteByte(0)
+	}
+
+	buf.Write(dynstr)
+	pad(dynOffset)
+	write(dyns)
+	buf.Write(shstrtab)
+	pad(shOffset)
+	write(sections)
+	return buf.Bytes()
+}
+
+type testFile struct {
+	data []byte
+	link string
+}
+
+// testFileSystem is an in-memory file system
+// (the parent directories are implied by the file paths)
+type testFileSystem map[string]testFile
+
+type testFileInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+}
+
+func (i *testFileInfo) Name() string       { return i.name }
+func (i *testFileInfo) Size() int64        { return i.size }
+func (i *testFileInfo) Mode() os.FileMode  { return i.mode }
+func (i *testFileInfo) ModTime() time.Time { return time.Time{} }
+func (i *testFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *testFileInfo) Sys() interface{}   { return nil }
+
+type testFileData struct {
+	*bytes.Reader
+}
+
+func (testFileData) Close() error { return nil }
+
+func (fs testFileSystem) isDir(name string) bool {
+	if name == "/" {
+		return true
+	}
+
+	for filePath := range fs {
+		if strings.HasPrefix(filePath, name+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (fs testFileSystem) Lstat(name string) (os.FileInfo, error) {
+	name = path.Clean(name)
+	if file, found := fs[name]; found {
+		if file.link != "" {
+			return &testFileInfo{name: path.Base(name), mode: os.ModeSymlink | 0777}, nil
+		}
+
+		return &testFileInfo{name: path.Base(name), size: int64(len(file.data)), mode: 0755}, nil
+	}
+
+	if fs.isDir(name) {
+		return &testFileInfo{name: path.Base(name), mode: os.ModeDir | 0755}, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func (fs testFileSystem) Readlink(name string) (string, error) {
+	if file, found := fs[path.Clean(name)]; found && file.link != "" {
+		return file.link, nil
+	}
+
+	return "", errors.New("not a symlink")
+}
+
+func (fs testFileSystem) ReadDir(name string) ([]os.FileInfo, error) {
+	name = path.Clean(name)
+	if !fs.isDir(name) {
+		return nil, os.ErrNotExist
+	}
+
+	entries := map[string]os.FileInfo{}
+	for filePath := range fs {
+		if !strings.HasPrefix(filePath, strings.TrimSuffix(name, "/")+"/") {
+			continue
+		}
+
+		entryName := strings.SplitN(strings.TrimPrefix(filePath, name+"/"), "/", 2)[0]
+		if info, err := fs.Lstat(path.Join(name, entryName)); err == nil {
+			entries[entryName] = info
+		}
+	}
+
+	var infos []os.FileInfo
+	for _, info := range entries {
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	return infos, nil
+}
+
+func (fs testFileSystem) Open(name string) (File, error) {
+	r := &Resolver{fs: fs}
+	file, found := fs[r.realPath(name)]
+	if !found || file.link != "" {
+		return nil, os.ErrNotExist
+	}
+
+	return testFileData{bytes.NewReader(file.data)}, nil
+}
+
+const (
+	testGlibcLoader = "/lib64/ld-linux-x86-64.so.2"
+	testMuslLoader  = "/lib/ld-musl-x86_64.so.1"
+)
+
+func newTestGlibcFileSystem() testFileSystem {
+	return testFileSystem{
+		testGlibcLoader:                       {data: testELF{}.build()},
+		"/etc/ld.so.conf":                     {data: []byte("# libc default configuration\ninclude /etc/ld.so.conf.d/*.conf\n")},
+		"/etc/ld.so.conf.d/x86_64.conf":       {data: []byte("/lib/x86_64-linux-gnu\n/usr/lib/x86_64-linux-gnu\n")},
+		"/lib/x86_64-linux-gnu/libc.so.6":     {link: "libc-2.31.so"},
+		"/lib/x86_64-linux-gnu/libc-2.31.so":  {data: testELF{}.build()},
+		"/usr/lib/x86_64-linux-gnu/libz.so.1": {data: testELF{needed: []string{"libc.so.6"}}.build()},
+	}
+}
+
+func TestResolverDependencies(t *testing.T) {
+	tt := []struct {
+		name        string
+		files       testFileSystem
+		binPath     string
+		libraryPath string
+		expected    []string
+	}{
+		{
+			name:    "static binary",
+			files:   testFileSystem{"/app/server": {data: testELF{}.build()}},
+			binPath: "/app/server",
+		},
+		{
+			name: "interpreter path longer than PATH_MAX",
+			files: testFileSystem{
+				"/app/server": {data: testELF{interp: "/lib64/" + strings.Repeat("x", 4096)}.build()},
+			},
+			binPath: "/app/server",
+		},
+		{
+			name: "needed libraries in the ld.so.conf directories",
+			files: testFileSystem{
+				"/app/server": {data: testELF{interp: testGlibcLoader, needed: []string{"libz.so.1", "libc.so.6"}}.build()},
+			},
+			binPath: "/app/server",
+			expected: []string{
+				testGlibcLoader,
+				"/usr/lib/x86_64-linux-gnu/libz.so.1",
+				"/lib/x86_64-linux-gnu/libc.so.6",
+			},
+		},
+		{
+			name: "rpath with origin (the libraries use the executable rpath)",
+			files: testFileSystem{
+				"/usr/bin/server":    {link: "/app/bin/server"},
+				"/app/bin/server":    {data: testELF{interp: testGlibcLoader, needed: []string{"libapp.so", "libc.so.6"}, rpath: "$ORIGIN/../lib"}.build()},
+				"/app/lib/libapp.so": {data: testELF{needed: []string{"libdep.so"}}.build()},
+				"/app/lib/libdep.so": {data: testELF{}.build()},
+				//the incompatible libraries are skipped
+				"/app/lib/libc.so.6": {data: testELF{machine: elf.EM_AARCH64}.build()},
+			},
+			binPath: "/usr/bin/server",
+			expected: []string{
+				testGlibcLoader,
+				"/app/lib/libapp.so",
+				"/lib/x86_64-linux-gnu/libc.so.6",
+				"/app/lib/libdep.so",
+			},
+		},
+		{
+			name: "runpath (the libraries don't use the executable runpath)",
+			files: testFileSystem{
+				"/app/server":        {data: testELF{interp: testGlibcLoader, needed: []string{"libapp.so"}, runpath: "${ORIGIN}/lib"}.build()},
+				"/app/lib/libapp.so": {data: testELF{needed: []string{"libdep.so"}}.build()},
+				"/app/lib/libdep.so": {data: testELF{}.build()},
+			},
+			binPath: "/app/server",
+			expected: []string{
+				testGlibcLoader,
+				"/app/lib/libapp.so",
+			},
+		},
+		{
+			name: "runpath disables the rpath",
+			files: testFileSystem{
+				"/app/server":            {data: testELF{interp: testGlibcLoader, needed: []string{"libapp.so"}, rpath: "/app/rpath", runpath: "/app/runpath"}.build()},
+				"/app/rpath/libapp.so":   {data: testELF{}.build()},
+				"/app/runpath/libapp.so": {data: testELF{}.build()},
+			},
+			binPath: "/app/server",
+			expected: []string{
+				testGlibcLoader,
+				"/app/runpath/libapp.so",
+			},
+		},
+		{
+			name: "musl search path file",
+			files: testFileSystem{
+				testMuslLoader:                   {data: testELF{}.build()},
+				"/etc/ld-musl-x86_64.path":       {data: []byte("/usr/local/lib:/usr/lib\n")},
+				"/usr/lib/libssl.so.3":           {data: testELF{needed: []string{"libcrypto.so.3"}}.build()},
+				"/lib/libssl.so.3":               {data: testELF{}.build()},
+				"/app/server":                    {data: testELF{interp: testMuslLoader, needed: []string{"libssl.so.3", "libc.musl-x86_64.so.1"}, runpath: "/app/lib"}.build()},
+				"/app/lib/libcrypto.so.3":        {data: testELF{}.build()},
+				"/lib/libc.musl-x86_64.so.1":     {link: "ld-musl-x86_64.so.1"},
+				"/usr/lib/libc.musl-x86_64.so.1": {link: "/lib/ld-musl-x86_64.so.1"},
+			},
+			binPath: "/app/server",
+			expected: []string{
+				testMuslLoader,
+				"/usr/lib/libssl.so.3",
+				"/usr/lib/libc.musl-x86_64.so.1",
+				//musl uses the executable runpath for the library dependencies
+				"/app/lib/libcrypto.so.3",
+			},
+		},
+		{
+			name: "musl default search path",
+			files: testFileSystem{
+				testMuslLoader:               {data: testELF{}.build()},
+				"/usr/lib/libssl.so.3":       {data: testELF{}.build()},
+				"/lib/libc.musl-x86_64.so.1": {link: "ld-musl-x86_64.so.1"},
+				"/app/server":                {data: testELF{interp: testMuslLoader, needed: []string{"libssl.so.3", "libc.musl-x86_64.so.1"}}.build()},
+			},
+			binPath: "/app/server",
+			expected: []string{
+				testMuslLoader,
+				"/usr/lib/libssl.so.3",
+				"/lib/libc.musl-x86_64.so.1",
+			},
+		},
+		{
+			name: "library path after rpath",
+			files: testFileSystem{
+				"/app/server":          {data: testELF{interp: testGlibcLoader, needed: []string{"libapp.so", "libdep.so", "libc.so.6"}, rpath: "/app/rpath"}.build()},
+				"/app/rpath/libapp.so": {data: testELF{}.build()},
+				"/app/env/libapp.so":   {data: testELF{}.build()},
+				"/app/env/libdep.so":   {data: testELF{}.build()},
+				"/app/env/libc.so.6":   {data: testELF{}.build()},
+			},
+			binPath: "/app/server",
+			//the relative and empty library path directories are skipped
+			libraryPath: "lib::/app/env",
+			expected: []string{
+				testGlibcLoader,
+				"/app/rpath/libapp.so",
+				"/app/env/libdep.so",
+				"/app/env/libc.so.6",
+			},
+		},
+		{
+			name: "library path before runpath",
+			files: testFileSystem{
+				"/app/bin/server":        {data: testELF{interp: testGlibcLoader, needed: []string{"libapp.so"}, runpath: "/app/runpath"}.build()},
+				"/app/runpath/libapp.so": {data: testELF{needed: []string{"libdep.so"}}.build()},
+				"/app/lib/libapp.so":     {data: testELF{needed: []string{"libdep.so"}}.build()},
+				"/app/lib/libdep.so":     {data: testELF{}.build()},
+			},
+			binPath: "/app/bin/server",
+			//the library path tokens are expanded for the executable
+			//(and the library path is used for the library dependencies)
+			libraryPath: "$ORIGIN/../lib",
+			expected: []string{
+				testGlibcLoader,
+				"/app/lib/libapp.so",
+				"/app/lib/libdep.so",
+			},
+		},
+		{
+			name: "musl library path before rpath",
+			files: testFileSystem{
+				testMuslLoader:               {data: testELF{}.build()},
+				"/lib/libc.musl-x86_64.so.1": {link: "ld-musl-x86_64.so.1"},
+				"/app/server":                {data: testELF{interp: testMuslLoader, needed: []string{"libapp.so", "libc.musl-x86_64.so.1"}, rpath: "/app/rpath"}.build()},
+				"/app/rpath/libapp.so":       {data: testELF{}.build()},
+				"/app/env/libapp.so":         {data: testELF{}.build()},
+			},
+			binPath:     "/app/server",
+			libraryPath: "/app/env",
+			expected: []string{
+				testMuslLoader,
+				"/app/env/libapp.so",
+				"/lib/libc.musl-x86_64.so.1",
+			},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			fs := newTestGlibcFileSystem()
+			for name, file := range test.files {
+				fs[name] = file
+			}
+
+			deps, err := NewResolver(fs, test.libraryPath).Dependencies(test.binPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(deps, test.expected) {
+				t.Errorf("unexpected dependencies:\ngot      %v\nexpected %v", deps, test.expected)
+			}
+		})
+	}
+}
+
+func TestResolverErrors(t *testing.T) {
+	fs := testFileSystem{
+		"/app/config.txt": {data: []byte("not a binary file")},
+	}
+
+	r := NewResolver(fs, "")
+	if _, err := r.Dependencies("app/server"); err != ErrFilePathNotAbs {
+		t.Errorf("relative path: got %v expected %v", err, ErrFilePathNotAbs)
+	}
+
+	if _, err := r.Dependencies("/app/config.txt"); err != ErrFileNotBin {
+		t.Errorf("not a binary: got %v expected %v", err, ErrFileNotBin)
+	}
+}
+
+func TestLibraryPath(t *testing.T) {
+	tt := []struct {
+		env      []string
+		expected string
+	}{
+		{env: []string{"PATH=/bin", "LD_LIBRARY_PATH=/app/lib:/opt/lib"}, expected: "/app/lib:/opt/lib"},
+		{env: []string{"LD_LIBRARY_PATH=/old", "LD_LIBRARY_PATH=/new"}, expected: "/new"},
+		{env: []string{"LD_LIBRARY_PATHS=/app/lib", "LD_PRELOAD=/app/lib/x.so"}},
+		{},
+	}
+
+	for _, test := range tt {
+		if got := LibraryPath(test.env); got != test.expected {
+			t.Errorf("%q: got %q expected %q", test.env, got, test.expected)
+		}
+	}
+}
@@ -1,28 +1,28 @@
 package sodeps
 
 import (
-	"bytes"
 	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
-	"github.com/docker-slim/docker-slim/pkg/app/sensor/detectors/binfile"
-
 	log "github.com/sirupsen/logrus"
 )
 
 // Inspector errors
 var (
-	ErrFilePathNotAbs      = errors.New("file path is not absolute")
-	ErrFileNotBin          = errors.New("file is not a binary")
-	ErrDepResolverNotFound = errors.New("dependency resolver not found")
+	ErrFilePathNotAbs = errors.New("file path is not absolute")
+	ErrFileNotBin     = errors.New("file is not a binary")
 )
 
-const (
-	resolverExeName = "ldd"
-)
+var localResolver = NewResolver(LocalFileSystem, "")
+
+// SetLibraryPath sets the LD_LIBRARY_PATH value for the local dependency resolution
+// (the sensor uses the target app environment)
+func SetLibraryPath(value string) {
+	localResolver = NewResolver(LocalFileSystem, value)
+}
 
 func AllExeDependencies(exeFileName string, find bool) ([]string, error) {
 	if !strings.HasPrefix(exeFileName, "/") {
@@ -42,12 +42,19 @@ func AllExeDependencies(exeFileName string, find bool) ([]string, error) {
 }
 
 func AllDependencies(binFilePath string) ([]string, error) {
+	return localResolver.AllDependencies(binFilePath)
+}
+
+// AllDependencies returns the binary file, its program interpreter and shared libraries
+// including the symlink targets for the dependencies that are symlinks
+func (r *Resolver) AllDependencies(binFilePath string) ([]string, error) {
 	//binFilePath could point to an executable or a shared object
 	if !strings.HasPrefix(binFilePath, "/") {
 		return nil, ErrFilePathNotAbs
 	}
 
-	if _, err := os.Stat(binFilePath); err != nil {
+	libs, err := r.Dependencies(binFilePath)
+	if err != nil {
 		if os.IsNotExist(err) {
 			log.Debugf("sodeps.AllDependencies(%v): missing target - %v", binFilePath, err)
 		}
@@ -55,85 +62,23 @@ func AllDependencies(binFilePath string) ([]string, error) {
 		return nil, err
 	}
 
-	if isBin, _ := binfile.Detected(binFilePath); !isBin {
-		return nil, ErrFileNotBin
-	}
-
-	resolverExePath, err := exec.LookPath(resolverExeName)
-	if err != nil {
-		log.Debugf("sodeps.AllDependencies(%v): resolver not found - %v", binFilePath, err)
-		return nil, ErrDepResolverNotFound
-	}
-
-	var cerr bytes.Buffer
-	var cout bytes.Buffer
-	cmd := exec.Command(resolverExePath, binFilePath)
-	cmd.Stderr = &cerr
-	cmd.Stdout = &cout
-
-	if err := cmd.Start(); err != nil {
-		log.Debugf("sodeps.AllDependencies(%v): exe run error - %v", binFilePath, err)
-		return nil, err
-	}
-
-	if err := cmd.Wait(); err != nil {
-		log.Debugf("sodeps.AllDependencies(%v): exe error result - %v (stderr: '%v')", binFilePath, err, cerr.String())
-		return nil, err
-	}
-
-	if cout.Len() == 0 {
-		log.Debugf("sodeps.AllDependencies(%v): no results", binFilePath)
-		return nil, nil
-	}
-
 	var deps []string
 	deps = append(deps, binFilePath)
+	deps = append(deps, libs...)
 
-	lines := strings.Split(cout.String(), "\n")
-	for _, line := range lines {
-		clean := strings.TrimSpace(line)
-		if len(clean) == 0 {
-			continue
-		}
-
-		if strings.Contains(clean, "statically linked") {
-			log.Debugf("sodeps.AllDependencies(%v): statically linked binary file", binFilePath)
-			continue
-		}
-
-		parts := strings.Split(clean, " ")
-
-		if len(parts) == 4 {
-			if parts[1] != "=>" {
-				log.Debugf("sodeps.AllDependencies(%v): unexpected line format - '%v'", binFilePath, clean)
-				continue
-			}
-
-			deps = append(deps, parts[2])
-			continue
-		}
-
-		if len(parts) == 2 {
-			if strings.HasPrefix(parts[0], "/") {
-				//full path (dynamic linker)
-				deps = append(deps, parts[0])
-				continue
-			}
-
-			if strings.HasPrefix(parts[0], "linux-vdso") {
+	var allDeps []string
+	known := map[string]struct{}{}
+	for depth := 0; len(deps) > 0; depth++ {
+		var fileDeps []string
+		fileDeps, deps = r.resolveDepArtifacts(deps)
+		for _, name := range fileDeps {
+			if _, ok := known[name]; ok {
 				continue
 			}
 
-			log.Debugf("sodeps.AllDependencies(%v): unexpected line - '%v'", binFilePath, clean)
-			continue
+			known[name] = struct{}{}
+			allDeps = append(allDeps, name)
 		}
-	}
-
-	var allDeps []string
-	for depth := 0; len(deps) > 0; depth++ {
-		var fileDeps []string
-		fileDeps, deps = resolveDepArtifacts(deps)
-		allDeps = append(allDeps, fileDeps...)
 
 		if depth > 5 {
 			log.Debugf("sodeps.AllDependencies(%v): link ref too deep - breaking", binFilePath)
@@ -144,12 +89,12 @@ func AllDependencies(binFilePath string) ([]string, error) {
 	return allDeps, nil
 }
 
-func resolveDepArtifacts(names []string) (files, links []string) {
+func (r *Resolver) resolveDepArtifacts(names []string) (files, links []string) {
 	for _, name := range names {
-		if info, err := os.Lstat(name); err == nil {
+		if info, err := r.fs.Lstat(name); err == nil {
 			files = append(files, name)
 			if info.Mode()&os.ModeSymlink != 0 {
-				linkRef, err := os.Readlink(name)
+				linkRef, err := r.fs.Readlink(name)
 				if err != nil {
 					log.Debugf("sodeps.resolveDepArtifacts: %v - error reading link (%v)\n", name, err)
 					continue
@@ -137,6 +137,10 @@ func Run(errorCh chan error,
 				log.Debugf("fanmon: processor - [%v] handling event %v", fanReport.EventCount, e)
 
 				_, ok := origPaths[e.File]
+				if !ok && int(e.Pid) != fanReport.MonitorPid {
+					addNewFile(fanReport, &e)
+				}
+
 				if includeNew {
 					ok = true
 				}
@@ -209,6 +213,33 @@ func Run(errorCh chan error,
 	return resultChan
 }
 
+// addNewFile records the files created after the monitor started
+// (they are recorded even if the new files are not included in the process file data)
+func addNewFile(fanReport *report.FanMonitorReport, e *Event) {
+	if fanReport.NewFiles == nil {
+		fanReport.NewFiles = map[string]*report.FileInfo{}
+	}
+
+	fi, ok := fanReport.NewFiles[e.File]
+	if !ok {
+		fi = &report.FileInfo{
+			Name:         e.File,
+			FirstEventID: e.ID,
+		}
+
+		fanReport.NewFiles[e.File] = fi
+	}
+
+	fi.EventCount++
+	if e.IsRead {
+		fi.ReadCount++
+	}
+
+	if e.IsWrite {
+		fi.WriteCount++
+	}
+}
+
 func procFilePath(pid int, key string) string {
 	return fmt.Sprintf(procFsFilePath, pid, key)
 }
@@ -19,8 +19,11 @@ import (
 )
 
 type syscallEvent struct {
-	callNum uint32
-	retVal  uint64
+	callNum      uint32
+	retVal       uint64
+	argParams    []uint64
+	capArgParams []uint64
+	callContext  *system.CallContext
 }
 
 const (
@@ -58,6 +61,27 @@ func Run(
 	sysInfo := system.GetSystemInfo()
 	archName := system.MachineToArchName(sysInfo.Machine)
 	syscallResolver := system.CallNumberResolver(archName)
+	callNumResolver := system.CallNameResolver(archName)
+
+	//the high-risk syscalls with the recorded argument values (by syscall number)
+	recordedCallArgs := map[uint64]system.CallArgsInfo{}
+	if callNumResolver != nil {
+		for name, args := range system.RecordedCallArgs {
+			if num, ok := callNumResolver(name); ok {
+				recordedCallArgs[uint64(num)] = args
+			}
+		}
+	}
+
+	//the syscalls that need capabilities (by syscall number)
+	capCallArgs := map[uint64]system.CallArgsInfo{}
+	if callNumResolver != nil {
+		for name, info := range system.CallCapabilities {
+			if num, ok := callNumResolver(name); ok {
+				capCallArgs[uint64(num)] = info.Args
+			}
+		}
+	}
 
 	resultChan := make(chan *report.PtMonitorReport, 1)
 
@@ -142,6 +166,9 @@ func Run(
 			gotRetVal := false
 			var callNum uint64
 			var retVal uint64
+			var argParams []uint64
+			var capArgParams []uint64
+			var callContext *system.CallContext
 			for wstat.Stopped() {
 				var regs unix.PtraceRegsArm64
 
@@ -157,6 +184,24 @@ func Run(
 					syscallReturn = true
 					gotCallNum = true
 
+					params := callParams(regs)
+					readMem := callMemReader(targetPid)
+
+					argParams = nil
+					if args, ok := recordedCallArgs[callNum]; ok {
+						argParams = system.CallArgValues(args, params, readMem)
+					}
+
+					capArgParams = nil
+					callContext = nil
+					if args, ok := capCallArgs[callNum]; ok {
+						capArgParams = system.CallArgValues(args, params, readMem)
+						//the process state is captured before the call changes it
+						//(the target app is the application process tree root)
+						callContext = system.NewCallContext(syscallResolver(uint32(callNum)),
+							targetPid, targetPid, params, readMem)
+					}
+
 				case true:
 					if err := unix.PtraceGetRegSetArm64(targetPid, 1, &regs); err != nil {
 						//if err := syscall.PtraceGetRegs(pid, &regs); err != nil {
@@ -189,8 +234,11 @@ func Run(
 
 					select {
 					case eventChan <- syscallEvent{
-						callNum: uint32(callNum),
-						retVal:  retVal,
+						callNum:      uint32(callNum),
+						retVal:       retVal,
+						argParams:    argParams,
+						capArgParams: capArgParams,
+						callContext:  callContext,
 					}:
 					case <-stopChan:
 						log.Info("ptmon: collector - stopping...")
@@ -228,6 +276,21 @@ func Run(
 				} else {
 					syscallStats[e.callNum] = 1
 				}
+
+				if args, ok := recordedCallArgs[uint64(e.callNum)]; ok && len(e.argParams) > 0 {
+					//the recorded indexes are the indexes used to collect the values
+					ptReport.AddSyscallArgs(e.callNum,
+						syscallResolver(e.callNum),
+						args.Indexes,
+						e.argParams)
+				}
+
+				if _, ok := capCallArgs[uint64(e.callNum)]; ok && int64(e.retVal) >= 0 {
+					name := syscallResolver(e.callNum)
+					for _, capName := range system.CallUsedCapabilities(name, e.capArgParams, e.callContext) {
+						ptReport.AddCapabilityUse(capName, name)
+					}
+				}
 			}
 		}
 
@@ -249,3 +312,26 @@ func Run(
 
 	return resultChan
 }
+
+// callParams returns the syscall argument values
+func callParams(regs unix.PtraceRegsArm64) []uint64 {
+	return []uint64{
+		system.CallFirstParam(regs),
+		system.CallSecondParam(regs),
+		system.CallThirdParam(regs),
+		system.CallFourthParam(regs),
+		system.CallFifthParam(regs),
+	}
+}
+
+// callMemReader reads the traced process memory
+func callMemReader(pid int) system.CallMemReader {
+	return func(ptr uint64, out []byte) error {
+		if _, err := unix.PtracePeekData(pid, uintptr(ptr), out); err != nil {
+			log.Debugf("ptmon: collector - PtracePeekData error: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,163 @@
+// +build linux
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/report"
+	"github.com/docker-slim/docker-slim/pkg/system"
+)
+
+// osPackageDBFiles returns the OS package database files in the container filesystem
+func osPackageDBFiles() []string {
+	dbFiles := append([]string{}, system.OSPackageDBFiles...)
+	if matches, err := filepath.Glob(filepath.Join(system.DpkgStatusDir, "*")); err == nil {
+		dbFiles = append(dbFiles, matches...)
+	}
+
+	var result []string
+	for _, dbFile := range dbFiles {
+		if !system.IsOSPackageDBFile(dbFile) {
+			continue
+		}
+
+		if info, err := os.Stat(dbFile); err == nil && info.Mode().IsRegular() {
+			result = append(result, dbFile)
+		}
+	}
+
+	return result
+}
+
+// loadOSPackages loads the installed OS packages (with their file lists if available)
+func loadOSPackages() []*system.OSPackage {
+	var packages []*system.OSPackage
+	for _, dbFile := range osPackageDBFiles() {
+		raw, err := ioutil.ReadFile(dbFile)
+		if err != nil {
+			log.Debugf("sensor: loadOSPackages - error reading '%s' => %v", dbFile, err)
+			continue
+		}
+
+		dbPackages, err := system.NewOSPackagesFromData(dbFile, raw)
+		if err != nil {
+			log.Debugf("sensor: loadOSPackages - error parsing '%s' => %v", dbFile, err)
+			continue
+		}
+
+		if system.OSPackageManagerForDB(dbFile) == system.OSPackageManagerDpkg {
+			for _, pkg := range dbPackages {
+				pkg.Files = dpkgPackageFiles(dbFile, pkg)
+			}
+		}
+
+		packages = append(packages, dbPackages...)
+	}
+
+	return packages
+}
+
+func dpkgPackageFiles(dbFile string, pkg *system.OSPackage) []string {
+	if dbFile != system.DpkgStatusFile {
+		//the distroless package records have the file lists in the md5sums files
+		raw, err := ioutil.ReadFile(dbFile + ".md5sums")
+		if err != nil {
+			return nil
+		}
+
+		return system.ParseDpkgMd5sums(raw)
+	}
+
+	for _, listPath := range system.DpkgFileListPaths(pkg) {
+		raw, err := ioutil.ReadFile(listPath)
+		if err == nil {
+			return system.ParseDpkgFileList(raw)
+		}
+	}
+
+	return nil
+}
+
+// osPackagesInfo returns the OS packages with files in the saved artifacts
+func (p *artifactStore) osPackagesInfo() []*report.OSPackageInfo {
+	packages := loadOSPackages()
+	if len(packages) == 0 {
+		return nil
+	}
+
+	//the package file lists may use the paths with symlinked directories (e.g., '/lib' in merged-usr distros)
+	dirCache := map[string]string{}
+	resolvePath := func(name string) string {
+		dir, base := filepath.Split(name)
+		resolved, found := dirCache[dir]
+		if !found {
+			var err error
+			if resolved, err = filepath.EvalSymlinks(dir); err != nil {
+				resolved = dir
+			}
+
+			dirCache[dir] = resolved
+		}
+
+		return filepath.Join(resolved, base)
+	}
+
+	var result []*report.OSPackageInfo
+	for _, pkg := range packages {
+		var count int
+		for _, name := range pkg.Files {
+			if _, found := p.rawNames[name]; found {
+				count++
+				continue
+			}
+
+			if _, found := p.rawNames[resolvePath(name)]; found {
+				count++
+			}
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		result = append(result, &report.OSPackageInfo{
+			Name:          pkg.Name,
+			Version:       pkg.Version,
+			Arch:          pkg.Arch,
+			InstalledSize: pkg.InstalledSize,
+			Manager:       pkg.Manager,
+			Files:         count,
+		})
+	}
+
+	return result
+}
+
+func (p *artifactStore) appStacksInfo() []*report.AppStackInfo {
+	var result []*report.AppStackInfo
+	for _, appStack := range p.appStacks {
+		info := &report.AppStackInfo{
+			Language:  appStack.language,
+			CodeFiles: appStack.codeFiles,
+		}
+
+		for dir := range appStack.packageDirs {
+			info.PackageDirs = append(info.PackageDirs, dir)
+		}
+
+		sort.Strings(info.PackageDirs)
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Language < result[j].Language
+	})
+
+	return result
+}
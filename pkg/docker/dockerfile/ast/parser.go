@@ -308,6 +308,10 @@ func Parse(rwc io.Reader) (*Result, error) {
 
 		var hasEmptyContinuationLine bool
 		for !isEndOfLine && scanner.Scan() {
+			//the raw lines include the continuation lines
+			//(so the instruction line numbers match the raw lines)
+			lines = append(lines, scanner.Text())
+
 			bytesRead, err := processLine(d, scanner.Bytes(), false)
 			if err != nil {
 				return nil, err
@@ -103,17 +103,7 @@ func FromFile(fpath string) (*spec.Dockerfile, error) {
 				}
 
 				if len(inst.Args) > 0 {
-					var parts []string
-					var hasDigest bool
-					switch {
-					case strings.Contains(inst.Args[0], ":"):
-						parts = strings.Split(inst.Args[0], ":")
-					case strings.Contains(inst.Args[0], "@"):
-						parts = strings.Split(inst.Args[0], "@")
-						hasDigest = true
-					default:
-						parts = append(parts, inst.Args[0])
-					}
+					parts, hasDigest := splitImageRef(inst.Args[0])
 
 					if len(parts) > 0 {
 						if len(parts[0]) > 0 {
@@ -128,20 +118,8 @@ func FromFile(fpath string) (*spec.Dockerfile, error) {
 
 								if len(parts) == 1 {
 									if len(argVal) > 0 {
-										switch {
-										case strings.Contains(argVal, ":"):
-											parts = strings.Split(argVal, ":")
-										case strings.Contains(argVal, "@"):
-											parts = strings.Split(argVal, "@")
-											hasDigest = true
-										default:
-											parts = nil
-											parts = append(parts, argVal)
-										}
-
-										if len(parts) == 1 {
-											currentStage.Parent.Name = parts[0]
-										}
+										parts, hasDigest = splitImageRef(argVal)
+										currentStage.Parent.Name = parts[0]
 									}
 									currentStage.Parent.BuildArgAll = argName
 								} else {
@@ -321,6 +299,25 @@ func FromFile(fpath string) (*spec.Dockerfile, error) {
 	return dockerfile, nil
 }
 
+// splitImageRef splits the image reference into the image name and its tag or digest
+// (the registry host can have a port, so the tag is after the last ':' in the last path element)
+func splitImageRef(ref string) ([]string, bool) {
+	if idx := strings.Index(ref, "@"); idx > -1 {
+		name := ref[:idx]
+		if tagIdx := strings.LastIndex(name, ":"); tagIdx > strings.LastIndex(name, "/") {
+			name = name[:tagIdx]
+		}
+
+		return []string{name, ref[idx+1:]}, true
+	}
+
+	if idx := strings.LastIndex(ref, ":"); idx > strings.LastIndex(ref, "/") {
+		return []string{ref[:idx], ref[idx+1:]}, false
+	}
+
+	return []string{ref}, false
+}
+
 func GetRefName(ref string) string {
 	ref = strings.TrimPrefix(ref, "$")
 	if strings.HasPrefix(ref, "{") && strings.HasSuffix(ref, "}") {
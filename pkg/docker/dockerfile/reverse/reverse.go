@@ -94,6 +94,12 @@ func DockerfileFromHistory(apiClient *docker.Client, imageID string) (*Dockerfil
 		return nil, err
 	}
 
+	return DockerfileFromHistoryData(imageHistory)
+}
+
+// DockerfileFromHistoryData recreates Dockerfile information from container image history records
+// (the records are in the Docker 'history' API order - the latest instruction first)
+func DockerfileFromHistoryData(imageHistory []docker.ImageHistory) (*Dockerfile, error) {
 	var out Dockerfile
 
 	log.Debugf("\n\nIMAGE HISTORY =>\n%#v\n\n", imageHistory)
@@ -82,6 +82,7 @@ type ImageReport struct {
 	Duplicates   map[string]*DuplicateFilesReport `json:"duplicates,omitempty"`
 	SpecialPerms *SpecialPermsInfo                `json:"special_perms,omitempty"`
 	OSShells     []*system.OSShell                `json:"shells,omitempty"`
+	OSPackages   *OSPackagesInfo                  `json:"os_packages,omitempty"`
 	Certs        CertsInfo                        `json:"certs"`
 	CACerts      CertsInfo                        `json:"ca_certs"`
 }
@@ -138,6 +139,7 @@ type Layer struct {
 	References          map[string]*ObjectMetadata
 	Top                 TopObjects
 	Distro              *system.DistroInfo
+	OSPackages          map[string][]*system.OSPackage    //package DB file -> installed packages
 	DataMatches         map[string][]*ChangeDataMatcher   //object.Name -> matched CDM
 	DataHashMatches     map[string]*ChangeDataHashMatcher //object.Name -> matched CDHM
 	pathMatches         bool
@@ -445,6 +447,7 @@ func newLayer(id string, topChangesMax int) *Layer {
 		Index:           -1,
 		References:      map[string]*ObjectMetadata{},
 		Top:             NewTopObjects(topChangesCount),
+		OSPackages:      map[string][]*system.OSPackage{},
 		DataMatches:     map[string][]*ChangeDataMatcher{},
 		DataHashMatches: map[string]*ChangeDataHashMatcher{},
 	}
@@ -602,24 +605,39 @@ func LoadPackage(archivePath string,
 			imageID, archivePath, archiveFiles)
 	}
 
+	if err := processLayers(pkg, layers, archivePath, imageID, utf8Detector, doDetectDuplicates); err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+// processLayers links the loaded layers (in the manifest order) and collects the change history
+func processLayers(
+	pkg *Package,
+	layers map[string]*Layer,
+	sourceName string,
+	imageID string,
+	utf8Detector *UTF8Detector,
+	doDetectDuplicates bool) error {
 	for idx, layerPath := range pkg.Manifest.Layers {
 		parts := strings.Split(layerPath, "/")
 		layerID := parts[0]
 		layer, ok := layers[layerID]
 		if !ok {
-			log.Errorf("dockerimage.LoadPackage: error missing layer in archive(%v/%v) - %v", archivePath, layerPath, err)
-			return nil, fmt.Errorf("dockerimage.LoadPackage: missing layer (%v) for image ID - %v", layerPath, imageID)
+			log.Errorf("dockerimage.processLayers: error missing layer in image source(%v/%v)", sourceName, layerPath)
+			return fmt.Errorf("dockerimage.processLayers: missing layer (%v) for image ID - %v", layerPath, imageID)
 		}
 
 		layer.Index = idx
 		//adding layers based on their manifest order
 		pkg.Layers = append(pkg.Layers, layer)
 		if len(pkg.Layers)-1 != layer.Index {
-			return nil, fmt.Errorf("dockerimage.LoadPackage: layer index mismatch - %v / %v", len(pkg.Layers)-1, layer.Index)
+			return fmt.Errorf("dockerimage.processLayers: layer index mismatch - %v / %v", len(pkg.Layers)-1, layer.Index)
 		}
 
 		if layerPath != layer.Path {
-			return nil, fmt.Errorf("dockerimage.LoadPackage: layer path mismatch - %v / %v", layerPath, layer.Path)
+			return fmt.Errorf("dockerimage.processLayers: layer path mismatch - %v / %v", layerPath, layer.Path)
 		}
 
 		if idx == 0 {
@@ -796,7 +814,7 @@ func LoadPackage(archivePath string,
 			diffID := pkg.Config.RootFS.DiffIDs[idx]
 			layer.FSDiffID = diffID
 		} else {
-			log.Debugf("dockerimage.LoadPackage: no FS diff for layer index %v", idx)
+			log.Debugf("dockerimage.processLayers: no FS diff for layer index %v", idx)
 		}
 	}
 
@@ -851,7 +869,7 @@ func LoadPackage(archivePath string,
 		}
 	}
 
-	return pkg, nil
+	return nil
 }
 
 func hasChangePathMatcherDumps(changePathMatchers []*ChangePathMatcher) bool {
@@ -1195,6 +1213,7 @@ func inspectFile(
 
 	if system.IsOSReleaseFile(fullPath) ||
 		system.IsOSShellsFile(fullPath) ||
+		system.IsOSPackageDBFile(fullPath) ||
 		len(changeDataMatchers) > 0 ||
 		cpmDumps ||
 		cdhmDumps ||
@@ -1243,6 +1262,15 @@ func inspectFile(
 			}
 		}
 
+		if system.IsOSPackageDBFile(fullPath) {
+			packages, err := system.NewOSPackagesFromData(fullPath, data)
+			if err != nil {
+				log.Debugf("dockerimage.inspectFile: error parsing package DB (%s) - %v", fullPath, err)
+			} else {
+				layer.OSPackages[fullPath] = packages
+			}
+		}
+
 		if system.IsOSReleaseFile(fullPath) {
 			osr, err := system.NewOsRelease(data)
 			if err != nil {
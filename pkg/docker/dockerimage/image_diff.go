@@ -0,0 +1,441 @@
+package dockerimage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/system"
+)
+
+// Default number of directory levels used to aggregate the size deltas
+const DefaultDiffDirDepth = 3
+
+// ImageDiffReport describes the differences between the final filesystems and configs of two images
+type ImageDiffReport struct {
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	Stats       ImageDiffStats     `json:"stats"`
+	Config      []*ConfigFieldDiff `json:"config,omitempty"`
+	Packages    *OSPackagesDiff    `json:"packages,omitempty"`
+	Directories []*DirectoryDiff   `json:"directories,omitempty"`
+	Added       []*ObjectDiff      `json:"added,omitempty"`
+	Removed     []*ObjectDiff      `json:"removed,omitempty"`
+	Modified    []*ObjectDiff      `json:"modified,omitempty"`
+}
+
+type ImageDiffStats struct {
+	FromObjectCount   uint64 `json:"from_object_count"`
+	ToObjectCount     uint64 `json:"to_object_count"`
+	FromSize          uint64 `json:"from_size"`
+	ToSize            uint64 `json:"to_size"`
+	SizeDelta         int64  `json:"size_delta"`
+	AddedCount        uint64 `json:"added_count"`
+	AddedSize         uint64 `json:"added_size"`
+	RemovedCount      uint64 `json:"removed_count"`
+	RemovedSize       uint64 `json:"removed_size"`
+	ModifiedCount     uint64 `json:"modified_count"`
+	ModifiedSizeDelta int64  `json:"modified_size_delta"`
+}
+
+// ObjectDiff describes an added, removed or modified filesystem object
+type ObjectDiff struct {
+	Name     string   `json:"name"`
+	FromSize int64    `json:"from_size,omitempty"`
+	ToSize   int64    `json:"to_size,omitempty"`
+	Layer    int      `json:"layer"`             //layer index in the image where the object comes from
+	Changes  []string `json:"changes,omitempty"` //changed object attributes (type, size, data, mode, owner, link)
+}
+
+// DirectoryDiff describes the size and object count deltas for a directory
+type DirectoryDiff struct {
+	Path      string `json:"path"`
+	FromSize  uint64 `json:"from_size"`
+	ToSize    uint64 `json:"to_size"`
+	SizeDelta int64  `json:"size_delta"`
+	FromCount uint64 `json:"from_count"`
+	ToCount   uint64 `json:"to_count"`
+}
+
+// ConfigFieldDiff describes a changed image config field
+// (the list and map fields use the 'added' and 'removed' values)
+type ConfigFieldDiff struct {
+	Field   string      `json:"field"`
+	From    interface{} `json:"from,omitempty"`
+	To      interface{} `json:"to,omitempty"`
+	Added   []string    `json:"added,omitempty"`
+	Removed []string    `json:"removed,omitempty"`
+}
+
+type OSPackagesDiff struct {
+	FromCount int                 `json:"from_count"`
+	ToCount   int                 `json:"to_count"`
+	Added     []*system.OSPackage `json:"added,omitempty"`
+	Removed   []*system.OSPackage `json:"removed,omitempty"`
+	Changed   []*OSPackageChange  `json:"changed,omitempty"`
+}
+
+type OSPackageChange struct {
+	Name        string `json:"name"`
+	Arch        string `json:"arch,omitempty"`
+	Manager     string `json:"manager"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+}
+
+// Object change attributes
+const (
+	ObjectChangeType  = "type"
+	ObjectChangeSize  = "size"
+	ObjectChangeData  = "data"
+	ObjectChangeMode  = "mode"
+	ObjectChangeOwner = "owner"
+	ObjectChangeLink  = "link"
+)
+
+// DiffPackages compares the final filesystems, configs and OS packages for two image packages
+// (the data changes for the objects with the same size are detected only if the packages have the data hashes)
+func DiffPackages(from, to *Package, dirDepth int) *ImageDiffReport {
+	report := &ImageDiffReport{}
+
+	fromObjects := from.FinalObjects()
+	toObjects := to.FinalObjects()
+
+	dirs := map[string]*DirectoryDiff{}
+	dirInfo := func(name string) []*DirectoryDiff {
+		var result []*DirectoryDiff
+		for _, dir := range objectDirs(name, dirDepth) {
+			info, found := dirs[dir]
+			if !found {
+				info = &DirectoryDiff{Path: dir}
+				dirs[dir] = info
+			}
+
+			result = append(result, info)
+		}
+
+		return result
+	}
+
+	for name, fromObject := range fromObjects {
+		report.Stats.FromObjectCount++
+		report.Stats.FromSize += uint64(fromObject.Size)
+		for _, info := range dirInfo(name) {
+			info.FromCount++
+			info.FromSize += uint64(fromObject.Size)
+		}
+
+		toObject, found := toObjects[name]
+		if !found {
+			report.Stats.RemovedCount++
+			report.Stats.RemovedSize += uint64(fromObject.Size)
+			report.Removed = append(report.Removed,
+				&ObjectDiff{
+					Name:     name,
+					FromSize: fromObject.Size,
+					Layer:    fromObject.LayerIndex,
+				})
+			continue
+		}
+
+		if changes := objectChanges(fromObject, toObject); len(changes) > 0 {
+			report.Stats.ModifiedCount++
+			report.Stats.ModifiedSizeDelta += toObject.Size - fromObject.Size
+			report.Modified = append(report.Modified,
+				&ObjectDiff{
+					Name:     name,
+					FromSize: fromObject.Size,
+					ToSize:   toObject.Size,
+					Layer:    toObject.LayerIndex,
+					Changes:  changes,
+				})
+		}
+	}
+
+	for name, toObject := range toObjects {
+		report.Stats.ToObjectCount++
+		report.Stats.ToSize += uint64(toObject.Size)
+		for _, info := range dirInfo(name) {
+			info.ToCount++
+			info.ToSize += uint64(toObject.Size)
+		}
+
+		if _, found := fromObjects[name]; !found {
+			report.Stats.AddedCount++
+			report.Stats.AddedSize += uint64(toObject.Size)
+			report.Added = append(report.Added,
+				&ObjectDiff{
+					Name:   name,
+					ToSize: toObject.Size,
+					Layer:  toObject.LayerIndex,
+				})
+		}
+	}
+
+	report.Stats.SizeDelta = int64(report.Stats.ToSize) - int64(report.Stats.FromSize)
+
+	for _, info := range dirs {
+		info.SizeDelta = int64(info.ToSize) - int64(info.FromSize)
+		if info.SizeDelta != 0 || info.FromCount != info.ToCount {
+			report.Directories = append(report.Directories, info)
+		}
+	}
+
+	sort.Slice(report.Directories, func(i, j int) bool {
+		di := absInt64(report.Directories[i].SizeDelta)
+		dj := absInt64(report.Directories[j].SizeDelta)
+		if di != dj {
+			return di > dj
+		}
+
+		return report.Directories[i].Path < report.Directories[j].Path
+	})
+
+	sortObjectDiffs(report.Added)
+	sortObjectDiffs(report.Removed)
+	sortObjectDiffs(report.Modified)
+
+	report.Config = diffConfigs(from.Config, to.Config)
+	report.Packages = diffOSPackages(
+		installedOSPackages(from, fromObjects),
+		installedOSPackages(to, toObjects))
+
+	return report
+}
+
+func objectChanges(from, to *ObjectMetadata) []string {
+	var changes []string
+	if from.Mode&os.ModeType != to.Mode&os.ModeType {
+		changes = append(changes, ObjectChangeType)
+	}
+
+	if from.Size != to.Size {
+		changes = append(changes, ObjectChangeSize)
+	}
+
+	if from.Hash != "" && to.Hash != "" && from.Hash != to.Hash {
+		changes = append(changes, ObjectChangeData)
+	}
+
+	if from.Mode&^os.ModeType != to.Mode&^os.ModeType {
+		changes = append(changes, ObjectChangeMode)
+	}
+
+	if from.UID != to.UID || from.GID != to.GID {
+		changes = append(changes, ObjectChangeOwner)
+	}
+
+	if from.LinkTarget != to.LinkTarget {
+		changes = append(changes, ObjectChangeLink)
+	}
+
+	return changes
+}
+
+func objectDirs(name string, depth int) []string {
+	var dirs []string
+	parts := strings.Split(strings.Trim(filepath.Dir(name), "/"), "/")
+	for i := 0; i < len(parts) && i < depth; i++ {
+		if parts[i] == "" {
+			break
+		}
+
+		dirs = append(dirs, "/"+strings.Join(parts[:i+1], "/"))
+	}
+
+	return dirs
+}
+
+func sortObjectDiffs(list []*ObjectDiff) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+}
+
+func absInt64(val int64) int64 {
+	if val < 0 {
+		return -val
+	}
+
+	return val
+}
+
+func diffConfigs(from, to *ConfigObject) []*ConfigFieldDiff {
+	fromCC := &ContainerConfig{}
+	toCC := &ContainerConfig{}
+	var fromOS, toOS, fromArch, toArch string
+	if from != nil {
+		fromOS = from.OS
+		fromArch = from.Architecture
+		if from.Config != nil {
+			fromCC = from.Config
+		}
+	}
+
+	if to != nil {
+		toOS = to.OS
+		toArch = to.Architecture
+		if to.Config != nil {
+			toCC = to.Config
+		}
+	}
+
+	var diffs []*ConfigFieldDiff
+	addValue := func(field string, fromVal, toVal interface{}) {
+		if !reflect.DeepEqual(fromVal, toVal) {
+			diffs = append(diffs, &ConfigFieldDiff{
+				Field: field,
+				From:  fromVal,
+				To:    toVal,
+			})
+		}
+	}
+
+	addSet := func(field string, fromVals, toVals []string) {
+		added, removed := diffStringSets(fromVals, toVals)
+		if len(added) > 0 || len(removed) > 0 {
+			diffs = append(diffs, &ConfigFieldDiff{
+				Field:   field,
+				Added:   added,
+				Removed: removed,
+			})
+		}
+	}
+
+	addValue("os", fromOS, toOS)
+	addValue("architecture", fromArch, toArch)
+	addValue("user", fromCC.User, toCC.User)
+	addValue("workdir", fromCC.WorkingDir, toCC.WorkingDir)
+	addValue("entrypoint", emptyAsNil(fromCC.Entrypoint), emptyAsNil(toCC.Entrypoint))
+	addValue("cmd", emptyAsNil(fromCC.Cmd), emptyAsNil(toCC.Cmd))
+	addValue("shell", emptyAsNil(fromCC.Shell), emptyAsNil(toCC.Shell))
+	addValue("stop_signal", fromCC.StopSignal, toCC.StopSignal)
+	addSet("env", fromCC.Env, toCC.Env)
+	addSet("exposed_ports", setKeys(fromCC.ExposedPorts), setKeys(toCC.ExposedPorts))
+	addSet("volumes", setKeys(fromCC.Volumes), setKeys(toCC.Volumes))
+	addSet("labels", labelList(fromCC.Labels), labelList(toCC.Labels))
+	addSet("onbuild", fromCC.OnBuild, toCC.OnBuild)
+
+	return diffs
+}
+
+func diffStringSets(from, to []string) ([]string, []string) {
+	fromSet := map[string]struct{}{}
+	for _, v := range from {
+		fromSet[v] = struct{}{}
+	}
+
+	toSet := map[string]struct{}{}
+	for _, v := range to {
+		toSet[v] = struct{}{}
+	}
+
+	var added, removed []string
+	for v := range toSet {
+		if _, found := fromSet[v]; !found {
+			added = append(added, v)
+		}
+	}
+
+	for v := range fromSet {
+		if _, found := toSet[v]; !found {
+			removed = append(removed, v)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func emptyAsNil(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list
+}
+
+func setKeys(set map[string]struct{}) []string {
+	var keys []string
+	for k := range set {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func labelList(labels map[string]string) []string {
+	var list []string
+	for k, v := range labels {
+		list = append(list, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	return list
+}
+
+func diffOSPackages(from, to []*system.OSPackage) *OSPackagesDiff {
+	if len(from) == 0 && len(to) == 0 {
+		return nil
+	}
+
+	diff := &OSPackagesDiff{
+		FromCount: len(from),
+		ToCount:   len(to),
+	}
+
+	fromPackages := map[string]*system.OSPackage{}
+	for _, p := range from {
+		fromPackages[osPackageKey(p)] = p
+	}
+
+	toPackages := map[string]*system.OSPackage{}
+	for _, p := range to {
+		toPackages[osPackageKey(p)] = p
+	}
+
+	for k, fp := range fromPackages {
+		tp, found := toPackages[k]
+		if !found {
+			diff.Removed = append(diff.Removed, fp)
+			continue
+		}
+
+		if fp.Version != tp.Version {
+			diff.Changed = append(diff.Changed, &OSPackageChange{
+				Name:        fp.Name,
+				Arch:        fp.Arch,
+				Manager:     fp.Manager,
+				FromVersion: fp.Version,
+				ToVersion:   tp.Version,
+			})
+		}
+	}
+
+	for k, tp := range toPackages {
+		if _, found := fromPackages[k]; !found {
+			diff.Added = append(diff.Added, tp)
+		}
+	}
+
+	sortOSPackages(diff.Added)
+	sortOSPackages(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Name < diff.Changed[j].Name
+	})
+
+	return diff
+}
+
+func sortOSPackages(list []*system.OSPackage) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+
+		return list[i].Arch < list[j].Arch
+	})
+}
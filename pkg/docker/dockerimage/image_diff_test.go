@@ -0,0 +1,220 @@
+package dockerimage
+
+import (
+	"archive/tar"
+	"os"
+	"reflect"
+	"testing"
+
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestFinalObjectsWhiteouts(t *testing.T) {
+	base := testLayerTar(t,
+		testDir("etc/"),
+		testFile("etc/hosts", "127.0.0.1 localhost\n"),
+		testFile("etc/motd", "hello\n"),
+		testDir("var/cache/"),
+		testFile("var/cache/a", "a"),
+		testFile("var/cache/b", "b"),
+		testDir("opt/app/"),
+		testFile("opt/app/bin", "bin"),
+		testFile("opt/app/lib/libapp.so", "lib"))
+
+	tt := []struct {
+		desc     string
+		layer    []testEntry
+		expected []string
+	}{
+		{
+			desc:  "file whiteout",
+			layer: []testEntry{testFile("etc/.wh.motd", "")},
+			expected: []string{
+				"/etc", "/etc/hosts",
+				"/opt/app", "/opt/app/bin", "/opt/app/lib/libapp.so",
+				"/var/cache", "/var/cache/a", "/var/cache/b",
+			},
+		},
+		{
+			desc:  "directory whiteout",
+			layer: []testEntry{testFile("opt/.wh.app", "")},
+			expected: []string{
+				"/etc", "/etc/hosts", "/etc/motd",
+				"/var/cache", "/var/cache/a", "/var/cache/b",
+			},
+		},
+		{
+			//the opaque directory keeps the files added in the same layer
+			desc: "opaque whiteout",
+			layer: []testEntry{
+				testDir("var/cache/"),
+				testFile("var/cache/.wh..wh..opq", ""),
+				testFile("var/cache/c", "c"),
+			},
+			expected: []string{
+				"/etc", "/etc/hosts", "/etc/motd",
+				"/opt/app", "/opt/app/bin", "/opt/app/lib/libapp.so",
+				"/var/cache", "/var/cache/c",
+			},
+		},
+		{
+			//the whiteout applies only to the lower layers
+			desc: "recreated file",
+			layer: []testEntry{
+				testFile("etc/.wh.motd", ""),
+				testFile("etc/motd", "recreated\n"),
+			},
+			expected: []string{
+				"/etc", "/etc/hosts", "/etc/motd",
+				"/opt/app", "/opt/app/bin", "/opt/app/lib/libapp.so",
+				"/var/cache", "/var/cache/a", "/var/cache/b",
+			},
+		},
+	}
+
+	for _, test := range tt {
+		pkg := testPackage(t, testImage(t, gocrv1.Config{}, base, testLayerTar(t, test.layer...)))
+		if got := finalNames(pkg); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s:\ngot      %q\nexpected %q", test.desc, got, test.expected)
+		}
+	}
+}
+
+func diffNames(list []*ObjectDiff) []string {
+	var names []string
+	for _, info := range list {
+		names = append(names, info.Name)
+	}
+
+	return names
+}
+
+func TestDiffPackages(t *testing.T) {
+	base := testLayerTar(t,
+		testDir("app/"),
+		testFile("app/config.yaml", "port: 8080\n"),
+		testFile("app/data.txt", "0123456789"),
+		testFile("app/main.sh", "#!/bin/sh\n"),
+		testFile("app/removed.txt", "removed"),
+		testSymlink("app/current", "/app/v1"),
+		testDir("app/cache/"),
+		testFile("app/cache/old", "old data"))
+
+	fromImage := testImage(t,
+		gocrv1.Config{
+			Entrypoint: []string{"/app/main.sh"},
+			Env:        []string{"PATH=/bin", "MODE=dev"},
+			Labels:     map[string]string{"version": "1"},
+			User:       "app",
+		},
+		base)
+
+	toImage := testImage(t,
+		gocrv1.Config{
+			Entrypoint: []string{"/app/main.sh", "--prod"},
+			Env:        []string{"PATH=/bin", "MODE=prod"},
+			Labels:     map[string]string{"version": "1"},
+			User:       "app",
+		},
+		base,
+		testLayerTar(t,
+			testFile("app/.wh.removed.txt", ""),
+			//same size with different data
+			testFile("app/config.yaml", "port: 9090\n"),
+			testFile("app/data.txt", "0123"),
+			testEntry{name: "app/main.sh", data: "#!/bin/sh\n", mode: 0755},
+			testSymlink("app/current", "/app/v2"),
+			testFile("app/cache/.wh..wh..opq", ""),
+			testFile("app/added.txt", "added")))
+
+	from := testPackage(t, fromImage)
+	to := testPackage(t, toImage)
+	report := DiffPackages(from, to, 2)
+
+	if got, expected := diffNames(report.Added), []string{"/app/added.txt"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("added: got %q expected %q", got, expected)
+	}
+
+	if got, expected := diffNames(report.Removed), []string{"/app/cache/old", "/app/removed.txt"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("removed: got %q expected %q", got, expected)
+	}
+
+	expectedChanges := map[string][]string{
+		"/app/config.yaml": {ObjectChangeData},
+		"/app/current":     {ObjectChangeLink},
+		"/app/data.txt":    {ObjectChangeSize, ObjectChangeData},
+		"/app/main.sh":     {ObjectChangeMode},
+	}
+
+	changes := map[string][]string{}
+	for _, info := range report.Modified {
+		changes[info.Name] = info.Changes
+		if info.Layer != 1 {
+			t.Errorf("%s: unexpected modified object layer - %d", info.Name, info.Layer)
+		}
+	}
+
+	if !reflect.DeepEqual(changes, expectedChanges) {
+		t.Errorf("modified:\ngot      %v\nexpected %v", changes, expectedChanges)
+	}
+
+	stats := report.Stats
+	if stats.AddedCount != 1 || stats.AddedSize != 5 ||
+		stats.RemovedCount != 2 || stats.RemovedSize != 15 ||
+		stats.ModifiedCount != 4 || stats.ModifiedSizeDelta != -6 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if stats.SizeDelta != int64(stats.ToSize)-int64(stats.FromSize) || stats.SizeDelta != 5-15-6 {
+		t.Errorf("unexpected size delta: %+v", stats)
+	}
+
+	//the directories are sorted by the size delta
+	if len(report.Directories) == 0 || report.Directories[0].Path != "/app" || report.Directories[0].SizeDelta != -16 {
+		t.Errorf("unexpected directory diffs: %+v", report.Directories[0])
+	}
+
+	expectedConfig := []*ConfigFieldDiff{
+		{Field: "entrypoint", From: []string{"/app/main.sh"}, To: []string{"/app/main.sh", "--prod"}},
+		{Field: "env", Added: []string{"MODE=prod"}, Removed: []string{"MODE=dev"}},
+	}
+
+	if !reflect.DeepEqual(report.Config, expectedConfig) {
+		for _, diff := range report.Config {
+			t.Logf("config diff: %+v", diff)
+		}
+
+		t.Errorf("unexpected config diffs")
+	}
+
+	if report.Packages != nil {
+		t.Errorf("unexpected package diff: %+v", report.Packages)
+	}
+}
+
+func TestObjectChanges(t *testing.T) {
+	file := ObjectMetadata{Name: "/app/file", Size: 10, Mode: 0644, Hash: "a", TypeFlag: tar.TypeReg}
+
+	tt := []struct {
+		desc     string
+		change   func(object *ObjectMetadata)
+		expected []string
+	}{
+		{desc: "same", change: func(object *ObjectMetadata) {}},
+		{desc: "type", change: func(object *ObjectMetadata) { object.Mode = os.ModeDir | 0644; object.Hash = "" }, expected: []string{ObjectChangeType}},
+		{desc: "size and data", change: func(object *ObjectMetadata) { object.Size = 20; object.Hash = "b" }, expected: []string{ObjectChangeSize, ObjectChangeData}},
+		//the data is compared only if both objects have the data hashes
+		{desc: "no hash", change: func(object *ObjectMetadata) { object.Hash = "" }},
+		{desc: "mode", change: func(object *ObjectMetadata) { object.Mode = 0755 }, expected: []string{ObjectChangeMode}},
+		{desc: "owner", change: func(object *ObjectMetadata) { object.GID = 1000 }, expected: []string{ObjectChangeOwner}},
+		{desc: "link", change: func(object *ObjectMetadata) { object.LinkTarget = "/app/other" }, expected: []string{ObjectChangeLink}},
+	}
+
+	for _, test := range tt {
+		from, to := file, file
+		test.change(&to)
+		if got := objectChanges(&from, &to); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: got %v expected %v", test.desc, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package dockerimage
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxFSSymlinks = 40
+)
+
+// FileSystem errors
+var (
+	ErrTooManySymlinks = errors.New("too many levels of symbolic links")
+	ErrNoFileData      = errors.New("no file data")
+)
+
+// FileSystem provides read access to the final (merged) image filesystem
+// using the file data in the saved image archive (without extracting the layers)
+type FileSystem struct {
+	archivePath string
+	objects     map[string]*ObjectMetadata
+	children    map[string][]string
+	data        map[*ObjectMetadata]*objectData
+}
+
+// the file data location in the image archive
+type objectData struct {
+	offset int64
+	size   int64
+}
+
+// NewFileSystem creates a new filesystem for the image package loaded from the image archive
+// (indexing the file data locations in the layer data)
+func NewFileSystem(archivePath string, pkg *Package) (*FileSystem, error) {
+	fs := &FileSystem{
+		archivePath: archivePath,
+		objects:     pkg.FinalObjects(),
+		children:    map[string][]string{},
+		data:        map[*ObjectMetadata]*objectData{},
+	}
+
+	for name := range fs.objects {
+		if name == "/" {
+			continue
+		}
+
+		//the parent directories might not have their own objects in the layer data
+		for child, dir := name, path.Dir(name); ; child, dir = dir, path.Dir(dir) {
+			_, found := fs.children[dir]
+			fs.children[dir] = append(fs.children[dir], path.Base(child))
+			if found || dir == "/" {
+				break
+			}
+		}
+	}
+
+	for dir, names := range fs.children {
+		fs.children[dir] = uniqueSortedNames(names)
+	}
+
+	//the layers using the data in each layer archive file
+	dataLayers := map[string][]*Layer{}
+	for _, layer := range pkg.Layers {
+		layerPath := layer.Path
+		if layer.LayerDataSource != "" {
+			layerPath = fmt.Sprintf("%s%s", layer.LayerDataSource, layerSuffix)
+		}
+
+		dataLayers[layerPath] = append(dataLayers[layerPath], layer)
+	}
+
+	if err := fs.indexData(dataLayers); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+func (fs *FileSystem) indexData(dataLayers map[string][]*Layer) error {
+	afile, err := os.Open(fs.archivePath)
+	if err != nil {
+		return err
+	}
+
+	defer afile.Close()
+
+	ar := &countingReader{r: afile}
+	tr := tar.NewReader(ar)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			log.Errorf("dockerimage.FileSystem.indexData: error reading archive(%v) - %v", fs.archivePath, err)
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		layers := dataLayers[filepath.Clean(hdr.Name)]
+		if len(layers) == 0 {
+			continue
+		}
+
+		layerOffset := ar.n
+		lr := &countingReader{r: tr}
+		ltr := tar.NewReader(lr)
+		for {
+			lhdr, err := ltr.Next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				log.Errorf("dockerimage.FileSystem.indexData: error reading layer(%v) - %v", hdr.Name, err)
+				return err
+			}
+
+			if lhdr.Typeflag != tar.TypeReg {
+				continue
+			}
+
+			name := fmt.Sprintf("/%s", filepath.Clean(lhdr.Name))
+			name = strings.ReplaceAll(name, "//", "/")
+			object, found := fs.objects[name]
+			if !found {
+				continue
+			}
+
+			for _, layer := range layers {
+				if layer.References[name] == object {
+					fs.data[object] = &objectData{
+						offset: layerOffset + lr.n,
+						size:   lhdr.Size,
+					}
+
+					break
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Lstat returns the file info without following the symlink in the last path element
+func (fs *FileSystem) Lstat(name string) (os.FileInfo, error) {
+	fullPath, object, err := fs.resolve(name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if object == nil {
+		return &objectFileInfo{name: path.Base(fullPath), mode: os.ModeDir | 0755}, nil
+	}
+
+	return newObjectFileInfo(object), nil
+}
+
+// Stat returns the file info following the symlinks
+func (fs *FileSystem) Stat(name string) (os.FileInfo, error) {
+	fullPath, object, err := fs.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if object == nil {
+		return &objectFileInfo{name: path.Base(fullPath), mode: os.ModeDir | 0755}, nil
+	}
+
+	return newObjectFileInfo(object), nil
+}
+
+// RealPath returns the path with all symlinks resolved
+func (fs *FileSystem) RealPath(name string) (string, error) {
+	fullPath, _, err := fs.resolve(name, true)
+	return fullPath, err
+}
+
+// Readlink returns the symlink target
+func (fs *FileSystem) Readlink(name string) (string, error) {
+	_, object, err := fs.resolve(name, false)
+	if err != nil {
+		return "", err
+	}
+
+	if object == nil || object.TypeFlag != tar.TypeSymlink {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: os.ErrInvalid}
+	}
+
+	return object.LinkTarget, nil
+}
+
+// ReadDir returns the directory entries sorted by name
+func (fs *FileSystem) ReadDir(name string) ([]os.FileInfo, error) {
+	fullPath, object, err := fs.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if object != nil && !object.Mode.IsDir() {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: os.ErrInvalid}
+	}
+
+	var entries []os.FileInfo
+	for _, childName := range fs.children[fullPath] {
+		info, err := fs.Lstat(path.Join(fullPath, childName))
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, info)
+	}
+
+	return entries, nil
+}
+
+// Open opens the regular file (following the symlinks)
+func (fs *FileSystem) Open(name string) (*File, error) {
+	_, object, err := fs.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if object == nil || !object.Mode.IsRegular() {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrInvalid}
+	}
+
+	ref, found := fs.data[object]
+	if !found {
+		if object.Size == 0 {
+			return &File{SectionReader: io.NewSectionReader(strings.NewReader(""), 0, 0)}, nil
+		}
+
+		return nil, &os.PathError{Op: "open", Path: name, Err: ErrNoFileData}
+	}
+
+	afile, err := os.Open(fs.archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{
+		SectionReader: io.NewSectionReader(afile, ref.offset, ref.size),
+		archive:       afile,
+	}, nil
+}
+
+// resolve returns the clean path and the object for the path following the symlinks in the path
+// (the returned object is nil for the directories without their own objects)
+func (fs *FileSystem) resolve(name string, followLast bool) (string, *ObjectMetadata, error) {
+	if !path.IsAbs(name) {
+		name = "/" + name
+	}
+
+	resolved := "/"
+	var object *ObjectMetadata
+	parts := strings.Split(strings.TrimPrefix(path.Clean(name), "/"), "/")
+	for links := 0; len(parts) > 0; {
+		part := parts[0]
+		parts = parts[1:]
+
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			object = fs.objects[resolved]
+			continue
+		}
+
+		next := path.Join(resolved, part)
+		var found bool
+		object, found = fs.objects[next]
+		if !found {
+			if _, isDir := fs.children[next]; !isDir {
+				return "", nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+			}
+		}
+
+		if object != nil && object.TypeFlag == tar.TypeLink {
+			//hard links point to the other objects using their archive paths
+			target := path.Join("/", object.LinkTarget)
+			if targetObject, found := fs.objects[target]; found {
+				object = targetObject
+			}
+		}
+
+		if object == nil || object.TypeFlag != tar.TypeSymlink || (len(parts) == 0 && !followLast) {
+			resolved = next
+			continue
+		}
+
+		links++
+		if links > maxFSSymlinks {
+			return "", nil, &os.PathError{Op: "stat", Path: name, Err: ErrTooManySymlinks}
+		}
+
+		if path.IsAbs(object.LinkTarget) {
+			resolved = "/"
+		}
+
+		parts = append(strings.Split(object.LinkTarget, "/"), parts...)
+	}
+
+	if resolved == "/" {
+		object = fs.objects[resolved]
+	}
+
+	return resolved, object, nil
+}
+
+// File is a regular file opened in the image filesystem
+type File struct {
+	*io.SectionReader
+	archive *os.File
+}
+
+// Close closes the file
+func (f *File) Close() error {
+	if f.archive == nil {
+		return nil
+	}
+
+	return f.archive.Close()
+}
+
+type objectFileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+	object  *ObjectMetadata
+}
+
+func newObjectFileInfo(object *ObjectMetadata) *objectFileInfo {
+	return &objectFileInfo{
+		name:    path.Base(object.Name),
+		size:    object.Size,
+		mode:    object.Mode,
+		modTime: object.ModTime,
+		object:  object,
+	}
+}
+
+func (fi *objectFileInfo) Name() string       { return fi.name }
+func (fi *objectFileInfo) Size() int64        { return fi.size }
+func (fi *objectFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi *objectFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *objectFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *objectFileInfo) Sys() interface{}   { return fi.object }
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+func uniqueSortedNames(names []string) []string {
+	sort.Strings(names)
+	var result []string
+	for idx, name := range names {
+		if idx > 0 && names[idx-1] == name {
+			continue
+		}
+
+		result = append(result, name)
+	}
+
+	return result
+}
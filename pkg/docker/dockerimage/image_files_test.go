@@ -0,0 +1,332 @@
+package dockerimage
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testImageID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// testArchive creates a saved image archive ('docker save' format) with the layer tar data
+// (the nil layers reuse the data of the previous layer using a layer symlink)
+func testArchive(t *testing.T, dir string, layers ...[]byte) string {
+	archivePath := filepath.Join(dir, "image.tar")
+	afile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer afile.Close()
+
+	tw := tar.NewWriter(afile)
+	writeFile := func(name string, data []byte) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifest := ManifestObject{Config: testImageID + ".json"}
+	for idx, data := range layers {
+		layerPath := fmt.Sprintf("layer%d%s", idx, layerSuffix)
+		manifest.Layers = append(manifest.Layers, layerPath)
+		if data == nil {
+			err := tw.WriteHeader(&tar.Header{
+				Name:     layerPath,
+				Typeflag: tar.TypeSymlink,
+				Linkname: fmt.Sprintf("../layer%d%s", idx-1, layerSuffix),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			continue
+		}
+
+		writeFile(layerPath, data)
+	}
+
+	config, err := json.Marshal(&ConfigObject{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(testImageID+".json", config)
+
+	manifestData, err := json.Marshal([]ManifestObject{manifest})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(manifestFileName, manifestData)
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return archivePath
+}
+
+// testFileSystem creates the image filesystem for the layer tar data
+func testFileSystem(t *testing.T, dir string, layers ...[]byte) *FileSystem {
+	archivePath := testArchive(t, dir, layers...)
+	pkg, err := LoadPackage(archivePath, testImageID, false, -1, false, false, nil, nil, nil, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := NewFileSystem(archivePath, pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fs
+}
+
+func newTestFileSystem(t *testing.T, dir string) *FileSystem {
+	return testFileSystem(t, dir,
+		testLayerTar(t,
+			testDir("bin/"),
+			testFile("bin/busybox", "BUSYBOX"),
+			testSymlink("bin/sh", "busybox"),
+			testDir("lib/"),
+			testFile("lib/libc.so", "LIBC"),
+			testSymlink("lib64", "lib"),
+			testDir("etc/"),
+			testFile("etc/app.conf", "conf v1"),
+			testFile("etc/empty", ""),
+			testSymlink("loop/a", "b"),
+			testSymlink("loop/b", "/loop/a"),
+			testSymlink("app/config", "../etc/app.conf"),
+			testSymlink("app/missing", "/etc/missing")),
+		testLayerTar(t,
+			testFile("etc/app.conf", "conf v2 (updated)"),
+			testHardlink("usr/bin/env", "bin/busybox"),
+			testFile("usr/local/bin/tool", "TOOL")))
+}
+
+func TestFileSystemResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockerimage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	fs := newTestFileSystem(t, dir)
+
+	tt := []struct {
+		name     string
+		realPath string
+		err      error
+	}{
+		{name: "/bin/sh", realPath: "/bin/busybox"},
+		{name: "bin/sh", realPath: "/bin/busybox"},
+		{name: "/lib64/libc.so", realPath: "/lib/libc.so"},
+		{name: "/app/config", realPath: "/etc/app.conf"},
+		//'..' is applied to the resolved symlink target
+		{name: "/lib64/../etc/app.conf", realPath: "/etc/app.conf"},
+		{name: "/bin/../../../etc/./app.conf", realPath: "/etc/app.conf"},
+		//the parent directories without their own objects
+		{name: "/usr/local/bin", realPath: "/usr/local/bin"},
+		{name: "/", realPath: "/"},
+		{name: "/loop/a", err: ErrTooManySymlinks},
+		{name: "/loop/a/file", err: ErrTooManySymlinks},
+		{name: "/app/missing", err: os.ErrNotExist},
+		{name: "/etc/missing", err: os.ErrNotExist},
+		{name: "/lib64/missing/libc.so", err: os.ErrNotExist},
+	}
+
+	for _, test := range tt {
+		realPath, err := fs.RealPath(test.name)
+		if test.err != nil {
+			if pathErr, ok := err.(*os.PathError); !ok || pathErr.Err != test.err {
+				t.Errorf("%s: got error %v expected %v", test.name, err, test.err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error - %v", test.name, err)
+			continue
+		}
+
+		if realPath != test.realPath {
+			t.Errorf("%s: got %q expected %q", test.name, realPath, test.realPath)
+		}
+	}
+}
+
+func TestFileSystemStat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockerimage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	fs := newTestFileSystem(t, dir)
+
+	tt := []struct {
+		name  string
+		lstat os.FileMode
+		stat  os.FileMode
+		size  int64
+	}{
+		{name: "/bin/sh", lstat: os.ModeSymlink, stat: 0, size: 7},
+		{name: "/lib64", lstat: os.ModeSymlink, stat: os.ModeDir},
+		{name: "/etc/app.conf", lstat: 0, stat: 0, size: 17},
+		//the hardlinks use the link target objects
+		{name: "/usr/bin/env", lstat: 0, stat: 0, size: 7},
+		{name: "/usr/local", lstat: os.ModeDir, stat: os.ModeDir},
+	}
+
+	for _, test := range tt {
+		linfo, err := fs.Lstat(test.name)
+		if err != nil {
+			t.Errorf("%s: unexpected lstat error - %v", test.name, err)
+			continue
+		}
+
+		info, err := fs.Stat(test.name)
+		if err != nil {
+			t.Errorf("%s: unexpected stat error - %v", test.name, err)
+			continue
+		}
+
+		if linfo.Mode()&os.ModeType != test.lstat || info.Mode()&os.ModeType != test.stat {
+			t.Errorf("%s: got lstat/stat types %v/%v expected %v/%v", test.name, linfo.Mode()&os.ModeType, info.Mode()&os.ModeType, test.lstat, test.stat)
+		}
+
+		if !info.IsDir() && info.Size() != test.size {
+			t.Errorf("%s: got size %d expected %d", test.name, info.Size(), test.size)
+		}
+	}
+
+	if target, err := fs.Readlink("/app/config"); err != nil || target != "../etc/app.conf" {
+		t.Errorf("unexpected symlink target: %q (%v)", target, err)
+	}
+
+	if _, err := fs.Readlink("/etc/app.conf"); err == nil {
+		t.Errorf("expected an error reading a regular file as a symlink")
+	}
+
+	entries, err := fs.ReadDir("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	expected := []string{"app", "bin", "etc", "lib", "lib64", "loop", "usr"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("root entries: got %q expected %q", names, expected)
+	}
+
+	//the directory symlinks are followed
+	entries, err = fs.ReadDir("/lib64")
+	if err != nil || len(entries) != 1 || entries[0].Name() != "libc.so" {
+		t.Errorf("unexpected /lib64 entries: %v (%v)", entries, err)
+	}
+
+	if _, err := fs.ReadDir("/etc/app.conf"); err == nil {
+		t.Errorf("expected an error reading a file as a directory")
+	}
+}
+
+func TestFileSystemOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockerimage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	fs := newTestFileSystem(t, dir)
+
+	tt := []struct {
+		name     string
+		expected string
+	}{
+		{name: "/bin/busybox", expected: "BUSYBOX"},
+		{name: "/bin/sh", expected: "BUSYBOX"},
+		{name: "/usr/bin/env", expected: "BUSYBOX"},
+		{name: "/lib64/libc.so", expected: "LIBC"},
+		//the data from the upper layer
+		{name: "/app/config", expected: "conf v2 (updated)"},
+		{name: "/usr/local/bin/tool", expected: "TOOL"},
+		{name: "/etc/empty", expected: ""},
+	}
+
+	for _, test := range tt {
+		f, err := fs.Open(test.name)
+		if err != nil {
+			t.Errorf("%s: unexpected error - %v", test.name, err)
+			continue
+		}
+
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil || string(data) != test.expected {
+			t.Errorf("%s: got %q (%v) expected %q", test.name, data, err, test.expected)
+		}
+	}
+
+	f, err := fs.Open("/etc/app.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+
+	buf := make([]byte, 7)
+	if n, err := f.ReadAt(buf, 9); n != 7 || err != nil || string(buf) != "updated" {
+		t.Errorf("unexpected data at offset: %q (%d, %v)", buf[:n], n, err)
+	}
+
+	if n, err := f.ReadAt(buf, 14); n != 3 || err != io.EOF {
+		t.Errorf("unexpected read past the file data: %d (%v)", n, err)
+	}
+
+	for _, name := range []string{"/etc", "/lib64", "/usr/local", "/loop/a", "/missing"} {
+		if f, err := fs.Open(name); err == nil {
+			f.Close()
+			t.Errorf("%s: expected an open error", name)
+		}
+	}
+}
+
+func TestFileSystemLayerDataSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockerimage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	//the duplicate layer uses the data in the source layer
+	fs := testFileSystem(t, dir,
+		testLayerTar(t, testFile("app/data", "v1")),
+		testLayerTar(t, testFile("app/data", "v2")),
+		nil)
+
+	f, err := fs.Open("/app/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+	if data, err := ioutil.ReadAll(f); err != nil || string(data) != "v2" {
+		t.Errorf("got %q (%v) expected %q", data, err, "v2")
+	}
+}
@@ -0,0 +1,211 @@
+package dockerimage
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/system"
+)
+
+// FinalObjects returns the objects in the final (merged) image filesystem
+// (applying the layer changes in the layer order)
+func (ref *Package) FinalObjects() map[string]*ObjectMetadata {
+	objects := map[string]*ObjectMetadata{}
+	for _, layer := range ref.Layers {
+		//the whiteouts in a layer apply only to the objects in the lower layers
+		for _, object := range layer.Objects {
+			if object.Change != ChangeDelete {
+				continue
+			}
+
+			if object.DirContentDelete {
+				dirPrefix := strings.TrimSuffix(object.Name, "*")
+				for name := range objects {
+					if strings.HasPrefix(name, dirPrefix) {
+						delete(objects, name)
+					}
+				}
+
+				continue
+			}
+
+			name := objectPath(object.Name)
+			delete(objects, name)
+			dirPrefix := name + "/"
+			for oname := range objects {
+				if strings.HasPrefix(oname, dirPrefix) {
+					delete(objects, oname)
+				}
+			}
+		}
+
+		for _, object := range layer.Objects {
+			if object.Change == ChangeDelete {
+				continue
+			}
+
+			objects[objectPath(object.Name)] = object
+		}
+	}
+
+	return objects
+}
+
+// InstalledOSPackages returns the OS packages installed in the final image filesystem
+func (ref *Package) InstalledOSPackages() []*system.OSPackage {
+	return installedOSPackages(ref, ref.FinalObjects())
+}
+
+// OSPackagesInfo is the OS package inventory for the final image filesystem
+type OSPackagesInfo struct {
+	Managers      []string         `json:"managers"`
+	Count         int              `json:"count"`
+	InstalledSize uint64           `json:"installed_size"`
+	Packages      []*OSPackageInfo `json:"packages"`
+}
+
+// OSPackageInfo is an installed OS package with the index of the layer that installed it
+// (the layer where the installed package version first appears in the package database)
+type OSPackageInfo struct {
+	*system.OSPackage
+	Layer int `json:"layer"`
+}
+
+// OSPackageInventory returns the OS packages installed in the final image filesystem
+// (returns nil if the image has no known package databases)
+func (ref *Package) OSPackageInventory() *OSPackagesInfo {
+	packages := ref.InstalledOSPackages()
+	if len(packages) == 0 {
+		return nil
+	}
+
+	installedBy := map[string]int{}
+	versions := map[string]string{}
+	dbs := map[string][]*system.OSPackage{}
+	for _, layer := range ref.Layers {
+		changed := len(layer.OSPackages) > 0
+		for dbPath := range dbs {
+			if layerDeletes(layer, dbPath) {
+				delete(dbs, dbPath)
+				changed = true
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		for dbPath, dbPackages := range layer.OSPackages {
+			dbs[dbPath] = dbPackages
+		}
+
+		current := map[string]string{}
+		for _, dbPackages := range dbs {
+			for _, p := range dbPackages {
+				current[osPackageKey(p)] = p.Version
+			}
+		}
+
+		for key, version := range current {
+			if prev, found := versions[key]; !found || prev != version {
+				versions[key] = version
+				installedBy[key] = layer.Index
+			}
+		}
+
+		for key := range versions {
+			if _, found := current[key]; !found {
+				delete(versions, key)
+				delete(installedBy, key)
+			}
+		}
+	}
+
+	info := &OSPackagesInfo{
+		Count: len(packages),
+	}
+
+	managers := map[string]struct{}{}
+	for _, p := range packages {
+		managers[p.Manager] = struct{}{}
+		info.InstalledSize += uint64(p.InstalledSize)
+		info.Packages = append(info.Packages,
+			&OSPackageInfo{
+				OSPackage: p,
+				Layer:     installedBy[osPackageKey(p)],
+			})
+	}
+
+	for m := range managers {
+		info.Managers = append(info.Managers, m)
+	}
+
+	sort.Strings(info.Managers)
+	sort.Slice(info.Packages, func(i, j int) bool {
+		if info.Packages[i].Name != info.Packages[j].Name {
+			return info.Packages[i].Name < info.Packages[j].Name
+		}
+
+		return info.Packages[i].Arch < info.Packages[j].Arch
+	})
+
+	return info
+}
+
+// layerDeletes returns true if the layer whiteouts remove the file from the lower layers
+// (the file itself, one of its parent directories or the content of an opaque parent directory)
+func layerDeletes(layer *Layer, filePath string) bool {
+	for name := filePath; ; name = path.Dir(name) {
+		if object, found := layer.References[name]; found && object.Change == ChangeDelete {
+			return true
+		}
+
+		if name == "/" || name == "." {
+			return false
+		}
+
+		opaqueDir := path.Join(path.Dir(name), "*")
+		if object, found := layer.References[opaqueDir]; found && object.DirContentDelete {
+			return true
+		}
+	}
+}
+
+func osPackageKey(p *system.OSPackage) string {
+	return fmt.Sprintf("%s/%s/%s", p.Manager, p.Name, p.Arch)
+}
+
+func installedOSPackages(pkg *Package, objects map[string]*ObjectMetadata) []*system.OSPackage {
+	dbs := map[string][]*system.OSPackage{}
+	for _, layer := range pkg.Layers {
+		for dbPath, packages := range layer.OSPackages {
+			dbs[dbPath] = packages
+		}
+	}
+
+	var dbPaths []string
+	for dbPath := range dbs {
+		if _, found := objects[dbPath]; found {
+			dbPaths = append(dbPaths, dbPath)
+		}
+	}
+
+	sort.Strings(dbPaths)
+
+	var result []*system.OSPackage
+	for _, dbPath := range dbPaths {
+		result = append(result, dbs[dbPath]...)
+	}
+
+	return result
+}
+
+func objectPath(name string) string {
+	if len(name) > 1 {
+		return strings.TrimSuffix(name, "/")
+	}
+
+	return name
+}
@@ -0,0 +1,96 @@
+package dockerimage
+
+import (
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/system"
+)
+
+const testDpkgStatus = "/var/lib/dpkg/status"
+
+func newTestLayer(index int, objects ...*ObjectMetadata) *Layer {
+	layer := &Layer{
+		Index:      index,
+		References: map[string]*ObjectMetadata{},
+		OSPackages: map[string][]*system.OSPackage{},
+	}
+
+	for _, object := range objects {
+		layer.Objects = append(layer.Objects, object)
+		layer.References[object.Name] = object
+	}
+
+	return layer
+}
+
+func testPackageDB(layer *Layer, packages ...*system.OSPackage) *Layer {
+	object := &ObjectMetadata{Name: testDpkgStatus, Change: ChangeAdd}
+	layer.Objects = append(layer.Objects, object)
+	layer.References[object.Name] = object
+	layer.OSPackages[testDpkgStatus] = packages
+	return layer
+}
+
+func TestOSPackageInventoryWhiteouts(t *testing.T) {
+	curl := &system.OSPackage{Name: "curl", Version: "7.74.0", Manager: system.OSPackageManagerDpkg}
+
+	tt := []struct {
+		name     string
+		whiteout *ObjectMetadata
+		layer    int
+	}{
+		{
+			name:     "no whiteout",
+			whiteout: &ObjectMetadata{Name: "/tmp/file", Change: ChangeAdd},
+			layer:    0,
+		},
+		{
+			name:     "file whiteout",
+			whiteout: &ObjectMetadata{Name: testDpkgStatus, Change: ChangeDelete},
+			layer:    2,
+		},
+		{
+			name:     "parent directory whiteout",
+			whiteout: &ObjectMetadata{Name: "/var/lib", Change: ChangeDelete},
+			layer:    2,
+		},
+		{
+			name:     "opaque directory whiteout",
+			whiteout: &ObjectMetadata{Name: "/var/lib/dpkg/*", Change: ChangeDelete, DirContentDelete: true},
+			layer:    2,
+		},
+		{
+			name:     "opaque parent directory whiteout",
+			whiteout: &ObjectMetadata{Name: "/var/*", Change: ChangeDelete, DirContentDelete: true},
+			layer:    2,
+		},
+		{
+			name:     "opaque sibling directory whiteout",
+			whiteout: &ObjectMetadata{Name: "/var/lib/apt/*", Change: ChangeDelete, DirContentDelete: true},
+			layer:    0,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			//the package DB is removed and then recreated with the same package,
+			//so the package is installed by the layer that recreated the DB
+			pkg := &Package{
+				Layers: []*Layer{
+					testPackageDB(newTestLayer(0), curl),
+					newTestLayer(1, test.whiteout),
+					testPackageDB(newTestLayer(2), curl),
+				},
+			}
+
+			info := pkg.OSPackageInventory()
+			if info == nil || len(info.Packages) != 1 {
+				t.Fatalf("expected one package: %+v", info)
+			}
+
+			if info.Packages[0].Layer != test.layer {
+				t.Errorf("package layer: got %d expected %d", info.Packages[0].Layer, test.layer)
+			}
+		})
+	}
+}
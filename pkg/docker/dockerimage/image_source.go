@@ -0,0 +1,151 @@
+package dockerimage
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// LoadPackageFromImage loads the image package data streaming the image layers
+// directly from the image source (registry, OCI image layout or image archive)
+// without saving the image with Docker
+func LoadPackageFromImage(
+	img gocrv1.Image,
+	sourceName string,
+	topChangesMax int,
+	doHashData bool,
+	doDetectDuplicates bool,
+	changeDataHashMatchers map[string]*ChangeDataHashMatcher,
+	changePathMatchers []*ChangePathMatcher,
+	changeDataMatchers map[string]*ChangeDataMatcher,
+	utf8Detector *UTF8Detector,
+	doDetectAllCertFiles bool,
+	doDetectAllCertPKFiles bool,
+) (*Package, error) {
+	cn, err := img.ConfigName()
+	if err != nil {
+		return nil, err
+	}
+
+	imageID := cn.Hex
+	rawConfig, err := img.RawConfigFile()
+	if err != nil {
+		log.Errorf("dockerimage.LoadPackageFromImage: error reading image config (%v) - %v", sourceName, err)
+		return nil, err
+	}
+
+	pkg := newPackage()
+	var imageConfig ConfigObject
+	if err := json.Unmarshal(rawConfig, &imageConfig); err != nil {
+		log.Errorf("dockerimage.LoadPackageFromImage: error decoding image config (%v) - %v", sourceName, err)
+		return nil, err
+	}
+
+	pkg.Config = &imageConfig
+	pkg.Manifest = &ManifestObject{
+		Config: fmt.Sprintf("%s.json", imageID),
+	}
+
+	imageLayers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	cpmDumps := hasChangePathMatcherDumps(changePathMatchers)
+	layers := map[string]*Layer{}
+	for idx, imageLayer := range imageLayers {
+		diffID, err := imageLayer.DiffID()
+		if err != nil {
+			return nil, err
+		}
+
+		layerID := diffID.Hex
+		if _, found := layers[layerID]; found {
+			//the same layer data can be used more than once
+			layerID = fmt.Sprintf("%s.%d", layerID, idx)
+		}
+
+		layerPath := fmt.Sprintf("%s%s", layerID, layerSuffix)
+		pkg.Manifest.Layers = append(pkg.Manifest.Layers, layerPath)
+
+		layer, err := layerFromImageLayer(
+			pkg,
+			imageLayer,
+			layerPath,
+			layerID,
+			topChangesMax,
+			doHashData,
+			doDetectDuplicates,
+			changeDataHashMatchers,
+			changePathMatchers,
+			cpmDumps,
+			changeDataMatchers,
+			utf8Detector,
+			doDetectAllCertFiles,
+			doDetectAllCertPKFiles)
+		if err != nil {
+			log.Errorf("dockerimage.LoadPackageFromImage: error reading layer (%v/%v) - %v", sourceName, layerPath, err)
+			return nil, err
+		}
+
+		layers[layerID] = layer
+	}
+
+	if err := processLayers(pkg, layers, sourceName, imageID, utf8Detector, doDetectDuplicates); err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+func layerFromImageLayer(
+	pkg *Package,
+	imageLayer gocrv1.Layer,
+	layerPath string,
+	layerID string,
+	topChangesMax int,
+	doHashData bool,
+	doDetectDuplicates bool,
+	changeDataHashMatchers map[string]*ChangeDataHashMatcher,
+	changePathMatchers []*ChangePathMatcher,
+	cpmDumps bool,
+	changeDataMatchers map[string]*ChangeDataMatcher,
+	utf8Detector *UTF8Detector,
+	doDetectAllCertFiles bool,
+	doDetectAllCertPKFiles bool,
+) (*Layer, error) {
+	rc, err := imageLayer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	layer, err := layerFromStream(
+		pkg,
+		layerPath,
+		tar.NewReader(rc),
+		layerID,
+		topChangesMax,
+		doHashData,
+		doDetectDuplicates,
+		changeDataHashMatchers,
+		changePathMatchers,
+		cpmDumps,
+		changeDataMatchers,
+		utf8Detector,
+		doDetectAllCertFiles,
+		doDetectAllCertPKFiles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	//draining the stream (the tar padding) to let the layer source verify the data
+	_, err = io.Copy(ioutil.Discard, rc)
+	return layer, err
+}
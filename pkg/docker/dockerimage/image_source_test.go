@@ -0,0 +1,186 @@
+package dockerimage
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"testing"
+
+	gocrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// testEntry is a layer tar entry
+// (the directories end with '/' and the symlinks/hardlinks have a link target)
+type testEntry struct {
+	name     string
+	data     string
+	link     string
+	typeflag byte
+	mode     int64
+}
+
+func testFile(name, data string) testEntry {
+	return testEntry{name: name, data: data}
+}
+
+func testDir(name string) testEntry {
+	return testEntry{name: name, typeflag: tar.TypeDir}
+}
+
+func testSymlink(name, target string) testEntry {
+	return testEntry{name: name, link: target, typeflag: tar.TypeSymlink}
+}
+
+func testHardlink(name, target string) testEntry {
+	return testEntry{name: name, link: target, typeflag: tar.TypeLink}
+}
+
+// testLayerTar creates the layer tar data
+func testLayerTar(t *testing.T, entries ...testEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.link,
+			Mode:     entry.mode,
+		}
+
+		switch hdr.Typeflag {
+		case 0, tar.TypeReg:
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(entry.data))
+			if hdr.Mode == 0 {
+				hdr.Mode = 0644
+			}
+		case tar.TypeDir:
+			hdr.Mode = 0755
+		case tar.TypeSymlink:
+			hdr.Mode = 0777
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(entry.data)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// testImage creates an image with the layer tar data
+func testImage(t *testing.T, config gocrv1.Config, layers ...[]byte) gocrv1.Image {
+	img, err := mutate.ConfigFile(empty.Image, &gocrv1.ConfigFile{
+		OS:           "linux",
+		Architecture: "amd64",
+		Config:       config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range layers {
+		data := data
+		layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		img, err = mutate.AppendLayers(img, layer)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return img
+}
+
+// testPackage loads the image package for the image (with the file data hashes)
+func testPackage(t *testing.T, img gocrv1.Image) *Package {
+	pkg, err := LoadPackageFromImage(img, "test", -1, true, false, nil, nil, nil, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pkg
+}
+
+func finalNames(pkg *Package) []string {
+	var names []string
+	for name := range pkg.FinalObjects() {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func TestLoadPackageFromImage(t *testing.T) {
+	base := testLayerTar(t,
+		testDir("etc/"),
+		testFile("etc/hosts", "127.0.0.1 localhost\n"),
+		testFile("etc/motd", "hello\n"),
+		testDir("app/"),
+		testFile("app/main.sh", "#!/bin/sh\necho app\n"))
+	update := testLayerTar(t,
+		testFile("etc/motd", "welcome\n"),
+		testFile("etc/.wh.hosts", ""))
+
+	config := gocrv1.Config{
+		Entrypoint: []string{"/app/main.sh"},
+		Env:        []string{"PATH=/usr/bin:/bin"},
+	}
+
+	//the same layer data is used twice
+	img := testImage(t, config, base, update, update)
+	pkg := testPackage(t, img)
+
+	if pkg.Config == nil || len(pkg.Config.Config.Entrypoint) != 1 || pkg.Config.Config.Entrypoint[0] != "/app/main.sh" {
+		t.Fatalf("unexpected image config: %+v", pkg.Config)
+	}
+
+	if len(pkg.Layers) != 3 || len(pkg.Manifest.Layers) != 3 {
+		t.Fatalf("expected 3 layers (got %d)", len(pkg.Layers))
+	}
+
+	seen := map[string]bool{}
+	for idx, layer := range pkg.Layers {
+		if layer.Index != idx || layer.Path != pkg.Manifest.Layers[idx] {
+			t.Errorf("layer %d: unexpected layer index/path - %d/%s", idx, layer.Index, layer.Path)
+		}
+
+		if seen[layer.ID] {
+			t.Errorf("layer %d: duplicate layer ID - %s", idx, layer.ID)
+		}
+
+		seen[layer.ID] = true
+	}
+
+	expected := []string{"/app", "/app/main.sh", "/etc", "/etc/motd"}
+	if got := finalNames(pkg); !reflect.DeepEqual(got, expected) {
+		t.Errorf("final objects:\ngot      %q\nexpected %q", got, expected)
+	}
+
+	motd := pkg.FinalObjects()["/etc/motd"]
+	if motd == nil || motd.Size != int64(len("welcome\n")) || motd.LayerIndex != 2 {
+		t.Errorf("unexpected final /etc/motd object: %+v", motd)
+	}
+}
@@ -14,7 +14,45 @@ type Context struct {
 	Dockerignore    *dockerignore.Matcher
 }
 
+// Options are the check options from the lint config
+// (the check specific options are ignored by the other checks)
 type Options struct {
+	//Level overrides the check level label (severity)
+	Level string `json:"level,omitempty"`
+	//AllowedRegistries are the registries (or registry repo prefixes)
+	//for the stage base images (ID.20006)
+	AllowedRegistries []string `json:"allowed_registries,omitempty"`
+	//MaxLayerCount is the maximum number of layers in a stage (ID.20020)
+	MaxLayerCount int `json:"max_layer_count,omitempty"`
+	//AllowedRootUserStages are the stage names (or indexes)
+	//where the last USER instruction can be root (ID.20017)
+	AllowedRootUserStages []string `json:"allowed_root_user_stages,omitempty"`
+}
+
+// IsLevel returns true if the check level label value is a known level
+func IsLevel(level string) bool {
+	switch level {
+	case LevelFatal, LevelError, LevelWarn, LevelInfo, LevelStyle:
+		return true
+	}
+
+	return false
+}
+
+// WithOptions returns the check info with the level override from the check options
+func (i *Info) WithOptions(opts *Options) *Info {
+	if opts == nil || opts.Level == "" || opts.Level == i.Labels[LabelLevel] {
+		return i
+	}
+
+	info := *i
+	info.Labels = map[string]string{}
+	for k, v := range i.Labels {
+		info.Labels[k] = v
+	}
+
+	info.Labels[LabelLevel] = opts.Level
+	return &info
 }
 
 type Info struct {
@@ -6,6 +6,17 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerfile/spec"
+)
+
+const (
+	defaultImageRegistry = "docker.io"
+	//used when the stage base image is not from one of the allowed registries
+	registryMainMessage  = "Stage from not allowed registry in Dockerfile"
+	registryMatchMessage = "Stage: index=%d name='%s' start=%d end=%d parent='%s' registry='%s' (registry not allowed)"
+	//used when there are the latest tag and the registry matches
+	latestRegistryMainMessage = "Stage from latest tag and from not allowed registry in Dockerfile"
 )
 
 func init() {
@@ -31,21 +42,29 @@ type StageFromLatest struct {
 	Info
 }
 
+// Run checks the stage parent images for the latest (or missing) tags
+// and for the registries that are not in the allowed registry list (if it's set).
+// The other stages and 'scratch' used as the stage parents have no tags,
+// so they are not reported as the latest tag matches.
 func (c *StageFromLatest) Run(opts *Options, ctx *Context) (*Result, error) {
 	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
 	result := &Result{
 		Source: &c.Info,
 	}
 
+	var allowedRegistries []string
+	if opts != nil {
+		allowedRegistries = opts.AllowedRegistries
+	}
+
+	var latestHit, registryHit bool
 	for _, stage := range ctx.Dockerfile.Stages {
 		if stage.Parent.Name != "" {
-			if (stage.Parent.Tag == "" || strings.ToLower(stage.Parent.Tag) == "latest") &&
+			if stage.Parent.ParentStage == nil &&
+				stage.Parent.Name != "scratch" &&
+				(stage.Parent.Tag == "" || strings.ToLower(stage.Parent.Tag) == "latest") &&
 				stage.Parent.Digest == "" {
-				if !result.Hit {
-					result.Hit = true
-					result.Message = c.MainMessage
-				}
-
+				latestHit = true
 				match := &Match{
 					Stage: stage,
 					Message: fmt.Sprintf(c.MatchMessage,
@@ -58,8 +77,79 @@ func (c *StageFromLatest) Run(opts *Options, ctx *Context) (*Result, error) {
 
 				result.Matches = append(result.Matches, match)
 			}
+
+			if len(allowedRegistries) > 0 && isExternalImage(stage) {
+				imageName := normalizedImageName(stage.Parent.Name)
+				if !isAllowedImage(imageName, allowedRegistries) {
+					registryHit = true
+					match := &Match{
+						Stage: stage,
+						Message: fmt.Sprintf(registryMatchMessage,
+							stage.Index,
+							stage.Name,
+							stage.StartLine,
+							stage.EndLine,
+							stage.Parent.Name,
+							imageRegistry(imageName)),
+					}
+
+					result.Matches = append(result.Matches, match)
+				}
+			}
 		}
 	}
 
+	switch {
+	case latestHit && registryHit:
+		result.Hit = true
+		result.Message = latestRegistryMainMessage
+	case latestHit:
+		result.Hit = true
+		result.Message = c.MainMessage
+	case registryHit:
+		result.Hit = true
+		result.Message = registryMainMessage
+	}
+
 	return result, nil
 }
+
+// isExternalImage returns true if the stage parent is an image from a registry
+// (not another stage, 'scratch' or an unresolved build arg)
+func isExternalImage(stage *spec.BuildStage) bool {
+	return stage.Parent.ParentStage == nil &&
+		stage.Parent.Name != "scratch" &&
+		!strings.Contains(stage.Parent.Name, "$")
+}
+
+// normalizedImageName returns the image name with its registry
+// (e.g., 'ubuntu' -> 'docker.io/library/ubuntu')
+func normalizedImageName(name string) string {
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 &&
+		(strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return name
+	}
+
+	if len(parts) == 1 {
+		return fmt.Sprintf("%s/library/%s", defaultImageRegistry, name)
+	}
+
+	return fmt.Sprintf("%s/%s", defaultImageRegistry, name)
+}
+
+func imageRegistry(normalizedName string) string {
+	return strings.SplitN(normalizedName, "/", 2)[0]
+}
+
+func isAllowedImage(normalizedName string, allowed []string) bool {
+	for _, prefix := range allowed {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if normalizedName == prefix ||
+			strings.HasPrefix(normalizedName, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
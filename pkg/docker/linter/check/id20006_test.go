@@ -0,0 +1,194 @@
+package check
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerfile/parser"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerfile/spec"
+)
+
+const testRegistryDockerfile = `ARG BASE=alpine:3.12
+FROM golang:1.15 AS build
+FROM build AS test
+FROM scratch
+FROM $BASE
+FROM gcr.io/distroless/static:nonroot
+FROM ubuntu:20.04
+FROM quay.io/org/app:1.0
+FROM localhost:5000/app:1.0
+FROM myorg/app:2.0
+FROM python
+FROM registry.example.com:443/base/image@sha256:0123456789abcdef
+`
+
+// testDockerfile parses the Dockerfile content
+func testDockerfile(t *testing.T, content string) *spec.Dockerfile {
+	dir, err := ioutil.TempDir("", "ds-lint-check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dockerfilePath := filepath.Join(dir, "Dockerfile")
+	if err := ioutil.WriteFile(dockerfilePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	df, err := parser.FromFile(dockerfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return df
+}
+
+func stageFromLatestCheck() *StageFromLatest {
+	for _, runner := range AllChecks {
+		if info := runner.Get(); info.ID == "ID.20006" {
+			return runner.(*StageFromLatest)
+		}
+	}
+
+	return &StageFromLatest{}
+}
+
+func TestStageFromLatestRegistries(t *testing.T) {
+	df := testDockerfile(t, testRegistryDockerfile)
+
+	tt := []struct {
+		name    string
+		allowed []string
+		//the stage parents in the matches
+		expected []string
+	}{
+		{
+			name:     "no allowed registries (latest tag only)",
+			expected: []string{"python"},
+		},
+		{
+			name:     "library images and distroless",
+			allowed:  []string{"docker.io/library", "gcr.io/distroless/"},
+			expected: []string{"quay.io/org/app", "localhost:5000/app", "myorg/app", "python", "registry.example.com:443/base/image"},
+		},
+		{
+			name:     "registry",
+			allowed:  []string{"docker.io"},
+			expected: []string{"gcr.io/distroless/static", "quay.io/org/app", "localhost:5000/app", "python", "registry.example.com:443/base/image"},
+		},
+		{
+			name:    "repo prefix is not a name prefix",
+			allowed: []string{"gcr.io/distro", "docker.io/myorg", "localhost:5000", "quay.io/org/app", "registry.example.com:443/"},
+			expected: []string{
+				"golang",
+				"alpine",
+				"gcr.io/distroless/static",
+				"ubuntu",
+				"python",
+				"python"},
+		},
+	}
+
+	c := stageFromLatestCheck()
+	for _, test := range tt {
+		result, err := c.Run(&Options{AllowedRegistries: test.allowed}, &Context{Dockerfile: df})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		var parents []string
+		for _, m := range result.Matches {
+			parents = append(parents, m.Stage.Parent.Name)
+		}
+
+		if !reflect.DeepEqual(parents, test.expected) {
+			t.Errorf("%s: unexpected matches:\ngot      %v\nexpected %v", test.name, parents, test.expected)
+		}
+
+		if result.Hit != (len(test.expected) > 0) {
+			t.Errorf("%s: unexpected hit status - %v", test.name, result.Hit)
+		}
+	}
+}
+
+func TestStageFromLatestMessages(t *testing.T) {
+	tt := []struct {
+		name       string
+		dockerfile string
+		allowed    []string
+		message    string
+		matches    []string
+	}{
+		{
+			name:       "stage and scratch parents",
+			dockerfile: "FROM ubuntu:20.04 AS base\nFROM base\nFROM scratch\n",
+			allowed:    []string{"docker.io"},
+		},
+		{
+			name:       "latest tag",
+			dockerfile: "FROM ubuntu AS base\nFROM base\n",
+			message:    "Stage from latest tag in Dockerfile",
+			matches:    []string{"Stage: index=0 name='base' start=1 end=1 parent='ubuntu'"},
+		},
+		{
+			name:       "registry",
+			dockerfile: "FROM quay.io/org/app:1.0\nFROM scratch\n",
+			allowed:    []string{"docker.io"},
+			message:    registryMainMessage,
+			matches:    []string{"Stage: index=0 name='' start=1 end=1 parent='quay.io/org/app' registry='quay.io' (registry not allowed)"},
+		},
+		{
+			name:       "latest tag and registry",
+			dockerfile: "FROM ubuntu\nFROM quay.io/org/app:latest\n",
+			allowed:    []string{"docker.io"},
+			message:    latestRegistryMainMessage,
+			matches: []string{
+				"Stage: index=0 name='' start=1 end=1 parent='ubuntu'",
+				"Stage: index=1 name='' start=2 end=2 parent='quay.io/org/app'",
+				"Stage: index=1 name='' start=2 end=2 parent='quay.io/org/app' registry='quay.io' (registry not allowed)",
+			},
+		},
+	}
+
+	c := stageFromLatestCheck()
+	for _, test := range tt {
+		result, err := c.Run(&Options{AllowedRegistries: test.allowed}, &Context{Dockerfile: testDockerfile(t, test.dockerfile)})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if result.Hit != (test.message != "") || result.Message != test.message {
+			t.Errorf("%s: got hit=%v message=%q expected %q", test.name, result.Hit, result.Message, test.message)
+		}
+
+		var matches []string
+		for _, m := range result.Matches {
+			matches = append(matches, m.Message)
+		}
+
+		if !reflect.DeepEqual(matches, test.matches) {
+			t.Errorf("%s: unexpected matches:\ngot      %q\nexpected %q", test.name, matches, test.matches)
+		}
+	}
+}
+
+func TestNormalizedImageName(t *testing.T) {
+	tt := map[string]string{
+		"ubuntu":                     "docker.io/library/ubuntu",
+		"ubuntu:20.04":               "docker.io/library/ubuntu:20.04",
+		"myorg/app":                  "docker.io/myorg/app",
+		"gcr.io/distroless/static":   "gcr.io/distroless/static",
+		"localhost/app":              "localhost/app",
+		"localhost:5000/app:1.0":     "localhost:5000/app:1.0",
+		"registry.example.com/a/b/c": "registry.example.com/a/b/c",
+	}
+
+	for name, expected := range tt {
+		if got := normalizedImageName(name); got != expected {
+			t.Errorf("normalizedImageName(%s) = %s, expected %s", name, got, expected)
+		}
+	}
+}
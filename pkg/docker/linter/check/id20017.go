@@ -3,10 +3,12 @@ package check
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerfile/spec"
 	"github.com/docker-slim/docker-slim/pkg/docker/instruction"
 )
 
@@ -42,6 +44,9 @@ func (c *LastUserRoot) Run(opts *Options, ctx *Context) (*Result, error) {
 	lastStageIdx := len(ctx.Dockerfile.Stages) - 1
 	if lastStageIdx > -1 {
 		stage := ctx.Dockerfile.Stages[lastStageIdx]
+		if opts != nil && isAllowedRootUserStage(stage, opts.AllowedRootUserStages) {
+			return result, nil
+		}
 
 		if instructions, ok := stage.CurrentInstructionsByType[instruction.User]; ok {
 			lastUserIdx := len(instructions) - 1
@@ -76,3 +81,14 @@ func (c *LastUserRoot) Run(opts *Options, ctx *Context) (*Result, error) {
 
 	return result, nil
 }
+
+func isAllowedRootUserStage(stage *spec.BuildStage, allowed []string) bool {
+	for _, name := range allowed {
+		if (stage.Name != "" && name == stage.Name) ||
+			name == strconv.Itoa(stage.Index) {
+			return true
+		}
+	}
+
+	return false
+}
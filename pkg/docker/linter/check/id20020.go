@@ -47,6 +47,11 @@ func (c *TooManyLayers) Run(opts *Options, ctx *Context) (*Result, error) {
 	//* it doesn't know the storage driver and its exact limit
 	//* not all RUN instructions generate new layers (e.g., "RUN python -V")
 	//* not all WORKDIR instructions generate new layers (new layer only when dir doesn't exist)
+	maxCount := maxLayerCount
+	if opts != nil && opts.MaxLayerCount > 0 {
+		maxCount = opts.MaxLayerCount
+	}
+
 	for _, stage := range ctx.Dockerfile.Stages {
 		layerCount := 0
 		for _, inst := range stage.CurrentInstructions {
@@ -59,7 +64,7 @@ func (c *TooManyLayers) Run(opts *Options, ctx *Context) (*Result, error) {
 			}
 		}
 
-		if layerCount > maxLayerCount {
+		if layerCount > maxCount {
 			result.Hit = true
 			result.Message = fmt.Sprintf(c.MainMessage, layerCount)
 		}
@@ -0,0 +1,64 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/docker-slim/docker-slim/pkg/docker/linter/check"
+)
+
+// Config is the lint config (YAML or JSON) with the check options by check ID
+type Config struct {
+	Checks map[string]*check.Options `json:"checks"`
+}
+
+// LoadConfig loads the check options (by check ID) from the lint config file
+func LoadConfig(filePath string) (map[string]*check.Options, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	//JSON is valid YAML
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	known := map[string]struct{}{}
+	for _, c := range check.AllChecks {
+		known[c.Get().ID] = struct{}{}
+	}
+
+	for id, opts := range config.Checks {
+		if _, found := known[id]; !found {
+			return nil, fmt.Errorf("unknown check ID - %s", id)
+		}
+
+		if opts == nil {
+			delete(config.Checks, id)
+			continue
+		}
+
+		if opts.Level != "" && !check.IsLevel(opts.Level) {
+			return nil, fmt.Errorf("unknown check level - %s (check ID: %s)", opts.Level, id)
+		}
+
+		if opts.MaxLayerCount < 0 {
+			return nil, fmt.Errorf("invalid max layer count - %d (check ID: %s)", opts.MaxLayerCount, id)
+		}
+	}
+
+	return config.Checks, nil
+}
@@ -0,0 +1,183 @@
+package linter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/docker/linter/check"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ds-lint-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	expected := map[string]*check.Options{
+		"ID.20006": {
+			Level:             check.LevelWarn,
+			AllowedRegistries: []string{"docker.io/library", "gcr.io/distroless"},
+		},
+		"ID.20017": {AllowedRootUserStages: []string{"build", "0"}},
+		"ID.20020": {MaxLayerCount: 10},
+	}
+
+	tt := []struct {
+		name     string
+		data     string
+		expected map[string]*check.Options
+		err      string
+	}{
+		{
+			name: "config.yaml",
+			data: `
+checks:
+  ID.20006:
+    level: warn
+    allowed_registries:
+      - docker.io/library
+      - gcr.io/distroless
+  ID.20017:
+    allowed_root_user_stages: [build, "0"]
+  ID.20020:
+    max_layer_count: 10
+  #the checks without options are ignored
+  ID.20001:
+`,
+			expected: expected,
+		},
+		{
+			name: "config.json",
+			data: `{"checks": {
+	"ID.20006": {"level": "warn", "allowed_registries": ["docker.io/library", "gcr.io/distroless"]},
+	"ID.20017": {"allowed_root_user_stages": ["build", "0"]},
+	"ID.20020": {"max_layer_count": 10}}}`,
+			expected: expected,
+		},
+		{
+			name:     "empty.yaml",
+			data:     "checks: {}\n",
+			expected: map[string]*check.Options{},
+		},
+		{
+			name: "unknown-option.yaml",
+			data: "checks:\n  ID.20006:\n    allowed_registry: [docker.io]\n",
+			err:  "unknown field",
+		},
+		{
+			name: "unknown-section.yaml",
+			data: "rules:\n  ID.20006:\n    level: warn\n",
+			err:  "unknown field",
+		},
+		{
+			name: "unknown-check.yaml",
+			data: "checks:\n  ID.99999:\n    level: warn\n",
+			err:  "unknown check ID - ID.99999",
+		},
+		{
+			name: "unknown-level.yaml",
+			data: "checks:\n  ID.20006:\n    level: critical\n",
+			err:  "unknown check level - critical",
+		},
+		{
+			name: "max-layer-count.yaml",
+			data: "checks:\n  ID.20020:\n    max_layer_count: -1\n",
+			err:  "invalid max layer count - -1",
+		},
+		{
+			name: "max-layer-count-type.yaml",
+			data: "checks:\n  ID.20020:\n    max_layer_count: many\n",
+			err:  "cannot unmarshal",
+		},
+		{
+			name: "invalid.yaml",
+			data: "checks: [\n",
+			err:  "yaml",
+		},
+	}
+
+	for _, test := range tt {
+		filePath := filepath.Join(dir, test.name)
+		if err := ioutil.WriteFile(filePath, []byte(test.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		config, err := LoadConfig(filePath)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected error with '%s', got %v", test.name, test.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error - %v", test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(config, test.expected) {
+			t.Errorf("%s: unexpected config:\ngot      %#v\nexpected %#v", test.name, config, test.expected)
+		}
+	}
+
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Errorf("missing config file: expected an error")
+	}
+}
+
+func TestExecuteConfig(t *testing.T) {
+	options := Options{
+		DockerfilePath:   filepath.Join("testdata", "sarif", "Dockerfile"),
+		SkipBuildContext: true,
+		SkipDockerignore: true,
+		Selector: CheckSelector{
+			IncludeCheckIDs: map[string]struct{}{"ID.20012": {}, "ID.20017": {}},
+		},
+	}
+
+	report, err := Execute(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := report.Hits["ID.20017"]; !found {
+		t.Fatalf("ID.20017: expected a hit without the config")
+	}
+
+	options.Config = map[string]*check.Options{
+		"ID.20012": {Level: check.LevelError},
+		//the stage index is used for the stages without names
+		"ID.20017": {AllowedRootUserStages: []string{"1"}},
+	}
+
+	report, err = Execute(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := report.Hits["ID.20017"]; found {
+		t.Errorf("ID.20017: unexpected hit for the allowed root user stage")
+	}
+
+	result, found := report.Hits["ID.20012"]
+	if !found {
+		t.Fatalf("ID.20012: expected a hit")
+	}
+
+	if level := result.Source.Labels[check.LabelLevel]; level != check.LevelError {
+		t.Errorf("ID.20012: expected the level override (%s), got %s", check.LevelError, level)
+	}
+
+	//the level override doesn't change the shared check info
+	for _, c := range check.AllChecks {
+		if info := c.Get(); info.ID == "ID.20012" && info.Labels[check.LabelLevel] != check.LevelWarn {
+			t.Errorf("ID.20012: check info level changed to %s", info.Labels[check.LabelLevel])
+		}
+	}
+}
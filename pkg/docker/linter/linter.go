@@ -130,6 +130,8 @@ func Execute(options Options) (*Report, error) {
 	var selectedChecks []check.Runner
 	for _, check := range check.AllChecks {
 		info := check.Get()
+		//the check labels are selected with the level overrides from the config
+		info = info.WithOptions(options.Config[info.ID])
 
 		if len(options.Selector.IncludeCheckIDs) > 0 {
 			if _, ok := options.Selector.IncludeCheckIDs[info.ID]; ok {
@@ -235,6 +237,8 @@ done:
 				if checkState.Error != nil {
 					report.Errors[info.ID] = checkState.Error
 				} else {
+					checkState.Result.Source = checkState.Result.Source.WithOptions(checkState.Options)
+
 					if checkState.Result.Hit {
 						report.Hits[info.ID] = checkState.Result
 					} else {
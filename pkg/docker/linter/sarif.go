@@ -0,0 +1,372 @@
+package linter
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker-slim/docker-slim/pkg/docker/linter/check"
+)
+
+// SARIF format info
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIF result levels
+const (
+	SARIFLevelError   = "error"
+	SARIFLevelWarning = "warning"
+	SARIFLevelNote    = "note"
+)
+
+// SARIFSourceRootID is the base ID for the relative Dockerfile paths
+const SARIFSourceRootID = "%SRCROOT%"
+
+// SARIFLog is a SARIF (Static Analysis Results Interchange Format) log
+// (only the properties used to describe the lint results)
+type SARIFLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool        SARIFTool          `json:"tool"`
+	Invocations []*SARIFInvocation `json:"invocations,omitempty"`
+	Artifacts   []*SARIFArtifact   `json:"artifacts,omitempty"`
+	Results     []*SARIFResult     `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFToolComponent `json:"driver"`
+}
+
+type SARIFToolComponent struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationURI string       `json:"informationUri,omitempty"`
+	Rules          []*SARIFRule `json:"rules,omitempty"`
+}
+
+type SARIFRule struct {
+	ID                   string                  `json:"id"`
+	Name                 string                  `json:"name,omitempty"`
+	ShortDescription     *SARIFMessage           `json:"shortDescription,omitempty"`
+	FullDescription      *SARIFMessage           `json:"fullDescription,omitempty"`
+	HelpURI              string                  `json:"helpUri,omitempty"`
+	DefaultConfiguration *SARIFRuleConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{}  `json:"properties,omitempty"`
+}
+
+type SARIFRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFInvocation struct {
+	ExecutionSuccessful        bool                 `json:"executionSuccessful"`
+	ToolExecutionNotifications []*SARIFNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type SARIFNotification struct {
+	Level          string                    `json:"level"`
+	Message        SARIFMessage              `json:"message"`
+	AssociatedRule *SARIFReportingDescriptor `json:"associatedRule,omitempty"`
+}
+
+type SARIFReportingDescriptor struct {
+	ID    string `json:"id"`
+	Index int    `json:"index"`
+}
+
+type SARIFArtifact struct {
+	Location SARIFArtifactLocation `json:"location"`
+}
+
+type SARIFArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type SARIFResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    SARIFMessage           `json:"message"`
+	Locations  []*SARIFLocation       `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+type SARIFRegion struct {
+	StartLine int           `json:"startLine"`
+	EndLine   int           `json:"endLine,omitempty"`
+	Snippet   *SARIFMessage `json:"snippet,omitempty"`
+}
+
+// SARIFToolInfo describes the tool in the SARIF log
+type SARIFToolInfo struct {
+	Name           string
+	Version        string
+	InformationURI string
+}
+
+// SARIFLevel maps the check level label to the SARIF result level
+func SARIFLevel(level string) string {
+	switch level {
+	case check.LevelFatal, check.LevelError:
+		return SARIFLevelError
+	case check.LevelWarn:
+		return SARIFLevelWarning
+	default:
+		return SARIFLevelNote
+	}
+}
+
+// SARIF converts the lint report to a SARIF log
+// (the rules include all executed checks, the results include the check hits)
+func (r *Report) SARIF(dockerfilePath string, tool SARIFToolInfo) *SARIFLog {
+	run := &SARIFRun{
+		Tool: SARIFTool{
+			Driver: SARIFToolComponent{
+				Name:           tool.Name,
+				Version:        tool.Version,
+				InformationURI: tool.InformationURI,
+			},
+		},
+		Results: []*SARIFResult{},
+	}
+
+	checks := map[string]*check.Info{}
+	for id, result := range r.Hits {
+		checks[id] = result.Source
+	}
+
+	for id, result := range r.NoHits {
+		checks[id] = result.Source
+	}
+
+	var ids []string
+	for id := range checks {
+		ids = append(ids, id)
+	}
+
+	for id := range r.Errors {
+		if _, found := checks[id]; !found {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+	ruleIndexes := map[string]int{}
+	for idx, id := range ids {
+		ruleIndexes[id] = idx
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule(id, checks[id]))
+	}
+
+	invocation := &SARIFInvocation{
+		ExecutionSuccessful: len(r.Errors) == 0,
+	}
+
+	var errIDs []string
+	for id := range r.Errors {
+		errIDs = append(errIDs, id)
+	}
+
+	sort.Strings(errIDs)
+	for _, id := range errIDs {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications,
+			&SARIFNotification{
+				Level:   SARIFLevelError,
+				Message: SARIFMessage{Text: r.Errors[id].Error()},
+				AssociatedRule: &SARIFReportingDescriptor{
+					ID:    id,
+					Index: ruleIndexes[id],
+				},
+			})
+	}
+
+	run.Invocations = []*SARIFInvocation{invocation}
+
+	artifactLocation := sarifArtifactLocation(dockerfilePath)
+	run.Artifacts = []*SARIFArtifact{{Location: artifactLocation}}
+
+	for _, id := range ids {
+		result, found := r.Hits[id]
+		if !found {
+			continue
+		}
+
+		level := SARIFLevel(result.Source.Labels[check.LabelLevel])
+		newResult := func(message string) *SARIFResult {
+			if message == "" {
+				message = result.Message
+			}
+
+			if message == "" {
+				message = result.Source.Name
+			}
+
+			return &SARIFResult{
+				RuleID:    id,
+				RuleIndex: ruleIndexes[id],
+				Level:     level,
+				Message:   SARIFMessage{Text: message},
+			}
+		}
+
+		if len(result.Matches) == 0 {
+			sr := newResult(result.Message)
+			sr.Locations = []*SARIFLocation{
+				{PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: artifactLocation}},
+			}
+
+			run.Results = append(run.Results, sr)
+			continue
+		}
+
+		for _, m := range result.Matches {
+			sr := newResult(m.Message)
+			sr.Locations = []*SARIFLocation{
+				{
+					PhysicalLocation: SARIFPhysicalLocation{
+						ArtifactLocation: artifactLocation,
+						Region:           sarifMatchRegion(m),
+					},
+				},
+			}
+
+			if m.Stage != nil {
+				sr.Properties = map[string]interface{}{
+					"stage.index": m.Stage.Index,
+				}
+
+				if m.Stage.Name != "" {
+					sr.Properties["stage.name"] = m.Stage.Name
+				}
+			}
+
+			run.Results = append(run.Results, sr)
+		}
+	}
+
+	return &SARIFLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []*SARIFRun{run},
+	}
+}
+
+func sarifRule(id string, info *check.Info) *SARIFRule {
+	rule := &SARIFRule{ID: id}
+	if info == nil {
+		//checks that failed to run have no results
+		for _, c := range check.AllChecks {
+			if c.Get().ID == id {
+				info = c.Get()
+				break
+			}
+		}
+
+		if info == nil {
+			return rule
+		}
+	}
+
+	rule.Name = info.Name
+	rule.HelpURI = info.DetailsURL
+	if info.Name != "" {
+		rule.ShortDescription = &SARIFMessage{Text: info.Name}
+	}
+
+	if info.Description != "" {
+		rule.FullDescription = &SARIFMessage{Text: info.Description}
+	}
+
+	rule.DefaultConfiguration = &SARIFRuleConfiguration{
+		Level: SARIFLevel(info.Labels[check.LabelLevel]),
+	}
+
+	if len(info.Labels) > 0 {
+		rule.Properties = map[string]interface{}{}
+		var tags []string
+		for k, v := range info.Labels {
+			rule.Properties[k] = v
+			tags = append(tags, v)
+		}
+
+		sort.Strings(tags)
+		rule.Properties["tags"] = tags
+	}
+
+	return rule
+}
+
+// sarifMatchRegion returns the Dockerfile lines for the check match
+// (the stage matches point to the stage FROM instruction)
+func sarifMatchRegion(m *check.Match) *SARIFRegion {
+	inst := m.Instruction
+	if inst == nil && m.Stage != nil {
+		inst = m.Stage.FromInstruction
+	}
+
+	if inst != nil && inst.StartLine > 0 {
+		region := &SARIFRegion{
+			StartLine: inst.StartLine,
+			EndLine:   inst.EndLine,
+		}
+
+		if region.EndLine < region.StartLine {
+			region.EndLine = region.StartLine
+		}
+
+		if len(inst.RawLines) > 0 {
+			region.Snippet = &SARIFMessage{Text: strings.Join(inst.RawLines, "\n")}
+		}
+
+		return region
+	}
+
+	if m.Stage != nil && m.Stage.StartLine > 0 {
+		region := &SARIFRegion{
+			StartLine: m.Stage.StartLine,
+			EndLine:   m.Stage.EndLine,
+		}
+
+		if region.EndLine < region.StartLine {
+			region.EndLine = region.StartLine
+		}
+
+		return region
+	}
+
+	return nil
+}
+
+// sarifArtifactLocation returns the Dockerfile location
+// (the relative paths are relative to the source root)
+func sarifArtifactLocation(dockerfilePath string) SARIFArtifactLocation {
+	if filepath.IsAbs(dockerfilePath) {
+		return SARIFArtifactLocation{
+			URI: "file://" + filepath.ToSlash(dockerfilePath),
+		}
+	}
+
+	return SARIFArtifactLocation{
+		URI:       strings.TrimPrefix(filepath.ToSlash(filepath.Clean(dockerfilePath)), "./"),
+		URIBaseID: SARIFSourceRootID,
+	}
+}
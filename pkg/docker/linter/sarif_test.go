@@ -0,0 +1,84 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func TestSARIF(t *testing.T) {
+	dockerfilePath := filepath.Join("testdata", "sarif", "Dockerfile")
+	report, err := Execute(Options{
+		DockerfilePath:   dockerfilePath,
+		SkipBuildContext: true,
+		SkipDockerignore: true,
+		Selector: CheckSelector{
+			IncludeCheckIDs: map[string]struct{}{
+				"ID.20006": {}, //stage match (FROM instruction region)
+				"ID.20012": {}, //instruction match (multi-line region)
+				"ID.20014": {}, //no hits (rule only)
+				"ID.20017": {}, //instruction match
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the checks that fail to run have rules and tool notifications
+	report.Errors["ID.20001"] = errors.New("check failed")
+
+	sarif := report.SARIF(dockerfilePath, SARIFToolInfo{
+		Name:           "docker-slim",
+		Version:        "1.0.0",
+		InformationURI: "https://dockersl.im",
+	})
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarif); err != nil {
+		t.Fatal(err)
+	}
+
+	goldenPath := filepath.Join("testdata", "sarif", "golden.sarif.json")
+	if *updateGolden {
+		if err := ioutil.WriteFile(goldenPath, out.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out.Bytes(), expected) {
+		t.Errorf("SARIF output does not match %s (run the test with -update to update it):\n%s", goldenPath, out.String())
+	}
+}
+
+func TestSARIFArtifactLocation(t *testing.T) {
+	tt := []struct {
+		path     string
+		expected SARIFArtifactLocation
+	}{
+		{path: "Dockerfile", expected: SARIFArtifactLocation{URI: "Dockerfile", URIBaseID: SARIFSourceRootID}},
+		{path: "./app/Dockerfile", expected: SARIFArtifactLocation{URI: "app/Dockerfile", URIBaseID: SARIFSourceRootID}},
+		{path: "app/../build/Dockerfile", expected: SARIFArtifactLocation{URI: "build/Dockerfile", URIBaseID: SARIFSourceRootID}},
+		{path: "/src/app/Dockerfile", expected: SARIFArtifactLocation{URI: "file:///src/app/Dockerfile"}},
+	}
+
+	for _, test := range tt {
+		if got := sarifArtifactLocation(test.path); got != test.expected {
+			t.Errorf("sarifArtifactLocation(%q) = %+v, expected %+v", test.path, got, test.expected)
+		}
+	}
+}
@@ -75,6 +75,10 @@ type syscallState struct {
 	exiting      bool
 	pathParam    string
 	pathParamErr error
+	argParams    []uint64
+	capArgParams []uint64
+	callContext  *system.CallContext
+	appPid       int
 }
 
 type App struct {
@@ -110,10 +114,13 @@ func (a *App) PGID() int {
 const eventBufSize = 2000
 
 type syscallEvent struct {
-	pid       int
-	callNum   uint32
-	retVal    uint64
-	pathParam string
+	pid          int
+	callNum      uint32
+	retVal       uint64
+	pathParam    string
+	argParams    []uint64
+	capArgParams []uint64
+	callContext  *system.CallContext
 }
 
 func newApp(cmd string,
@@ -198,11 +205,50 @@ func (app *App) processSyscallActivity(e *syscallEvent) {
 	app.syscallActivity[e.callNum]++
 }
 
+// isReportedEvent checks if the syscall event is sent to the event processor
+// (the syscalls without path params are always reported)
+func (app *App) isReportedEvent(e *syscallEvent) bool {
+	if app.includeNew || e.pathParam == "" {
+		return true
+	}
+
+	_, ok := app.origPaths[e.pathParam]
+	return ok
+}
+
+func (app *App) processSyscallArgs(e *syscallEvent) {
+	if len(e.argParams) == 0 {
+		return
+	}
+
+	p, found := syscallProcessors[int(e.callNum)].(*callArgsSyscallProcessor)
+	if !found {
+		return
+	}
+
+	//the recorded indexes are the indexes used to collect the values
+	app.Report.AddSyscallArgs(e.callNum,
+		p.SyscallName(),
+		p.Args.Indexes,
+		e.argParams)
+}
+
+func (app *App) processCapabilities(e *syscallEvent) {
+	p, found := syscallProcessors[int(e.callNum)]
+	if !found || p.SyscallType() != CallArgsType || p.FailedReturnStatus(e.retVal) {
+		return
+	}
+
+	for _, name := range system.CallUsedCapabilities(p.SyscallName(), e.capArgParams, e.callContext) {
+		app.Report.AddCapabilityUse(name, p.SyscallName())
+	}
+}
+
 func (app *App) processFileActivity(e *syscallEvent) {
 	if e.pathParam != "" {
 		p, found := syscallProcessors[int(e.callNum)]
 		if !found {
-			log.Debug("ptrace.App.processFileActivity - no syscall processor - %#v", e)
+			log.Debugf("ptrace.App.processFileActivity - no syscall processor - %#v", e)
 			//shouldn't happen
 			return
 		}
@@ -279,6 +325,8 @@ done:
 				}
 			*/
 			app.processSyscallActivity(&e)
+			app.processSyscallArgs(&e)
+			app.processCapabilities(&e)
 			app.processFileActivity(&e)
 		}
 	}
@@ -480,7 +528,7 @@ func (app *App) collect() {
 	log.Debugf("ptrace.App.collect: trace syscall mainPID=%v", callPid)
 
 	pidSyscallState := map[int]*syscallState{}
-	pidSyscallState[callPid] = &syscallState{pid: callPid}
+	pidSyscallState[callPid] = &syscallState{pid: callPid, appPid: callPid}
 
 	mainExiting := false
 	waitFor := -1
@@ -599,7 +647,7 @@ func (app *App) collect() {
 			} else {
 				log.Debugf("ptrace.App.collect: collector loop - new pid - mainPid=%v pid=%v (prevPid=%v) - add state", app.MainPID(), wpid, prevPid)
 				//TODO: create new process records from clones/forks
-				cstate = &syscallState{pid: wpid}
+				cstate = &syscallState{pid: wpid, appPid: callPid}
 				pidSyscallState[wpid] = cstate
 			}
 
@@ -617,23 +665,24 @@ func (app *App) collect() {
 
 			if cstate.gotCallNum && cstate.gotRetVal {
 				evt := syscallEvent{
-					pid:       wpid,
-					callNum:   uint32(cstate.callNum),
-					retVal:    cstate.retVal,
-					pathParam: cstate.pathParam,
+					pid:          wpid,
+					callNum:      uint32(cstate.callNum),
+					retVal:       cstate.retVal,
+					pathParam:    cstate.pathParam,
+					argParams:    cstate.argParams,
+					capArgParams: cstate.capArgParams,
+					callContext:  cstate.callContext,
 				}
 
 				cstate.gotCallNum = false
 				cstate.gotRetVal = false
 				cstate.pathParam = ""
 				cstate.pathParamErr = nil
+				cstate.argParams = nil
+				cstate.capArgParams = nil
+				cstate.callContext = nil
 
-				_, ok := app.origPaths[evt.pathParam]
-				if app.includeNew {
-					ok = true
-				}
-
-				if ok {
+				if app.isReportedEvent(&evt) {
 					select {
 					case app.eventCh <- evt:
 					default:
@@ -660,7 +709,7 @@ func (app *App) collect() {
 						log.Debugf("ptrace.App.collect: PTRACE_EVENT_CLONE/[V]FORK[_DONE] - pid already exists - %v", newPid)
 						pidSyscallState[int(newPid)].started = true
 					} else {
-						pidSyscallState[int(newPid)] = &syscallState{pid: int(newPid), started: true}
+						pidSyscallState[int(newPid)] = &syscallState{pid: int(newPid), appPid: callPid, started: true}
 					}
 				}
 
@@ -766,6 +815,7 @@ type SyscallTypeName string
 
 const (
 	CheckFileType SyscallTypeName = "type.checkfile"
+	CallArgsType  SyscallTypeName = "type.callargs"
 )
 
 type SyscallProcessor interface {
@@ -859,6 +909,57 @@ func (ref *checkFileSyscallProcessor) FailedReturnStatus(retVal uint64) bool {
 	return retVal != 0
 }
 
+// callArgsSyscallProcessor records the argument values for the high-risk syscalls
+// (used to generate the seccomp profile argument filters)
+// and for the syscalls that need capabilities (used to check if the capabilities were used)
+type callArgsSyscallProcessor struct {
+	*syscallProcessorCore
+	Args    system.CallArgsInfo
+	CapArgs system.CallArgsInfo
+}
+
+func (ref *callArgsSyscallProcessor) OnCall(pid int, regs syscall.PtraceRegs, cstate *syscallState) {
+	params := callParams(regs)
+	readMem := callMemReader(pid)
+	cstate.argParams = system.CallArgValues(ref.Args, params, readMem)
+	cstate.capArgParams = system.CallArgValues(ref.CapArgs, params, readMem)
+	//the process state is captured before the call changes it
+	cstate.callContext = system.NewCallContext(ref.Name, pid, cstate.appPid, params, readMem)
+}
+
+func callParams(regs syscall.PtraceRegs) []uint64 {
+	return []uint64{
+		system.CallFirstParam(regs),
+		system.CallSecondParam(regs),
+		system.CallThirdParam(regs),
+		system.CallFourthParam(regs),
+		system.CallFifthParam(regs),
+	}
+}
+
+func callMemReader(pid int) system.CallMemReader {
+	return func(ptr uint64, out []byte) error {
+		if _, err := syscall.PtracePeekData(pid, uintptr(ptr), out); err != nil {
+			log.Debugf("callMemReader: syscall.PtracePeekData error - '%v'", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+func (ref *callArgsSyscallProcessor) OnReturn(pid int, regs syscall.PtraceRegs, cstate *syscallState) {
+	log.Tracef("callArgsSyscallProcessor.OnReturn: [%d] {%d}%s(%v) = %d", pid, cstate.callNum, ref.Name, cstate.argParams, int(cstate.retVal))
+}
+
+func (ref *callArgsSyscallProcessor) FailedCall(cstate *syscallState) bool {
+	return ref.FailedReturnStatus(cstate.retVal)
+}
+
+func (ref *callArgsSyscallProcessor) FailedReturnStatus(retVal uint64) bool {
+	return int64(retVal) < 0
+}
+
 //TODO: introduce syscall num and name consts to use instead of liternal values
 var syscallProcessors = map[int]SyscallProcessor{}
 
@@ -978,6 +1079,30 @@ func init() {
 		},
 	})
 
+	callArgsNames := map[string]struct{}{}
+	for name := range system.RecordedCallArgs {
+		callArgsNames[name] = struct{}{}
+	}
+
+	for name := range system.CallCapabilities {
+		callArgsNames[name] = struct{}{}
+	}
+
+	for name := range callArgsNames {
+		if _, found := system.LookupCallNumber(name); !found {
+			//not all syscalls that need capabilities exist on all architectures
+			continue
+		}
+
+		addSyscallProcessor(&callArgsSyscallProcessor{
+			syscallProcessorCore: &syscallProcessorCore{
+				Name: name,
+				Type: CallArgsType,
+			},
+			Args:    system.RecordedCallArgs[name],
+			CapArgs: system.CallCapabilities[name].Args,
+		})
+	}
 }
 
 func addSyscallProcessor(p SyscallProcessor) {
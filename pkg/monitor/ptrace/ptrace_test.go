@@ -0,0 +1,55 @@
+package ptrace
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/system"
+)
+
+func TestReportedEvents(t *testing.T) {
+	origPaths := map[string]interface{}{"/etc/hosts": nil}
+	app, err := newApp("/app", nil, "", "", false, nil, nil, nil, nil, false, origPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cloneNum, found := system.LookupCallNumber("clone")
+	if !found {
+		t.Fatal("no clone syscall number")
+	}
+
+	openNum, _ := system.LookupCallNumber("openat")
+
+	tt := []struct {
+		evt      syscallEvent
+		expected bool
+	}{
+		//the syscalls without path params are reported even if the new files are not included
+		{evt: syscallEvent{callNum: uint32(cloneNum), argParams: []uint64{0x3d0f00}}, expected: true},
+		{evt: syscallEvent{callNum: uint32(openNum), pathParam: "/etc/hosts"}, expected: true},
+		{evt: syscallEvent{callNum: uint32(openNum), pathParam: "/tmp/new.txt"}, expected: false},
+	}
+
+	for idx, test := range tt {
+		if got := app.isReportedEvent(&test.evt); got != test.expected {
+			t.Errorf("%d: got %v expected %v (%+v)", idx, got, test.expected, test.evt)
+		}
+
+		if test.expected {
+			app.processSyscallArgs(&test.evt)
+		}
+	}
+
+	if len(app.Report.SyscallArgs) != 1 {
+		t.Fatalf("unexpected recorded args: %+v", app.Report.SyscallArgs)
+	}
+
+	for _, info := range app.Report.SyscallArgs {
+		if info.Name != "clone" ||
+			!reflect.DeepEqual(info.ArgIndexes, system.RecordedCallArgs["clone"].Indexes) ||
+			!reflect.DeepEqual(info.Values, [][]uint64{{0x3d0f00}}) {
+			t.Errorf("unexpected clone args: %+v", info)
+		}
+	}
+}
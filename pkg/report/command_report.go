@@ -103,7 +103,7 @@ type SystemMetadata struct {
 }
 
 // Output Version for 'build'
-const OVBuildCommand = "1.0"
+const OVBuildCommand = "1.3"
 
 // BuildCommand is the 'build' command report data
 type BuildCommand struct {
@@ -115,49 +115,79 @@ type BuildCommand struct {
 	MinifiedImageSizeHuman string               `json:"minified_image_size_human"`
 	MinifiedImage          string               `json:"minified_image"`
 	MinifiedImageHasData   bool                 `json:"minified_image_has_data"`
+	MinifiedImageID        string               `json:"minified_image_id,omitempty"`
+	MinifiedImageDigest    string               `json:"minified_image_digest,omitempty"`
+	MinifiedImageOCILayout string               `json:"minified_image_oci_layout,omitempty"`
+	MinifiedImageArchive   string               `json:"minified_image_archive,omitempty"`
 	MinifiedBy             float64              `json:"minified_by"`
+	ImageBuildEngine       string               `json:"image_build_engine,omitempty"`
 	ArtifactLocation       string               `json:"artifact_location"`
 	ContainerReportName    string               `json:"container_report_name"`
 	SeccompProfileName     string               `json:"seccomp_profile_name"`
+	SeccompMissingSyscalls map[string][]string  `json:"seccomp_missing_syscalls,omitempty"`
 	AppArmorProfileName    string               `json:"apparmor_profile_name"`
+	CapabilitiesFileName   string               `json:"capabilities_file_name,omitempty"`
+	Capabilities           []string             `json:"capabilities,omitempty"` //the capabilities used by the application
+	KubernetesArtifacts    []string             `json:"kubernetes_artifacts,omitempty"`
+	RemovedFilesReportName string               `json:"removed_files_report_name,omitempty"`
+	SBOMFiles              []string             `json:"sbom_files,omitempty"`
+	StaticIncludes         []string             `json:"static_includes,omitempty"`
+	StaticOnly             bool                 `json:"static_only,omitempty"`
+	KeptFileRules          map[string]int       `json:"kept_file_rules,omitempty"`
+	Explanations           []*KeptFileInfo      `json:"explanations,omitempty"`
+	MergedReports          []string             `json:"merged_reports,omitempty"`
 	ImageStack             []*reverse.ImageInfo `json:"image_stack"`
 }
 
+// KeptFileInfo explains why a file (or a directory) was kept in the minified image
+type KeptFileInfo struct {
+	Path     string        `json:"path"`
+	Kept     bool          `json:"kept"`
+	KeptPath string        `json:"kept_path,omitempty"`
+	KeptBy   []*Provenance `json:"kept_by,omitempty"`
+}
+
 // Output Version for 'profile'
 const OVProfileCommand = "1.0"
 
 // ProfileCommand is the 'profile' command report data
 type ProfileCommand struct {
 	Command
-	OriginalImage          string  `json:"original_image"`
-	OriginalImageSize      int64   `json:"original_image_size"`
-	OriginalImageSizeHuman string  `json:"original_image_size_human"`
-	MinifiedImageSize      int64   `json:"minified_image_size"`
-	MinifiedImageSizeHuman string  `json:"minified_image_size_human"`
-	MinifiedImage          string  `json:"minified_image"`
-	MinifiedImageHasData   bool    `json:"minified_image_has_data"`
-	MinifiedBy             float64 `json:"minified_by"`
-	ArtifactLocation       string  `json:"artifact_location"`
-	ContainerReportName    string  `json:"container_report_name"`
-	SeccompProfileName     string  `json:"seccomp_profile_name"`
-	AppArmorProfileName    string  `json:"apparmor_profile_name"`
+	OriginalImage          string              `json:"original_image"`
+	OriginalImageSize      int64               `json:"original_image_size"`
+	OriginalImageSizeHuman string              `json:"original_image_size_human"`
+	MinifiedImageSize      int64               `json:"minified_image_size"`
+	MinifiedImageSizeHuman string              `json:"minified_image_size_human"`
+	MinifiedImage          string              `json:"minified_image"`
+	MinifiedImageHasData   bool                `json:"minified_image_has_data"`
+	MinifiedBy             float64             `json:"minified_by"`
+	ArtifactLocation       string              `json:"artifact_location"`
+	ContainerReportName    string              `json:"container_report_name"`
+	SeccompProfileName     string              `json:"seccomp_profile_name"`
+	AppArmorProfileName    string              `json:"apparmor_profile_name"`
+	SeccompMissingSyscalls map[string][]string `json:"seccomp_missing_syscalls,omitempty"`
+	CapabilitiesFileName   string              `json:"capabilities_file_name,omitempty"`
+	Capabilities           []string            `json:"capabilities,omitempty"` //the capabilities used by the application
+	KubernetesArtifacts    []string            `json:"kubernetes_artifacts,omitempty"`
 }
 
 // Output Version for 'xray'
-const OVXrayCommand = "1.2"
+const OVXrayCommand = "1.4"
 
 // XrayCommand is the 'xray' command report data
 type XrayCommand struct {
 	Command
-	TargetReference      string                      `json:"target_reference"`
-	SourceImage          ImageMetadata               `json:"source_image"`
-	ArtifactLocation     string                      `json:"artifact_location"`
-	ImageReport          *dockerimage.ImageReport    `json:"image_report,omitempty"`
-	ImageStack           []*reverse.ImageInfo        `json:"image_stack"`
-	ImageLayers          []*dockerimage.LayerReport  `json:"image_layers"`
-	ImageArchiveLocation string                      `json:"image_archive_location"`
-	RawImageManifest     *dockerimage.ManifestObject `json:"raw_image_manifest,omitempty"`
-	RawImageConfig       *dockerimage.ConfigObject   `json:"raw_image_config,omitempty"`
+	TargetReference      string                       `json:"target_reference"`
+	SourceImage          ImageMetadata                `json:"source_image"`
+	ArtifactLocation     string                       `json:"artifact_location"`
+	ImageReport          *dockerimage.ImageReport     `json:"image_report,omitempty"`
+	ImageStack           []*reverse.ImageInfo         `json:"image_stack"`
+	ImageLayers          []*dockerimage.LayerReport   `json:"image_layers"`
+	ImageArchiveLocation string                       `json:"image_archive_location"`
+	RawImageManifest     *dockerimage.ManifestObject  `json:"raw_image_manifest,omitempty"`
+	RawImageConfig       *dockerimage.ConfigObject    `json:"raw_image_config,omitempty"`
+	ImageDiff            *dockerimage.ImageDiffReport `json:"image_diff,omitempty"`
+	SBOMFiles            []string                     `json:"sbom_files,omitempty"`
 }
 
 // Output Version for 'lint'
@@ -234,12 +264,63 @@ type RunCommand struct {
 }
 
 // Output Version for 'registry'
-const OVRegistryCommand = "1.0"
+const OVRegistryCommand = "1.3"
 
 // RegistryCommand is the 'registry' command report data
 type RegistryCommand struct {
 	Command
-	TargetReference string `json:"target_reference"`
+	TargetReference string            `json:"target_reference"`
+	Push            *RegistryPushInfo `json:"push,omitempty"`
+	Copy            *RegistryCopyInfo `json:"copy,omitempty"`
+	Pull            *RegistryPullInfo `json:"pull,omitempty"`
+}
+
+// RegistryPushInfo provides the 'registry push' results
+type RegistryPushInfo struct {
+	Source        string `json:"source"`
+	SourcePath    string `json:"source_path,omitempty"`
+	Destination   string `json:"destination"`
+	Digest        string `json:"digest"`
+	LayerCount    int    `json:"layer_count"`
+	BytesUploaded int64  `json:"bytes_uploaded"`
+}
+
+// RegistryPullInfo provides the 'registry pull' results
+type RegistryPullInfo struct {
+	Reference     string                  `json:"reference"`
+	Digest        string                  `json:"digest"`
+	MediaType     string                  `json:"media_type"`
+	Manifests     []*RegistryManifestInfo `json:"manifests,omitempty"`
+	Images        []*RegistryManifestInfo `json:"images,omitempty"`
+	OCILayoutPath string                  `json:"oci_layout_path,omitempty"`
+	ArchivePath   string                  `json:"archive_path,omitempty"`
+	SavedToDocker bool                    `json:"saved_to_docker,omitempty"`
+}
+
+// RegistryManifestInfo provides the image index manifest info
+type RegistryManifestInfo struct {
+	Platform  string `json:"platform,omitempty"`
+	Digest    string `json:"digest"`
+	MediaType string `json:"media_type"`
+	Size      int64  `json:"size"`
+}
+
+// RegistryCopyInfo provides the 'registry copy' results
+type RegistryCopyInfo struct {
+	Source       string                     `json:"source"`
+	SourceDigest string                     `json:"source_digest"`
+	MediaType    string                     `json:"media_type"`
+	Platforms    []string                   `json:"platforms,omitempty"`
+	Destinations []*RegistryCopyDestination `json:"destinations"`
+}
+
+// RegistryCopyDestination provides the 'registry copy' results for one destination
+// (the digest is different from the source digest if the platforms are filtered)
+type RegistryCopyDestination struct {
+	Reference     string `json:"reference"`
+	Digest        string `json:"digest"`
+	PinnedRef     string `json:"pinned_reference"`
+	BytesUploaded int64  `json:"bytes_uploaded"`
 }
 
 func (cmd *Command) init(containerized bool) {
@@ -507,3 +588,8 @@ func (p *XrayCommand) Save() bool {
 func (p *LintCommand) Save() bool {
 	return p.saveInfo(p)
 }
+
+// Save saves the Registry command report data to the configured location
+func (p *RegistryCommand) Save() bool {
+	return p.saveInfo(p)
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"os"
+	"sort"
+	"strconv"
 )
 
 // ArtifactType is an artifact type ID
@@ -73,6 +75,7 @@ type FanMonitorReport struct {
 	MainProcess      *ProcessInfo                    `json:"main_process"`
 	Processes        map[string]*ProcessInfo         `json:"processes"`
 	ProcessFiles     map[string]map[string]*FileInfo `json:"process_files"`
+	NewFiles         map[string]*FileInfo            `json:"new_files,omitempty"` //files created at runtime (not in the original image)
 }
 
 // PeMonitorReport is a processing monitoring report
@@ -88,13 +91,106 @@ type SyscallStatInfo struct {
 	Count  uint64 `json:"count"`
 }
 
+// SyscallArgsInfo contains the observed argument values for a system call
+// (the distinct value sets in the ArgIndexes order)
+type SyscallArgsInfo struct {
+	Number     uint32     `json:"num"`
+	Name       string     `json:"name"`
+	ArgIndexes []uint     `json:"arg_indexes"`
+	Values     [][]uint64 `json:"values"`
+}
+
+// CapabilityInfo describes the observed use of a Linux capability
+type CapabilityInfo struct {
+	Name     string   `json:"name"`
+	Count    uint64   `json:"count"`
+	Syscalls []string `json:"syscalls"` //the system calls that used the capability
+}
+
 // PtMonitorReport contains various process execution metadata
 type PtMonitorReport struct {
-	ArchName     string                     `json:"arch_name"`
-	SyscallCount uint64                     `json:"syscall_count"`
-	SyscallNum   uint32                     `json:"syscall_num"`
-	SyscallStats map[string]SyscallStatInfo `json:"syscall_stats"`
-	FSActivity   map[string]*FSActivityInfo `json:"fs_activity"`
+	ArchName     string                      `json:"arch_name"`
+	SyscallCount uint64                      `json:"syscall_count"`
+	SyscallNum   uint32                      `json:"syscall_num"`
+	SyscallStats map[string]SyscallStatInfo  `json:"syscall_stats"`
+	SyscallArgs  map[string]*SyscallArgsInfo `json:"syscall_args,omitempty"`
+	Capabilities map[string]*CapabilityInfo  `json:"capabilities,omitempty"` //keyed by the capability name
+	FSActivity   map[string]*FSActivityInfo  `json:"fs_activity"`
+}
+
+// AddSyscallArgs records the argument values for a system call
+// (keyed by the system call number like the system call stats)
+func (p *PtMonitorReport) AddSyscallArgs(num uint32, name string, indexes []uint, values []uint64) {
+	if p.SyscallArgs == nil {
+		p.SyscallArgs = map[string]*SyscallArgsInfo{}
+	}
+
+	key := strconv.FormatUint(uint64(num), 10)
+	info, found := p.SyscallArgs[key]
+	if !found {
+		info = &SyscallArgsInfo{
+			Number:     num,
+			Name:       name,
+			ArgIndexes: indexes,
+		}
+
+		p.SyscallArgs[key] = info
+	}
+
+	info.AddValues(values)
+}
+
+// AddCapabilityUse records a capability used by a system call
+func (p *PtMonitorReport) AddCapabilityUse(name string, syscallName string) {
+	if p.Capabilities == nil {
+		p.Capabilities = map[string]*CapabilityInfo{}
+	}
+
+	info, found := p.Capabilities[name]
+	if !found {
+		info = &CapabilityInfo{Name: name}
+		p.Capabilities[name] = info
+	}
+
+	info.Count++
+	for _, current := range info.Syscalls {
+		if current == syscallName {
+			return
+		}
+	}
+
+	info.Syscalls = append(info.Syscalls, syscallName)
+}
+
+// CapabilitySet returns the minimal (sorted) set of the capabilities used by the application
+func (p *PtMonitorReport) CapabilitySet() []string {
+	var names []string
+	for name := range p.Capabilities {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// AddValues adds the argument values if they are not recorded yet
+func (i *SyscallArgsInfo) AddValues(values []uint64) {
+	if len(values) != len(i.ArgIndexes) {
+		return
+	}
+
+next:
+	for _, current := range i.Values {
+		for idx := range current {
+			if current[idx] != values[idx] {
+				continue next
+			}
+		}
+
+		return
+	}
+
+	i.Values = append(i.Values, values)
 }
 
 type FSActivityInfo struct {
@@ -119,8 +215,41 @@ type ArtifactProps struct {
 	AppType    string          `json:"app_type,omitempty"`
 	FileInode  uint64          `json:"-"` //todo
 	FSActivity *FSActivityInfo `json:"-"`
+	KeptBy     []*Provenance   `json:"kept_by,omitempty"`
+}
+
+// Provenance describes why an artifact was kept in the minified image
+type Provenance struct {
+	Rule   string `json:"rule"`
+	Source string `json:"source,omitempty"` //the artifact or the parameter that caused the artifact to be kept
 }
 
+// Provenance rules
+const (
+	ProvenanceFanotify         = "fanotify" //source: the process that accessed the file
+	ProvenancePtrace           = "ptrace"   //source: the file check system calls
+	ProvenanceSymlink          = "symlink"
+	ProvenanceHardlink         = "hardlink"
+	ProvenanceELFDeps          = "elf.deps"
+	ProvenanceStaticEntrypoint = "static.entrypoint"
+	ProvenanceStaticELFDeps    = "static.elf.deps"
+	ProvenanceStaticShebang    = "static.shebang"
+	ProvenanceStaticSymlink    = "static.symlink"
+	ProvenanceStaticSystem     = "static.system"
+	ProvenanceIncludePath      = "include.path"
+	ProvenanceIncludeBin       = "include.bin"
+	ProvenanceIncludeExe       = "include.exe"
+	ProvenanceIncludeShell     = "include.shell"
+	ProvenancePreservePath     = "preserve.path"
+	ProvenanceCerts            = "certs"
+	ProvenanceSystem           = "system"
+	ProvenanceFixupPython      = "fixup.python"
+	ProvenanceFixupRuby        = "fixup.ruby"
+	ProvenanceFixupNode        = "fixup.node"
+	ProvenanceFixupNginx       = "fixup.nginx"
+	ProvenanceMergeReport      = "merge.report"
+)
+
 // UnmarshalJSON decodes artifact property data
 func (p *ArtifactProps) UnmarshalJSON(data []byte) error {
 	type artifactPropsType ArtifactProps
@@ -157,9 +286,38 @@ func (p *ArtifactProps) MarshalJSON() ([]byte, error) {
 	return out.Bytes(), err
 }
 
+// AppStackInfo contains the detected application stack info
+type AppStackInfo struct {
+	Language    string   `json:"language"`
+	CodeFiles   uint     `json:"code_files"`
+	PackageDirs []string `json:"package_dirs,omitempty"`
+}
+
+// AppDependencyInfo contains the info for a language level application dependency
+type AppDependencyInfo struct {
+	Language string `json:"language"`
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Path     string `json:"path"`
+	Runtime  bool   `json:"runtime"` //true if the dependency files were accessed at runtime
+}
+
+// OSPackageInfo contains the info for an OS package with files in the image artifacts
+type OSPackageInfo struct {
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	Arch          string `json:"arch,omitempty"`
+	InstalledSize int64  `json:"installed_size,omitempty"`
+	Manager       string `json:"manager"`
+	Files         int    `json:"files"` //number of package files in the image artifacts
+}
+
 // ImageReport contains image report fields
 type ImageReport struct {
-	Files []*ArtifactProps `json:"files"`
+	Files           []*ArtifactProps     `json:"files"`
+	AppStacks       []*AppStackInfo      `json:"app_stacks,omitempty"`
+	AppDependencies []*AppDependencyInfo `json:"app_dependencies,omitempty"`
+	OSPackages      []*OSPackageInfo     `json:"os_packages,omitempty"`
 }
 
 // MonitorReports contains monitoring report fields
@@ -0,0 +1,23 @@
+package report
+
+import (
+	"github.com/docker-slim/docker-slim/pkg/command"
+)
+
+// Output Version for the exit summary
+const OVExitSummary = "1.0"
+
+// ExitSummary is the machine-readable summary saved when the command exits
+// (saved for the early exits too, when the command report might not be saved)
+type ExitSummary struct {
+	Version    string        `json:"version"`
+	AppVersion string        `json:"app_version"`
+	Command    string        `json:"command"`
+	State      command.State `json:"state"`
+	ExitCode   int           `json:"exit_code"`
+	ErrorType  string        `json:"error_type,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	ReportFile string        `json:"report_file,omitempty"`
+	StartTime  string        `json:"start_time"`
+	EndTime    string        `json:"end_time"`
+}
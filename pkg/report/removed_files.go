@@ -0,0 +1,47 @@
+package report
+
+// DefaultRemovedFilesReportFileName is the default name for the removed files report
+const DefaultRemovedFilesReportFileName = "removed_files.json"
+
+// RemovedFilesReport describes the files from the original image
+// that are not in the minified image
+type RemovedFilesReport struct {
+	Count       int                      `json:"count"`
+	Size        int64                    `json:"size"`
+	SizeHuman   string                   `json:"size_human"`
+	KeptCount   int                      `json:"kept_count"`
+	KeptSize    int64                    `json:"kept_size"`
+	Directories []*RemovedFilesGroup     `json:"directories,omitempty"`
+	OSPackages  []*RemovedOSPackageFiles `json:"os_packages,omitempty"`
+	Unpackaged  *RemovedFilesGroup       `json:"unpackaged,omitempty"`
+	Executables []*RemovedFileInfo       `json:"executables,omitempty"` //executables and shared objects
+}
+
+// RemovedFilesGroup contains the removed file stats for a group of files
+type RemovedFilesGroup struct {
+	Name      string `json:"name"`
+	Count     int    `json:"count"`
+	Size      int64  `json:"size"`
+	SizeHuman string `json:"size_human"`
+}
+
+// RemovedOSPackageFiles contains the removed file stats for an OS package
+type RemovedOSPackageFiles struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Arch       string `json:"arch,omitempty"`
+	Manager    string `json:"manager"`
+	Count      int    `json:"count"`
+	Size       int64  `json:"size"`
+	SizeHuman  string `json:"size_human"`
+	KeptCount  int    `json:"kept_count"`
+	AllRemoved bool   `json:"all_removed"`
+}
+
+// RemovedFileInfo describes a removed executable or shared object
+type RemovedFileInfo struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Mode      string `json:"mode"`
+	OSPackage string `json:"os_package,omitempty"`
+}
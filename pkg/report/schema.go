@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/docker-slim/docker-slim/pkg/command"
+	"github.com/docker-slim/docker-slim/pkg/docker/dockerimage"
+	"github.com/docker-slim/docker-slim/pkg/util/jsonschema"
+)
+
+// Report schema names (in addition to the command names)
+const (
+	SchemaContainerReport    = "container"
+	SchemaRemovedFilesReport = "removed-files"
+	SchemaExitSummary        = "exit-summary"
+)
+
+type reportSchemaInfo struct {
+	name    string
+	title   string
+	version string //output version for the command reports
+	report  interface{}
+}
+
+var reportSchemas = []*reportSchemaInfo{
+	{name: string(command.Build), title: "'build' command report", version: OVBuildCommand, report: BuildCommand{}},
+	{name: string(command.Profile), title: "'profile' command report", version: OVProfileCommand, report: ProfileCommand{}},
+	{name: string(command.Xray), title: "'xray' command report", version: OVXrayCommand, report: XrayCommand{}},
+	{name: string(command.Lint), title: "'lint' command report", version: OVLintCommand, report: LintCommand{}},
+	{name: string(command.Containerize), title: "'containerize' command report", version: OVContainerizeCommand, report: ContainerizeCommand{}},
+	{name: string(command.Convert), title: "'convert' command report", version: OVConvertCommand, report: ConvertCommand{}},
+	{name: string(command.Edit), title: "'edit' command report", version: OVEditCommand, report: EditCommand{}},
+	{name: string(command.Debug), title: "'debug' command report", version: OVDebugCommand, report: DebugCommand{}},
+	{name: string(command.Probe), title: "'probe' command report", version: OVProbeCommand, report: ProbeCommand{}},
+	{name: string(command.Server), title: "'server' command report", version: OVServerCommand, report: ServerCommand{}},
+	{name: string(command.Run), title: "'run' command report", version: OVRunCommand, report: RunCommand{}},
+	{name: string(command.Registry), title: "'registry' command report", version: OVRegistryCommand, report: RegistryCommand{}},
+	{name: SchemaContainerReport, title: "container report (" + DefaultContainerReportFileName + ")", report: ContainerReport{}},
+	{name: SchemaRemovedFilesReport, title: "removed files report (" + DefaultRemovedFilesReportFileName + ")", report: RemovedFilesReport{}},
+	{name: SchemaExitSummary, title: "exit summary", version: OVExitSummary, report: ExitSummary{}},
+}
+
+// SchemaNames returns the names of the reports with JSON schemas
+func SchemaNames() []string {
+	var names []string
+	for _, info := range reportSchemas {
+		names = append(names, info.name)
+	}
+
+	return names
+}
+
+// Schema returns the JSON schema for the named report
+// (the command report schemas require the current command output version)
+func Schema(name string) (*jsonschema.Schema, error) {
+	for _, info := range reportSchemas {
+		if info.name != name {
+			continue
+		}
+
+		g := jsonschema.NewGenerator()
+		g.Overrides[reflect.TypeOf(dockerimage.ChangeType(0))] = &jsonschema.Schema{Type: jsonschema.TypeString}
+		g.ExtraProperties[reflect.TypeOf(ArtifactProps{})] = map[string]*jsonschema.Schema{
+			"file_type": {Type: jsonschema.TypeString},
+		}
+
+		schema := g.Reflect(info.report)
+		schema.Title = info.title
+		if info.version != "" {
+			schema.Comment = fmt.Sprintf("output version %s", info.version)
+			if vs, found := schema.Properties["version"]; found {
+				vs.Const = info.version
+			}
+		}
+
+		return schema, nil
+	}
+
+	return nil, fmt.Errorf("unknown report schema - %s", name)
+}
+
+// SchemaData returns the encoded JSON schema for the named report
+func SchemaData(name string) ([]byte, error) {
+	schema, err := Schema(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
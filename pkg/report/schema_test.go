@@ -0,0 +1,290 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/docker-slim/docker-slim/pkg/command"
+	"github.com/docker-slim/docker-slim/pkg/util/jsonschema"
+)
+
+func TestSchemaReports(t *testing.T) {
+	for _, info := range reportSchemas {
+		schema, err := Schema(info.name)
+		if err != nil {
+			t.Fatalf("Schema(%s): %v", info.name, err)
+		}
+
+		//the schema documents are encoded as JSON for the 'report schema' command
+		if _, err := SchemaData(info.name); err != nil {
+			t.Fatalf("SchemaData(%s): %v", info.name, err)
+		}
+
+		zero := reflect.New(reflect.TypeOf(info.report))
+		setVersion(zero, info.version)
+		if errs := validateReport(schema, zero.Interface()); len(errs) > 0 {
+			t.Errorf("%s: empty report does not match the schema:\n%s", info.name, strings.Join(errs, "\n"))
+		}
+
+		filled := reflect.New(reflect.TypeOf(info.report))
+		fillValue(filled.Elem(), 0)
+		setVersion(filled, info.version)
+		if errs := validateReport(schema, filled.Interface()); len(errs) > 0 {
+			t.Errorf("%s: filled report does not match the schema:\n%s", info.name, strings.Join(errs, "\n"))
+		}
+	}
+}
+
+func TestSchemaBuildCommandReport(t *testing.T) {
+	schema, err := Schema(string(command.Build))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewBuildCommand("", false)
+	cmd.State = command.StateCompleted
+	cmd.MinifiedImage = "app.slim"
+	cmd.KeptFileRules = map[string]int{ProvenanceFanotify: 2}
+	if errs := validateReport(schema, cmd); len(errs) > 0 {
+		t.Fatalf("build command report does not match the schema:\n%s", strings.Join(errs, "\n"))
+	}
+
+	data, _ := json.Marshal(cmd)
+	var doc map[string]interface{}
+	json.Unmarshal(data, &doc)
+
+	tt := []struct {
+		name   string
+		change func(doc map[string]interface{})
+	}{
+		{name: "unknown field", change: func(doc map[string]interface{}) { doc["unknown_field"] = 1 }},
+		{name: "wrong version", change: func(doc map[string]interface{}) { doc["version"] = "0.1" }},
+		{name: "missing field", change: func(doc map[string]interface{}) { delete(doc, "minified_image") }},
+		{name: "wrong type", change: func(doc map[string]interface{}) { doc["minified_by"] = "a lot" }},
+		{name: "wrong map value", change: func(doc map[string]interface{}) {
+			doc["kept_file_rules"] = map[string]interface{}{ProvenanceFanotify: "2"}
+		}},
+	}
+
+	for _, test := range tt {
+		changed := map[string]interface{}{}
+		for k, v := range doc {
+			changed[k] = v
+		}
+
+		test.change(changed)
+		if errs := validateSchema(schema, schema, changed, ""); len(errs) == 0 {
+			t.Errorf("%s: expected validation errors", test.name)
+		}
+	}
+}
+
+func TestSchemaContainerReportFileType(t *testing.T) {
+	schema, err := Schema(SchemaContainerReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	creport := &ContainerReport{}
+	creport.Image.Files = []*ArtifactProps{
+		{
+			FileType: FileArtifactType,
+			FilePath: "/bin/sh",
+			ModeText: "-rwxr-xr-x",
+			KeptBy:   []*Provenance{{Rule: ProvenanceFanotify, Source: "/bin/sh"}},
+		},
+	}
+
+	if errs := validateReport(schema, creport); len(errs) > 0 {
+		t.Fatalf("container report does not match the schema:\n%s", strings.Join(errs, "\n"))
+	}
+}
+
+func setVersion(v reflect.Value, version string) {
+	if version == "" {
+		return
+	}
+
+	if field := v.Elem().FieldByName("Version"); field.IsValid() && field.Kind() == reflect.String {
+		field.SetString(version)
+	}
+}
+
+// fillValue sets all fields, one element in the slices and one entry in the maps
+// (so the encoded data includes every nested schema)
+func fillValue(v reflect.Value, depth int) {
+	if depth > 8 || !v.CanSet() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.String:
+		v.SetString("value")
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillValue(v.Elem(), depth+1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillValue(v.Index(0), depth+1)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillValue(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		key := reflect.New(v.Type().Key()).Elem()
+		fillValue(key, depth+1)
+		elem := reflect.New(v.Type().Elem()).Elem()
+		fillValue(elem, depth+1)
+		m.SetMapIndex(key, elem)
+		v.Set(m)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillValue(v.Field(i), depth+1)
+		}
+	}
+}
+
+func validateReport(schema *jsonschema.Schema, report interface{}) []string {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []string{err.Error()}
+	}
+
+	return validateSchema(schema, schema, doc, "")
+}
+
+// validateSchema validates the decoded JSON data
+// (supports only the schema keywords used by the schema generator)
+func validateSchema(root, schema *jsonschema.Schema, value interface{}, location string) []string {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/definitions/")
+		def, found := root.Definitions[name]
+		if !found {
+			return []string{fmt.Sprintf("%s: unknown schema reference - %s", location, schema.Ref)}
+		}
+
+		return validateSchema(root, def, value, location)
+	}
+
+	if len(schema.AnyOf) > 0 {
+		var errs []string
+		for _, option := range schema.AnyOf {
+			optionErrs := validateSchema(root, option, value, location)
+			if len(optionErrs) == 0 {
+				return nil
+			}
+
+			errs = append(errs, optionErrs...)
+		}
+
+		return errs
+	}
+
+	if schema.Type != nil && !matchesType(schema.Type, value) {
+		return []string{fmt.Sprintf("%s: unexpected value type - %T (expected %v)", location, value, schema.Type)}
+	}
+
+	if schema.Const != nil && !reflect.DeepEqual(schema.Const, value) {
+		return []string{fmt.Sprintf("%s: unexpected value - %v (expected %v)", location, value, schema.Const)}
+	}
+
+	var errs []string
+	switch tv := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, found := tv[name]; !found {
+				errs = append(errs, fmt.Sprintf("%s: missing required property - %s", location, name))
+			}
+		}
+
+		var names []string
+		for name := range tv {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+		for _, name := range names {
+			propLocation := location + "/" + name
+			if ps, found := schema.Properties[name]; found {
+				errs = append(errs, validateSchema(root, ps, tv[name], propLocation)...)
+				continue
+			}
+
+			switch ap := schema.AdditionalProperties.(type) {
+			case bool:
+				if !ap {
+					errs = append(errs, fmt.Sprintf("%s: unexpected property", propLocation))
+				}
+			case *jsonschema.Schema:
+				errs = append(errs, validateSchema(root, ap, tv[name], propLocation)...)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for idx, item := range tv {
+				errs = append(errs, validateSchema(root, schema.Items, item, fmt.Sprintf("%s/%d", location, idx))...)
+			}
+		}
+	}
+
+	return errs
+}
+
+func matchesType(schemaType interface{}, value interface{}) bool {
+	var names []string
+	switch st := schemaType.(type) {
+	case string:
+		names = []string{st}
+	case []string:
+		names = st
+	}
+
+	for _, name := range names {
+		switch tv := value.(type) {
+		case nil:
+			if name == jsonschema.TypeNull {
+				return true
+			}
+		case bool:
+			if name == jsonschema.TypeBoolean {
+				return true
+			}
+		case float64:
+			if name == jsonschema.TypeNumber || (name == jsonschema.TypeInteger && tv == float64(int64(tv))) {
+				return true
+			}
+		case string:
+			if name == jsonschema.TypeString {
+				return true
+			}
+		case []interface{}:
+			if name == jsonschema.TypeArray {
+				return true
+			}
+		case map[string]interface{}:
+			if name == jsonschema.TypeObject {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,191 @@
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	cdxFormat      = "CycloneDX"
+	cdxSpecVersion = "1.4"
+	cdxImageRef    = "image"
+)
+
+type cdxDocument struct {
+	BOMFormat    string           `json:"bomFormat"`
+	SpecVersion  string           `json:"specVersion"`
+	SerialNumber string           `json:"serialNumber"`
+	Version      int              `json:"version"`
+	Metadata     cdxMetadata      `json:"metadata"`
+	Components   []*cdxComponent  `json:"components"`
+	Dependencies []*cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp"`
+	Tools     []*cdxTool    `json:"tools"`
+	Component *cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Vendor  string `json:"vendor,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxComponent struct {
+	BOMRef     string         `json:"bom-ref"`
+	Type       string         `json:"type"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version,omitempty"`
+	Hashes     []*cdxHash     `json:"hashes,omitempty"`
+	PURL       string         `json:"purl,omitempty"`
+	Properties []*cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+const cdxPropertyPrefix = "docker-slim:"
+
+func (ref *Document) cycloneDX() *cdxDocument {
+	image := &cdxComponent{
+		BOMRef:  cdxImageRef,
+		Type:    "container",
+		Name:    ref.imageName(),
+		Version: ref.Image.Digest,
+	}
+
+	if ref.Image.ID != "" {
+		image.Properties = append(image.Properties, &cdxProperty{Name: cdxPropertyPrefix + "image:id", Value: ref.Image.ID})
+	}
+
+	if ref.Image.OS != "" {
+		image.Properties = append(image.Properties, &cdxProperty{Name: cdxPropertyPrefix + "image:os", Value: ref.Image.OS})
+	}
+
+	if ref.Image.Architecture != "" {
+		image.Properties = append(image.Properties, &cdxProperty{Name: cdxPropertyPrefix + "image:architecture", Value: ref.Image.Architecture})
+	}
+
+	if ref.Image.Distro != "" {
+		image.Properties = append(image.Properties, &cdxProperty{Name: cdxPropertyPrefix + "image:distro", Value: ref.Image.Distro})
+	}
+
+	doc := &cdxDocument{
+		BOMFormat:    cdxFormat,
+		SpecVersion:  cdxSpecVersion,
+		SerialNumber: fmt.Sprintf("urn:uuid:%s", newUUID()),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: ref.Created.Format(time.RFC3339),
+			Tools: []*cdxTool{
+				{
+					Name:    ToolName,
+					Version: ref.ToolVersion,
+				},
+			},
+			Component: image,
+		},
+		Components: []*cdxComponent{},
+	}
+
+	imageDeps := &cdxDependency{Ref: cdxImageRef, DependsOn: []string{}}
+	refs := map[string]struct{}{}
+	for _, p := range ref.Packages {
+		purl := PackageURL(p, ref.Image.Distro)
+		if _, found := refs[purl]; found {
+			//the BOM references must be unique
+			continue
+		}
+
+		refs[purl] = struct{}{}
+		component := &cdxComponent{
+			BOMRef:  purl,
+			Type:    "library",
+			Name:    p.Name,
+			Version: p.Version,
+			PURL:    purl,
+			Properties: []*cdxProperty{
+				{Name: cdxPropertyPrefix + "package:manager", Value: p.Manager},
+			},
+		}
+
+		if p.InstalledSize > 0 {
+			component.Properties = append(component.Properties,
+				&cdxProperty{Name: cdxPropertyPrefix + "package:installed_size", Value: fmt.Sprintf("%d", p.InstalledSize)})
+		}
+
+		doc.Components = append(doc.Components, component)
+		imageDeps.DependsOn = append(imageDeps.DependsOn, component.BOMRef)
+	}
+
+	for _, s := range ref.AppStacks {
+		component := &cdxComponent{
+			BOMRef: fmt.Sprintf("app-stack:%s", s.Language),
+			Type:   "framework",
+			Name:   s.Language,
+			Properties: []*cdxProperty{
+				{Name: cdxPropertyPrefix + "app_stack:code_files", Value: fmt.Sprintf("%d", s.CodeFiles)},
+			},
+		}
+
+		if len(s.PackageDirs) > 0 {
+			component.Properties = append(component.Properties,
+				&cdxProperty{Name: cdxPropertyPrefix + "app_stack:package_dirs", Value: strings.Join(s.PackageDirs, ",")})
+		}
+
+		doc.Components = append(doc.Components, component)
+		imageDeps.DependsOn = append(imageDeps.DependsOn, component.BOMRef)
+	}
+
+	for _, f := range ref.Files {
+		component := &cdxComponent{
+			BOMRef: fmt.Sprintf("file:%s", f.Path),
+			Type:   "file",
+			Name:   f.Path,
+			Properties: []*cdxProperty{
+				{Name: cdxPropertyPrefix + "file:size", Value: fmt.Sprintf("%d", f.Size)},
+			},
+		}
+
+		if f.SHA1 != "" {
+			component.Hashes = append(component.Hashes, &cdxHash{Alg: "SHA-1", Content: f.SHA1})
+		}
+
+		if f.SHA256 != "" {
+			component.Hashes = append(component.Hashes, &cdxHash{Alg: "SHA-256", Content: f.SHA256})
+		}
+
+		if f.DataType != "" {
+			component.Properties = append(component.Properties,
+				&cdxProperty{Name: cdxPropertyPrefix + "file:data_type", Value: f.DataType})
+		}
+
+		if f.AppType != "" {
+			component.Properties = append(component.Properties,
+				&cdxProperty{Name: cdxPropertyPrefix + "file:app_type", Value: f.AppType})
+		}
+
+		doc.Components = append(doc.Components, component)
+	}
+
+	if len(imageDeps.DependsOn) > 0 {
+		doc.Dependencies = append(doc.Dependencies, imageDeps)
+	}
+
+	return doc
+}
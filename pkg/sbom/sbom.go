@@ -0,0 +1,257 @@
+package sbom
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SBOM document formats
+const (
+	FormatSPDXJSON      = "spdx-json"
+	FormatCycloneDXJSON = "cyclonedx-json"
+)
+
+// SBOM document file names
+const (
+	FileNameSPDXJSON      = "sbom.spdx.json"
+	FileNameCycloneDXJSON = "sbom.cyclonedx.json"
+)
+
+const ToolName = "docker-slim"
+
+var ErrUnknownFormat = errors.New("unknown SBOM format")
+
+// Formats returns the supported SBOM formats
+func Formats() []string {
+	return []string{FormatSPDXJSON, FormatCycloneDXJSON}
+}
+
+// IsFormat checks if the SBOM format is supported
+func IsFormat(format string) bool {
+	switch format {
+	case FormatSPDXJSON, FormatCycloneDXJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// FileName returns the SBOM document file name for the format
+func FileName(format string) string {
+	switch format {
+	case FormatSPDXJSON:
+		return FileNameSPDXJSON
+	case FormatCycloneDXJSON:
+		return FileNameCycloneDXJSON
+	default:
+		return ""
+	}
+}
+
+// Image describes the image the SBOM document is for
+type Image struct {
+	Name         string
+	ID           string
+	Digest       string
+	OS           string
+	Architecture string
+	Distro       string //distro name (used for the package URL namespaces)
+}
+
+// File is a filesystem object in the image
+type File struct {
+	Path     string
+	Size     int64
+	SHA1     string //empty if the file data is not hashed (the file is not included in the SPDX documents)
+	SHA256   string
+	DataType string
+	AppType  string
+}
+
+// Package is an OS package installed in the image
+type Package struct {
+	Name          string
+	Version       string
+	Arch          string
+	Manager       string
+	InstalledSize int64
+}
+
+// AppStack is a detected application stack (language runtime and its package directories)
+type AppStack struct {
+	Language    string
+	CodeFiles   uint
+	PackageDirs []string
+}
+
+// Document is the format independent SBOM data
+type Document struct {
+	Image       Image
+	Created     time.Time
+	ToolVersion string
+	Files       []*File
+	Packages    []*Package
+	AppStacks   []*AppStack
+}
+
+// New creates a new SBOM document for the image
+func New(image Image, toolVersion string) *Document {
+	return &Document{
+		Image:       image,
+		Created:     time.Now().UTC(),
+		ToolVersion: toolVersion,
+	}
+}
+
+// Marshal encodes the SBOM document using the selected format
+func (ref *Document) Marshal(format string) ([]byte, error) {
+	ref.sort()
+
+	var doc interface{}
+	switch format {
+	case FormatSPDXJSON:
+		doc = ref.spdx()
+	case FormatCycloneDXJSON:
+		doc = ref.cycloneDX()
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// Save saves the SBOM document using the selected format
+func (ref *Document) Save(format, path string) error {
+	data, err := ref.Marshal(format)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func (ref *Document) sort() {
+	sort.Slice(ref.Files, func(i, j int) bool {
+		return ref.Files[i].Path < ref.Files[j].Path
+	})
+
+	sort.Slice(ref.Packages, func(i, j int) bool {
+		if ref.Packages[i].Name != ref.Packages[j].Name {
+			return ref.Packages[i].Name < ref.Packages[j].Name
+		}
+
+		return ref.Packages[i].Arch < ref.Packages[j].Arch
+	})
+
+	sort.Slice(ref.AppStacks, func(i, j int) bool {
+		return ref.AppStacks[i].Language < ref.AppStacks[j].Language
+	})
+}
+
+func (ref *Document) imageName() string {
+	if ref.Image.Name != "" {
+		return ref.Image.Name
+	}
+
+	return ref.Image.ID
+}
+
+func (ref *Document) toolName() string {
+	if ref.ToolVersion == "" {
+		return ToolName
+	}
+
+	return fmt.Sprintf("%s-%s", ToolName, ref.ToolVersion)
+}
+
+// PackageURL returns the package URL (purl) for the OS package
+func PackageURL(pkg *Package, distro string) string {
+	var purlType string
+	switch pkg.Manager {
+	case "dpkg":
+		purlType = "deb"
+	case "apk":
+		purlType = "apk"
+	case "rpm":
+		purlType = "rpm"
+	default:
+		purlType = "generic"
+	}
+
+	version := pkg.Version
+	var qualifiers []string
+	if pkg.Arch != "" {
+		qualifiers = append(qualifiers, "arch="+url.QueryEscape(pkg.Arch))
+	}
+
+	if purlType == "rpm" {
+		//the rpm epoch is a qualifier in the package URLs
+		if parts := strings.SplitN(version, ":", 2); len(parts) == 2 {
+			version = parts[1]
+			qualifiers = append(qualifiers, "epoch="+url.QueryEscape(parts[0]))
+		}
+	}
+
+	var purl strings.Builder
+	purl.WriteString("pkg:")
+	purl.WriteString(purlType)
+	purl.WriteString("/")
+	if namespace := purlNamespace(distro); namespace != "" {
+		purl.WriteString(namespace)
+		purl.WriteString("/")
+	}
+
+	purl.WriteString(url.PathEscape(pkg.Name))
+	if version != "" {
+		purl.WriteString("@")
+		purl.WriteString(url.PathEscape(version))
+	}
+
+	if len(qualifiers) > 0 {
+		purl.WriteString("?")
+		purl.WriteString(strings.Join(qualifiers, "&"))
+	}
+
+	return purl.String()
+}
+
+func purlNamespace(distro string) string {
+	name := strings.ToLower(distro)
+	switch {
+	case name == "":
+		return ""
+	case strings.Contains(name, "red hat"):
+		return "redhat"
+	case strings.Contains(name, "suse"):
+		return "opensuse"
+	}
+
+	return strings.Fields(name)[0]
+}
+
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40 //version 4
+	b[8] = (b[8] & 0x3f) | 0x80 //variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,123 @@
+package sbom
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+var uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}`)
+
+func testDocument() *Document {
+	doc := New(Image{
+		Name:         "app:latest",
+		ID:           "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		Digest:       "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+		OS:           "linux",
+		Architecture: "amd64",
+		Distro:       "Debian GNU/Linux",
+	}, "1.0.0")
+
+	doc.Created = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	doc.Files = []*File{
+		{Path: "/usr/bin/app", Size: 1024, SHA1: "da39a3ee5e6b4b0d3255bfef95601890afd80709", DataType: "ELF 64-bit LSB executable"},
+		{Path: "/app/main.py", Size: 64, SHA1: "0a4d55a8d778e5022fab701977c5d840bbc486d0", DataType: "Python script, ASCII text executable", AppType: "python"},
+		//no SHA1 (not in the SPDX file section)
+		{Path: "/etc/unreadable", Size: 10},
+	}
+
+	doc.Packages = []*Package{
+		{Name: "libc6", Version: "2.31-13", Arch: "amd64", Manager: "dpkg", InstalledSize: 12345 * 1024},
+		{Name: "bash", Version: "5.1-2", Arch: "amd64", Manager: "dpkg"},
+		//duplicate package (one CycloneDX component)
+		{Name: "bash", Version: "5.1-2", Arch: "amd64", Manager: "dpkg"},
+	}
+
+	doc.AppStacks = []*AppStack{
+		{Language: "python", CodeFiles: 1, PackageDirs: []string{"/usr/lib/python3/dist-packages/"}},
+	}
+
+	return doc
+}
+
+func TestMarshal(t *testing.T) {
+	for _, format := range Formats() {
+		out, err := testDocument().Marshal(format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		//the serial numbers and the document namespaces are random
+		out = uuidPattern.ReplaceAll(out, []byte("00000000-0000-4000-8000-000000000000"))
+
+		goldenPath := filepath.Join("testdata", "golden."+FileName(format))
+		if *updateGolden {
+			if err := ioutil.WriteFile(goldenPath, out, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		expected, err := ioutil.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(out, expected) {
+			t.Errorf("%s output does not match %s (run the test with -update to update it):\n%s", format, goldenPath, out)
+		}
+	}
+
+	if _, err := testDocument().Marshal("spdx-tag-value"); err != ErrUnknownFormat {
+		t.Errorf("unexpected error for an unknown format - %v", err)
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	tt := []struct {
+		pkg      Package
+		distro   string
+		expected string
+	}{
+		{
+			pkg:      Package{Name: "libc6", Version: "2.31-13+deb11u5", Arch: "amd64", Manager: "dpkg"},
+			distro:   "Debian GNU/Linux",
+			expected: "pkg:deb/debian/libc6@2.31-13+deb11u5?arch=amd64",
+		},
+		{
+			pkg:      Package{Name: "musl", Version: "1.2.2-r7", Arch: "x86_64", Manager: "apk"},
+			distro:   "Alpine Linux",
+			expected: "pkg:apk/alpine/musl@1.2.2-r7?arch=x86_64",
+		},
+		{
+			pkg:      Package{Name: "openssl-libs", Version: "1:1.1.1k-6.el8", Arch: "x86_64", Manager: "rpm"},
+			distro:   "Red Hat Enterprise Linux",
+			expected: "pkg:rpm/redhat/openssl-libs@1.1.1k-6.el8?arch=x86_64&epoch=1",
+		},
+		{
+			pkg:      Package{Name: "glibc", Version: "2.31-7.fc32", Manager: "rpm"},
+			distro:   "openSUSE Leap",
+			expected: "pkg:rpm/opensuse/glibc@2.31-7.fc32",
+		},
+		{
+			//the dpkg epoch is a part of the version
+			pkg:      Package{Name: "zlib1g", Version: "1:1.2.11.dfsg-2", Manager: "dpkg"},
+			expected: "pkg:deb/zlib1g@1:1.2.11.dfsg-2",
+		},
+		{
+			pkg:      Package{Name: "lib c", Manager: "other"},
+			expected: "pkg:generic/lib%20c",
+		},
+	}
+
+	for _, test := range tt {
+		if got := PackageURL(&test.pkg, test.distro); got != test.expected {
+			t.Errorf("%+v: got %q expected %q", test.pkg, got, test.expected)
+		}
+	}
+}
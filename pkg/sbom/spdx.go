@@ -0,0 +1,230 @@
+package sbom
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	spdxVersion     = "SPDX-2.3"
+	spdxDataLicense = "CC0-1.0"
+	spdxNoAssertion = "NOASSERTION"
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+	spdxImageID     = "SPDXRef-Image"
+	spdxNamespace   = "https://github.com/docker-slim/docker-slim/spdx"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string              `json:"spdxVersion"`
+	DataLicense       string              `json:"dataLicense"`
+	SPDXID            string              `json:"SPDXID"`
+	Name              string              `json:"name"`
+	DocumentNamespace string              `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo    `json:"creationInfo"`
+	Packages          []*spdxPackage      `json:"packages"`
+	Files             []*spdxFile         `json:"files,omitempty"`
+	Relationships     []*spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxPackage struct {
+	SPDXID                string             `json:"SPDXID"`
+	Name                  string             `json:"name"`
+	VersionInfo           string             `json:"versionInfo,omitempty"`
+	DownloadLocation      string             `json:"downloadLocation"`
+	FilesAnalyzed         bool               `json:"filesAnalyzed"`
+	LicenseConcluded      string             `json:"licenseConcluded"`
+	LicenseDeclared       string             `json:"licenseDeclared"`
+	CopyrightText         string             `json:"copyrightText"`
+	PrimaryPackagePurpose string             `json:"primaryPackagePurpose,omitempty"`
+	Checksums             []*spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []*spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment               string             `json:"comment,omitempty"`
+}
+
+type spdxFile struct {
+	SPDXID             string          `json:"SPDXID"`
+	FileName           string          `json:"fileName"`
+	FileTypes          []string        `json:"fileTypes,omitempty"`
+	Checksums          []*spdxChecksum `json:"checksums"`
+	LicenseConcluded   string          `json:"licenseConcluded"`
+	LicenseInfoInFiles []string        `json:"licenseInfoInFiles,omitempty"`
+	CopyrightText      string          `json:"copyrightText"`
+	Comment            string          `json:"comment,omitempty"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+func spdxID(prefix string, idx int, name string) string {
+	return fmt.Sprintf("SPDXRef-%s-%d-%s", prefix, idx, spdxIDInvalidChars.ReplaceAllString(name, "-"))
+}
+
+func (ref *Document) spdx() *spdxDocument {
+	name := ref.imageName()
+	doc := &spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       spdxDataLicense,
+		SPDXID:            spdxDocumentID,
+		Name:              name,
+		DocumentNamespace: fmt.Sprintf("%s/%s-%s", spdxNamespace, spdxIDInvalidChars.ReplaceAllString(name, "-"), newUUID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  ref.Created.Format(time.RFC3339),
+			Creators: []string{fmt.Sprintf("Tool: %s", ref.toolName())},
+		},
+	}
+
+	image := &spdxPackage{
+		SPDXID:                spdxImageID,
+		Name:                  name,
+		VersionInfo:           ref.Image.Digest,
+		DownloadLocation:      spdxNoAssertion,
+		LicenseConcluded:      spdxNoAssertion,
+		LicenseDeclared:       spdxNoAssertion,
+		CopyrightText:         spdxNoAssertion,
+		PrimaryPackagePurpose: "CONTAINER",
+	}
+
+	if ref.Image.ID != "" {
+		image.Comment = fmt.Sprintf("image ID: %s", ref.Image.ID)
+	}
+
+	doc.Packages = append(doc.Packages, image)
+	doc.Relationships = append(doc.Relationships,
+		&spdxRelationship{
+			SPDXElementID:      spdxDocumentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: spdxImageID,
+		})
+
+	for idx, p := range ref.Packages {
+		id := spdxID("Package", idx, p.Name)
+		doc.Packages = append(doc.Packages,
+			&spdxPackage{
+				SPDXID:           id,
+				Name:             p.Name,
+				VersionInfo:      p.Version,
+				DownloadLocation: spdxNoAssertion,
+				LicenseConcluded: spdxNoAssertion,
+				LicenseDeclared:  spdxNoAssertion,
+				CopyrightText:    spdxNoAssertion,
+				ExternalRefs: []*spdxExternalRef{
+					{
+						ReferenceCategory: "PACKAGE-MANAGER",
+						ReferenceType:     "purl",
+						ReferenceLocator:  PackageURL(p, ref.Image.Distro),
+					},
+				},
+				Comment: fmt.Sprintf("package manager: %s", p.Manager),
+			})
+
+		doc.Relationships = append(doc.Relationships,
+			&spdxRelationship{
+				SPDXElementID:      spdxImageID,
+				RelationshipType:   "CONTAINS",
+				RelatedSPDXElement: id,
+			})
+	}
+
+	for idx, s := range ref.AppStacks {
+		id := spdxID("AppStack", idx, s.Language)
+		doc.Packages = append(doc.Packages,
+			&spdxPackage{
+				SPDXID:                id,
+				Name:                  s.Language,
+				DownloadLocation:      spdxNoAssertion,
+				LicenseConcluded:      spdxNoAssertion,
+				LicenseDeclared:       spdxNoAssertion,
+				CopyrightText:         spdxNoAssertion,
+				PrimaryPackagePurpose: "FRAMEWORK",
+				Comment:               fmt.Sprintf("application stack (code files: %d)", s.CodeFiles),
+			})
+
+		doc.Relationships = append(doc.Relationships,
+			&spdxRelationship{
+				SPDXElementID:      spdxImageID,
+				RelationshipType:   "CONTAINS",
+				RelatedSPDXElement: id,
+			})
+	}
+
+	for idx, f := range ref.Files {
+		if f.SHA1 == "" {
+			//SPDX requires the SHA1 checksum for every file
+			//(the unhashed files are only in the CycloneDX documents)
+			continue
+		}
+
+		id := spdxID("File", idx, f.Path)
+		file := &spdxFile{
+			SPDXID:           id,
+			FileName:         f.Path,
+			LicenseConcluded: spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			Checksums:        []*spdxChecksum{{Algorithm: "SHA1", ChecksumValue: f.SHA1}},
+		}
+
+		if f.SHA256 != "" {
+			file.Checksums = append(file.Checksums, &spdxChecksum{Algorithm: "SHA256", ChecksumValue: f.SHA256})
+		}
+
+		if fileType := spdxFileType(f); fileType != "" {
+			file.FileTypes = []string{fileType}
+		}
+
+		if f.DataType != "" {
+			file.Comment = f.DataType
+		}
+
+		doc.Files = append(doc.Files, file)
+		doc.Relationships = append(doc.Relationships,
+			&spdxRelationship{
+				SPDXElementID:      spdxImageID,
+				RelationshipType:   "CONTAINS",
+				RelatedSPDXElement: id,
+			})
+	}
+
+	return doc
+}
+
+func spdxFileType(f *File) string {
+	switch {
+	case f.AppType != "":
+		return "SOURCE"
+	case strings.Contains(f.DataType, "ELF"), strings.Contains(f.DataType, "executable"):
+		return "BINARY"
+	case strings.Contains(f.DataType, "text"):
+		return "TEXT"
+	case strings.Contains(f.DataType, "archive"), strings.Contains(f.DataType, "compressed"):
+		return "ARCHIVE"
+	case strings.Contains(f.DataType, "image data"):
+		return "IMAGE"
+	case f.DataType != "":
+		return "OTHER"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,260 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	DpkgStatusFile   = "/var/lib/dpkg/status"
+	DpkgStatusDir    = "/var/lib/dpkg/status.d/" //used by distroless images
+	DpkgInfoDir      = "/var/lib/dpkg/info/"
+	ApkInstalledFile = "/lib/apk/db/installed"
+)
+
+const (
+	OSPackageManagerDpkg = "dpkg"
+	OSPackageManagerApk  = "apk"
+)
+
+type OSPackage struct {
+	Name          string   `json:"name"`
+	Version       string   `json:"version"`
+	Arch          string   `json:"arch,omitempty"`
+	InstalledSize int64    `json:"installed_size,omitempty"` //bytes
+	Manager       string   `json:"manager"`
+	Files         []string `json:"-"` //package files (if available in the package database)
+}
+
+// OSPackageManagerForDB returns the package manager for the package database file
+// (returns an empty string if it's not a known package database file)
+func OSPackageManagerForDB(name string) string {
+	switch {
+	case name == DpkgStatusFile:
+		return OSPackageManagerDpkg
+	case strings.HasPrefix(name, DpkgStatusDir) && len(name) > len(DpkgStatusDir):
+		if strings.HasSuffix(name, ".md5sums") {
+			return ""
+		}
+
+		return OSPackageManagerDpkg
+	case name == ApkInstalledFile:
+		return OSPackageManagerApk
+	case isRpmDBFile(name):
+		return OSPackageManagerRpm
+	default:
+		return ""
+	}
+}
+
+// OSPackageDBFiles are the known package database files
+// (the distroless dpkg records are the files in DpkgStatusDir)
+var OSPackageDBFiles = []string{
+	DpkgStatusFile,
+	ApkInstalledFile,
+	RpmDBDir + RpmDBSQLiteFile,
+	RpmDBDir + RpmDBNativeFile,
+	RpmDBDir + RpmDBBerkeleyFile,
+	RpmDBSysImageDir + RpmDBSQLiteFile,
+	RpmDBSysImageDir + RpmDBNativeFile,
+	RpmDBSysImageDir + RpmDBBerkeleyFile,
+}
+
+func IsOSPackageDBFile(name string) bool {
+	return OSPackageManagerForDB(name) != ""
+}
+
+// NewOSPackagesFromData parses the package database data for the package database file
+func NewOSPackagesFromData(name string, raw []byte) ([]*OSPackage, error) {
+	switch OSPackageManagerForDB(name) {
+	case OSPackageManagerDpkg:
+		return ParseDpkgStatus(raw), nil
+	case OSPackageManagerApk:
+		return ParseApkInstalled(raw), nil
+	case OSPackageManagerRpm:
+		return ParseRpmDB(raw)
+	default:
+		return nil, fmt.Errorf("unknown package database - %s", name)
+	}
+}
+
+// ParseDpkgStatus parses the dpkg status database records
+// (only the installed packages are returned)
+func ParseDpkgStatus(raw []byte) []*OSPackage {
+	var packages []*OSPackage
+
+	var (
+		current   *OSPackage
+		installed bool
+	)
+
+	flush := func() {
+		if current != nil && current.Name != "" && installed {
+			packages = append(packages, current)
+		}
+
+		current = nil
+		installed = false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			//continuation line (multi-line field values)
+			continue
+		}
+
+		key, val, found := cutField(line, ":")
+		if !found {
+			continue
+		}
+
+		if current == nil {
+			current = &OSPackage{Manager: OSPackageManagerDpkg}
+			//the distroless package records don't have the status field
+			installed = true
+		}
+
+		switch key {
+		case "Package":
+			current.Name = val
+		case "Version":
+			current.Version = val
+		case "Architecture":
+			current.Arch = val
+		case "Installed-Size":
+			if size, err := strconv.ParseInt(val, 10, 64); err == nil {
+				current.InstalledSize = size * 1024
+			}
+		case "Status":
+			fields := strings.Fields(val)
+			installed = len(fields) > 0 && fields[len(fields)-1] == "installed"
+		}
+	}
+
+	flush()
+	return packages
+}
+
+// ParseApkInstalled parses the apk installed database records
+func ParseApkInstalled(raw []byte) []*OSPackage {
+	var packages []*OSPackage
+
+	var (
+		current *OSPackage
+		fileDir string
+	)
+
+	flush := func() {
+		if current != nil && current.Name != "" {
+			packages = append(packages, current)
+		}
+
+		current = nil
+		fileDir = ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		key, val, found := cutField(line, ":")
+		if !found {
+			continue
+		}
+
+		if current == nil {
+			current = &OSPackage{Manager: OSPackageManagerApk}
+		}
+
+		switch key {
+		case "P":
+			current.Name = val
+		case "V":
+			current.Version = val
+		case "A":
+			current.Arch = val
+		case "I":
+			if size, err := strconv.ParseInt(val, 10, 64); err == nil {
+				current.InstalledSize = size
+			}
+		case "F":
+			//the 'R' (file name) records are for the last 'F' (directory) record
+			fileDir = val
+		case "R":
+			current.Files = append(current.Files, fmt.Sprintf("/%s", strings.TrimPrefix(path.Join(fileDir, val), "/")))
+		}
+	}
+
+	flush()
+	return packages
+}
+
+// DpkgFileListPaths returns the possible dpkg file list paths for the package
+// (the multi-arch packages include the architecture in the file list name)
+func DpkgFileListPaths(pkg *OSPackage) []string {
+	var paths []string
+	if pkg.Arch != "" {
+		paths = append(paths, fmt.Sprintf("%s%s:%s.list", DpkgInfoDir, pkg.Name, pkg.Arch))
+	}
+
+	return append(paths, fmt.Sprintf("%s%s.list", DpkgInfoDir, pkg.Name))
+}
+
+// ParseDpkgFileList parses the dpkg package file list ('/var/lib/dpkg/info/NAME.list')
+func ParseDpkgFileList(raw []byte) []string {
+	var files []string
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line == "/." {
+			continue
+		}
+
+		files = append(files, line)
+	}
+
+	return files
+}
+
+// ParseDpkgMd5sums parses the file paths in the dpkg md5sums data
+// (used for the package file lists in the distroless 'status.d' package records)
+func ParseDpkgMd5sums(raw []byte) []string {
+	var files []string
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		files = append(files, fmt.Sprintf("/%s", strings.TrimPrefix(fields[1], "/")))
+	}
+
+	return files
+}
+
+func cutField(line, sep string) (string, string, bool) {
+	idx := strings.Index(line, sep)
+	if idx < 1 {
+		return "", "", false
+	}
+
+	return line[:idx], strings.TrimSpace(line[idx+len(sep):]), true
+}
@@ -0,0 +1,227 @@
+package system
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	RpmDBDir          = "/var/lib/rpm/"
+	RpmDBSysImageDir  = "/usr/lib/sysimage/rpm/"
+	RpmDBBerkeleyFile = "Packages"     //BerkeleyDB hash database (older RHEL/CentOS/Amazon Linux)
+	RpmDBSQLiteFile   = "rpmdb.sqlite" //SQLite database (Fedora 33+, RHEL 9+)
+	RpmDBNativeFile   = "Packages.db"  //NDB database (SUSE)
+)
+
+const OSPackageManagerRpm = "rpm"
+
+var (
+	ErrRpmDBUnknownFormat = errors.New("unknown rpm database format")
+	ErrRpmHeaderMalformed = errors.New("malformed rpm header")
+)
+
+func isRpmDBFile(name string) bool {
+	var dbName string
+	switch {
+	case strings.HasPrefix(name, RpmDBDir):
+		dbName = name[len(RpmDBDir):]
+	case strings.HasPrefix(name, RpmDBSysImageDir):
+		dbName = name[len(RpmDBSysImageDir):]
+	default:
+		return false
+	}
+
+	switch dbName {
+	case RpmDBBerkeleyFile, RpmDBSQLiteFile, RpmDBNativeFile:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseRpmDB parses the rpm package database (BerkeleyDB, SQLite or NDB)
+func ParseRpmDB(raw []byte) ([]*OSPackage, error) {
+	var (
+		blobs [][]byte
+		err   error
+	)
+
+	switch {
+	case isSQLiteData(raw):
+		blobs, err = rpmBlobsFromSQLite(raw)
+	case isNDBData(raw):
+		blobs, err = rpmBlobsFromNDB(raw)
+	case isBDBHashData(raw):
+		blobs, err = rpmBlobsFromBDB(raw)
+	default:
+		return nil, ErrRpmDBUnknownFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []*OSPackage
+	for _, blob := range blobs {
+		pkg, err := parseRpmHeaderBlob(blob)
+		if err != nil {
+			//skipping the records that are not package headers
+			continue
+		}
+
+		if pkg.Name == "gpg-pubkey" {
+			//the imported signing keys are also stored as package headers
+			continue
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// rpm header tags and types (from rpmtag.h)
+const (
+	rpmTagName       = 1000
+	rpmTagVersion    = 1001
+	rpmTagRelease    = 1002
+	rpmTagEpoch      = 1003
+	rpmTagSize       = 1009
+	rpmTagArch       = 1022
+	rpmTagDirIndexes = 1116
+	rpmTagBaseNames  = 1117
+	rpmTagDirNames   = 1118
+	rpmTagLongSize   = 5009
+	rpmTypeStringArr = 8
+	rpmTypeInt32     = 4
+	rpmTypeInt64     = 5
+	rpmTypeString    = 6
+	rpmTypeI18N      = 9
+	rpmIndexEntrySz  = 16
+)
+
+// parseRpmHeaderBlob parses the header blob (the header without the lead/magic)
+// stored in the rpm databases
+func parseRpmHeaderBlob(blob []byte) (*OSPackage, error) {
+	if len(blob) < 8 {
+		return nil, ErrRpmHeaderMalformed
+	}
+
+	indexCount := int(binary.BigEndian.Uint32(blob[0:4]))
+	dataLen := int(binary.BigEndian.Uint32(blob[4:8]))
+	dataStart := 8 + indexCount*rpmIndexEntrySz
+	if indexCount <= 0 || dataLen < 0 || dataStart+dataLen > len(blob) {
+		return nil, ErrRpmHeaderMalformed
+	}
+
+	data := blob[dataStart : dataStart+dataLen]
+
+	var (
+		release    string
+		epoch      = -1
+		pkg        = &OSPackage{Manager: OSPackageManagerRpm}
+		dirIndexes []int
+		baseNames  []string
+		dirNames   []string
+	)
+
+	for i := 0; i < indexCount; i++ {
+		entry := blob[8+i*rpmIndexEntrySz : 8+(i+1)*rpmIndexEntrySz]
+		tag := binary.BigEndian.Uint32(entry[0:4])
+		dataType := binary.BigEndian.Uint32(entry[4:8])
+		offset := int(int32(binary.BigEndian.Uint32(entry[8:12])))
+		count := int(binary.BigEndian.Uint32(entry[12:16]))
+		if offset < 0 || offset >= len(data) {
+			continue
+		}
+
+		switch tag {
+		case rpmTagName, rpmTagVersion, rpmTagRelease, rpmTagArch:
+			if dataType != rpmTypeString && dataType != rpmTypeI18N {
+				continue
+			}
+
+			val := data[offset:]
+			if end := bytes.IndexByte(val, 0); end >= 0 {
+				val = val[:end]
+			}
+
+			switch tag {
+			case rpmTagName:
+				pkg.Name = string(val)
+			case rpmTagVersion:
+				pkg.Version = string(val)
+			case rpmTagRelease:
+				release = string(val)
+			case rpmTagArch:
+				pkg.Arch = string(val)
+			}
+		case rpmTagEpoch:
+			if dataType == rpmTypeInt32 && offset+4 <= len(data) {
+				epoch = int(binary.BigEndian.Uint32(data[offset:]))
+			}
+		case rpmTagSize:
+			if dataType == rpmTypeInt32 && offset+4 <= len(data) && pkg.InstalledSize == 0 {
+				pkg.InstalledSize = int64(binary.BigEndian.Uint32(data[offset:]))
+			}
+		case rpmTagLongSize:
+			if dataType == rpmTypeInt64 && offset+8 <= len(data) {
+				pkg.InstalledSize = int64(binary.BigEndian.Uint64(data[offset:]))
+			}
+		case rpmTagDirIndexes:
+			if dataType == rpmTypeInt32 && count > 0 && offset+count*4 <= len(data) {
+				for j := 0; j < count; j++ {
+					dirIndexes = append(dirIndexes, int(binary.BigEndian.Uint32(data[offset+j*4:])))
+				}
+			}
+		case rpmTagBaseNames:
+			if dataType == rpmTypeStringArr {
+				baseNames = rpmStringArray(data[offset:], count)
+			}
+		case rpmTagDirNames:
+			if dataType == rpmTypeStringArr {
+				dirNames = rpmStringArray(data[offset:], count)
+			}
+		}
+	}
+
+	if len(baseNames) == len(dirIndexes) {
+		for j, baseName := range baseNames {
+			if dirIndexes[j] >= 0 && dirIndexes[j] < len(dirNames) {
+				pkg.Files = append(pkg.Files, dirNames[dirIndexes[j]]+baseName)
+			}
+		}
+	}
+
+	if pkg.Name == "" {
+		return nil, ErrRpmHeaderMalformed
+	}
+
+	if release != "" {
+		pkg.Version = fmt.Sprintf("%s-%s", pkg.Version, release)
+	}
+
+	if epoch > 0 {
+		pkg.Version = fmt.Sprintf("%d:%s", epoch, pkg.Version)
+	}
+
+	return pkg, nil
+}
+
+func rpmStringArray(data []byte, count int) []string {
+	var values []string
+	for i := 0; i < count && len(data) > 0; i++ {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			break
+		}
+
+		values = append(values, string(data[:end]))
+		data = data[end+1:]
+	}
+
+	return values
+}
@@ -0,0 +1,241 @@
+package system
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestOSPackageDBFiles(t *testing.T) {
+	for _, name := range OSPackageDBFiles {
+		if !IsOSPackageDBFile(name) {
+			t.Errorf("%s is not detected as a package database file", name)
+		}
+	}
+}
+
+func TestOSPackageManagerForDB(t *testing.T) {
+	tt := []struct {
+		name     string
+		expected string
+	}{
+		{name: DpkgStatusFile, expected: OSPackageManagerDpkg},
+		{name: DpkgStatusDir + "base-files", expected: OSPackageManagerDpkg},
+		{name: DpkgStatusDir + "base-files.md5sums"},
+		{name: DpkgStatusDir},
+		{name: ApkInstalledFile, expected: OSPackageManagerApk},
+		{name: "/var/lib/dpkg/status-old"},
+		{name: "/etc/hosts"},
+	}
+
+	for _, test := range tt {
+		if got := OSPackageManagerForDB(test.name); got != test.expected {
+			t.Errorf("%s: got %q expected %q", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestParseDpkgStatus(t *testing.T) {
+	tt := []struct {
+		desc     string
+		raw      string
+		expected []*OSPackage
+	}{
+		{
+			desc: "installed",
+			raw: `Package: libc6
+Status: install ok installed
+Priority: optional
+Installed-Size: 12345
+Architecture: amd64
+Version: 2.31-13+deb11u5
+Description: GNU C Library: Shared libraries
+ Contains the standard libraries that are used by nearly all programs on
+ the system.
+ .
+ Package: not-a-package
+
+Package: bash
+Status: install ok installed
+Architecture: amd64
+Version: 5.1-2+deb11u1
+Installed-Size: 6470
+`,
+			expected: []*OSPackage{
+				{Name: "libc6", Version: "2.31-13+deb11u5", Arch: "amd64", InstalledSize: 12345 * 1024, Manager: OSPackageManagerDpkg},
+				{Name: "bash", Version: "5.1-2+deb11u1", Arch: "amd64", InstalledSize: 6470 * 1024, Manager: OSPackageManagerDpkg},
+			},
+		},
+		{
+			desc: "removed and config-files",
+			raw: `Package: vim
+Status: deinstall ok config-files
+Version: 2:8.2.2434-3
+Architecture: amd64
+
+Package: nano
+Status: deinstall ok not-installed
+Version: 5.4-2
+
+Package: curl
+Status: install ok half-configured
+Version: 7.74.0-1.3
+
+Package: zlib1g
+Status: install ok installed
+Version: 1:1.2.11.dfsg-2
+`,
+			expected: []*OSPackage{
+				{Name: "zlib1g", Version: "1:1.2.11.dfsg-2", Manager: OSPackageManagerDpkg},
+			},
+		},
+		{
+			//the distroless 'status.d' records don't have the status field
+			desc: "distroless record",
+			raw: `Package: tzdata
+Version: 2021a-1+deb11u8
+Architecture: all
+Maintainer: GNU Libc Maintainers <debian-glibc@lists.debian.org>
+Installed-Size: 3393
+`,
+			expected: []*OSPackage{
+				{Name: "tzdata", Version: "2021a-1+deb11u8", Arch: "all", InstalledSize: 3393 * 1024, Manager: OSPackageManagerDpkg},
+			},
+		},
+		{
+			desc: "malformed",
+			raw: `no separator here
+Version: 1.0
+Installed-Size: large
+
+Package: ok
+Installed-Size: -
+:empty key
+`,
+			expected: []*OSPackage{
+				{Name: "ok", Manager: OSPackageManagerDpkg},
+			},
+		},
+		{desc: "empty"},
+	}
+
+	for _, test := range tt {
+		if got := ParseDpkgStatus([]byte(test.raw)); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s:\ngot      %s\nexpected %s", test.desc, packagesString(got), packagesString(test.expected))
+		}
+	}
+}
+
+func TestParseApkInstalled(t *testing.T) {
+	tt := []struct {
+		desc     string
+		raw      string
+		expected []*OSPackage
+	}{
+		{
+			desc: "packages",
+			raw: `C:Q1Z2B8+Sl3R7obA0MKxcM6JWrqX0A=
+P:musl
+V:1.2.2-r7
+A:x86_64
+S:383152
+I:622592
+T:the musl c library (libc) implementation
+F:lib
+R:ld-musl-x86_64.so.1
+a:0:0:755
+Z:Q1Wm7Yp1fzYz5eRm0rNSWzr2nkDw0=
+R:libc.musl-x86_64.so.1
+F:usr/lib
+
+P:busybox
+V:1.34.1-r3
+A:x86_64
+I:946176
+R:root-file
+F:bin
+R:busybox
+F:etc/securetty.d
+R:busybox
+`,
+			expected: []*OSPackage{
+				{
+					Name:          "musl",
+					Version:       "1.2.2-r7",
+					Arch:          "x86_64",
+					InstalledSize: 622592,
+					Manager:       OSPackageManagerApk,
+					Files:         []string{"/lib/ld-musl-x86_64.so.1", "/lib/libc.musl-x86_64.so.1"},
+				},
+				{
+					Name:          "busybox",
+					Version:       "1.34.1-r3",
+					Arch:          "x86_64",
+					InstalledSize: 946176,
+					Manager:       OSPackageManagerApk,
+					Files:         []string{"/root-file", "/bin/busybox", "/etc/securetty.d/busybox"},
+				},
+			},
+		},
+		{
+			desc: "no package name",
+			raw: `V:1.0
+F:lib
+R:libx.so
+`,
+		},
+	}
+
+	for _, test := range tt {
+		if got := ParseApkInstalled([]byte(test.raw)); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s:\ngot      %s\nexpected %s", test.desc, packagesString(got), packagesString(test.expected))
+		}
+	}
+}
+
+func TestParseDpkgFileList(t *testing.T) {
+	raw := `/.
+/bin
+/bin/bash
+
+/usr/share/doc/bash/copyright
+`
+
+	expected := []string{"/bin", "/bin/bash", "/usr/share/doc/bash/copyright"}
+	if got := ParseDpkgFileList([]byte(raw)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q expected %q", got, expected)
+	}
+}
+
+func TestParseDpkgMd5sums(t *testing.T) {
+	raw := `6e9f9a6d1a0ebd4e3d2e5b3b9aaff32e  usr/share/zoneinfo/UTC
+0123456789abcdef0123456789abcdef  /etc/timezone
+malformed-line
+`
+
+	expected := []string{"/usr/share/zoneinfo/UTC", "/etc/timezone"}
+	if got := ParseDpkgMd5sums([]byte(raw)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q expected %q", got, expected)
+	}
+}
+
+func TestDpkgFileListPaths(t *testing.T) {
+	expected := []string{"/var/lib/dpkg/info/libc6:amd64.list", "/var/lib/dpkg/info/libc6.list"}
+	if got := DpkgFileListPaths(&OSPackage{Name: "libc6", Arch: "amd64"}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q expected %q", got, expected)
+	}
+
+	expected = []string{"/var/lib/dpkg/info/bash.list"}
+	if got := DpkgFileListPaths(&OSPackage{Name: "bash"}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q expected %q", got, expected)
+	}
+}
+
+func packagesString(packages []*OSPackage) string {
+	var out string
+	for _, pkg := range packages {
+		out += fmt.Sprintf("%+v ", *pkg)
+	}
+
+	return out
+}
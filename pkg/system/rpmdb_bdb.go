@@ -0,0 +1,161 @@
+package system
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+//NOTE:
+//Minimal read-only BerkeleyDB hash database reader for the rpm 'Packages' database.
+//The page layouts are from the BerkeleyDB 'dbinc/db_page.h' header.
+
+const (
+	bdbHashMagic        = 0x061561
+	bdbMetaHashType     = 8  //P_HASHMETA
+	bdbPageHashUnsorted = 2  //P_HASH_UNSORTED
+	bdbPageOverflow     = 7  //P_OVERFLOW
+	bdbPageHash         = 13 //P_HASH
+	bdbPageHeaderSize   = 26
+	bdbItemKeyData      = 1 //H_KEYDATA
+	bdbItemOffPage      = 3 //H_OFFPAGE
+)
+
+var ErrBDBMalformed = errors.New("malformed berkeleydb hash database")
+
+func bdbByteOrder(raw []byte) (binary.ByteOrder, bool) {
+	if len(raw) < 72 {
+		return nil, false
+	}
+
+	if binary.LittleEndian.Uint32(raw[12:16]) == bdbHashMagic {
+		return binary.LittleEndian, true
+	}
+
+	if binary.BigEndian.Uint32(raw[12:16]) == bdbHashMagic {
+		return binary.BigEndian, true
+	}
+
+	return nil, false
+}
+
+func isBDBHashData(raw []byte) bool {
+	_, ok := bdbByteOrder(raw)
+	return ok
+}
+
+// rpmBlobsFromBDB returns the values stored in the hash database pages
+func rpmBlobsFromBDB(raw []byte) ([][]byte, error) {
+	order, ok := bdbByteOrder(raw)
+	if !ok || raw[25] != bdbMetaHashType {
+		return nil, ErrBDBMalformed
+	}
+
+	pageSize := int(order.Uint32(raw[20:24]))
+	lastPage := int(order.Uint32(raw[32:36]))
+	if pageSize < 512 || pageSize > 65536 {
+		return nil, ErrBDBMalformed
+	}
+
+	page := func(pgno int) []byte {
+		start := pgno * pageSize
+		if pgno < 0 || start+pageSize > len(raw) {
+			return nil
+		}
+
+		return raw[start : start+pageSize]
+	}
+
+	var blobs [][]byte
+	for pgno := 1; pgno <= lastPage; pgno++ {
+		data := page(pgno)
+		if data == nil {
+			break
+		}
+
+		pageType := data[25]
+		if pageType != bdbPageHash && pageType != bdbPageHashUnsorted {
+			continue
+		}
+
+		entries := int(order.Uint16(data[20:22]))
+		if bdbPageHeaderSize+entries*2 > pageSize {
+			return nil, ErrBDBMalformed
+		}
+
+		offsets := make([]int, entries)
+		for i := range offsets {
+			offsets[i] = int(order.Uint16(data[bdbPageHeaderSize+i*2:]))
+		}
+
+		//the items are key/value pairs (the values have the odd indexes)
+		for i := 1; i < entries; i += 2 {
+			offset := offsets[i]
+			if offset <= 0 || offset >= pageSize {
+				return nil, ErrBDBMalformed
+			}
+
+			switch data[offset] {
+			case bdbItemOffPage:
+				//type(1) + unused(3) + pgno(4) + tlen(4)
+				if offset+12 > pageSize {
+					return nil, ErrBDBMalformed
+				}
+
+				valuePage := int(order.Uint32(data[offset+4:]))
+				valueLen := int(order.Uint32(data[offset+8:]))
+				if valueLen > len(raw) {
+					return nil, ErrBDBMalformed
+				}
+
+				value, err := bdbOverflowValue(page, order, valuePage, valueLen)
+				if err != nil {
+					return nil, err
+				}
+
+				blobs = append(blobs, value)
+			case bdbItemKeyData:
+				//the items are stored from the end of the page
+				//(the value data ends where its key data starts)
+				end := offsets[i-1]
+				if end <= offset+1 || end > pageSize {
+					return nil, ErrBDBMalformed
+				}
+
+				blobs = append(blobs, data[offset+1:end])
+			}
+		}
+	}
+
+	return blobs, nil
+}
+
+func bdbOverflowValue(page func(int) []byte, order binary.ByteOrder, pgno, length int) ([]byte, error) {
+	value := make([]byte, 0, length)
+	visited := map[int]struct{}{}
+	for pgno != 0 {
+		if _, found := visited[pgno]; found {
+			return nil, ErrBDBMalformed
+		}
+		visited[pgno] = struct{}{}
+
+		data := page(pgno)
+		if data == nil || data[25] != bdbPageOverflow {
+			return nil, ErrBDBMalformed
+		}
+
+		//the 'hf_offset' field has the data length for the overflow pages
+		dataLen := int(order.Uint16(data[22:24]))
+		if bdbPageHeaderSize+dataLen > len(data) {
+			return nil, ErrBDBMalformed
+		}
+
+		value = append(value, data[bdbPageHeaderSize:bdbPageHeaderSize+dataLen]...)
+		pgno = int(order.Uint32(data[16:20]))
+	}
+
+	if len(value) != length {
+		return nil, ErrBDBMalformed
+	}
+
+	return value, nil
+}
@@ -0,0 +1,78 @@
+package system
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+//NOTE:
+//Minimal read-only reader for the rpm NDB database ('Packages.db').
+//The layouts are from the rpm 'lib/backend/ndb/rpmpkg.c' source.
+
+const (
+	ndbHeaderMagic      = 'R' | 'p'<<8 | 'm'<<16 | 'P'<<24
+	ndbSlotMagic        = 'S' | 'l'<<8 | 'o'<<16 | 't'<<24
+	ndbBlobMagic        = 'B' | 'l'<<8 | 'b'<<16 | 'S'<<24
+	ndbVersion          = 0
+	ndbPageSize         = 4096
+	ndbBlockSize        = 16
+	ndbSlotSize         = 16
+	ndbHeaderSize       = 32
+	ndbBlobHeaderSize   = 16
+	ndbSlotEntriesStart = ndbHeaderSize / ndbSlotSize
+)
+
+var ErrNDBMalformed = errors.New("malformed rpm ndb database")
+
+func isNDBData(raw []byte) bool {
+	return len(raw) >= ndbHeaderSize && binary.LittleEndian.Uint32(raw[0:4]) == ndbHeaderMagic
+}
+
+// rpmBlobsFromNDB returns the header blobs referenced by the database slots
+func rpmBlobsFromNDB(raw []byte) ([][]byte, error) {
+	if !isNDBData(raw) || binary.LittleEndian.Uint32(raw[4:8]) != ndbVersion {
+		return nil, ErrNDBMalformed
+	}
+
+	slotPages := int(binary.LittleEndian.Uint32(raw[12:16]))
+	slotsEnd := slotPages * ndbPageSize
+	if slotPages == 0 || slotsEnd > len(raw) {
+		return nil, ErrNDBMalformed
+	}
+
+	var blobs [][]byte
+	for pos := ndbSlotEntriesStart * ndbSlotSize; pos+ndbSlotSize <= slotsEnd; pos += ndbSlotSize {
+		slot := raw[pos : pos+ndbSlotSize]
+		if binary.LittleEndian.Uint32(slot[0:4]) != ndbSlotMagic {
+			return nil, ErrNDBMalformed
+		}
+
+		pkgIndex := binary.LittleEndian.Uint32(slot[4:8])
+		if pkgIndex == 0 {
+			//free slot
+			continue
+		}
+
+		blkOffset := int(binary.LittleEndian.Uint32(slot[8:12]))
+		blobStart := blkOffset * ndbBlockSize
+		if blobStart+ndbBlobHeaderSize > len(raw) {
+			return nil, ErrNDBMalformed
+		}
+
+		blobHeader := raw[blobStart : blobStart+ndbBlobHeaderSize]
+		if binary.LittleEndian.Uint32(blobHeader[0:4]) != ndbBlobMagic ||
+			binary.LittleEndian.Uint32(blobHeader[4:8]) != pkgIndex {
+			return nil, ErrNDBMalformed
+		}
+
+		blobLen := int(binary.LittleEndian.Uint32(blobHeader[12:16]))
+		dataStart := blobStart + ndbBlobHeaderSize
+		if dataStart+blobLen > len(raw) {
+			return nil, ErrNDBMalformed
+		}
+
+		blobs = append(blobs, raw[dataStart:dataStart+blobLen])
+	}
+
+	return blobs, nil
+}
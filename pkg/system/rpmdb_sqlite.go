@@ -0,0 +1,361 @@
+package system
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+//NOTE:
+//Minimal read-only SQLite database reader for the rpm 'rpmdb.sqlite' database.
+//It reads the table b-tree pages for the 'Packages' table directly
+//(see https://www.sqlite.org/fileformat.html).
+//The changes that are not checkpointed from the WAL file are not visible.
+
+const (
+	sqliteHeader         = "SQLite format 3\x00"
+	sqliteHeaderSize     = 100
+	sqlitePageLeafTable  = 0x0d
+	sqlitePageInnerTable = 0x05
+	rpmSQLiteTableName   = "Packages"
+)
+
+var ErrSQLiteMalformed = errors.New("malformed sqlite database")
+
+func isSQLiteData(raw []byte) bool {
+	return len(raw) >= sqliteHeaderSize && bytes.HasPrefix(raw, []byte(sqliteHeader))
+}
+
+type sqliteDB struct {
+	raw        []byte
+	pageSize   int
+	usableSize int
+}
+
+// rpmBlobsFromSQLite returns the header blobs from the 'Packages' table
+func rpmBlobsFromSQLite(raw []byte) ([][]byte, error) {
+	if !isSQLiteData(raw) {
+		return nil, ErrSQLiteMalformed
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(raw[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+
+	if pageSize < 512 {
+		return nil, ErrSQLiteMalformed
+	}
+
+	db := &sqliteDB{
+		raw:        raw,
+		pageSize:   pageSize,
+		usableSize: pageSize - int(raw[20]),
+	}
+
+	//the minimum usable page size (the page size without the reserved space) is 480
+	if db.usableSize < 480 {
+		return nil, ErrSQLiteMalformed
+	}
+
+	//the schema table (columns: type, name, tbl_name, rootpage, sql) is in page 1
+	rootPage := 0
+	err := db.walkTable(1, func(record []interface{}) error {
+		if len(record) < 4 {
+			return nil
+		}
+
+		recType, _ := record[0].(string)
+		recName, _ := record[1].(string)
+		if recType == "table" && strings.EqualFold(recName, rpmSQLiteTableName) {
+			if pgno, ok := record[3].(int64); ok {
+				rootPage = int(pgno)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rootPage == 0 {
+		return nil, ErrSQLiteMalformed
+	}
+
+	//the 'Packages' table columns: hnum (rowid alias), blob
+	var blobs [][]byte
+	err = db.walkTable(rootPage, func(record []interface{}) error {
+		if len(record) < 2 {
+			return nil
+		}
+
+		if blob, ok := record[1].([]byte); ok {
+			blobs = append(blobs, blob)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return blobs, nil
+}
+
+func (db *sqliteDB) page(pgno int) ([]byte, int, error) {
+	start := (pgno - 1) * db.pageSize
+	if pgno < 1 || start+db.pageSize > len(db.raw) {
+		return nil, 0, ErrSQLiteMalformed
+	}
+
+	//the b-tree page header on page 1 is after the database header
+	headerOffset := 0
+	if pgno == 1 {
+		headerOffset = sqliteHeaderSize
+	}
+
+	return db.raw[start : start+db.pageSize], headerOffset, nil
+}
+
+func (db *sqliteDB) walkTable(pgno int, handler func([]interface{}) error) error {
+	return db.walkTablePage(pgno, handler, map[int]struct{}{})
+}
+
+func (db *sqliteDB) walkTablePage(pgno int, handler func([]interface{}) error, visited map[int]struct{}) error {
+	if _, found := visited[pgno]; found {
+		return ErrSQLiteMalformed
+	}
+	visited[pgno] = struct{}{}
+
+	data, hoff, err := db.page(pgno)
+	if err != nil {
+		return err
+	}
+
+	pageType := data[hoff]
+	cellCount := int(binary.BigEndian.Uint16(data[hoff+3:]))
+
+	var cellsOffset int
+	switch pageType {
+	case sqlitePageLeafTable:
+		cellsOffset = hoff + 8
+	case sqlitePageInnerTable:
+		cellsOffset = hoff + 12
+	default:
+		return ErrSQLiteMalformed
+	}
+
+	if cellsOffset+cellCount*2 > len(data) {
+		return ErrSQLiteMalformed
+	}
+
+	for i := 0; i < cellCount; i++ {
+		cell := int(binary.BigEndian.Uint16(data[cellsOffset+i*2:]))
+		if cell >= len(data) {
+			return ErrSQLiteMalformed
+		}
+
+		if pageType == sqlitePageInnerTable {
+			if cell+4 > len(data) {
+				return ErrSQLiteMalformed
+			}
+
+			child := int(binary.BigEndian.Uint32(data[cell:]))
+			if err := db.walkTablePage(child, handler, visited); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		payload, err := db.cellPayload(data, cell)
+		if err != nil {
+			return err
+		}
+
+		record, err := parseSQLiteRecord(payload)
+		if err != nil {
+			return err
+		}
+
+		if err := handler(record); err != nil {
+			return err
+		}
+	}
+
+	if pageType == sqlitePageInnerTable {
+		rightChild := int(binary.BigEndian.Uint32(data[hoff+8:]))
+		return db.walkTablePage(rightChild, handler, visited)
+	}
+
+	return nil
+}
+
+// cellPayload returns the full payload for a table leaf cell (including the overflow pages)
+func (db *sqliteDB) cellPayload(data []byte, cell int) ([]byte, error) {
+	payloadSize, n := sqliteVarint(data[cell:])
+	if n == 0 {
+		return nil, ErrSQLiteMalformed
+	}
+	cell += n
+
+	//rowid
+	_, n = sqliteVarint(data[cell:])
+	if n == 0 {
+		return nil, ErrSQLiteMalformed
+	}
+	cell += n
+
+	//the payload can't be bigger than the database
+	if payloadSize > uint64(len(db.raw)) {
+		return nil, ErrSQLiteMalformed
+	}
+
+	size := int(payloadSize)
+	maxLocal := db.usableSize - 35
+	localSize := size
+	if size > maxLocal {
+		minLocal := ((db.usableSize-12)*32)/255 - 23
+		localSize = minLocal + (size-minLocal)%(db.usableSize-4)
+		if localSize > maxLocal {
+			localSize = minLocal
+		}
+	}
+
+	if cell+localSize > len(data) {
+		return nil, ErrSQLiteMalformed
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, data[cell:cell+localSize]...)
+	if localSize == size {
+		return payload, nil
+	}
+
+	if cell+localSize+4 > len(data) {
+		return nil, ErrSQLiteMalformed
+	}
+
+	overflow := int(binary.BigEndian.Uint32(data[cell+localSize:]))
+	visited := map[int]struct{}{}
+	for overflow != 0 && len(payload) < size {
+		if _, found := visited[overflow]; found {
+			return nil, ErrSQLiteMalformed
+		}
+		visited[overflow] = struct{}{}
+
+		page, _, err := db.page(overflow)
+		if err != nil {
+			return nil, err
+		}
+
+		chunk := size - len(payload)
+		if chunk > db.usableSize-4 {
+			chunk = db.usableSize - 4
+		}
+
+		payload = append(payload, page[4:4+chunk]...)
+		overflow = int(binary.BigEndian.Uint32(page[0:4]))
+	}
+
+	if len(payload) != size {
+		return nil, ErrSQLiteMalformed
+	}
+
+	return payload, nil
+}
+
+// parseSQLiteRecord decodes the record values (int64, float placeholder, string or []byte)
+func parseSQLiteRecord(payload []byte) ([]interface{}, error) {
+	headerSize, n := sqliteVarint(payload)
+	if n == 0 || headerSize > uint64(len(payload)) {
+		return nil, ErrSQLiteMalformed
+	}
+
+	var serialTypes []int64
+	for pos := n; pos < int(headerSize); {
+		st, n := sqliteVarint(payload[pos:])
+		if n == 0 {
+			return nil, ErrSQLiteMalformed
+		}
+
+		serialTypes = append(serialTypes, int64(st))
+		pos += n
+	}
+
+	var values []interface{}
+	pos := int(headerSize)
+	for _, st := range serialTypes {
+		var size int
+		switch {
+		case st == 0, st == 8, st == 9:
+			size = 0
+		case st >= 1 && st <= 4:
+			size = int(st)
+		case st == 5:
+			size = 6
+		case st == 6, st == 7:
+			size = 8
+		case st >= 12:
+			size = int((st - 12) / 2)
+		default:
+			return nil, ErrSQLiteMalformed
+		}
+
+		if pos+size > len(payload) {
+			return nil, ErrSQLiteMalformed
+		}
+
+		val := payload[pos : pos+size]
+		pos += size
+
+		switch {
+		case st == 0:
+			values = append(values, nil)
+		case st == 8:
+			values = append(values, int64(0))
+		case st == 9:
+			values = append(values, int64(1))
+		case st >= 1 && st <= 6:
+			//big-endian two's complement integers
+			var iv int64
+			if val[0]&0x80 != 0 {
+				iv = -1
+			}
+
+			for _, b := range val {
+				iv = iv<<8 | int64(b)
+			}
+
+			values = append(values, iv)
+		case st == 7:
+			values = append(values, val)
+		case st%2 == 0:
+			values = append(values, val)
+		default:
+			values = append(values, string(val))
+		}
+	}
+
+	return values, nil
+}
+
+func sqliteVarint(data []byte) (uint64, int) {
+	var val uint64
+	for i := 0; i < 9 && i < len(data); i++ {
+		if i == 8 {
+			return val<<8 | uint64(data[i]), 9
+		}
+
+		val = val<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return val, i + 1
+		}
+	}
+
+	return 0, 0
+}
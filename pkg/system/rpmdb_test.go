@@ -0,0 +1,347 @@
+package system
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type testRpmEntry struct {
+	tag, dataType, count uint32
+	value                []byte
+}
+
+// newTestRpmHeader creates the header blob the way it's stored in the rpm databases
+// (testdata/rpmdb.sqlite has the same headers created with the same layout)
+func newTestRpmHeader(name, version, release, arch string, epoch int, files ...string) []byte {
+	cstr := func(s string) []byte { return append([]byte(s), 0) }
+	u32 := func(v int) []byte {
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(v))
+		return b
+	}
+
+	entries := []testRpmEntry{
+		{tag: rpmTagName, dataType: rpmTypeString, count: 1, value: cstr(name)},
+		{tag: rpmTagVersion, dataType: rpmTypeString, count: 1, value: cstr(version)},
+		{tag: rpmTagRelease, dataType: rpmTypeString, count: 1, value: cstr(release)},
+		{tag: rpmTagSize, dataType: rpmTypeInt32, count: 1, value: u32(1024)},
+		{tag: rpmTagArch, dataType: rpmTypeString, count: 1, value: cstr(arch)},
+	}
+
+	if epoch >= 0 {
+		entries = append(entries, testRpmEntry{tag: rpmTagEpoch, dataType: rpmTypeInt32, count: 1, value: u32(epoch)})
+	}
+
+	if len(files) > 0 {
+		dirSet := map[string]struct{}{}
+		for _, f := range files {
+			dirSet[path.Dir(f)+"/"] = struct{}{}
+		}
+
+		var dirs []string
+		for d := range dirSet {
+			dirs = append(dirs, d)
+		}
+		sort.Strings(dirs)
+
+		var dirIndexes, baseNames, dirNames []byte
+		for _, f := range files {
+			dirIndexes = append(dirIndexes, u32(sort.SearchStrings(dirs, path.Dir(f)+"/"))...)
+			baseNames = append(baseNames, cstr(path.Base(f))...)
+		}
+
+		for _, d := range dirs {
+			dirNames = append(dirNames, cstr(d)...)
+		}
+
+		entries = append(entries,
+			testRpmEntry{tag: rpmTagDirIndexes, dataType: rpmTypeInt32, count: uint32(len(files)), value: dirIndexes},
+			testRpmEntry{tag: rpmTagBaseNames, dataType: rpmTypeStringArr, count: uint32(len(files)), value: baseNames},
+			testRpmEntry{tag: rpmTagDirNames, dataType: rpmTypeStringArr, count: uint32(len(dirs)), value: dirNames})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+	var index, data bytes.Buffer
+	for _, e := range entries {
+		if e.dataType == rpmTypeInt32 {
+			for data.Len()%4 != 0 {
+				data.WriteByte(0)
+			}
+		}
+
+		for _, v := range []uint32{e.tag, e.dataType, uint32(data.Len()), e.count} {
+			_ = binary.Write(&index, binary.BigEndian, v)
+		}
+
+		data.Write(e.value)
+	}
+
+	blob := make([]byte, 8)
+	binary.BigEndian.PutUint32(blob[0:4], uint32(len(entries)))
+	binary.BigEndian.PutUint32(blob[4:8], uint32(data.Len()))
+	blob = append(blob, index.Bytes()...)
+	return append(blob, data.Bytes()...)
+}
+
+func testBigPackageFiles() []string {
+	var files []string
+	for i := 0; i < 150; i++ {
+		files = append(files, fmt.Sprintf("/usr/share/big/file-%03d.txt", i))
+	}
+
+	return append(files, "/usr/bin/big")
+}
+
+// testRpmHeaders returns the headers in testdata/rpmdb.sqlite
+func testRpmHeaders() [][]byte {
+	var blobs [][]byte
+	for i := 0; i < 40; i++ {
+		blobs = append(blobs, newTestRpmHeader(fmt.Sprintf("pkg-%02d", i), fmt.Sprintf("1.0.%d", i), "1.el9", "x86_64", -1))
+	}
+
+	blobs = append(blobs,
+		newTestRpmHeader("big", "2.0", "3.el9", "noarch", 1, testBigPackageFiles()...),
+		newTestRpmHeader("gpg-pubkey", "fd431d51", "4ae0493b", "(none)", -1))
+	return blobs
+}
+
+func checkTestRpmPackages(t *testing.T, packages []*OSPackage) {
+	if len(packages) != 41 {
+		t.Fatalf("expected 41 packages (without gpg-pubkey), got %d", len(packages))
+	}
+
+	for i, p := range packages[:40] {
+		expected := &OSPackage{
+			Name:          fmt.Sprintf("pkg-%02d", i),
+			Version:       fmt.Sprintf("1.0.%d-1.el9", i),
+			Arch:          "x86_64",
+			InstalledSize: 1024,
+			Manager:       OSPackageManagerRpm,
+		}
+
+		if !reflect.DeepEqual(p, expected) {
+			t.Errorf("package %d:\ngot      %+v\nexpected %+v", i, p, expected)
+		}
+	}
+
+	big := packages[40]
+	if big.Name != "big" || big.Version != "1:2.0-3.el9" || big.Arch != "noarch" {
+		t.Errorf("unexpected package: %+v", big)
+	}
+
+	if !reflect.DeepEqual(big.Files, testBigPackageFiles()) {
+		t.Errorf("unexpected package files (%d): %v", len(big.Files), big.Files)
+	}
+}
+
+// newTestBDB creates a BerkeleyDB hash database with one key/value pair per hash page
+// (the values bigger than the page are stored in the overflow pages)
+func newTestBDB(blobs [][]byte) []byte {
+	const pageSize = 512
+	order := binary.LittleEndian
+
+	newPage := func(pageType byte) []byte {
+		page := make([]byte, pageSize)
+		page[25] = pageType
+		return page
+	}
+
+	meta := newPage(bdbMetaHashType)
+	order.PutUint32(meta[12:16], bdbHashMagic)
+	order.PutUint32(meta[20:24], pageSize)
+	pages := [][]byte{meta}
+
+	for idx, blob := range blobs {
+		page := newPage(bdbPageHash)
+		pages = append(pages, page)
+
+		key := make([]byte, 5)
+		key[0] = bdbItemKeyData
+		order.PutUint32(key[1:], uint32(idx+1))
+
+		var value []byte
+		if len(blob)+1+len(key)+bdbPageHeaderSize+4 <= pageSize {
+			value = append([]byte{bdbItemKeyData}, blob...)
+		} else {
+			value = make([]byte, 12)
+			value[0] = bdbItemOffPage
+			order.PutUint32(value[4:], uint32(len(pages)))
+			order.PutUint32(value[8:], uint32(len(blob)))
+
+			for rest := blob; len(rest) > 0; {
+				chunk := rest
+				if len(chunk) > pageSize-bdbPageHeaderSize {
+					chunk = chunk[:pageSize-bdbPageHeaderSize]
+				}
+				rest = rest[len(chunk):]
+
+				overflow := newPage(bdbPageOverflow)
+				order.PutUint16(overflow[22:24], uint16(len(chunk)))
+				copy(overflow[bdbPageHeaderSize:], chunk)
+				if len(rest) > 0 {
+					order.PutUint32(overflow[16:20], uint32(len(pages)+1))
+				}
+
+				pages = append(pages, overflow)
+			}
+		}
+
+		keyOffset := pageSize - len(key)
+		valueOffset := keyOffset - len(value)
+		copy(page[keyOffset:], key)
+		copy(page[valueOffset:], value)
+		order.PutUint16(page[20:22], 2)
+		order.PutUint16(page[bdbPageHeaderSize:], uint16(keyOffset))
+		order.PutUint16(page[bdbPageHeaderSize+2:], uint16(valueOffset))
+	}
+
+	order.PutUint32(meta[32:36], uint32(len(pages)-1))
+	return bytes.Join(pages, nil)
+}
+
+// newTestNDB creates an rpm NDB database with one slot page
+func newTestNDB(blobs [][]byte) []byte {
+	order := binary.LittleEndian
+	raw := make([]byte, ndbPageSize)
+	order.PutUint32(raw[0:4], ndbHeaderMagic)
+	order.PutUint32(raw[12:16], 1)
+
+	for pos := ndbSlotEntriesStart * ndbSlotSize; pos < ndbPageSize; pos += ndbSlotSize {
+		order.PutUint32(raw[pos:], ndbSlotMagic)
+	}
+
+	for idx, blob := range blobs {
+		pkgIndex := uint32(idx + 1)
+		slot := raw[(ndbSlotEntriesStart+idx)*ndbSlotSize:]
+		order.PutUint32(slot[4:8], pkgIndex)
+		order.PutUint32(slot[8:12], uint32(len(raw)/ndbBlockSize))
+
+		header := make([]byte, ndbBlobHeaderSize)
+		order.PutUint32(header[0:4], ndbBlobMagic)
+		order.PutUint32(header[4:8], pkgIndex)
+		order.PutUint32(header[12:16], uint32(len(blob)))
+
+		raw = append(raw, header...)
+		raw = append(raw, blob...)
+		for len(raw)%ndbBlockSize != 0 {
+			raw = append(raw, 0)
+		}
+	}
+
+	return raw
+}
+
+func TestParseRpmDB(t *testing.T) {
+	sqliteDB, err := ioutil.ReadFile("testdata/rpmdb.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name string
+		raw  []byte
+	}{
+		{name: "sqlite", raw: sqliteDB},
+		{name: "bdb", raw: newTestBDB(testRpmHeaders())},
+		{name: "ndb", raw: newTestNDB(testRpmHeaders())},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			packages, err := ParseRpmDB(test.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkTestRpmPackages(t, packages)
+		})
+	}
+}
+
+func TestParseRpmDBSQLitePages(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/rpmdb.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the fixture needs the interior table pages and the overflow pages to be useful
+	var interior bool
+	pageSize := int(binary.BigEndian.Uint16(raw[16:18]))
+	for pgno := 1; pgno*pageSize <= len(raw); pgno++ {
+		hoff := (pgno - 1) * pageSize
+		if pgno == 1 {
+			hoff += sqliteHeaderSize
+		}
+
+		if raw[hoff] == sqlitePageInnerTable {
+			interior = true
+		}
+	}
+
+	if !interior {
+		t.Error("testdata/rpmdb.sqlite has no interior table pages")
+	}
+
+	if blob := testRpmHeaders()[40]; len(blob) < 2*pageSize {
+		t.Errorf("the big package header (%d bytes) doesn't use the overflow pages", len(blob))
+	}
+}
+
+func TestParseRpmDBMalformed(t *testing.T) {
+	sqliteDB, err := ioutil.ReadFile("testdata/rpmdb.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbs := map[string][]byte{
+		"sqlite": sqliteDB,
+		"bdb":    newTestBDB(testRpmHeaders()),
+		"ndb":    newTestNDB(testRpmHeaders()),
+	}
+
+	if _, err := ParseRpmDB([]byte("not a package database")); err != ErrRpmDBUnknownFormat {
+		t.Errorf("unknown format: got %v expected %v", err, ErrRpmDBUnknownFormat)
+	}
+
+	reservedSpace := append([]byte{}, sqliteDB...)
+	reservedSpace[20] = 200
+	if _, err := ParseRpmDB(reservedSpace); err != ErrSQLiteMalformed {
+		t.Errorf("sqlite page reserved space: got %v expected %v", err, ErrSQLiteMalformed)
+	}
+
+	//the big package header is the offpage value on the hash page after the 40 small package pages
+	overflowLen := append([]byte{}, dbs["bdb"]...)
+	bigPage := overflowLen[41*512:]
+	valueOffset := int(binary.LittleEndian.Uint16(bigPage[bdbPageHeaderSize+2:]))
+	binary.LittleEndian.PutUint32(bigPage[valueOffset+8:], 0xffffffff)
+	if _, err := ParseRpmDB(overflowLen); err != ErrBDBMalformed {
+		t.Errorf("bdb overflow value length: got %v expected %v", err, ErrBDBMalformed)
+	}
+
+	for name, raw := range dbs {
+		t.Run(name, func(t *testing.T) {
+			//the truncated and corrupted databases must not panic
+			for size := 0; size < len(raw); size += 61 {
+				_, _ = ParseRpmDB(raw[:size])
+			}
+
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 2000; i++ {
+				corrupted := append([]byte{}, raw...)
+				for j := 0; j < 4; j++ {
+					corrupted[rnd.Intn(len(corrupted))] = byte(rnd.Intn(256))
+				}
+
+				_, _ = ParseRpmDB(corrupted)
+			}
+		})
+	}
+}
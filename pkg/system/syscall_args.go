@@ -0,0 +1,96 @@
+package system
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// CallArgsInfo describes the recorded arguments for a system call
+type CallArgsInfo struct {
+	//Indexes are the recorded argument indexes (only the first five arguments are supported)
+	Indexes []uint
+	//FirstParamRef is true if the first argument is a pointer to the recorded 64 bit value
+	//(e.g., the 'clone3' flags in 'struct clone_args')
+	FirstParamRef bool
+	//SecondParamSockAddr is true if the second argument is a 'struct sockaddr' pointer
+	//(the address family and the port are recorded instead of the argument value)
+	SecondParamSockAddr bool
+}
+
+// RecordedCallArgs are the high-risk system calls with the recorded argument values
+// (used to generate the argument filters in the seccomp profiles)
+var RecordedCallArgs = map[string]CallArgsInfo{
+	"clone":       {Indexes: []uint{0}},                      //flags
+	"clone3":      {Indexes: []uint{0}, FirstParamRef: true}, //clone_args.flags
+	"socket":      {Indexes: []uint{0, 1}},                   //domain, type
+	"ioctl":       {Indexes: []uint{1}},                      //request
+	"personality": {Indexes: []uint{0}},                      //persona
+	"prctl":       {Indexes: []uint{0}},                      //option
+}
+
+// CallMemReader reads the traced process memory the pointer argument points to
+type CallMemReader func(ptr uint64, out []byte) error
+
+// CallArgValues returns the recorded argument values from the system call params
+// (the pointer arguments are read with the memory reader; the values are zero if they can't be read)
+func CallArgValues(args CallArgsInfo, params []uint64, readMem CallMemReader) []uint64 {
+	var values []uint64
+	for _, idx := range args.Indexes {
+		if idx >= uint(len(params)) {
+			continue
+		}
+
+		value := params[idx]
+		switch {
+		case idx == 0 && args.FirstParamRef:
+			var out [8]byte
+			if err := readMem(value, out[:]); err != nil {
+				value = 0
+			} else {
+				value = binary.LittleEndian.Uint64(out[:])
+			}
+		case idx == 1 && args.SecondParamSockAddr:
+			//the address family and the port (in the network byte order)
+			var out [4]byte
+			if err := readMem(value, out[:]); err != nil {
+				values = append(values, 0, 0)
+			} else {
+				values = append(values,
+					uint64(binary.LittleEndian.Uint16(out[0:2])),
+					uint64(binary.BigEndian.Uint16(out[2:4])))
+			}
+
+			continue
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// maxCallStringLen is the max length of the string arguments
+const maxCallStringLen = 4096
+
+// ReadCallString reads the NUL terminated string argument
+// (the string is read in words, so the reads don't cross the page boundaries)
+func ReadCallString(readMem CallMemReader, ptr uint64) string {
+	var data []byte
+	var out [8]byte
+	for len(data) < maxCallStringLen {
+		//the first read is aligned to the word boundary
+		chunk := out[:8-ptr%8]
+		if err := readMem(ptr, chunk); err != nil {
+			break
+		}
+
+		if idx := bytes.IndexByte(chunk, 0); idx >= 0 {
+			return string(append(data, chunk[:idx]...))
+		}
+
+		data = append(data, chunk...)
+		ptr += uint64(len(chunk))
+	}
+
+	return string(data)
+}
@@ -0,0 +1,50 @@
+package system
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCallArgValues(t *testing.T) {
+	mem := map[uint64][]byte{}
+	flags := make([]byte, 8)
+	binary.LittleEndian.PutUint64(flags, 0x10000000)
+	mem[0x1000] = flags
+	//AF_INET, port 8080 (network byte order)
+	mem[0x2000] = []byte{2, 0, 0x1f, 0x90}
+
+	readMem := func(ptr uint64, out []byte) error {
+		data, found := mem[ptr]
+		if !found {
+			return errors.New("bad address")
+		}
+
+		copy(out, data)
+		return nil
+	}
+
+	tt := []struct {
+		args     CallArgsInfo
+		params   []uint64
+		expected []uint64
+	}{
+		{args: RecordedCallArgs["clone"], params: []uint64{0x3d0f00, 1, 2}, expected: []uint64{0x3d0f00}},
+		{args: RecordedCallArgs["socket"], params: []uint64{2, 1, 0}, expected: []uint64{2, 1}},
+		{args: RecordedCallArgs["ioctl"], params: []uint64{1, 0x5413, 0}, expected: []uint64{0x5413}},
+		{args: RecordedCallArgs["clone3"], params: []uint64{0x1000, 88}, expected: []uint64{0x10000000}},
+		//the values that can't be read are zero
+		{args: RecordedCallArgs["clone3"], params: []uint64{0x3000, 88}, expected: []uint64{0}},
+		{args: CallArgsInfo{Indexes: []uint{0, 1}, SecondParamSockAddr: true}, params: []uint64{3, 0x2000, 16}, expected: []uint64{3, 2, 8080}},
+		{args: CallArgsInfo{Indexes: []uint{1}, SecondParamSockAddr: true}, params: []uint64{3, 0x3000, 16}, expected: []uint64{0, 0}},
+		//the indexes without the param values are skipped
+		{args: CallArgsInfo{Indexes: []uint{2, 3}}, params: []uint64{1, 2, 3}, expected: []uint64{3}},
+	}
+
+	for idx, test := range tt {
+		if got := CallArgValues(test.args, test.params, readMem); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%d: got %v expected %v", idx, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package system
+
+// Linux capability names (the names used by 'docker run --cap-add' and Kubernetes)
+const (
+	CapChown          = "CHOWN"
+	CapKill           = "KILL"
+	CapMknod          = "MKNOD"
+	CapNetBindService = "NET_BIND_SERVICE"
+	CapNetRaw         = "NET_RAW"
+	CapSetGID         = "SETGID"
+	CapSetUID         = "SETUID"
+	CapSysAdmin       = "SYS_ADMIN"
+	CapSysBoot        = "SYS_BOOT"
+	CapSysChroot      = "SYS_CHROOT"
+	CapSysModule      = "SYS_MODULE"
+	CapSysNice        = "SYS_NICE"
+	CapSysPacct       = "SYS_PACCT"
+	CapSysPtrace      = "SYS_PTRACE"
+	CapSysRawIO       = "SYS_RAWIO"
+	CapSysTime        = "SYS_TIME"
+	CapSyslog         = "SYSLOG"
+)
+
+// CallCapabilityInfo describes the capabilities used by a system call
+type CallCapabilityInfo struct {
+	//Caps are the capabilities used by the successful calls
+	Caps []string
+	//Args are the argument values needed to check if the call used the capabilities
+	Args CallArgsInfo
+	//Context is the process state needed to check if the call used the capabilities
+	Context CallContextInfo
+	//Uses returns true if the call with the argument values used the capabilities
+	//(nil if all successful calls use them)
+	Uses func(values []uint64, ctx *CallContext) bool
+}
+
+// CallContextInfo describes the process state captured before the call
+type CallContextInfo struct {
+	//IDs is true if the check needs the process ids
+	IDs bool
+	//File is the file argument (the check needs the file owner)
+	File *CallFileArg
+	//SignalTarget is true if the check needs to know if the first argument (the signal target pid)
+	//is in the application process tree
+	SignalTarget bool
+}
+
+// CallFileArg describes the file argument of a system call
+type CallFileArg struct {
+	//FD is the fd argument index (the directory fd if there's a path argument; -1 if there's no fd)
+	FD int
+	//Path is the path argument index (-1 if there's no path)
+	Path int
+	//Flags is the 'AT_*' flags argument index (-1 if there are no flags)
+	Flags int
+	//NoFollow is true if the symlinks are not followed ('lchown')
+	NoFollow bool
+}
+
+// ProcessIDs are the real, effective, saved and file system ids of a process
+type ProcessIDs struct {
+	UIDs [4]uint32
+	GIDs [4]uint32
+}
+
+// FileOwner is the file owner and group
+type FileOwner struct {
+	UID uint32
+	GID uint32
+}
+
+// CallContext is the traced process state captured before the system call
+// (for the capability checks that depend on the process ids, the file ownership or the signal target)
+type CallContext struct {
+	//IDs are the process ids (nil if they are unknown)
+	IDs *ProcessIDs
+	//FileOwner is the owner of the file argument (nil if it's unknown)
+	FileOwner *FileOwner
+	//OwnSignalTarget is true if the signal target is in the application process tree
+	OwnSignalTarget bool
+}
+
+const (
+	afInet        = 2
+	afInet6       = 10
+	afPacket      = 17
+	sockTypeRaw   = 3
+	sockTypeMask  = 0xf //SOCK_NONBLOCK and SOCK_CLOEXEC are in the higher bits
+	maxPrivPort   = 1023
+	fileTypeMask  = 0170000
+	fileTypeChar  = 0020000
+	fileTypeBlock = 0060000
+	schedFIFO     = 1
+	schedRR       = 2
+	//the 'syslog' actions allowed without CAP_SYSLOG (read all and the buffer size)
+	syslogActionReadAll    = 3
+	syslogActionSizeBuffer = 10
+	//CLONE_NEWNS|CLONE_NEWCGROUP|CLONE_NEWUTS|CLONE_NEWIPC|CLONE_NEWPID|CLONE_NEWNET
+	cloneNewNamespaces = 0x00020000 | 0x02000000 | 0x04000000 | 0x08000000 | 0x20000000 | 0x40000000
+	cloneNewUser       = 0x10000000
+)
+
+// unchangedID is the -1 id value (the id is not changed)
+const unchangedID = 0xffffffff
+
+// set*id call types
+const (
+	setID = iota
+	setREID
+	setRESID
+	setFSID
+)
+
+// privilegedBind is true for the inet sockets bound to the privileged ports
+func privilegedBind(values []uint64, ctx *CallContext) bool {
+	if len(values) < 2 {
+		return false
+	}
+
+	family, port := values[0], values[1]
+	return (family == afInet || family == afInet6) && port > 0 && port <= maxPrivPort
+}
+
+// rawSocket is true for the raw and packet sockets
+func rawSocket(values []uint64, ctx *CallContext) bool {
+	if len(values) < 2 {
+		return false
+	}
+
+	return values[0] == afPacket || values[1]&sockTypeMask == sockTypeRaw
+}
+
+// newNamespaces is true if the clone/unshare flags create new namespaces
+// (without a new user namespace where the process gets all capabilities)
+func newNamespaces(values []uint64, ctx *CallContext) bool {
+	if len(values) < 1 {
+		return false
+	}
+
+	return values[0]&cloneNewNamespaces != 0 && values[0]&cloneNewUser == 0
+}
+
+// deviceFile is true if the 'mknod' mode is for a character or block device
+func deviceFile(values []uint64, ctx *CallContext) bool {
+	if len(values) < 1 {
+		return false
+	}
+
+	fileType := values[0] & fileTypeMask
+	return fileType == fileTypeChar || fileType == fileTypeBlock
+}
+
+// negativeNice is true if the priority is raised
+func negativeNice(values []uint64, ctx *CallContext) bool {
+	return len(values) > 0 && int32(values[0]) < 0
+}
+
+// realtimePolicy is true for the real-time scheduling policies
+func realtimePolicy(values []uint64, ctx *CallContext) bool {
+	if len(values) < 1 {
+		return false
+	}
+
+	policy := values[0] &^ 0x40000000 //SCHED_RESET_ON_FORK
+	return policy == schedFIFO || policy == schedRR
+}
+
+// privilegedSyslog is true for the 'syslog' actions that need CAP_SYSLOG
+func privilegedSyslog(values []uint64, ctx *CallContext) bool {
+	if len(values) < 1 {
+		return false
+	}
+
+	return values[0] != syslogActionReadAll && values[0] != syslogActionSizeBuffer
+}
+
+func hasID(id uint32, ids ...uint32) bool {
+	for _, current := range ids {
+		if id == current {
+			return true
+		}
+	}
+
+	return false
+}
+
+// privilegedSetID returns the check for the set*id calls
+// (the unprivileged processes can only switch between their real, effective and saved ids,
+// so the calls that keep or swap the current ids don't need the capability)
+func privilegedSetID(callType int, group bool) func(values []uint64, ctx *CallContext) bool {
+	return func(values []uint64, ctx *CallContext) bool {
+		if ctx == nil || ctx.IDs == nil {
+			return true
+		}
+
+		ids := ctx.IDs.UIDs
+		if group {
+			ids = ctx.IDs.GIDs
+		}
+
+		real, effective, saved, fs := ids[0], ids[1], ids[2], ids[3]
+		allowed := func(idx int, current ...uint32) bool {
+			if idx >= len(values) {
+				return false
+			}
+
+			id := uint32(values[idx])
+			return id == unchangedID || hasID(id, current...)
+		}
+
+		switch callType {
+		case setID:
+			return !(len(values) > 0 && hasID(uint32(values[0]), real, saved))
+		case setREID:
+			return !(allowed(0, real, effective) && allowed(1, real, effective, saved))
+		case setRESID:
+			return !(allowed(0, real, effective, saved) &&
+				allowed(1, real, effective, saved) &&
+				allowed(2, real, effective, saved))
+		case setFSID:
+			return !allowed(0, real, effective, saved, fs)
+		}
+
+		return true
+	}
+}
+
+// privilegedChown is true if the ownership change needs CAP_CHOWN
+// (the file owner can keep the file owner and set the group to the file group or its own group;
+// the supplementary groups are not checked)
+func privilegedChown(values []uint64, ctx *CallContext) bool {
+	if len(values) < 2 || ctx == nil || ctx.IDs == nil || ctx.FileOwner == nil {
+		return true
+	}
+
+	owner, group := uint32(values[0]), uint32(values[1])
+	fsUID, fsGID := ctx.IDs.UIDs[3], ctx.IDs.GIDs[3]
+	isOwner := fsUID == ctx.FileOwner.UID
+	ownerAllowed := owner == unchangedID || (isOwner && owner == ctx.FileOwner.UID)
+	groupAllowed := group == unchangedID || (isOwner && (group == ctx.FileOwner.GID || group == fsGID))
+	return !(ownerAllowed && groupAllowed)
+}
+
+// foreignSignalTarget is true if the signal is sent outside of the application process tree
+func foreignSignalTarget(values []uint64, ctx *CallContext) bool {
+	return ctx == nil || !ctx.OwnSignalTarget
+}
+
+// setIDCapability creates the capability info for the set*id calls
+func setIDCapability(callType int, group bool) CallCapabilityInfo {
+	info := CallCapabilityInfo{
+		Caps:    []string{CapSetUID},
+		Args:    CallArgsInfo{Indexes: []uint{0}},
+		Context: CallContextInfo{IDs: true},
+		Uses:    privilegedSetID(callType, group),
+	}
+
+	if group {
+		info.Caps = []string{CapSetGID}
+	}
+
+	switch callType {
+	case setREID:
+		info.Args.Indexes = []uint{0, 1}
+	case setRESID:
+		info.Args.Indexes = []uint{0, 1, 2}
+	}
+
+	return info
+}
+
+// chownCapability creates the capability info for the chown calls
+// (the owner and group argument indexes and the file argument)
+func chownCapability(indexes []uint, file *CallFileArg) CallCapabilityInfo {
+	return CallCapabilityInfo{
+		Caps:    []string{CapChown},
+		Args:    CallArgsInfo{Indexes: indexes},
+		Context: CallContextInfo{IDs: true, File: file},
+		Uses:    privilegedChown,
+	}
+}
+
+// CallCapabilities are the system calls that need capabilities
+// (the 'chown', 'set*id' and 'kill' calls are counted only if the ids change in a way
+// that needs the capability or if the signal is sent outside of the application process tree)
+var CallCapabilities = map[string]CallCapabilityInfo{
+	//chown(const char *pathname, uid_t owner, gid_t group)
+	"chown":  chownCapability([]uint{1, 2}, &CallFileArg{FD: -1, Path: 0, Flags: -1}),
+	"lchown": chownCapability([]uint{1, 2}, &CallFileArg{FD: -1, Path: 0, Flags: -1, NoFollow: true}),
+	//fchown(int fd, uid_t owner, gid_t group)
+	"fchown": chownCapability([]uint{1, 2}, &CallFileArg{FD: 0, Path: -1, Flags: -1}),
+	//fchownat(int dirfd, const char *pathname, uid_t owner, gid_t group, int flags)
+	"fchownat": chownCapability([]uint{2, 3}, &CallFileArg{FD: 0, Path: 1, Flags: 4}),
+	"chown32":  chownCapability([]uint{1, 2}, &CallFileArg{FD: -1, Path: 0, Flags: -1}),
+	"lchown32": chownCapability([]uint{1, 2}, &CallFileArg{FD: -1, Path: 0, Flags: -1, NoFollow: true}),
+	"fchown32": chownCapability([]uint{1, 2}, &CallFileArg{FD: 0, Path: -1, Flags: -1}),
+
+	"setuid":      setIDCapability(setID, false),
+	"setuid32":    setIDCapability(setID, false),
+	"setreuid":    setIDCapability(setREID, false),
+	"setreuid32":  setIDCapability(setREID, false),
+	"setresuid":   setIDCapability(setRESID, false),
+	"setresuid32": setIDCapability(setRESID, false),
+	"setfsuid":    setIDCapability(setFSID, false),
+	"setfsuid32":  setIDCapability(setFSID, false),
+
+	"setgid":      setIDCapability(setID, true),
+	"setgid32":    setIDCapability(setID, true),
+	"setregid":    setIDCapability(setREID, true),
+	"setregid32":  setIDCapability(setREID, true),
+	"setresgid":   setIDCapability(setRESID, true),
+	"setresgid32": setIDCapability(setRESID, true),
+	"setfsgid":    setIDCapability(setFSID, true),
+	"setfsgid32":  setIDCapability(setFSID, true),
+
+	//the process always needs CAP_SETGID to set the supplementary groups
+	"setgroups":   {Caps: []string{CapSetGID}},
+	"setgroups32": {Caps: []string{CapSetGID}},
+
+	//kill(pid_t pid, int sig)
+	"kill": {
+		Caps:    []string{CapKill},
+		Context: CallContextInfo{SignalTarget: true},
+		Uses:    foreignSignalTarget,
+	},
+	//tkill(pid_t tid, int sig)
+	"tkill": {
+		Caps:    []string{CapKill},
+		Context: CallContextInfo{SignalTarget: true},
+		Uses:    foreignSignalTarget,
+	},
+	//tgkill(pid_t tgid, pid_t tid, int sig)
+	"tgkill": {
+		Caps:    []string{CapKill},
+		Context: CallContextInfo{SignalTarget: true},
+		Uses:    foreignSignalTarget,
+	},
+
+	//bind(int fd, struct sockaddr *addr, socklen_t addrlen)
+	"bind": {
+		Caps: []string{CapNetBindService},
+		Args: CallArgsInfo{Indexes: []uint{1}, SecondParamSockAddr: true},
+		Uses: privilegedBind,
+	},
+	//socket(int domain, int type, int protocol)
+	"socket": {
+		Caps: []string{CapNetRaw},
+		Args: CallArgsInfo{Indexes: []uint{0, 1}},
+		Uses: rawSocket,
+	},
+
+	"mount":         {Caps: []string{CapSysAdmin}},
+	"umount2":       {Caps: []string{CapSysAdmin}},
+	"pivot_root":    {Caps: []string{CapSysAdmin}},
+	"setns":         {Caps: []string{CapSysAdmin}},
+	"sethostname":   {Caps: []string{CapSysAdmin}},
+	"setdomainname": {Caps: []string{CapSysAdmin}},
+	"unshare": {
+		Caps: []string{CapSysAdmin},
+		Args: CallArgsInfo{Indexes: []uint{0}},
+		Uses: newNamespaces,
+	},
+	"clone": {
+		Caps: []string{CapSysAdmin},
+		Args: CallArgsInfo{Indexes: []uint{0}},
+		Uses: newNamespaces,
+	},
+	"clone3": {
+		Caps: []string{CapSysAdmin},
+		Args: CallArgsInfo{Indexes: []uint{0}, FirstParamRef: true},
+		Uses: newNamespaces,
+	},
+
+	"chroot": {Caps: []string{CapSysChroot}},
+	//mknod(const char *pathname, mode_t mode, dev_t dev)
+	"mknod": {
+		Caps: []string{CapMknod},
+		Args: CallArgsInfo{Indexes: []uint{1}},
+		Uses: deviceFile,
+	},
+	//mknodat(int dirfd, const char *pathname, mode_t mode, dev_t dev)
+	"mknodat": {
+		Caps: []string{CapMknod},
+		Args: CallArgsInfo{Indexes: []uint{2}},
+		Uses: deviceFile,
+	},
+
+	"ptrace":            {Caps: []string{CapSysPtrace}},
+	"process_vm_readv":  {Caps: []string{CapSysPtrace}},
+	"process_vm_writev": {Caps: []string{CapSysPtrace}},
+
+	//setpriority(int which, id_t who, int prio)
+	"setpriority": {
+		Caps: []string{CapSysNice},
+		Args: CallArgsInfo{Indexes: []uint{2}},
+		Uses: negativeNice,
+	},
+	//sched_setscheduler(pid_t pid, int policy, const struct sched_param *param)
+	"sched_setscheduler": {
+		Caps: []string{CapSysNice},
+		Args: CallArgsInfo{Indexes: []uint{1}},
+		Uses: realtimePolicy,
+	},
+
+	"init_module":   {Caps: []string{CapSysModule}},
+	"finit_module":  {Caps: []string{CapSysModule}},
+	"delete_module": {Caps: []string{CapSysModule}},
+	"reboot":        {Caps: []string{CapSysBoot}},
+	"settimeofday":  {Caps: []string{CapSysTime}},
+	"clock_settime": {Caps: []string{CapSysTime}},
+	"acct":          {Caps: []string{CapSysPacct}},
+	"iopl":          {Caps: []string{CapSysRawIO}},
+	"ioperm":        {Caps: []string{CapSysRawIO}},
+	//syslog(int type, char *bufp, int len)
+	"syslog": {
+		Caps: []string{CapSyslog},
+		Args: CallArgsInfo{Indexes: []uint{0}},
+		Uses: privilegedSyslog,
+	},
+}
+
+// CallUsedCapabilities returns the capabilities used by a successful system call
+// (the argument values are recorded using the CallCapabilities argument info
+// and the context is captured before the call; the calls without the context are counted)
+func CallUsedCapabilities(name string, values []uint64, ctx *CallContext) []string {
+	info, found := CallCapabilities[name]
+	if !found {
+		return nil
+	}
+
+	if info.Uses != nil && !info.Uses(values, ctx) {
+		return nil
+	}
+
+	return info.Caps
+}
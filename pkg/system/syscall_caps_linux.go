@@ -0,0 +1,198 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	atFDCWD           = -100
+	atSymlinkNoFollow = 0x100
+	//max process tree depth to check (the parent process chain)
+	maxProcessTreeDepth = 64
+)
+
+// NewCallContext captures the traced process state before the system call
+// (appPid is the application process tree root; returns nil if the call doesn't need the context)
+func NewCallContext(name string, pid int, appPid int, params []uint64, readMem CallMemReader) *CallContext {
+	info, found := CallCapabilities[name]
+	if !found || (!info.Context.IDs && info.Context.File == nil && !info.Context.SignalTarget) {
+		return nil
+	}
+
+	ctx := &CallContext{}
+	if info.Context.IDs {
+		if ids, err := ReadProcessIDs(pid); err == nil {
+			ctx.IDs = ids
+		}
+	}
+
+	if info.Context.File != nil {
+		ctx.FileOwner = callFileOwner(pid, info.Context.File, params, readMem)
+	}
+
+	if info.Context.SignalTarget && len(params) > 0 {
+		ctx.OwnSignalTarget = ownSignalTarget(name, int(int32(params[0])), pid, appPid)
+	}
+
+	return ctx
+}
+
+// ownSignalTarget checks if the signal target is in the application process tree
+func ownSignalTarget(name string, target int, pid int, appPid int) bool {
+	if name == "kill" {
+		switch {
+		case target == 0:
+			//the caller's process group
+			return true
+		case target == -1:
+			//all processes the caller can signal
+			return false
+		case target < 0:
+			//the process group (checking the group leader)
+			target = -target
+		}
+	}
+
+	if target <= 0 {
+		return false
+	}
+
+	return target == pid || InProcessTree(appPid, target)
+}
+
+// InProcessTree checks if the process is the root process or its descendant
+// (the threads are checked using their parent process)
+func InProcessTree(rootPid int, pid int) bool {
+	for depth := 0; depth < maxProcessTreeDepth && pid > 0; depth++ {
+		if pid == rootPid {
+			return true
+		}
+
+		status, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+		if err != nil {
+			return false
+		}
+
+		ppid, found := statusField(status, "PPid")
+		if !found || len(ppid) != 1 {
+			return false
+		}
+
+		pid = int(ppid[0])
+	}
+
+	return false
+}
+
+// ReadProcessIDs reads the process ids from '/proc/<pid>/status'
+func ReadProcessIDs(pid int) (*ProcessIDs, error) {
+	status, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProcessIDs(status)
+}
+
+func parseProcessIDs(status []byte) (*ProcessIDs, error) {
+	var ids ProcessIDs
+	uids, foundUIDs := statusField(status, "Uid")
+	gids, foundGIDs := statusField(status, "Gid")
+	if !foundUIDs || !foundGIDs || len(uids) != 4 || len(gids) != 4 {
+		return nil, fmt.Errorf("no process ids")
+	}
+
+	copy(ids.UIDs[:], uids)
+	copy(ids.GIDs[:], gids)
+	return &ids, nil
+}
+
+// statusField returns the numeric values of a '/proc/<pid>/status' field
+func statusField(status []byte, name string) ([]uint32, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, name+":") {
+			continue
+		}
+
+		var values []uint32
+		for _, field := range strings.Fields(strings.TrimPrefix(line, name+":")) {
+			value, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, false
+			}
+
+			values = append(values, uint32(value))
+		}
+
+		return values, true
+	}
+
+	return nil, false
+}
+
+// callFileOwner returns the owner of the file argument
+// (the paths are resolved in the traced process root and working directories)
+func callFileOwner(pid int, arg *CallFileArg, params []uint64, readMem CallMemReader) *FileOwner {
+	param := func(idx int) (uint64, bool) {
+		if idx < 0 || idx >= len(params) {
+			return 0, false
+		}
+
+		return params[idx], true
+	}
+
+	noFollow := arg.NoFollow
+	if flags, ok := param(arg.Flags); ok && flags&atSymlinkNoFollow != 0 {
+		noFollow = true
+	}
+
+	var filePath string
+	if ptr, ok := param(arg.Path); ok {
+		filePath = ReadCallString(readMem, ptr)
+	}
+
+	fd, hasFD := param(arg.FD)
+	switch {
+	case filePath == "" && hasFD:
+		//'fchown' and the 'AT_EMPTY_PATH' calls
+		filePath = fmt.Sprintf("/proc/%d/fd/%d", pid, int32(fd))
+		noFollow = false
+	case filePath == "":
+		return nil
+	case strings.HasPrefix(filePath, "/"):
+		filePath = path.Join(fmt.Sprintf("/proc/%d/root", pid), filePath)
+	case hasFD && int32(fd) != atFDCWD:
+		filePath = path.Join(fmt.Sprintf("/proc/%d/fd/%d", pid, int32(fd)), filePath)
+	default:
+		filePath = path.Join(fmt.Sprintf("/proc/%d/cwd", pid), filePath)
+	}
+
+	var info os.FileInfo
+	var err error
+	if noFollow {
+		info, err = os.Lstat(filePath)
+	} else {
+		info, err = os.Stat(filePath)
+	}
+
+	if err != nil {
+		return nil
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	return &FileOwner{UID: stat.Uid, GID: stat.Gid}
+}
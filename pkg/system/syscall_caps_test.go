@@ -0,0 +1,226 @@
+package system
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testIDs(uids, gids [4]uint32) *CallContext {
+	return &CallContext{IDs: &ProcessIDs{UIDs: uids, GIDs: gids}}
+}
+
+func TestCallUsedCapabilities(t *testing.T) {
+	user := [4]uint32{1000, 1000, 1000, 1000}
+	root := [4]uint32{0, 0, 0, 0}
+	//the ids after 'seteuid(1000)' in a root process
+	dropped := [4]uint32{0, 1000, 0, 1000}
+
+	tt := []struct {
+		desc     string
+		name     string
+		values   []uint64
+		ctx      *CallContext
+		expected []string
+	}{
+		{desc: "setuid no-op", name: "setuid", values: []uint64{1000}, ctx: testIDs(user, user)},
+		{desc: "setuid saved", name: "setuid", values: []uint64{0}, ctx: testIDs(dropped, dropped)},
+		{desc: "setuid drop", name: "setuid", values: []uint64{1000}, ctx: testIDs(root, root), expected: []string{CapSetUID}},
+		{desc: "setuid no context", name: "setuid", values: []uint64{1000}, expected: []string{CapSetUID}},
+		{desc: "setgid drop", name: "setgid", values: []uint64{1000}, ctx: testIDs(root, root), expected: []string{CapSetGID}},
+		{desc: "setgid other group", name: "setgid32", values: []uint64{2000}, ctx: testIDs(user, user), expected: []string{CapSetGID}},
+		{desc: "setreuid unchanged", name: "setreuid", values: []uint64{unchangedID, 1000}, ctx: testIDs(user, user)},
+		{desc: "setreuid swap", name: "setreuid", values: []uint64{1000, 0}, ctx: testIDs(dropped, dropped)},
+		{desc: "setreuid drop", name: "setreuid", values: []uint64{1000, 1000}, ctx: testIDs(root, root), expected: []string{CapSetUID}},
+		{desc: "setresuid saved", name: "setresuid", values: []uint64{unchangedID, 0, unchangedID}, ctx: testIDs(dropped, dropped)},
+		{desc: "setresgid drop", name: "setresgid", values: []uint64{1000, 1000, 1000}, ctx: testIDs(root, root), expected: []string{CapSetGID}},
+		{desc: "setfsuid effective", name: "setfsuid", values: []uint64{1000}, ctx: testIDs(dropped, dropped)},
+		{desc: "setfsuid other", name: "setfsuid", values: []uint64{2000}, ctx: testIDs(user, user), expected: []string{CapSetUID}},
+		{desc: "setgroups", name: "setgroups", values: nil, ctx: testIDs(user, user), expected: []string{CapSetGID}},
+	}
+
+	for _, test := range tt {
+		if got := CallUsedCapabilities(test.name, test.values, test.ctx); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: got %v expected %v", test.desc, got, test.expected)
+		}
+	}
+}
+
+func TestCallUsedCapabilitiesChown(t *testing.T) {
+	user := [4]uint32{1000, 1000, 1000, 1000}
+	root := [4]uint32{0, 0, 0, 0}
+	withOwner := func(ctx *CallContext, uid, gid uint32) *CallContext {
+		ctx.FileOwner = &FileOwner{UID: uid, GID: gid}
+		return ctx
+	}
+
+	tt := []struct {
+		desc     string
+		values   []uint64
+		ctx      *CallContext
+		expected []string
+	}{
+		{desc: "owner no-op", values: []uint64{1000, 1000}, ctx: withOwner(testIDs(user, user), 1000, 1000)},
+		{desc: "owner unchanged", values: []uint64{unchangedID, unchangedID}, ctx: withOwner(testIDs(user, user), 0, 0)},
+		{desc: "owner own group", values: []uint64{unchangedID, 1000}, ctx: withOwner(testIDs(user, user), 1000, 2000)},
+		{desc: "root on root file", values: []uint64{0, 0}, ctx: withOwner(testIDs(root, root), 0, 0)},
+		{desc: "root on app file", values: []uint64{0, 0}, ctx: withOwner(testIDs(root, root), 1000, 1000), expected: []string{CapChown}},
+		{desc: "root gives file", values: []uint64{1000, unchangedID}, ctx: withOwner(testIDs(root, root), 0, 0), expected: []string{CapChown}},
+		{desc: "other group", values: []uint64{unchangedID, 3000}, ctx: withOwner(testIDs(user, user), 1000, 1000), expected: []string{CapChown}},
+		{desc: "unknown owner", values: []uint64{1000, 1000}, ctx: testIDs(user, user), expected: []string{CapChown}},
+		{desc: "no context", values: []uint64{1000, 1000}, expected: []string{CapChown}},
+	}
+
+	for _, test := range tt {
+		if got := CallUsedCapabilities("fchownat", test.values, test.ctx); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: got %v expected %v", test.desc, got, test.expected)
+		}
+	}
+}
+
+func TestCallUsedCapabilitiesKill(t *testing.T) {
+	tt := []struct {
+		name     string
+		ctx      *CallContext
+		expected []string
+	}{
+		{name: "kill", ctx: &CallContext{OwnSignalTarget: true}},
+		{name: "tgkill", ctx: &CallContext{OwnSignalTarget: true}},
+		{name: "kill", ctx: &CallContext{}, expected: []string{CapKill}},
+		{name: "tkill", expected: []string{CapKill}},
+	}
+
+	for idx, test := range tt {
+		if got := CallUsedCapabilities(test.name, []uint64{1, 15}, test.ctx); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%d/%s: got %v expected %v", idx, test.name, got, test.expected)
+		}
+	}
+}
+
+func TestOwnSignalTarget(t *testing.T) {
+	pid := os.Getpid()
+	tt := []struct {
+		name     string
+		target   int
+		expected bool
+	}{
+		{name: "kill", target: 0, expected: true},
+		{name: "kill", target: -1, expected: false},
+		{name: "kill", target: pid, expected: true},
+		{name: "kill", target: -pid, expected: true},
+		{name: "tkill", target: pid, expected: true},
+		{name: "tkill", target: 0, expected: false},
+		//the parent process is not in the application process tree
+		{name: "kill", target: os.Getppid(), expected: false},
+	}
+
+	for idx, test := range tt {
+		if got := ownSignalTarget(test.name, test.target, pid, pid); got != test.expected {
+			t.Errorf("%d/%s(%d): got %v expected %v", idx, test.name, test.target, got, test.expected)
+		}
+	}
+
+	if !InProcessTree(os.Getppid(), pid) {
+		t.Errorf("expected the process to be in its parent process tree")
+	}
+}
+
+func TestParseProcessIDs(t *testing.T) {
+	status := "Name:\tapp\nUmask:\t0022\nState:\tS (sleeping)\nPPid:\t1\n" +
+		"Uid:\t1000\t1001\t1002\t1003\nGid:\t2000\t2001\t2002\t2003\nGroups:\t2000\n"
+
+	ids, err := parseProcessIDs([]byte(status))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &ProcessIDs{
+		UIDs: [4]uint32{1000, 1001, 1002, 1003},
+		GIDs: [4]uint32{2000, 2001, 2002, 2003},
+	}
+
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("got %+v expected %+v", ids, expected)
+	}
+
+	if _, err := parseProcessIDs([]byte("Name:\tapp\nUid:\t1000\n")); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+// testMemReader reads the test memory starting at the base address
+func testMemReader(base uint64, mem []byte) CallMemReader {
+	return func(ptr uint64, out []byte) error {
+		if ptr < base || ptr+uint64(len(out)) > base+uint64(len(mem)) {
+			return errors.New("bad address")
+		}
+
+		copy(out, mem[ptr-base:])
+		return nil
+	}
+}
+
+func TestReadCallString(t *testing.T) {
+	tt := []struct {
+		mem      string
+		ptr      uint64
+		expected string
+	}{
+		{mem: "/etc/hosts\x00.......", ptr: 0x1000, expected: "/etc/hosts"},
+		//the unaligned strings
+		{mem: "../etc/hosts\x00......", ptr: 0x1003, expected: "etc/hosts"},
+		{mem: "\x00.......", ptr: 0x1000, expected: ""},
+		//the strings without the NUL terminator are read up to the unreadable memory
+		{mem: "/app/data", ptr: 0x1000, expected: "/app/dat"},
+	}
+
+	for idx, test := range tt {
+		if got := ReadCallString(testMemReader(0x1000, []byte(test.mem)), test.ptr); got != test.expected {
+			t.Errorf("%d: got %q expected %q", idx, got, test.expected)
+		}
+	}
+}
+
+func TestCallFileOwner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syscall-caps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(filePath, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	readMem := testMemReader(0x1000, []byte(filePath+"\x00"))
+	expected := &FileOwner{UID: uint32(os.Geteuid()), GID: uint32(os.Getegid())}
+	pid := os.Getpid()
+
+	tt := []struct {
+		desc   string
+		arg    *CallFileArg
+		params []uint64
+		owner  *FileOwner
+	}{
+		{desc: "path", arg: CallCapabilities["chown"].Context.File, params: []uint64{0x1000, 0, 0}, owner: expected},
+		{desc: "fd", arg: CallCapabilities["fchown"].Context.File, params: []uint64{uint64(file.Fd()), 0, 0}, owner: expected},
+		{desc: "at path", arg: CallCapabilities["fchownat"].Context.File, params: []uint64{uint64(0xffffff9c), 0x1000, 0, 0, 0}, owner: expected},
+		{desc: "missing file", arg: CallCapabilities["chown"].Context.File, params: []uint64{0x2000, 0, 0}},
+	}
+
+	for _, test := range tt {
+		if got := callFileOwner(pid, test.arg, test.params, readMem); !reflect.DeepEqual(got, test.owner) {
+			t.Errorf("%s: got %+v expected %+v", test.desc, got, test.owner)
+		}
+	}
+}
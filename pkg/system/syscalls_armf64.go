@@ -88,7 +88,7 @@ var syscallNumTableArmFamily64 = map[uint32]string{
 	76:  "splice",
 	77:  "tee",
 	78:  "readlinkat",
-	79:  "fstatat",
+	79:  "newfstatat",
 	80:  "fstat",
 	81:  "sync",
 	82:  "fsync",
@@ -46,8 +46,14 @@ func CallThirdParam(regs syscall.PtraceRegs) uint64 {
 	return regs.Rdx
 }
 
+// CallFourthParam returns the fourth syscall param
+// (the syscalls use r10 instead of rcx, which has the return address)
 func CallFourthParam(regs syscall.PtraceRegs) uint64 {
-	return regs.Rcx
+	return regs.R10
+}
+
+func CallFifthParam(regs syscall.PtraceRegs) uint64 {
+	return regs.R8
 }
 
 /*
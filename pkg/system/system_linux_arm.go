@@ -41,3 +41,15 @@ func CallFirstParam(regs syscall.PtraceRegs) uint64 {
 func CallSecondParam(regs syscall.PtraceRegs) uint64 {
 	return uint64(regs.Uregs[1])
 }
+
+func CallThirdParam(regs syscall.PtraceRegs) uint64 {
+	return uint64(regs.Uregs[2])
+}
+
+func CallFourthParam(regs syscall.PtraceRegs) uint64 {
+	return uint64(regs.Uregs[3])
+}
+
+func CallFifthParam(regs syscall.PtraceRegs) uint64 {
+	return uint64(regs.Uregs[4])
+}
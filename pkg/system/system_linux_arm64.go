@@ -41,3 +41,15 @@ func CallFirstParam(regs unix.PtraceRegsArm64) uint64 {
 func CallSecondParam(regs unix.PtraceRegsArm64) uint64 {
 	return uint64(regs.Regs[1])
 }
+
+func CallThirdParam(regs unix.PtraceRegsArm64) uint64 {
+	return uint64(regs.Regs[2])
+}
+
+func CallFourthParam(regs unix.PtraceRegsArm64) uint64 {
+	return uint64(regs.Regs[3])
+}
+
+func CallFifthParam(regs unix.PtraceRegsArm64) uint64 {
+	return uint64(regs.Regs[4])
+}
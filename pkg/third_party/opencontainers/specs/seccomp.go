@@ -68,6 +68,7 @@ type Syscall struct {
 	Name     string   `json:"name,omitempty"`
 	Names    []string `json:"names,omitempty"`
 	Action   Action   `json:"action"`
+	ErrnoRet *uint    `json:"errnoRet,omitempty"`
 	Args     []*Arg   `json:"args,omitempty"`
 	Comment  string   `json:"comment,omitempty"`
 	Includes Filter   `json:"includes,omitempty"`
@@ -0,0 +1,390 @@
+// Package jsonschema generates JSON Schema (draft-07) documents for the Go types
+// using the same field rules as the standard JSON encoder.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema version for the generated documents
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// JSON Schema type names
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+// Schema is a JSON Schema document (only the keywords used by the generator)
+type Schema struct {
+	Version              string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Comment              string             `json:"$comment,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` //type name or a list of type names
+	Format               string             `json:"format,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` //bool or schema
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Generator creates the schemas for the Go types
+// (the struct types are added to the schema definitions)
+type Generator struct {
+	// Overrides are the schemas for the types with custom JSON encoding
+	Overrides map[reflect.Type]*Schema
+	// ExtraProperties are the properties added by the custom JSON encoders for the struct types
+	ExtraProperties map[reflect.Type]map[string]*Schema
+
+	definitions map[string]*Schema
+	names       map[reflect.Type]string
+}
+
+// NewGenerator creates a new schema generator
+func NewGenerator() *Generator {
+	return &Generator{
+		Overrides: map[reflect.Type]*Schema{
+			reflect.TypeOf(time.Time{}): {Type: TypeString, Format: "date-time"},
+		},
+		ExtraProperties: map[reflect.Type]map[string]*Schema{},
+	}
+}
+
+// Reflect returns the schema document for the value type
+func (g *Generator) Reflect(v interface{}) *Schema {
+	g.definitions = map[string]*Schema{}
+	g.names = map[reflect.Type]string{}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var root *Schema
+	if t.Kind() == reflect.Struct && !g.hasCustomEncoding(t) {
+		root = g.structSchema(t)
+	} else {
+		root = g.typeSchema(t)
+	}
+
+	root.Version = Draft
+	if len(g.definitions) > 0 {
+		root.Definitions = g.definitions
+	}
+
+	return root
+}
+
+func (g *Generator) hasCustomEncoding(t reflect.Type) bool {
+	if _, found := g.Overrides[t]; found {
+		return true
+	}
+
+	if _, found := g.ExtraProperties[t]; found {
+		return false
+	}
+
+	marshalerType := reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType := reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	return t.Implements(marshalerType) ||
+		reflect.PtrTo(t).Implements(marshalerType) ||
+		t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+func (g *Generator) typeSchema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		//the pointer receiver encoders are checked for the element type
+		return nullable(g.typeSchema(t.Elem()))
+	}
+
+	if schema, found := g.Overrides[t]; found {
+		copied := *schema
+		return &copied
+	}
+
+	if g.hasCustomEncoding(t) {
+		textMarshalerType := reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+		if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+			return &Schema{Type: TypeString}
+		}
+
+		//unknown custom encoding (any value)
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}
+	case reflect.String:
+		return &Schema{Type: TypeString}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			//encoded as a base64 string
+			return nullable(&Schema{Type: TypeString})
+		}
+
+		return nullable(&Schema{Type: TypeArray, Items: g.typeSchema(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: TypeArray, Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return nullable(&Schema{Type: TypeObject, AdditionalProperties: g.typeSchema(t.Elem())})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+
+		return &Schema{Ref: "#/definitions/" + g.definitionName(t)}
+	default:
+		//interfaces (any value)
+		return &Schema{}
+	}
+}
+
+// definitionName returns the definition name for the named struct type
+// (adding the type definition if it's not added yet)
+func (g *Generator) definitionName(t reflect.Type) string {
+	if name, found := g.names[t]; found {
+		return name
+	}
+
+	name := t.Name()
+	if pkgPath := t.PkgPath(); pkgPath != "" {
+		name = path.Base(pkgPath) + "." + name
+	}
+
+	//different packages may have the same base name
+	if _, found := g.definitions[name]; found {
+		for idx := 2; ; idx++ {
+			indexed := fmt.Sprintf("%s_%d", name, idx)
+			if _, found := g.definitions[indexed]; !found {
+				name = indexed
+				break
+			}
+		}
+	}
+
+	g.names[t] = name
+	//placeholder for the recursive types
+	g.definitions[name] = &Schema{}
+	*g.definitions[name] = *g.structSchema(t)
+	return name
+}
+
+type fieldInfo struct {
+	name      string
+	depth     int
+	omitEmpty bool
+	asString  bool
+	tagged    bool
+	t         reflect.Type
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:                 TypeObject,
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+
+	for _, f := range dominantFields(structFields(t, 0, map[reflect.Type]bool{})) {
+		var fs *Schema
+		if f.asString {
+			fs = &Schema{Type: TypeString}
+		} else {
+			fs = g.typeSchema(f.t)
+		}
+
+		schema.Properties[f.name] = fs
+		if !f.omitEmpty {
+			schema.Required = append(schema.Required, f.name)
+		}
+	}
+
+	extra := g.ExtraProperties[t]
+	var extraNames []string
+	for name := range extra {
+		extraNames = append(extraNames, name)
+	}
+
+	sort.Strings(extraNames)
+	for _, name := range extraNames {
+		copied := *extra[name]
+		schema.Properties[name] = &copied
+		schema.Required = append(schema.Required, name)
+	}
+
+	return schema
+}
+
+// structFields returns the encoded struct fields (including the fields from the embedded structs)
+func structFields(t reflect.Type, depth int, visited map[reflect.Type]bool) []*fieldInfo {
+	if visited[t] {
+		return nil
+	}
+
+	visited[t] = true
+	defer delete(visited, t)
+
+	var fields []*fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		ft := sf.Type
+		if sf.Anonymous {
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if sf.PkgPath != "" && ft.Kind() != reflect.Struct {
+				continue
+			}
+		} else if sf.PkgPath != "" {
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+			fields = append(fields, structFields(ft, depth+1, visited)...)
+			continue
+		}
+
+		info := &fieldInfo{
+			name:   name,
+			depth:  depth,
+			tagged: name != "",
+			t:      sf.Type,
+		}
+
+		if info.name == "" {
+			info.name = sf.Name
+		}
+
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				info.omitEmpty = true
+			case "string":
+				switch sf.Type.Kind() {
+				case reflect.Bool, reflect.String,
+					reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+					reflect.Float32, reflect.Float64:
+					info.asString = true
+				}
+			}
+		}
+
+		fields = append(fields, info)
+	}
+
+	return fields
+}
+
+// dominantFields applies the encoding/json rules for the fields with the same name
+// (the shallower field wins, then the tagged field; the other conflicting fields are dropped)
+func dominantFields(fields []*fieldInfo) []*fieldInfo {
+	byName := map[string][]*fieldInfo{}
+	var names []string
+	for _, f := range fields {
+		if _, found := byName[f.name]; !found {
+			names = append(names, f.name)
+		}
+
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	var result []*fieldInfo
+	for _, name := range names {
+		candidates := byName[name]
+		minDepth := candidates[0].depth
+		for _, f := range candidates {
+			if f.depth < minDepth {
+				minDepth = f.depth
+			}
+		}
+
+		var dominant []*fieldInfo
+		for _, f := range candidates {
+			if f.depth == minDepth {
+				dominant = append(dominant, f)
+			}
+		}
+
+		if len(dominant) > 1 {
+			var tagged []*fieldInfo
+			for _, f := range dominant {
+				if f.tagged {
+					tagged = append(tagged, f)
+				}
+			}
+
+			dominant = tagged
+		}
+
+		if len(dominant) == 1 {
+			result = append(result, dominant[0])
+		}
+	}
+
+	return result
+}
+
+// nullable allows the null value for the schema (e.g., for the nil pointers, slices and maps)
+func nullable(schema *Schema) *Schema {
+	switch {
+	case schema.Ref != "":
+		return &Schema{AnyOf: []*Schema{schema, {Type: TypeNull}}}
+	case schema.Type == nil:
+		//already allows any value
+		return schema
+	}
+
+	switch st := schema.Type.(type) {
+	case string:
+		if st != TypeNull {
+			schema.Type = []string{st, TypeNull}
+		}
+	case []string:
+		for _, name := range st {
+			if name == TypeNull {
+				return schema
+			}
+		}
+
+		schema.Type = append(st, TypeNull)
+	}
+
+	return schema
+}